@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
@@ -13,6 +14,12 @@ import (
 func main() {
 	ctx := context.Background()
 	if err := mainE(ctx); err != nil {
+		var exitErr *cli.ExitError
+		if errors.As(err, &exitErr) {
+			clog.FromContext(ctx).Error(err.Error())
+			os.Exit(exitErr.Code)
+		}
+
 		clog.FromContext(ctx).Fatal(err.Error())
 	}
 }
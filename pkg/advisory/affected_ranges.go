@@ -0,0 +1,162 @@
+package advisory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// AffectedRange is a range of versions of a package that are affected by an
+// advisory's vulnerability.
+type AffectedRange struct {
+	// Introduced is the first affected version, or "0" if all versions before
+	// Fixed are affected.
+	Introduced string
+
+	// Fixed is the first version after Introduced that isn't affected, or empty if
+	// the vulnerability hasn't been fixed since Introduced.
+	Fixed string
+}
+
+// Vers returns the range as a "vers" version range specifier for APK packages
+// (see https://github.com/package-url/purl-spec/blob/main/VERSION-RANGE-SPEC.rst),
+// e.g. "vers:apk/>=1.2.0-r0|<1.2.4-r0", as used by CSAF's product version ranges.
+func (r AffectedRange) Vers() string {
+	var constraints []string
+	if r.Introduced != "0" {
+		constraints = append(constraints, ">="+r.Introduced)
+	}
+	if r.Fixed != "" {
+		constraints = append(constraints, "<"+r.Fixed)
+	}
+	if len(constraints) == 0 {
+		return "vers:apk/*"
+	}
+	return "vers:apk/" + strings.Join(constraints, "|")
+}
+
+// AffectedRanges derives the ranges of versions of the package that are affected
+// by the advisory's vulnerability, from the advisory's events and the package's
+// version timeline.
+//
+// Until the first fixed event, all versions are affected. An event that reopens
+// the advisory after a fix (e.g. a detection of a regression) starts another
+// range, whose introduced version is the earliest version the package was
+// defined with after the previous fixed version, and before the event, since
+// the regression can't have come later. If the timeline is nil or doesn't have
+// such a version, the previous fixed version is used. A later fixed event
+// without a reopening event in between corrects the fixed version of the
+// previous range.
+//
+// An advisory whose latest event is a false positive determination has no
+// affected ranges. An earlier false positive determination discards the range
+// it concludes.
+func AffectedRanges(ctx context.Context, timeline PackageVersionTimeline, pkgName string, adv v2.Advisory) ([]AffectedRange, error) {
+	events := adv.SortedEvents()
+	if len(events) == 0 || events[len(events)-1].Type == v2.EventTypeFalsePositiveDetermination {
+		return nil, nil
+	}
+
+	var changes []PackageVersionChange
+	changesLoaded := false
+
+	var ranges []AffectedRange
+	current := &AffectedRange{Introduced: "0"}
+
+	for _, e := range events {
+		switch e.Type {
+		case v2.EventTypeFixed:
+			d, ok := e.Data.(v2.Fixed)
+			if !ok {
+				return nil, fmt.Errorf("unexpected data type for fixed event: %T (package %q, advisory ID %q)", e.Data, pkgName, adv.ID)
+			}
+
+			switch {
+			case current != nil:
+				current.Fixed = d.FixedVersion
+				ranges = append(ranges, *current)
+				current = nil
+			case len(ranges) > 0:
+				ranges[len(ranges)-1].Fixed = d.FixedVersion
+			default:
+				ranges = append(ranges, AffectedRange{Introduced: "0", Fixed: d.FixedVersion})
+			}
+
+		case v2.EventTypeFalsePositiveDetermination:
+			current = nil
+
+		case v2.EventTypeFixNotPlanned:
+			// The range stays open.
+
+		default:
+			if current != nil {
+				continue
+			}
+
+			if len(ranges) == 0 {
+				current = &AffectedRange{Introduced: "0"}
+				continue
+			}
+
+			if timeline != nil && !changesLoaded {
+				var err error
+				changes, err = timeline.Timeline(ctx, pkgName)
+				if err != nil {
+					return nil, fmt.Errorf("getting version timeline of package %q: %w", pkgName, err)
+				}
+				changesLoaded = true
+			}
+
+			previousFixed := ranges[len(ranges)-1].Fixed
+			current = &AffectedRange{Introduced: versionAfter(changes, previousFixed, time.Time(e.Timestamp))}
+		}
+	}
+
+	if current != nil {
+		ranges = append(ranges, *current)
+	}
+
+	return ranges, nil
+}
+
+// versionAfter returns the earliest version in the timeline that's greater than
+// the given version and was defined at or before the given time. If there's no
+// such version, it returns the given version.
+func versionAfter(changes []PackageVersionChange, version string, before time.Time) string {
+	v, err := apk.ParseVersion(version)
+	if err != nil {
+		return version
+	}
+
+	for _, c := range changes {
+		if c.Time.After(before) {
+			break
+		}
+		cv, err := apk.ParseVersion(c.Version)
+		if err != nil {
+			continue
+		}
+		if apk.CompareVersions(cv, v) > 0 {
+			return c.Version
+		}
+	}
+
+	return version
+}
+
+// osvRange returns the OSV range with the events of the affected ranges.
+func osvRange(ranges []AffectedRange) models.Range {
+	r := models.Range{Type: models.RangeEcosystem}
+	for _, ar := range ranges {
+		r.Events = append(r.Events, models.Event{Introduced: ar.Introduced})
+		if ar.Fixed != "" {
+			r.Events = append(r.Events, models.Event{Fixed: ar.Fixed})
+		}
+	}
+	return r
+}
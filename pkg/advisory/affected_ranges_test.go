@@ -0,0 +1,116 @@
+package advisory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAffectedRanges(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC)
+	}
+	detection := func(d int) v2.Event {
+		return v2.Event{Timestamp: v2.Timestamp(day(d)), Type: v2.EventTypeDetection, Data: v2.Detection{Type: v2.DetectionTypeManual}}
+	}
+	fixed := func(d int, version string) v2.Event {
+		return v2.Event{Timestamp: v2.Timestamp(day(d)), Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: version}}
+	}
+	falsePositive := func(d int) v2.Event {
+		return v2.Event{Timestamp: v2.Timestamp(day(d)), Type: v2.EventTypeFalsePositiveDetermination, Data: v2.FalsePositiveDetermination{Type: v2.FPTypeVulnerableCodeNotIncludedInPackage}}
+	}
+
+	timeline := mockPackageVersionTimeline{
+		"ko": {
+			{Version: "0.15.0-r0", Time: day(1)},
+			{Version: "0.15.1-r0", Time: day(3)},
+			{Version: "0.15.2-r0", Time: day(5)},
+			{Version: "0.15.3-r0", Time: day(12)},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		timeline PackageVersionTimeline
+		events   []v2.Event
+		expected []AffectedRange
+	}{
+		{
+			name:     "fixed",
+			timeline: timeline,
+			events:   []v2.Event{detection(2), fixed(4, "0.15.1-r0")},
+			expected: []AffectedRange{{Introduced: "0", Fixed: "0.15.1-r0"}},
+		},
+		{
+			name:     "open",
+			timeline: timeline,
+			events:   []v2.Event{detection(2)},
+			expected: []AffectedRange{{Introduced: "0"}},
+		},
+		{
+			name:     "regression",
+			timeline: timeline,
+			events:   []v2.Event{detection(2), fixed(4, "0.15.1-r0"), detection(10), fixed(11, "0.15.2-r1")},
+			expected: []AffectedRange{{Introduced: "0", Fixed: "0.15.1-r0"}, {Introduced: "0.15.2-r0", Fixed: "0.15.2-r1"}},
+		},
+		{
+			name:     "unfixed regression",
+			timeline: timeline,
+			events:   []v2.Event{fixed(4, "0.15.1-r0"), detection(10)},
+			expected: []AffectedRange{{Introduced: "0", Fixed: "0.15.1-r0"}, {Introduced: "0.15.2-r0"}},
+		},
+		{
+			name:     "regression without timeline",
+			events:   []v2.Event{detection(2), fixed(4, "0.15.1-r0"), detection(10), fixed(11, "0.15.2-r1")},
+			expected: []AffectedRange{{Introduced: "0", Fixed: "0.15.1-r0"}, {Introduced: "0.15.1-r0", Fixed: "0.15.2-r1"}},
+		},
+		{
+			name:     "corrected fixed version",
+			timeline: timeline,
+			events:   []v2.Event{detection(2), fixed(4, "0.15.1-r0"), fixed(6, "0.15.2-r0")},
+			expected: []AffectedRange{{Introduced: "0", Fixed: "0.15.2-r0"}},
+		},
+		{
+			name:     "false positive",
+			timeline: timeline,
+			events:   []v2.Event{detection(2), falsePositive(3)},
+			expected: nil,
+		},
+		{
+			name:     "fixed after earlier false positive",
+			timeline: timeline,
+			events:   []v2.Event{detection(2), falsePositive(3), fixed(6, "0.15.2-r0")},
+			expected: []AffectedRange{{Introduced: "0", Fixed: "0.15.2-r0"}},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			adv := v2.Advisory{ID: "CGA-2222-2222-2222", Events: tt.events}
+
+			ranges, err := AffectedRanges(context.Background(), tt.timeline, "ko", adv)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ranges)
+		})
+	}
+}
+
+func TestAffectedRange_Vers(t *testing.T) {
+	assert.Equal(t, "vers:apk/<1.2.4-r0", AffectedRange{Introduced: "0", Fixed: "1.2.4-r0"}.Vers())
+	assert.Equal(t, "vers:apk/>=1.2.0-r0|<1.2.4-r0", AffectedRange{Introduced: "1.2.0-r0", Fixed: "1.2.4-r0"}.Vers())
+	assert.Equal(t, "vers:apk/>=1.2.0-r0", AffectedRange{Introduced: "1.2.0-r0"}.Vers())
+	assert.Equal(t, "vers:apk/*", AffectedRange{Introduced: "0"}.Vers())
+}
+
+func TestOSVRange(t *testing.T) {
+	r := osvRange([]AffectedRange{{Introduced: "0", Fixed: "0.15.1-r0"}, {Introduced: "0.15.2-r0"}})
+	assert.Equal(t, models.Range{
+		Type:   models.RangeEcosystem,
+		Events: []models.Event{{Introduced: "0"}, {Fixed: "0.15.1-r0"}, {Introduced: "0.15.2-r0"}},
+	}, r)
+}
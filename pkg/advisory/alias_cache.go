@@ -0,0 +1,170 @@
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// DefaultAliasCachePath is where CachingAliasFinder stores resolved aliases by
+// default.
+var DefaultAliasCachePath = path.Join(xdg.CacheHome, "wolfictl", "aliases", "ghsa.json")
+
+// DefaultAliasCacheMaxAge is how long resolved aliases are used by default
+// before they're resolved again. GHSA↔CVE mappings rarely change once they
+// exist, but new GHSAs are published for existing CVEs.
+const DefaultAliasCacheMaxAge = 7 * 24 * time.Hour
+
+// CachingAliasFinder is an AliasFinder that caches the aliases resolved by
+// another AliasFinder in a local file, so that they can be reused across runs.
+// Call Save to write the cache file.
+type CachingAliasFinder struct {
+	finder AliasFinder
+	path   string
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache aliasCache
+	dirty bool
+}
+
+type aliasCache struct {
+	CVEByGHSA  map[string]cachedCVE   `json:"cveByGHSA"`
+	GHSAsByCVE map[string]cachedGHSAs `json:"ghsasByCVE"`
+}
+
+type cachedCVE struct {
+	CVE        string    `json:"cve"`
+	ResolvedAt time.Time `json:"resolvedAt"`
+}
+
+type cachedGHSAs struct {
+	GHSAs      []string  `json:"ghsas"`
+	ResolvedAt time.Time `json:"resolvedAt"`
+}
+
+// NewCachingAliasFinder returns a CachingAliasFinder that uses the given finder
+// for aliases that aren't in the cache file at the given path, or that were
+// resolved longer than maxAge ago. A cache file that doesn't exist yet is
+// treated as empty.
+func NewCachingAliasFinder(finder AliasFinder, cachePath string, maxAge time.Duration) (*CachingAliasFinder, error) {
+	f := &CachingAliasFinder{
+		finder: finder,
+		path:   cachePath,
+		maxAge: maxAge,
+		now:    time.Now,
+		cache: aliasCache{
+			CVEByGHSA:  make(map[string]cachedCVE),
+			GHSAsByCVE: make(map[string]cachedGHSAs),
+		},
+	}
+
+	b, err := os.ReadFile(cachePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return f, nil
+		}
+		return nil, fmt.Errorf("reading alias cache: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &f.cache); err != nil {
+		return nil, fmt.Errorf("decoding alias cache %q: %w", cachePath, err)
+	}
+	if f.cache.CVEByGHSA == nil {
+		f.cache.CVEByGHSA = make(map[string]cachedCVE)
+	}
+	if f.cache.GHSAsByCVE == nil {
+		f.cache.GHSAsByCVE = make(map[string]cachedGHSAs)
+	}
+
+	return f, nil
+}
+
+func (f *CachingAliasFinder) CVEForGHSA(ctx context.Context, ghsaID string) (string, error) {
+	f.mu.Lock()
+	entry, ok := f.cache.CVEByGHSA[ghsaID]
+	f.mu.Unlock()
+	if ok && f.fresh(entry.ResolvedAt) {
+		return entry.CVE, nil
+	}
+
+	cveID, err := f.finder.CVEForGHSA(ctx, ghsaID)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	f.cache.CVEByGHSA[ghsaID] = cachedCVE{CVE: cveID, ResolvedAt: f.now()}
+	f.dirty = true
+	f.mu.Unlock()
+
+	return cveID, nil
+}
+
+func (f *CachingAliasFinder) GHSAsForCVE(ctx context.Context, cveID string) ([]string, error) {
+	f.mu.Lock()
+	entry, ok := f.cache.GHSAsByCVE[cveID]
+	f.mu.Unlock()
+	if ok && f.fresh(entry.ResolvedAt) {
+		return entry.GHSAs, nil
+	}
+
+	ghsaIDs, err := f.finder.GHSAsForCVE(ctx, cveID)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.cache.GHSAsByCVE[cveID] = cachedGHSAs{GHSAs: ghsaIDs, ResolvedAt: f.now()}
+	f.dirty = true
+	f.mu.Unlock()
+
+	return ghsaIDs, nil
+}
+
+func (f *CachingAliasFinder) fresh(resolvedAt time.Time) bool {
+	return f.now().Sub(resolvedAt) < f.maxAge
+}
+
+// Save writes the cache file, if any aliases were resolved since the cache was
+// loaded.
+func (f *CachingAliasFinder) Save() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.dirty {
+		return nil
+	}
+
+	b, err := json.Marshal(f.cache)
+	if err != nil {
+		return fmt.Errorf("encoding alias cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("creating alias cache directory: %w", err)
+	}
+
+	// Write to a temporary file first, so that an interrupted write doesn't leave
+	// a corrupt cache behind.
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("writing alias cache: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("writing alias cache: %w", err)
+	}
+
+	f.dirty = false
+	return nil
+}
@@ -0,0 +1,77 @@
+package advisory
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachingAliasFinder(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "aliases", "ghsa.json")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	counting := &countingAliasFinder{
+		finder: mockAliasFinder{
+			cveByGHSA:  map[string]string{"GHSA-2222-2222-2222": "CVE-2023-2222"},
+			ghsasByCVE: map[string][]string{"CVE-2023-2222": {"GHSA-2222-2222-2222"}},
+		},
+	}
+
+	newFinder := func(t *testing.T) *CachingAliasFinder {
+		t.Helper()
+		f, err := NewCachingAliasFinder(counting, cachePath, 24*time.Hour)
+		require.NoError(t, err)
+		f.now = func() time.Time { return now }
+		return f
+	}
+
+	ctx := context.Background()
+
+	// Resolve the aliases, and save them to the cache file.
+	f := newFinder(t)
+	cve, err := f.CVEForGHSA(ctx, "GHSA-2222-2222-2222")
+	require.NoError(t, err)
+	assert.Equal(t, "CVE-2023-2222", cve)
+	ghsas, err := f.GHSAsForCVE(ctx, "CVE-2023-2222")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GHSA-2222-2222-2222"}, ghsas)
+	require.NoError(t, f.Save())
+	assert.Equal(t, 2, counting.calls)
+
+	// A new finder uses the cache file.
+	now = now.Add(time.Hour)
+	f = newFinder(t)
+	cve, err = f.CVEForGHSA(ctx, "GHSA-2222-2222-2222")
+	require.NoError(t, err)
+	assert.Equal(t, "CVE-2023-2222", cve)
+	ghsas, err = f.GHSAsForCVE(ctx, "CVE-2023-2222")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GHSA-2222-2222-2222"}, ghsas)
+	assert.Equal(t, 2, counting.calls)
+
+	// Stale entries are resolved again.
+	now = now.Add(24 * time.Hour)
+	f = newFinder(t)
+	_, err = f.CVEForGHSA(ctx, "GHSA-2222-2222-2222")
+	require.NoError(t, err)
+	assert.Equal(t, 3, counting.calls)
+}
+
+type countingAliasFinder struct {
+	finder AliasFinder
+	calls  int
+}
+
+func (f *countingAliasFinder) CVEForGHSA(ctx context.Context, ghsaID string) (string, error) {
+	f.calls++
+	return f.finder.CVEForGHSA(ctx, ghsaID)
+}
+
+func (f *countingAliasFinder) GHSAsForCVE(ctx context.Context, cveID string) ([]string, error) {
+	f.calls++
+	return f.finder.GHSAsForCVE(ctx, cveID)
+}
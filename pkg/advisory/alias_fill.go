@@ -0,0 +1,152 @@
+package advisory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	vulnadvs "github.com/chainguard-dev/advisory-schema/pkg/vuln"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+)
+
+// FillAliasesOptions configures the FillAliases operation.
+type FillAliasesOptions struct {
+	// AdvisoryDocs is the Index of advisory documents on which to operate.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// AliasFinder is used to resolve the aliases of the advisories' CVE and GHSA
+	// IDs.
+	AliasFinder AliasFinder
+
+	// SelectedPackages is the set of packages to operate on. If empty, all packages
+	// will be operated on.
+	SelectedPackages map[string]struct{}
+
+	// DryRun finds the missing aliases without updating the advisory documents.
+	DryRun bool
+}
+
+// AliasFill describes the aliases found missing from an advisory.
+type AliasFill struct {
+	// Package is the name of the package the advisory is for.
+	Package string
+
+	// AdvisoryID is the ID of the advisory.
+	AdvisoryID string
+
+	// Added are the aliases added to the advisory.
+	Added []string
+
+	// Conflicts are aliases found for the advisory that weren't added, because
+	// they're already used by another advisory for the package. These need to be
+	// resolved manually, e.g. by merging the advisories.
+	Conflicts []string
+}
+
+// FillAliases resolves the complete set of CVE and GHSA aliases for each
+// selected advisory, using the advisory's existing CVE and GHSA aliases as a
+// starting point, and adds any that are missing to the advisory. It returns
+// what was found for each advisory that's missing aliases, sorted by package
+// and advisory ID.
+//
+// Unlike DiscoverAliases, an alias that would cause two advisories for the same
+// package to describe the same vulnerability is reported as a conflict instead
+// of stopping the whole operation.
+func FillAliases(ctx context.Context, opts FillAliasesOptions) ([]AliasFill, error) {
+	var fills []AliasFill
+
+	for _, doc := range opts.AdvisoryDocs.Select().Configurations() {
+		if len(opts.SelectedPackages) > 0 {
+			if _, ok := opts.SelectedPackages[doc.Package.Name]; !ok {
+				continue
+			}
+		}
+
+		advisories := make(v2.Advisories, len(doc.Advisories))
+		copy(advisories, doc.Advisories)
+
+		var docFills []AliasFill
+		for i, adv := range advisories {
+			var known []string
+			for _, alias := range adv.Aliases {
+				if vulnadvs.RegexCVE.MatchString(alias) || vulnadvs.RegexGHSA.MatchString(alias) {
+					known = append(known, alias)
+				}
+			}
+			if len(known) == 0 {
+				continue
+			}
+
+			complete, err := CompleteAliasSet(ctx, opts.AliasFinder, known)
+			if err != nil {
+				return nil, fmt.Errorf("resolving aliases for %s in %s: %w", adv.ID, doc.Package.Name, err)
+			}
+
+			fill := AliasFill{Package: doc.Package.Name, AdvisoryID: adv.ID}
+			for _, alias := range complete {
+				if adv.DescribesVulnerability(alias) {
+					continue
+				}
+				if describedByOtherAdvisory(advisories, adv.ID, alias) {
+					fill.Conflicts = append(fill.Conflicts, alias)
+					continue
+				}
+				fill.Added = append(fill.Added, alias)
+			}
+			if len(fill.Added) == 0 && len(fill.Conflicts) == 0 {
+				continue
+			}
+
+			// Keep the existing order of the aliases, since reordering them isn't part of
+			// filling them in.
+			adv.Aliases = append(append([]string{}, adv.Aliases...), fill.Added...)
+			advisories[i] = adv
+
+			docFills = append(docFills, fill)
+		}
+
+		if len(docFills) == 0 {
+			continue
+		}
+		fills = append(fills, docFills...)
+
+		if opts.DryRun {
+			continue
+		}
+
+		u := adv2.NewAdvisoriesSectionUpdater(func(_ v2.Document) (v2.Advisories, error) {
+			return advisories, nil
+		})
+		if err := opts.AdvisoryDocs.Select().WhereName(doc.Name()).Update(ctx, u); err != nil {
+			return nil, fmt.Errorf("updating advisories for %q: %w", doc.Name(), err)
+		}
+
+		// Update the schema version to the latest version.
+		err := opts.AdvisoryDocs.Select().WhereName(doc.Name()).Update(ctx, adv2.NewSchemaVersionSectionUpdater(v2.SchemaVersion))
+		if err != nil {
+			return nil, fmt.Errorf("unable to update schema version for %q: %w", doc.Name(), err)
+		}
+	}
+
+	sort.Slice(fills, func(i, j int) bool {
+		if fills[i].Package != fills[j].Package {
+			return fills[i].Package < fills[j].Package
+		}
+		return fills[i].AdvisoryID < fills[j].AdvisoryID
+	})
+
+	return fills, nil
+}
+
+// describedByOtherAdvisory returns true if an advisory other than the one with
+// the given ID describes the vulnerability.
+func describedByOtherAdvisory(advisories v2.Advisories, advisoryID, vulnID string) bool {
+	for _, other := range advisories {
+		if other.ID != advisoryID && other.DescribesVulnerability(vulnID) {
+			return true
+		}
+	}
+	return false
+}
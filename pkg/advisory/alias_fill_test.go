@@ -0,0 +1,107 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestFillAliases(t *testing.T) {
+	af := mockAliasFinder{
+		cveByGHSA: map[string]string{
+			"GHSA-2222-2222-2222": "CVE-2023-2222",
+			"GHSA-3333-3333-3333": "CVE-2023-3333",
+			"GHSA-4444-4444-4444": "CVE-2023-3333",
+		},
+		ghsasByCVE: map[string][]string{
+			"CVE-2023-2222": {"GHSA-2222-2222-2222"},
+			"CVE-2023-3333": {"GHSA-3333-3333-3333", "GHSA-4444-4444-4444"},
+		},
+	}
+
+	expectedFills := []AliasFill{
+		{
+			Package:    "crane",
+			AdvisoryID: "CGA-7777-7777-7777",
+			Added:      []string{"GHSA-2222-2222-2222"},
+		},
+		{
+			Package:    "ko",
+			AdvisoryID: "CGA-2222-2222-2222",
+			Added:      []string{"GHSA-2222-2222-2222"},
+		},
+		{
+			Package:    "ko",
+			AdvisoryID: "CGA-3333-3333-3333",
+			Added:      []string{"CVE-2023-3333"},
+			Conflicts:  []string{"GHSA-4444-4444-4444"},
+		},
+		{
+			Package:    "ko",
+			AdvisoryID: "CGA-4444-4444-4444",
+			Conflicts:  []string{"CVE-2023-3333", "GHSA-3333-3333-3333"},
+		},
+	}
+
+	aliasesOf := func(t *testing.T, opts FillAliasesOptions, pkg, advisoryID string) []string {
+		t.Helper()
+		doc := opts.AdvisoryDocs.Select().WhereName(pkg).Configurations()[0]
+		adv, ok := doc.Advisories.Get(advisoryID)
+		require.True(t, ok)
+		return adv.Aliases
+	}
+
+	cases := []struct {
+		name   string
+		dryRun bool
+	}{
+		{name: "update"},
+		{name: "dry run", dryRun: true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			advisoryDocs, err := adv2.NewIndex(context.Background(), memfs.New(os.DirFS("testdata/fill_aliases")))
+			require.NoError(t, err)
+
+			opts := FillAliasesOptions{
+				AdvisoryDocs: advisoryDocs,
+				AliasFinder:  af,
+				DryRun:       tt.dryRun,
+			}
+
+			fills, err := FillAliases(context.Background(), opts)
+			require.NoError(t, err)
+			assert.Equal(t, expectedFills, fills)
+
+			if tt.dryRun {
+				assert.Equal(t, []string{"CVE-2023-2222"}, aliasesOf(t, opts, "ko", "CGA-2222-2222-2222"))
+				return
+			}
+
+			assert.Equal(t, []string{"CVE-2023-2222", "GHSA-2222-2222-2222"}, aliasesOf(t, opts, "ko", "CGA-2222-2222-2222"))
+			assert.Equal(t, []string{"GHSA-3333-3333-3333", "CVE-2023-3333"}, aliasesOf(t, opts, "ko", "CGA-3333-3333-3333"))
+			assert.Equal(t, []string{"GHSA-4444-4444-4444"}, aliasesOf(t, opts, "ko", "CGA-4444-4444-4444"))
+			assert.Equal(t, []string{"GO-2023-1234"}, aliasesOf(t, opts, "ko", "CGA-6666-6666-6666"))
+			assert.Equal(t, []string{"CVE-2023-2222", "GHSA-2222-2222-2222"}, aliasesOf(t, opts, "crane", "CGA-7777-7777-7777"))
+		})
+	}
+
+	t.Run("selected packages", func(t *testing.T) {
+		advisoryDocs, err := adv2.NewIndex(context.Background(), memfs.New(os.DirFS("testdata/fill_aliases")))
+		require.NoError(t, err)
+
+		fills, err := FillAliases(context.Background(), FillAliasesOptions{
+			AdvisoryDocs:     advisoryDocs,
+			AliasFinder:      af,
+			SelectedPackages: map[string]struct{}{"crane": {}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, expectedFills[:1], fills)
+	})
+}
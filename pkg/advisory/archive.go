@@ -0,0 +1,221 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+// ArchiveDirName is the name of the directory in an advisories repo that holds
+// archived advisories. It has an advisory document for each package with
+// archived advisories, in the same format as the documents at the root of the
+// repo. Since indexes of the repo don't include subdirectories, archived
+// advisories aren't part of the working set that commands modifying advisory
+// data operate on. FSGetter and IndexWithArchive include them for reading.
+const ArchiveDirName = "archive"
+
+// ErrArchivedAdvisory is returned when adding an event to an advisory that's
+// been archived. The advisory must be moved back to the package's document at
+// the root of the advisories repo first.
+var ErrArchivedAdvisory = errors.New("advisory is archived")
+
+// ArchiveOptions configures the FindArchivable operation.
+type ArchiveOptions struct {
+	// AdvisoryDocs is the Index of advisory documents on which to operate.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// SelectedPackages is the set of packages to operate on. If empty, all
+	// packages are operated on.
+	SelectedPackages map[string]struct{}
+
+	// Horizon is the time before which an advisory must have been concluded to be
+	// archived.
+	Horizon time.Time
+}
+
+// ArchiveGroup is the advisories of a package that can be archived.
+type ArchiveGroup struct {
+	// Package is the name of the package.
+	Package string
+
+	// Advisories are the package's advisories that can be archived, sorted by ID.
+	Advisories []v2.Advisory
+
+	// Remaining is the number of the package's advisories that stay in the
+	// working set.
+	Remaining int
+}
+
+// FindArchivable returns the advisories whose latest event concludes them (it's
+// a fix, a false positive determination, or a fix-not-planned event) and is
+// before the horizon, grouped by package and sorted by package name.
+func FindArchivable(opts ArchiveOptions) ([]ArchiveGroup, error) {
+	if opts.AdvisoryDocs == nil {
+		return nil, errors.New("advisory documents must be provided")
+	}
+	if opts.Horizon.IsZero() {
+		return nil, errors.New("a horizon must be provided")
+	}
+
+	var groups []ArchiveGroup
+	for _, doc := range opts.AdvisoryDocs.Select().Configurations() {
+		pkg := doc.Package.Name
+		if len(opts.SelectedPackages) > 0 {
+			if _, ok := opts.SelectedPackages[pkg]; !ok {
+				continue
+			}
+		}
+
+		g := ArchiveGroup{Package: pkg}
+		for _, adv := range doc.Advisories {
+			if len(adv.Events) == 0 {
+				g.Remaining++
+				continue
+			}
+			latest := adv.Latest()
+			if !isTerminalEvent(latest) || !time.Time(latest.Timestamp).Before(opts.Horizon) {
+				g.Remaining++
+				continue
+			}
+			g.Advisories = append(g.Advisories, adv)
+		}
+		if len(g.Advisories) == 0 {
+			continue
+		}
+
+		sort.Slice(g.Advisories, func(i, j int) bool {
+			return g.Advisories[i].ID < g.Advisories[j].ID
+		})
+		groups = append(groups, g)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Package < groups[j].Package
+	})
+
+	return groups, nil
+}
+
+// ArchiveAdvisories moves the advisories of each group from the advisory
+// documents to the archived advisory documents, creating the package's archived
+// document if needed.
+//
+// An advisory document can't be left without advisories, and an Index can't
+// remove files, so when all of a package's advisories are archived, its
+// document isn't updated. The paths of these documents are returned, and the
+// caller must remove them.
+func ArchiveAdvisories(ctx context.Context, docs, archived *configs.Index[v2.Document], groups []ArchiveGroup) ([]string, error) {
+	var emptied []string
+	for _, g := range groups {
+		entry, err := docs.Select().WhereName(g.Package).First()
+		if err != nil {
+			return nil, fmt.Errorf("finding advisory document for package %q: %w", g.Package, err)
+		}
+		doc := entry.Configuration()
+
+		archivedDocs := archived.Select().WhereName(g.Package)
+		switch archivedDocs.Len() {
+		case 0:
+			advisories := slices.Clone(v2.Advisories(g.Advisories))
+			sort.Sort(advisories)
+			if err := archived.Create(ctx, entry.Path(), v2.Document{
+				SchemaVersion: v2.SchemaVersion,
+				Package:       doc.Package,
+				Advisories:    advisories,
+			}); err != nil {
+				return nil, fmt.Errorf("creating archived advisory document for package %q: %w", g.Package, err)
+			}
+
+		case 1:
+			if err := archivedDocs.Update(ctx, adv2.NewAdvisoriesSectionUpdater(func(doc v2.Document) (v2.Advisories, error) {
+				advisories := slices.Clone(doc.Advisories)
+				for _, adv := range g.Advisories {
+					if _, exists := advisories.Get(adv.ID); exists {
+						return nil, fmt.Errorf("advisory %s is already archived", adv.ID)
+					}
+					advisories = append(advisories, adv)
+				}
+				sort.Sort(advisories)
+				return advisories, nil
+			})); err != nil {
+				return nil, fmt.Errorf("archiving advisories of package %q: %w", g.Package, err)
+			}
+
+		default:
+			return nil, fmt.Errorf("found %d archived advisory documents for package %q", archivedDocs.Len(), g.Package)
+		}
+
+		if g.Remaining == 0 {
+			emptied = append(emptied, entry.Path())
+			continue
+		}
+
+		if err := docs.Select().WhereName(g.Package).Update(ctx, adv2.NewAdvisoriesSectionUpdater(func(doc v2.Document) (v2.Advisories, error) {
+			return slices.DeleteFunc(slices.Clone(doc.Advisories), func(adv v2.Advisory) bool {
+				return slices.ContainsFunc(g.Advisories, func(a v2.Advisory) bool { return a.ID == adv.ID })
+			}), nil
+		})); err != nil {
+			return nil, fmt.Errorf("removing archived advisories of package %q: %w", g.Package, err)
+		}
+	}
+
+	return emptied, nil
+}
+
+// IndexWithArchive returns an Index of the advisory documents of the advisories
+// repo in fsys, with the archived advisories merged back into them, so that
+// exports of the advisory data are the same whether or not advisories have been
+// archived. The documents are merged in memory, and fsys isn't modified.
+func IndexWithArchive(ctx context.Context, fsys fs.FS) (*configs.Index[v2.Document], error) {
+	docs, err := adv2.NewIndex(ctx, memfs.New(fsys))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := fs.Stat(fsys, ArchiveDirName); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return docs, nil
+		}
+		return nil, err
+	}
+
+	archiveFsys, err := fs.Sub(fsys, ArchiveDirName)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := adv2.NewIndex(ctx, memfs.New(archiveFsys))
+	if err != nil {
+		return nil, fmt.Errorf("indexing archived advisory documents: %w", err)
+	}
+
+	for _, entry := range archived.Select().Entries() {
+		archivedDoc := entry.Configuration()
+
+		documents := docs.Select().WhereName(archivedDoc.Name())
+		if documents.Len() == 0 {
+			if err := docs.Create(ctx, entry.Path(), *archivedDoc); err != nil {
+				return nil, fmt.Errorf("adding archived advisory document for package %q: %w", archivedDoc.Name(), err)
+			}
+			continue
+		}
+
+		if err := documents.Update(ctx, adv2.NewAdvisoriesSectionUpdater(func(doc v2.Document) (v2.Advisories, error) {
+			advisories := append(slices.Clone(doc.Advisories), archivedDoc.Advisories...)
+			sort.Sort(advisories)
+			return advisories, nil
+		})); err != nil {
+			return nil, fmt.Errorf("merging archived advisories of package %q: %w", archivedDoc.Name(), err)
+		}
+	}
+
+	return docs, nil
+}
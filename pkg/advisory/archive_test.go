@@ -0,0 +1,133 @@
+package advisory
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory/secdb"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+func TestArchive(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	for _, name := range []string{"brotli", "ko", "openssl"} {
+		b, err := os.ReadFile("testdata/export/advisories/" + name + ".advisories.yaml")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+".advisories.yaml"), b, 0o600))
+	}
+	archiveDir := filepath.Join(dir, ArchiveDirName)
+	require.NoError(t, os.Mkdir(archiveDir, 0o755))
+
+	exportedRows := func(index *configs.Index[v2.Document]) []string {
+		r, err := ExportCSV(ExportOptions{AdvisoryDocIndices: []*configs.Index[v2.Document]{index}})
+		require.NoError(t, err)
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		rows := strings.Split(strings.TrimSpace(string(b)), "\n")
+		slices.Sort(rows)
+		return rows
+	}
+
+	before, err := IndexWithArchive(ctx, os.DirFS(dir))
+	require.NoError(t, err)
+	expected := exportedRows(before)
+
+	docs, err := adv2.NewIndex(ctx, rwos.DirFS(dir))
+	require.NoError(t, err)
+	archived, err := adv2.NewIndex(ctx, rwos.DirFS(archiveDir))
+	require.NoError(t, err)
+
+	groups, err := FindArchivable(ArchiveOptions{
+		AdvisoryDocs: docs,
+		Horizon:      time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	// All of brotli's advisories were fixed in 2022, none of ko's were, and
+	// some of openssl's were.
+	require.Len(t, groups, 2)
+	assert.Equal(t, "brotli", groups[0].Package)
+	assert.Len(t, groups[0].Advisories, 1)
+	assert.Equal(t, 0, groups[0].Remaining)
+	assert.Equal(t, "openssl", groups[1].Package)
+	assert.Len(t, groups[1].Advisories, 4)
+	assert.Equal(t, 12, groups[1].Remaining)
+
+	emptied, err := ArchiveAdvisories(ctx, docs, archived, groups)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"brotli.advisories.yaml"}, emptied)
+	for _, path := range emptied {
+		require.NoError(t, os.Remove(filepath.Join(dir, path)))
+	}
+
+	docs, err = adv2.NewIndex(ctx, rwos.DirFS(dir))
+	require.NoError(t, err)
+	archived, err = adv2.NewIndex(ctx, rwos.DirFS(archiveDir))
+	require.NoError(t, err)
+	for _, index := range []*configs.Index[v2.Document]{docs, archived} {
+		for _, doc := range index.Select().Configurations() {
+			require.NoError(t, doc.Validate())
+		}
+	}
+	assert.Equal(t, 2, docs.Select().Len())
+	assert.Equal(t, 2, archived.Select().Len())
+
+	t.Run("export fidelity", func(t *testing.T) {
+		after, err := IndexWithArchive(ctx, os.DirFS(dir))
+		require.NoError(t, err)
+		assert.Equal(t, expected, exportedRows(after))
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		// Without the archive, the archived advisories look like removals.
+		assert.False(t, IndexDiff(before, docs).IsZero())
+
+		after, err := IndexWithArchive(ctx, os.DirFS(dir))
+		require.NoError(t, err)
+		assert.True(t, IndexDiff(before, after).IsZero())
+	})
+
+	t.Run("cross-check", func(t *testing.T) {
+		alpine := AlpineSecDBTracker{Databases: []secdb.Database{{
+			Packages: []secdb.PackageEntry{
+				{Pkg: secdb.Package{Name: "brotli", Secfixes: secdb.Secfixes{
+					"1.0.9-r0": {"CVE-2020-8927"},
+				}}},
+			},
+		}}}
+		opts := CrossCheckOptions{Packages: []string{"brotli"}, Trackers: []ExternalTracker{alpine}}
+
+		opts.AdvisoryDocs = docs
+		findings, err := CrossCheck(ctx, opts)
+		require.NoError(t, err)
+		assert.Len(t, findings, 1)
+
+		opts.AdvisoryDocs, err = IndexWithArchive(ctx, os.DirFS(dir))
+		require.NoError(t, err)
+		findings, err = CrossCheck(ctx, opts)
+		require.NoError(t, err)
+		assert.Empty(t, findings)
+	})
+
+	t.Run("already archived", func(t *testing.T) {
+		_, err := ArchiveAdvisories(ctx, docs, archived, []ArchiveGroup{{
+			Package:    "openssl",
+			Advisories: archived.Select().WhereName("openssl").Configurations()[0].Advisories[:1],
+			Remaining:  1,
+		}})
+		assert.ErrorContains(t, err, "is already archived")
+	})
+}
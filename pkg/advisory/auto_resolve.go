@@ -0,0 +1,226 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+)
+
+// AutoResolveOptions configures the FindAutoResolutions operation.
+type AutoResolveOptions struct {
+	// AdvisoryDocs is the Index of advisory documents on which to operate.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// SelectedPackages is the set of packages to operate on. If empty, all
+	// packages are operated on.
+	SelectedPackages map[string]struct{}
+
+	// APKIndex is the index of the distro's published APK packages. Only
+	// versions found here are used as fixed versions.
+	APKIndex *apk.APKIndex
+
+	// PackageVersionTimeline is used to find the version of a package at the time
+	// of an advisory's latest event, and the versions that came after it.
+	PackageVersionTimeline PackageVersionTimeline
+}
+
+// AutoResolution is a fixed event that can be added to an open advisory,
+// because a build with a newer upstream version of the package has been
+// published since the vulnerability was detected.
+type AutoResolution struct {
+	// Package is the name of the package the advisory is for.
+	Package string
+
+	// Advisory is the advisory to resolve.
+	Advisory v2.Advisory
+
+	// VulnerableVersion is the package's version at the time of the advisory's
+	// latest event.
+	VulnerableVersion string
+
+	// FixedVersion is the version of the first published build of the package
+	// with a newer upstream version than VulnerableVersion.
+	FixedVersion string
+}
+
+// Request returns the advisory request that adds the fixed event to the
+// advisory.
+func (r AutoResolution) Request(timestamp v2.Timestamp) Request {
+	return Request{
+		Package:    r.Package,
+		AdvisoryID: r.Advisory.ID,
+		Aliases:    r.Advisory.Aliases,
+		Event: v2.Event{
+			Timestamp: timestamp,
+			Type:      v2.EventTypeFixed,
+			Data: v2.Fixed{
+				FixedVersion: r.FixedVersion,
+			},
+		},
+	}
+}
+
+// FindAutoResolutions finds the open advisories (those whose latest event is a
+// detection or a pending upstream fix) for which the vulnerable version of the
+// package has been superseded, and returns the fixed event to add to each.
+//
+// A version is superseded when a published build of the package has a greater
+// upstream version (i.e. ignoring the epoch), since rebuilds of the same
+// upstream version don't pick up upstream fixes. Detections of components other
+// than the package itself (e.g. a Go module found by a scan) are skipped, since
+// updating the package doesn't necessarily update the component.
+func FindAutoResolutions(ctx context.Context, opts AutoResolveOptions) ([]AutoResolution, error) {
+	if opts.AdvisoryDocs == nil {
+		return nil, errors.New("advisory documents must be provided")
+	}
+	if opts.APKIndex == nil {
+		return nil, errors.New("an APKINDEX must be provided")
+	}
+	if opts.PackageVersionTimeline == nil {
+		return nil, errors.New("a package version timeline must be provided")
+	}
+
+	log := clog.FromContext(ctx)
+	published := publishedVersions(opts.APKIndex)
+
+	var resolutions []AutoResolution
+	for _, doc := range opts.AdvisoryDocs.Select().Configurations() {
+		pkg := doc.Package.Name
+		if len(opts.SelectedPackages) > 0 {
+			if _, ok := opts.SelectedPackages[pkg]; !ok {
+				continue
+			}
+		}
+
+		var timeline []PackageVersionChange
+		for _, adv := range doc.Advisories {
+			latest := adv.Latest()
+			if !isAutoResolvable(latest) {
+				continue
+			}
+
+			if timeline == nil {
+				var err error
+				timeline, err = opts.PackageVersionTimeline.Timeline(ctx, pkg)
+				if err != nil {
+					return nil, fmt.Errorf("getting version timeline for package %q: %w", pkg, err)
+				}
+			}
+
+			vulnerable, fixed, ok := findSupersedingVersion(timeline, time.Time(latest.Timestamp), published[pkg])
+			if !ok {
+				log.Debug("no superseding version found", "package", pkg, "advisory", adv.ID)
+				continue
+			}
+
+			resolutions = append(resolutions, AutoResolution{
+				Package:           pkg,
+				Advisory:          adv,
+				VulnerableVersion: vulnerable,
+				FixedVersion:      fixed,
+			})
+		}
+	}
+
+	sort.Slice(resolutions, func(i, j int) bool {
+		if resolutions[i].Package != resolutions[j].Package {
+			return resolutions[i].Package < resolutions[j].Package
+		}
+		return resolutions[i].Advisory.ID < resolutions[j].Advisory.ID
+	})
+
+	return resolutions, nil
+}
+
+// isAutoResolvable returns true if the advisory's latest event says the
+// package's own code is (or might be) vulnerable, pending a fix.
+func isAutoResolvable(latest v2.Event) bool {
+	switch latest.Type {
+	case v2.EventTypePendingUpstreamFix:
+		return true
+
+	case v2.EventTypeDetection:
+		d, ok := latest.Data.(v2.Detection)
+		if !ok {
+			return false
+		}
+		if scanData, ok := d.Data.(v2.DetectionScanV1); ok {
+			return scanData.ComponentType == "apk"
+		}
+		return true
+	}
+
+	return false
+}
+
+// findSupersedingVersion returns the package's version as of the given time,
+// and the version of the first published build after it with a greater upstream
+// version.
+func findSupersedingVersion(timeline []PackageVersionChange, at time.Time, published map[string]struct{}) (vulnerable, fixed string, ok bool) {
+	idx := -1
+	for i, c := range timeline {
+		if c.Time.After(at) {
+			break
+		}
+		idx = i
+	}
+	if idx < 0 {
+		// The package's history doesn't go back far enough.
+		return "", "", false
+	}
+	vulnerable = timeline[idx].Version
+
+	vulnerableUpstream, err := apk.ParseVersion(upstreamVersion(vulnerable))
+	if err != nil {
+		return "", "", false
+	}
+
+	for _, c := range timeline[idx+1:] {
+		if _, ok := published[c.Version]; !ok {
+			continue
+		}
+
+		v, err := apk.ParseVersion(upstreamVersion(c.Version))
+		if err != nil {
+			continue
+		}
+		if apk.CompareVersions(v, vulnerableUpstream) > 0 {
+			return vulnerable, c.Version, true
+		}
+	}
+
+	return "", "", false
+}
+
+var epochSuffix = regexp.MustCompile(`-r\d+$`)
+
+// upstreamVersion returns the version without its epoch, e.g. "1.2.3" for
+// "1.2.3-r4".
+func upstreamVersion(version string) string {
+	return epochSuffix.ReplaceAllString(version, "")
+}
+
+// publishedVersions returns the set of versions in the APKINDEX for each origin
+// package.
+func publishedVersions(apkindex *apk.APKIndex) map[string]map[string]struct{} {
+	versions := make(map[string]map[string]struct{})
+	for _, pkg := range apkindex.Packages {
+		name := pkg.Origin
+		if name == "" {
+			name = pkg.Name
+		}
+		if versions[name] == nil {
+			versions[name] = make(map[string]struct{})
+		}
+		versions[name][pkg.Version] = struct{}{}
+	}
+	return versions
+}
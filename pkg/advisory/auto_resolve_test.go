@@ -0,0 +1,94 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestFindAutoResolutions(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2023, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	timeline := mockPackageVersionTimeline{
+		"ko": {
+			{Version: "0.9.0-r0", Time: day(time.January, 1)},
+			{Version: "0.9.0-r1", Time: day(time.February, 1)},
+			{Version: "0.10.0-r0", Time: day(time.March, 1)},
+			{Version: "1.0.0-r0", Time: day(time.April, 1)},
+		},
+		"bar": {
+			{Version: "2.0.0-r0", Time: day(time.January, 1)},
+			{Version: "2.0.0-r1", Time: day(time.February, 1)},
+		},
+	}
+
+	// 0.10.0-r0 was never published.
+	apkindex := &apk.APKIndex{
+		Packages: []*apk.Package{
+			{Name: "ko", Origin: "ko", Version: "0.9.0-r0"},
+			{Name: "ko", Origin: "ko", Version: "0.9.0-r1"},
+			{Name: "ko", Origin: "ko", Version: "1.0.0-r0"},
+			{Name: "bar", Origin: "bar", Version: "2.0.0-r0"},
+			{Name: "bar-doc", Origin: "bar", Version: "2.0.0-r1"},
+		},
+	}
+
+	advisoryDocs, err := adv2.NewIndex(context.Background(), memfs.New(os.DirFS("testdata/auto_resolve")))
+	require.NoError(t, err)
+
+	t.Run("all packages", func(t *testing.T) {
+		resolutions, err := FindAutoResolutions(context.Background(), AutoResolveOptions{
+			AdvisoryDocs:           advisoryDocs,
+			APKIndex:               apkindex,
+			PackageVersionTimeline: timeline,
+		})
+		require.NoError(t, err)
+
+		type summary struct{ id, vulnerable, fixed string }
+		var actual []summary
+		for _, r := range resolutions {
+			assert.Equal(t, "ko", r.Package)
+			actual = append(actual, summary{r.Advisory.ID, r.VulnerableVersion, r.FixedVersion})
+		}
+
+		assert.Equal(t, []summary{
+			{"CGA-2222-2222-2222", "0.9.0-r0", "1.0.0-r0"},
+			{"CGA-3333-3333-3333", "0.10.0-r0", "1.0.0-r0"},
+			{"CGA-7777-7777-7777", "0.9.0-r1", "1.0.0-r0"},
+		}, actual)
+
+		req := resolutions[0].Request(v2.Timestamp(day(time.June, 1)))
+		require.NoError(t, req.Validate())
+		assert.Equal(t, "CGA-2222-2222-2222", req.AdvisoryID)
+		assert.Equal(t, v2.Fixed{FixedVersion: "1.0.0-r0"}, req.Event.Data)
+	})
+
+	t.Run("selected packages", func(t *testing.T) {
+		resolutions, err := FindAutoResolutions(context.Background(), AutoResolveOptions{
+			AdvisoryDocs:           advisoryDocs,
+			SelectedPackages:       map[string]struct{}{"bar": {}},
+			APKIndex:               apkindex,
+			PackageVersionTimeline: timeline,
+		})
+		require.NoError(t, err)
+
+		// Only the epoch was bumped.
+		assert.Empty(t, resolutions)
+	})
+}
+
+type mockPackageVersionTimeline map[string][]PackageVersionChange
+
+func (m mockPackageVersionTimeline) Timeline(_ context.Context, pkgName string) ([]PackageVersionChange, error) {
+	return m[pkgName], nil
+}
@@ -0,0 +1,264 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+// BulkEditPatch is a declarative description of changes to make across an
+// advisories repo. It's usually decoded from YAML, like:
+//
+//	edits:
+//	  - match: vuln=CVE-2024-1234 AND (package=a OR package=b OR package=c)
+//	    append:
+//	      type: false-positive-determination
+//	      data:
+//	        type: vulnerable-code-not-included-in-package
+//	        note: The vulnerable code was removed upstream before the first release.
+type BulkEditPatch struct {
+	Edits []BulkEdit `yaml:"edits"`
+}
+
+// BulkEdit is a single edit of a BulkEditPatch.
+type BulkEdit struct {
+	// Match is a query expression (see ParseQuery) that selects the advisories to
+	// edit.
+	Match string `yaml:"match"`
+
+	// Append is the event to add to each matching advisory. If the event has no
+	// timestamp, the time of the edit is used.
+	Append v2.Event `yaml:"append"`
+
+	query Query
+}
+
+// ParseBulkEditPatch decodes a BulkEditPatch from YAML, and checks that its
+// queries and events are valid.
+func ParseBulkEditPatch(r io.Reader) (*BulkEditPatch, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	patch := &BulkEditPatch{}
+	if err := dec.Decode(patch); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("patch is empty")
+		}
+		return nil, fmt.Errorf("decoding patch: %w", err)
+	}
+
+	if len(patch.Edits) == 0 {
+		return nil, fmt.Errorf("patch has no edits")
+	}
+
+	var errs []error
+	for i := range patch.Edits {
+		e := &patch.Edits[i]
+
+		if e.Match == "" {
+			errs = append(errs, fmt.Errorf("edit %d: match must not be empty", i+1))
+		} else {
+			q, err := ParseQuery(e.Match)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("edit %d: parsing match: %w", i+1, err))
+			}
+			e.query = q
+		}
+
+		event := e.Append
+		if event.Timestamp.IsZero() {
+			// The timestamp is filled in when the edit is applied.
+			event.Timestamp = v2.Now()
+		}
+		if err := event.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("edit %d: invalid event: %w", i+1, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return patch, nil
+}
+
+// BulkEditOptions configures the PlanBulkEdit operation.
+type BulkEditOptions struct {
+	// Patch describes the edits to make.
+	Patch *BulkEditPatch
+
+	// CVSS is used for the "severity" field of the patch's queries, and may be
+	// nil.
+	CVSS CVSSData
+
+	// Now is the timestamp used for appended events that don't have one.
+	Now v2.Timestamp
+}
+
+// BulkEditChange is an event appended to an advisory by a bulk edit.
+type BulkEditChange struct {
+	Package    string
+	AdvisoryID string
+	Event      v2.Event
+}
+
+// BulkEditFile is an advisory document file changed by a bulk edit.
+type BulkEditFile struct {
+	// Path is the path of the file, relative to the advisories repo.
+	Path string
+
+	Before, After []byte
+}
+
+// BulkEditPlan is the result of applying a bulk edit in memory. Nothing is
+// written until Apply is called.
+type BulkEditPlan struct {
+	Changes []BulkEditChange
+	Files   []BulkEditFile
+}
+
+// PlanBulkEdit applies the patch's edits, in order, to an in-memory copy of the
+// advisories repo in fsys. Each edit matches against the advisories as left by
+// the previous edits. If any edit fails, or leaves an advisory document
+// invalid, an error is returned and the plan can't be applied, so that a patch
+// is either applied completely or not at all.
+func PlanBulkEdit(ctx context.Context, fsys fs.FS, opts BulkEditOptions) (*BulkEditPlan, error) {
+	if opts.Patch == nil {
+		return nil, fmt.Errorf("no patch given")
+	}
+
+	mem := memfs.New(fsys)
+	advisoryDocs, err := adv2.NewIndex(ctx, mem)
+	if err != nil {
+		return nil, fmt.Errorf("indexing advisory documents: %w", err)
+	}
+
+	plan := &BulkEditPlan{}
+	changedPaths := make(map[string]struct{})
+
+	for i, e := range opts.Patch.Edits {
+		event := e.Append
+		if event.Timestamp.IsZero() {
+			event.Timestamp = opts.Now
+		}
+
+		for _, entry := range advisoryDocs.Select().Entries() {
+			doc := entry.Configuration()
+
+			var matchedIDs []string
+			for _, adv := range doc.Advisories {
+				subject := QuerySubject{
+					Package:  doc.Package.Name,
+					Advisory: adv,
+					Severity: advisorySeverity(adv, opts.CVSS),
+				}
+				if e.query.Match(subject) {
+					matchedIDs = append(matchedIDs, adv.ID)
+				}
+			}
+			if len(matchedIDs) == 0 {
+				continue
+			}
+
+			u := adv2.NewAdvisoriesSectionUpdater(func(doc v2.Document) (v2.Advisories, error) {
+				advisories := doc.Advisories
+				for _, id := range matchedIDs {
+					adv, _ := advisories.Get(id)
+					adv.Events = append(adv.Events, event)
+					advisories = advisories.Update(id, adv)
+				}
+				sort.Sort(advisories)
+				return advisories, nil
+			})
+			if err := entry.Update(ctx, u); err != nil {
+				return nil, fmt.Errorf("edit %d: updating %q: %w", i+1, doc.Package.Name, err)
+			}
+
+			if err := entry.Update(ctx, adv2.NewSchemaVersionSectionUpdater(v2.SchemaVersion)); err != nil {
+				return nil, fmt.Errorf("edit %d: updating schema version for %q: %w", i+1, doc.Package.Name, err)
+			}
+
+			for _, id := range matchedIDs {
+				plan.Changes = append(plan.Changes, BulkEditChange{
+					Package:    doc.Package.Name,
+					AdvisoryID: id,
+					Event:      event,
+				})
+			}
+			changedPaths[entry.Path()] = struct{}{}
+		}
+	}
+
+	paths := make([]string, 0, len(changedPaths))
+	for p := range changedPaths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var errs []error
+	for _, p := range paths {
+		doc := advisoryDocs.Select().WhereFilePath(p).Configurations()[0]
+		if err := doc.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p, err))
+			continue
+		}
+
+		before, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		after, err := fs.ReadFile(mem, p)
+		if err != nil {
+			return nil, err
+		}
+
+		plan.Files = append(plan.Files, BulkEditFile{Path: p, Before: before, After: after})
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("bulk edit would leave invalid advisory documents: %w", err)
+	}
+
+	return plan, nil
+}
+
+// Diff returns a unified diff of the plan's changes to the advisories repo.
+func (p *BulkEditPlan) Diff() (string, error) {
+	var out strings.Builder
+	for _, f := range p.Files {
+		d, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(f.Before)),
+			B:        difflib.SplitLines(string(f.After)),
+			FromFile: "a/" + f.Path,
+			ToFile:   "b/" + f.Path,
+			Context:  3,
+		})
+		if err != nil {
+			return "", fmt.Errorf("diffing %q: %w", f.Path, err)
+		}
+		out.WriteString(d)
+	}
+	return out.String(), nil
+}
+
+// Apply writes the plan's changed files to the advisories repo in dir.
+func (p *BulkEditPlan) Apply(dir string) error {
+	for _, f := range p.Files {
+		if err := os.WriteFile(filepath.Join(dir, f.Path), f.After, 0o644); err != nil {
+			return fmt.Errorf("writing %q: %w", f.Path, err)
+		}
+	}
+	return nil
+}
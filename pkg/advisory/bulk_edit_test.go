@@ -0,0 +1,134 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+const testBulkEditPatch = `
+edits:
+  - match: vuln=CVE-2024-2222 AND (package=ko OR package=crane)
+    append:
+      type: false-positive-determination
+      data:
+        type: vulnerable-code-not-included-in-package
+        note: The vulnerable code isn't built.
+  - match: package=ko AND status=detection
+    append:
+      timestamp: 2024-03-01T00:00:00Z
+      type: fix-not-planned
+      data:
+        note: Upstream is unmaintained.
+`
+
+func TestParseBulkEditPatch(t *testing.T) {
+	patch, err := ParseBulkEditPatch(strings.NewReader(testBulkEditPatch))
+	require.NoError(t, err)
+	require.Len(t, patch.Edits, 2)
+	assert.Equal(t, v2.EventTypeFalsePositiveDetermination, patch.Edits[0].Append.Type)
+	assert.True(t, patch.Edits[0].Append.Timestamp.IsZero())
+
+	cases := []struct {
+		name     string
+		patch    string
+		errorMsg string
+	}{
+		{
+			name:     "empty",
+			patch:    "",
+			errorMsg: "patch is empty",
+		},
+		{
+			name:     "no edits",
+			patch:    "edits: []",
+			errorMsg: "patch has no edits",
+		},
+		{
+			name:     "unknown field",
+			patch:    "edits:\n  - match: package=ko\n    prepend: {}\n",
+			errorMsg: "field prepend not found",
+		},
+		{
+			name:     "invalid query",
+			patch:    "edits:\n  - match: package=\n    append:\n      type: detection\n      data:\n        type: manual\n",
+			errorMsg: "edit 1: parsing match",
+		},
+		{
+			name:     "invalid event",
+			patch:    "edits:\n  - match: package=ko\n    append:\n      type: fixed\n      data:\n        fixed-version: \"\"\n",
+			errorMsg: "edit 1: invalid event",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBulkEditPatch(strings.NewReader(tt.patch))
+			assert.ErrorContains(t, err, tt.errorMsg)
+		})
+	}
+}
+
+func TestPlanBulkEdit(t *testing.T) {
+	patch, err := ParseBulkEditPatch(strings.NewReader(testBulkEditPatch))
+	require.NoError(t, err)
+
+	now := v2.Timestamp(time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC))
+	fsys := os.DirFS("testdata/remediation")
+
+	plan, err := PlanBulkEdit(context.Background(), fsys, BulkEditOptions{Patch: patch, Now: now})
+	require.NoError(t, err)
+
+	var changes []string
+	for _, c := range plan.Changes {
+		changes = append(changes, c.Package+"/"+c.AdvisoryID+"/"+c.Event.Type)
+	}
+	assert.Equal(t, []string{
+		"crane/CGA-6666-6666-6666/false-positive-determination",
+		"ko/CGA-2222-2222-2222/false-positive-determination",
+		"ko/CGA-4444-4444-4444/fix-not-planned",
+	}, changes)
+	assert.Equal(t, now, plan.Changes[0].Event.Timestamp)
+
+	require.Len(t, plan.Files, 2)
+	assert.Equal(t, "crane.advisories.yaml", plan.Files[0].Path)
+	assert.Equal(t, "ko.advisories.yaml", plan.Files[1].Path)
+
+	diff, err := plan.Diff()
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- a/ko.advisories.yaml\n+++ b/ko.advisories.yaml\n")
+	assert.Contains(t, diff, "+      - timestamp: 2024-03-01T00:00:00Z\n+        type: fix-not-planned\n")
+
+	// The source files aren't changed until the plan is applied.
+	before, err := os.ReadFile("testdata/remediation/ko.advisories.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, before, plan.Files[1].Before)
+
+	dir := t.TempDir()
+	for _, name := range []string{"crane.advisories.yaml", "ko.advisories.yaml"} {
+		b, err := os.ReadFile(filepath.Join("testdata/remediation", name))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), b, 0o644))
+	}
+
+	require.NoError(t, plan.Apply(dir))
+
+	advisoryDocs, err := adv2.NewIndex(context.Background(), rwos.DirFS(dir))
+	require.NoError(t, err)
+	ko := advisoryDocs.Select().WhereName("ko").Configurations()[0]
+	adv, ok := ko.Advisories.Get("CGA-4444-4444-4444")
+	require.True(t, ok)
+	assert.Equal(t, v2.EventTypeFixNotPlanned, adv.Latest().Type)
+	adv, ok = ko.Advisories.Get("CGA-2222-2222-2222")
+	require.True(t, ok)
+	assert.Equal(t, v2.EventTypeFalsePositiveDetermination, adv.Latest().Type)
+}
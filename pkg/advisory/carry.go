@@ -0,0 +1,260 @@
+package advisory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	cgaid "github.com/chainguard-dev/advisory-schema/pkg/advisory"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+)
+
+// CarryOptions configures the Carry operation.
+type CarryOptions struct {
+	// AdvisoryDocs is the Index of advisory documents on which to operate.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// ArchivedDocs is the Index of the advisory documents in the archive directory
+	// of the advisories repo (see ArchiveDirName). The archived advisories of the
+	// source package are carried over to the archived documents of the other
+	// packages. It may be nil, if the repo has no archive.
+	ArchivedDocs *configs.Index[v2.Document]
+
+	// From is the name of the package whose advisories are carried over, e.g. the
+	// package's name before it was renamed or split.
+	From string
+
+	// To is the names of the packages to carry the advisories over to: the new
+	// name of a renamed package, or the packages a package was split into.
+	To []string
+
+	// Now is the timestamp of the provenance events recorded in the carried
+	// advisories.
+	Now v2.Timestamp
+}
+
+// CarriedAdvisory describes an advisory carried over to another package.
+type CarriedAdvisory struct {
+	// Package is the name of the package the advisory was carried over to.
+	Package string
+
+	// SourceID is the ID of the advisory in the package it was carried over from.
+	SourceID string
+
+	// ID is the ID of the carried advisory, or empty if the advisory was skipped.
+	ID string
+
+	Aliases []string
+}
+
+// CarryResult describes the outcome of the Carry operation.
+type CarryResult struct {
+	// Carried are the advisories carried over.
+	Carried []CarriedAdvisory
+
+	// Skipped are the advisories not carried over to a package, because the
+	// package already has an advisory for the same vulnerability.
+	Skipped []CarriedAdvisory
+}
+
+// Carry carries the advisories of a package over to one or more other packages,
+// for when a package is renamed or split into several packages, so that the
+// history of the package's advisories isn't lost.
+//
+// Each carried advisory keeps its aliases and all of its events, and gets a new
+// ID, since advisory IDs are unique per package and vulnerability. If the
+// latest event has a note, a copy of the event is appended with a note
+// recording where the advisory was carried over from; this keeps the
+// advisory's status unchanged. (Other event types, like "fixed", have nowhere
+// to record provenance, so the carried history is left as is.) Archived
+// advisories stay archived: they're carried over to the archived documents of
+// the other packages.
+//
+// The advisories of the source package are left unchanged, since they still
+// apply to the versions of the package published under its old name.
+func Carry(ctx context.Context, opts CarryOptions) (*CarryResult, error) {
+	if len(opts.To) == 0 {
+		return nil, fmt.Errorf("no packages to carry advisories over to")
+	}
+	if slices.Contains(opts.To, opts.From) {
+		return nil, fmt.Errorf("cannot carry advisories of %q over to itself", opts.From)
+	}
+
+	source, err := packageAdvisories(opts.AdvisoryDocs, opts.From)
+	if err != nil {
+		return nil, err
+	}
+	archivedSource, err := packageAdvisories(opts.ArchivedDocs, opts.From)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil && archivedSource == nil {
+		return nil, fmt.Errorf("no advisory document found for package %q", opts.From)
+	}
+
+	note := carryNote(opts.From, opts.To)
+
+	result := &CarryResult{}
+	for _, pkg := range opts.To {
+		live, err := packageAdvisories(opts.AdvisoryDocs, pkg)
+		if err != nil {
+			return nil, err
+		}
+		archived, err := packageAdvisories(opts.ArchivedDocs, pkg)
+		if err != nil {
+			return nil, err
+		}
+		existing := slices.Concat(live, archived)
+
+		carry := func(docs *configs.Index[v2.Document], advisories v2.Advisories) error {
+			var carried v2.Advisories
+			for _, adv := range advisories {
+				c := CarriedAdvisory{
+					Package:  pkg,
+					SourceID: adv.ID,
+					Aliases:  adv.Aliases,
+				}
+
+				if _, exists := existing.GetByAnyVulnerability(adv.Aliases...); exists {
+					result.Skipped = append(result.Skipped, c)
+					continue
+				}
+
+				id, err := cgaid.GenerateCGAID()
+				if err != nil {
+					return fmt.Errorf("generating CGA ID: %w", err)
+				}
+				c.ID = id
+
+				carried = append(carried, carryAdvisory(adv, id, fmt.Sprintf(note, adv.ID), opts.Now))
+				result.Carried = append(result.Carried, c)
+			}
+
+			return addCarriedAdvisories(ctx, docs, pkg, carried)
+		}
+
+		if err := carry(opts.AdvisoryDocs, source); err != nil {
+			return nil, err
+		}
+		if err := carry(opts.ArchivedDocs, archivedSource); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// packageAdvisories returns the advisories of the package's document in the
+// index, or nil if the index is nil or has no document for the package.
+func packageAdvisories(docs *configs.Index[v2.Document], pkg string) (v2.Advisories, error) {
+	if docs == nil {
+		return nil, nil
+	}
+
+	documents := docs.Select().WhereName(pkg)
+	switch documents.Len() {
+	case 0:
+		return nil, nil
+	case 1:
+		return documents.Configurations()[0].Advisories, nil
+	default:
+		return nil, fmt.Errorf("expected 1 advisory document for package %q, found %d", pkg, documents.Len())
+	}
+}
+
+// addCarriedAdvisories adds the carried advisories to the package's document in
+// the index, creating the document if it doesn't exist yet.
+func addCarriedAdvisories(ctx context.Context, docs *configs.Index[v2.Document], pkg string, carried v2.Advisories) error {
+	if len(carried) == 0 {
+		return nil
+	}
+
+	documents := docs.Select().WhereName(pkg)
+	if documents.Len() == 0 {
+		sort.Sort(carried)
+		err := docs.Create(ctx, fmt.Sprintf("%s.advisories.yaml", pkg), v2.Document{
+			SchemaVersion: v2.SchemaVersion,
+			Package:       v2.Package{Name: pkg},
+			Advisories:    carried,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to create advisory document for %q: %w", pkg, err)
+		}
+		return nil
+	}
+
+	u := adv2.NewAdvisoriesSectionUpdater(func(doc v2.Document) (v2.Advisories, error) {
+		advisories := append(doc.Advisories, carried...)
+		sort.Sort(advisories)
+		return advisories, nil
+	})
+	if err := documents.Update(ctx, u); err != nil {
+		return fmt.Errorf("unable to carry advisories over to %q: %w", pkg, err)
+	}
+	return nil
+}
+
+// carryNote returns the provenance note for advisories carried over from one
+// package to others, with a placeholder for the source advisory's ID.
+func carryNote(from string, to []string) string {
+	if len(to) == 1 {
+		return "Carried over from advisory %s of package " + from + ", which was renamed to " + to[0] + "."
+	}
+	return "Carried over from advisory %s of package " + from + ", which was split into " + strings.Join(to, ", ") + "."
+}
+
+func carryAdvisory(adv v2.Advisory, id, note string, now v2.Timestamp) v2.Advisory {
+	events := slices.Clone(adv.Events)
+
+	if latest := adv.Latest(); !latest.IsZero() {
+		if e, ok := withNote(latest, note); ok {
+			e.Timestamp = now
+			events = append(events, e)
+		}
+	}
+
+	return v2.Advisory{
+		ID:      id,
+		Aliases: slices.Clone(adv.Aliases),
+		Events:  events,
+	}
+}
+
+// withNote returns a copy of the event with the given note added to its note,
+// and whether the event's type has a note.
+func withNote(e v2.Event, note string) (v2.Event, bool) {
+	if existing := e.Note(); existing != "" {
+		note = existing + "\n\n" + note
+	}
+
+	switch data := e.Data.(type) {
+	case v2.TruePositiveDetermination:
+		data.Note = note
+		e.Data = data
+	case v2.FalsePositiveDetermination:
+		data.Note = note
+		e.Data = data
+	case v2.AnalysisNotPlanned:
+		data.Note = note
+		e.Data = data
+	case v2.FixNotPlanned:
+		data.Note = note
+		e.Data = data
+	case v2.PendingUpstreamFix:
+		data.Note = note
+		e.Data = data
+	case nil:
+		if e.Type != v2.EventTypeTruePositiveDetermination {
+			return v2.Event{}, false
+		}
+		e.Data = v2.TruePositiveDetermination{Note: note}
+	default:
+		return v2.Event{}, false
+	}
+
+	return e, true
+}
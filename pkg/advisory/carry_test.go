@@ -0,0 +1,156 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestCarry(t *testing.T) {
+	ctx := context.Background()
+	now := v2.Timestamp(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	newIndex := func(t *testing.T) *configs.Index[v2.Document] {
+		t.Helper()
+		index, err := adv2.NewIndex(ctx, memfs.New(os.DirFS("testdata/carry")))
+		require.NoError(t, err)
+		return index
+	}
+	document := func(t *testing.T, index *configs.Index[v2.Document], name string) v2.Document {
+		t.Helper()
+		entry, err := index.Select().WhereName(name).First()
+		require.NoError(t, err)
+		return *entry.Configuration()
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		index := newIndex(t)
+
+		result, err := Carry(ctx, CarryOptions{AdvisoryDocs: index, From: "libfoo", To: []string{"libfoo2"}, Now: now})
+		require.NoError(t, err)
+		require.Len(t, result.Carried, 3)
+		assert.Empty(t, result.Skipped)
+
+		doc := document(t, index, "libfoo2")
+		assert.Equal(t, v2.SchemaVersion, doc.SchemaVersion)
+		require.Len(t, doc.Advisories, 3)
+		require.NoError(t, doc.Validate())
+
+		source := document(t, index, "libfoo")
+		for i, c := range result.Carried {
+			assert.Equal(t, "libfoo2", c.Package)
+			assert.Equal(t, source.Advisories[i].ID, c.SourceID)
+
+			adv, ok := doc.Advisories.Get(c.ID)
+			require.True(t, ok)
+			assert.NotEqual(t, c.SourceID, adv.ID)
+			assert.Equal(t, source.Advisories[i].Aliases, adv.Aliases)
+			assert.Equal(t, source.Advisories[i].Events, adv.Events[:len(source.Advisories[i].Events)])
+		}
+
+		// Fixed: nowhere to record provenance.
+		fixed, _ := doc.Advisories.GetByVulnerability("CVE-2024-2222")
+		assert.Len(t, fixed.Events, 2)
+
+		pending, _ := doc.Advisories.GetByVulnerability("CVE-2024-3333")
+		require.Len(t, pending.Events, 2)
+		assert.Equal(t, v2.Event{
+			Timestamp: now,
+			Type:      v2.EventTypePendingUpstreamFix,
+			Data: v2.PendingUpstreamFix{
+				Note: "The fix requires an upstream API change.\n\nCarried over from advisory CGA-3333-3333-3333 of package libfoo, which was renamed to libfoo2.",
+			},
+		}, pending.Events[1])
+
+		fp, _ := doc.Advisories.GetByVulnerability("CVE-2024-4444")
+		require.Len(t, fp.Events, 2)
+		assert.Equal(t, "Carried over from advisory CGA-4444-4444-4444 of package libfoo, which was renamed to libfoo2.", fp.Latest().Note())
+		assert.Equal(t, v2.FPTypeVulnerableCodeNotIncludedInPackage, fp.Latest().Data.(v2.FalsePositiveDetermination).Type)
+	})
+
+	t.Run("split", func(t *testing.T) {
+		index := newIndex(t)
+
+		result, err := Carry(ctx, CarryOptions{AdvisoryDocs: index, From: "libfoo", To: []string{"libfoo-libs", "libfoo-utils"}, Now: now})
+		require.NoError(t, err)
+		assert.Len(t, result.Carried, 5)
+		assert.Equal(t, []CarriedAdvisory{{
+			Package:  "libfoo-utils",
+			SourceID: "CGA-4444-4444-4444",
+			Aliases:  []string{"CVE-2024-4444"},
+		}}, result.Skipped)
+
+		libs := document(t, index, "libfoo-libs")
+		assert.Len(t, libs.Advisories, 3)
+
+		utils := document(t, index, "libfoo-utils")
+		require.Len(t, utils.Advisories, 3)
+		require.NoError(t, utils.Validate())
+		existing, _ := utils.Advisories.GetByVulnerability("CVE-2024-4444")
+		assert.Equal(t, "CGA-5555-5555-5555", existing.ID)
+
+		pending, _ := utils.Advisories.GetByVulnerability("CVE-2024-3333")
+		assert.Contains(t, pending.Latest().Note(), "which was split into libfoo-libs, libfoo-utils.")
+	})
+
+	t.Run("archived", func(t *testing.T) {
+		index := newIndex(t)
+
+		// libfoo has an archived advisory, and libfoo2 has an archived advisory for
+		// one of libfoo's live advisories.
+		archived, err := adv2.NewIndex(ctx, memfs.New(os.DirFS("testdata/carry/archive")))
+		require.NoError(t, err)
+
+		result, err := Carry(ctx, CarryOptions{AdvisoryDocs: index, ArchivedDocs: archived, From: "libfoo", To: []string{"libfoo2"}, Now: now})
+		require.NoError(t, err)
+		assert.Len(t, result.Carried, 3)
+		assert.Equal(t, []CarriedAdvisory{{
+			Package:  "libfoo2",
+			SourceID: "CGA-2222-2222-2222",
+			Aliases:  []string{"CVE-2024-2222"},
+		}}, result.Skipped)
+
+		doc := document(t, index, "libfoo2")
+		assert.Len(t, doc.Advisories, 2)
+
+		archivedDoc := document(t, archived, "libfoo2")
+		require.Len(t, archivedDoc.Advisories, 2)
+		require.NoError(t, archivedDoc.Validate())
+		carried, ok := archivedDoc.Advisories.GetByVulnerability("CVE-2023-1111")
+		require.True(t, ok)
+		assert.NotEqual(t, "CGA-9999-9999-9999", carried.ID)
+
+		// The source package's archived advisories are left as they are.
+		assert.Len(t, document(t, archived, "libfoo").Advisories, 1)
+	})
+
+	t.Run("only archived", func(t *testing.T) {
+		index := newIndex(t)
+		archived, err := adv2.NewIndex(ctx, memfs.New(os.DirFS("testdata/carry/archive")))
+		require.NoError(t, err)
+
+		result, err := Carry(ctx, CarryOptions{AdvisoryDocs: index, ArchivedDocs: archived, From: "libfoo2", To: []string{"libfoo3"}, Now: now})
+		require.NoError(t, err)
+		assert.Len(t, result.Carried, 1)
+		assert.Equal(t, 0, index.Select().WhereName("libfoo3").Len())
+		assert.Len(t, document(t, archived, "libfoo3").Advisories, 1)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		index := newIndex(t)
+
+		_, err := Carry(ctx, CarryOptions{AdvisoryDocs: index, From: "libbar", To: []string{"libbar2"}, Now: now})
+		assert.Error(t, err)
+
+		_, err = Carry(ctx, CarryOptions{AdvisoryDocs: index, From: "libfoo", To: []string{"libfoo"}, Now: now})
+		assert.Error(t, err)
+	})
+}
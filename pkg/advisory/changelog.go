@@ -0,0 +1,189 @@
+package advisory
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+)
+
+// ChangelogEntry is an advisory that changed, as described in a Changelog.
+type ChangelogEntry struct {
+	// Package is the name of the package the advisory is for.
+	Package string
+
+	// Advisory is the advisory after the change.
+	Advisory v2.Advisory
+
+	// Event is the latest of the events added to the advisory, which determines
+	// the entry's section of the changelog.
+	Event v2.Event
+
+	// New is true if the advisory was created by the change.
+	New bool
+}
+
+// Changelog is a digest of the changes to advisory data, for people rather than
+// machines. Each advisory that gained events is in one section, determined by
+// the latest of those events.
+type Changelog struct {
+	// Fixes are the advisories that were fixed.
+	Fixes []ChangelogEntry
+
+	// FalsePositives are the advisories that were determined to be false
+	// positives.
+	FalsePositives []ChangelogEntry
+
+	// FixesNotPlanned are the advisories that won't be fixed.
+	FixesNotPlanned []ChangelogEntry
+
+	// Investigations are the advisories that were created or updated without
+	// being concluded, e.g. with a detection or a true positive determination.
+	Investigations []ChangelogEntry
+}
+
+// NewChangelog returns the changelog of the changes described by diff. Changes
+// that don't add events (e.g. new aliases, or removed advisories) aren't
+// included.
+func NewChangelog(diff IndexDiffResult) Changelog {
+	var c Changelog
+
+	for _, doc := range diff.Added {
+		for _, adv := range doc.Advisories {
+			c.add(doc.Name(), adv, adv.Events, true)
+		}
+	}
+
+	for _, doc := range diff.Modified {
+		for _, adv := range doc.Added {
+			c.add(doc.Name, adv, adv.Events, true)
+		}
+		for _, adv := range doc.Modified {
+			c.add(doc.Name, adv.Added, adv.AddedEvents, false)
+		}
+	}
+
+	for _, entries := range []*[]ChangelogEntry{&c.Fixes, &c.FalsePositives, &c.FixesNotPlanned, &c.Investigations} {
+		sort.Slice(*entries, func(i, j int) bool {
+			a, b := (*entries)[i], (*entries)[j]
+			if a.Package != b.Package {
+				return a.Package < b.Package
+			}
+			return a.Advisory.ID < b.Advisory.ID
+		})
+	}
+
+	return c
+}
+
+func (c *Changelog) add(pkg string, adv v2.Advisory, addedEvents []v2.Event, isNew bool) {
+	if len(addedEvents) == 0 {
+		return
+	}
+
+	latest := v2.Advisory{Events: addedEvents}.Latest()
+	entry := ChangelogEntry{Package: pkg, Advisory: adv, Event: latest, New: isNew}
+
+	switch latest.Type {
+	case v2.EventTypeFixed:
+		c.Fixes = append(c.Fixes, entry)
+	case v2.EventTypeFalsePositiveDetermination:
+		c.FalsePositives = append(c.FalsePositives, entry)
+	case v2.EventTypeFixNotPlanned:
+		c.FixesNotPlanned = append(c.FixesNotPlanned, entry)
+	default:
+		c.Investigations = append(c.Investigations, entry)
+	}
+}
+
+// Len returns the number of advisories in the changelog.
+func (c Changelog) Len() int {
+	return len(c.Fixes) + len(c.FalsePositives) + len(c.FixesNotPlanned) + len(c.Investigations)
+}
+
+// WriteMarkdown writes the changelog to w as a Markdown document with the given
+// title, with a table for each non-empty section.
+func (c Changelog) WriteMarkdown(w io.Writer, title string) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+
+	if c.Len() == 0 {
+		sb.WriteString("No advisories changed.\n")
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+
+	fmt.Fprintf(
+		&sb,
+		"%d advisories changed: %d fixed, %d false positives, %d fixes not planned, %d under investigation.\n",
+		c.Len(), len(c.Fixes), len(c.FalsePositives), len(c.FixesNotPlanned), len(c.Investigations),
+	)
+
+	writeChangelogSection(&sb, "Fixes", []string{"Fixed version"}, c.Fixes, func(e ChangelogEntry) []string {
+		version := ""
+		if d, ok := e.Event.Data.(v2.Fixed); ok {
+			version = d.FixedVersion
+		}
+		return []string{version}
+	})
+
+	writeChangelogSection(&sb, "False positives", []string{"Reason", "Note"}, c.FalsePositives, func(e ChangelogEntry) []string {
+		reason := ""
+		if d, ok := e.Event.Data.(v2.FalsePositiveDetermination); ok {
+			reason = d.Type
+		}
+		return []string{reason, e.Event.Note()}
+	})
+
+	writeChangelogSection(&sb, "Fixes not planned", []string{"Note"}, c.FixesNotPlanned, func(e ChangelogEntry) []string {
+		return []string{e.Event.Note()}
+	})
+
+	writeChangelogSection(&sb, "Investigations", []string{"Status", "Note"}, c.Investigations, func(e ChangelogEntry) []string {
+		status := e.Event.Type
+		if e.New {
+			status += " (new)"
+		}
+		return []string{status, e.Event.Note()}
+	})
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeChangelogSection(sb *strings.Builder, heading string, columns []string, entries []ChangelogEntry, cells func(ChangelogEntry) []string) {
+	if len(entries) == 0 {
+		return
+	}
+
+	columns = append([]string{"Package", "Vulnerability"}, columns...)
+
+	fmt.Fprintf(sb, "\n## %s\n\n", heading)
+	fmt.Fprintf(sb, "| %s |\n", strings.Join(columns, " | "))
+	fmt.Fprintf(sb, "|%s\n", strings.Repeat(" --- |", len(columns)))
+
+	for _, e := range entries {
+		row := append([]string{e.Package, changelogVulnerability(e.Advisory)}, cells(e)...)
+		for i := range row {
+			row[i] = escapeMarkdownTableCell(row[i])
+		}
+		fmt.Fprintf(sb, "| %s |\n", strings.Join(row, " | "))
+	}
+}
+
+// changelogVulnerability returns the advisory's aliases, which are the IDs that
+// readers of a changelog are most likely to recognize, followed by its ID.
+func changelogVulnerability(adv v2.Advisory) string {
+	if len(adv.Aliases) == 0 {
+		return adv.ID
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(adv.Aliases, ", "), adv.ID)
+}
+
+func escapeMarkdownTableCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
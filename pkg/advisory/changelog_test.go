@@ -0,0 +1,93 @@
+package advisory
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelog(t *testing.T) {
+	day := func(d int) v2.Timestamp {
+		return v2.Timestamp(time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC))
+	}
+	detection := v2.Event{Timestamp: day(1), Type: v2.EventTypeDetection, Data: v2.Detection{Type: v2.DetectionTypeManual}}
+
+	base := v2.Document{
+		SchemaVersion: v2.SchemaVersion,
+		Package:       v2.Package{Name: "ko"},
+		Advisories: v2.Advisories{
+			{ID: "CGA-2222-2222-2222", Aliases: []string{"CVE-2024-2222"}, Events: []v2.Event{detection}},
+			{ID: "CGA-3333-3333-3333", Aliases: []string{"CVE-2024-3333"}, Events: []v2.Event{detection}},
+			{ID: "CGA-4444-4444-4444", Events: []v2.Event{detection}},
+		},
+	}
+
+	head := base
+	head.Advisories = v2.Advisories{
+		{ID: "CGA-2222-2222-2222", Aliases: []string{"CVE-2024-2222"}, Events: []v2.Event{
+			detection,
+			{Timestamp: day(2), Type: v2.EventTypeTruePositiveDetermination},
+			{Timestamp: day(3), Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: "0.15.2-r1"}},
+		}},
+		{ID: "CGA-3333-3333-3333", Aliases: []string{"CVE-2024-3333", "GHSA-2222-3333-4444"}, Events: []v2.Event{detection}},
+		{ID: "CGA-4444-4444-4444", Events: []v2.Event{
+			detection,
+			{Timestamp: day(2), Type: v2.EventTypeFalsePositiveDetermination, Data: v2.FalsePositiveDetermination{
+				Type: v2.FPTypeVulnerableCodeNotIncludedInPackage,
+				Note: "The vulnerable\ncode | isn't built.",
+			}},
+		}},
+		{ID: "CGA-5555-5555-5555", Aliases: []string{"CVE-2024-5555"}, Events: []v2.Event{
+			{Timestamp: day(4), Type: v2.EventTypeTruePositiveDetermination, Data: v2.TruePositiveDetermination{Note: "Reachable."}},
+		}},
+	}
+
+	added := v2.Document{
+		SchemaVersion: v2.SchemaVersion,
+		Package:       v2.Package{Name: "crane"},
+		Advisories: v2.Advisories{
+			{ID: "CGA-6666-6666-6666", Aliases: []string{"CVE-2024-6666"}, Events: []v2.Event{
+				{Timestamp: day(5), Type: v2.EventTypeFixNotPlanned, Data: v2.FixNotPlanned{Note: "Upstream is archived."}},
+			}},
+		},
+	}
+
+	c := NewChangelog(IndexDiffResult{
+		Added:    []v2.Document{added},
+		Modified: []DocumentDiffResult{documentDiff(base, head)},
+	})
+
+	ids := func(entries []ChangelogEntry) []string {
+		var ids []string
+		for _, e := range entries {
+			ids = append(ids, e.Advisory.ID)
+		}
+		return ids
+	}
+
+	// The alias-only change to CGA-3333-3333-3333 isn't included.
+	assert.Equal(t, 4, c.Len())
+	assert.Equal(t, []string{"CGA-2222-2222-2222"}, ids(c.Fixes))
+	assert.Equal(t, []string{"CGA-4444-4444-4444"}, ids(c.FalsePositives))
+	assert.Equal(t, []string{"CGA-6666-6666-6666"}, ids(c.FixesNotPlanned))
+	assert.Equal(t, []string{"CGA-5555-5555-5555"}, ids(c.Investigations))
+	assert.True(t, c.Investigations[0].New)
+
+	var sb strings.Builder
+	require.NoError(t, c.WriteMarkdown(&sb, "Security changelog"))
+	md := sb.String()
+
+	assert.True(t, strings.HasPrefix(md, "# Security changelog\n\n4 advisories changed: 1 fixed, 1 false positives, 1 fixes not planned, 1 under investigation.\n"))
+	assert.Contains(t, md, "## Fixes\n\n| Package | Vulnerability | Fixed version |\n| --- | --- | --- |\n| ko | CVE-2024-2222 (CGA-2222-2222-2222) | 0.15.2-r1 |\n")
+	assert.Contains(t, md, `| ko | CGA-4444-4444-4444 | vulnerable-code-not-included-in-package | The vulnerable code \| isn't built. |`)
+	assert.Contains(t, md, "| crane | CVE-2024-6666 (CGA-6666-6666-6666) | Upstream is archived. |")
+	assert.Contains(t, md, "| ko | CVE-2024-5555 (CGA-5555-5555-5555) | true-positive-determination (new) | Reachable. |")
+
+	sb.Reset()
+	require.NoError(t, Changelog{}.WriteMarkdown(&sb, "Empty"))
+	assert.Equal(t, "# Empty\n\nNo advisories changed.\n", sb.String())
+}
@@ -0,0 +1,539 @@
+package advisory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	cgaid "github.com/chainguard-dev/advisory-schema/pkg/advisory"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/advisory-schema/pkg/vuln"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory/secdb"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+)
+
+// The statuses of a vulnerability in another distro's security tracker.
+const (
+	// ExternalStatusFixed means the other distro has fixed the vulnerability.
+	ExternalStatusFixed = "fixed"
+
+	// ExternalStatusNotAffected means the other distro has determined that its
+	// package isn't affected by the vulnerability.
+	ExternalStatusNotAffected = "not-affected"
+
+	// ExternalStatusTriaged means the other distro is tracking the vulnerability,
+	// but hasn't resolved it.
+	ExternalStatusTriaged = "triaged"
+)
+
+// ExternalStatus is the status of a vulnerability for a package in another
+// distro's security tracker.
+type ExternalStatus struct {
+	// Tracker is the name of the tracker, e.g. "alpine".
+	Tracker string `json:"tracker"`
+
+	// Package is the name of the package in the other distro.
+	Package string `json:"package"`
+
+	// Vulnerability is the vulnerability's ID, preferably a CVE ID.
+	Vulnerability string `json:"vulnerability"`
+
+	// Aliases are the vulnerability's other IDs known to the tracker.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Status is one of ExternalStatusFixed, ExternalStatusNotAffected, or
+	// ExternalStatusTriaged.
+	Status string `json:"status"`
+
+	// FixedVersion is the other distro's version of the package that fixed the
+	// vulnerability, if known.
+	FixedVersion string `json:"fixedVersion,omitempty"`
+}
+
+// ExternalTracker is another distro's security tracker.
+type ExternalTracker interface {
+	// Name returns the tracker's name, e.g. "alpine".
+	Name() string
+
+	// Statuses returns the tracker's vulnerability statuses for the given
+	// packages. Packages the tracker doesn't know about are ignored.
+	Statuses(ctx context.Context, packages []string) ([]ExternalStatus, error)
+}
+
+// CrossCheckOptions configures the CrossCheck operation.
+type CrossCheckOptions struct {
+	// AdvisoryDocs is the Index of advisory documents on which to operate.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// Packages are the names of the packages to check. Packages are compared with
+	// the other distros' packages of the same name.
+	Packages []string
+
+	// Trackers are the other distros' security trackers to compare against.
+	Trackers []ExternalTracker
+}
+
+// CrossCheckFinding is a vulnerability that other distros have fixed or triaged
+// for a package, but that none of the package's advisories are about.
+type CrossCheckFinding struct {
+	// Package is the name of the package.
+	Package string `json:"package"`
+
+	// Vulnerability is the vulnerability's ID, preferably a CVE ID.
+	Vulnerability string `json:"vulnerability"`
+
+	// Statuses are the vulnerability's statuses in the other distros' trackers,
+	// ordered by tracker.
+	Statuses []ExternalStatus `json:"statuses"`
+}
+
+// CrossCheck compares the packages' advisories to other distros' security
+// trackers, and returns the vulnerabilities that the trackers know about for a
+// package, but that aren't the ID or an alias of any of the package's
+// advisories.
+func CrossCheck(ctx context.Context, opts CrossCheckOptions) ([]CrossCheckFinding, error) {
+	if opts.AdvisoryDocs == nil {
+		return nil, errors.New("advisory documents must be provided")
+	}
+
+	log := clog.FromContext(ctx)
+
+	known := make(map[string]map[string]struct{}, len(opts.Packages))
+	for _, pkg := range opts.Packages {
+		ids := make(map[string]struct{})
+		for _, doc := range opts.AdvisoryDocs.Select().WhereName(pkg).Configurations() {
+			for _, adv := range doc.Advisories {
+				ids[adv.ID] = struct{}{}
+				for _, alias := range adv.Aliases {
+					ids[alias] = struct{}{}
+				}
+			}
+		}
+		known[pkg] = ids
+	}
+
+	findingsByKey := make(map[string]*CrossCheckFinding)
+	for _, t := range opts.Trackers {
+		statuses, err := t.Statuses(ctx, opts.Packages)
+		if err != nil {
+			return nil, fmt.Errorf("getting statuses from %s tracker: %w", t.Name(), err)
+		}
+		log.Debug("got statuses from tracker", "tracker", t.Name(), "count", len(statuses))
+
+		for _, s := range statuses {
+			ids, ok := known[s.Package]
+			if !ok {
+				continue
+			}
+			if slices.ContainsFunc(append([]string{s.Vulnerability}, s.Aliases...), func(id string) bool {
+				_, ok := ids[id]
+				return ok
+			}) {
+				continue
+			}
+
+			key := s.Package + "/" + s.Vulnerability
+			f, ok := findingsByKey[key]
+			if !ok {
+				f = &CrossCheckFinding{Package: s.Package, Vulnerability: s.Vulnerability}
+				findingsByKey[key] = f
+			}
+			f.Statuses = append(f.Statuses, s)
+		}
+	}
+
+	findings := make([]CrossCheckFinding, 0, len(findingsByKey))
+	for _, f := range findingsByKey {
+		sort.SliceStable(f.Statuses, func(i, j int) bool {
+			return f.Statuses[i].Tracker < f.Statuses[j].Tracker
+		})
+		findings = append(findings, *f)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Package != findings[j].Package {
+			return findings[i].Package < findings[j].Package
+		}
+		return findings[i].Vulnerability < findings[j].Vulnerability
+	})
+
+	return findings, nil
+}
+
+// externalVulnerabilityIDs returns the given IDs that are valid advisory
+// aliases, with a CVE ID first if there is one, for use as the Vulnerability and
+// Aliases of an ExternalStatus. It returns false if none of the IDs are valid.
+func externalVulnerabilityIDs(ids []string) (id string, aliases []string, ok bool) {
+	var valid []string
+	for _, id := range ids {
+		if cgaid.RegexCGA.MatchString(id) || vuln.ValidateID(id) != nil || slices.Contains(valid, id) {
+			continue
+		}
+		valid = append(valid, id)
+	}
+	if len(valid) == 0 {
+		return "", nil, false
+	}
+
+	if i := slices.IndexFunc(valid, func(id string) bool { return strings.HasPrefix(id, "CVE-") }); i > 0 {
+		valid[0], valid[i] = valid[i], valid[0]
+	}
+
+	if len(valid) > 1 {
+		aliases = valid[1:]
+	}
+	return valid[0], aliases, true
+}
+
+// AlpineSecDBTracker is an ExternalTracker for Alpine-style security databases,
+// such as those published at https://secdb.alpinelinux.org.
+type AlpineSecDBTracker struct {
+	// Databases are the security databases, e.g. Alpine's "main" and "community"
+	// databases.
+	Databases []secdb.Database
+}
+
+func (AlpineSecDBTracker) Name() string {
+	return "alpine"
+}
+
+// Statuses returns the vulnerabilities in the packages' secfixes. A
+// vulnerability listed under a version is fixed in that version, and one listed
+// under secdb.NAK doesn't affect the package. If a vulnerability is listed under
+// several versions, the highest version is used.
+func (t AlpineSecDBTracker) Statuses(_ context.Context, packages []string) ([]ExternalStatus, error) {
+	selected := make(map[string]struct{}, len(packages))
+	for _, pkg := range packages {
+		selected[pkg] = struct{}{}
+	}
+
+	var statuses []ExternalStatus
+	for _, db := range t.Databases {
+		for _, entry := range db.Packages {
+			if _, ok := selected[entry.Pkg.Name]; !ok {
+				continue
+			}
+
+			byID := make(map[string]ExternalStatus)
+			for version, vulns := range entry.Pkg.Secfixes {
+				for _, v := range vulns {
+					id, aliases, ok := externalVulnerabilityIDs(strings.Fields(v))
+					if !ok {
+						continue
+					}
+
+					s := ExternalStatus{
+						Tracker:       t.Name(),
+						Package:       entry.Pkg.Name,
+						Vulnerability: id,
+						Aliases:       aliases,
+						Status:        ExternalStatusFixed,
+						FixedVersion:  version,
+					}
+					if version == secdb.NAK {
+						s.Status = ExternalStatusNotAffected
+						s.FixedVersion = ""
+					}
+
+					if existing, ok := byID[id]; ok && !alpineStatusSupersedes(s, existing) {
+						continue
+					}
+					byID[id] = s
+				}
+			}
+
+			ids := make([]string, 0, len(byID))
+			for id := range byID {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				statuses = append(statuses, byID[id])
+			}
+		}
+	}
+
+	return statuses, nil
+}
+
+// alpineStatusSupersedes reports whether status a takes precedence over status
+// b when a vulnerability is listed more than once for a package.
+func alpineStatusSupersedes(a, b ExternalStatus) bool {
+	if a.Status != ExternalStatusFixed || b.Status != ExternalStatusFixed {
+		return a.Status == ExternalStatusFixed
+	}
+
+	va, err := apk.ParseVersion(a.FixedVersion)
+	if err != nil {
+		return false
+	}
+	vb, err := apk.ParseVersion(b.FixedVersion)
+	if err != nil {
+		return true
+	}
+	return apk.CompareVersions(va, vb) > 0
+}
+
+// DebianTrackerURL is the URL of the Debian security tracker's JSON data.
+const DebianTrackerURL = "https://security-tracker.debian.org/tracker/data/json"
+
+// DebianTracker is an ExternalTracker for the Debian security tracker, whose
+// packages are Debian source packages.
+type DebianTracker struct {
+	// data is the tracker's data, by source package, then by vulnerability ID.
+	data map[string]map[string]debianTrackerVulnerability
+}
+
+type debianTrackerVulnerability struct {
+	Releases map[string]struct {
+		Status       string `json:"status"`
+		FixedVersion string `json:"fixed_version"`
+	} `json:"releases"`
+}
+
+// NewDebianTracker returns a DebianTracker for the JSON data read from r, in
+// the format served at DebianTrackerURL.
+func NewDebianTracker(r io.Reader) (*DebianTracker, error) {
+	t := &DebianTracker{}
+	if err := json.NewDecoder(r).Decode(&t.data); err != nil {
+		return nil, fmt.Errorf("decoding Debian security tracker data: %w", err)
+	}
+	return t, nil
+}
+
+func (*DebianTracker) Name() string {
+	return "debian"
+}
+
+// Statuses returns the vulnerabilities the tracker lists for the packages. A
+// vulnerability is fixed if it's resolved in any Debian release (preferring the
+// fixed version in "sid"), and doesn't affect the package if it was resolved
+// in version "0". Otherwise, it's triaged.
+func (t *DebianTracker) Statuses(_ context.Context, packages []string) ([]ExternalStatus, error) {
+	var statuses []ExternalStatus
+	for _, pkg := range packages {
+		vulns := t.data[pkg]
+
+		ids := make([]string, 0, len(vulns))
+		for id := range vulns {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			if _, _, ok := externalVulnerabilityIDs([]string{id}); !ok {
+				// E.g. Debian's "TEMP-" IDs for vulnerabilities without a CVE ID.
+				continue
+			}
+
+			releases := make([]string, 0, len(vulns[id].Releases))
+			for release := range vulns[id].Releases {
+				releases = append(releases, release)
+			}
+			sort.Slice(releases, func(i, j int) bool {
+				// "sid" first, since it has the latest fixes.
+				if (releases[i] == "sid") != (releases[j] == "sid") {
+					return releases[i] == "sid"
+				}
+				return releases[i] < releases[j]
+			})
+
+			s := ExternalStatus{
+				Tracker:       t.Name(),
+				Package:       pkg,
+				Vulnerability: id,
+				Status:        ExternalStatusTriaged,
+			}
+			for _, release := range releases {
+				r := vulns[id].Releases[release]
+				if r.Status != "resolved" {
+					continue
+				}
+				if r.FixedVersion == "0" {
+					s.Status = ExternalStatusNotAffected
+				} else {
+					s.Status = ExternalStatusFixed
+					s.FixedVersion = r.FixedVersion
+				}
+				break
+			}
+			statuses = append(statuses, s)
+		}
+	}
+
+	return statuses, nil
+}
+
+// OSVTracker is an ExternalTracker for a distro ecosystem of the OSV database
+// (https://osv.dev), such as "Ubuntu:24.04:LTS".
+type OSVTracker struct {
+	// Ecosystem is the OSV ecosystem of the distro.
+	Ecosystem string
+
+	// URL is the base URL of the OSV API. If empty, "https://api.osv.dev" is used.
+	URL string
+
+	// HTTPClient is used to query the API. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+func (t OSVTracker) Name() string {
+	return "osv:" + t.Ecosystem
+}
+
+type osvTrackerQuery struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+}
+
+type osvTrackerVulnerability struct {
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases"`
+	Upstream []string `json:"upstream"`
+	Affected []struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Events []struct {
+				Fixed string `json:"fixed"`
+			} `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+}
+
+// Statuses returns the vulnerabilities the ecosystem's OSV records list for
+// the packages. A vulnerability is fixed if its record has a fixed version for
+// the package, and triaged otherwise.
+func (t OSVTracker) Statuses(ctx context.Context, packages []string) ([]ExternalStatus, error) {
+	base := t.URL
+	if base == "" {
+		base = "https://api.osv.dev"
+	}
+
+	var statuses []ExternalStatus
+	for start := 0; start < len(packages); start += osvTrackerBatchSize {
+		batch := packages[start:min(start+osvTrackerBatchSize, len(packages))]
+
+		batchStatuses, err := t.batchStatuses(ctx, base, batch)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, batchStatuses...)
+	}
+
+	return statuses, nil
+}
+
+// osvTrackerBatchSize is the maximum number of queries the OSV API accepts in a
+// single batch query.
+const osvTrackerBatchSize = 1000
+
+func (t OSVTracker) batchStatuses(ctx context.Context, base string, batch []string) ([]ExternalStatus, error) {
+	var req struct {
+		Queries []osvTrackerQuery `json:"queries"`
+	}
+	for _, pkg := range batch {
+		var q osvTrackerQuery
+		q.Package.Name = pkg
+		q.Package.Ecosystem = t.Ecosystem
+		req.Queries = append(req.Queries, q)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			Vulns []struct {
+				ID string `json:"id"`
+			} `json:"vulns"`
+		} `json:"results"`
+	}
+	if err := t.do(ctx, http.MethodPost, base+"/v1/querybatch", bytes.NewReader(body), &resp); err != nil {
+		return nil, fmt.Errorf("querying OSV: %w", err)
+	}
+	if len(resp.Results) != len(batch) {
+		return nil, fmt.Errorf("querying OSV: expected %d results, got %d", len(batch), len(resp.Results))
+	}
+
+	var statuses []ExternalStatus
+	for i, result := range resp.Results {
+		pkg := batch[i]
+
+		for _, v := range result.Vulns {
+			var record osvTrackerVulnerability
+			if err := t.do(ctx, http.MethodGet, base+"/v1/vulns/"+url.PathEscape(v.ID), nil, &record); err != nil {
+				return nil, fmt.Errorf("getting OSV vulnerability %s: %w", v.ID, err)
+			}
+
+			id, aliases, ok := externalVulnerabilityIDs(slices.Concat([]string{record.ID}, record.Upstream, record.Aliases))
+			if !ok {
+				continue
+			}
+
+			s := ExternalStatus{
+				Tracker:       t.Name(),
+				Package:       pkg,
+				Vulnerability: id,
+				Aliases:       aliases,
+				Status:        ExternalStatusTriaged,
+			}
+			for _, a := range record.Affected {
+				if a.Package.Name != pkg {
+					continue
+				}
+				for _, r := range a.Ranges {
+					for _, e := range r.Events {
+						if e.Fixed != "" {
+							s.Status = ExternalStatusFixed
+							s.FixedVersion = e.Fixed
+						}
+					}
+				}
+			}
+			statuses = append(statuses, s)
+		}
+	}
+
+	return statuses, nil
+}
+
+func (t OSVTracker) do(ctx context.Context, method, u string, body io.Reader, v any) error {
+	client := t.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
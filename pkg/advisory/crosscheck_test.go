@@ -0,0 +1,88 @@
+package advisory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory/secdb"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestCrossCheck(t *testing.T) {
+	ctx := context.Background()
+
+	docs, err := adv2.NewIndex(ctx, memfs.New(os.DirFS("testdata/crosscheck")))
+	require.NoError(t, err)
+
+	alpine := AlpineSecDBTracker{Databases: []secdb.Database{{
+		Packages: []secdb.PackageEntry{
+			{Pkg: secdb.Package{Name: "ko", Secfixes: secdb.Secfixes{
+				"0.15.1-r0": {"CVE-2024-1111", "CVE-2024-5555"},
+				"0.15.9-r0": {"CVE-2024-5555"},
+				"0":         {"CVE-2024-6666"},
+			}}},
+			{Pkg: secdb.Package{Name: "crane", Secfixes: secdb.Secfixes{
+				"0.19.0-r0": {"CVE-2024-7777"},
+			}}},
+		},
+	}}}
+
+	debian, err := NewDebianTracker(strings.NewReader(`{
+  "ko": {
+    "CVE-2024-5555": {"releases": {
+      "bookworm": {"status": "resolved", "fixed_version": "0.15.1-1"},
+      "sid": {"status": "resolved", "fixed_version": "0.15.2-1"}
+    }},
+    "CVE-2024-8888": {"releases": {"sid": {"status": "open"}}},
+    "TEMP-0000000-ABCDEF": {"releases": {"sid": {"status": "open"}}}
+  }
+}`))
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			_, _ = w.Write([]byte(`{"results": [{"vulns": [{"id": "UBUNTU-CVE-2024-9999"}, {"id": "GHSA-2222-3333-4444"}]}]}`))
+		case "/v1/vulns/UBUNTU-CVE-2024-9999":
+			_, _ = w.Write([]byte(`{"id": "UBUNTU-CVE-2024-9999", "upstream": ["CVE-2024-9999"], "affected": [{"package": {"name": "ko"}, "ranges": [{"events": [{"introduced": "0"}, {"fixed": "0.15.3-0ubuntu1"}]}]}]}`))
+		case "/v1/vulns/GHSA-2222-3333-4444":
+			_, _ = w.Write([]byte(`{"id": "GHSA-2222-3333-4444", "aliases": ["CVE-2024-2222"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	osv := OSVTracker{Ecosystem: "Ubuntu:24.04:LTS", URL: srv.URL}
+
+	findings, err := CrossCheck(ctx, CrossCheckOptions{
+		AdvisoryDocs: docs,
+		Packages:     []string{"ko"},
+		Trackers:     []ExternalTracker{alpine, debian, osv},
+	})
+	require.NoError(t, err)
+
+	// CVE-2024-1111 is an alias of an advisory, and the GHSA's CVE is matched by
+	// the GHSA. crane isn't checked.
+	assert.Equal(t, []CrossCheckFinding{
+		{Package: "ko", Vulnerability: "CVE-2024-5555", Statuses: []ExternalStatus{
+			{Tracker: "alpine", Package: "ko", Vulnerability: "CVE-2024-5555", Status: ExternalStatusFixed, FixedVersion: "0.15.9-r0"},
+			{Tracker: "debian", Package: "ko", Vulnerability: "CVE-2024-5555", Status: ExternalStatusFixed, FixedVersion: "0.15.2-1"},
+		}},
+		{Package: "ko", Vulnerability: "CVE-2024-6666", Statuses: []ExternalStatus{
+			{Tracker: "alpine", Package: "ko", Vulnerability: "CVE-2024-6666", Status: ExternalStatusNotAffected},
+		}},
+		{Package: "ko", Vulnerability: "CVE-2024-8888", Statuses: []ExternalStatus{
+			{Tracker: "debian", Package: "ko", Vulnerability: "CVE-2024-8888", Status: ExternalStatusTriaged},
+		}},
+		{Package: "ko", Vulnerability: "CVE-2024-9999", Statuses: []ExternalStatus{
+			{Tracker: "osv:Ubuntu:24.04:LTS", Package: "ko", Vulnerability: "CVE-2024-9999", Status: ExternalStatusFixed, FixedVersion: "0.15.3-0ubuntu1"},
+		}},
+	}, findings)
+}
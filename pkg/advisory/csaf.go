@@ -0,0 +1,253 @@
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	cgaid "github.com/chainguard-dev/advisory-schema/pkg/advisory"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/advisory-schema/pkg/vuln"
+	"github.com/chainguard-dev/clog"
+	"github.com/openvex/go-vex/pkg/csaf"
+	"github.com/package-url/packageurl-go"
+)
+
+// CSAF product statuses, see
+// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3239-vulnerabilities-property---product-status.
+const (
+	csafStatusFirstAffected      = "first_affected"
+	csafStatusFirstFixed         = "first_fixed"
+	csafStatusFixed              = "fixed"
+	csafStatusKnownAffected      = "known_affected"
+	csafStatusKnownNotAffected   = "known_not_affected"
+	csafStatusLastAffected       = "last_affected"
+	csafStatusUnderInvestigation = "under_investigation"
+)
+
+// DecodeCSAF reads a CSAF document from JSON.
+func DecodeCSAF(r io.Reader) (*csaf.CSAF, error) {
+	doc := new(csaf.CSAF)
+	if err := json.NewDecoder(r).Decode(doc); err != nil {
+		return nil, fmt.Errorf("decoding CSAF document: %w", err)
+	}
+	return doc, nil
+}
+
+// CSAFRequests translates the product statuses of a CSAF VEX document into
+// advisory requests, one for each package and vulnerability. The requests'
+// events are timestamped with the document's current release date.
+//
+// Products are matched to packages by their "purl" identification helpers,
+// which must be APK package URLs. A product that's a relationship of other
+// products (e.g. an APK installed in an image) is matched by the product it
+// references. Products that can't be matched are skipped.
+//
+// The statuses map to events as follows:
+//
+//   - known_not_affected: a false positive determination, whose type is derived
+//     from the product's VEX justification flag, and whose note is the product's
+//     "impact" threat details.
+//   - fixed, first_fixed: a fixed event, whose fixed version is the product's
+//     package URL version. Products without a version are skipped.
+//   - known_affected, first_affected, last_affected: a fix-not-planned event if
+//     the product's remediation is "no_fix_planned", and a true positive
+//     determination otherwise, with the remediation's details as the note.
+//   - under_investigation: a manual detection.
+func CSAFRequests(ctx context.Context, doc *csaf.CSAF) ([]Request, error) {
+	log := clog.FromContext(ctx)
+
+	packages := csafProductPackages(doc)
+
+	timestamp := v2.Timestamp(doc.Document.Tracking.CurrentReleaseDate)
+	if doc.Document.Tracking.CurrentReleaseDate.IsZero() {
+		timestamp = v2.Now()
+	}
+
+	var requests []Request
+	for i := range doc.Vulnerabilities {
+		v := doc.Vulnerabilities[i]
+
+		aliases := csafVulnerabilityIDs(v)
+		if len(aliases) == 0 {
+			log.Warn("skipping CSAF vulnerability without a CVE, GHSA, or Go vulnerability ID", "cve", v.CVE)
+			continue
+		}
+
+		statuses := make([]string, 0, len(v.ProductStatus))
+		for status := range v.ProductStatus {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+
+		for _, status := range statuses {
+			for _, productID := range v.ProductStatus[status] {
+				purl, ok := packages[productID]
+				if !ok {
+					log.Warn("skipping CSAF product that isn't an APK package", "productID", productID, "vulnerability", aliases[0])
+					continue
+				}
+
+				event, ok := csafEvent(v, status, productID, purl, timestamp)
+				if !ok {
+					log.Warn("skipping CSAF product status that has no corresponding event", "productID", productID, "status", status, "vulnerability", aliases[0])
+					continue
+				}
+
+				req := Request{
+					Package: purl.Name,
+					Aliases: aliases,
+					Event:   event,
+				}
+				if err := req.Validate(); err != nil {
+					return nil, fmt.Errorf("translating status %q of product %q for %s: %w", status, productID, aliases[0], err)
+				}
+
+				// The same package can be several products (e.g. when it's installed in
+				// several images), which would be redundant requests.
+				if slices.ContainsFunc(requests, func(r Request) bool {
+					return r.Package == req.Package && r.Event == req.Event && slices.Equal(r.Aliases, req.Aliases)
+				}) {
+					continue
+				}
+
+				requests = append(requests, req)
+			}
+		}
+	}
+
+	return requests, nil
+}
+
+// csafProductPackages returns the APK package URLs of the document's products,
+// by product ID.
+func csafProductPackages(doc *csaf.CSAF) map[string]packageurl.PackageURL {
+	packages := make(map[string]packageurl.PackageURL)
+
+	var walk func(branch csaf.ProductBranch)
+	walk = func(branch csaf.ProductBranch) {
+		if p := branch.Product; p.ID != "" {
+			if purl, err := packageurl.FromString(p.IdentificationHelper["purl"]); err == nil && purl.Type == "apk" {
+				packages[p.ID] = purl
+			}
+		}
+		for _, b := range branch.Branches {
+			walk(b)
+		}
+	}
+	walk(doc.ProductTree)
+
+	for _, r := range doc.ProductTree.Relationships {
+		if purl, ok := packages[r.ProductRef]; ok && r.FullProductName.ID != "" {
+			packages[r.FullProductName.ID] = purl
+		}
+	}
+
+	return packages
+}
+
+// csafVulnerabilityIDs returns the vulnerability's CVE ID and its other IDs that
+// are valid advisory aliases.
+func csafVulnerabilityIDs(v csaf.Vulnerability) []string {
+	ids := []string{v.CVE}
+	for _, id := range v.IDs {
+		ids = append(ids, id.Text)
+	}
+
+	var aliases []string
+	for _, id := range ids {
+		if id == "" || cgaid.RegexCGA.MatchString(id) || vuln.ValidateID(id) != nil {
+			continue
+		}
+		if !slices.Contains(aliases, id) {
+			aliases = append(aliases, id)
+		}
+	}
+
+	return aliases
+}
+
+func csafEvent(v csaf.Vulnerability, status, productID string, purl packageurl.PackageURL, timestamp v2.Timestamp) (v2.Event, bool) {
+	event := v2.Event{Timestamp: timestamp}
+
+	switch status {
+	case csafStatusKnownNotAffected:
+		fp := v2.FalsePositiveDetermination{Type: v2.FPTypeVulnerableCodeNotIncludedInPackage}
+		for _, f := range v.Flags {
+			if slices.Contains(f.ProductIDs, productID) {
+				fp.Type = csafFalsePositiveType(f.Label)
+				break
+			}
+		}
+		for _, t := range v.Threats {
+			if t.Category == "impact" && slices.Contains(t.ProductIDs, productID) {
+				fp.Note = t.Details
+				break
+			}
+		}
+		event.Type = v2.EventTypeFalsePositiveDetermination
+		event.Data = fp
+
+	case csafStatusFixed, csafStatusFirstFixed:
+		if purl.Version == "" {
+			return v2.Event{}, false
+		}
+		event.Type = v2.EventTypeFixed
+		event.Data = v2.Fixed{FixedVersion: purl.Version}
+
+	case csafStatusKnownAffected, csafStatusFirstAffected, csafStatusLastAffected:
+		var remediation csaf.RemediationData
+		for _, r := range v.Remediations {
+			if slices.Contains(r.ProductIDs, productID) {
+				remediation = r
+				break
+			}
+		}
+
+		if remediation.Category == "no_fix_planned" {
+			note := remediation.Details
+			if note == "" {
+				note = "No fix is planned."
+			}
+			event.Type = v2.EventTypeFixNotPlanned
+			event.Data = v2.FixNotPlanned{Note: note}
+			break
+		}
+
+		event.Type = v2.EventTypeTruePositiveDetermination
+		event.Data = v2.TruePositiveDetermination{Note: remediation.Details}
+
+	case csafStatusUnderInvestigation:
+		event.Type = v2.EventTypeDetection
+		event.Data = v2.Detection{Type: v2.DetectionTypeManual}
+
+	default:
+		return v2.Event{}, false
+	}
+
+	return event, true
+}
+
+// csafFalsePositiveType returns the false positive determination type for the
+// CSAF VEX justification flag label. This is the inverse of the VEX
+// compatibility mapping of the types.
+func csafFalsePositiveType(label string) string {
+	switch label {
+	case "component_not_present":
+		return v2.FPTypeComponentVulnerabilityMismatch
+
+	case "vulnerable_code_not_in_execute_path":
+		return v2.FPTypeVulnerableCodeNotInExecutionPath
+
+	case "vulnerable_code_cannot_be_controlled_by_adversary":
+		return v2.FPTypeVulnerableCodeCannotBeControlledByAdversary
+
+	case "inline_mitigations_already_exist":
+		return v2.FPTypeInlineMitigationsExist
+	}
+
+	return v2.FPTypeVulnerableCodeNotIncludedInPackage
+}
@@ -0,0 +1,359 @@
+package advisory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/samber/lo"
+)
+
+// The types below are the subset of CSAF 2.0
+// (https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html) used by
+// ExportCSAF. They're separate from the CSAF types used for importing, which
+// don't omit empty values, as the CSAF schema requires.
+
+type csafDocument struct {
+	Document        csafDocumentMetadata `json:"document"`
+	ProductTree     csafProductTree      `json:"product_tree"`
+	Vulnerabilities []csafVulnerability  `json:"vulnerabilities,omitempty"`
+}
+
+type csafDocumentMetadata struct {
+	Category    string        `json:"category"`
+	CSAFVersion string        `json:"csaf_version"`
+	Publisher   csafPublisher `json:"publisher"`
+	Title       string        `json:"title"`
+	Tracking    csafTracking  `json:"tracking"`
+}
+
+type csafPublisher struct {
+	Category  string `json:"category"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type csafTracking struct {
+	CurrentReleaseDate time.Time      `json:"current_release_date"`
+	Generator          csafGenerator  `json:"generator"`
+	ID                 string         `json:"id"`
+	InitialReleaseDate time.Time      `json:"initial_release_date"`
+	RevisionHistory    []csafRevision `json:"revision_history"`
+	Status             string         `json:"status"`
+	Version            string         `json:"version"`
+}
+
+type csafGenerator struct {
+	Engine struct {
+		Name string `json:"name"`
+	} `json:"engine"`
+}
+
+type csafRevision struct {
+	Date    time.Time `json:"date"`
+	Number  string    `json:"number"`
+	Summary string    `json:"summary"`
+}
+
+type csafProductTree struct {
+	Branches []csafBranch `json:"branches,omitempty"`
+}
+
+type csafBranch struct {
+	Category string       `json:"category"`
+	Name     string       `json:"name"`
+	Branches []csafBranch `json:"branches,omitempty"`
+	Product  *csafProduct `json:"product,omitempty"`
+}
+
+type csafProduct struct {
+	Name      string `json:"name"`
+	ProductID string `json:"product_id"`
+}
+
+type csafVulnerability struct {
+	CVE           string              `json:"cve,omitempty"`
+	IDs           []csafID            `json:"ids,omitempty"`
+	ProductStatus map[string][]string `json:"product_status"`
+	Flags         []csafFlag          `json:"flags,omitempty"`
+	Threats       []csafThreat        `json:"threats,omitempty"`
+	Remediations  []csafRemediation   `json:"remediations,omitempty"`
+}
+
+type csafID struct {
+	SystemName string `json:"system_name"`
+	Text       string `json:"text"`
+}
+
+type csafFlag struct {
+	Label      string   `json:"label"`
+	ProductIDs []string `json:"product_ids"`
+}
+
+type csafThreat struct {
+	Category   string   `json:"category"`
+	Details    string   `json:"details"`
+	ProductIDs []string `json:"product_ids"`
+}
+
+type csafRemediation struct {
+	Category   string   `json:"category"`
+	Details    string   `json:"details"`
+	ProductIDs []string `json:"product_ids"`
+}
+
+// ExportCSAF returns a reader of a CSAF VEX document with a vulnerability for
+// each advisory, about the advisory's package in the ecosystem given by
+// opts.Ecosystem, which is also the document's publisher. opts.
+// PublisherNamespace is the publisher's namespace, as CSAF requires.
+//
+// The products are version ranges of the packages, as "vers" specifiers (see
+// AffectedRange.Vers). The product statuses by the advisory's latest event are:
+//
+//   - fixed: "known_affected" for each of the advisory's AffectedRanges, with a
+//     vendor fix remediation, and "fixed" for the fixed version and later.
+//   - false-positive-determination: "known_not_affected" for all versions, with
+//     a justification flag, and the note as the impact.
+//   - true-positive-determination, pending-upstream-fix, fix-not-planned:
+//     "known_affected" for each of the advisory's AffectedRanges, with a
+//     remediation saying that no fix is available, or planned.
+//   - detection: "under_investigation" for all versions.
+//
+// Advisories whose latest event is "analysis-not-planned" aren't exported.
+func ExportCSAF(ctx context.Context, opts ExportOptions) (io.Reader, error) {
+	if opts.Ecosystem == "" {
+		return nil, fmt.Errorf("an ecosystem is required for CSAF export")
+	}
+	if opts.PublisherNamespace == "" {
+		return nil, fmt.Errorf("a publisher namespace is required for CSAF export")
+	}
+
+	// The products (by ID) of each package, and the vulnerabilities by advisory ID.
+	products := make(map[string]map[string]csafProduct)
+	vulnerabilities := make(map[string]csafVulnerability)
+	var released time.Time
+
+	for _, index := range opts.AdvisoryDocIndices {
+		for _, doc := range opts.documents(index) {
+			name := doc.Package.Name
+
+			for _, adv := range doc.Advisories {
+				if len(adv.Events) == 0 {
+					continue
+				}
+				latest := adv.Latest()
+
+				statuses, err := csafProductStatuses(ctx, opts.PackageVersionTimeline, name, adv)
+				if err != nil {
+					return nil, err
+				}
+				if len(statuses) == 0 {
+					continue
+				}
+
+				v := csafVulnerabilityForAdvisory(adv, opts.Ecosystem)
+				v.ProductStatus = make(map[string][]string)
+				for _, s := range statuses {
+					product := csafRangeProduct(name, s.versions)
+					if products[name] == nil {
+						products[name] = make(map[string]csafProduct)
+					}
+					products[name][product.ProductID] = product
+
+					v.ProductStatus[s.status] = append(v.ProductStatus[s.status], product.ProductID)
+					ids := []string{product.ProductID}
+					if s.flag != "" {
+						v.Flags = append(v.Flags, csafFlag{Label: s.flag, ProductIDs: ids})
+					}
+					if s.impact != "" {
+						v.Threats = append(v.Threats, csafThreat{Category: "impact", Details: s.impact, ProductIDs: ids})
+					}
+					if s.remediation != "" {
+						v.Remediations = append(v.Remediations, csafRemediation{Category: s.remediation, Details: s.remediationDetails, ProductIDs: ids})
+					}
+				}
+
+				vulnerabilities[adv.ID] = v
+				if t := time.Time(latest.Timestamp); t.After(released) {
+					released = t
+				}
+			}
+		}
+	}
+
+	if released.IsZero() {
+		released = time.Now()
+	}
+	released = released.UTC()
+
+	doc := csafDocument{
+		Document: csafDocumentMetadata{
+			Category:    "csaf_vex",
+			CSAFVersion: "2.0",
+			Publisher: csafPublisher{
+				Category:  "vendor",
+				Name:      opts.Ecosystem,
+				Namespace: opts.PublisherNamespace,
+			},
+			Title: fmt.Sprintf("%s security advisories", opts.Ecosystem),
+			Tracking: csafTracking{
+				CurrentReleaseDate: released,
+				ID:                 fmt.Sprintf("%s-vex-%s", strings.ToLower(opts.Ecosystem), released.Format("20060102T150405Z")),
+				InitialReleaseDate: released,
+				RevisionHistory: []csafRevision{{
+					Date:    released,
+					Number:  "1",
+					Summary: "Exported from the advisory data.",
+				}},
+				Status:  "final",
+				Version: "1",
+			},
+		},
+	}
+	doc.Document.Tracking.Generator.Engine.Name = "wolfictl"
+
+	vendor := csafBranch{Category: "vendor", Name: opts.Ecosystem}
+	names := lo.Keys(products)
+	sort.Strings(names)
+	for _, name := range names {
+		pkg := csafBranch{Category: "product_name", Name: name}
+		ids := lo.Keys(products[name])
+		sort.Strings(ids)
+		for _, id := range ids {
+			product := products[name][id]
+			pkg.Branches = append(pkg.Branches, csafBranch{
+				Category: "product_version_range",
+				Name:     strings.TrimPrefix(product.Name, name+" "),
+				Product:  &product,
+			})
+		}
+		vendor.Branches = append(vendor.Branches, pkg)
+	}
+	if len(vendor.Branches) > 0 {
+		doc.ProductTree.Branches = []csafBranch{vendor}
+	}
+
+	ids := lo.Keys(vulnerabilities)
+	sort.Strings(ids)
+	for _, id := range ids {
+		doc.Vulnerabilities = append(doc.Vulnerabilities, vulnerabilities[id])
+	}
+
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encoding CSAF document: %w", err)
+	}
+
+	return buf, nil
+}
+
+// csafProductStatus is the status of a version range of a package.
+type csafProductStatus struct {
+	status   string
+	versions AffectedRange
+
+	flag, impact                    string
+	remediation, remediationDetails string
+}
+
+// csafProductStatuses returns the statuses of the version ranges of the package
+// for the advisory (see ExportCSAF).
+func csafProductStatuses(ctx context.Context, timeline PackageVersionTimeline, pkgName string, adv v2.Advisory) ([]csafProductStatus, error) {
+	latest := adv.Latest()
+	allVersions := AffectedRange{Introduced: "0"}
+
+	var remediation, details string
+	switch latest.Type {
+	case v2.EventTypeFalsePositiveDetermination:
+		s := csafProductStatus{status: csafStatusKnownNotAffected, versions: allVersions, flag: string(openVEXJustification(""))}
+		if d, ok := latest.Data.(v2.FalsePositiveDetermination); ok {
+			s.flag = string(openVEXJustification(d.Type))
+			s.impact = d.Note
+		}
+		return []csafProductStatus{s}, nil
+
+	case v2.EventTypeDetection:
+		return []csafProductStatus{{status: csafStatusUnderInvestigation, versions: allVersions}}, nil
+
+	case v2.EventTypeFixed:
+		remediation = "vendor_fix"
+
+	case v2.EventTypeTruePositiveDetermination, v2.EventTypePendingUpstreamFix:
+		remediation, details = "none_available", "No fix is available yet."
+
+	case v2.EventTypeFixNotPlanned:
+		remediation, details = "no_fix_planned", "No fix is planned."
+		if d, ok := latest.Data.(v2.FixNotPlanned); ok && d.Note != "" {
+			details = d.Note
+		}
+
+	default:
+		return nil, nil
+	}
+
+	ranges, err := AffectedRanges(ctx, timeline, pkgName, adv)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []csafProductStatus
+	for _, r := range ranges {
+		s := csafProductStatus{status: csafStatusKnownAffected, versions: r, remediation: remediation, remediationDetails: details}
+		if r.Fixed != "" {
+			s.remediation = "vendor_fix"
+			s.remediationDetails = fmt.Sprintf("Upgrade to version %s or later.", r.Fixed)
+		}
+		statuses = append(statuses, s)
+	}
+
+	if latest.Type == v2.EventTypeFixed && len(ranges) > 0 {
+		fixed := ranges[len(ranges)-1].Fixed
+		statuses = append(statuses, csafProductStatus{status: csafStatusFixed, versions: AffectedRange{Introduced: fixed}})
+	}
+
+	return statuses, nil
+}
+
+// csafRangeProduct returns the product for the version range of the package.
+func csafRangeProduct(pkgName string, r AffectedRange) csafProduct {
+	vers := r.Vers()
+	return csafProduct{
+		Name:      fmt.Sprintf("%s %s", pkgName, vers),
+		ProductID: fmt.Sprintf("%s:%s", pkgName, vers),
+	}
+}
+
+// csafVulnerabilityForAdvisory identifies the advisory's vulnerability by its CVE
+// ID, if it has one, and its other IDs, including the advisory's ID.
+func csafVulnerabilityForAdvisory(adv v2.Advisory, ecosystem string) csafVulnerability {
+	var v csafVulnerability
+	if i := slices.IndexFunc(adv.Aliases, func(id string) bool { return strings.HasPrefix(id, "CVE-") }); i >= 0 {
+		v.CVE = adv.Aliases[i]
+	}
+
+	v.IDs = append(v.IDs, csafID{SystemName: fmt.Sprintf("%s advisory", ecosystem), Text: adv.ID})
+	for _, alias := range adv.Aliases {
+		if alias == v.CVE {
+			continue
+		}
+		systemName := "Vulnerability ID"
+		switch {
+		case strings.HasPrefix(alias, "GHSA-"):
+			systemName = "GitHub Security Advisory"
+		case strings.HasPrefix(alias, "GO-"):
+			systemName = "Go Vulnerability Database"
+		}
+		v.IDs = append(v.IDs, csafID{SystemName: systemName, Text: alias})
+	}
+
+	return v
+}
@@ -0,0 +1,95 @@
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+func TestExportCSAF(t *testing.T) {
+	advisoryDocs, err := adv2.NewIndex(context.Background(), rwos.DirFS("./testdata/export/advisories"))
+	require.NoError(t, err)
+
+	opts := ExportOptions{
+		AdvisoryDocIndices: []*configs.Index[v2.Document]{advisoryDocs},
+		Ecosystem:          "Wolfi",
+		PublisherNamespace: "https://github.com/wolfi-dev",
+	}
+
+	t.Run("all packages", func(t *testing.T) {
+		doc := exportCSAF(t, opts)
+		assert.Equal(t, "csaf_vex", doc.Document.Category)
+		assert.Equal(t, "https://github.com/wolfi-dev", doc.Document.Publisher.Namespace)
+		assert.Len(t, doc.Vulnerabilities, 22)
+
+		require.Len(t, doc.ProductTree.Branches, 1)
+		productIDs := make(map[string]bool)
+		for _, pkg := range doc.ProductTree.Branches[0].Branches {
+			for _, b := range pkg.Branches {
+				productIDs[b.Product.ProductID] = true
+			}
+		}
+
+		// Each product with a status is in the product tree.
+		for _, v := range doc.Vulnerabilities {
+			for _, ids := range v.ProductStatus {
+				for _, id := range ids {
+					assert.True(t, productIDs[id], "product %q isn't in the product tree", id)
+				}
+			}
+		}
+
+		fixed := csafVulnerabilityByCVE(t, doc, "CVE-2020-8927")
+		assert.Equal(t, []string{"brotli:vers:apk/<1.0.9-r0"}, fixed.ProductStatus[csafStatusKnownAffected])
+		assert.Equal(t, []string{"brotli:vers:apk/>=1.0.9-r0"}, fixed.ProductStatus[csafStatusFixed])
+		require.Len(t, fixed.Remediations, 1)
+		assert.Equal(t, "vendor_fix", fixed.Remediations[0].Category)
+		assert.Contains(t, fixed.IDs, csafID{SystemName: "Wolfi advisory", Text: "CGA-37qj-pjrf-fmrw"})
+
+		notAffected := csafVulnerabilityByCVE(t, doc, "CVE-2023-0466")
+		assert.Equal(t, []string{"openssl:vers:apk/*"}, notAffected.ProductStatus[csafStatusKnownNotAffected])
+		require.Len(t, notAffected.Flags, 1)
+		assert.Equal(t, "vulnerable_code_not_present", notAffected.Flags[0].Label)
+		require.Len(t, notAffected.Threats, 1)
+		assert.Contains(t, notAffected.Threats[0].Details, "documentation not matching function behavior")
+	})
+
+	t.Run("no publisher namespace", func(t *testing.T) {
+		_, err := ExportCSAF(context.Background(), ExportOptions{AdvisoryDocIndices: opts.AdvisoryDocIndices, Ecosystem: "Wolfi"})
+		assert.Error(t, err)
+	})
+}
+
+func exportCSAF(t *testing.T, opts ExportOptions) *csafDocument {
+	t.Helper()
+
+	r, err := ExportCSAF(context.Background(), opts)
+	require.NoError(t, err)
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	doc := new(csafDocument)
+	require.NoError(t, json.Unmarshal(b, doc))
+	return doc
+}
+
+func csafVulnerabilityByCVE(t *testing.T, doc *csafDocument, cve string) csafVulnerability {
+	t.Helper()
+
+	for _, v := range doc.Vulnerabilities {
+		if v.CVE == cve {
+			return v
+		}
+	}
+	require.Failf(t, "vulnerability not found", "no vulnerability for %s", cve)
+	return csafVulnerability{}
+}
@@ -0,0 +1,58 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSAFRequests(t *testing.T) {
+	f, err := os.Open("testdata/csaf/triage.json")
+	require.NoError(t, err)
+	defer f.Close()
+
+	doc, err := DecodeCSAF(f)
+	require.NoError(t, err)
+
+	requests, err := CSAFRequests(context.Background(), doc)
+	require.NoError(t, err)
+
+	timestamp := v2.Timestamp(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+	assert.Equal(t, []Request{
+		{
+			Package: "curl",
+			Aliases: []string{"CVE-2024-2398", "GHSA-2222-3333-4444"},
+			Event: v2.Event{
+				Timestamp: timestamp,
+				Type:      v2.EventTypeFixed,
+				Data:      v2.Fixed{FixedVersion: "8.6.0-r0"},
+			},
+		},
+		{
+			Package: "openssl",
+			Aliases: []string{"CVE-2024-2511"},
+			Event: v2.Event{
+				Timestamp: timestamp,
+				Type:      v2.EventTypeFalsePositiveDetermination,
+				Data: v2.FalsePositiveDetermination{
+					Type: v2.FPTypeVulnerableCodeNotInExecutionPath,
+					Note: "example-app doesn't use TLS session resumption.",
+				},
+			},
+		},
+		{
+			Package: "zlib",
+			Aliases: []string{"CVE-2023-45853"},
+			Event: v2.Event{
+				Timestamp: timestamp,
+				Type:      v2.EventTypeFixNotPlanned,
+				Data:      v2.FixNotPlanned{Note: "The vulnerable minizip code isn't built."},
+			},
+		},
+	}, requests)
+}
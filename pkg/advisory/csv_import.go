@@ -0,0 +1,246 @@
+package advisory
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+)
+
+// The columns of CSV advisory data, as written by ExportCSV. The aliases column
+// isn't written by ExportCSV, but can be added to describe new advisories.
+const (
+	csvColumnPackage           = "package"
+	csvColumnAdvisoryID        = "advisory_id"
+	csvColumnAliases           = "aliases"
+	csvColumnEventTimestamp    = "event_timestamp"
+	csvColumnEventType         = "event_type"
+	csvColumnFalsePositiveType = "false_positive_type"
+	csvColumnNote              = "note"
+	csvColumnFixedVersion      = "fixed_version"
+)
+
+// CSVRowError is an invalid row of CSV advisory data.
+type CSVRowError struct {
+	// Line is the line number of the row.
+	Line int
+
+	Err error
+}
+
+func (e *CSVRowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+// CSVRequests translates CSV advisory data into advisory requests, one for each
+// row. This is the inverse of ExportCSV: each row is an event of the advisory
+// with the row's ID in the row's package.
+//
+// The header row is required, but the columns can be in any order, and unknown
+// columns are ignored. A row without an advisory_id is for a new advisory, and
+// must have an aliases column with the IDs of the vulnerability, separated by
+// whitespace. A row without an event_timestamp is timestamped with the given
+// timestamp.
+//
+// Every row is validated, and the returned error joins a *CSVRowError for each
+// invalid row. The requests are sorted by their events' timestamps, so that the
+// events of an advisory are imported in order.
+func CSVRequests(r io.Reader, timestamp v2.Timestamp) ([]Request, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header row")
+		}
+		return nil, fmt.Errorf("reading header row: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{csvColumnPackage, csvColumnEventType} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing %q column", name)
+		}
+	}
+	_, hasID := columns[csvColumnAdvisoryID]
+	_, hasAliases := columns[csvColumnAliases]
+	if !hasID && !hasAliases {
+		return nil, fmt.Errorf("missing %q or %q column", csvColumnAdvisoryID, csvColumnAliases)
+	}
+
+	var requests []Request
+	var errs []error
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		req, err := csvRowRequest(field, timestamp)
+		if err != nil {
+			errs = append(errs, &CSVRowError{Line: line, Err: err})
+			continue
+		}
+		requests = append(requests, req)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(requests, func(i, j int) bool {
+		return time.Time(requests[i].Event.Timestamp).Before(time.Time(requests[j].Event.Timestamp))
+	})
+
+	return requests, nil
+}
+
+func csvRowRequest(field func(name string) string, timestamp v2.Timestamp) (Request, error) {
+	req := Request{
+		Package:    field(csvColumnPackage),
+		AdvisoryID: field(csvColumnAdvisoryID),
+	}
+	if aliases := strings.Fields(field(csvColumnAliases)); len(aliases) > 0 {
+		req.Aliases = aliases
+	}
+	if req.AdvisoryID == "" && len(req.Aliases) == 0 {
+		return Request{}, fmt.Errorf("%s or %s is required", csvColumnAdvisoryID, csvColumnAliases)
+	}
+
+	if ts := field(csvColumnEventTimestamp); ts != "" {
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return Request{}, fmt.Errorf("invalid %s: %w", csvColumnEventTimestamp, err)
+		}
+		timestamp = v2.Timestamp(t)
+	}
+
+	note := field(csvColumnNote)
+	event := v2.Event{
+		Timestamp: timestamp,
+		Type:      field(csvColumnEventType),
+	}
+	switch event.Type {
+	case v2.EventTypeDetection:
+		event.Data = v2.Detection{Type: v2.DetectionTypeManual}
+
+	case v2.EventTypeTruePositiveDetermination:
+		// ExportCSV doesn't distinguish a determination without data from one
+		// without a note.
+		if note != "" {
+			event.Data = v2.TruePositiveDetermination{Note: note}
+		}
+
+	case v2.EventTypeFalsePositiveDetermination:
+		event.Data = v2.FalsePositiveDetermination{Type: field(csvColumnFalsePositiveType), Note: note}
+
+	case v2.EventTypeFixed:
+		event.Data = v2.Fixed{FixedVersion: field(csvColumnFixedVersion)}
+
+	case v2.EventTypeFixNotPlanned:
+		event.Data = v2.FixNotPlanned{Note: note}
+
+	case v2.EventTypeAnalysisNotPlanned:
+		event.Data = v2.AnalysisNotPlanned{Note: note}
+
+	case v2.EventTypePendingUpstreamFix:
+		event.Data = v2.PendingUpstreamFix{Note: note}
+
+	default:
+		return Request{}, fmt.Errorf("invalid %s %q, must be one of [%s]", csvColumnEventType, event.Type, strings.Join(v2.EventTypes, ", "))
+	}
+	req.Event = event
+
+	if err := req.Validate(); err != nil {
+		return Request{}, err
+	}
+
+	return req, nil
+}
+
+// UnknownAdvisoryError is returned when a request's advisory ID isn't the ID of
+// any of its package's advisories.
+type UnknownAdvisoryError struct {
+	Request Request
+}
+
+func (e *UnknownAdvisoryError) Error() string {
+	return fmt.Sprintf("%s: advisory %s doesn't exist", e.Request.Package, e.Request.AdvisoryID)
+}
+
+// ImportCSVRequests merges requests translated from CSV advisory data (see
+// CSVRequests) into existing advisory data, as ImportRequests does.
+//
+// CSV advisory data usually includes events that are already in the existing
+// advisories (e.g. when it was exported, edited, and imported back), and it
+// lacks some of the events' data, such as the details of detections. So a
+// request is skipped if its advisory already has an event of the same type at
+// the same time.
+//
+// A request for an advisory ID that doesn't exist isn't imported, and the
+// returned error also joins an *UnknownAdvisoryError for each of these.
+func ImportCSVRequests(ctx context.Context, requests []Request, opts ImportOptions) (ImportResult, error) {
+	var result ImportResult
+	var errs []error
+
+	var remaining []Request
+	for _, req := range requests {
+		advs, err := opts.Getter.Advisories(ctx, req.Package)
+		if err != nil {
+			return result, fmt.Errorf("getting advisories for package %q: %w", req.Package, err)
+		}
+
+		existing := MatchToRequest(advs, req)
+		if existing == nil {
+			if req.AdvisoryID != "" {
+				errs = append(errs, &UnknownAdvisoryError{Request: req})
+				continue
+			}
+		} else if slices.ContainsFunc(existing.Events, func(e v2.Event) bool {
+			return e.Type == req.Event.Type && time.Time(e.Timestamp).Equal(time.Time(req.Event.Timestamp))
+		}) {
+			result.Skipped++
+			continue
+		}
+
+		remaining = append(remaining, req)
+	}
+
+	imported, err := ImportRequests(ctx, remaining, opts)
+	result.Created += imported.Created
+	result.Updated += imported.Updated
+	result.Skipped += imported.Skipped
+
+	var conflictErr *ConflictError
+	if err != nil && !errors.As(err, &conflictErr) {
+		return result, err
+	}
+
+	return result, errors.Join(append(errs, err)...)
+}
@@ -0,0 +1,143 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+func TestCSVRequests(t *testing.T) {
+	timestamp := v2.Timestamp(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+
+	t.Run("valid", func(t *testing.T) {
+		requests, err := CSVRequests(strings.NewReader(`event_type,package,advisory_id,aliases,event_timestamp,note,fixed_version,false_positive_type,reviewer
+fixed,ko,CGA-2222-2222-2222,,2024-05-03T00:00:00Z,,0.15.2-r1,,alice
+true-positive-determination,ko,CGA-2222-2222-2222,,2024-05-02T00:00:00Z,,,,alice
+false-positive-determination,zlib,,CVE-2024-1234 GHSA-2222-3333-4444,,Only used by the tests.,,vulnerable-code-not-included-in-package,bob
+`), timestamp)
+		require.NoError(t, err)
+
+		ts := func(day int) v2.Timestamp {
+			return v2.Timestamp(time.Date(2024, 5, day, 0, 0, 0, 0, time.UTC))
+		}
+		assert.Equal(t, []Request{
+			{
+				Package:    "ko",
+				AdvisoryID: "CGA-2222-2222-2222",
+				Event:      v2.Event{Timestamp: ts(2), Type: v2.EventTypeTruePositiveDetermination},
+			},
+			{
+				Package:    "ko",
+				AdvisoryID: "CGA-2222-2222-2222",
+				Event:      v2.Event{Timestamp: ts(3), Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: "0.15.2-r1"}},
+			},
+			{
+				Package: "zlib",
+				Aliases: []string{"CVE-2024-1234", "GHSA-2222-3333-4444"},
+				Event: v2.Event{Timestamp: timestamp, Type: v2.EventTypeFalsePositiveDetermination, Data: v2.FalsePositiveDetermination{
+					Type: v2.FPTypeVulnerableCodeNotIncludedInPackage,
+					Note: "Only used by the tests.",
+				}},
+			},
+		}, requests)
+	})
+
+	t.Run("invalid rows", func(t *testing.T) {
+		_, err := CSVRequests(strings.NewReader(`package,advisory_id,event_timestamp,event_type,false_positive_type,note,fixed_version
+ko,CGA-2222-2222-2222,2024-05-03T00:00:00Z,fixed,,,
+ko,,2024-05-03T00:00:00Z,fixed,,,0.15.2-r1
+ko,CGA-2222-2222-2222,yesterday,fixed,,,0.15.2-r1
+ko,CGA-2222-2222-2222,2024-05-03T00:00:00Z,triaged,,,
+ko,CGA-2222-2222-2222,2024-05-03T00:00:00Z,fixed,,,0.15.2-r1
+`), timestamp)
+		require.Error(t, err)
+
+		var rowErr *CSVRowError
+		require.True(t, errors.As(err, &rowErr))
+		assert.Equal(t, 2, rowErr.Line)
+
+		for _, msg := range []string{"line 2: ", "line 3: advisory_id or aliases is required", "line 4: invalid event_timestamp", `line 5: invalid event_type "triaged"`} {
+			assert.Contains(t, err.Error(), msg)
+		}
+		assert.NotContains(t, err.Error(), "line 6")
+	})
+
+	t.Run("missing columns", func(t *testing.T) {
+		_, err := CSVRequests(strings.NewReader("package,event_type\n"), timestamp)
+		assert.ErrorContains(t, err, `missing "advisory_id" or "aliases" column`)
+
+		_, err = CSVRequests(strings.NewReader(""), timestamp)
+		assert.ErrorContains(t, err, "missing header row")
+	})
+}
+
+func TestImportCSVRequests(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	for _, name := range []string{"brotli", "ko", "openssl"} {
+		b, err := os.ReadFile("testdata/export/advisories/" + name + ".advisories.yaml")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(dir+"/"+name+".advisories.yaml", b, 0o600))
+	}
+
+	opts := ImportOptions{
+		Getter: NewFSGetter(os.DirFS(dir)),
+		Putter: NewFSPutterWithAutomaticEncoder(rwos.DirFS(dir)),
+	}
+	timestamp := v2.Timestamp(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+
+	t.Run("round trip", func(t *testing.T) {
+		f, err := os.Open("testdata/export/expected.csv")
+		require.NoError(t, err)
+		defer f.Close()
+
+		requests, err := CSVRequests(f, timestamp)
+		require.NoError(t, err)
+
+		// Every event is already in the advisories.
+		result, err := ImportCSVRequests(ctx, requests, opts)
+		require.NoError(t, err)
+		assert.Equal(t, ImportResult{Skipped: len(requests)}, result)
+	})
+
+	t.Run("edits", func(t *testing.T) {
+		requests, err := CSVRequests(strings.NewReader(`package,advisory_id,aliases,event_timestamp,event_type,false_positive_type,note,fixed_version
+brotli,CGA-37qj-pjrf-fmrw,,2022-09-15T02:40:18Z,fixed,,,1.0.9-r0
+brotli,CGA-37qj-pjrf-fmrw,,2024-05-01T00:00:00Z,fixed,,,1.1.0-r0
+brotli,CGA-2222-2222-2222,,2024-05-01T00:00:00Z,fixed,,,1.1.0-r0
+brotli,,CVE-2024-1234,2024-05-01T00:00:00Z,detection,,,
+brotli,,CVE-2024-1234,2024-05-02T00:00:00Z,pending-upstream-fix,,Waiting for the next release.,
+`), timestamp)
+		require.NoError(t, err)
+
+		result, err := ImportCSVRequests(ctx, requests, opts)
+		require.Error(t, err)
+
+		var conflictErr *ConflictError
+		require.True(t, errors.As(err, &conflictErr), "expected a conflict, got %v", err)
+		assert.Equal(t, "CGA-37qj-pjrf-fmrw", conflictErr.AdvisoryID)
+		var unknownErr *UnknownAdvisoryError
+		require.True(t, errors.As(err, &unknownErr), "expected an unknown advisory, got %v", err)
+		assert.Equal(t, "CGA-2222-2222-2222", unknownErr.Request.AdvisoryID)
+
+		assert.Equal(t, ImportResult{Created: 1, Updated: 1, Skipped: 1}, result)
+
+		index, err := adv2.NewIndex(ctx, rwos.DirFS(dir))
+		require.NoError(t, err)
+		brotli := index.Select().WhereName("brotli").Configurations()[0]
+		added, ok := brotli.Advisories.GetByVulnerability("CVE-2024-1234")
+		require.True(t, ok)
+		require.Len(t, added.Events, 2)
+		assert.Equal(t, v2.EventTypePendingUpstreamFix, added.Latest().Type)
+	})
+}
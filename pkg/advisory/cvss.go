@@ -0,0 +1,224 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	"github.com/wolfi-dev/wolfictl/pkg/vuln/nvdapi"
+	"gopkg.in/yaml.v3"
+)
+
+// CVSSFileName is the name of the file at the root of an advisories repo that
+// stores the CVSS data of the CVEs referenced by the repo's advisories. The
+// advisory schema doesn't have a place for this data, and it's the same for
+// every package a CVE affects, so it's kept separately. The file is hidden so
+// that it's not mistaken for an advisory document.
+const CVSSFileName = ".cvss.yaml"
+
+// CVSSRecord is the CVSS score of a CVE.
+type CVSSRecord struct {
+	// Version is the CVSS version, e.g. "3.1".
+	Version string `yaml:"version"`
+
+	// Vector is the CVSS vector string.
+	Vector string `yaml:"vector"`
+
+	// Score is the CVSS base score.
+	Score float64 `yaml:"score"`
+
+	// Severity is the severity of the base score, e.g. "HIGH".
+	Severity string `yaml:"severity"`
+}
+
+func (r CVSSRecord) String() string {
+	return fmt.Sprintf("%.1f %s (%s)", r.Score, r.Severity, r.Vector)
+}
+
+// CVSSData is the CVSS data of CVEs, keyed by CVE ID.
+type CVSSData map[string]CVSSRecord
+
+// ReadCVSSData reads the CVSS data from the CVSSFileName file in the given
+// filesystem. If the file doesn't exist, the returned data is empty.
+func ReadCVSSData(fsys fs.FS) (CVSSData, error) {
+	b, err := fs.ReadFile(fsys, CVSSFileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return CVSSData{}, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", CVSSFileName, err)
+	}
+
+	data := CVSSData{}
+	if err := yaml.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", CVSSFileName, err)
+	}
+	return data, nil
+}
+
+// Encode writes the CVSS data as YAML, sorted by CVE ID.
+func (d CVSSData) Encode(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(d); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// CVSSSource looks up the current CVSS score of a CVE.
+type CVSSSource interface {
+	// CVSS returns the CVE's CVSS score, or nil if there isn't one.
+	CVSS(ctx context.Context, cveID string) (*CVSSRecord, error)
+}
+
+// NVDCVSSSource is a CVSSSource that uses the NVD API.
+type NVDCVSSSource struct {
+	detector *nvdapi.Detector
+}
+
+// NewNVDCVSSSource returns a new NVDCVSSSource that makes its requests to the
+// NVD API using the given Detector.
+func NewNVDCVSSSource(detector *nvdapi.Detector) *NVDCVSSSource {
+	return &NVDCVSSSource{detector: detector}
+}
+
+func (s NVDCVSSSource) CVSS(ctx context.Context, cveID string) (*CVSSRecord, error) {
+	cve, err := s.detector.CVE(ctx, cveID)
+	if err != nil {
+		return nil, err
+	}
+	if cve == nil {
+		return nil, nil
+	}
+
+	cvss, ok := cve.CVSS()
+	if !ok {
+		return nil, nil
+	}
+
+	return &CVSSRecord{
+		Version:  cvss.Version,
+		Vector:   cvss.Vector,
+		Score:    cvss.Score,
+		Severity: cvss.Severity,
+	}, nil
+}
+
+// SyncCVSSOptions configures the SyncCVSS operation.
+type SyncCVSSOptions struct {
+	// AdvisoryDocs is the Index of advisory documents whose CVEs are synced.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// SelectedPackages is the set of packages whose CVEs are synced. If empty, the
+	// CVEs of all packages are synced.
+	SelectedPackages map[string]struct{}
+
+	// Source is where the current CVSS data is looked up.
+	Source CVSSSource
+
+	// Current is the CVSS data to update. It's not modified.
+	Current CVSSData
+}
+
+// CVSSChange is a change to the CVSS data of a CVE. Old is nil if the CVE had
+// no CVSS data, and New is nil if the CVE no longer has CVSS data.
+type CVSSChange struct {
+	CVE string
+	Old *CVSSRecord
+	New *CVSSRecord
+}
+
+// SyncCVSS looks up the CVSS score of each CVE referenced by the advisories'
+// aliases, and returns the updated CVSS data, along with the changes made to
+// the current data, sorted by CVE ID. Data for CVEs that aren't looked up is
+// kept as is.
+func SyncCVSS(ctx context.Context, opts SyncCVSSOptions) (CVSSData, []CVSSChange, error) {
+	if opts.AdvisoryDocs == nil {
+		return nil, nil, errors.New("advisory documents must be provided")
+	}
+	if opts.Source == nil {
+		return nil, nil, errors.New("a CVSS source must be provided")
+	}
+
+	log := clog.FromContext(ctx)
+
+	updated := make(CVSSData, len(opts.Current))
+	for id, r := range opts.Current {
+		updated[id] = r
+	}
+
+	var changes []CVSSChange
+	for _, id := range referencedCVEs(opts.AdvisoryDocs, opts.SelectedPackages) {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		log.Debug("looking up CVSS data", "cve", id)
+		r, err := opts.Source.CVSS(ctx, id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("looking up CVSS data for %s: %w", id, err)
+		}
+
+		var old *CVSSRecord
+		if o, ok := opts.Current[id]; ok {
+			old = &o
+		}
+
+		switch {
+		case r == nil && old == nil:
+			continue
+
+		case r == nil:
+			delete(updated, id)
+
+		case old != nil && *old == *r:
+			continue
+
+		default:
+			updated[id] = *r
+		}
+
+		changes = append(changes, CVSSChange{CVE: id, Old: old, New: r})
+	}
+
+	return updated, changes, nil
+}
+
+// referencedCVEs returns the sorted, unique CVE IDs among the aliases of the
+// advisories of the selected packages.
+func referencedCVEs(docs *configs.Index[v2.Document], selectedPackages map[string]struct{}) []string {
+	seen := make(map[string]struct{})
+	var ids []string
+
+	for _, doc := range docs.Select().Configurations() {
+		if len(selectedPackages) > 0 {
+			if _, ok := selectedPackages[doc.Package.Name]; !ok {
+				continue
+			}
+		}
+
+		for _, adv := range doc.Advisories {
+			for _, alias := range adv.Aliases {
+				if !strings.HasPrefix(alias, "CVE-") {
+					continue
+				}
+				if _, ok := seen[alias]; ok {
+					continue
+				}
+				seen[alias] = struct{}{}
+				ids = append(ids, alias)
+			}
+		}
+	}
+
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,113 @@
+package advisory
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestSyncCVSS(t *testing.T) {
+	fsys := os.DirFS("testdata/cvss")
+
+	current, err := ReadCVSSData(fsys)
+	require.NoError(t, err)
+	require.Len(t, current, 4)
+
+	advisoryDocs, err := adv2.NewIndex(context.Background(), memfs.New(fsys))
+	require.NoError(t, err)
+	require.Equal(t, 2, advisoryDocs.Select().Len())
+
+	rescored := CVSSRecord{
+		Version:  "3.1",
+		Vector:   "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H",
+		Score:    7.5,
+		Severity: "HIGH",
+	}
+	added := CVSSRecord{
+		Version:  "3.1",
+		Vector:   "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:L/I:N/A:N",
+		Score:    3.7,
+		Severity: "LOW",
+	}
+	source := mockCVSSSource{
+		"CVE-2023-2222": &rescored,
+		"CVE-2023-3333": ptr(current["CVE-2023-3333"]),
+		"CVE-2023-5555": &added,
+	}
+
+	t.Run("all packages", func(t *testing.T) {
+		updated, changes, err := SyncCVSS(context.Background(), SyncCVSSOptions{
+			AdvisoryDocs: advisoryDocs,
+			Source:       source,
+			Current:      current,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []CVSSChange{
+			{CVE: "CVE-2023-2222", Old: ptr(current["CVE-2023-2222"]), New: &rescored},
+			{CVE: "CVE-2023-4444", Old: ptr(current["CVE-2023-4444"]), New: nil},
+			{CVE: "CVE-2023-5555", Old: nil, New: &added},
+		}, changes)
+
+		assert.Equal(t, CVSSData{
+			"CVE-2020-9999": current["CVE-2020-9999"],
+			"CVE-2023-2222": rescored,
+			"CVE-2023-3333": current["CVE-2023-3333"],
+			"CVE-2023-5555": added,
+		}, updated)
+
+		// The current data isn't modified.
+		assert.Len(t, current, 4)
+		assert.Equal(t, 5.3, current["CVE-2023-2222"].Score)
+	})
+
+	t.Run("selected packages", func(t *testing.T) {
+		_, changes, err := SyncCVSS(context.Background(), SyncCVSSOptions{
+			AdvisoryDocs:     advisoryDocs,
+			SelectedPackages: map[string]struct{}{"crane": {}},
+			Source:           source,
+			Current:          current,
+		})
+		require.NoError(t, err)
+
+		var cves []string
+		for _, c := range changes {
+			cves = append(cves, c.CVE)
+		}
+		assert.Equal(t, []string{"CVE-2023-2222", "CVE-2023-5555"}, cves)
+	})
+}
+
+func TestCVSSData_Encode(t *testing.T) {
+	current, err := ReadCVSSData(os.DirFS("testdata/cvss"))
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("testdata/cvss/" + CVSSFileName)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, current.Encode(&buf))
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestReadCVSSData_missing(t *testing.T) {
+	data, err := ReadCVSSData(os.DirFS(t.TempDir()))
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
+
+type mockCVSSSource map[string]*CVSSRecord
+
+func (m mockCVSSSource) CVSS(_ context.Context, cveID string) (*CVSSRecord, error) {
+	return m[cveID], nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
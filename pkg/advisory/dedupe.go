@@ -0,0 +1,197 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+)
+
+// DedupeOptions configures the FindDuplicates operation.
+type DedupeOptions struct {
+	// AdvisoryDocs is the Index of advisory documents on which to operate.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// SelectedPackages is the set of packages to operate on. If empty, all
+	// packages are operated on.
+	SelectedPackages map[string]struct{}
+
+	// AliasFinder, if set, is used to complete the set of vulnerability IDs of
+	// each advisory, so that e.g. an advisory for a GHSA is found to duplicate an
+	// advisory for the GHSA's CVE. If nil, only advisories that have an alias in
+	// common are duplicates.
+	AliasFinder AliasFinder
+}
+
+// DuplicateGroup is a set of advisories of a package that are about the same
+// vulnerability, under different advisories.
+type DuplicateGroup struct {
+	// Package is the name of the package the advisories are for.
+	Package string
+
+	// Advisories are the duplicate advisories. The first one is the advisory that
+	// the others are merged into, which is the one that was created first.
+	Advisories []v2.Advisory
+}
+
+// Merged returns the advisory that the group's advisories are merged into. It
+// has the ID of the group's first advisory, the union of the advisories'
+// aliases, and the union of their events, ordered by timestamp. The IDs of the
+// other advisories are dropped, since aliases can't be advisory IDs.
+func (g DuplicateGroup) Merged() v2.Advisory {
+	merged := v2.Advisory{ID: g.Advisories[0].ID}
+
+	for _, adv := range g.Advisories {
+		merged = merged.MergeInAliases(adv.Aliases...)
+		for _, e := range adv.Events {
+			if !slices.ContainsFunc(merged.Events, func(existing v2.Event) bool { return eventsEqual(existing, e) }) {
+				merged.Events = append(merged.Events, e)
+			}
+		}
+	}
+
+	sort.SliceStable(merged.Events, func(i, j int) bool {
+		return merged.Events[i].Timestamp.Before(merged.Events[j].Timestamp)
+	})
+
+	return merged
+}
+
+// FindDuplicates finds the advisories within each package that are about the
+// same vulnerability, i.e. whose sets of vulnerability IDs (their aliases,
+// completed by opts.AliasFinder if it's set) overlap, directly or through other
+// advisories of the package.
+func FindDuplicates(ctx context.Context, opts DedupeOptions) ([]DuplicateGroup, error) {
+	if opts.AdvisoryDocs == nil {
+		return nil, errors.New("advisory documents must be provided")
+	}
+
+	log := clog.FromContext(ctx)
+
+	var groups []DuplicateGroup
+	for _, doc := range opts.AdvisoryDocs.Select().Configurations() {
+		pkg := doc.Package.Name
+		if len(opts.SelectedPackages) > 0 {
+			if _, ok := opts.SelectedPackages[pkg]; !ok {
+				continue
+			}
+		}
+
+		// Group the advisories by connecting each one to the first advisory seen with
+		// each of its vulnerability IDs, using a union-find structure.
+		parent := make([]int, len(doc.Advisories))
+		for i := range parent {
+			parent[i] = i
+		}
+		var find func(i int) int
+		find = func(i int) int {
+			if parent[i] != i {
+				parent[i] = find(parent[i])
+			}
+			return parent[i]
+		}
+
+		firstWithID := make(map[string]int)
+		for i, adv := range doc.Advisories {
+			ids := adv.Aliases
+			if opts.AliasFinder != nil && len(ids) > 0 {
+				completed, err := CompleteAliasSet(ctx, opts.AliasFinder, ids)
+				if err != nil {
+					log.Warn("unable to complete aliases of advisory", "package", pkg, "advisory", adv.ID, "error", err)
+				} else {
+					ids = completed
+				}
+			}
+
+			for _, id := range ids {
+				j, ok := firstWithID[id]
+				if !ok {
+					firstWithID[id] = i
+					continue
+				}
+				parent[find(i)] = find(j)
+			}
+		}
+
+		byRoot := make(map[int][]v2.Advisory)
+		var roots []int
+		for i, adv := range doc.Advisories {
+			root := find(i)
+			if _, ok := byRoot[root]; !ok {
+				roots = append(roots, root)
+			}
+			byRoot[root] = append(byRoot[root], adv)
+		}
+
+		for _, root := range roots {
+			advs := byRoot[root]
+			if len(advs) < 2 {
+				continue
+			}
+
+			sort.SliceStable(advs, func(i, j int) bool {
+				a, b := createdAt(advs[i]), createdAt(advs[j])
+				if !a.Equal(b) {
+					return a.Before(b)
+				}
+				return advs[i].ID < advs[j].ID
+			})
+
+			groups = append(groups, DuplicateGroup{Package: pkg, Advisories: advs})
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Package != groups[j].Package {
+			return groups[i].Package < groups[j].Package
+		}
+		return groups[i].Advisories[0].ID < groups[j].Advisories[0].ID
+	})
+
+	return groups, nil
+}
+
+// MergeDuplicates replaces the advisories of each group in the advisory
+// documents with the group's merged advisory (see DuplicateGroup.Merged).
+func MergeDuplicates(ctx context.Context, docs *configs.Index[v2.Document], groups []DuplicateGroup) error {
+	for _, g := range groups {
+		documents := docs.Select().WhereName(g.Package)
+		if count := documents.Len(); count != 1 {
+			return fmt.Errorf("cannot merge advisories: found %d advisory documents for package %q", count, g.Package)
+		}
+
+		merged := g.Merged()
+		if err := merged.Validate(); err != nil {
+			return fmt.Errorf("merging advisories %s of package %q: %w", advisoryIDs(g.Advisories), g.Package, err)
+		}
+
+		u := adv2.NewAdvisoriesSectionUpdater(func(doc v2.Document) (v2.Advisories, error) {
+			advisories := slices.DeleteFunc(slices.Clone(doc.Advisories), func(adv v2.Advisory) bool {
+				return adv.ID != merged.ID && slices.ContainsFunc(g.Advisories, func(dup v2.Advisory) bool { return dup.ID == adv.ID })
+			})
+			advisories = advisories.Update(merged.ID, merged)
+			sort.Sort(advisories)
+			return advisories, nil
+		})
+		if err := documents.Update(ctx, u); err != nil {
+			return fmt.Errorf("merging advisories %s of package %q: %w", advisoryIDs(g.Advisories), g.Package, err)
+		}
+	}
+
+	return nil
+}
+
+// createdAt returns the timestamp of the advisory's earliest event.
+func createdAt(adv v2.Advisory) v2.Timestamp {
+	events := adv.SortedEvents()
+	if len(events) == 0 {
+		return v2.Timestamp{}
+	}
+	return events[0].Timestamp
+}
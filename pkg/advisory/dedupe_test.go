@@ -0,0 +1,76 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	ctx := context.Background()
+
+	groupIDs := func(groups []DuplicateGroup) [][]string {
+		var ids [][]string
+		for _, g := range groups {
+			assert.Equal(t, "ko", g.Package)
+			ids = append(ids, advisoryIDs(g.Advisories))
+		}
+		return ids
+	}
+
+	t.Run("shared aliases", func(t *testing.T) {
+		docs, err := adv2.NewIndex(ctx, memfs.New(os.DirFS("testdata/dedupe")))
+		require.NoError(t, err)
+
+		groups, err := FindDuplicates(ctx, DedupeOptions{AdvisoryDocs: docs})
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"CGA-2222-2222-2222", "CGA-3333-3333-3333"}}, groupIDs(groups))
+
+		merged := groups[0].Merged()
+		assert.Equal(t, "CGA-2222-2222-2222", merged.ID)
+		assert.Equal(t, []string{"CVE-2024-1111", "GHSA-2222-3333-4444"}, merged.Aliases)
+		require.Len(t, merged.Events, 2, "the identical detections are merged")
+		assert.Equal(t, v2.EventTypeFixed, merged.Latest().Type)
+
+		require.NoError(t, MergeDuplicates(ctx, docs, groups))
+
+		doc := docs.Select().WhereName("ko").Configurations()[0]
+		assert.Equal(t, []string{"CGA-2222-2222-2222", "CGA-4444-4444-4444", "CGA-5555-5555-5555", "CGA-6666-6666-6666"}, advisoryIDs(doc.Advisories))
+		adv, ok := doc.Advisories.Get("CGA-2222-2222-2222")
+		require.True(t, ok)
+		assert.Equal(t, merged, adv)
+	})
+
+	t.Run("aliases from alias finder", func(t *testing.T) {
+		docs, err := adv2.NewIndex(ctx, memfs.New(os.DirFS("testdata/dedupe")))
+		require.NoError(t, err)
+
+		groups, err := FindDuplicates(ctx, DedupeOptions{
+			AdvisoryDocs: docs,
+			AliasFinder: mockAliasFinder{
+				cveByGHSA:  map[string]string{"GHSA-5555-6666-7777": "CVE-2024-5555"},
+				ghsasByCVE: map[string][]string{"CVE-2024-5555": {"GHSA-5555-6666-7777"}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"CGA-2222-2222-2222", "CGA-3333-3333-3333"},
+			{"CGA-4444-4444-4444", "CGA-5555-5555-5555"},
+		}, groupIDs(groups))
+	})
+
+	t.Run("selected packages", func(t *testing.T) {
+		docs, err := adv2.NewIndex(ctx, memfs.New(os.DirFS("testdata/dedupe")))
+		require.NoError(t, err)
+
+		groups, err := FindDuplicates(ctx, DedupeOptions{AdvisoryDocs: docs, SelectedPackages: map[string]struct{}{"crane": {}}})
+		require.NoError(t, err)
+		assert.Empty(t, groups)
+	})
+}
@@ -1,12 +1,20 @@
 package advisory
 
 import (
+	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
 
 	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/google/osv-scanner/pkg/models"
 	_ "github.com/santhosh-tekuri/jsonschema/v5/httploader" // to be able to download the schema from the URL
 
 	"github.com/samber/lo"
@@ -17,6 +25,83 @@ import (
 
 type ExportOptions struct {
 	AdvisoryDocIndices []*configs.Index[v2.Document]
+
+	// Ecosystem is the OSV ecosystem of the exported packages, which is the name
+	// of the distro (e.g. "Wolfi"). It's used by ExportOSV, ExportOpenVEX, and
+	// ExportCSAF.
+	Ecosystem string
+
+	// PublisherNamespace is the URL of the namespace of the publisher of CSAF
+	// documents (e.g. "https://github.com/wolfi-dev"). It's only used by
+	// ExportCSAF.
+	PublisherNamespace string
+
+	// Packages are the names of the packages whose advisories are exported. If
+	// empty, the advisories of all packages are exported.
+	Packages []string
+
+	// ModifiedSince, if set, limits the export to advisories whose latest event
+	// is at or after this time, for incremental exports.
+	ModifiedSince time.Time
+
+	// Statuses, if set, limits the export to advisories whose latest event has one
+	// of these types (e.g. "fixed").
+	Statuses []string
+
+	// CVSS is the CVSS data used for the severity of the exported advisories. It's
+	// only used by ExportParquet and WriteHTMLSite, and may be nil.
+	CVSS CVSSData
+
+	// PackageVersionTimeline is used by ExportOSV and ExportCSAF to derive the
+	// affected version ranges of the advisories (see AffectedRanges). It may be nil.
+	PackageVersionTimeline PackageVersionTimeline
+}
+
+// documents returns the advisory documents of the index that are selected by
+// the options' filters. When advisories are filtered by ModifiedSince or
+// Statuses, each document only keeps its matching advisories, and documents left
+// without any are dropped.
+func (opts ExportOptions) documents(index *configs.Index[v2.Document]) []v2.Document {
+	sel := index.Select()
+	if len(opts.Packages) > 0 {
+		sel = sel.Where(func(e configs.Entry[v2.Document]) bool {
+			cfg := e.Configuration()
+			return cfg != nil && slices.Contains(opts.Packages, cfg.Name())
+		})
+	}
+	docs := sel.Configurations()
+
+	if opts.ModifiedSince.IsZero() && len(opts.Statuses) == 0 {
+		return docs
+	}
+
+	var filtered []v2.Document
+	for _, doc := range docs {
+		doc.Advisories = lo.Filter(doc.Advisories, func(adv v2.Advisory, _ int) bool {
+			return opts.includesAdvisory(adv)
+		})
+		if len(doc.Advisories) > 0 {
+			filtered = append(filtered, doc)
+		}
+	}
+
+	return filtered
+}
+
+func (opts ExportOptions) includesAdvisory(adv v2.Advisory) bool {
+	if len(adv.Events) == 0 {
+		return false
+	}
+	latest := adv.Latest()
+
+	if !opts.ModifiedSince.IsZero() && time.Time(latest.Timestamp).Before(opts.ModifiedSince) {
+		return false
+	}
+	if len(opts.Statuses) > 0 && !slices.Contains(opts.Statuses, latest.Type) {
+		return false
+	}
+
+	return true
 }
 
 // ExportCSV returns a reader of advisory data encoded as CSV.
@@ -33,7 +118,7 @@ func ExportCSV(opts ExportOptions) (io.Reader, error) {
 	}
 
 	for _, index := range opts.AdvisoryDocIndices {
-		documents := index.Select().Configurations()
+		documents := opts.documents(index)
 
 		for _, doc := range documents {
 			for _, adv := range doc.Advisories {
@@ -92,7 +177,7 @@ func ExportYAML(opts ExportOptions) (io.Reader, error) {
 	buf := new(bytes.Buffer)
 
 	for _, index := range opts.AdvisoryDocIndices {
-		docs := index.Select().Configurations()
+		docs := opts.documents(index)
 
 		for i, doc := range docs {
 			// Sort events for each advisory
@@ -115,3 +200,113 @@ func ExportYAML(opts ExportOptions) (io.Reader, error) {
 
 	return buf, nil
 }
+
+// ExportOSV returns the advisory data as OSV vulnerabilities, sorted by ID. Each
+// advisory whose latest event is a fix or a false positive determination is a
+// vulnerability of its package in the ecosystem given by opts.Ecosystem. A fixed
+// advisory's affected range has the events of its AffectedRanges, so that e.g.
+// a regression after a fix is included. Other advisories aren't exported, since
+// they don't yet say which versions are affected. Advisories with the same ID
+// (e.g. in different indices) are exported as a single vulnerability, affecting
+// each of their packages.
+func ExportOSV(ctx context.Context, opts ExportOptions) ([]models.Vulnerability, error) {
+	if opts.Ecosystem == "" {
+		return nil, fmt.Errorf("an ecosystem is required for OSV export")
+	}
+	ecosystem := models.Ecosystem(opts.Ecosystem)
+
+	vulnerabilitiesByID := make(map[string]models.Vulnerability)
+	for _, index := range opts.AdvisoryDocIndices {
+		for _, doc := range opts.documents(index) {
+			for _, adv := range doc.Advisories {
+				affectedRange, ok, err := osvAffectedRange(ctx, opts.PackageVersionTimeline, doc.Package.Name, adv)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+				events := adv.SortedEvents()
+
+				addOSVVulnerability(vulnerabilitiesByID, models.Vulnerability{
+					ID:        adv.ID,
+					Aliases:   adv.Aliases,
+					Published: time.Time(events[0].Timestamp),
+					Modified:  time.Time(events[len(events)-1].Timestamp),
+					Affected: []models.Affected{{
+						Package: models.Package{
+							Name:      doc.Package.Name,
+							Ecosystem: ecosystem,
+							Purl:      createPurl(doc.Package.Name, ecosystem),
+						},
+						Ranges: []models.Range{affectedRange},
+					}},
+				})
+			}
+		}
+	}
+
+	return sortedOSVVulnerabilities(vulnerabilitiesByID), nil
+}
+
+// WriteOSVDirectory writes each OSV vulnerability to a JSON file in dir, named
+// after the vulnerability's ID.
+func WriteOSVDirectory(dir string, vulnerabilities []models.Vulnerability) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating OSV output directory: %w", err)
+	}
+
+	for i := range vulnerabilities {
+		v := vulnerabilities[i]
+
+		f, err := os.Create(filepath.Join(dir, v.ID+".json"))
+		if err != nil {
+			return fmt.Errorf("creating file for OSV vulnerability %q: %w", v.ID, err)
+		}
+
+		if err := encodeOSV(f, v); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("closing file for OSV vulnerability %q: %w", v.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteOSVZip writes each OSV vulnerability as a JSON file, named after the
+// vulnerability's ID, in a zip archive written to w. This is the layout of the
+// archives of the OSV ecosystems' data (e.g. "all.zip").
+func WriteOSVZip(w io.Writer, vulnerabilities []models.Vulnerability) error {
+	zw := zip.NewWriter(w)
+
+	for i := range vulnerabilities {
+		v := vulnerabilities[i]
+
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     v.ID + ".json",
+			Method:   zip.Deflate,
+			Modified: v.Modified,
+		})
+		if err != nil {
+			return fmt.Errorf("adding OSV vulnerability %q to zip: %w", v.ID, err)
+		}
+
+		if err := encodeOSV(f, v); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func encodeOSV(w io.Writer, v models.Vulnerability) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encoding OSV vulnerability %q to JSON: %w", v.ID, err)
+	}
+	return nil
+}
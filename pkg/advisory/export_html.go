@@ -0,0 +1,267 @@
+package advisory
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+)
+
+//go:embed html_site.html.tmpl
+var htmlSiteTemplateText string
+
+var htmlSiteTemplate = template.Must(template.New("site").Funcs(template.FuncMap{
+	"eventDetail": htmlSiteEventDetail,
+}).Parse(htmlSiteTemplateText))
+
+// HTMLSiteSearchIndexFileName is the name of the file of a static advisory site
+// that lists every advisory, which the site's search box uses.
+const HTMLSiteSearchIndexFileName = "search-index.json"
+
+// HTMLSiteSearchEntry is an entry of a static advisory site's search index.
+type HTMLSiteSearchEntry struct {
+	Package      string   `json:"package"`
+	ID           string   `json:"id"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Status       string   `json:"status"`
+	FixedVersion string   `json:"fixed_version,omitempty"`
+	Updated      string   `json:"updated"`
+
+	// URL is the path of the advisory's package page, relative to the root of the
+	// site.
+	URL string `json:"url"`
+}
+
+type htmlSitePage struct {
+	// Root is the relative path from the page to the root of the site.
+	Root  string
+	Title string
+	AsOf  time.Time
+}
+
+type htmlSiteIndexPage struct {
+	htmlSitePage
+	Packages []htmlSitePackage
+}
+
+type htmlSitePackage struct {
+	Name       string
+	Advisories []htmlSiteAdvisory
+	Open       int
+}
+
+type htmlSiteAdvisory struct {
+	ID           string
+	Aliases      []string
+	Status       string
+	FixedVersion string
+	Updated      time.Time
+	Events       []v2.Event
+}
+
+type htmlSitePackagePage struct {
+	htmlSitePage
+	Package htmlSitePackage
+}
+
+type htmlSiteVulnerabilityPage struct {
+	htmlSitePage
+	ID       string
+	Severity *CVSSRecord
+	Entries  []htmlSiteVulnerabilityEntry
+}
+
+type htmlSiteVulnerabilityEntry struct {
+	Package  string
+	Advisory htmlSiteAdvisory
+}
+
+// WriteHTMLSite writes a browsable static website of the advisory data to dir,
+// for publishing the status of the advisories to users who don't want to clone
+// the advisories repo. The site has:
+//
+//   - index.html, listing the packages, with a box to search the advisories;
+//   - packages/<package>.html, for the advisories of each package, with their
+//     full event history;
+//   - vulnerabilities/<id>.html, for the advisories of each vulnerability ID
+//     (each alias, or the advisory ID of advisories without aliases) across all
+//     packages, with the vulnerability's CVSS severity from opts.CVSS, if any;
+//   - HTMLSiteSearchIndexFileName, the search index, which can also be used as a
+//     simple JSON API.
+//
+// The site doesn't need a server-side component, but the search box needs the
+// site to be served over HTTP, since browsers don't let pages loaded from files
+// fetch other files.
+func WriteHTMLSite(dir string, opts ExportOptions) error {
+	packages, asOf := htmlSitePackages(opts)
+
+	for _, sub := range []string{"packages", "vulnerabilities"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return fmt.Errorf("creating site directory: %w", err)
+		}
+	}
+
+	err := writeHTMLSitePage(filepath.Join(dir, "index.html"), "index", htmlSiteIndexPage{
+		htmlSitePage: htmlSitePage{Root: ".", Title: "Security advisories", AsOf: asOf},
+		Packages:     packages,
+	})
+	if err != nil {
+		return err
+	}
+
+	var searchIndex []HTMLSiteSearchEntry
+	vulnerabilities := make(map[string][]htmlSiteVulnerabilityEntry)
+
+	for _, pkg := range packages {
+		err := writeHTMLSitePage(filepath.Join(dir, "packages", pkg.Name+".html"), "package", htmlSitePackagePage{
+			htmlSitePage: htmlSitePage{Root: "..", Title: pkg.Name, AsOf: asOf},
+			Package:      pkg,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, adv := range pkg.Advisories {
+			searchIndex = append(searchIndex, HTMLSiteSearchEntry{
+				Package:      pkg.Name,
+				ID:           adv.ID,
+				Aliases:      adv.Aliases,
+				Status:       adv.Status,
+				FixedVersion: adv.FixedVersion,
+				Updated:      adv.Updated.Format(time.RFC3339),
+				URL:          "packages/" + pkg.Name + ".html#" + adv.ID,
+			})
+
+			ids := adv.Aliases
+			if len(ids) == 0 {
+				ids = []string{adv.ID}
+			}
+			for _, id := range ids {
+				vulnerabilities[id] = append(vulnerabilities[id], htmlSiteVulnerabilityEntry{Package: pkg.Name, Advisory: adv})
+			}
+		}
+	}
+
+	for id, entries := range vulnerabilities {
+		page := htmlSiteVulnerabilityPage{
+			htmlSitePage: htmlSitePage{Root: "..", Title: id, AsOf: asOf},
+			ID:           id,
+			Entries:      entries,
+		}
+		if r, ok := opts.CVSS[id]; ok {
+			page.Severity = &r
+		}
+
+		if err := writeHTMLSitePage(filepath.Join(dir, "vulnerabilities", id+".html"), "vulnerability", page); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(filepath.Join(dir, HTMLSiteSearchIndexFileName))
+	if err != nil {
+		return fmt.Errorf("creating search index: %w", err)
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(searchIndex); err != nil {
+		return fmt.Errorf("writing search index: %w", err)
+	}
+
+	return f.Close()
+}
+
+// htmlSitePackages returns the packages of the advisory data, sorted by name,
+// and the time of the data's latest event.
+func htmlSitePackages(opts ExportOptions) ([]htmlSitePackage, time.Time) {
+	byName := make(map[string]*htmlSitePackage)
+	var asOf time.Time
+
+	for _, index := range opts.AdvisoryDocIndices {
+		for _, doc := range opts.documents(index) {
+			pkg, ok := byName[doc.Package.Name]
+			if !ok {
+				pkg = &htmlSitePackage{Name: doc.Package.Name}
+				byName[doc.Package.Name] = pkg
+			}
+
+			for _, adv := range doc.Advisories {
+				latest := adv.Latest()
+				a := htmlSiteAdvisory{
+					ID:      adv.ID,
+					Aliases: adv.Aliases,
+					Status:  latest.Type,
+					Updated: time.Time(latest.Timestamp).UTC(),
+					Events:  adv.SortedEvents(),
+				}
+				if fixed, ok := latest.Data.(v2.Fixed); ok {
+					a.FixedVersion = fixed.FixedVersion
+				}
+				if !adv.Resolved() {
+					pkg.Open++
+				}
+				if a.Updated.After(asOf) {
+					asOf = a.Updated
+				}
+
+				pkg.Advisories = append(pkg.Advisories, a)
+			}
+		}
+	}
+
+	packages := make([]htmlSitePackage, 0, len(byName))
+	for _, pkg := range byName {
+		sort.Slice(pkg.Advisories, func(i, j int) bool {
+			return pkg.Advisories[i].ID < pkg.Advisories[j].ID
+		})
+		packages = append(packages, *pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+
+	return packages, asOf
+}
+
+func writeHTMLSitePage(path, name string, data any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %q: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := htmlSiteTemplate.ExecuteTemplate(f, name, data); err != nil {
+		return fmt.Errorf("rendering %q: %w", path, err)
+	}
+
+	return f.Close()
+}
+
+// htmlSiteEventDetail returns a short description of the event's data.
+func htmlSiteEventDetail(e v2.Event) string {
+	var parts []string
+
+	switch data := e.Data.(type) {
+	case v2.Fixed:
+		parts = append(parts, "fixed in "+data.FixedVersion)
+	case v2.FalsePositiveDetermination:
+		parts = append(parts, data.Type)
+	case v2.Detection:
+		parts = append(parts, data.Type)
+		if scan, ok := data.Data.(v2.DetectionScanV1); ok {
+			parts = append(parts, fmt.Sprintf("%s %s (%s)", scan.ComponentName, scan.ComponentVersion, scan.ComponentType))
+		}
+	}
+
+	if note := e.Note(); note != "" {
+		parts = append(parts, note)
+	}
+
+	return strings.Join(parts, ": ")
+}
@@ -0,0 +1,79 @@
+package advisory
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/parquet-go/parquet-go"
+)
+
+// EventRecord is a row of the flattened events table produced by
+// ExportParquet, with one row per advisory event.
+type EventRecord struct {
+	Package    string    `parquet:"package,dict"`
+	AdvisoryID string    `parquet:"advisory_id"`
+	Aliases    []string  `parquet:"aliases,list"`
+	EventType  string    `parquet:"event_type,dict"`
+	Timestamp  time.Time `parquet:"timestamp,timestamp(millisecond)"`
+
+	// FixedVersion is only set for fixed events.
+	FixedVersion string `parquet:"fixed_version,optional"`
+
+	// Severity is the highest CVSS severity among the advisory's CVEs, according
+	// to ExportOptions.CVSS, or SeverityUnknown. It's the same for all the events
+	// of an advisory.
+	Severity string `parquet:"severity,dict"`
+}
+
+// ExportEventRecords returns the events of all advisories as EventRecords,
+// ordered by package, advisory ID and then event timestamp.
+func ExportEventRecords(opts ExportOptions) []EventRecord {
+	var records []EventRecord
+
+	for _, index := range opts.AdvisoryDocIndices {
+		for _, doc := range opts.documents(index) {
+			for _, adv := range doc.Advisories {
+				severity := advisorySeverity(adv, opts.CVSS)
+
+				for _, event := range adv.SortedEvents() {
+					r := EventRecord{
+						Package:    doc.Package.Name,
+						AdvisoryID: adv.ID,
+						Aliases:    adv.Aliases,
+						EventType:  event.Type,
+						Timestamp:  time.Time(event.Timestamp).UTC(),
+						Severity:   severity,
+					}
+					if fixed, ok := event.Data.(v2.Fixed); ok {
+						r.FixedVersion = fixed.FixedVersion
+					}
+
+					records = append(records, r)
+				}
+			}
+		}
+	}
+
+	return records
+}
+
+// ExportParquet returns a reader of advisory data encoded as a Parquet file,
+// with one row per advisory event (see EventRecord). Unlike the other export
+// formats, this is meant to be loaded into analytics tools, like BigQuery or
+// DuckDB, to study how advisories change over time.
+func ExportParquet(opts ExportOptions) (io.Reader, error) {
+	buf := new(bytes.Buffer)
+
+	w := parquet.NewGenericWriter[EventRecord](buf, parquet.Compression(&parquet.Snappy))
+	if _, err := w.Write(ExportEventRecords(opts)); err != nil {
+		return nil, fmt.Errorf("writing Parquet rows: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("finishing Parquet file: %w", err)
+	}
+
+	return buf, nil
+}
@@ -1,19 +1,28 @@
 package advisory
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/parquet-go/parquet-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/wolfi-dev/wolfictl/pkg/configs"
 	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
 	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
 )
 
 func Test_ExportFuncs(t *testing.T) {
@@ -64,3 +73,277 @@ func Test_ExportFuncs(t *testing.T) {
 		})
 	}
 }
+
+func Test_ExportOSV(t *testing.T) {
+	advisoryDocs, err := adv2.NewIndex(context.Background(), rwos.DirFS("./testdata/export/advisories"))
+	require.NoError(t, err)
+
+	opts := ExportOptions{
+		AdvisoryDocIndices: []*configs.Index[v2.Document]{advisoryDocs},
+		Ecosystem:          "Wolfi",
+	}
+
+	vulnerabilities, err := ExportOSV(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, vulnerabilities, 22)
+
+	byID := make(map[string]models.Vulnerability)
+	for _, v := range vulnerabilities {
+		byID[v.ID] = v
+	}
+
+	fixed := byID["CGA-37qj-pjrf-fmrw"]
+	assert.Equal(t, []string{"CVE-2020-8927"}, fixed.Aliases)
+	assert.Equal(t, time.Date(2022, 9, 15, 2, 40, 18, 0, time.UTC), fixed.Modified)
+	assert.Equal(t, []models.Affected{{
+		Package: models.Package{
+			Name:      "brotli",
+			Ecosystem: "Wolfi",
+			Purl:      "pkg:apk/wolfi/brotli",
+		},
+		Ranges: []models.Range{{
+			Type:   models.RangeEcosystem,
+			Events: []models.Event{{Introduced: "0"}, {Fixed: "1.0.9-r0"}},
+		}},
+	}}, fixed.Affected)
+
+	falsePositive := byID["CGA-mm7m-x6cw-5fg4"]
+	require.Len(t, falsePositive.Affected, 1)
+	assert.Equal(t, rangeForFalsePositive(), falsePositive.Affected[0].Ranges[0])
+
+	t.Run("directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "osv")
+		require.NoError(t, WriteOSVDirectory(dir, vulnerabilities))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, len(vulnerabilities))
+
+		b, err := os.ReadFile(filepath.Join(dir, "CGA-37qj-pjrf-fmrw.json"))
+		require.NoError(t, err)
+		var decoded models.Vulnerability
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		assert.Equal(t, fixed.Affected, decoded.Affected)
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteOSVZip(buf, vulnerabilities))
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		require.Len(t, zr.File, len(vulnerabilities))
+		assert.Equal(t, vulnerabilities[0].ID+".json", zr.File[0].Name)
+	})
+
+	t.Run("same advisory ID in multiple indices", func(t *testing.T) {
+		otherDocs, err := adv2.NewIndex(context.Background(), memfs.New(fstest.MapFS{
+			"brotli-compat.advisories.yaml": {Data: []byte(`schema-version: "2"
+
+package:
+  name: brotli-compat
+
+advisories:
+  - id: CGA-37qj-pjrf-fmrw
+    aliases:
+      - CVE-2020-8927
+    events:
+      - timestamp: 2022-09-16T00:00:00Z
+        type: fixed
+        data:
+          fixed-version: 1.0.0-r1
+`)},
+		}))
+		require.NoError(t, err)
+
+		vulnerabilities, err := ExportOSV(context.Background(), ExportOptions{
+			AdvisoryDocIndices: []*configs.Index[v2.Document]{advisoryDocs, otherDocs},
+			Ecosystem:          "Wolfi",
+		})
+		require.NoError(t, err)
+		require.Len(t, vulnerabilities, 22)
+
+		var merged models.Vulnerability
+		for _, v := range vulnerabilities {
+			if v.ID == "CGA-37qj-pjrf-fmrw" {
+				merged = v
+			}
+		}
+
+		// Neither index's entry overwrites the other's.
+		require.Len(t, merged.Affected, 2)
+		assert.Equal(t, "brotli", merged.Affected[0].Package.Name)
+		assert.Equal(t, "brotli-compat", merged.Affected[1].Package.Name)
+		assert.Equal(t, []models.Event{{Introduced: "0"}, {Fixed: "1.0.0-r1"}}, merged.Affected[1].Ranges[0].Events)
+		assert.Equal(t, []string{"CVE-2020-8927"}, merged.Aliases)
+		assert.Equal(t, time.Date(2022, 9, 16, 0, 0, 0, 0, time.UTC), merged.Modified)
+	})
+
+	t.Run("no ecosystem", func(t *testing.T) {
+		_, err := ExportOSV(context.Background(), ExportOptions{AdvisoryDocIndices: opts.AdvisoryDocIndices})
+		assert.Error(t, err)
+	})
+}
+
+func Test_ExportParquet(t *testing.T) {
+	fsys := os.DirFS("testdata/remediation")
+
+	cvss, err := ReadCVSSData(fsys)
+	require.NoError(t, err)
+
+	advisoryDocs, err := adv2.NewIndex(context.Background(), memfs.New(fsys))
+	require.NoError(t, err)
+
+	opts := ExportOptions{
+		AdvisoryDocIndices: []*configs.Index[v2.Document]{advisoryDocs},
+		CVSS:               cvss,
+	}
+
+	exported, err := ExportParquet(opts)
+	require.NoError(t, err)
+	b, err := io.ReadAll(exported)
+	require.NoError(t, err)
+
+	rows, err := parquet.Read[EventRecord](bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	require.Len(t, rows, 11)
+
+	assert.Equal(t, EventRecord{
+		Package:    "crane",
+		AdvisoryID: "CGA-6666-6666-6666",
+		Aliases:    []string{"CVE-2024-2222"},
+		EventType:  v2.EventTypeDetection,
+		Timestamp:  time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Severity:   "CRITICAL",
+	}, rows[0])
+
+	assert.Equal(t, EventRecord{
+		Package:      "ko",
+		AdvisoryID:   "CGA-3333-3333-3333",
+		Aliases:      []string{"CVE-2024-3333"},
+		EventType:    v2.EventTypeFixed,
+		Timestamp:    time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC),
+		FixedVersion: "0.15.1-r0",
+		Severity:     "HIGH",
+	}, rows[8])
+
+	assert.Equal(t, SeverityUnknown, rows[len(rows)-1].Severity)
+}
+
+func Test_WriteHTMLSite(t *testing.T) {
+	fsys := os.DirFS("testdata/remediation")
+
+	cvss, err := ReadCVSSData(fsys)
+	require.NoError(t, err)
+
+	advisoryDocs, err := adv2.NewIndex(context.Background(), memfs.New(fsys))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	err = WriteHTMLSite(dir, ExportOptions{
+		AdvisoryDocIndices: []*configs.Index[v2.Document]{advisoryDocs},
+		CVSS:               cvss,
+	})
+	require.NoError(t, err)
+
+	read := func(path string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(dir, path))
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	index := read("index.html")
+	assert.Contains(t, index, `<a href="packages/crane.html">crane</a>`)
+	assert.Contains(t, index, `<a href="packages/ko.html">ko</a>`)
+
+	ko := read("packages/ko.html")
+	assert.Contains(t, ko, `<tr id="CGA-3333-3333-3333">`)
+	assert.Contains(t, ko, `<a href="../vulnerabilities/CVE-2024-3333.html">CVE-2024-3333</a>`)
+	assert.Contains(t, ko, "fixed in 0.15.1-r0")
+
+	// CVE-2024-2222 has advisories in both packages.
+	vuln := read("vulnerabilities/CVE-2024-2222.html")
+	assert.Contains(t, vuln, "CVSS 3.1: 9.8 CRITICAL")
+	assert.Contains(t, vuln, `<a href="../packages/crane.html#CGA-6666-6666-6666">CGA-6666-6666-6666</a>`)
+	assert.Contains(t, vuln, `<a href="../packages/ko.html#CGA-2222-2222-2222">CGA-2222-2222-2222</a>`)
+
+	var searchIndex []HTMLSiteSearchEntry
+	require.NoError(t, json.Unmarshal([]byte(read(HTMLSiteSearchIndexFileName)), &searchIndex))
+	require.Len(t, searchIndex, 6)
+	assert.Equal(t, HTMLSiteSearchEntry{
+		Package:      "ko",
+		ID:           "CGA-3333-3333-3333",
+		Aliases:      []string{"CVE-2024-3333"},
+		Status:       v2.EventTypeFixed,
+		FixedVersion: "0.15.1-r0",
+		Updated:      "2024-01-21T00:00:00Z",
+		URL:          "packages/ko.html#CGA-3333-3333-3333",
+	}, searchIndex[3])
+}
+
+func Test_ExportOptionsFilters(t *testing.T) {
+	advisoryDocs, err := adv2.NewIndex(context.Background(), rwos.DirFS("./testdata/export/advisories"))
+	require.NoError(t, err)
+	indices := []*configs.Index[v2.Document]{advisoryDocs}
+
+	exportedIDs := func(opts ExportOptions) []string {
+		opts.AdvisoryDocIndices = indices
+
+		var ids []string
+		for _, r := range ExportEventRecords(opts) {
+			if len(ids) == 0 || ids[len(ids)-1] != r.AdvisoryID {
+				ids = append(ids, r.AdvisoryID)
+			}
+		}
+		return ids
+	}
+
+	t.Run("packages", func(t *testing.T) {
+		ids := exportedIDs(ExportOptions{Packages: []string{"brotli", "ko"}})
+		assert.Len(t, ids, 6)
+		assert.Contains(t, ids, "CGA-37qj-pjrf-fmrw")
+		assert.NotContains(t, ids, "CGA-mm7m-x6cw-5fg4")
+	})
+
+	t.Run("modified since", func(t *testing.T) {
+		ids := exportedIDs(ExportOptions{ModifiedSince: time.Date(2023, 4, 8, 16, 32, 54, 0, time.UTC)})
+		assert.ElementsMatch(t, []string{
+			"CGA-5f5c-53mg-6p2v",
+			"CGA-4j8r-gcwr-9w6v",
+			"CGA-fpww-5q32-3pf7",
+			"CGA-4wr7-3v5h-hr26",
+			"CGA-gg4h-ppqq-vf35",
+			"CGA-7jpc-77v9-8xwj",
+			"CGA-mm7m-x6cw-5fg4",
+		}, ids)
+	})
+
+	t.Run("statuses", func(t *testing.T) {
+		ids := exportedIDs(ExportOptions{Statuses: []string{v2.EventTypeFalsePositiveDetermination}})
+		assert.Equal(t, []string{"CGA-mm7m-x6cw-5fg4"}, ids)
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		ids := exportedIDs(ExportOptions{
+			Packages:      []string{"openssl"},
+			ModifiedSince: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC),
+			Statuses:      []string{v2.EventTypeFixed},
+		})
+		assert.Equal(t, []string{"CGA-7jpc-77v9-8xwj"}, ids)
+	})
+
+	t.Run("documents without matching advisories are dropped", func(t *testing.T) {
+		exported, err := ExportYAML(ExportOptions{
+			AdvisoryDocIndices: indices,
+			Statuses:           []string{v2.EventTypeFalsePositiveDetermination},
+		})
+		require.NoError(t, err)
+
+		b, err := io.ReadAll(exported)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), "name: openssl")
+		assert.NotContains(t, string(b), "name: brotli")
+		assert.NotContains(t, string(b), "---")
+	})
+}
@@ -0,0 +1,153 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+)
+
+// FindFixOptions configures the FindFix operation.
+type FindFixOptions struct {
+	// AdvisoryDocs is the Index of advisory documents on which to operate.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// PackageVersionTimeline is used to find when the package's version moved past
+	// the vulnerable range.
+	PackageVersionTimeline PackageVersionTimeline
+
+	// Package is the name of the package the advisory is for.
+	Package string
+
+	// Vulnerability is the advisory's ID, or one of its aliases.
+	Vulnerability string
+
+	// UpstreamFixedVersion is the first upstream version of the package that isn't
+	// vulnerable, i.e. the end of the vulnerable range, e.g. "1.2.4".
+	UpstreamFixedVersion string
+}
+
+// FixProposal is a fixed event proposed for an advisory, based on the change to
+// the package's build configuration that moved the package's version past the
+// vulnerable range.
+type FixProposal struct {
+	// Package is the name of the package the advisory is for.
+	Package string
+
+	// Advisory is the advisory to resolve.
+	Advisory v2.Advisory
+
+	// VulnerableVersion is the last vulnerable version the package was defined
+	// with.
+	VulnerableVersion string
+
+	// FixedVersion is the version the package was defined with by the change that
+	// moved it past the vulnerable range.
+	FixedVersion string
+
+	// Commit is the hash of that change's commit, if known.
+	Commit string
+
+	// Time is when that change landed in the distro.
+	Time time.Time
+}
+
+// Request returns the advisory request that adds the proposed fixed event to
+// the advisory, using the time of the fix as the event's timestamp. If the fix
+// is older than the advisory's latest event (e.g. the advisory was marked as
+// pending an upstream fix after the fix had already landed), the event's
+// timestamp is just after the latest event instead, so that the fixed event
+// becomes the advisory's latest event.
+func (p FixProposal) Request() Request {
+	timestamp := p.Time.UTC()
+	if latest := time.Time(p.Advisory.Latest().Timestamp); !timestamp.After(latest) {
+		timestamp = latest.Add(time.Second)
+	}
+
+	return Request{
+		Package:    p.Package,
+		AdvisoryID: p.Advisory.ID,
+		Aliases:    p.Advisory.Aliases,
+		Event: v2.Event{
+			Timestamp: v2.Timestamp(timestamp),
+			Type:      v2.EventTypeFixed,
+			Data: v2.Fixed{
+				FixedVersion: p.FixedVersion,
+			},
+		},
+	}
+}
+
+// FindFix proposes a fixed event for a package's advisory, by finding the
+// change in the package's version history that moved the package's upstream
+// version from inside the vulnerable range (below UpstreamFixedVersion) to
+// outside of it.
+//
+// If the package's version went back into the vulnerable range later, the last
+// change out of the range is used, since the earlier fixes didn't stick.
+func FindFix(ctx context.Context, opts FindFixOptions) (*FixProposal, error) {
+	if opts.AdvisoryDocs == nil {
+		return nil, errors.New("advisory documents must be provided")
+	}
+	if opts.PackageVersionTimeline == nil {
+		return nil, errors.New("a package version timeline must be provided")
+	}
+
+	fixedUpstream, err := apk.ParseVersion(strings.TrimPrefix(opts.UpstreamFixedVersion, "v"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing upstream fixed version %q: %w", opts.UpstreamFixedVersion, err)
+	}
+
+	docs := opts.AdvisoryDocs.Select().WhereName(opts.Package).Configurations()
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("expected 1 advisory document for package %q, found %d", opts.Package, len(docs))
+	}
+
+	adv, ok := docs[0].Advisories.GetByVulnerability(opts.Vulnerability)
+	if !ok {
+		return nil, fmt.Errorf("no advisory for %s in package %q", opts.Vulnerability, opts.Package)
+	}
+	if adv.Latest().Type == v2.EventTypeFixed {
+		return nil, fmt.Errorf("advisory %s is already fixed", adv.ID)
+	}
+
+	timeline, err := opts.PackageVersionTimeline.Timeline(ctx, opts.Package)
+	if err != nil {
+		return nil, fmt.Errorf("getting version timeline for package %q: %w", opts.Package, err)
+	}
+
+	lastVulnerable := -1
+	for i, c := range timeline {
+		v, err := apk.ParseVersion(upstreamVersion(c.Version))
+		if err != nil {
+			continue
+		}
+		if apk.CompareVersions(v, fixedUpstream) < 0 {
+			lastVulnerable = i
+		}
+	}
+
+	switch {
+	case len(timeline) == 0:
+		return nil, fmt.Errorf("no version history found for package %q", opts.Package)
+	case lastVulnerable < 0:
+		return nil, fmt.Errorf("package %q was never defined with a version below %s", opts.Package, opts.UpstreamFixedVersion)
+	case lastVulnerable == len(timeline)-1:
+		return nil, fmt.Errorf("package %q is still defined with a vulnerable version (%s)", opts.Package, timeline[lastVulnerable].Version)
+	}
+
+	fix := timeline[lastVulnerable+1]
+	return &FixProposal{
+		Package:           opts.Package,
+		Advisory:          adv,
+		VulnerableVersion: timeline[lastVulnerable].Version,
+		FixedVersion:      fix.Version,
+		Commit:            fix.Commit,
+		Time:              fix.Time,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestFindFix(t *testing.T) {
+	ctx := context.Background()
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2023, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	timeline := mockPackageVersionTimeline{
+		"ko": {
+			{Version: "0.9.0-r0", Time: day(time.January, 1), Commit: "aaaa"},
+			{Version: "0.10.0-r0", Time: day(time.March, 1), Commit: "bbbb"},
+			// A revert back into the vulnerable range.
+			{Version: "0.9.0-r1", Time: day(time.March, 2), Commit: "cccc"},
+			{Version: "0.10.0-r1", Time: day(time.March, 10), Commit: "dddd"},
+			{Version: "1.0.0-r0", Time: day(time.April, 1), Commit: "eeee"},
+		},
+		"bar": {
+			{Version: "2.0.0-r0", Time: day(time.January, 1)},
+		},
+	}
+
+	advisoryDocs, err := adv2.NewIndex(ctx, memfs.New(os.DirFS("testdata/auto_resolve")))
+	require.NoError(t, err)
+
+	find := func(pkg, vuln, fixedIn string) (*FixProposal, error) {
+		return FindFix(ctx, FindFixOptions{
+			AdvisoryDocs:           advisoryDocs,
+			PackageVersionTimeline: timeline,
+			Package:                pkg,
+			Vulnerability:          vuln,
+			UpstreamFixedVersion:   fixedIn,
+		})
+	}
+
+	t.Run("fix found", func(t *testing.T) {
+		proposal, err := find("ko", "CVE-2023-2222", "v0.10.0")
+		require.NoError(t, err)
+
+		assert.Equal(t, "CGA-3333-3333-3333", proposal.Advisory.ID)
+		assert.Equal(t, "0.9.0-r1", proposal.VulnerableVersion)
+		assert.Equal(t, "0.10.0-r1", proposal.FixedVersion)
+		assert.Equal(t, "dddd", proposal.Commit)
+
+		// The fix is older than the advisory's pending upstream fix event.
+		req := proposal.Request()
+		require.NoError(t, req.Validate())
+		assert.Equal(t, v2.Event{
+			Timestamp: v2.Timestamp(day(time.March, 15).Add(time.Second)),
+			Type:      v2.EventTypeFixed,
+			Data:      v2.Fixed{FixedVersion: "0.10.0-r1"},
+		}, req.Event)
+	})
+
+	t.Run("by advisory ID", func(t *testing.T) {
+		proposal, err := find("ko", "CGA-2222-2222-2222", "1.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0-r0", proposal.FixedVersion)
+		assert.Equal(t, "eeee", proposal.Commit)
+		assert.Equal(t, v2.Timestamp(day(time.April, 1)), proposal.Request().Event.Timestamp)
+	})
+
+	t.Run("still vulnerable", func(t *testing.T) {
+		_, err := find("ko", "CVE-2023-2222", "1.1.0")
+		assert.ErrorContains(t, err, "still defined with a vulnerable version (1.0.0-r0)")
+	})
+
+	t.Run("never vulnerable", func(t *testing.T) {
+		_, err := find("bar", "CVE-2023-7777", "1.0.0")
+		assert.ErrorContains(t, err, "never defined with a version below 1.0.0")
+	})
+
+	t.Run("no advisory", func(t *testing.T) {
+		_, err := find("ko", "CVE-2023-9999", "1.0.0")
+		assert.Error(t, err)
+	})
+}
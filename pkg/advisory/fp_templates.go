@@ -0,0 +1,112 @@
+package advisory
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FalsePositiveTemplatesFileName is the name of the file at the root of an
+// advisories repo that stores the repo's false positive templates. The file is
+// hidden so that it's not mistaken for an advisory document.
+const FalsePositiveTemplatesFileName = ".false-positive-templates.yaml"
+
+// FalsePositiveTemplate is a commonly used false positive determination, which
+// can be referenced by name when creating or updating advisories instead of
+// spelling out its type and note each time.
+type FalsePositiveTemplate struct {
+	// Type is the type of false positive, one of v2.FPTypes.
+	Type string `yaml:"type"`
+
+	// Note is the prose explanation of the false positive.
+	Note string `yaml:"note"`
+}
+
+// ApplyTo fills in the false positive determination fields of the request
+// params that aren't set yet with the template's values, so that values given
+// explicitly take precedence over the template's. If the params' event type
+// isn't set, it's set to a false positive determination.
+func (t FalsePositiveTemplate) ApplyTo(p *RequestParams) error {
+	switch p.EventType {
+	case "":
+		p.EventType = v2.EventTypeFalsePositiveDetermination
+	case v2.EventTypeFalsePositiveDetermination:
+	default:
+		return fmt.Errorf("false positive templates can't be used with event type %q", p.EventType)
+	}
+
+	if p.FalsePositiveType == "" {
+		p.FalsePositiveType = t.Type
+	}
+	if p.FalsePositiveNote == "" && p.Note == "" {
+		p.Note = t.Note
+	}
+
+	return nil
+}
+
+// FalsePositiveTemplates are the false positive templates of an advisories
+// repo, keyed by name.
+type FalsePositiveTemplates map[string]FalsePositiveTemplate
+
+// ReadFalsePositiveTemplates reads the false positive templates from the
+// FalsePositiveTemplatesFileName file in the given filesystem. If the file
+// doesn't exist, there are no templates.
+func ReadFalsePositiveTemplates(fsys fs.FS) (FalsePositiveTemplates, error) {
+	b, err := fs.ReadFile(fsys, FalsePositiveTemplatesFileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return FalsePositiveTemplates{}, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", FalsePositiveTemplatesFileName, err)
+	}
+
+	templates := FalsePositiveTemplates{}
+	if err := yaml.Unmarshal(b, &templates); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", FalsePositiveTemplatesFileName, err)
+	}
+
+	var errs []error
+	for _, name := range templates.Names() {
+		t := templates[name]
+		fp := v2.FalsePositiveDetermination{Type: t.Type, Note: t.Note}
+		if err := fp.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("template %q: %w", name, err))
+		}
+		if t.Note == "" {
+			errs = append(errs, fmt.Errorf("template %q: note is required", name))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", FalsePositiveTemplatesFileName, err)
+	}
+
+	return templates, nil
+}
+
+// Names returns the names of the templates, sorted.
+func (t FalsePositiveTemplates) Names() []string {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the template with the given name.
+func (t FalsePositiveTemplates) Get(name string) (FalsePositiveTemplate, error) {
+	template, ok := t[name]
+	if !ok {
+		if len(t) == 0 {
+			return FalsePositiveTemplate{}, fmt.Errorf("no false positive template %q: the advisories repo has no templates (%s)", name, FalsePositiveTemplatesFileName)
+		}
+		return FalsePositiveTemplate{}, fmt.Errorf("no false positive template %q, available templates: %s", name, strings.Join(t.Names(), ", "))
+	}
+	return template, nil
+}
@@ -0,0 +1,96 @@
+package advisory
+
+import (
+	"testing"
+	"testing/fstest"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFalsePositiveTemplates(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			FalsePositiveTemplatesFileName: {Data: []byte(`
+fix-backported:
+  type: vulnerable-code-version-not-used
+  note: The fix for this vulnerability was backported to the packaged version.
+not-in-execution-path:
+  type: vulnerable-code-not-in-execution-path
+  note: The vulnerable code isn't reachable from the package's entry points.
+`)},
+		}
+
+		templates, err := ReadFalsePositiveTemplates(fsys)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"fix-backported", "not-in-execution-path"}, templates.Names())
+
+		template, err := templates.Get("fix-backported")
+		require.NoError(t, err)
+		assert.Equal(t, FalsePositiveTemplate{
+			Type: v2.FPTypeVulnerableCodeVersionNotUsed,
+			Note: "The fix for this vulnerability was backported to the packaged version.",
+		}, template)
+
+		_, err = templates.Get("fix-backport")
+		assert.ErrorContains(t, err, "available templates: fix-backported, not-in-execution-path")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		templates, err := ReadFalsePositiveTemplates(fstest.MapFS{})
+		require.NoError(t, err)
+		assert.Empty(t, templates)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			FalsePositiveTemplatesFileName: {Data: []byte(`
+bad-type:
+  type: not-a-type
+  note: Something.
+no-note:
+  type: vulnerable-code-not-included-in-package
+`)},
+		}
+
+		_, err := ReadFalsePositiveTemplates(fsys)
+		assert.ErrorContains(t, err, `template "bad-type": invalid false positive determination type "not-a-type"`)
+		assert.ErrorContains(t, err, `template "no-note": note is required`)
+	})
+}
+
+func TestFalsePositiveTemplate_ApplyTo(t *testing.T) {
+	template := FalsePositiveTemplate{
+		Type: v2.FPTypeVulnerableCodeVersionNotUsed,
+		Note: "The fix for this vulnerability was backported to the packaged version.",
+	}
+
+	t.Run("fills in missing values", func(t *testing.T) {
+		p := RequestParams{PackageNames: []string{"ko"}, Vulns: []string{"CVE-2024-2222"}, Timestamp: "now"}
+		require.NoError(t, template.ApplyTo(&p))
+		assert.Empty(t, p.MissingValues())
+
+		reqs, err := p.GenerateRequests()
+		require.NoError(t, err)
+		require.Len(t, reqs, 1)
+		assert.Equal(t, v2.EventTypeFalsePositiveDetermination, reqs[0].Event.Type)
+		assert.Equal(t, v2.FalsePositiveDetermination{Type: template.Type, Note: template.Note}, reqs[0].Event.Data)
+	})
+
+	t.Run("explicit values take precedence", func(t *testing.T) {
+		p := RequestParams{
+			EventType:         v2.EventTypeFalsePositiveDetermination,
+			FalsePositiveType: v2.FPTypeComponentVulnerabilityMismatch,
+			Note:              "Only the tests use the affected version.",
+		}
+		require.NoError(t, template.ApplyTo(&p))
+		assert.Equal(t, v2.FPTypeComponentVulnerabilityMismatch, p.FalsePositiveType)
+		assert.Equal(t, "Only the tests use the affected version.", p.Note)
+	})
+
+	t.Run("other event type", func(t *testing.T) {
+		p := RequestParams{EventType: v2.EventTypeFixed}
+		assert.Error(t, template.ApplyTo(&p))
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"path"
+	"sort"
 	"strings"
 
 	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
@@ -14,7 +16,8 @@ import (
 var _ Getter = (*FSGetter)(nil)
 
 // FSGetter is a getter that loads advisory data from YAML files in an
-// fs.FS on-demand, avoiding file opens/reads until needed.
+// fs.FS on-demand, avoiding file opens/reads until needed. Archived advisories
+// (see ArchiveDirName) are included with the package's other advisories.
 type FSGetter struct {
 	fsys fs.FS
 }
@@ -30,7 +33,35 @@ func (g FSGetter) PackageNames(_ context.Context) ([]string, error) {
 	// If we find this to be insufficient, we can evolve to a partial decode of the
 	// document, but that will use more memory and be slower.
 
-	entries, err := fs.ReadDir(g.fsys, ".")
+	names, err := packageNamesInDir(g.fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	archivedNames, err := packageNamesInDir(g.fsys, ArchiveDirName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if len(archivedNames) == 0 {
+		return names, nil
+	}
+
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		seen[name] = struct{}{}
+	}
+	for _, name := range archivedNames {
+		if _, ok := seen[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func packageNamesInDir(fsys fs.FS, dir string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
 	if err != nil {
 		return nil, fmt.Errorf("reading directory: %w", err)
 	}
@@ -51,7 +82,17 @@ func (g FSGetter) PackageNames(_ context.Context) ([]string, error) {
 
 func (g FSGetter) Advisories(_ context.Context, packageName string) ([]v2.PackageAdvisory, error) {
 	advFileName := fmt.Sprintf("%s.advisories.yaml", packageName)
-	return g.AdvisoriesFromFile(advFileName, packageName)
+	advisories, err := g.AdvisoriesFromFile(advFileName, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	archived, err := g.AdvisoriesFromFile(path.Join(ArchiveDirName, advFileName), packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(advisories, archived...), nil
 }
 
 // AdvisoriesFromFile is a function not part of the Getter interface. It allows
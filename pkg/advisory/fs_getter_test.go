@@ -20,7 +20,8 @@ func TestFSGetter(t *testing.T) {
 		names, err := g.PackageNames(ctx)
 		require.NoError(t, err)
 
-		expected := []string{"brotli"}
+		// zlib only has archived advisories.
+		expected := []string{"brotli", "zlib"}
 		assert.Equal(t, expected, names)
 	})
 
@@ -49,6 +50,22 @@ func TestFSGetter(t *testing.T) {
 						},
 					},
 				},
+				{
+					PackageName: "brotli",
+					Advisory: v2.Advisory{
+						ID: "CGA-yyyy-yyyy-yyyy",
+						Aliases: []string{
+							"CVE-2019-1234",
+						},
+						Events: []v2.Event{{
+							Timestamp: v2.Timestamp(time.Date(2019, 9, 15, 2, 40, 18, 0, time.UTC)),
+							Type:      v2.EventTypeFixed,
+							Data: v2.Fixed{
+								FixedVersion: "1.0.7-r0",
+							},
+						}},
+					},
+				},
 			}
 
 			if diff := cmp.Diff(expected, actual); diff != "" {
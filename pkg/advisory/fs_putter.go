@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"path"
 	"sort"
 
 	cgaid "github.com/chainguard-dev/advisory-schema/pkg/advisory"
@@ -64,6 +65,14 @@ func NewFSPutter(fsys rwfs.FS, enc DocumentEncoder) *FSPutter {
 // file at the root of the given `fsys`. If none is available, a default
 // configuration is used for the encoder.
 func NewFSPutterWithAutomaticEncoder(fsys rwfs.FS) *FSPutter {
+	return NewFSPutter(fsys, NewAutomaticYamDocumentEncoder(fsys))
+}
+
+// NewAutomaticYamDocumentEncoder creates a new DocumentEncoder like
+// NewYamDocumentEncoder, using the formatting options from the `.yam.yaml` file
+// at the root of the given `fsys`. If none is available, a default
+// configuration is used.
+func NewAutomaticYamDocumentEncoder(fsys fs.FS) DocumentEncoder {
 	// We'll set defaults to be used if we can't open and use the config file.
 	encodeOptions := formatted.EncodeOptions{
 		Indent:         2,
@@ -83,7 +92,7 @@ func NewFSPutterWithAutomaticEncoder(fsys rwfs.FS) *FSPutter {
 		}
 	}
 
-	return NewFSPutter(fsys, NewYamDocumentEncoder(encodeOptions))
+	return NewYamDocumentEncoder(encodeOptions)
 }
 
 func (p FSPutter) Upsert(_ context.Context, request Request) (string, error) {
@@ -99,6 +108,14 @@ func (p FSPutter) UpsertToFile(advFileName string, request Request) (string, err
 		return "", ErrEmptyPackage
 	}
 
+	// Hold a lock on the file from reading it to writing it, so that concurrent
+	// updates by other processes aren't lost.
+	unlock, err := rwfs.Lock(p.fsys, advFileName)
+	if err != nil {
+		return "", err
+	}
+	defer unlock() //nolint:errcheck
+
 	// The advisories file might exist, or not. If it does exist, the advisory
 	// itself might exist, or not.
 
@@ -136,6 +153,13 @@ func (p FSPutter) UpsertToFile(advFileName string, request Request) (string, err
 	// We set the schema to current whenever we're operating on the file.
 	doc.SchemaVersion = v2.SchemaVersion
 
+	// An archived advisory must be moved back to the package's document before it's
+	// updated, so that the package doesn't end up with two advisories for the same
+	// vulnerability.
+	if err := p.checkNotArchived(advFileName, request, doc); err != nil {
+		return "", err
+	}
+
 	// Find or create the advisory
 	var advisory *v2.Advisory
 	if reqID := request.AdvisoryID; reqID != "" {
@@ -203,6 +227,46 @@ func (p FSPutter) UpsertToFile(advFileName string, request Request) (string, err
 	return advisory.ID, nil
 }
 
+// checkNotArchived returns an error if the request is for an advisory that
+// isn't in doc but is in the package's archived advisory document.
+func (p FSPutter) checkNotArchived(advFileName string, request Request, doc *v2.Document) error {
+	if request.AdvisoryID != "" {
+		if _, exists := doc.Advisories.Get(request.AdvisoryID); exists {
+			return nil
+		}
+	} else if _, exists := doc.Advisories.GetByAnyVulnerability(request.Aliases...); exists {
+		return nil
+	}
+
+	archivedFileName := path.Join(ArchiveDirName, path.Base(advFileName))
+	f, err := p.fsys.Open(archivedFileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("opening archived advisory file %q: %w", archivedFileName, err)
+	}
+	defer f.Close()
+
+	archived, err := v2.DecodeDocument(f)
+	if err != nil {
+		return fmt.Errorf("decoding archived advisory file %q: %w", archivedFileName, err)
+	}
+
+	var adv v2.Advisory
+	var exists bool
+	if request.AdvisoryID != "" {
+		adv, exists = archived.Advisories.Get(request.AdvisoryID)
+	} else {
+		adv, exists = archived.Advisories.GetByAnyVulnerability(request.Aliases...)
+	}
+	if exists {
+		return fmt.Errorf("advisory %s for package %q is archived in %q, move it back to %q before updating it: %w", adv.ID, request.Package, archivedFileName, advFileName, ErrArchivedAdvisory)
+	}
+
+	return nil
+}
+
 func union(slice1, slice2 []string) []string {
 	m := make(map[string]struct{})
 	for _, s := range slice1 {
@@ -1,10 +1,12 @@
 package advisory
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/chainguard-dev/yam/pkg/yam/formatted"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
 	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/testerfs"
 )
 
@@ -203,3 +206,81 @@ func TestNewFSPutterWithAutomaticEncoder(t *testing.T) {
 		})
 	}
 }
+
+func TestFSPutter_ConcurrentUpserts(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	fsys := rwos.DirFS(dir)
+
+	// A slow encoder makes the upserts overlap, so that unsynchronized upserts
+	// would overwrite each other's changes.
+	enc := NewAutomaticYamDocumentEncoder(fsys)
+	p := NewFSPutter(fsys, func(w io.Writer, doc v2.Document) error {
+		time.Sleep(5 * time.Millisecond)
+		return enc(w, doc)
+	})
+
+	// Each upsert adds an advisory to the same (initially nonexistent) document.
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = p.Upsert(ctx, Request{
+				Package: "foo",
+				Aliases: []string{fmt.Sprintf("CVE-2024-%04d", 1000+i)},
+				Event:   v2.Event{Timestamp: v2.Now(), Type: v2.EventTypeDetection, Data: v2.Detection{Type: v2.DetectionTypeManual}},
+			})
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "foo.advisories.yaml"))
+	require.NoError(t, err)
+	defer f.Close()
+	doc, err := v2.DecodeDocument(f)
+	require.NoError(t, err)
+	assert.Len(t, doc.Advisories, n)
+}
+
+func TestFSPutter_ArchivedAdvisory(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, ArchiveDirName), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ArchiveDirName, "foo.advisories.yaml"), []byte(`schema-version: 2.0.2
+
+package:
+  name: foo
+
+advisories:
+  - id: CGA-1111-1111-1111
+    aliases:
+      - CVE-2023-1111
+    events:
+      - timestamp: 2022-09-15T02:40:18Z
+        type: fixed
+        data:
+          fixed-version: 1.2.3-r4
+`), 0o600))
+
+	p := NewFSPutterWithAutomaticEncoder(rwos.DirFS(dir))
+	event := v2.Event{Timestamp: v2.Now(), Type: v2.EventTypeDetection, Data: v2.Detection{Type: v2.DetectionTypeManual}}
+
+	_, err := p.Upsert(ctx, Request{Package: "foo", Aliases: []string{"CVE-2023-1111"}, Event: event})
+	assert.ErrorIs(t, err, ErrArchivedAdvisory)
+
+	_, err = p.Upsert(ctx, Request{Package: "foo", AdvisoryID: "CGA-1111-1111-1111", Event: event})
+	assert.ErrorIs(t, err, ErrArchivedAdvisory)
+
+	_, err = os.Stat(filepath.Join(dir, "foo.advisories.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// Other vulnerabilities of the package aren't affected.
+	_, err = p.Upsert(ctx, Request{Package: "foo", Aliases: []string{"CVE-2023-2222"}, Event: event})
+	assert.NoError(t, err)
+}
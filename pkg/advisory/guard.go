@@ -0,0 +1,102 @@
+package advisory
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/internal/errorhelpers"
+)
+
+// Guard checks the changes in the advisory data described by diff for
+// regressions, which are changes that lose or contradict the recorded history
+// of the advisories. It returns an error describing each regression, or nil if
+// there are none. The regressions are:
+//
+//   - a document or an advisory was removed;
+//   - an existing event was modified or removed, i.e. history was rewritten;
+//   - an event was added with a timestamp before the advisory's latest event,
+//     which moves the advisory's timeline backwards;
+//   - a fixed advisory was downgraded, i.e. its latest event is no longer a fix
+//     (or a false positive determination), or it's a fix in a lower version.
+func Guard(diff IndexDiffResult) error {
+	var errs []error
+
+	for _, doc := range diff.Removed {
+		errs = append(errs, errorhelpers.LabelError(doc.Name(), errors.New("document was removed")))
+	}
+
+	for _, doc := range diff.Modified {
+		for _, adv := range doc.Removed {
+			errs = append(errs, errorhelpers.LabelError(doc.Name, errorhelpers.LabelError(adv.ID, errors.New("advisory was removed"))))
+		}
+
+		for _, adv := range doc.Modified {
+			for _, err := range guardAdvisory(adv) {
+				errs = append(errs, errorhelpers.LabelError(doc.Name, errorhelpers.LabelError(adv.ID, err)))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func guardAdvisory(diff DiffResult) []error {
+	var errs []error
+
+	for _, e := range diff.RemovedEvents {
+		errs = append(errs, fmt.Errorf("event was modified or removed: %s at %s", describeEvent(e), e.Timestamp))
+	}
+
+	if len(diff.Removed.Events) == 0 {
+		return errs
+	}
+	before := diff.Removed.Latest()
+
+	for _, e := range diff.AddedEvents {
+		if time.Time(e.Timestamp).Before(time.Time(before.Timestamp)) {
+			errs = append(errs, fmt.Errorf("added event (%s) has timestamp %s, which is before the advisory's latest event (%s at %s)", describeEvent(e), e.Timestamp, describeEvent(before), before.Timestamp))
+		}
+	}
+
+	if err := guardFixedStatus(before, diff.Added); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// guardFixedStatus returns an error if the advisory was fixed as of the event
+// before, and isn't fixed anymore or is fixed in a lower version.
+func guardFixedStatus(before v2.Event, adv v2.Advisory) error {
+	fixedBefore, ok := before.Data.(v2.Fixed)
+	if !ok || len(adv.Events) == 0 {
+		return nil
+	}
+	after := adv.Latest()
+
+	switch after.Type {
+	case v2.EventTypeFalsePositiveDetermination:
+		return nil
+
+	case v2.EventTypeFixed:
+		fixedAfter, ok := after.Data.(v2.Fixed)
+		if !ok {
+			return nil
+		}
+
+		vBefore, errBefore := apk.ParseVersion(fixedBefore.FixedVersion)
+		vAfter, errAfter := apk.ParseVersion(fixedAfter.FixedVersion)
+		if errBefore != nil || errAfter != nil {
+			return nil
+		}
+		if apk.CompareVersions(vAfter, vBefore) < 0 {
+			return fmt.Errorf("fixed version was downgraded from %s to %s", fixedBefore.FixedVersion, fixedAfter.FixedVersion)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("status was downgraded from fixed (%s) to %s", fixedBefore.FixedVersion, after.Type)
+}
@@ -0,0 +1,167 @@
+package advisory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+func TestGuard(t *testing.T) {
+	day := func(d int) v2.Timestamp {
+		return v2.Timestamp(time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC))
+	}
+	detection := v2.Event{Timestamp: day(1), Type: v2.EventTypeDetection, Data: v2.Detection{Type: v2.DetectionTypeManual}}
+	fixed := func(ts v2.Timestamp, version string) v2.Event {
+		return v2.Event{Timestamp: ts, Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: version}}
+	}
+
+	base := v2.Document{
+		SchemaVersion: v2.SchemaVersion,
+		Package:       v2.Package{Name: "ko"},
+		Advisories: v2.Advisories{
+			{ID: "CGA-2222-2222-2222", Aliases: []string{"CVE-2024-2222"}, Events: []v2.Event{detection, fixed(day(3), "0.15.2-r1")}},
+			{ID: "CGA-3333-3333-3333", Aliases: []string{"CVE-2024-3333"}, Events: []v2.Event{detection}},
+		},
+	}
+
+	withEvents := func(events ...v2.Event) v2.Document {
+		doc := base
+		doc.Advisories = v2.Advisories{
+			{ID: "CGA-2222-2222-2222", Aliases: []string{"CVE-2024-2222"}, Events: events},
+			base.Advisories[1],
+		}
+		return doc
+	}
+
+	cases := []struct {
+		name     string
+		diff     IndexDiffResult
+		expected []string
+	}{
+		{
+			name: "event appended",
+			diff: IndexDiffResult{Modified: []DocumentDiffResult{
+				documentDiff(base, withEvents(detection, fixed(day(3), "0.15.2-r1"), fixed(day(4), "0.15.3-r0"))),
+			}},
+		},
+		{
+			name: "reclassified as false positive",
+			diff: IndexDiffResult{Modified: []DocumentDiffResult{
+				documentDiff(base, withEvents(detection, fixed(day(3), "0.15.2-r1"), v2.Event{
+					Timestamp: day(4),
+					Type:      v2.EventTypeFalsePositiveDetermination,
+					Data:      v2.FalsePositiveDetermination{Type: v2.FPTypeVulnerableCodeNotIncludedInPackage},
+				})),
+			}},
+		},
+		{
+			name:     "document removed",
+			diff:     IndexDiffResult{Removed: []v2.Document{base}},
+			expected: []string{"ko: document was removed"},
+		},
+		{
+			name: "advisory removed",
+			diff: IndexDiffResult{Modified: []DocumentDiffResult{
+				documentDiff(base, v2.Document{SchemaVersion: base.SchemaVersion, Package: base.Package, Advisories: base.Advisories[1:]}),
+			}},
+			expected: []string{"ko: CGA-2222-2222-2222: advisory was removed"},
+		},
+		{
+			name: "event rewritten",
+			diff: IndexDiffResult{Modified: []DocumentDiffResult{
+				documentDiff(base, withEvents(detection, fixed(day(3), "0.15.3-r0"))),
+			}},
+			expected: []string{"ko: CGA-2222-2222-2222: event was modified or removed: fixed 0.15.2-r1 at 2024-05-03T00:00:00Z"},
+		},
+		{
+			name: "timestamp moved backwards",
+			diff: IndexDiffResult{Modified: []DocumentDiffResult{
+				documentDiff(base, withEvents(detection, fixed(day(3), "0.15.2-r1"), fixed(day(2), "0.15.3-r0"))),
+			}},
+			expected: []string{"added event (fixed 0.15.3-r0) has timestamp 2024-05-02T00:00:00Z, which is before the advisory's latest event (fixed 0.15.2-r1 at 2024-05-03T00:00:00Z)"},
+		},
+		{
+			name: "status downgraded",
+			diff: IndexDiffResult{Modified: []DocumentDiffResult{
+				documentDiff(base, withEvents(detection, fixed(day(3), "0.15.2-r1"), v2.Event{
+					Timestamp: day(4),
+					Type:      v2.EventTypeTruePositiveDetermination,
+					Data:      v2.TruePositiveDetermination{},
+				})),
+			}},
+			expected: []string{"ko: CGA-2222-2222-2222: status was downgraded from fixed (0.15.2-r1) to true-positive-determination"},
+		},
+		{
+			name: "fixed version downgraded",
+			diff: IndexDiffResult{Modified: []DocumentDiffResult{
+				documentDiff(base, withEvents(detection, fixed(day(3), "0.15.2-r1"), fixed(day(4), "0.15.2-r0"))),
+			}},
+			expected: []string{"ko: CGA-2222-2222-2222: fixed version was downgraded from 0.15.2-r1 to 0.15.2-r0"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Guard(tt.diff)
+			if len(tt.expected) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			for _, msg := range tt.expected {
+				assert.ErrorContains(t, err, msg)
+			}
+		})
+	}
+}
+
+func TestGuardArchiveMove(t *testing.T) {
+	ctx := t.Context()
+
+	baseDir, headDir := t.TempDir(), t.TempDir()
+	for _, dir := range []string{baseDir, headDir} {
+		for _, name := range []string{"brotli", "ko", "openssl"} {
+			b, err := os.ReadFile("testdata/export/advisories/" + name + ".advisories.yaml")
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name+".advisories.yaml"), b, 0o600))
+		}
+	}
+
+	// Archive some of the head's advisories, removing brotli's document.
+	archiveDir := filepath.Join(headDir, ArchiveDirName)
+	require.NoError(t, os.Mkdir(archiveDir, 0o755))
+	docs, err := adv2.NewIndex(ctx, rwos.DirFS(headDir))
+	require.NoError(t, err)
+	archived, err := adv2.NewIndex(ctx, rwos.DirFS(archiveDir))
+	require.NoError(t, err)
+	groups, err := FindArchivable(ArchiveOptions{
+		AdvisoryDocs: docs,
+		Horizon:      time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	emptied, err := ArchiveAdvisories(ctx, docs, archived, groups)
+	require.NoError(t, err)
+	for _, path := range emptied {
+		require.NoError(t, os.Remove(filepath.Join(headDir, path)))
+	}
+
+	// Without the archive, the moves look like removals.
+	baseRoot, err := adv2.NewIndex(ctx, rwos.DirFS(baseDir))
+	require.NoError(t, err)
+	headRoot, err := adv2.NewIndex(ctx, rwos.DirFS(headDir))
+	require.NoError(t, err)
+	assert.Error(t, Guard(IndexDiff(baseRoot, headRoot)))
+
+	base, err := IndexWithArchive(ctx, os.DirFS(baseDir))
+	require.NoError(t, err)
+	head, err := IndexWithArchive(ctx, os.DirFS(headDir))
+	require.NoError(t, err)
+	assert.NoError(t, Guard(IndexDiff(base, head)))
+}
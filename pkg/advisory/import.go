@@ -3,13 +3,23 @@ package advisory
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
+	cgaid "github.com/chainguard-dev/advisory-schema/pkg/advisory"
 	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/advisory-schema/pkg/vuln"
+	"github.com/chainguard-dev/clog"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/samber/lo"
 	"github.com/wolfi-dev/wolfictl/pkg/configs"
 	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
 	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
@@ -30,6 +40,279 @@ func ImporAdvisoriesYAML(inputData []byte) (tempDir string, documents *configs.I
 		docs = append(docs, pkg)
 	}
 
+	return indexDocuments(docs)
+}
+
+// ImportOSVOptions configures ImportAdvisoriesOSV.
+type ImportOSVOptions struct {
+	// Ecosystem is the OSV ecosystem of the packages to import advisories for (e.g.
+	// "Wolfi", or "Alpine", which also matches versioned ecosystems like
+	// "Alpine:v3.20"). If empty, the advisories for packages in any ecosystem are
+	// imported.
+	Ecosystem string
+
+	// Packages are the names of the packages to import advisories for. If empty,
+	// the advisories for all packages are imported.
+	Packages []string
+}
+
+// ImportAdvisoriesOSV imports OSV vulnerability records (e.g. from osv.dev or
+// another distro's OSV feed) as advisories, and presents them as a config index
+// struct. The input is either a single OSV record or a JSON array of them.
+func ImportAdvisoriesOSV(ctx context.Context, inputData []byte, opts ImportOSVOptions) (tempDir string, documents *configs.Index[v2.Document], err error) {
+	vulnerabilities, err := DecodeOSVRecords(inputData)
+	if err != nil {
+		return "", nil, err
+	}
+
+	docs, err := OSVToDocuments(ctx, vulnerabilities, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return indexDocuments(docs)
+}
+
+// DecodeOSVRecords decodes OSV vulnerability records from either a single OSV
+// record or a JSON array of them.
+func DecodeOSVRecords(inputData []byte) ([]models.Vulnerability, error) {
+	var vulnerabilities []models.Vulnerability
+	var err error
+	if trimmed := bytes.TrimSpace(inputData); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &vulnerabilities)
+	} else {
+		var v models.Vulnerability
+		err = json.Unmarshal(trimmed, &v)
+		vulnerabilities = append(vulnerabilities, v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal OSV input: %w", err)
+	}
+
+	return vulnerabilities, nil
+}
+
+// OSVRequests translates OSV vulnerability records to requests for ImportRequests,
+// with one request per event of the advisories that OSVToDocuments returns for
+// the records, in timestamp order.
+//
+// The requests identify the advisories by their aliases, not by the records'
+// CGA IDs, since those are usually assigned by another advisories repo. Records
+// without aliases are skipped.
+func OSVRequests(ctx context.Context, vulnerabilities []models.Vulnerability, opts ImportOSVOptions) ([]Request, error) {
+	log := clog.FromContext(ctx)
+
+	docs, err := OSVToDocuments(ctx, vulnerabilities, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []Request
+	for _, doc := range docs {
+		for _, adv := range doc.Advisories {
+			if len(adv.Aliases) == 0 {
+				log.Warn("skipping OSV record without a CVE, GHSA, or Go vulnerability ID", "package", doc.Name(), "id", adv.ID)
+				continue
+			}
+
+			for _, event := range adv.Events {
+				req := Request{
+					Package: doc.Name(),
+					Aliases: adv.Aliases,
+					Event:   event,
+				}
+				if err := req.Validate(); err != nil {
+					return nil, fmt.Errorf("translating OSV data for %v in package %q: %w", adv.Aliases, doc.Name(), err)
+				}
+
+				requests = append(requests, req)
+			}
+		}
+	}
+
+	sort.SliceStable(requests, func(i, j int) bool {
+		return time.Time(requests[i].Event.Timestamp).Before(time.Time(requests[j].Event.Timestamp))
+	})
+
+	return requests, nil
+}
+
+// OSVToDocuments converts OSV vulnerability records to advisory documents, with
+// one document per affected package, sorted by package name.
+//
+// Each record becomes an advisory for each of its affected packages. The
+// advisory's ID is the record's ID if it's a CGA ID, and a new CGA ID
+// otherwise. Its aliases are the record's other CVE, GHSA, and Go vulnerability
+// IDs. A record without any such ID is skipped, since the advisory wouldn't say
+// which vulnerability it's about.
+//
+// The advisory's event is derived from the affected package's "ECOSYSTEM"
+// ranges, and is timestamped with the record's modification time:
+//
+//   - A range fixed at version "0" (i.e. no version was ever affected) becomes a
+//     false positive determination.
+//   - Otherwise, the latest fixed version becomes a fixed event.
+//   - If no range is fixed, the package is still affected, so the event is a
+//     true positive determination.
+func OSVToDocuments(ctx context.Context, vulnerabilities []models.Vulnerability, opts ImportOSVOptions) ([]v2.Document, error) {
+	log := clog.FromContext(ctx)
+
+	docsByPackage := make(map[string]*v2.Document)
+	for i := range vulnerabilities {
+		v := vulnerabilities[i]
+
+		id, aliases := osvAdvisoryIDs(v)
+		if id == "" && len(aliases) == 0 {
+			log.Warn("skipping OSV record without a CGA, CVE, GHSA, or Go vulnerability ID", "id", v.ID)
+			continue
+		}
+
+		timestamp := v.Modified
+		if timestamp.IsZero() {
+			timestamp = v.Published
+		}
+
+		for _, affected := range v.Affected {
+			name := affected.Package.Name
+			if !osvEcosystemMatches(affected.Package.Ecosystem, opts.Ecosystem) {
+				continue
+			}
+			if len(opts.Packages) > 0 && !slices.Contains(opts.Packages, name) {
+				continue
+			}
+
+			doc, ok := docsByPackage[name]
+			if !ok {
+				doc = &v2.Document{
+					SchemaVersion: v2.SchemaVersion,
+					Package:       v2.Package{Name: name},
+				}
+				docsByPackage[name] = doc
+			}
+
+			event := osvEvent(v.ID, affected, timestamp)
+
+			if j := slices.IndexFunc(doc.Advisories, func(adv v2.Advisory) bool {
+				return (id != "" && adv.ID == id) || slices.ContainsFunc(aliases, adv.DescribesVulnerability)
+			}); j >= 0 {
+				// Another record is about the same vulnerability, so the advisory is the
+				// combination of them.
+				adv := doc.Advisories[j].MergeInAliases(aliases...)
+				adv.Events = append(adv.Events, event)
+				doc.Advisories[j] = adv
+				continue
+			}
+
+			advID := id
+			if advID == "" {
+				var err error
+				advID, err = cgaid.GenerateCGAID()
+				if err != nil {
+					return nil, fmt.Errorf("generating CGA ID: %w", err)
+				}
+			}
+
+			doc.Advisories = append(doc.Advisories, v2.Advisory{
+				ID:      advID,
+				Aliases: aliases,
+				Events:  []v2.Event{event},
+			})
+		}
+	}
+
+	docs := make([]v2.Document, 0, len(docsByPackage))
+	for _, name := range lo.Keys(docsByPackage) {
+		doc := docsByPackage[name]
+		sort.Sort(doc.Advisories)
+		docs = append(docs, *doc)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Package.Name < docs[j].Package.Name
+	})
+
+	return docs, nil
+}
+
+// osvAdvisoryIDs returns the advisory ID (if the record has a CGA ID) and the
+// aliases for the OSV record.
+func osvAdvisoryIDs(v models.Vulnerability) (id string, aliases []string) {
+	for _, vulnID := range append([]string{v.ID}, v.Aliases...) {
+		switch {
+		case cgaid.RegexCGA.MatchString(vulnID):
+			if id == "" {
+				id = vulnID
+			}
+
+		case vuln.ValidateID(vulnID) == nil:
+			if !slices.Contains(aliases, vulnID) {
+				aliases = append(aliases, vulnID)
+			}
+		}
+	}
+
+	sort.Strings(aliases)
+	return id, aliases
+}
+
+func osvEcosystemMatches(ecosystem models.Ecosystem, expected string) bool {
+	if expected == "" {
+		return true
+	}
+
+	name, _, _ := strings.Cut(string(ecosystem), ":")
+	return strings.EqualFold(name, expected)
+}
+
+// osvEvent returns the advisory event for the affected package of the OSV
+// record with the given ID.
+func osvEvent(osvID string, affected models.Affected, timestamp time.Time) v2.Event {
+	var fixedVersion string
+	for _, r := range affected.Ranges {
+		if r.Type != models.RangeEcosystem {
+			continue
+		}
+
+		for _, e := range r.Events {
+			switch {
+			case e.Fixed == "":
+				continue
+
+			case e.Fixed == "0":
+				return v2.Event{
+					Timestamp: v2.Timestamp(timestamp),
+					Type:      v2.EventTypeFalsePositiveDetermination,
+					Data: v2.FalsePositiveDetermination{
+						Type: v2.FPTypeVulnerableCodeVersionNotUsed,
+						Note: fmt.Sprintf("No version of the package is affected, according to OSV record %s.", osvID),
+					},
+				}
+
+			case fixedVersion == "" || versionAtLeast(e.Fixed, fixedVersion):
+				fixedVersion = e.Fixed
+			}
+		}
+	}
+
+	if fixedVersion == "" {
+		return v2.Event{
+			Timestamp: v2.Timestamp(timestamp),
+			Type:      v2.EventTypeTruePositiveDetermination,
+			Data: v2.TruePositiveDetermination{
+				Note: fmt.Sprintf("The package is affected and not yet fixed, according to OSV record %s.", osvID),
+			},
+		}
+	}
+
+	return v2.Event{
+		Timestamp: v2.Timestamp(timestamp),
+		Type:      v2.EventTypeFixed,
+		Data:      v2.Fixed{FixedVersion: fixedVersion},
+	}
+}
+
+// indexDocuments writes the advisory documents to a temporary directory and
+// returns the directory and an index of its documents.
+func indexDocuments(docs []v2.Document) (tempDir string, documents *configs.Index[v2.Document], err error) {
 	tempDir, err = os.MkdirTemp("", "adv-")
 	if err != nil {
 		return "", nil, fmt.Errorf("unable to create temporary directory: %v", err)
@@ -2,10 +2,18 @@ package advisory
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
+	"time"
 
+	cgaid "github.com/chainguard-dev/advisory-schema/pkg/advisory"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
 	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
 
 	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
@@ -39,3 +47,250 @@ func Test_ImportAdvisoriesYAML(t *testing.T) {
 		})
 	}
 }
+
+func Test_OSVToDocuments(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round trip", func(t *testing.T) {
+		advisoryDocs, err := adv2.NewIndex(ctx, rwos.DirFS("./testdata/export/advisories"))
+		require.NoError(t, err)
+
+		vulnerabilities, err := ExportOSV(ctx, ExportOptions{
+			AdvisoryDocIndices: []*configs.Index[v2.Document]{advisoryDocs},
+			Ecosystem:          "Wolfi",
+		})
+		require.NoError(t, err)
+
+		docs, err := OSVToDocuments(ctx, vulnerabilities, ImportOSVOptions{Ecosystem: "wolfi"})
+		require.NoError(t, err)
+		require.Len(t, docs, 3)
+
+		for _, doc := range docs {
+			require.NoError(t, doc.Validate())
+
+			original, ok := lo.Find(advisoryDocs.Select().Configurations(), func(d v2.Document) bool {
+				return d.Package.Name == doc.Package.Name
+			})
+			require.True(t, ok)
+
+			for _, adv := range doc.Advisories {
+				originalAdv, ok := original.Advisories.Get(adv.ID)
+				require.True(t, ok, "advisory %q not in original document", adv.ID)
+				assert.Equal(t, originalAdv.Aliases, adv.Aliases)
+				assert.Equal(t, originalAdv.Latest().Type, adv.Latest().Type)
+				if adv.Latest().Type == v2.EventTypeFixed {
+					assert.Equal(t, originalAdv.Latest().Data, adv.Latest().Data)
+				}
+			}
+		}
+	})
+
+	t.Run("other distro", func(t *testing.T) {
+		cgaid.DefaultIDGenerator = cgaid.StaticIDGenerator{ID: "CGA-2222-2222-2222"}
+		defer func() { cgaid.DefaultIDGenerator = &cgaid.RandomIDGenerator{} }()
+
+		modified := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+		vulnerabilities := []models.Vulnerability{
+			{
+				ID:       "ALPINE-CVE-2024-1234",
+				Aliases:  []string{"CVE-2024-1234", "GHSA-2222-3333-4444"},
+				Modified: modified,
+				Affected: []models.Affected{
+					{
+						Package: models.Package{Name: "curl", Ecosystem: "Alpine:v3.19"},
+						Ranges: []models.Range{{
+							Type:   models.RangeEcosystem,
+							Events: []models.Event{{Introduced: "0"}, {Fixed: "8.5.0-r0"}},
+						}},
+					},
+					{
+						Package: models.Package{Name: "curl", Ecosystem: "Alpine:v3.20"},
+						Ranges: []models.Range{{
+							Type:   models.RangeEcosystem,
+							Events: []models.Event{{Introduced: "0"}, {Fixed: "8.6.0-r0"}},
+						}},
+					},
+					{
+						Package: models.Package{Name: "curl", Ecosystem: "Debian:12"},
+						Ranges: []models.Range{{
+							Type:   models.RangeEcosystem,
+							Events: []models.Event{{Introduced: "0"}, {Fixed: "9.0.0-1"}},
+						}},
+					},
+				},
+			},
+			{
+				ID:       "ALPINE-CVE-2024-5678",
+				Aliases:  []string{"CVE-2024-5678"},
+				Modified: modified,
+				Affected: []models.Affected{{
+					Package: models.Package{Name: "openssl", Ecosystem: "Alpine:v3.20"},
+					Ranges: []models.Range{{
+						Type:   models.RangeEcosystem,
+						Events: []models.Event{{Introduced: "3.2.0-r0"}},
+					}},
+				}},
+			},
+			{
+				ID:       "ALPINE-CVE-2024-9999",
+				Aliases:  []string{"CVE-2024-9999"},
+				Modified: modified,
+				Affected: []models.Affected{{
+					Package: models.Package{Name: "openssl", Ecosystem: "Alpine:v3.20"},
+					Ranges: []models.Range{{
+						Type:   models.RangeEcosystem,
+						Events: []models.Event{{Introduced: "0"}, {Fixed: "0"}},
+					}},
+				}},
+			},
+			{
+				ID:       "ALPINE-NO-ALIASES",
+				Modified: modified,
+				Affected: []models.Affected{{
+					Package: models.Package{Name: "zlib", Ecosystem: "Alpine:v3.20"},
+				}},
+			},
+		}
+
+		docs, err := OSVToDocuments(ctx, vulnerabilities, ImportOSVOptions{Ecosystem: "Alpine"})
+		require.NoError(t, err)
+		require.Len(t, docs, 2)
+
+		curl := docs[0]
+		assert.Equal(t, "curl", curl.Package.Name)
+		require.Len(t, curl.Advisories, 1)
+		assert.Equal(t, "CGA-2222-2222-2222", curl.Advisories[0].ID)
+		assert.Equal(t, []string{"CVE-2024-1234", "GHSA-2222-3333-4444"}, curl.Advisories[0].Aliases)
+		latest := curl.Advisories[0].Latest()
+		assert.Equal(t, v2.EventTypeFixed, latest.Type)
+		assert.Equal(t, v2.Fixed{FixedVersion: "8.6.0-r0"}, latest.Data)
+		assert.Equal(t, v2.Timestamp(modified), latest.Timestamp)
+
+		openssl := docs[1]
+		assert.Equal(t, "openssl", openssl.Package.Name)
+		require.Len(t, openssl.Advisories, 2)
+		types := lo.Map(openssl.Advisories, func(adv v2.Advisory, _ int) string { return adv.Latest().Type })
+		assert.ElementsMatch(t, []string{v2.EventTypeTruePositiveDetermination, v2.EventTypeFalsePositiveDetermination}, types)
+
+		t.Run("packages", func(t *testing.T) {
+			docs, err := OSVToDocuments(ctx, vulnerabilities, ImportOSVOptions{Packages: []string{"openssl"}})
+			require.NoError(t, err)
+			require.Len(t, docs, 1)
+			assert.Equal(t, "openssl", docs[0].Package.Name)
+		})
+	})
+}
+
+func Test_ImportAdvisoriesOSV(t *testing.T) {
+	record := []byte(`{
+  "id": "GHSA-2222-3333-4444",
+  "aliases": ["CVE-2024-1234"],
+  "modified": "2024-05-06T07:08:09Z",
+  "affected": [{
+    "package": {"ecosystem": "Wolfi", "name": "curl"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "8.6.0-r0"}]}]
+  }]
+}`)
+
+	for name, input := range map[string][]byte{
+		"single record": record,
+		"array":         append(append([]byte("["), record...), ']'),
+	} {
+		t.Run(name, func(t *testing.T) {
+			tempDir, importedDocuments, err := ImportAdvisoriesOSV(context.Background(), input, ImportOSVOptions{})
+			require.NoError(t, err)
+			defer os.RemoveAll(tempDir)
+
+			require.Equal(t, 1, importedDocuments.Select().Len())
+			doc := importedDocuments.Select().Configurations()[0]
+			assert.Equal(t, "curl", doc.Package.Name)
+			require.Len(t, doc.Advisories, 1)
+			assert.Equal(t, []string{"CVE-2024-1234", "GHSA-2222-3333-4444"}, doc.Advisories[0].Aliases)
+		})
+	}
+}
+
+func Test_OSVRequests(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	b, err := os.ReadFile("testdata/export/advisories/brotli.advisories.yaml")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dir+"/brotli.advisories.yaml", b, 0o600))
+
+	vulnerabilities, err := DecodeOSVRecords([]byte(`[
+  {
+    "id": "CGA-aaaa-bbbb-cccc",
+    "aliases": ["CVE-2020-8927"],
+    "modified": "2024-05-01T00:00:00Z",
+    "affected": [{
+      "package": {"ecosystem": "Wolfi", "name": "brotli"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.0.9-r0"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-2222-3333-4444",
+    "aliases": ["CVE-2024-1234"],
+    "modified": "2024-05-03T00:00:00Z",
+    "affected": [
+      {
+        "package": {"ecosystem": "Wolfi", "name": "curl"},
+        "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "8.6.0-r0"}]}]
+      },
+      {
+        "package": {"ecosystem": "Alpine:v3.20", "name": "curl"},
+        "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "8.5.0-r1"}]}]
+      }
+    ]
+  },
+  {
+    "id": "CVE-2024-1234",
+    "modified": "2024-05-02T00:00:00Z",
+    "affected": [{
+      "package": {"ecosystem": "Wolfi", "name": "curl"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}]}]
+    }]
+  },
+  {
+    "id": "CGA-dddd-eeee-ffff",
+    "modified": "2024-05-04T00:00:00Z",
+    "affected": [{
+      "package": {"ecosystem": "Wolfi", "name": "zlib"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}]}]
+    }]
+  }
+]`))
+	require.NoError(t, err)
+
+	requests, err := OSVRequests(ctx, vulnerabilities, ImportOSVOptions{Ecosystem: "Wolfi"})
+	require.NoError(t, err)
+
+	// The record without aliases is skipped, and the curl records are merged into
+	// one advisory, with its events in timestamp order.
+	var summary []string
+	for _, req := range requests {
+		summary = append(summary, fmt.Sprintf("%s %v %s", req.Package, req.Aliases, req.Event.Type))
+	}
+	assert.Equal(t, []string{
+		"brotli [CVE-2020-8927] fixed",
+		"curl [CVE-2024-1234 GHSA-2222-3333-4444] true-positive-determination",
+		"curl [CVE-2024-1234 GHSA-2222-3333-4444] fixed",
+	}, summary)
+
+	result, err := ImportRequests(ctx, requests, ImportOptions{
+		Getter: NewFSGetter(os.DirFS(dir)),
+		Putter: NewFSPutterWithAutomaticEncoder(rwos.DirFS(dir)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ImportResult{Created: 1, Updated: 1, Skipped: 1}, result)
+
+	index, err := adv2.NewIndex(ctx, rwos.DirFS(dir))
+	require.NoError(t, err)
+	curl := index.Select().WhereName("curl").Configurations()
+	require.Len(t, curl, 1)
+	require.NoError(t, curl[0].Validate())
+	adv, ok := curl[0].Advisories.GetByVulnerability("GHSA-2222-3333-4444")
+	require.True(t, ok)
+	assert.Len(t, adv.Events, 2)
+	assert.Equal(t, v2.Fixed{FixedVersion: "8.6.0-r0"}, adv.Latest().Data)
+}
@@ -0,0 +1,160 @@
+package advisory
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/hashicorp/go-version"
+)
+
+// ErrMergeConflict is returned by MergeDocuments when the changes made to a
+// document on both sides of a merge can't be combined.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// MergeDocuments merges the changes made to an advisory document on two sides
+// ("ours" and "theirs") of a merge, relative to their common ancestor ("base"),
+// which is the zero Document if both sides added the document.
+//
+// Instead of merging the documents' text, their contents are merged: the
+// result has the union of the advisories of both sides, and for advisories on
+// both sides, the union of their aliases and events, with the events ordered by
+// timestamp. Advisories, aliases and events removed on one side are removed,
+// as long as the other side didn't change them. The result's schema version is
+// the newer of the two sides' schema versions.
+//
+// If the sides can't be merged, because the documents are for different
+// packages, an advisory was removed on one side and changed on the other, or
+// the merged document isn't valid (e.g. both sides added an advisory for the
+// same vulnerability), an error wrapping ErrMergeConflict is returned.
+func MergeDocuments(base, ours, theirs v2.Document) (v2.Document, error) {
+	if ours.Package.Name != theirs.Package.Name {
+		return v2.Document{}, fmt.Errorf("%w: documents are for different packages (%q and %q)", ErrMergeConflict, ours.Package.Name, theirs.Package.Name)
+	}
+
+	schemaVersion, err := newerSchemaVersion(ours.SchemaVersion, theirs.SchemaVersion)
+	if err != nil {
+		return v2.Document{}, err
+	}
+
+	merged := v2.Document{
+		SchemaVersion: schemaVersion,
+		Package:       ours.Package,
+	}
+
+	var conflicts []error
+
+	ids := merge3(nil, advisoryIDs(ours.Advisories), advisoryIDs(theirs.Advisories), func(a, b string) bool { return a == b })
+	for _, id := range ids {
+		b, inBase := base.Advisories.Get(id)
+		o, inOurs := ours.Advisories.Get(id)
+		t, inTheirs := theirs.Advisories.Get(id)
+
+		switch {
+		case inOurs && inTheirs:
+			merged.Advisories = append(merged.Advisories, mergeAdvisories(b, o, t))
+
+		case inOurs:
+			if !inBase {
+				merged.Advisories = append(merged.Advisories, o)
+				continue
+			}
+			if !reflect.DeepEqual(b, o) {
+				conflicts = append(conflicts, fmt.Errorf("%w: advisory %s was removed in theirs, but changed in ours", ErrMergeConflict, id))
+			}
+
+		case inTheirs:
+			if !inBase {
+				merged.Advisories = append(merged.Advisories, t)
+				continue
+			}
+			if !reflect.DeepEqual(b, t) {
+				conflicts = append(conflicts, fmt.Errorf("%w: advisory %s was removed in ours, but changed in theirs", ErrMergeConflict, id))
+			}
+		}
+	}
+
+	if err := errors.Join(conflicts...); err != nil {
+		return v2.Document{}, err
+	}
+
+	sort.Sort(merged.Advisories)
+
+	// Both sides may have added an advisory for the same vulnerability, under
+	// different IDs. That can't be resolved automatically.
+	if err := merged.Validate(); err != nil {
+		return v2.Document{}, fmt.Errorf("%w: merged document is invalid: %w", ErrMergeConflict, err)
+	}
+
+	return merged, nil
+}
+
+func mergeAdvisories(base, ours, theirs v2.Advisory) v2.Advisory {
+	merged := v2.Advisory{
+		ID:      ours.ID,
+		Aliases: merge3(base.Aliases, ours.Aliases, theirs.Aliases, func(a, b string) bool { return a == b }),
+		Events:  merge3(base.Events, ours.Events, theirs.Events, eventsEqual),
+	}
+
+	sort.Strings(merged.Aliases)
+	sort.SliceStable(merged.Events, func(i, j int) bool {
+		return merged.Events[i].Timestamp.Before(merged.Events[j].Timestamp)
+	})
+
+	return merged
+}
+
+func eventsEqual(a, b v2.Event) bool {
+	return a.Timestamp.Equal(b.Timestamp) && a.Type == b.Type && reflect.DeepEqual(a.Data, b.Data)
+}
+
+// merge3 merges two changed versions of a set of elements. The result has the
+// elements of ours, followed by the elements only in theirs, leaving out the
+// elements of base that were removed by either side.
+func merge3[T any](base, ours, theirs []T, equal func(a, b T) bool) []T {
+	contains := func(s []T, x T) bool {
+		return slices.ContainsFunc(s, func(y T) bool { return equal(x, y) })
+	}
+
+	var merged []T
+	for _, x := range ours {
+		if contains(base, x) && !contains(theirs, x) {
+			continue
+		}
+		merged = append(merged, x)
+	}
+	for _, x := range theirs {
+		if contains(ours, x) || contains(base, x) {
+			continue
+		}
+		merged = append(merged, x)
+	}
+
+	return merged
+}
+
+func newerSchemaVersion(a, b string) (string, error) {
+	if a == b || b == "" {
+		return a, nil
+	}
+	if a == "" {
+		return b, nil
+	}
+
+	va, err := version.NewVersion(a)
+	if err != nil {
+		return "", fmt.Errorf("parsing schema version %q: %w", a, err)
+	}
+	vb, err := version.NewVersion(b)
+	if err != nil {
+		return "", fmt.Errorf("parsing schema version %q: %w", b, err)
+	}
+
+	if vb.GreaterThan(va) {
+		return b, nil
+	}
+	return a, nil
+}
@@ -0,0 +1,108 @@
+package advisory
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeDocuments(t *testing.T) {
+	ts := func(day int) v2.Timestamp {
+		return v2.Timestamp(time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC))
+	}
+	detection := func(day int) v2.Event {
+		return v2.Event{Timestamp: ts(day), Type: v2.EventTypeDetection, Data: v2.Detection{Type: v2.DetectionTypeManual}}
+	}
+	fixed := func(day int, version string) v2.Event {
+		return v2.Event{Timestamp: ts(day), Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: version}}
+	}
+	doc := func(schemaVersion string, advs ...v2.Advisory) v2.Document {
+		return v2.Document{SchemaVersion: schemaVersion, Package: v2.Package{Name: "ko"}, Advisories: advs}
+	}
+
+	adv2222 := v2.Advisory{ID: "CGA-2222-2222-2222", Aliases: []string{"CVE-2024-2222"}, Events: []v2.Event{detection(1)}}
+	adv3333 := v2.Advisory{ID: "CGA-3333-3333-3333", Aliases: []string{"CVE-2024-3333"}, Events: []v2.Event{detection(2)}}
+	adv4444 := v2.Advisory{ID: "CGA-4444-4444-4444", Aliases: []string{"CVE-2024-4444"}, Events: []v2.Event{detection(3)}}
+
+	t.Run("concurrent additions", func(t *testing.T) {
+		base := doc("2.0.1", adv2222)
+
+		ours := doc("2.0.2", adv2222, adv4444)
+		ours.Advisories[0].Events = append(slices.Clone(adv2222.Events), fixed(10, "0.15.0-r0"))
+
+		theirs := doc("2.0.1", adv2222, adv3333)
+		theirs.Advisories[0].Aliases = []string{"CVE-2024-2222", "GHSA-2222-2222-2222"}
+		theirs.Advisories[0].Events = append([]v2.Event{fixed(5, "0.14.0-r1")}, adv2222.Events...)
+
+		merged, err := MergeDocuments(base, ours, theirs)
+		require.NoError(t, err)
+
+		assert.Equal(t, "2.0.2", merged.SchemaVersion)
+		assert.Equal(t, "ko", merged.Package.Name)
+		require.Len(t, merged.Advisories, 3)
+		assert.Equal(t, v2.Advisory{
+			ID:      "CGA-2222-2222-2222",
+			Aliases: []string{"CVE-2024-2222", "GHSA-2222-2222-2222"},
+			Events:  []v2.Event{detection(1), fixed(5, "0.14.0-r1"), fixed(10, "0.15.0-r0")},
+		}, merged.Advisories[0])
+		assert.Equal(t, adv3333, merged.Advisories[1])
+		assert.Equal(t, adv4444, merged.Advisories[2])
+	})
+
+	t.Run("both sides added the document", func(t *testing.T) {
+		merged, err := MergeDocuments(v2.Document{}, doc("2.0.2", adv2222), doc("2.0.2", adv2222, adv3333))
+		require.NoError(t, err)
+		assert.Equal(t, doc("2.0.2", adv2222, adv3333), merged)
+	})
+
+	t.Run("removals", func(t *testing.T) {
+		base := doc("2.0.2", adv2222, adv3333)
+		base.Advisories[0].Events = []v2.Event{detection(1), fixed(5, "0.14.0-r1")}
+
+		// Ours removes an event, theirs removes an advisory.
+		ours := doc("2.0.2", adv2222, adv3333)
+		theirs := doc("2.0.2", base.Advisories[0])
+
+		merged, err := MergeDocuments(base, ours, theirs)
+		require.NoError(t, err)
+		assert.Equal(t, doc("2.0.2", adv2222), merged)
+	})
+
+	t.Run("removed and changed", func(t *testing.T) {
+		base := doc("2.0.2", adv2222, adv3333)
+
+		changed := adv3333
+		changed.Events = append(slices.Clone(adv3333.Events), fixed(5, "0.14.0-r1"))
+		ours := doc("2.0.2", adv2222, changed)
+		theirs := doc("2.0.2", adv2222)
+
+		_, err := MergeDocuments(base, ours, theirs)
+		assert.ErrorIs(t, err, ErrMergeConflict)
+		assert.ErrorContains(t, err, "CGA-3333-3333-3333 was removed in theirs")
+	})
+
+	t.Run("same vulnerability added under different IDs", func(t *testing.T) {
+		base := doc("2.0.2", adv2222)
+
+		other := adv3333
+		other.ID = "CGA-5555-5555-5555"
+		ours := doc("2.0.2", adv2222, adv3333)
+		theirs := doc("2.0.2", adv2222, other)
+
+		_, err := MergeDocuments(base, ours, theirs)
+		assert.ErrorIs(t, err, ErrMergeConflict)
+		assert.ErrorContains(t, err, "merged document is invalid")
+	})
+
+	t.Run("different packages", func(t *testing.T) {
+		theirs := doc("2.0.2", adv2222)
+		theirs.Package.Name = "crane"
+
+		_, err := MergeDocuments(v2.Document{}, doc("2.0.2", adv2222), theirs)
+		assert.ErrorIs(t, err, ErrMergeConflict)
+	})
+}
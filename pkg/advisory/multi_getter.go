@@ -0,0 +1,117 @@
+package advisory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+)
+
+// assert that MultiGetter implements Getter
+var _ Getter = (*MultiGetter)(nil)
+
+// MultiGetter is a getter that combines the advisory data from multiple
+// getters, such as the advisories repositories of more than one distro.
+//
+// When more than one getter has an advisory for the same vulnerability (i.e.
+// the advisories share an ID or an alias) in the same package, the advisories
+// are merged into one: its aliases are the union of the advisories' aliases,
+// and its events are the advisories' events in chronological order. This means
+// the most recent event across all getters determines the advisory's current
+// state. The merged advisory keeps the ID of the advisory from the first getter
+// that has one.
+type MultiGetter struct {
+	getters []Getter
+}
+
+func NewMultiGetter(getters ...Getter) *MultiGetter {
+	return &MultiGetter{
+		getters: getters,
+	}
+}
+
+func (g MultiGetter) PackageNames(ctx context.Context) ([]string, error) {
+	seen := make(map[string]struct{})
+	var names []string
+
+	for _, getter := range g.getters {
+		getterNames, err := getter.PackageNames(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range getterNames {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+func (g MultiGetter) Advisories(ctx context.Context, packageName string) ([]v2.PackageAdvisory, error) {
+	var result []v2.PackageAdvisory
+
+	// indexByVulnID maps advisory IDs and aliases to an index in result.
+	indexByVulnID := make(map[string]int)
+
+	for i, getter := range g.getters {
+		advs, err := getter.Advisories(ctx, packageName)
+		if err != nil {
+			return nil, fmt.Errorf("getting advisories from getter %d: %w", i, err)
+		}
+
+		for _, adv := range advs {
+			idx, ok := findAdvisoryIndex(indexByVulnID, adv.Advisory)
+			if !ok {
+				idx = len(result)
+				result = append(result, v2.PackageAdvisory{
+					PackageName: packageName,
+					Advisory: v2.Advisory{
+						ID:      adv.ID,
+						Aliases: slices.Clone(adv.Aliases),
+						Events:  slices.Clone(adv.Events),
+					},
+				})
+			} else {
+				merged := &result[idx].Advisory
+				for _, alias := range adv.Aliases {
+					if !slices.Contains(merged.Aliases, alias) {
+						merged.Aliases = append(merged.Aliases, alias)
+					}
+				}
+				merged.Events = append(merged.Events, adv.Events...)
+				sort.SliceStable(merged.Events, func(a, b int) bool {
+					return time.Time(merged.Events[a].Timestamp).Before(time.Time(merged.Events[b].Timestamp))
+				})
+			}
+
+			indexByVulnID[adv.ID] = idx
+			for _, alias := range adv.Aliases {
+				indexByVulnID[alias] = idx
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func findAdvisoryIndex(indexByVulnID map[string]int, adv v2.Advisory) (int, bool) {
+	if idx, ok := indexByVulnID[adv.ID]; ok {
+		return idx, true
+	}
+
+	for _, alias := range adv.Aliases {
+		if idx, ok := indexByVulnID[alias]; ok {
+			return idx, true
+		}
+	}
+
+	return 0, false
+}
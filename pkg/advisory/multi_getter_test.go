@@ -0,0 +1,91 @@
+package advisory
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiGetter(t *testing.T) {
+	ctx := t.Context()
+	g := NewMultiGetter(
+		NewFSGetter(os.DirFS(filepath.Join("testdata", "multi_getter", "wolfi"))),
+		NewFSGetter(os.DirFS(filepath.Join("testdata", "multi_getter", "enterprise"))),
+	)
+
+	t.Run("PackageNames", func(t *testing.T) {
+		names, err := g.PackageNames(ctx)
+		require.NoError(t, err)
+
+		sort.Strings(names)
+		assert.Equal(t, []string{"brotli", "zlib"}, names)
+	})
+
+	t.Run("Advisories", func(t *testing.T) {
+		actual, err := g.Advisories(ctx, "brotli")
+		require.NoError(t, err)
+
+		expected := []v2.PackageAdvisory{
+			{
+				PackageName: "brotli",
+				Advisory: v2.Advisory{
+					ID:      "CGA-aaaa-aaaa-aaaa",
+					Aliases: []string{"CVE-2020-8927", "GHSA-5v8v-66v8-mwm7"},
+					Events: []v2.Event{
+						{
+							Timestamp: v2.Timestamp(time.Date(2022, 9, 15, 2, 40, 18, 0, time.UTC)),
+							Type:      v2.EventTypeDetection,
+							Data:      v2.Detection{Type: v2.DetectionTypeManual},
+						},
+						{
+							Timestamp: v2.Timestamp(time.Date(2022, 9, 16, 0, 0, 0, 0, time.UTC)),
+							Type:      v2.EventTypeFixed,
+							Data:      v2.Fixed{FixedVersion: "1.0.9-r0"},
+						},
+					},
+				},
+			},
+			{
+				PackageName: "brotli",
+				Advisory: v2.Advisory{
+					ID:      "CGA-bbbb-bbbb-bbbb",
+					Aliases: []string{"CVE-2023-0001"},
+					Events: []v2.Event{
+						{
+							Timestamp: v2.Timestamp(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+							Type:      v2.EventTypeFalsePositiveDetermination,
+							Data:      v2.FalsePositiveDetermination{Type: v2.FPTypeVulnerableCodeNotIncludedInPackage},
+						},
+					},
+				},
+			},
+		}
+
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Fatalf("unexpected advisories (-want +got):\n%s", diff)
+		}
+
+		// The merged advisory's latest event comes from the second getter.
+		assert.True(t, actual[0].ResolvedAtVersion("1.0.9-r0", "apk"))
+	})
+
+	t.Run("only in one getter", func(t *testing.T) {
+		actual, err := g.Advisories(ctx, "zlib")
+		require.NoError(t, err)
+		require.Len(t, actual, 1)
+		assert.Equal(t, "CGA-dddd-dddd-dddd", actual[0].ID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		actual, err := g.Advisories(ctx, "not-found")
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+}
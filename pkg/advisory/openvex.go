@@ -0,0 +1,209 @@
+package advisory
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/google/osv-scanner/pkg/models"
+	version "github.com/knqyf263/go-apk-version"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// OpenVEXStatement returns the OpenVEX statement about a package that's
+// supported by the advisory's latest event, if the event supports one. The
+// statement has no products; the caller adds the package as its product.
+//
+// If packageVersion is set, the statement is about that version of the package:
+// it's "fixed" only if the version is the fixed version or later, and
+// "affected" otherwise. If it's empty, the statement is about the package in
+// general, so a fix always makes it "fixed".
+//
+// The statuses by event type are:
+//
+//   - fixed: "fixed" (see above)
+//   - false-positive-determination: "not_affected", with a justification
+//   - true-positive-determination, pending-upstream-fix, fix-not-planned:
+//     "affected"
+//   - detection: "under_investigation"
+//
+// Advisories whose latest event is "analysis-not-planned" have no statement.
+func OpenVEXStatement(adv v2.Advisory, packageVersion string) (vex.Statement, bool) {
+	if len(adv.Events) == 0 {
+		return vex.Statement{}, false
+	}
+	latest := adv.Latest()
+
+	timestamp := time.Time(latest.Timestamp).UTC()
+	statement := vex.Statement{
+		Vulnerability: openVEXVulnerability(adv),
+		Timestamp:     &timestamp,
+	}
+
+	switch latest.Type {
+	case v2.EventTypeFixed:
+		d, ok := latest.Data.(v2.Fixed)
+		if !ok {
+			return vex.Statement{}, false
+		}
+		if packageVersion != "" && !versionAtLeast(packageVersion, d.FixedVersion) {
+			statement.Status = vex.StatusAffected
+			statement.ActionStatement = fmt.Sprintf("Upgrade to version %s or later.", d.FixedVersion)
+			break
+		}
+		statement.Status = vex.StatusFixed
+		statement.StatusNotes = fmt.Sprintf("fixed in version %s", d.FixedVersion)
+
+	case v2.EventTypeFalsePositiveDetermination:
+		statement.Status = vex.StatusNotAffected
+		statement.Justification = vex.VulnerableCodeNotPresent
+		if d, ok := latest.Data.(v2.FalsePositiveDetermination); ok {
+			statement.Justification = openVEXJustification(d.Type)
+			statement.ImpactStatement = d.Note
+		}
+
+	case v2.EventTypeTruePositiveDetermination:
+		statement.Status = vex.StatusAffected
+		statement.ActionStatement = "No fix is available yet."
+		if d, ok := latest.Data.(v2.TruePositiveDetermination); ok && d.Note != "" {
+			statement.StatusNotes = d.Note
+		}
+
+	case v2.EventTypePendingUpstreamFix:
+		statement.Status = vex.StatusAffected
+		statement.ActionStatement = "A fix is pending from the upstream project."
+		if d, ok := latest.Data.(v2.PendingUpstreamFix); ok && d.Note != "" {
+			statement.StatusNotes = d.Note
+		}
+
+	case v2.EventTypeFixNotPlanned:
+		statement.Status = vex.StatusAffected
+		statement.ActionStatement = "No fix is planned."
+		if d, ok := latest.Data.(v2.FixNotPlanned); ok && d.Note != "" {
+			statement.StatusNotes = d.Note
+		}
+
+	case v2.EventTypeDetection:
+		statement.Status = vex.StatusUnderInvestigation
+
+	default:
+		return vex.Statement{}, false
+	}
+
+	return statement, true
+}
+
+// openVEXVulnerability identifies the advisory's vulnerability by its CVE ID,
+// if it has one, with its other IDs (including the advisory's ID) as aliases.
+func openVEXVulnerability(adv v2.Advisory) vex.Vulnerability {
+	ids := append([]string{adv.ID}, adv.Aliases...)
+
+	name := ids[0]
+	if i := slices.IndexFunc(ids, func(id string) bool { return strings.HasPrefix(id, "CVE-") }); i >= 0 {
+		name = ids[i]
+	}
+
+	v := vex.Vulnerability{Name: vex.VulnerabilityID(name)}
+	for _, id := range ids {
+		if id != name {
+			v.Aliases = append(v.Aliases, vex.VulnerabilityID(id))
+		}
+	}
+
+	return v
+}
+
+func openVEXJustification(fpType string) vex.Justification {
+	switch fpType {
+	case v2.FPTypeComponentVulnerabilityMismatch:
+		return vex.ComponentNotPresent
+
+	case v2.FPTypeVulnerableCodeNotInExecutionPath:
+		return vex.VulnerableCodeNotInExecutePath
+
+	case v2.FPTypeVulnerableCodeCannotBeControlledByAdversary:
+		return vex.VulnerableCodeCannotBeControlledByAdversary
+
+	case v2.FPTypeInlineMitigationsExist:
+		return vex.InlineMitigationsAlreadyExist
+	}
+
+	return vex.VulnerableCodeNotPresent
+}
+
+// versionAtLeast returns true if the APK version v is the same as or later than
+// the APK version minimum. Invalid versions aren't comparable, so the result is
+// false.
+func versionAtLeast(v, minimum string) bool {
+	a, err := version.NewVersion(v)
+	if err != nil {
+		return false
+	}
+	b, err := version.NewVersion(minimum)
+	if err != nil {
+		return false
+	}
+
+	return !a.LessThan(b)
+}
+
+// ExportOpenVEX returns a reader of an OpenVEX document with a statement for
+// each advisory that has one (see OpenVEXStatement), about the advisory's
+// package in the ecosystem given by opts.Ecosystem.
+//
+// The statements' products are identified by the packages' package URLs. Since
+// a fix applies to the fixed version and later versions, a "fixed" statement's
+// product is the package at its fixed version.
+func ExportOpenVEX(opts ExportOptions) (io.Reader, error) {
+	if opts.Ecosystem == "" {
+		return nil, fmt.Errorf("an ecosystem is required for OpenVEX export")
+	}
+	ecosystem := models.Ecosystem(opts.Ecosystem)
+
+	doc := vex.New()
+	doc.Author = "wolfictl"
+	doc.Tooling = "wolfictl"
+
+	for _, index := range opts.AdvisoryDocIndices {
+		for _, advDoc := range opts.documents(index) {
+			name := advDoc.Package.Name
+
+			for _, adv := range advDoc.Advisories {
+				statement, ok := OpenVEXStatement(adv, "")
+				if !ok {
+					continue
+				}
+
+				purl := createPurl(name, ecosystem)
+				if statement.Status == vex.StatusFixed {
+					if d, ok := adv.Latest().Data.(v2.Fixed); ok {
+						purl += "@" + d.FixedVersion
+					}
+				}
+				statement.Products = []vex.Product{{
+					Component: vex.Component{
+						ID:          purl,
+						Identifiers: map[vex.IdentifierType]string{vex.PURL: purl},
+					},
+				}}
+
+				doc.Statements = append(doc.Statements, statement)
+			}
+		}
+	}
+
+	if _, err := doc.GenerateCanonicalID(); err != nil {
+		return nil, fmt.Errorf("generating OpenVEX document ID: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := doc.ToJSON(buf); err != nil {
+		return nil, fmt.Errorf("encoding OpenVEX document: %w", err)
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,332 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	cgaid "github.com/chainguard-dev/advisory-schema/pkg/advisory"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/advisory-schema/pkg/vuln"
+	"github.com/chainguard-dev/clog"
+	"github.com/openvex/go-vex/pkg/vex"
+	"github.com/package-url/packageurl-go"
+)
+
+// OpenVEXRequests translates the statements of an OpenVEX document into advisory
+// requests, one for each package and vulnerability. This is the inverse of
+// OpenVEXStatement.
+//
+// A statement's products are matched to packages by their APK package URLs. If
+// a product isn't an APK (e.g. it's a container image), its subcomponents that
+// are APKs are used instead. The statuses map to events as follows:
+//
+//   - not_affected: a false positive determination, whose type is derived from
+//     the statement's justification, and whose note is its impact statement.
+//   - fixed: a fixed event, whose fixed version is the product's package URL
+//     version. Products without a version are skipped.
+//   - affected: a true positive determination, whose note is the statement's
+//     status notes or action statement.
+//   - under_investigation: a manual detection.
+//
+// Events are timestamped with the statement's timestamp, or else the document's.
+func OpenVEXRequests(ctx context.Context, doc *vex.VEX) ([]Request, error) {
+	log := clog.FromContext(ctx)
+
+	var requests []Request
+	for i := range doc.Statements {
+		s := doc.Statements[i]
+
+		aliases := openVEXAliases(s.Vulnerability)
+		if len(aliases) == 0 {
+			log.Warn("skipping OpenVEX statement without a CVE, GHSA, or Go vulnerability ID", "vulnerability", s.Vulnerability.Name)
+			continue
+		}
+
+		timestamp := v2.Now()
+		switch {
+		case s.Timestamp != nil:
+			timestamp = v2.Timestamp(*s.Timestamp)
+		case doc.Timestamp != nil:
+			timestamp = v2.Timestamp(*doc.Timestamp)
+		}
+
+		for _, purl := range openVEXStatementAPKs(s) {
+			event, ok := openVEXEvent(s, purl, timestamp)
+			if !ok {
+				log.Warn("skipping OpenVEX statement that has no corresponding event", "product", purl.ToString(), "status", s.Status, "vulnerability", aliases[0])
+				continue
+			}
+
+			req := Request{
+				Package: purl.Name,
+				Aliases: aliases,
+				Event:   event,
+			}
+			if err := req.Validate(); err != nil {
+				return nil, fmt.Errorf("translating %s statement about %s for %s: %w", s.Status, purl.ToString(), aliases[0], err)
+			}
+
+			if slices.ContainsFunc(requests, func(r Request) bool {
+				return r.Package == req.Package && r.Event == req.Event && slices.Equal(r.Aliases, req.Aliases)
+			}) {
+				continue
+			}
+
+			requests = append(requests, req)
+		}
+	}
+
+	return requests, nil
+}
+
+// openVEXStatementAPKs returns the package URLs of the APKs that the statement
+// is about.
+func openVEXStatementAPKs(s vex.Statement) []packageurl.PackageURL {
+	var purls []packageurl.PackageURL
+	for _, p := range s.Products {
+		if purl, ok := componentAPK(p.Component); ok {
+			purls = append(purls, purl)
+			continue
+		}
+
+		for _, sc := range p.Subcomponents {
+			if purl, ok := componentAPK(sc.Component); ok {
+				purls = append(purls, purl)
+			}
+		}
+	}
+
+	return purls
+}
+
+func componentAPK(c vex.Component) (packageurl.PackageURL, bool) {
+	for _, id := range []string{c.Identifiers[vex.PURL], c.ID} {
+		if !strings.HasPrefix(id, "pkg:") {
+			continue
+		}
+		if purl, err := packageurl.FromString(id); err == nil && purl.Type == "apk" {
+			return purl, true
+		}
+	}
+
+	return packageurl.PackageURL{}, false
+}
+
+// openVEXAliases returns the vulnerability's IDs that are valid advisory
+// aliases.
+func openVEXAliases(v vex.Vulnerability) []string {
+	ids := []string{string(v.Name)}
+	for _, alias := range v.Aliases {
+		ids = append(ids, string(alias))
+	}
+
+	var aliases []string
+	for _, id := range ids {
+		if id == "" || cgaid.RegexCGA.MatchString(id) || vuln.ValidateID(id) != nil {
+			continue
+		}
+		if !slices.Contains(aliases, id) {
+			aliases = append(aliases, id)
+		}
+	}
+
+	return aliases
+}
+
+func openVEXEvent(s vex.Statement, purl packageurl.PackageURL, timestamp v2.Timestamp) (v2.Event, bool) {
+	event := v2.Event{Timestamp: timestamp}
+
+	switch s.Status {
+	case vex.StatusNotAffected:
+		event.Type = v2.EventTypeFalsePositiveDetermination
+		event.Data = v2.FalsePositiveDetermination{
+			Type: falsePositiveTypeForJustification(s.Justification),
+			Note: s.ImpactStatement,
+		}
+
+	case vex.StatusFixed:
+		if purl.Version == "" {
+			return v2.Event{}, false
+		}
+		event.Type = v2.EventTypeFixed
+		event.Data = v2.Fixed{FixedVersion: purl.Version}
+
+	case vex.StatusAffected:
+		note := s.StatusNotes
+		if note == "" {
+			note = s.ActionStatement
+		}
+		event.Type = v2.EventTypeTruePositiveDetermination
+		event.Data = v2.TruePositiveDetermination{Note: note}
+
+	case vex.StatusUnderInvestigation:
+		event.Type = v2.EventTypeDetection
+		event.Data = v2.Detection{Type: v2.DetectionTypeManual}
+
+	default:
+		return v2.Event{}, false
+	}
+
+	return event, true
+}
+
+// falsePositiveTypeForJustification returns the false positive determination
+// type for the VEX justification. This is the inverse of openVEXJustification.
+func falsePositiveTypeForJustification(j vex.Justification) string {
+	switch j {
+	case vex.ComponentNotPresent:
+		return v2.FPTypeComponentVulnerabilityMismatch
+
+	case vex.VulnerableCodeNotInExecutePath:
+		return v2.FPTypeVulnerableCodeNotInExecutionPath
+
+	case vex.VulnerableCodeCannotBeControlledByAdversary:
+		return v2.FPTypeVulnerableCodeCannotBeControlledByAdversary
+
+	case vex.InlineMitigationsAlreadyExist:
+		return v2.FPTypeInlineMitigationsExist
+	}
+
+	return v2.FPTypeVulnerableCodeNotIncludedInPackage
+}
+
+// ConflictError is returned when a request's event contradicts the latest event
+// of the existing advisory that it would be added to.
+type ConflictError struct {
+	Request    Request
+	AdvisoryID string
+	Latest     v2.Event
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf(
+		"%s: %s event contradicts the latest event of advisory %s (%s)",
+		e.Request.Package,
+		describeEvent(e.Request.Event),
+		e.AdvisoryID,
+		describeEvent(e.Latest),
+	)
+}
+
+func describeEvent(e v2.Event) string {
+	if d, ok := e.Data.(v2.Fixed); ok {
+		return fmt.Sprintf("%s %s", e.Type, d.FixedVersion)
+	}
+	return e.Type
+}
+
+// Conflict returns a *ConflictError if the request's event contradicts the
+// advisory's latest event, and nil otherwise.
+//
+// The events contradict each other if they conclude different VEX statuses
+// (see OpenVEXStatement), except that an "affected" advisory can be fixed, or
+// if they're fixes in different versions. Events that don't conclude a status
+// (e.g. detections) never conflict.
+func Conflict(adv v2.Advisory, req Request) error {
+	if len(adv.Events) == 0 {
+		return nil
+	}
+	latest := adv.Latest()
+
+	current, ok := OpenVEXStatement(adv, "")
+	if !ok {
+		return nil
+	}
+	incoming, ok := OpenVEXStatement(v2.Advisory{ID: adv.ID, Events: []v2.Event{req.Event}}, "")
+	if !ok {
+		return nil
+	}
+
+	conflict := &ConflictError{Request: req, AdvisoryID: adv.ID, Latest: latest}
+
+	switch {
+	case current.Status == vex.StatusUnderInvestigation || incoming.Status == vex.StatusUnderInvestigation:
+		return nil
+
+	case current.Status == vex.StatusAffected && incoming.Status == vex.StatusFixed:
+		return nil
+
+	case current.Status != incoming.Status:
+		return conflict
+
+	case current.Status == vex.StatusFixed && current.StatusNotes != incoming.StatusNotes:
+		return conflict
+	}
+
+	return nil
+}
+
+// ImportOptions configures ImportRequests.
+type ImportOptions struct {
+	// Getter is used to find the existing advisories of the requests' packages.
+	Getter Getter
+
+	// Putter stores the requests' advisory data.
+	Putter Putter
+
+	// Force imports requests that conflict with existing advisories, instead of
+	// skipping them.
+	Force bool
+}
+
+// ImportResult summarizes the outcome of ImportRequests.
+type ImportResult struct {
+	// Created is the number of new advisories.
+	Created int
+
+	// Updated is the number of events appended to existing advisories.
+	Updated int
+
+	// Skipped is the number of requests whose event was the same as the existing
+	// advisory's latest event.
+	Skipped int
+}
+
+// ImportRequests merges imported advisory requests into existing advisory data:
+// it creates a new advisory for each request without one, and appends the
+// request's event to the existing advisory otherwise.
+//
+// Requests whose event is the same as the advisory's latest event are skipped.
+// Requests that conflict with the advisory (see Conflict) aren't imported
+// unless opts.Force is set; the returned error joins their *ConflictError
+// values, after the other requests are imported.
+func ImportRequests(ctx context.Context, requests []Request, opts ImportOptions) (ImportResult, error) {
+	var result ImportResult
+	var conflicts []error
+
+	for _, req := range requests {
+		advs, err := opts.Getter.Advisories(ctx, req.Package)
+		if err != nil {
+			return result, fmt.Errorf("getting advisories for package %q: %w", req.Package, err)
+		}
+
+		existing := MatchToRequest(advs, req)
+		if existing != nil {
+			if latest := existing.Latest(); latest.Type == req.Event.Type && reflect.DeepEqual(latest.Data, req.Event.Data) {
+				result.Skipped++
+				continue
+			}
+
+			if err := Conflict(existing.Advisory, req); err != nil && !opts.Force {
+				conflicts = append(conflicts, err)
+				continue
+			}
+		}
+
+		if _, err := opts.Putter.Upsert(ctx, req); err != nil {
+			return result, fmt.Errorf("importing advisory data for %q (%v): %w", req.Package, req.Aliases, err)
+		}
+
+		if existing == nil {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	return result, errors.Join(conflicts...)
+}
@@ -0,0 +1,196 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/openvex/go-vex/pkg/vex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+func TestOpenVEXRequests(t *testing.T) {
+	ctx := context.Background()
+	timestamp := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	doc := vex.New()
+	doc.Timestamp = &timestamp
+	doc.Statements = []vex.Statement{
+		{
+			Vulnerability: vex.Vulnerability{Name: "CVE-2024-2398", Aliases: []vex.VulnerabilityID{"GHSA-2222-3333-4444", "ALPINE-1234"}},
+			Products: []vex.Product{{Component: vex.Component{
+				ID: "pkg:apk/wolfi/curl@8.6.0-r0?arch=x86_64",
+			}}},
+			Status: vex.StatusFixed,
+		},
+		{
+			Vulnerability: vex.Vulnerability{Name: "CVE-2024-2511"},
+			Products: []vex.Product{{
+				Component: vex.Component{ID: "pkg:oci/example-app@sha256%3A0000"},
+				Subcomponents: []vex.Subcomponent{
+					{Component: vex.Component{Identifiers: map[vex.IdentifierType]string{vex.PURL: "pkg:apk/wolfi/openssl@3.3.0-r0"}}},
+					{Component: vex.Component{ID: "pkg:golang/github.com/example/app@v1.0.0"}},
+				},
+			}},
+			Status:          vex.StatusNotAffected,
+			Justification:   vex.VulnerableCodeNotInExecutePath,
+			ImpactStatement: "example-app doesn't use TLS session resumption.",
+		},
+		{
+			Vulnerability: vex.Vulnerability{Name: "CVE-2024-9999"},
+			Products:      []vex.Product{{Component: vex.Component{ID: "pkg:apk/wolfi/zlib"}}},
+			Status:        vex.StatusFixed,
+		},
+	}
+
+	requests, err := OpenVEXRequests(ctx, &doc)
+	require.NoError(t, err)
+
+	ts := v2.Timestamp(timestamp)
+	assert.Equal(t, []Request{
+		{
+			Package: "curl",
+			Aliases: []string{"CVE-2024-2398", "GHSA-2222-3333-4444"},
+			Event:   v2.Event{Timestamp: ts, Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: "8.6.0-r0"}},
+		},
+		{
+			Package: "openssl",
+			Aliases: []string{"CVE-2024-2511"},
+			Event: v2.Event{Timestamp: ts, Type: v2.EventTypeFalsePositiveDetermination, Data: v2.FalsePositiveDetermination{
+				Type: v2.FPTypeVulnerableCodeNotInExecutionPath,
+				Note: "example-app doesn't use TLS session resumption.",
+			}},
+		},
+	}, requests)
+}
+
+func TestConflict(t *testing.T) {
+	event := func(typ string, data interface{}) v2.Event {
+		return v2.Event{Timestamp: v2.Now(), Type: typ, Data: data}
+	}
+	fixed := event(v2.EventTypeFixed, v2.Fixed{FixedVersion: "1.2.3-r1"})
+	falsePositive := event(v2.EventTypeFalsePositiveDetermination, v2.FalsePositiveDetermination{Type: v2.FPTypeVulnerableCodeNotInExecutionPath})
+	truePositive := event(v2.EventTypeTruePositiveDetermination, v2.TruePositiveDetermination{})
+	detection := event(v2.EventTypeDetection, v2.Detection{Type: v2.DetectionTypeManual})
+
+	cases := []struct {
+		name             string
+		latest, incoming v2.Event
+		expectedConflict bool
+	}{
+		{name: "affected then fixed", latest: truePositive, incoming: fixed},
+		{name: "same fix", latest: fixed, incoming: fixed},
+		{name: "detection then fixed", latest: detection, incoming: fixed},
+		{name: "fixed then detection", latest: fixed, incoming: detection},
+		{name: "fixed then not affected", latest: fixed, incoming: falsePositive, expectedConflict: true},
+		{name: "not affected then affected", latest: falsePositive, incoming: truePositive, expectedConflict: true},
+		{name: "fixed then affected", latest: fixed, incoming: truePositive, expectedConflict: true},
+		{
+			name:             "fixed in another version",
+			latest:           fixed,
+			incoming:         event(v2.EventTypeFixed, v2.Fixed{FixedVersion: "1.2.4-r0"}),
+			expectedConflict: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			adv := v2.Advisory{ID: "CGA-2222-2222-2222", Aliases: []string{"CVE-2024-1234"}, Events: []v2.Event{tt.latest}}
+			req := Request{Package: "foo", Aliases: []string{"CVE-2024-1234"}, Event: tt.incoming}
+
+			err := Conflict(adv, req)
+			if !tt.expectedConflict {
+				assert.NoError(t, err)
+				return
+			}
+
+			var conflictErr *ConflictError
+			require.ErrorAs(t, err, &conflictErr)
+			assert.Equal(t, "CGA-2222-2222-2222", conflictErr.AdvisoryID)
+		})
+	}
+}
+
+func TestImportRequests(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	b, err := os.ReadFile("testdata/export/advisories/brotli.advisories.yaml")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dir+"/brotli.advisories.yaml", b, 0o600))
+
+	opts := ImportOptions{
+		Getter: NewFSGetter(os.DirFS(dir)),
+		Putter: NewFSPutterWithAutomaticEncoder(rwos.DirFS(dir)),
+	}
+
+	timestamp := v2.Timestamp(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+	requests := []Request{
+		{
+			// The same as the existing advisory's latest event.
+			Package: "brotli",
+			Aliases: []string{"CVE-2020-8927"},
+			Event:   v2.Event{Timestamp: timestamp, Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: "1.0.9-r0"}},
+		},
+		{
+			// Contradicts the existing advisory's latest event.
+			Package: "brotli",
+			Aliases: []string{"CVE-2020-8927"},
+			Event: v2.Event{Timestamp: timestamp, Type: v2.EventTypeFalsePositiveDetermination, Data: v2.FalsePositiveDetermination{
+				Type: v2.FPTypeVulnerableCodeNotInExecutionPath,
+			}},
+		},
+		{
+			Package: "brotli",
+			Aliases: []string{"CVE-2024-1234"},
+			Event:   v2.Event{Timestamp: timestamp, Type: v2.EventTypeDetection, Data: v2.Detection{Type: v2.DetectionTypeManual}},
+		},
+		{
+			Package: "brotli",
+			Aliases: []string{"CVE-2024-1234"},
+			Event:   v2.Event{Timestamp: timestamp, Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: "1.1.0-r0"}},
+		},
+		{
+			Package: "curl",
+			Aliases: []string{"CVE-2024-2398"},
+			Event:   v2.Event{Timestamp: timestamp, Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: "8.6.0-r0"}},
+		},
+	}
+
+	result, err := ImportRequests(ctx, requests, opts)
+	var conflictErr *ConflictError
+	require.True(t, errors.As(err, &conflictErr), "expected a conflict, got %v", err)
+	assert.Equal(t, "CGA-37qj-pjrf-fmrw", conflictErr.AdvisoryID)
+	assert.Equal(t, ImportResult{Created: 2, Updated: 1, Skipped: 1}, result)
+
+	index, err := adv2.NewIndex(ctx, rwos.DirFS(dir))
+	require.NoError(t, err)
+	docs := index.Select().Configurations()
+	require.Len(t, docs, 2)
+	for _, doc := range docs {
+		require.NoError(t, doc.Validate())
+	}
+
+	brotli := index.Select().WhereName("brotli").Configurations()[0]
+	existing, ok := brotli.Advisories.Get("CGA-37qj-pjrf-fmrw")
+	require.True(t, ok)
+	assert.Len(t, existing.Events, 1)
+	added, ok := brotli.Advisories.GetByVulnerability("CVE-2024-1234")
+	require.True(t, ok)
+	assert.Equal(t, v2.EventTypeFixed, added.Latest().Type)
+
+	t.Run("force", func(t *testing.T) {
+		opts := opts
+		opts.Force = true
+
+		result, err := ImportRequests(ctx, requests[1:2], opts)
+		require.NoError(t, err)
+		assert.Equal(t, ImportResult{Updated: 1}, result)
+	})
+}
@@ -0,0 +1,186 @@
+package advisory
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/openvex/go-vex/pkg/vex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+func TestOpenVEXStatement(t *testing.T) {
+	event := func(typ string, data interface{}) v2.Advisory {
+		return v2.Advisory{
+			ID:      "CGA-xxxx-xxxx-xxxx",
+			Aliases: []string{"GHSA-xxxx-xxxx-xxxx", "CVE-2024-1234"},
+			Events: []v2.Event{{
+				Timestamp: v2.Timestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+				Type:      typ,
+				Data:      data,
+			}},
+		}
+	}
+
+	cases := []struct {
+		name           string
+		adv            v2.Advisory
+		packageVersion string
+		expectedStatus vex.Status
+		expectedOK     bool
+	}{
+		{
+			name:           "fixed",
+			adv:            event(v2.EventTypeFixed, v2.Fixed{FixedVersion: "1.2.3-r1"}),
+			expectedStatus: vex.StatusFixed,
+			expectedOK:     true,
+		},
+		{
+			name:           "fixed in the package version",
+			adv:            event(v2.EventTypeFixed, v2.Fixed{FixedVersion: "1.2.3-r1"}),
+			packageVersion: "1.2.3-r1",
+			expectedStatus: vex.StatusFixed,
+			expectedOK:     true,
+		},
+		{
+			name:           "fixed in a later version",
+			adv:            event(v2.EventTypeFixed, v2.Fixed{FixedVersion: "1.2.3-r1"}),
+			packageVersion: "1.2.3-r0",
+			expectedStatus: vex.StatusAffected,
+			expectedOK:     true,
+		},
+		{
+			name:           "false positive",
+			adv:            event(v2.EventTypeFalsePositiveDetermination, v2.FalsePositiveDetermination{Type: v2.FPTypeVulnerableCodeNotInExecutionPath}),
+			expectedStatus: vex.StatusNotAffected,
+			expectedOK:     true,
+		},
+		{
+			name:           "true positive",
+			adv:            event(v2.EventTypeTruePositiveDetermination, v2.TruePositiveDetermination{Note: "confirmed"}),
+			expectedStatus: vex.StatusAffected,
+			expectedOK:     true,
+		},
+		{
+			name:           "pending upstream fix",
+			adv:            event(v2.EventTypePendingUpstreamFix, v2.PendingUpstreamFix{Note: "waiting on upstream"}),
+			expectedStatus: vex.StatusAffected,
+			expectedOK:     true,
+		},
+		{
+			name:           "fix not planned",
+			adv:            event(v2.EventTypeFixNotPlanned, v2.FixNotPlanned{Note: "end of life"}),
+			expectedStatus: vex.StatusAffected,
+			expectedOK:     true,
+		},
+		{
+			name:           "detection",
+			adv:            event(v2.EventTypeDetection, v2.Detection{Type: v2.DetectionTypeManual}),
+			expectedStatus: vex.StatusUnderInvestigation,
+			expectedOK:     true,
+		},
+		{
+			name:       "analysis not planned",
+			adv:        event(v2.EventTypeAnalysisNotPlanned, v2.AnalysisNotPlanned{Note: "not worth it"}),
+			expectedOK: false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, ok := OpenVEXStatement(tt.adv, tt.packageVersion)
+			require.Equal(t, tt.expectedOK, ok)
+			if !ok {
+				return
+			}
+
+			assert.Equal(t, tt.expectedStatus, statement.Status)
+			assert.Equal(t, vex.VulnerabilityID("CVE-2024-1234"), statement.Vulnerability.Name)
+			assert.Equal(t, []vex.VulnerabilityID{"CGA-xxxx-xxxx-xxxx", "GHSA-xxxx-xxxx-xxxx"}, statement.Vulnerability.Aliases)
+			assert.NoError(t, statement.Validate())
+		})
+	}
+
+	t.Run("false positive justification", func(t *testing.T) {
+		statement, ok := OpenVEXStatement(event(v2.EventTypeFalsePositiveDetermination, v2.FalsePositiveDetermination{
+			Type: v2.FPTypeVulnerableCodeNotInExecutionPath,
+			Note: "the vulnerable function isn't called",
+		}), "")
+		require.True(t, ok)
+		assert.Equal(t, vex.VulnerableCodeNotInExecutePath, statement.Justification)
+		assert.Equal(t, "the vulnerable function isn't called", statement.ImpactStatement)
+	})
+}
+
+func TestExportOpenVEX(t *testing.T) {
+	advisoryDocs, err := adv2.NewIndex(context.Background(), rwos.DirFS("./testdata/export/advisories"))
+	require.NoError(t, err)
+
+	opts := ExportOptions{
+		AdvisoryDocIndices: []*configs.Index[v2.Document]{advisoryDocs},
+		Ecosystem:          "Wolfi",
+	}
+
+	t.Run("all packages", func(t *testing.T) {
+		doc := exportOpenVEX(t, opts)
+		assert.Len(t, doc.Statements, 22)
+		assert.NotEmpty(t, doc.ID)
+
+		for _, s := range doc.Statements {
+			assert.NoError(t, s.Validate())
+		}
+
+		statements := doc.StatementsByVulnerability("CVE-2020-8927")
+		require.Len(t, statements, 1)
+		fixed := statements[0]
+		assert.Equal(t, vex.StatusFixed, fixed.Status)
+		require.Len(t, fixed.Products, 1)
+		assert.Equal(t, "pkg:apk/wolfi/brotli@1.0.9-r0", fixed.Products[0].ID)
+	})
+
+	t.Run("scoped to packages", func(t *testing.T) {
+		scoped := opts
+		scoped.Packages = []string{"openssl"}
+
+		doc := exportOpenVEX(t, scoped)
+		require.NotEmpty(t, doc.Statements)
+
+		var notAffected []vex.Statement
+		for _, s := range doc.Statements {
+			assert.Contains(t, s.Products[0].ID, "pkg:apk/wolfi/openssl")
+			if s.Status == vex.StatusNotAffected {
+				notAffected = append(notAffected, s)
+			}
+		}
+
+		require.Len(t, notAffected, 1)
+		assert.Equal(t, vex.VulnerabilityID("CVE-2023-0466"), notAffected[0].Vulnerability.Name)
+		assert.Equal(t, "pkg:apk/wolfi/openssl", notAffected[0].Products[0].ID)
+	})
+
+	t.Run("no ecosystem", func(t *testing.T) {
+		_, err := ExportOpenVEX(ExportOptions{AdvisoryDocIndices: opts.AdvisoryDocIndices})
+		assert.Error(t, err)
+	})
+}
+
+func exportOpenVEX(t *testing.T, opts ExportOptions) *vex.VEX {
+	t.Helper()
+
+	r, err := ExportOpenVEX(opts)
+	require.NoError(t, err)
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	doc, err := vex.Parse(b)
+	require.NoError(t, err)
+
+	return doc
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -55,6 +56,10 @@ type OSVOptions struct {
 	// OutputDirectory is the path to a local directory in which the generated OSV
 	// dataset will be written.
 	OutputDirectory string
+
+	// PackageVersionTimeline is used to derive the affected version ranges of the
+	// advisories (see AffectedRanges). It may be nil.
+	PackageVersionTimeline PackageVersionTimeline
 }
 
 // OSVEcosystem is the name of the OSV ecosystem for Chainguard advisories.
@@ -139,19 +144,11 @@ func BuildOSVDataset(ctx context.Context, opts OSVOptions) error {
 				latestEvent := adv.Latest()
 				advisoryLastUpdated := time.Time(latestEvent.Timestamp)
 
-				var affectedRange models.Range
-
-				switch latestEvent.Type {
-				case v2.EventTypeFixed:
-					if d, ok := latestEvent.Data.(v2.Fixed); ok {
-						affectedRange = rangeForFixed(d.FixedVersion)
-					} else {
-						return fmt.Errorf("unexpected data type for fixed event: %T (advisory index %d, package %q, advisory ID %q)", latestEvent.Data, i, pkgName, adv.ID)
-					}
-				case v2.EventTypeFalsePositiveDetermination:
-					affectedRange = rangeForFalsePositive()
-				default:
-					// We don't yet produce OSV data for other event types.
+				affectedRange, ok, err := osvAffectedRange(ctx, opts.PackageVersionTimeline, pkgName, adv)
+				if err != nil {
+					return fmt.Errorf("advisory index %d: %w", i, err)
+				}
+				if !ok {
 					logger.Debug("skipping advisory with unsupported event type", "advisoryID", adv.ID, "eventType", latestEvent.Type)
 					continue
 				}
@@ -175,21 +172,20 @@ func BuildOSVDataset(ctx context.Context, opts OSVOptions) error {
 					Modified: advisoryLastUpdated,
 				}
 
-				advisoryIDsToModels[adv.ID] = entry
+				addOSVVulnerability(advisoryIDsToModels, entry)
 			}
 		}
 	}
 
-	ids := lo.Keys(advisoryIDsToModels)
-	sort.Strings(ids)
+	advisoryModels := sortedOSVVulnerabilities(advisoryIDsToModels)
 
 	// write the all.json ("the index") and individual advisory files
-	logger.Info("generating all.json index file", "outputDirectory", opts.OutputDirectory, "advisoryCount", len(ids))
+	logger.Info("generating all.json index file", "outputDirectory", opts.OutputDirectory, "advisoryCount", len(advisoryModels))
 
 	var indexEntries []models.Vulnerability
 
-	for _, id := range ids {
-		advisoryModel := advisoryIDsToModels[id]
+	for _, advisoryModel := range advisoryModels {
+		id := advisoryModel.ID
 
 		// for the all.json, we just need the id and modified date
 		indexEntry := models.Vulnerability{
@@ -238,22 +234,79 @@ func BuildOSVDataset(ctx context.Context, opts OSVOptions) error {
 	return nil
 }
 
-func createPurl(pkgName string, ecosystem models.Ecosystem) string {
-	return fmt.Sprintf("pkg:apk/%s/%s", strings.ToLower(string(ecosystem)), pkgName)
+// osvAffectedRange returns the OSV range of the versions of the package
+// affected by adv. It returns false if the advisory doesn't yet say which
+// versions are affected (i.e. its latest event isn't a fix or a false positive
+// determination), since we don't yet produce OSV data for other event types.
+func osvAffectedRange(ctx context.Context, timeline PackageVersionTimeline, pkgName string, adv v2.Advisory) (models.Range, bool, error) {
+	if len(adv.Events) == 0 {
+		return models.Range{}, false, nil
+	}
+
+	switch adv.Latest().Type {
+	case v2.EventTypeFixed:
+		ranges, err := AffectedRanges(ctx, timeline, pkgName, adv)
+		if err != nil {
+			return models.Range{}, false, err
+		}
+		return osvRange(ranges), true, nil
+
+	case v2.EventTypeFalsePositiveDetermination:
+		return rangeForFalsePositive(), true, nil
+
+	default:
+		return models.Range{}, false, nil
+	}
 }
 
-func rangeForFixed(fixedVersion string) models.Range {
-	return models.Range{
-		Type: models.RangeEcosystem,
-		Events: []models.Event{
-			{
-				Introduced: "0",
-			},
-			{
-				Fixed: fixedVersion,
-			},
-		},
+// addOSVVulnerability adds v to vulnerabilitiesByID. The same advisory ID can
+// be used in more than one advisory index (or document), so if there's already
+// a vulnerability with v's ID, v's affected packages and related IDs are merged
+// into it instead of replacing it.
+func addOSVVulnerability(vulnerabilitiesByID map[string]models.Vulnerability, v models.Vulnerability) {
+	existing, ok := vulnerabilitiesByID[v.ID]
+	if !ok {
+		vulnerabilitiesByID[v.ID] = v
+		return
+	}
+
+	existing.Affected = append(existing.Affected, v.Affected...)
+	existing.Aliases = mergeOSVIDs(existing.Aliases, v.Aliases)
+	existing.Related = mergeOSVIDs(existing.Related, v.Related)
+	if v.Modified.After(existing.Modified) {
+		existing.Modified = v.Modified
+	}
+	if !v.Published.IsZero() && (existing.Published.IsZero() || v.Published.Before(existing.Published)) {
+		existing.Published = v.Published
+	}
+
+	vulnerabilitiesByID[v.ID] = existing
+}
+
+// mergeOSVIDs returns the IDs in a followed by those in b that aren't in a.
+func mergeOSVIDs(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	return lo.Uniq(append(slices.Clone(a), b...))
+}
+
+// sortedOSVVulnerabilities returns the vulnerabilities in vulnerabilitiesByID,
+// sorted by ID.
+func sortedOSVVulnerabilities(vulnerabilitiesByID map[string]models.Vulnerability) []models.Vulnerability {
+	ids := lo.Keys(vulnerabilitiesByID)
+	sort.Strings(ids)
+
+	vulnerabilities := make([]models.Vulnerability, 0, len(ids))
+	for _, id := range ids {
+		vulnerabilities = append(vulnerabilities, vulnerabilitiesByID[id])
 	}
+
+	return vulnerabilities
+}
+
+func createPurl(pkgName string, ecosystem models.Ecosystem) string {
+	return fmt.Sprintf("pkg:apk/%s/%s", strings.ToLower(string(ecosystem)), pkgName)
 }
 
 func rangeForFalsePositive() models.Range {
@@ -0,0 +1,237 @@
+package advisory
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// OSVPublishedRecordsURL returns the URL of the archive of the records that
+// osv.dev has published for the ecosystem.
+func OSVPublishedRecordsURL(ecosystem string) string {
+	return fmt.Sprintf("https://osv-vulnerabilities.storage.googleapis.com/%s/all.zip", ecosystem)
+}
+
+// ReadOSVZip reads the OSV vulnerabilities from a zip archive in the layout
+// written by WriteOSVZip, e.g. an ecosystem's "all.zip" published by osv.dev.
+func ReadOSVZip(r io.ReaderAt, size int64) ([]models.Vulnerability, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("reading OSV zip: %w", err)
+	}
+
+	var vulnerabilities []models.Vulnerability
+	for _, f := range zr.File {
+		if path.Ext(f.Name) != ".json" {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %q in OSV zip: %w", f.Name, err)
+		}
+		var v models.Vulnerability
+		err = json.NewDecoder(rc).Decode(&v)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding %q in OSV zip: %w", f.Name, err)
+		}
+
+		vulnerabilities = append(vulnerabilities, v)
+	}
+
+	return vulnerabilities, nil
+}
+
+// ValidateOSVSubmission checks the OSV vulnerabilities against osv.dev's
+// requirements for the records of a data source, returning an error that joins
+// the problems of each record. IDs must start with idPrefix (e.g. "CGA-"), if
+// it's not empty.
+func ValidateOSVSubmission(vulnerabilities []models.Vulnerability, idPrefix string, now time.Time) error {
+	var errs []error
+
+	seen := make(map[string]struct{}, len(vulnerabilities))
+	for i := range vulnerabilities {
+		v := vulnerabilities[i]
+
+		if _, ok := seen[v.ID]; ok {
+			errs = append(errs, fmt.Errorf("%s: duplicate ID", v.ID))
+			continue
+		}
+		seen[v.ID] = struct{}{}
+
+		if err := validateOSVRecord(v, idPrefix, now); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", v.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateOSVRecord(v models.Vulnerability, idPrefix string, now time.Time) error {
+	var errs []error
+
+	switch {
+	case v.ID == "":
+		errs = append(errs, errors.New("ID is empty"))
+	case idPrefix != "" && !strings.HasPrefix(v.ID, idPrefix):
+		errs = append(errs, fmt.Errorf("ID doesn't start with %q", idPrefix))
+	}
+
+	switch {
+	case v.Modified.IsZero():
+		errs = append(errs, errors.New("modified time is missing"))
+	case v.Modified.After(now):
+		errs = append(errs, fmt.Errorf("modified time %s is in the future", v.Modified.Format(time.RFC3339)))
+	case !v.Published.IsZero() && v.Published.After(v.Modified):
+		errs = append(errs, errors.New("published time is after modified time"))
+	}
+
+	if slices.Contains(v.Aliases, v.ID) {
+		errs = append(errs, errors.New("aliases include the record's own ID"))
+	}
+
+	if v.Withdrawn.IsZero() && len(v.Affected) == 0 {
+		errs = append(errs, errors.New("no affected packages"))
+	}
+	for _, a := range v.Affected {
+		if a.Package.Ecosystem == "" || a.Package.Name == "" {
+			errs = append(errs, errors.New("affected package is missing its ecosystem or name"))
+			continue
+		}
+
+		for _, r := range a.Ranges {
+			if r.Type != models.RangeEcosystem {
+				errs = append(errs, fmt.Errorf("range of package %q has type %q, expected %q", a.Package.Name, r.Type, models.RangeEcosystem))
+			}
+			if len(r.Events) == 0 || r.Events[0].Introduced == "" {
+				errs = append(errs, fmt.Errorf("range of package %q doesn't start with an introduced event", a.Package.Name))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// OSVRecordsDiff is the difference between the OSV records exported from the
+// advisory data and those already published by osv.dev.
+type OSVRecordsDiff struct {
+	// Added are the exported records that haven't been published.
+	Added []models.Vulnerability
+
+	// Updated are the exported records whose content differs from their
+	// published records, and that have a later modified time.
+	Updated []models.Vulnerability
+
+	// Withdrawn are the published records that are no longer exported, marked as
+	// withdrawn. osv.dev doesn't delete records, so they must be withdrawn
+	// instead.
+	Withdrawn []models.Vulnerability
+
+	// Unbumped are the exported records whose content differs from their
+	// published records, but whose modified time isn't later, which osv.dev
+	// would ignore.
+	Unbumped []models.Vulnerability
+
+	// Unchanged is the number of exported records that are the same as their
+	// published records.
+	Unchanged int
+}
+
+// Changed returns the records to submit: the added, updated, and withdrawn
+// records.
+func (d OSVRecordsDiff) Changed() []models.Vulnerability {
+	return slices.Concat(d.Added, d.Updated, d.Withdrawn)
+}
+
+// Validate returns an error if the diff has unbumped records.
+func (d OSVRecordsDiff) Validate() error {
+	var errs []error
+	for _, v := range d.Unbumped {
+		errs = append(errs, fmt.Errorf("%s: content changed, but modified time %s isn't later than the published record's", v.ID, v.Modified.Format(time.RFC3339)))
+	}
+	return errors.Join(errs...)
+}
+
+// DiffOSVRecords compares the exported OSV records to the published ones. The
+// published records that aren't exported are marked as withdrawn at now. Fields
+// that osv.dev adds to the records it publishes (the schema version, and the
+// database-specific data of affected packages) are ignored.
+func DiffOSVRecords(exported, published []models.Vulnerability, now time.Time) (OSVRecordsDiff, error) {
+	publishedByID := make(map[string]models.Vulnerability, len(published))
+	for i := range published {
+		publishedByID[published[i].ID] = published[i]
+	}
+
+	var diff OSVRecordsDiff
+	exportedIDs := make(map[string]struct{}, len(exported))
+	for i := range exported {
+		v := exported[i]
+		exportedIDs[v.ID] = struct{}{}
+
+		p, ok := publishedByID[v.ID]
+		if !ok {
+			diff.Added = append(diff.Added, v)
+			continue
+		}
+
+		equal, err := osvRecordsEqual(v, p)
+		if err != nil {
+			return OSVRecordsDiff{}, err
+		}
+		switch {
+		case equal:
+			diff.Unchanged++
+		case v.Modified.After(p.Modified):
+			diff.Updated = append(diff.Updated, v)
+		default:
+			diff.Unbumped = append(diff.Unbumped, v)
+		}
+	}
+
+	for i := range published {
+		p := published[i]
+		if _, ok := exportedIDs[p.ID]; ok || !p.Withdrawn.IsZero() {
+			continue
+		}
+
+		p.Withdrawn = now
+		p.Modified = now
+		diff.Withdrawn = append(diff.Withdrawn, p)
+	}
+
+	for _, records := range [][]models.Vulnerability{diff.Added, diff.Updated, diff.Withdrawn, diff.Unbumped} {
+		sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	}
+
+	return diff, nil
+}
+
+func osvRecordsEqual(exported, published models.Vulnerability) (bool, error) {
+	published.SchemaVersion = exported.SchemaVersion
+	published.Affected = slices.Clone(published.Affected)
+	for i := range published.Affected {
+		published.Affected[i].DatabaseSpecific = nil
+	}
+
+	a, err := json.Marshal(exported)
+	if err != nil {
+		return false, fmt.Errorf("encoding OSV vulnerability %q to JSON: %w", exported.ID, err)
+	}
+	b, err := json.Marshal(published)
+	if err != nil {
+		return false, fmt.Errorf("encoding OSV vulnerability %q to JSON: %w", published.ID, err)
+	}
+
+	return bytes.Equal(a, b), nil
+}
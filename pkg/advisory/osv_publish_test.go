@@ -0,0 +1,106 @@
+package advisory
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOSVPublish(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	record := func(id string, modified time.Time, fixed string) models.Vulnerability {
+		return models.Vulnerability{
+			ID:       id,
+			Modified: modified,
+			Aliases:  []string{"CVE-2024-1111"},
+			Affected: []models.Affected{{
+				Package: models.Package{Name: "ko", Ecosystem: "Wolfi", Purl: "pkg:apk/wolfi/ko"},
+				Ranges:  []models.Range{osvRange([]AffectedRange{{Introduced: "0", Fixed: fixed}})},
+			}},
+		}
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		valid := record("CGA-2222-2222-2222", day(1), "0.15.2-r1")
+		assert.NoError(t, ValidateOSVSubmission([]models.Vulnerability{valid}, "CGA-", now))
+
+		future := record("CGA-3333-3333-3333", now.Add(time.Hour), "0.15.2-r1")
+		wrongPrefix := record("GHSA-2222-3333-4444", day(1), "0.15.2-r1")
+		noAffected := record("CGA-4444-4444-4444", day(1), "0.15.2-r1")
+		noAffected.Affected = nil
+		noIntroduced := record("CGA-5555-5555-5555", day(1), "0.15.2-r1")
+		noIntroduced.Affected[0].Ranges[0].Events = noIntroduced.Affected[0].Ranges[0].Events[1:]
+
+		err := ValidateOSVSubmission([]models.Vulnerability{valid, valid, future, wrongPrefix, noAffected, noIntroduced}, "CGA-", now)
+		require.Error(t, err)
+		for _, msg := range []string{
+			"CGA-2222-2222-2222: duplicate ID",
+			"CGA-3333-3333-3333: modified time 2024-06-01T01:00:00Z is in the future",
+			`GHSA-2222-3333-4444: ID doesn't start with "CGA-"`,
+			"CGA-4444-4444-4444: no affected packages",
+			`CGA-5555-5555-5555: range of package "ko" doesn't start with an introduced event`,
+		} {
+			assert.Contains(t, err.Error(), msg)
+		}
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		published := []models.Vulnerability{
+			record("CGA-2222-2222-2222", day(1), "0.15.2-r1"),
+			record("CGA-3333-3333-3333", day(1), "0.15.2-r1"),
+			record("CGA-4444-4444-4444", day(1), "0.15.2-r1"),
+			record("CGA-5555-5555-5555", day(1), "0.15.2-r1"),
+		}
+		// Fields added by osv.dev don't make a record differ.
+		published[0].SchemaVersion = "1.6.0"
+		published[0].Affected[0].DatabaseSpecific = map[string]any{"source": "https://example.com/CGA-2222-2222-2222.json"}
+
+		exported := []models.Vulnerability{
+			record("CGA-2222-2222-2222", day(1), "0.15.2-r1"),
+			record("CGA-3333-3333-3333", day(2), "0.15.3-r0"),
+			record("CGA-4444-4444-4444", day(1), "0.15.3-r0"),
+			record("CGA-6666-6666-6666", day(2), "0.15.2-r1"),
+		}
+
+		diff, err := DiffOSVRecords(exported, published, now)
+		require.NoError(t, err)
+
+		ids := func(vulns []models.Vulnerability) []string {
+			var ids []string
+			for _, v := range vulns {
+				ids = append(ids, v.ID)
+			}
+			return ids
+		}
+
+		assert.Equal(t, 1, diff.Unchanged)
+		assert.Equal(t, []string{"CGA-6666-6666-6666"}, ids(diff.Added))
+		assert.Equal(t, []string{"CGA-3333-3333-3333"}, ids(diff.Updated))
+		assert.Equal(t, []string{"CGA-4444-4444-4444"}, ids(diff.Unbumped))
+		require.Equal(t, []string{"CGA-5555-5555-5555"}, ids(diff.Withdrawn))
+		assert.Equal(t, now, diff.Withdrawn[0].Withdrawn)
+		assert.Equal(t, now, diff.Withdrawn[0].Modified)
+
+		assert.Equal(t, []string{"CGA-6666-6666-6666", "CGA-3333-3333-3333", "CGA-5555-5555-5555"}, ids(diff.Changed()))
+		assert.ErrorContains(t, diff.Validate(), "CGA-4444-4444-4444: content changed")
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		vulns := []models.Vulnerability{record("CGA-2222-2222-2222", day(1), "0.15.2-r1")}
+
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteOSVZip(buf, vulns))
+
+		read, err := ReadOSVZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		assert.Equal(t, vulns, read)
+	})
+}
@@ -0,0 +1,496 @@
+package advisory
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+)
+
+// A Query selects advisories. Queries are parsed from expressions such as:
+//
+//	package=openssl AND status=fixed AND fixed.version>=3.3.0
+//
+// An expression is made of comparisons of the form FIELD OP VALUE, combined
+// using AND, OR, NOT and parentheses. Adjacent comparisons without an operator
+// between them are combined using AND. Values containing spaces or special
+// characters can be double-quoted.
+//
+// The supported fields are:
+//
+//   - package: the name of the package
+//   - id: the advisory ID
+//   - alias: any of the advisory's aliases
+//   - vuln: the advisory ID or any of its aliases
+//   - status: the type of the advisory's latest event, e.g. "fixed"
+//   - fixed.version: the fixed version, if the latest event is a fixed event
+//   - severity: the highest CVSS severity among the advisory's CVEs
+//   - created: the time of the advisory's first event
+//   - updated: the time of the advisory's latest event
+//
+// The supported operators are =, !=, <, <=, > and >=. For the text fields
+// (package, id, alias and vuln), = and != support "*" wildcards, and only = and
+// != are supported. The other fields are compared by their natural order:
+// fixed.version by APK version, severity by rank (e.g. "severity>=high"), and
+// created and updated by time, given as a date (YYYY-MM-DD) or an RFC 3339
+// timestamp.
+//
+// A comparison with a field that the advisory doesn't have a value for (e.g.
+// fixed.version for an advisory that isn't fixed) doesn't match, regardless of
+// the operator.
+type Query interface {
+	// Match returns true if the query selects the subject.
+	Match(s QuerySubject) bool
+
+	String() string
+}
+
+// QuerySubject is what a Query is matched against.
+type QuerySubject struct {
+	Package  string
+	Advisory v2.Advisory
+
+	// Severity is the advisory's severity, as used by the "severity" field.
+	Severity string
+}
+
+// Query fields.
+const (
+	QueryFieldPackage      = "package"
+	QueryFieldID           = "id"
+	QueryFieldAlias        = "alias"
+	QueryFieldVuln         = "vuln"
+	QueryFieldStatus       = "status"
+	QueryFieldFixedVersion = "fixed.version"
+	QueryFieldSeverity     = "severity"
+	QueryFieldCreated      = "created"
+	QueryFieldUpdated      = "updated"
+)
+
+var queryFields = []string{
+	QueryFieldPackage,
+	QueryFieldID,
+	QueryFieldAlias,
+	QueryFieldVuln,
+	QueryFieldStatus,
+	QueryFieldFixedVersion,
+	QueryFieldSeverity,
+	QueryFieldCreated,
+	QueryFieldUpdated,
+}
+
+// ParseQuery parses a query expression. See Query for the syntax.
+func ParseQuery(s string) (Query, error) {
+	tokens, err := lexQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	p := &queryParser{tokens: tokens}
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != queryTokenEOF {
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+
+	return q, nil
+}
+
+type queryTokenKind int
+
+const (
+	queryTokenEOF queryTokenKind = iota
+	queryTokenWord
+	queryTokenString
+	queryTokenOp
+	queryTokenAnd
+	queryTokenOr
+	queryTokenNot
+	queryTokenLParen
+	queryTokenRParen
+)
+
+type queryToken struct {
+	kind  queryTokenKind
+	value string
+	pos   int
+}
+
+func (t queryToken) String() string {
+	if t.kind == queryTokenEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q at position %d", t.value, t.pos)
+}
+
+func isQueryOpChar(r rune) bool {
+	return r == '=' || r == '!' || r == '<' || r == '>'
+}
+
+func lexQuery(s string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: queryTokenLParen, value: "(", pos: i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: queryTokenRParen, value: ")", pos: i})
+			i++
+
+		case isQueryOpChar(r):
+			start := i
+			for i < len(runes) && isQueryOpChar(runes[i]) {
+				i++
+			}
+			op := string(runes[start:i])
+			if !slices.Contains([]string{"=", "!=", "<", "<=", ">", ">="}, op) {
+				return nil, fmt.Errorf("invalid operator %q at position %d", op, start)
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenOp, value: op, pos: start})
+
+		case r == '"':
+			start := i
+			i++
+			var b strings.Builder
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++ // closing quote
+			tokens = append(tokens, queryToken{kind: queryTokenString, value: b.String(), pos: start})
+
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !isQueryOpChar(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+				i++
+			}
+			word := string(runes[start:i])
+
+			kind := queryTokenWord
+			switch strings.ToUpper(word) {
+			case "AND":
+				kind = queryTokenAnd
+			case "OR":
+				kind = queryTokenOr
+			case "NOT":
+				kind = queryTokenNot
+			}
+			tokens = append(tokens, queryToken{kind: kind, value: word, pos: start})
+		}
+	}
+
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	i      int
+}
+
+func (p *queryParser) peek() queryToken {
+	if p.i >= len(p.tokens) {
+		return queryToken{kind: queryTokenEOF, pos: -1}
+	}
+	return p.tokens[p.i]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.peek()
+	if t.kind != queryTokenEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *queryParser) parseOr() (Query, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == queryTokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orQuery{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (Query, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().kind {
+		case queryTokenAnd:
+			p.next()
+
+		case queryTokenWord, queryTokenNot, queryTokenLParen:
+			// implicit AND
+
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andQuery{left, right}
+	}
+}
+
+func (p *queryParser) parseUnary() (Query, error) {
+	switch t := p.peek(); t.kind {
+	case queryTokenNot:
+		p.next()
+		q, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notQuery{q}, nil
+
+	case queryTokenLParen:
+		p.next()
+		q, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != queryTokenRParen {
+			return nil, fmt.Errorf("expected \")\" but found %s", t)
+		}
+		return q, nil
+
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *queryParser) parseComparison() (Query, error) {
+	field := p.next()
+	if field.kind != queryTokenWord {
+		return nil, fmt.Errorf("expected a field but found %s", field)
+	}
+	name := strings.ToLower(field.value)
+	if !slices.Contains(queryFields, name) {
+		return nil, fmt.Errorf("unknown field %q at position %d, must be one of [%s]", field.value, field.pos, strings.Join(queryFields, ", "))
+	}
+
+	op := p.next()
+	if op.kind != queryTokenOp {
+		return nil, fmt.Errorf("expected an operator after %q but found %s", field.value, op)
+	}
+
+	// A keyword in the position of a value can only be meant as a value, e.g.
+	// "package=not".
+	value := p.next()
+	switch value.kind {
+	case queryTokenWord, queryTokenString, queryTokenAnd, queryTokenOr, queryTokenNot:
+	default:
+		return nil, fmt.Errorf("expected a value after %q but found %s", field.value+op.value, value)
+	}
+
+	return newComparison(name, op.value, value.value)
+}
+
+type andQuery struct{ left, right Query }
+
+func (q andQuery) Match(s QuerySubject) bool { return q.left.Match(s) && q.right.Match(s) }
+func (q andQuery) String() string            { return fmt.Sprintf("(%s AND %s)", q.left, q.right) }
+
+type orQuery struct{ left, right Query }
+
+func (q orQuery) Match(s QuerySubject) bool { return q.left.Match(s) || q.right.Match(s) }
+func (q orQuery) String() string            { return fmt.Sprintf("(%s OR %s)", q.left, q.right) }
+
+type notQuery struct{ q Query }
+
+func (q notQuery) Match(s QuerySubject) bool { return !q.q.Match(s) }
+func (q notQuery) String() string            { return fmt.Sprintf("NOT %s", q.q) }
+
+// comparison is a single FIELD OP VALUE comparison. compare returns the result
+// of comparing the subject's value to the query's value (as in
+// strings.Compare), and false if the subject doesn't have a value for the
+// field.
+type comparison struct {
+	field, op, value string
+	compare          func(s QuerySubject) (int, bool)
+}
+
+func (c comparison) Match(s QuerySubject) bool {
+	result, ok := c.compare(s)
+	if !ok {
+		return false
+	}
+
+	switch c.op {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+func (c comparison) String() string {
+	return fmt.Sprintf("%s%s%q", c.field, c.op, c.value)
+}
+
+func newComparison(field, op, value string) (Query, error) {
+	c := comparison{field: field, op: op, value: value}
+
+	switch field {
+	case QueryFieldPackage, QueryFieldID, QueryFieldAlias, QueryFieldVuln:
+		if op != "=" && op != "!=" {
+			return nil, fmt.Errorf("operator %q isn't supported for field %q", op, field)
+		}
+		if _, err := path.Match(value, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q for field %q: %w", value, field, err)
+		}
+		return textComparison{field: field, op: op, pattern: value}, nil
+
+	case QueryFieldStatus:
+		if op != "=" && op != "!=" {
+			return nil, fmt.Errorf("operator %q isn't supported for field %q", op, field)
+		}
+		if !slices.Contains(v2.EventTypes, value) {
+			return nil, fmt.Errorf("invalid status %q, must be one of [%s]", value, strings.Join(v2.EventTypes, ", "))
+		}
+		c.compare = func(s QuerySubject) (int, bool) {
+			latest := s.Advisory.Latest()
+			if latest.Type == "" {
+				return 0, false
+			}
+			return strings.Compare(latest.Type, value), true
+		}
+
+	case QueryFieldFixedVersion:
+		want, err := apk.ParseVersion(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q for field %q: %w", value, field, err)
+		}
+		c.compare = func(s QuerySubject) (int, bool) {
+			fixed, ok := s.Advisory.Latest().Data.(v2.Fixed)
+			if !ok {
+				return 0, false
+			}
+			got, err := apk.ParseVersion(fixed.FixedVersion)
+			if err != nil {
+				return 0, false
+			}
+			return apk.CompareVersions(got, want), true
+		}
+
+	case QueryFieldSeverity:
+		want, ok := severityRanks[strings.ToUpper(value)]
+		if !ok {
+			return nil, fmt.Errorf("invalid severity %q", value)
+		}
+		c.compare = func(s QuerySubject) (int, bool) {
+			got, ok := severityRanks[strings.ToUpper(s.Severity)]
+			if !ok {
+				return 0, false
+			}
+			return got - want, true
+		}
+
+	case QueryFieldCreated, QueryFieldUpdated:
+		want, err := parseQueryTime(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q for field %q: %w", value, field, err)
+		}
+		c.compare = func(s QuerySubject) (int, bool) {
+			events := s.Advisory.SortedEvents()
+			if len(events) == 0 {
+				return 0, false
+			}
+			e := events[0]
+			if field == QueryFieldUpdated {
+				e = events[len(events)-1]
+			}
+			return time.Time(e.Timestamp).Compare(want), true
+		}
+	}
+
+	return c, nil
+}
+
+func parseQueryTime(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// textComparison matches a text field against a pattern that may contain "*"
+// wildcards. For the multi-valued fields (alias and vuln), "=" matches if any
+// value matches, and "!=" matches if no value matches.
+type textComparison struct {
+	field, op, pattern string
+}
+
+func (c textComparison) Match(s QuerySubject) bool {
+	var values []string
+	switch c.field {
+	case QueryFieldPackage:
+		values = []string{s.Package}
+	case QueryFieldID:
+		values = []string{s.Advisory.ID}
+	case QueryFieldAlias:
+		values = s.Advisory.Aliases
+	case QueryFieldVuln:
+		values = s.Advisory.VulnerabilityIDs()
+	}
+
+	matched := slices.ContainsFunc(values, func(v string) bool {
+		ok, _ := path.Match(c.pattern, v)
+		return ok
+	})
+
+	if c.op == "!=" {
+		return !matched
+	}
+	return matched
+}
+
+func (c textComparison) String() string {
+	return fmt.Sprintf("%s%s%q", c.field, c.op, c.pattern)
+}
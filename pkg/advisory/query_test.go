@@ -0,0 +1,115 @@
+package advisory
+
+import (
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	fixed := QuerySubject{
+		Package: "openssl",
+		Advisory: v2.Advisory{
+			ID:      "CGA-2222-2222-2222",
+			Aliases: []string{"CVE-2024-2222", "GHSA-2222-2222-2222"},
+			Events: []v2.Event{
+				{
+					Timestamp: v2.Timestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+					Type:      v2.EventTypeDetection,
+					Data:      v2.Detection{Type: v2.DetectionTypeManual},
+				},
+				{
+					Timestamp: v2.Timestamp(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+					Type:      v2.EventTypeFixed,
+					Data:      v2.Fixed{FixedVersion: "3.3.1-r0"},
+				},
+			},
+		},
+		Severity: "HIGH",
+	}
+	detected := QuerySubject{
+		Package: "openssl-dev",
+		Advisory: v2.Advisory{
+			ID:      "CGA-3333-3333-3333",
+			Aliases: []string{"CVE-2024-3333"},
+			Events: []v2.Event{
+				{
+					Timestamp: v2.Timestamp(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+					Type:      v2.EventTypeDetection,
+					Data:      v2.Detection{Type: v2.DetectionTypeManual},
+				},
+			},
+		},
+		Severity: SeverityUnknown,
+	}
+
+	cases := []struct {
+		query                   string
+		matchFixed, matchDetect bool
+	}{
+		{query: "package=openssl", matchFixed: true},
+		{query: "package=openssl*", matchFixed: true, matchDetect: true},
+		{query: "package!=openssl", matchDetect: true},
+		{query: "id=CGA-3333-3333-3333", matchDetect: true},
+		{query: "alias=GHSA-*", matchFixed: true},
+		{query: "alias!=GHSA-*", matchDetect: true},
+		{query: "vuln=CGA-2222-2222-2222", matchFixed: true},
+		{query: "vuln=CVE-2024-3333", matchDetect: true},
+		{query: "status=fixed", matchFixed: true},
+		{query: "status!=fixed", matchDetect: true},
+		{query: "fixed.version>=3.3.0", matchFixed: true},
+		{query: "fixed.version<3.3.1"},
+		{query: "fixed.version!=1.0.0", matchFixed: true},
+		{query: "severity>=high", matchFixed: true},
+		{query: "severity>HIGH"},
+		{query: "severity=unknown", matchDetect: true},
+		{query: "created<2024-02-01", matchFixed: true},
+		{query: "updated>=2024-02-01", matchFixed: true, matchDetect: true},
+		{query: "updated>2024-02-01T00:00:00Z", matchDetect: true},
+		{query: "package=openssl AND status=fixed AND fixed.version>=3.3.0", matchFixed: true},
+		{query: "package=openssl status=detection"},
+		{query: "status=fixed OR status=detection", matchFixed: true, matchDetect: true},
+		{query: "NOT status=fixed", matchDetect: true},
+		{query: "package=openssl* and not (status=fixed or severity=critical)", matchDetect: true},
+		{query: `package="openssl-dev"`, matchDetect: true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.matchFixed, q.Match(fixed), "fixed advisory")
+			assert.Equal(t, tt.matchDetect, q.Match(detected), "detected advisory")
+		})
+	}
+}
+
+func TestParseQuery_errors(t *testing.T) {
+	cases := map[string]string{
+		"":                       "empty query",
+		"package":                `expected an operator after "package" but found end of query`,
+		"package=":               `expected a value after "package=" but found end of query`,
+		"name=openssl":           `unknown field "name" at position 0`,
+		"package=!openssl":       `invalid operator "=!" at position 7`,
+		"package>openssl":        `operator ">" isn't supported for field "package"`,
+		"status=open":            `invalid status "open"`,
+		"severity=urgent":        `invalid severity "urgent"`,
+		"created>yesterday":      `invalid time "yesterday" for field "created"`,
+		"(package=openssl":       `expected ")" but found end of query`,
+		"package=openssl)":       `unexpected ")" at position 15`,
+		`package="openssl`:       "unterminated string at position 8",
+		"package=openssl OR AND": `expected a field but found "AND" at position 19`,
+	}
+
+	for query, want := range cases {
+		t.Run(query, func(t *testing.T) {
+			_, err := ParseQuery(query)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), want)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+)
+
+// RemediationOptions configures the FindRemediations operation.
+type RemediationOptions struct {
+	// Getter is the source of the advisories.
+	Getter Getter
+
+	// CVSS is the CVSS data used to determine the severity of advisories. It's
+	// optional; without it, all remediations have an unknown severity.
+	CVSS CVSSData
+
+	// Since excludes advisories remediated before this time. If zero, all
+	// remediated advisories are included.
+	Since time.Time
+}
+
+// Remediation is the time it took to remediate an advisory, i.e. the time from
+// the advisory's first detection to its first subsequent fixed or false
+// positive determination event.
+type Remediation struct {
+	Package    string
+	AdvisoryID string
+	Severity   string
+
+	// Resolution is the type of the event that remediated the advisory.
+	Resolution string
+
+	Detected   time.Time
+	Remediated time.Time
+}
+
+// Duration returns the time it took to remediate the advisory.
+func (r Remediation) Duration() time.Duration {
+	return r.Remediated.Sub(r.Detected)
+}
+
+// FindRemediations returns the remediation of each advisory available from the
+// getter that has been remediated, sorted by package and advisory ID.
+// Advisories without a detection event are skipped, since there's nothing to
+// measure the time to remediation from.
+func FindRemediations(ctx context.Context, opts RemediationOptions) ([]Remediation, error) {
+	if opts.Getter == nil {
+		return nil, errors.New("an advisory getter must be provided")
+	}
+
+	packageNames, err := opts.Getter.PackageNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing packages: %w", err)
+	}
+
+	var remediations []Remediation
+	for _, name := range packageNames {
+		advs, err := opts.Getter.Advisories(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("getting advisories for %q: %w", name, err)
+		}
+
+		for i := range advs {
+			adv := advs[i].Advisory
+
+			r, ok := remediationOf(adv)
+			if !ok {
+				continue
+			}
+			if !opts.Since.IsZero() && r.Remediated.Before(opts.Since) {
+				continue
+			}
+
+			r.Package = name
+			r.Severity = advisorySeverity(adv, opts.CVSS)
+			remediations = append(remediations, r)
+		}
+	}
+
+	sort.Slice(remediations, func(i, j int) bool {
+		if remediations[i].Package != remediations[j].Package {
+			return remediations[i].Package < remediations[j].Package
+		}
+		return remediations[i].AdvisoryID < remediations[j].AdvisoryID
+	})
+
+	return remediations, nil
+}
+
+func remediationOf(adv v2.Advisory) (Remediation, bool) {
+	var detected *v2.Event
+	for _, e := range adv.SortedEvents() {
+		switch {
+		case detected == nil && e.Type == v2.EventTypeDetection:
+			detected = &e
+
+		case detected != nil && (e.Type == v2.EventTypeFixed || e.Type == v2.EventTypeFalsePositiveDetermination):
+			return Remediation{
+				AdvisoryID: adv.ID,
+				Resolution: e.Type,
+				Detected:   time.Time(detected.Timestamp),
+				Remediated: time.Time(e.Timestamp),
+			}, true
+		}
+	}
+
+	return Remediation{}, false
+}
+
+// RemediationSummary summarizes the time to remediation of a group of
+// remediations. Percentiles use the nearest-rank method.
+type RemediationSummary struct {
+	// Key identifies the group, e.g. a severity or package name.
+	Key string
+
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+
+	// WithSLA is the number of remediations whose severity has an SLA, and
+	// WithinSLA is the number of those that were remediated within it.
+	WithSLA   int
+	WithinSLA int
+}
+
+// RemediationReport summarizes remediations overall, by severity and by
+// package.
+type RemediationReport struct {
+	Overall RemediationSummary
+
+	// BySeverity is sorted from the highest severity to the lowest.
+	BySeverity []RemediationSummary
+
+	// ByPackage is sorted by the number of remediations, from most to fewest.
+	ByPackage []RemediationSummary
+}
+
+// SummarizeRemediations computes a RemediationReport for the remediations. The
+// slas map severities (e.g. "CRITICAL") to the time within which advisories of
+// that severity are expected to be remediated; it may be nil.
+func SummarizeRemediations(remediations []Remediation, slas map[string]time.Duration) RemediationReport {
+	report := RemediationReport{
+		Overall: summarizeRemediations("all", remediations, slas),
+	}
+
+	bySeverity := make(map[string][]Remediation)
+	byPackage := make(map[string][]Remediation)
+	for _, r := range remediations {
+		bySeverity[r.Severity] = append(bySeverity[r.Severity], r)
+		byPackage[r.Package] = append(byPackage[r.Package], r)
+	}
+
+	for severity, rs := range bySeverity {
+		report.BySeverity = append(report.BySeverity, summarizeRemediations(severity, rs, slas))
+	}
+	sort.Slice(report.BySeverity, func(i, j int) bool {
+		return severityRanks[report.BySeverity[i].Key] > severityRanks[report.BySeverity[j].Key]
+	})
+
+	for pkg, rs := range byPackage {
+		report.ByPackage = append(report.ByPackage, summarizeRemediations(pkg, rs, slas))
+	}
+	sort.Slice(report.ByPackage, func(i, j int) bool {
+		a, b := report.ByPackage[i], report.ByPackage[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Key < b.Key
+	})
+
+	return report
+}
+
+func summarizeRemediations(key string, remediations []Remediation, slas map[string]time.Duration) RemediationSummary {
+	s := RemediationSummary{Key: key, Count: len(remediations)}
+	if len(remediations) == 0 {
+		return s
+	}
+
+	durations := make([]time.Duration, 0, len(remediations))
+	for _, r := range remediations {
+		d := r.Duration()
+		durations = append(durations, d)
+
+		if sla, ok := slas[r.Severity]; ok {
+			s.WithSLA++
+			if d <= sla {
+				s.WithinSLA++
+			}
+		}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	s.P50 = percentile(durations, 50)
+	s.P90 = percentile(durations, 90)
+	s.P95 = percentile(durations, 95)
+	s.Max = durations[len(durations)-1]
+
+	return s
+}
+
+// percentile returns the p-th percentile of the sorted, non-empty durations,
+// using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,136 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRemediations(t *testing.T) {
+	fsys := os.DirFS("testdata/remediation")
+
+	cvss, err := ReadCVSSData(fsys)
+	require.NoError(t, err)
+
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	all := []Remediation{
+		{
+			Package:    "crane",
+			AdvisoryID: "CGA-6666-6666-6666",
+			Severity:   "CRITICAL",
+			Resolution: v2.EventTypeFalsePositiveDetermination,
+			Detected:   day(2024, 2, 1),
+			Remediated: day(2024, 2, 11),
+		},
+		{
+			Package:    "crane",
+			AdvisoryID: "CGA-7777-7777-7777",
+			Severity:   "HIGH",
+			Resolution: v2.EventTypeFixed,
+			Detected:   day(2023, 12, 1),
+			Remediated: day(2023, 12, 6),
+		},
+		{
+			Package:    "ko",
+			AdvisoryID: "CGA-2222-2222-2222",
+			Severity:   "CRITICAL",
+			Resolution: v2.EventTypeFixed,
+			Detected:   day(2024, 1, 1),
+			Remediated: day(2024, 1, 3),
+		},
+		{
+			Package:    "ko",
+			AdvisoryID: "CGA-3333-3333-3333",
+			Severity:   "HIGH",
+			Resolution: v2.EventTypeFixed,
+			Detected:   day(2024, 1, 1),
+			Remediated: day(2024, 1, 21),
+		},
+	}
+
+	t.Run("all", func(t *testing.T) {
+		remediations, err := FindRemediations(context.Background(), RemediationOptions{
+			Getter: NewFSGetter(fsys),
+			CVSS:   cvss,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, all, remediations)
+	})
+
+	t.Run("since", func(t *testing.T) {
+		remediations, err := FindRemediations(context.Background(), RemediationOptions{
+			Getter: NewFSGetter(fsys),
+			CVSS:   cvss,
+			Since:  day(2024, 1, 1),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []Remediation{all[0], all[2], all[3]}, remediations)
+	})
+}
+
+func TestSummarizeRemediations(t *testing.T) {
+	const day = 24 * time.Hour
+
+	remediation := func(pkg, severity string, d time.Duration) Remediation {
+		detected := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		return Remediation{Package: pkg, Severity: severity, Detected: detected, Remediated: detected.Add(d)}
+	}
+
+	remediations := []Remediation{
+		remediation("crane", "CRITICAL", 10*day),
+		remediation("crane", "HIGH", 5*day),
+		remediation("ko", "CRITICAL", 2*day),
+		remediation("ko", "HIGH", 20*day),
+		remediation("ko", SeverityUnknown, 3*day),
+	}
+	slas := map[string]time.Duration{
+		"CRITICAL": 7 * day,
+		"HIGH":     30 * day,
+	}
+
+	expected := RemediationReport{
+		Overall: RemediationSummary{
+			Key: "all", Count: 5,
+			P50: 5 * day, P90: 20 * day, P95: 20 * day, Max: 20 * day,
+			WithSLA: 4, WithinSLA: 3,
+		},
+		BySeverity: []RemediationSummary{
+			{
+				Key: "CRITICAL", Count: 2,
+				P50: 2 * day, P90: 10 * day, P95: 10 * day, Max: 10 * day,
+				WithSLA: 2, WithinSLA: 1,
+			},
+			{
+				Key: "HIGH", Count: 2,
+				P50: 5 * day, P90: 20 * day, P95: 20 * day, Max: 20 * day,
+				WithSLA: 2, WithinSLA: 2,
+			},
+			{
+				Key: SeverityUnknown, Count: 1,
+				P50: 3 * day, P90: 3 * day, P95: 3 * day, Max: 3 * day,
+			},
+		},
+		ByPackage: []RemediationSummary{
+			{
+				Key: "ko", Count: 3,
+				P50: 3 * day, P90: 20 * day, P95: 20 * day, Max: 20 * day,
+				WithSLA: 2, WithinSLA: 2,
+			},
+			{
+				Key: "crane", Count: 2,
+				P50: 5 * day, P90: 10 * day, P95: 10 * day, Max: 10 * day,
+				WithSLA: 2, WithinSLA: 1,
+			},
+		},
+	}
+
+	assert.Equal(t, expected, SummarizeRemediations(remediations, slas))
+}
@@ -0,0 +1,59 @@
+package advisory
+
+import (
+	"errors"
+	"sort"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+)
+
+// SearchOptions configures the Search operation.
+type SearchOptions struct {
+	// AdvisoryDocs is the Index of advisory documents to search.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// Query selects the advisories to return.
+	Query Query
+
+	// CVSS is the CVSS data used to determine the severity of advisories for the
+	// query's "severity" field. It's optional; without it, all advisories have an
+	// unknown severity.
+	CVSS CVSSData
+}
+
+// Search returns the advisories selected by the query, sorted by package and
+// advisory ID.
+func Search(opts SearchOptions) ([]v2.PackageAdvisory, error) {
+	if opts.AdvisoryDocs == nil {
+		return nil, errors.New("advisory documents must be provided")
+	}
+	if opts.Query == nil {
+		return nil, errors.New("a query must be provided")
+	}
+
+	var results []v2.PackageAdvisory
+	for _, doc := range opts.AdvisoryDocs.Select().Configurations() {
+		for _, adv := range doc.Advisories {
+			s := QuerySubject{
+				Package:  doc.Package.Name,
+				Advisory: adv,
+				Severity: advisorySeverity(adv, opts.CVSS),
+			}
+			if !opts.Query.Match(s) {
+				continue
+			}
+
+			results = append(results, v2.PackageAdvisory{PackageName: doc.Package.Name, Advisory: adv})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].PackageName != results[j].PackageName {
+			return results[i].PackageName < results[j].PackageName
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	return results, nil
+}
@@ -0,0 +1,63 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestSearch(t *testing.T) {
+	fsys := os.DirFS("testdata/remediation")
+
+	cvss, err := ReadCVSSData(fsys)
+	require.NoError(t, err)
+
+	advisoryDocs, err := adv2.NewIndex(context.Background(), memfs.New(fsys))
+	require.NoError(t, err)
+
+	cases := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			query:    "status=fixed",
+			expected: []string{"crane/CGA-7777-7777-7777", "ko/CGA-2222-2222-2222", "ko/CGA-3333-3333-3333", "ko/CGA-5555-5555-5555"},
+		},
+		{
+			query:    "package=ko AND status=fixed AND fixed.version>=0.15.0",
+			expected: []string{"ko/CGA-2222-2222-2222", "ko/CGA-3333-3333-3333"},
+		},
+		{
+			query:    "severity=critical",
+			expected: []string{"crane/CGA-6666-6666-6666", "ko/CGA-2222-2222-2222"},
+		},
+		{
+			query:    "vuln=CVE-2024-3333 AND NOT package=ko",
+			expected: []string{"crane/CGA-7777-7777-7777"},
+		},
+		{
+			query: "package=wolfi-baselayout",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			results, err := Search(SearchOptions{AdvisoryDocs: advisoryDocs, Query: q, CVSS: cvss})
+			require.NoError(t, err)
+
+			var got []string
+			for _, r := range results {
+				got = append(got, r.PackageName+"/"+r.ID)
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -30,6 +30,29 @@ var (
 
 // BuildSecurityDatabase builds an Alpine-style security database from the given options.
 func BuildSecurityDatabase(ctx context.Context, opts BuildSecurityDatabaseOptions) ([]byte, error) {
+	packageEntries, err := buildSecurityDatabasePackageEntries(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	db := newSecurityDatabase(opts, packageEntries)
+	return json.MarshalIndent(db, "", "  ")
+}
+
+func newSecurityDatabase(opts BuildSecurityDatabaseOptions, packageEntries []secdb.PackageEntry) secdb.Database {
+	return secdb.Database{
+		APKURL:    apkURL,
+		Archs:     opts.Archs,
+		Repo:      opts.Repo,
+		URLPrefix: opts.URLPrefix,
+		Packages:  packageEntries,
+	}
+}
+
+// buildSecurityDatabasePackageEntries returns the security database entries of
+// the packages in the advisory documents, in the order of the indices and their
+// documents.
+func buildSecurityDatabasePackageEntries(ctx context.Context, opts BuildSecurityDatabaseOptions) ([]secdb.PackageEntry, error) {
 	log := clog.FromContext(ctx)
 	var packageEntries []secdb.PackageEntry
 
@@ -104,13 +127,5 @@ func BuildSecurityDatabase(ctx context.Context, opts BuildSecurityDatabaseOption
 		packageEntries = append(packageEntries, indexPackageEntries...)
 	}
 
-	db := secdb.Database{
-		APKURL:    apkURL,
-		Archs:     opts.Archs,
-		Repo:      opts.Repo,
-		URLPrefix: opts.URLPrefix,
-		Packages:  packageEntries,
-	}
-
-	return json.MarshalIndent(db, "", "  ")
+	return packageEntries, nil
 }
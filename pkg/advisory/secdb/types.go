@@ -20,3 +20,34 @@ type Package struct {
 type Secfixes map[string][]string
 
 const NAK = "0"
+
+// IndexFileName is the name of the index file of a sharded security database.
+const IndexFileName = "index.json"
+
+// Index describes the shards of a sharded security database. Each shard is a
+// complete Database containing the entries of a range of packages.
+type Index struct {
+	APKURL    string   `json:"apkurl"`
+	Archs     []string `json:"archs"`
+	Repo      string   `json:"reponame"`
+	URLPrefix string   `json:"urlprefix"`
+	Shards    []Shard  `json:"shards"`
+}
+
+// Shard describes a shard of a sharded security database. The packages of all
+// shards are sorted by name, so the shard that has a given package's entry is
+// the one whose First and Last package names include it.
+type Shard struct {
+	// File is the shard's file name, relative to the index file.
+	File string `json:"file"`
+
+	// First and Last are the names of the shard's first and last packages.
+	First string `json:"first"`
+	Last  string `json:"last"`
+
+	// Packages is the number of package entries in the shard.
+	Packages int `json:"packages"`
+
+	// SHA256 is the hex-encoded SHA-256 digest of the shard's file.
+	SHA256 string `json:"sha256"`
+}
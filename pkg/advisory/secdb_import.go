@@ -0,0 +1,150 @@
+package advisory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	cgaid "github.com/chainguard-dev/advisory-schema/pkg/advisory"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/advisory-schema/pkg/vuln"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory/secdb"
+)
+
+// securityDatabaseNAKNote is the note of the false positive determinations
+// imported from a security database's secdb.NAK entries.
+const securityDatabaseNAKNote = `Imported from a security database that lists this vulnerability as not affecting the package (version "0").`
+
+// SecurityDatabaseRequests translates the secfixes of an Alpine-style security
+// database into advisory requests, one for each package and vulnerability. This
+// is the inverse of BuildSecurityDatabase.
+//
+// A vulnerability listed under a package version becomes a fixed event in that
+// version, and one listed under secdb.NAK becomes a false positive
+// determination. If a vulnerability is listed under several versions (e.g. when
+// it was fixed again after a regression), the highest version is used, and a fix
+// takes precedence over a NAK.
+//
+// A secfixes entry can list several IDs separated by whitespace, which are
+// aliases of the same vulnerability. (Security databases built by wolfictl list
+// each alias as a separate entry, so importing one of those into an empty
+// advisories repo creates an advisory per alias.) IDs that aren't valid
+// advisory aliases are skipped.
+//
+// Since the database doesn't record when vulnerabilities were fixed, all events
+// are timestamped with the given timestamp.
+func SecurityDatabaseRequests(ctx context.Context, db secdb.Database, timestamp v2.Timestamp) ([]Request, error) {
+	log := clog.FromContext(ctx)
+
+	var requests []Request
+	for _, entry := range db.Packages {
+		name := entry.Pkg.Name
+
+		// The events by the first alias of the vulnerabilities.
+		events := make(map[string]v2.Event)
+		aliasesByID := make(map[string][]string)
+
+		for version, vulns := range entry.Pkg.Secfixes {
+			for _, v := range vulns {
+				aliases := securityDatabaseAliases(v)
+				if len(aliases) == 0 {
+					log.Warn("skipping security database entry without a valid vulnerability ID", "package", name, "version", version, "vulnerability", v)
+					continue
+				}
+				id := aliases[0]
+
+				event, err := securityDatabaseEvent(version, timestamp)
+				if err != nil {
+					return nil, fmt.Errorf("package %q: %w", name, err)
+				}
+
+				if existing, ok := events[id]; ok && !securityDatabaseEventSupersedes(event, existing) {
+					continue
+				}
+				events[id] = event
+				aliasesByID[id] = aliases
+			}
+		}
+
+		ids := make([]string, 0, len(events))
+		for id := range events {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			req := Request{
+				Package: name,
+				Aliases: aliasesByID[id],
+				Event:   events[id],
+			}
+			if err := req.Validate(); err != nil {
+				return nil, fmt.Errorf("translating security database entry for %s in package %q: %w", id, name, err)
+			}
+
+			requests = append(requests, req)
+		}
+	}
+
+	return requests, nil
+}
+
+// securityDatabaseAliases returns the IDs of a secfixes entry that are valid
+// advisory aliases.
+func securityDatabaseAliases(entry string) []string {
+	var aliases []string
+	for _, id := range strings.Fields(entry) {
+		if cgaid.RegexCGA.MatchString(id) || vuln.ValidateID(id) != nil {
+			continue
+		}
+		if !slices.Contains(aliases, id) {
+			aliases = append(aliases, id)
+		}
+	}
+
+	return aliases
+}
+
+func securityDatabaseEvent(version string, timestamp v2.Timestamp) (v2.Event, error) {
+	if version == secdb.NAK {
+		return v2.Event{
+			Timestamp: timestamp,
+			Type:      v2.EventTypeFalsePositiveDetermination,
+			Data: v2.FalsePositiveDetermination{
+				Type: v2.FPTypeVulnerableCodeNotIncludedInPackage,
+				Note: securityDatabaseNAKNote,
+			},
+		}, nil
+	}
+
+	if _, err := apk.ParseVersion(version); err != nil {
+		return v2.Event{}, fmt.Errorf("invalid fixed version %q: %w", version, err)
+	}
+
+	return v2.Event{
+		Timestamp: timestamp,
+		Type:      v2.EventTypeFixed,
+		Data:      v2.Fixed{FixedVersion: version},
+	}, nil
+}
+
+// securityDatabaseEventSupersedes reports whether event a takes precedence over
+// event b when a vulnerability is listed more than once for a package.
+func securityDatabaseEventSupersedes(a, b v2.Event) bool {
+	fixedA, okA := a.Data.(v2.Fixed)
+	fixedB, okB := b.Data.(v2.Fixed)
+
+	switch {
+	case okA && okB:
+		// Both versions were validated when the events were created.
+		va, _ := apk.ParseVersion(fixedA.FixedVersion) //nolint:errcheck
+		vb, _ := apk.ParseVersion(fixedB.FixedVersion) //nolint:errcheck
+		return apk.CompareVersions(va, vb) > 0
+	default:
+		return okA
+	}
+}
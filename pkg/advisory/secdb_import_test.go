@@ -0,0 +1,105 @@
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory/secdb"
+)
+
+func TestSecurityDatabaseRequests(t *testing.T) {
+	ctx := context.Background()
+	ts := v2.Timestamp(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+
+	t.Run("alpine-style database", func(t *testing.T) {
+		var db secdb.Database
+		require.NoError(t, json.Unmarshal([]byte(`{
+  "apkurl": "{{urlprefix}}/{{distroversion}}/{{reponame}}/{{arch}}/{{pkg.name}}-{{pkg.ver}}.apk",
+  "archs": ["x86_64"],
+  "reponame": "main",
+  "urlprefix": "https://dl-cdn.alpinelinux.org/alpine",
+  "packages": [
+    {
+      "pkg": {
+        "name": "curl",
+        "secfixes": {
+          "0": ["CVE-2021-22897", "CVE-2023-38546"],
+          "8.4.0-r0": ["CVE-2023-38545 GHSA-9x6m-wq3q-h3rw", "CVE-2023-38546"],
+          "8.6.0-r0": ["CVE-2024-0853", "XSA-123"],
+          "8.7.1-r0": ["CVE-2024-0853"]
+        }
+      }
+    },
+    {
+      "pkg": {
+        "name": "zlib",
+        "secfixes": {
+          "1.2.12-r0": ["CVE-2018-25032"]
+        }
+      }
+    }
+  ]
+}`), &db))
+
+		requests, err := SecurityDatabaseRequests(ctx, db, ts)
+		require.NoError(t, err)
+
+		fixed := func(version string) v2.Event {
+			return v2.Event{Timestamp: ts, Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: version}}
+		}
+		assert.Equal(t, []Request{
+			{
+				Package: "curl",
+				Aliases: []string{"CVE-2021-22897"},
+				Event: v2.Event{Timestamp: ts, Type: v2.EventTypeFalsePositiveDetermination, Data: v2.FalsePositiveDetermination{
+					Type: v2.FPTypeVulnerableCodeNotIncludedInPackage,
+					Note: securityDatabaseNAKNote,
+				}},
+			},
+			{Package: "curl", Aliases: []string{"CVE-2023-38545", "GHSA-9x6m-wq3q-h3rw"}, Event: fixed("8.4.0-r0")},
+			{Package: "curl", Aliases: []string{"CVE-2023-38546"}, Event: fixed("8.4.0-r0")},
+			{Package: "curl", Aliases: []string{"CVE-2024-0853"}, Event: fixed("8.7.1-r0")},
+			{Package: "zlib", Aliases: []string{"CVE-2018-25032"}, Event: fixed("1.2.12-r0")},
+		}, requests)
+	})
+
+	t.Run("wolfictl-built database", func(t *testing.T) {
+		b, err := os.ReadFile("testdata/secdb/security.json")
+		require.NoError(t, err)
+
+		var db secdb.Database
+		require.NoError(t, json.Unmarshal(b, &db))
+
+		requests, err := SecurityDatabaseRequests(ctx, db, ts)
+		require.NoError(t, err)
+		assert.Contains(t, requests, Request{
+			Package: "brotli",
+			Aliases: []string{"GHSA-2222-2222-2222"},
+			Event:   v2.Event{Timestamp: ts, Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: "1.0.9-r0"}},
+		})
+		assert.Contains(t, requests, Request{
+			Package: "openssl",
+			Aliases: []string{"CVE-2023-0466"},
+			Event: v2.Event{Timestamp: ts, Type: v2.EventTypeFalsePositiveDetermination, Data: v2.FalsePositiveDetermination{
+				Type: v2.FPTypeVulnerableCodeNotIncludedInPackage,
+				Note: securityDatabaseNAKNote,
+			}},
+		})
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		db := secdb.Database{Packages: []secdb.PackageEntry{{Pkg: secdb.Package{
+			Name:     "curl",
+			Secfixes: secdb.Secfixes{"not a version": {"CVE-2024-0853"}},
+		}}}}
+
+		_, err := SecurityDatabaseRequests(ctx, db, ts)
+		assert.ErrorContains(t, err, `package "curl": invalid fixed version "not a version"`)
+	})
+}
@@ -0,0 +1,272 @@
+package advisory
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wolfi-dev/wolfictl/pkg/advisory/secdb"
+)
+
+// Security database sharding strategies.
+const (
+	// ShardByPrefix puts the packages whose names share a prefix into the same
+	// shard. Shards are stable as packages are added and removed, but their sizes
+	// vary.
+	ShardByPrefix = "prefix"
+
+	// ShardByChunk splits the packages, sorted by name, into shards of a fixed
+	// number of packages. Shards have similar sizes, but adding or removing a
+	// package changes the boundaries of all subsequent shards.
+	ShardByChunk = "chunk"
+)
+
+// ShardStrategies are the supported security database sharding strategies.
+var ShardStrategies = []string{ShardByPrefix, ShardByChunk}
+
+// ShardOptions configures how a security database is sharded.
+type ShardOptions struct {
+	// By is the sharding strategy, either ShardByPrefix or ShardByChunk.
+	By string
+
+	// PrefixLength is the length of the package name prefix used by
+	// ShardByPrefix.
+	PrefixLength int
+
+	// ChunkSize is the number of packages per shard used by ShardByChunk.
+	ChunkSize int
+}
+
+// ShardedSecurityDatabase is a security database split into shards.
+type ShardedSecurityDatabase struct {
+	// Index describes the shards.
+	Index secdb.Index
+
+	// Shards are the encoded shards, keyed by file name.
+	Shards map[string][]byte
+}
+
+// BuildShardedSecurityDatabase builds an Alpine-style security database like
+// BuildSecurityDatabase, but split into shards according to shardOpts. Each
+// shard is a complete security database for its packages.
+func BuildShardedSecurityDatabase(ctx context.Context, opts BuildSecurityDatabaseOptions, shardOpts ShardOptions) (*ShardedSecurityDatabase, error) {
+	var (
+		currentKey     string
+		currentEntries []secdb.PackageEntry
+		shardCount     int
+	)
+
+	// needsNewShard returns true if the package entry doesn't belong in the current
+	// shard, and shardKey returns the key of a new shard starting with the entry.
+	var (
+		needsNewShard func(pe secdb.PackageEntry) bool
+		shardKey      func(pe secdb.PackageEntry) string
+	)
+
+	switch shardOpts.By {
+	case ShardByPrefix:
+		if shardOpts.PrefixLength < 1 {
+			return nil, fmt.Errorf("prefix length must be at least 1")
+		}
+		shardKey = func(pe secdb.PackageEntry) string {
+			return packageNamePrefix(pe.Pkg.Name, shardOpts.PrefixLength)
+		}
+		needsNewShard = func(pe secdb.PackageEntry) bool {
+			return shardKey(pe) != currentKey
+		}
+
+	case ShardByChunk:
+		if shardOpts.ChunkSize < 1 {
+			return nil, fmt.Errorf("chunk size must be at least 1")
+		}
+		shardKey = func(secdb.PackageEntry) string {
+			return fmt.Sprintf("%04d", shardCount)
+		}
+		needsNewShard = func(secdb.PackageEntry) bool {
+			return len(currentEntries) >= shardOpts.ChunkSize
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid sharding strategy %q, must be one of [%s]", shardOpts.By, strings.Join(ShardStrategies, ", "))
+	}
+
+	packageEntries, err := buildSecurityDatabasePackageEntries(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(packageEntries, func(i, j int) bool {
+		return packageEntries[i].Pkg.Name < packageEntries[j].Pkg.Name
+	})
+
+	result := &ShardedSecurityDatabase{
+		Index: secdb.Index{
+			APKURL:    apkURL,
+			Archs:     opts.Archs,
+			Repo:      opts.Repo,
+			URLPrefix: opts.URLPrefix,
+		},
+		Shards: make(map[string][]byte),
+	}
+
+	flush := func() error {
+		if len(currentEntries) == 0 {
+			return nil
+		}
+
+		b, err := json.MarshalIndent(newSecurityDatabase(opts, currentEntries), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		file := fmt.Sprintf("security-%s.json", currentKey)
+		digest := sha256.Sum256(b)
+		result.Shards[file] = b
+		result.Index.Shards = append(result.Index.Shards, secdb.Shard{
+			File:     file,
+			First:    currentEntries[0].Pkg.Name,
+			Last:     currentEntries[len(currentEntries)-1].Pkg.Name,
+			Packages: len(currentEntries),
+			SHA256:   hex.EncodeToString(digest[:]),
+		})
+
+		currentEntries = nil
+		shardCount++
+		return nil
+	}
+
+	for _, pe := range packageEntries {
+		if len(currentEntries) > 0 {
+			// Keep the entries of the same package (from different advisory repos) in
+			// the same shard, so that the shards' package ranges don't overlap.
+			samePackage := currentEntries[len(currentEntries)-1].Pkg.Name == pe.Pkg.Name
+
+			if !samePackage && needsNewShard(pe) {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if len(currentEntries) == 0 {
+			currentKey = shardKey(pe)
+		}
+		currentEntries = append(currentEntries, pe)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// packageNamePrefix returns the first n characters of the package name. Since
+// the characters allowed in package names are all valid in file names, the
+// prefix can be used as is in the shard's file name.
+func packageNamePrefix(name string, n int) string {
+	runes := []rune(name)
+	if len(runes) > n {
+		runes = runes[:n]
+	}
+	return string(runes)
+}
+
+// ShardedSecurityDatabaseWriteResult describes the changes made by
+// WriteShardedSecurityDatabase.
+type ShardedSecurityDatabaseWriteResult struct {
+	// Written are the file names of the shards that were new or changed.
+	Written []string
+
+	// Removed are the file names of the shards that no longer exist.
+	Removed []string
+
+	// Unchanged is the number of shards that were already up to date.
+	Unchanged int
+}
+
+// WriteShardedSecurityDatabase writes the sharded security database to the
+// directory, which is created if needed. If the directory already has an
+// index file, only the shards whose digests differ from the ones in that index
+// are written, and shards that are no longer part of the database are removed,
+// so that only the changed files need to be published. The index file is
+// always written last.
+func WriteShardedSecurityDatabase(dir string, db *ShardedSecurityDatabase) (*ShardedSecurityDatabaseWriteResult, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	previous := make(map[string]string)
+	b, err := os.ReadFile(filepath.Join(dir, secdb.IndexFileName))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// Nothing to compare to, so all shards are written.
+
+	case err != nil:
+		return nil, fmt.Errorf("reading existing index: %w", err)
+
+	default:
+		var index secdb.Index
+		if err := json.Unmarshal(b, &index); err != nil {
+			return nil, fmt.Errorf("decoding existing index: %w", err)
+		}
+		for _, s := range index.Shards {
+			previous[s.File] = s.SHA256
+		}
+	}
+
+	result := &ShardedSecurityDatabaseWriteResult{}
+
+	for _, s := range db.Index.Shards {
+		if digest, ok := previous[s.File]; ok && digest == s.SHA256 && shardFileExists(dir, s.File) {
+			result.Unchanged++
+			continue
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, s.File), db.Shards[s.File], os.FileMode(0o644)); err != nil {
+			return nil, fmt.Errorf("writing shard %q: %w", s.File, err)
+		}
+		result.Written = append(result.Written, s.File)
+	}
+
+	for file := range previous {
+		if _, ok := db.Shards[file]; ok {
+			continue
+		}
+		if filepath.Base(file) != file {
+			// Don't touch anything outside of the directory, regardless of what the
+			// existing index says.
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, file)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("removing shard %q: %w", file, err)
+		}
+		result.Removed = append(result.Removed, file)
+	}
+	sort.Strings(result.Removed)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(db.Index); err != nil {
+		return nil, fmt.Errorf("encoding index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, secdb.IndexFileName), buf.Bytes(), os.FileMode(0o644)); err != nil {
+		return nil, fmt.Errorf("writing index: %w", err)
+	}
+
+	return result, nil
+}
+
+func shardFileExists(dir, file string) bool {
+	_, err := os.Stat(filepath.Join(dir, file))
+	return err == nil
+}
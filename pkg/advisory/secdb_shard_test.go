@@ -0,0 +1,165 @@
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory/secdb"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+func secdbTestOptions(t *testing.T, dirs ...string) BuildSecurityDatabaseOptions {
+	t.Helper()
+
+	indices := make([]*configs.Index[v2.Document], 0, len(dirs))
+	for _, dir := range dirs {
+		index, err := adv2.NewIndex(context.Background(), rwos.DirFS(filepath.Join("testdata", "secdb", dir)))
+		require.NoError(t, err)
+		indices = append(indices, index)
+	}
+
+	return BuildSecurityDatabaseOptions{
+		AdvisoryDocIndices: indices,
+		URLPrefix:          "https://packages.wolfi.dev",
+		Archs:              []string{"x86_64"},
+		Repo:               "os",
+	}
+}
+
+func TestBuildShardedSecurityDatabase(t *testing.T) {
+	cases := []struct {
+		name      string
+		dirs      []string
+		shardOpts ShardOptions
+		expected  []secdb.Shard
+	}{
+		{
+			name:      "by prefix",
+			dirs:      []string{"advisories", "other-advisories"},
+			shardOpts: ShardOptions{By: ShardByPrefix, PrefixLength: 1},
+			expected: []secdb.Shard{
+				{File: "security-b.json", First: "brotli", Last: "brotli", Packages: 1},
+				{File: "security-c.json", First: "cups", Last: "cups", Packages: 1},
+				{File: "security-k.json", First: "ko", Last: "ko", Packages: 1},
+				{File: "security-o.json", First: "openssl", Last: "openssl", Packages: 1},
+			},
+		},
+		{
+			name:      "by chunk",
+			dirs:      []string{"advisories", "other-advisories"},
+			shardOpts: ShardOptions{By: ShardByChunk, ChunkSize: 3},
+			expected: []secdb.Shard{
+				{File: "security-0000.json", First: "brotli", Last: "ko", Packages: 3},
+				{File: "security-0001.json", First: "openssl", Last: "openssl", Packages: 1},
+			},
+		},
+		{
+			name:      "entries of the same package stay in the same chunk",
+			dirs:      []string{"advisories", "advisories-with-package-overlap"},
+			shardOpts: ShardOptions{By: ShardByChunk, ChunkSize: 2},
+			expected: []secdb.Shard{
+				{File: "security-0000.json", First: "brotli", Last: "ko", Packages: 3},
+				{File: "security-0001.json", First: "openssl", Last: "openssl", Packages: 1},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := secdbTestOptions(t, tt.dirs...)
+
+			db, err := BuildShardedSecurityDatabase(context.Background(), opts, tt.shardOpts)
+			require.NoError(t, err)
+
+			var shards []secdb.Shard
+			for _, s := range db.Index.Shards {
+				require.Len(t, s.SHA256, 64)
+				s.SHA256 = ""
+				shards = append(shards, s)
+			}
+			assert.Equal(t, tt.expected, shards)
+
+			// Together, the shards have the same entries as the unsharded database.
+			full, err := BuildSecurityDatabase(context.Background(), opts)
+			require.NoError(t, err)
+			var fullDB secdb.Database
+			require.NoError(t, json.Unmarshal(full, &fullDB))
+
+			var entries []secdb.PackageEntry
+			for _, s := range db.Index.Shards {
+				var shardDB secdb.Database
+				require.NoError(t, json.Unmarshal(db.Shards[s.File], &shardDB))
+				assert.Equal(t, fullDB.APKURL, shardDB.APKURL)
+				entries = append(entries, shardDB.Packages...)
+			}
+			assert.ElementsMatch(t, fullDB.Packages, entries)
+		})
+	}
+
+	t.Run("invalid options", func(t *testing.T) {
+		opts := secdbTestOptions(t, "advisories")
+
+		_, err := BuildShardedSecurityDatabase(context.Background(), opts, ShardOptions{By: "size"})
+		assert.ErrorContains(t, err, "invalid sharding strategy")
+
+		_, err = BuildShardedSecurityDatabase(context.Background(), opts, ShardOptions{By: ShardByChunk})
+		assert.ErrorContains(t, err, "chunk size must be at least 1")
+	})
+}
+
+func TestWriteShardedSecurityDatabase(t *testing.T) {
+	dir := t.TempDir()
+	shardOpts := ShardOptions{By: ShardByPrefix, PrefixLength: 1}
+
+	write := func(dirs ...string) *ShardedSecurityDatabaseWriteResult {
+		t.Helper()
+
+		db, err := BuildShardedSecurityDatabase(context.Background(), secdbTestOptions(t, dirs...), shardOpts)
+		require.NoError(t, err)
+		result, err := WriteShardedSecurityDatabase(dir, db)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := write("advisories")
+	assert.Equal(t, []string{"security-b.json", "security-k.json", "security-o.json"}, result.Written)
+	assert.Empty(t, result.Removed)
+	assert.FileExists(t, filepath.Join(dir, secdb.IndexFileName))
+
+	// Nothing changed.
+	result = write("advisories")
+	assert.Empty(t, result.Written)
+	assert.Equal(t, 3, result.Unchanged)
+
+	// A new package is added.
+	result = write("advisories", "other-advisories")
+	assert.Equal(t, []string{"security-c.json"}, result.Written)
+	assert.Equal(t, 3, result.Unchanged)
+
+	// A missing shard file is written again, even if it's unchanged.
+	require.NoError(t, os.Remove(filepath.Join(dir, "security-k.json")))
+	result = write("advisories", "other-advisories")
+	assert.Equal(t, []string{"security-k.json"}, result.Written)
+
+	// Packages are removed.
+	result = write("other-advisories")
+	assert.Empty(t, result.Written)
+	assert.Equal(t, []string{"security-b.json", "security-k.json", "security-o.json"}, result.Removed)
+	assert.NoFileExists(t, filepath.Join(dir, "security-b.json"))
+	assert.FileExists(t, filepath.Join(dir, "security-c.json"))
+
+	b, err := os.ReadFile(filepath.Join(dir, secdb.IndexFileName))
+	require.NoError(t, err)
+	var index secdb.Index
+	require.NoError(t, json.Unmarshal(b, &index))
+	require.Len(t, index.Shards, 1)
+	assert.Equal(t, "security-c.json", index.Shards[0].File)
+}
@@ -0,0 +1,370 @@
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+)
+
+// ServerSource loads the advisory data served by a Server, along with the CVSS
+// data used for the "severity" field of queries, which may be nil.
+type ServerSource func(ctx context.Context) (*configs.Index[v2.Document], CVSSData, error)
+
+// Server is a read-only HTTP API over advisory data. The data is loaded into
+// memory from a ServerSource, and is replaced atomically when Reload is
+// called, so requests are never served from partially loaded data.
+//
+// The server exposes the following endpoints:
+//
+//   - GET /v1/advisories: Responds with the advisories matching all the given
+//     query parameters: "package", "vuln" (the advisory ID or an alias),
+//     "status" (the type of the latest event), and "q" (a Query expression).
+//
+//   - GET /v1/packages/{package}/advisories: Responds with the advisories for
+//     the package.
+//
+//   - GET /v1/vulnerabilities/{id}/advisories: Responds with the advisories for
+//     the vulnerability (by advisory ID or alias) across all packages.
+//
+//   - GET /v1/packages/{package}/vulnerabilities/{id}: Responds with the
+//     package's advisory for the vulnerability, along with its status and
+//     whether it's resolved. If the "version" query parameter is given, the
+//     response also says whether the vulnerability is resolved at that version
+//     of the package. Responds with 404 if there's no such advisory.
+//
+//   - GET /v1/status: Responds with when the data was loaded and how much of it
+//     there is.
+//
+//   - GET /healthz: Responds with 200 OK once data has been loaded.
+//
+// Advisories are rendered as JSON using the advisory schema's PackageAdvisory
+// type, sorted by package and advisory ID.
+type Server struct {
+	source ServerSource
+	mux    *http.ServeMux
+
+	mu       sync.RWMutex
+	snapshot *serverSnapshot
+}
+
+type serverSnapshot struct {
+	loadedAt   time.Time
+	advisories []serverAdvisory
+	byPackage  map[string][]int
+	byVulnID   map[string][]int
+	packages   int
+}
+
+type serverAdvisory struct {
+	v2.PackageAdvisory
+	severity string
+}
+
+// NewServer returns a new Server that serves the data from the given source.
+// Call Reload to load the data before serving requests.
+func NewServer(source ServerSource) *Server {
+	s := &Server{
+		source: source,
+		mux:    http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /v1/advisories", s.handleAdvisories)
+	s.mux.HandleFunc("GET /v1/packages/{package}/advisories", s.handlePackageAdvisories)
+	s.mux.HandleFunc("GET /v1/vulnerabilities/{id}/advisories", s.handleVulnerabilityAdvisories)
+	s.mux.HandleFunc("GET /v1/packages/{package}/vulnerabilities/{id}", s.handlePackageVulnerability)
+	s.mux.HandleFunc("GET /v1/status", s.handleStatus)
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		if s.current() == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Reload loads the data from the server's source, and replaces the data being
+// served with it. If loading fails, the previously loaded data continues to be
+// served.
+func (s *Server) Reload(ctx context.Context) error {
+	index, cvss, err := s.source(ctx)
+	if err != nil {
+		return fmt.Errorf("loading advisory data: %w", err)
+	}
+
+	snapshot := &serverSnapshot{
+		loadedAt:  time.Now(),
+		byPackage: make(map[string][]int),
+		byVulnID:  make(map[string][]int),
+	}
+
+	for _, doc := range index.Select().Configurations() {
+		for _, adv := range doc.Advisories {
+			snapshot.advisories = append(snapshot.advisories, serverAdvisory{
+				PackageAdvisory: v2.PackageAdvisory{PackageName: doc.Package.Name, Advisory: adv},
+				severity:        advisorySeverity(adv, cvss),
+			})
+		}
+	}
+
+	sort.Slice(snapshot.advisories, func(i, j int) bool {
+		a, b := snapshot.advisories[i], snapshot.advisories[j]
+		if a.PackageName != b.PackageName {
+			return a.PackageName < b.PackageName
+		}
+		return a.ID < b.ID
+	})
+
+	for i, adv := range snapshot.advisories {
+		snapshot.byPackage[adv.PackageName] = append(snapshot.byPackage[adv.PackageName], i)
+		for _, id := range adv.VulnerabilityIDs() {
+			snapshot.byVulnID[id] = append(snapshot.byVulnID[id], i)
+		}
+	}
+	snapshot.packages = len(snapshot.byPackage)
+
+	s.mu.Lock()
+	s.snapshot = snapshot
+	s.mu.Unlock()
+
+	clog.FromContext(ctx).Info("loaded advisory data", "packages", snapshot.packages, "advisories", len(snapshot.advisories))
+	return nil
+}
+
+func (s *Server) current() *serverSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshot
+}
+
+// serverError is an error with an associated HTTP status code.
+type serverError struct {
+	status int
+	err    error
+}
+
+func (e *serverError) Error() string {
+	return e.err.Error()
+}
+
+func (e *serverError) Unwrap() error {
+	return e.err
+}
+
+var errServerNotLoaded = &serverError{status: http.StatusServiceUnavailable, err: errors.New("advisory data hasn't been loaded yet")}
+
+func (s *Server) handleAdvisories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	snapshot := s.current()
+	if snapshot == nil {
+		writeServerError(ctx, w, errServerNotLoaded)
+		return
+	}
+
+	params := r.URL.Query()
+
+	var q Query
+	if expr := params.Get("q"); expr != "" {
+		var err error
+		q, err = ParseQuery(expr)
+		if err != nil {
+			writeServerError(ctx, w, &serverError{status: http.StatusBadRequest, err: fmt.Errorf("parsing query: %w", err)})
+			return
+		}
+	}
+
+	status := params.Get("status")
+	if status != "" && !slices.Contains(v2.EventTypes, status) {
+		writeServerError(ctx, w, &serverError{status: http.StatusBadRequest, err: fmt.Errorf("invalid status %q", status)})
+		return
+	}
+
+	// Narrow down the candidates using the lookup tables where possible.
+	var candidates []int
+	switch {
+	case params.Get("package") != "":
+		candidates = snapshot.byPackage[params.Get("package")]
+	case params.Get("vuln") != "":
+		candidates = snapshot.byVulnID[params.Get("vuln")]
+	default:
+		candidates = make([]int, len(snapshot.advisories))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+
+	results := []v2.PackageAdvisory{}
+	for _, i := range candidates {
+		adv := snapshot.advisories[i]
+
+		if pkg := params.Get("package"); pkg != "" && adv.PackageName != pkg {
+			continue
+		}
+		if vuln := params.Get("vuln"); vuln != "" && !adv.DescribesVulnerability(vuln) {
+			continue
+		}
+		if status != "" && adv.Latest().Type != status {
+			continue
+		}
+		if q != nil && !q.Match(QuerySubject{Package: adv.PackageName, Advisory: adv.Advisory, Severity: adv.severity}) {
+			continue
+		}
+
+		results = append(results, adv.PackageAdvisory)
+	}
+
+	writeServerJSON(ctx, w, http.StatusOK, results)
+}
+
+func (s *Server) handlePackageAdvisories(w http.ResponseWriter, r *http.Request) {
+	s.writeAdvisories(w, r, func(snapshot *serverSnapshot) []int {
+		return snapshot.byPackage[r.PathValue("package")]
+	})
+}
+
+func (s *Server) handleVulnerabilityAdvisories(w http.ResponseWriter, r *http.Request) {
+	s.writeAdvisories(w, r, func(snapshot *serverSnapshot) []int {
+		return snapshot.byVulnID[r.PathValue("id")]
+	})
+}
+
+func (s *Server) writeAdvisories(w http.ResponseWriter, r *http.Request, lookup func(*serverSnapshot) []int) {
+	ctx := r.Context()
+
+	snapshot := s.current()
+	if snapshot == nil {
+		writeServerError(ctx, w, errServerNotLoaded)
+		return
+	}
+
+	results := []v2.PackageAdvisory{}
+	for _, i := range lookup(snapshot) {
+		results = append(results, snapshot.advisories[i].PackageAdvisory)
+	}
+
+	writeServerJSON(ctx, w, http.StatusOK, results)
+}
+
+// PackageVulnerabilityResponse is the response of the
+// /v1/packages/{package}/vulnerabilities/{id} endpoint.
+type PackageVulnerabilityResponse struct {
+	Package  string      `json:"package"`
+	Advisory v2.Advisory `json:"advisory"`
+
+	// Status is the type of the advisory's latest event.
+	Status string `json:"status"`
+
+	// Resolved is true if the vulnerability doesn't presently affect the package,
+	// or no further investigation is planned.
+	Resolved bool `json:"resolved"`
+
+	// FixedVersion is the version of the package that fixes the vulnerability, if
+	// the advisory's latest event is a fixed event.
+	FixedVersion string `json:"fixedVersion,omitempty"`
+
+	// ResolvedAtVersion says whether the vulnerability is resolved at the version
+	// given by the request's "version" query parameter, if given.
+	ResolvedAtVersion *bool `json:"resolvedAtVersion,omitempty"`
+}
+
+func (s *Server) handlePackageVulnerability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	snapshot := s.current()
+	if snapshot == nil {
+		writeServerError(ctx, w, errServerNotLoaded)
+		return
+	}
+
+	pkg, id := r.PathValue("package"), r.PathValue("id")
+
+	for _, i := range snapshot.byVulnID[id] {
+		adv := snapshot.advisories[i]
+		if adv.PackageName != pkg {
+			continue
+		}
+
+		latest := adv.Latest()
+		resp := PackageVulnerabilityResponse{
+			Package:  adv.PackageName,
+			Advisory: adv.Advisory,
+			Status:   latest.Type,
+			Resolved: adv.Resolved(),
+		}
+		if fixed, ok := latest.Data.(v2.Fixed); ok {
+			resp.FixedVersion = fixed.FixedVersion
+		}
+		if version := r.URL.Query().Get("version"); version != "" {
+			resolved := adv.ResolvedAtVersion(version, "apk")
+			resp.ResolvedAtVersion = &resolved
+		}
+
+		writeServerJSON(ctx, w, http.StatusOK, resp)
+		return
+	}
+
+	writeServerError(ctx, w, &serverError{status: http.StatusNotFound, err: fmt.Errorf("no advisory for %s in package %q", id, pkg)})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	snapshot := s.current()
+	if snapshot == nil {
+		writeServerError(ctx, w, errServerNotLoaded)
+		return
+	}
+
+	writeServerJSON(ctx, w, http.StatusOK, struct {
+		LoadedAt   time.Time `json:"loadedAt"`
+		Packages   int       `json:"packages"`
+		Advisories int       `json:"advisories"`
+	}{
+		LoadedAt:   snapshot.loadedAt,
+		Packages:   snapshot.packages,
+		Advisories: len(snapshot.advisories),
+	})
+}
+
+func writeServerError(ctx context.Context, w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var se *serverError
+	if errors.As(err, &se) {
+		status = se.status
+	}
+
+	if status >= http.StatusInternalServerError {
+		clog.FromContext(ctx).Warn("advisory request failed", "status", status, "error", err)
+	}
+
+	writeServerJSON(ctx, w, status, struct {
+		Error string `json:"error"`
+	}{
+		Error: err.Error(),
+	})
+}
+
+func writeServerJSON(ctx context.Context, w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		clog.FromContext(ctx).Warn("failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,183 @@
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestServer(t *testing.T) {
+	var failReload bool
+	source := func(ctx context.Context) (*configs.Index[v2.Document], CVSSData, error) {
+		if failReload {
+			return nil, nil, errors.New("boom")
+		}
+
+		fsys := os.DirFS("testdata/remediation")
+		cvss, err := ReadCVSSData(fsys)
+		if err != nil {
+			return nil, nil, err
+		}
+		index, err := adv2.NewIndex(ctx, memfs.New(fsys))
+		return index, cvss, err
+	}
+
+	s := NewServer(source)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	get := func(t *testing.T, path string, v any) int {
+		t.Helper()
+
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		if v != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+		}
+		return resp.StatusCode
+	}
+
+	// The schema's timestamps can't be decoded from JSON, so responses are decoded
+	// into just the fields that are checked.
+	type advisoryResponse struct {
+		PackageName string `json:"packageName"`
+		ID          string `json:"id"`
+	}
+	type packageVulnerabilityResponse struct {
+		Advisory          advisoryResponse `json:"advisory"`
+		Status            string           `json:"status"`
+		Resolved          bool             `json:"resolved"`
+		FixedVersion      string           `json:"fixedVersion"`
+		ResolvedAtVersion *bool            `json:"resolvedAtVersion"`
+	}
+
+	advisoryKeys := func(advs []advisoryResponse) []string {
+		var keys []string
+		for _, adv := range advs {
+			keys = append(keys, adv.PackageName+"/"+adv.ID)
+		}
+		return keys
+	}
+
+	t.Run("not loaded", func(t *testing.T) {
+		assert.Equal(t, http.StatusServiceUnavailable, get(t, "/healthz", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, get(t, "/v1/advisories", nil))
+	})
+
+	require.NoError(t, s.Reload(context.Background()))
+
+	t.Run("status", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get(t, "/healthz", nil))
+
+		var status map[string]any
+		require.Equal(t, http.StatusOK, get(t, "/v1/status", &status))
+		assert.EqualValues(t, 2, status["packages"])
+		assert.EqualValues(t, 6, status["advisories"])
+	})
+
+	t.Run("advisories", func(t *testing.T) {
+		cases := []struct {
+			query    string
+			expected []string
+		}{
+			{
+				query:    "",
+				expected: []string{"crane/CGA-6666-6666-6666", "crane/CGA-7777-7777-7777", "ko/CGA-2222-2222-2222", "ko/CGA-3333-3333-3333", "ko/CGA-4444-4444-4444", "ko/CGA-5555-5555-5555"},
+			},
+			{
+				query:    "?package=crane",
+				expected: []string{"crane/CGA-6666-6666-6666", "crane/CGA-7777-7777-7777"},
+			},
+			{
+				query:    "?vuln=CVE-2024-3333&status=fixed",
+				expected: []string{"crane/CGA-7777-7777-7777", "ko/CGA-3333-3333-3333"},
+			},
+			{
+				query:    "?package=ko&q=severity%3Dcritical",
+				expected: []string{"ko/CGA-2222-2222-2222"},
+			},
+			{
+				query:    "?package=wolfi-baselayout",
+				expected: nil,
+			},
+		}
+
+		for _, tt := range cases {
+			t.Run(tt.query, func(t *testing.T) {
+				var advs []advisoryResponse
+				require.Equal(t, http.StatusOK, get(t, "/v1/advisories"+tt.query, &advs))
+				assert.Equal(t, tt.expected, advisoryKeys(advs))
+			})
+		}
+
+		var body map[string]string
+		assert.Equal(t, http.StatusBadRequest, get(t, "/v1/advisories?q=package%3D", &body))
+		assert.Contains(t, body["error"], "parsing query")
+
+		assert.Equal(t, http.StatusBadRequest, get(t, "/v1/advisories?status=done", nil))
+	})
+
+	t.Run("package advisories", func(t *testing.T) {
+		var advs []advisoryResponse
+		require.Equal(t, http.StatusOK, get(t, "/v1/packages/crane/advisories", &advs))
+		assert.Equal(t, []string{"crane/CGA-6666-6666-6666", "crane/CGA-7777-7777-7777"}, advisoryKeys(advs))
+	})
+
+	t.Run("vulnerability advisories", func(t *testing.T) {
+		var advs []advisoryResponse
+		require.Equal(t, http.StatusOK, get(t, "/v1/vulnerabilities/CVE-2024-2222/advisories", &advs))
+		assert.Equal(t, []string{"crane/CGA-6666-6666-6666", "ko/CGA-2222-2222-2222"}, advisoryKeys(advs))
+	})
+
+	t.Run("package vulnerability", func(t *testing.T) {
+		var resp packageVulnerabilityResponse
+		require.Equal(t, http.StatusOK, get(t, "/v1/packages/ko/vulnerabilities/CVE-2024-3333", &resp))
+		assert.Equal(t, "CGA-3333-3333-3333", resp.Advisory.ID)
+		assert.Equal(t, v2.EventTypeFixed, resp.Status)
+		assert.True(t, resp.Resolved)
+		assert.Equal(t, "0.15.1-r0", resp.FixedVersion)
+		assert.Nil(t, resp.ResolvedAtVersion)
+
+		resp = packageVulnerabilityResponse{}
+		require.Equal(t, http.StatusOK, get(t, "/v1/packages/ko/vulnerabilities/CGA-3333-3333-3333?version=0.15.0-r0", &resp))
+		require.NotNil(t, resp.ResolvedAtVersion)
+		assert.False(t, *resp.ResolvedAtVersion)
+
+		resp = packageVulnerabilityResponse{}
+		require.Equal(t, http.StatusOK, get(t, "/v1/packages/ko/vulnerabilities/CVE-2024-3333?version=0.15.1-r0", &resp))
+		require.NotNil(t, resp.ResolvedAtVersion)
+		assert.True(t, *resp.ResolvedAtVersion)
+
+		resp = packageVulnerabilityResponse{}
+		require.Equal(t, http.StatusOK, get(t, "/v1/packages/ko/vulnerabilities/CVE-2024-4444", &resp))
+		assert.Equal(t, v2.EventTypeDetection, resp.Status)
+		assert.False(t, resp.Resolved)
+		assert.Empty(t, resp.FixedVersion)
+
+		assert.Equal(t, http.StatusNotFound, get(t, "/v1/packages/crane/vulnerabilities/CVE-2024-4444", nil))
+	})
+
+	t.Run("failed reload keeps serving", func(t *testing.T) {
+		failReload = true
+		defer func() { failReload = false }()
+
+		assert.Error(t, s.Reload(context.Background()))
+
+		var advs []advisoryResponse
+		require.Equal(t, http.StatusOK, get(t, "/v1/packages/crane/advisories", &advs))
+		assert.Len(t, advs, 2)
+	})
+}
@@ -0,0 +1,298 @@
+package advisory
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+)
+
+// SignatureBundleSuffix is appended to the path of a signed artifact to get the
+// path of its Sigstore bundle.
+const SignatureBundleSuffix = ".sigstore.json"
+
+// ChecksumsFileName is the name of the file listing the SHA-256 digests of the
+// files of a signed directory, in the format of sha256sum(1). Signing a
+// directory signs this file.
+const ChecksumsFileName = "SHA256SUMS"
+
+// SignOptions configures SignArtifact.
+type SignOptions struct {
+	// Key is the cosign signing key to use, which can be a path to a key file or a
+	// KMS URI. If empty, keyless signing is used, which requires an OIDC identity
+	// (e.g. from an interactive login, or ambient CI credentials).
+	Key string
+
+	// CosignPath is the path to the cosign executable. If empty, "cosign" is found
+	// in the PATH.
+	CosignPath string
+}
+
+// SignArtifact signs an exported advisory artifact, like a security database
+// or an OSV dataset, using cosign, and returns the path of the Sigstore bundle
+// written next to it.
+//
+// If path is a directory, a ChecksumsFileName file listing the digests of all
+// the files in the directory is written to it, and that file is signed instead,
+// so that the whole directory can be verified with one signature.
+//
+// Consumers can verify the artifact with VerifyArtifact, or with "cosign
+// verify-blob --bundle <bundle> <file>" (followed by "sha256sum -c" for a
+// directory).
+func SignArtifact(ctx context.Context, path string, opts SignOptions) (string, error) {
+	blob, err := signedBlobPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	if blob != path {
+		if err := writeChecksums(path); err != nil {
+			return "", err
+		}
+	}
+
+	bundle := blob + SignatureBundleSuffix
+
+	args := []string{
+		"sign-blob",
+		"--bundle", bundle,
+		// Don't prompt for confirmation before uploading to the transparency log.
+		"--yes",
+	}
+	if opts.Key != "" {
+		args = append(args, "--key", opts.Key)
+	}
+	args = append(args, blob)
+
+	clog.FromContext(ctx).Debug("signing advisory artifact", "path", blob, "keyless", opts.Key == "")
+
+	if err := runCosign(ctx, opts.CosignPath, args); err != nil {
+		return "", err
+	}
+
+	return bundle, nil
+}
+
+// VerifyOptions configures VerifyArtifact. Either Key, or both
+// CertificateIdentity and CertificateOIDCIssuer, must be set.
+type VerifyOptions struct {
+	// Key is the cosign public key to verify with, which can be a path to a key
+	// file or a KMS URI.
+	Key string
+
+	// CertificateIdentity and CertificateOIDCIssuer are the identity expected in
+	// the signing certificate of a keyless signature, and the OIDC issuer of that
+	// identity.
+	CertificateIdentity   string
+	CertificateOIDCIssuer string
+
+	// CosignPath is the path to the cosign executable. If empty, "cosign" is found
+	// in the PATH.
+	CosignPath string
+}
+
+// VerifyArtifact verifies the signature of an artifact signed by SignArtifact,
+// using the Sigstore bundle next to it. If path is a directory, the signature
+// of its ChecksumsFileName file is verified, followed by the digests of the
+// files it lists. Files in the directory that aren't listed are an error, since
+// they weren't part of the signed artifact.
+func VerifyArtifact(ctx context.Context, path string, opts VerifyOptions) error {
+	if opts.Key == "" && (opts.CertificateIdentity == "" || opts.CertificateOIDCIssuer == "") {
+		return fmt.Errorf("a key, or a certificate identity and OIDC issuer, are required for verification")
+	}
+
+	blob, err := signedBlobPath(path)
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		"verify-blob",
+		"--bundle", blob + SignatureBundleSuffix,
+	}
+	if opts.Key != "" {
+		args = append(args, "--key", opts.Key)
+	} else {
+		args = append(args,
+			"--certificate-identity", opts.CertificateIdentity,
+			"--certificate-oidc-issuer", opts.CertificateOIDCIssuer,
+		)
+	}
+	args = append(args, blob)
+
+	clog.FromContext(ctx).Debug("verifying advisory artifact", "path", blob)
+
+	if err := runCosign(ctx, opts.CosignPath, args); err != nil {
+		return fmt.Errorf("verifying signature of %q: %w", blob, err)
+	}
+
+	if blob != path {
+		return verifyChecksums(path)
+	}
+
+	return nil
+}
+
+func signedBlobPath(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if fi.IsDir() {
+		return filepath.Join(path, ChecksumsFileName), nil
+	}
+	return path, nil
+}
+
+func runCosign(ctx context.Context, cosignPath string, args []string) error {
+	if cosignPath == "" {
+		cosignPath = "cosign"
+	}
+
+	// Cosign's output is passed through, since signing can be interactive (e.g. to
+	// complete an OIDC login, or to enter the key's password).
+	cmd := exec.CommandContext(ctx, cosignPath, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running cosign: %w", err)
+	}
+
+	return nil
+}
+
+// checksummedFiles returns the paths, relative to dir, of the files covered by
+// the directory's checksums, sorted.
+func checksummedFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ChecksumsFileName || rel == ChecksumsFileName+SignatureBundleSuffix {
+			return nil
+		}
+
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing files of %q: %w", dir, err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func writeChecksums(dir string) error {
+	files, err := checksummedFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for _, f := range files {
+		digest, err := fileSHA256(filepath.Join(dir, filepath.FromSlash(f)))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "%s  %s\n", digest, f)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ChecksumsFileName), []byte(sb.String()), 0o644); err != nil {
+		return fmt.Errorf("writing checksums: %w", err)
+	}
+
+	return nil
+}
+
+func verifyChecksums(dir string) error {
+	f, err := os.Open(filepath.Join(dir, ChecksumsFileName))
+	if err != nil {
+		return fmt.Errorf("reading checksums: %w", err)
+	}
+	defer f.Close()
+
+	expected := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		digest, file, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok {
+			return fmt.Errorf("malformed checksums line: %q", scanner.Text())
+		}
+		expected[file] = digest
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading checksums: %w", err)
+	}
+
+	files, err := checksummedFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, file := range files {
+		digest, ok := expected[file]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: not covered by the signature", file))
+			continue
+		}
+		delete(expected, file)
+
+		actual, err := fileSHA256(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			return err
+		}
+		if actual != digest {
+			errs = append(errs, fmt.Errorf("%s: digest mismatch", file))
+		}
+	}
+
+	missing := make([]string, 0, len(expected))
+	for file := range expected {
+		missing = append(missing, file)
+	}
+	sort.Strings(missing)
+	for _, file := range missing {
+		errs = append(errs, fmt.Errorf("%s: missing", file))
+	}
+
+	return errors.Join(errs...)
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing %q: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,141 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCosign writes an executable that records its arguments and writes a
+// placeholder bundle.
+func fakeCosign(t *testing.T) (cosignPath, argsPath string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	cosignPath = filepath.Join(dir, "cosign")
+	argsPath = filepath.Join(dir, "args")
+
+	script := `#!/bin/sh
+printf '%s\n' "$@" > ` + argsPath + `
+if [ "$1" = sign-blob ]; then
+  while [ $# -gt 0 ]; do
+    case "$1" in
+      --bundle) echo '{}' > "$2"; shift ;;
+    esac
+    shift
+  done
+fi
+`
+	require.NoError(t, os.WriteFile(cosignPath, []byte(script), 0o700)) //nolint:gosec // the fake cosign must be executable
+
+	return cosignPath, argsPath
+}
+
+func readArgs(t *testing.T, argsPath string) []string {
+	t.Helper()
+
+	b, err := os.ReadFile(argsPath)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(b)), "\n")
+}
+
+func TestSignArtifact(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("file", func(t *testing.T) {
+		cosign, argsPath := fakeCosign(t)
+
+		secdb := filepath.Join(t.TempDir(), "security.json")
+		require.NoError(t, os.WriteFile(secdb, []byte(`{"packages":[]}`), 0o600))
+
+		bundle, err := SignArtifact(ctx, secdb, SignOptions{Key: "cosign.key", CosignPath: cosign})
+		require.NoError(t, err)
+
+		assert.Equal(t, secdb+SignatureBundleSuffix, bundle)
+		assert.FileExists(t, bundle)
+		assert.Equal(t, []string{"sign-blob", "--bundle", bundle, "--yes", "--key", "cosign.key", secdb}, readArgs(t, argsPath))
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		cosign, argsPath := fakeCosign(t)
+
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "ko"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "all.json"), []byte("[]"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "ko", "CGA-2222-2222-2222.json"), []byte("{}"), 0o600))
+
+		bundle, err := SignArtifact(ctx, dir, SignOptions{CosignPath: cosign})
+		require.NoError(t, err)
+
+		checksums := filepath.Join(dir, ChecksumsFileName)
+		assert.Equal(t, checksums+SignatureBundleSuffix, bundle)
+		assert.Equal(t, []string{"sign-blob", "--bundle", bundle, "--yes", checksums}, readArgs(t, argsPath))
+
+		b, err := os.ReadFile(checksums)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945  all.json\n"+
+				"44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a  ko/CGA-2222-2222-2222.json\n",
+			string(b),
+		)
+	})
+}
+
+func TestVerifyArtifact(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("requires a key or an identity", func(t *testing.T) {
+		err := VerifyArtifact(ctx, t.TempDir(), VerifyOptions{CertificateIdentity: "someone@example.com"})
+		assert.Error(t, err)
+	})
+
+	t.Run("keyless", func(t *testing.T) {
+		cosign, argsPath := fakeCosign(t)
+
+		secdb := filepath.Join(t.TempDir(), "security.json")
+		require.NoError(t, os.WriteFile(secdb, []byte(`{"packages":[]}`), 0o600))
+
+		err := VerifyArtifact(ctx, secdb, VerifyOptions{
+			CertificateIdentity:   "https://github.com/wolfi-dev/advisories/.github/workflows/release.yaml@refs/heads/main",
+			CertificateOIDCIssuer: "https://token.actions.githubusercontent.com",
+			CosignPath:            cosign,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"verify-blob",
+			"--bundle", secdb + SignatureBundleSuffix,
+			"--certificate-identity", "https://github.com/wolfi-dev/advisories/.github/workflows/release.yaml@refs/heads/main",
+			"--certificate-oidc-issuer", "https://token.actions.githubusercontent.com",
+			secdb,
+		}, readArgs(t, argsPath))
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		cosign, _ := fakeCosign(t)
+		opts := VerifyOptions{Key: "cosign.pub", CosignPath: cosign}
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte("{}"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte("{}"), 0o600))
+
+		_, err := SignArtifact(ctx, dir, SignOptions{CosignPath: cosign})
+		require.NoError(t, err)
+		require.NoError(t, VerifyArtifact(ctx, dir, opts))
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"tampered":true}`), 0o600))
+		require.NoError(t, os.Remove(filepath.Join(dir, "b.json")))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "c.json"), []byte("{}"), 0o600))
+
+		err = VerifyArtifact(ctx, dir, opts)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "a.json: digest mismatch")
+		assert.ErrorContains(t, err, "b.json: missing")
+		assert.ErrorContains(t, err, "c.json: not covered by the signature")
+	})
+}
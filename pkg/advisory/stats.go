@@ -0,0 +1,256 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+)
+
+// SeverityUnknown is the severity used in Stats for advisories whose CVEs have
+// no CVSS data.
+const SeverityUnknown = "UNKNOWN"
+
+// severityRanks orders the CVSS severities, from lowest to highest.
+var severityRanks = map[string]int{
+	SeverityUnknown: 0,
+	"NONE":          1,
+	"LOW":           2,
+	"MEDIUM":        3,
+	"HIGH":          4,
+	"CRITICAL":      5,
+}
+
+// OpenAdvisoryAgeBuckets are the upper bounds of the age buckets used for open
+// advisories in Stats. Open advisories older than the last bound are counted
+// in a final, unbounded bucket.
+var OpenAdvisoryAgeBuckets = []struct {
+	Label string
+	Max   time.Duration
+}{
+	{Label: "<1w", Max: 7 * 24 * time.Hour},
+	{Label: "1-4w", Max: 28 * 24 * time.Hour},
+	{Label: "1-3mo", Max: 91 * 24 * time.Hour},
+	{Label: "3-6mo", Max: 182 * 24 * time.Hour},
+}
+
+const openAdvisoryAgeBucketOldest = ">6mo"
+
+// StatsOptions configures the ComputeStats operation.
+type StatsOptions struct {
+	// Getter is the source of the advisories. Use a MultiGetter to compute
+	// statistics across multiple advisories repos.
+	Getter Getter
+
+	// CVSS is the CVSS data used to determine the severity of advisories. It's
+	// optional; without it, all advisories have an unknown severity.
+	CVSS CVSSData
+
+	// Now is the time used to compute the age of open advisories. If zero, the
+	// current time is used.
+	Now time.Time
+
+	// Weeks is the number of most recent weeks (including the current week) for
+	// which the advisories added per week are counted. If zero, all weeks with at
+	// least one added advisory are counted.
+	Weeks int
+}
+
+// Stats are aggregate statistics over a set of advisories.
+type Stats struct {
+	// Packages is the number of packages with at least one advisory.
+	Packages int `json:"packages"`
+
+	// Advisories is the total number of advisories.
+	Advisories int `json:"advisories"`
+
+	// Open is the number of advisories that aren't resolved, i.e. whose latest
+	// event is a detection or a true positive determination.
+	Open int `json:"open"`
+
+	// ByEventType counts the advisories by the type of their latest event.
+	ByEventType []Count `json:"byEventType"`
+
+	// BySeverity counts the advisories by the highest CVSS severity among their
+	// CVEs.
+	BySeverity []Count `json:"bySeverity"`
+
+	// ByPackage counts the advisories of each package, sorted by the number of
+	// advisories, from most to fewest.
+	ByPackage []PackageCount `json:"byPackage"`
+
+	// OpenByAge counts the open advisories by the time since they were created.
+	OpenByAge []Count `json:"openByAge"`
+
+	// AddedPerWeek counts the advisories by the ISO week in which they were
+	// created, e.g. "2024-W07", from oldest to newest.
+	AddedPerWeek []Count `json:"addedPerWeek"`
+}
+
+// Count is the number of advisories for a given key.
+type Count struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// PackageCount is the number of advisories for a package.
+type PackageCount struct {
+	Package    string `json:"package"`
+	Advisories int    `json:"advisories"`
+	Open       int    `json:"open"`
+}
+
+// ComputeStats computes aggregate statistics over all advisories available
+// from the getter. Advisories without any events are ignored.
+func ComputeStats(ctx context.Context, opts StatsOptions) (*Stats, error) {
+	if opts.Getter == nil {
+		return nil, errors.New("an advisory getter must be provided")
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	packageNames, err := opts.Getter.PackageNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing packages: %w", err)
+	}
+
+	stats := &Stats{}
+	byEventType := make(map[string]int)
+	bySeverity := make(map[string]int)
+	openByAge := make(map[string]int)
+	addedPerWeek := make(map[string]int)
+
+	for _, name := range packageNames {
+		advs, err := opts.Getter.Advisories(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("getting advisories for %q: %w", name, err)
+		}
+
+		pc := PackageCount{Package: name}
+		for i := range advs {
+			adv := advs[i].Advisory
+			if len(adv.Events) == 0 {
+				continue
+			}
+
+			pc.Advisories++
+			byEventType[adv.Latest().Type]++
+			bySeverity[advisorySeverity(adv, opts.CVSS)]++
+
+			created := time.Time(adv.SortedEvents()[0].Timestamp)
+			addedPerWeek[isoWeek(created)]++
+
+			if !adv.Resolved() {
+				pc.Open++
+				openByAge[ageBucket(now.Sub(created))]++
+			}
+		}
+
+		if pc.Advisories == 0 {
+			continue
+		}
+
+		stats.Packages++
+		stats.Advisories += pc.Advisories
+		stats.Open += pc.Open
+		stats.ByPackage = append(stats.ByPackage, pc)
+	}
+
+	sort.Slice(stats.ByPackage, func(i, j int) bool {
+		a, b := stats.ByPackage[i], stats.ByPackage[j]
+		if a.Advisories != b.Advisories {
+			return a.Advisories > b.Advisories
+		}
+		return a.Package < b.Package
+	})
+
+	for _, typ := range v2.EventTypes {
+		if n, ok := byEventType[typ]; ok {
+			stats.ByEventType = append(stats.ByEventType, Count{Key: typ, Count: n})
+		}
+	}
+
+	severities := make([]string, 0, len(bySeverity))
+	for s := range bySeverity {
+		severities = append(severities, s)
+	}
+	sort.Slice(severities, func(i, j int) bool {
+		return severityRanks[severities[i]] > severityRanks[severities[j]]
+	})
+	for _, s := range severities {
+		stats.BySeverity = append(stats.BySeverity, Count{Key: s, Count: bySeverity[s]})
+	}
+
+	for _, b := range OpenAdvisoryAgeBuckets {
+		stats.OpenByAge = append(stats.OpenByAge, Count{Key: b.Label, Count: openByAge[b.Label]})
+	}
+	stats.OpenByAge = append(stats.OpenByAge, Count{Key: openAdvisoryAgeBucketOldest, Count: openByAge[openAdvisoryAgeBucketOldest]})
+
+	stats.AddedPerWeek = weeklyCounts(addedPerWeek, now, opts.Weeks)
+
+	return stats, nil
+}
+
+// advisorySeverity returns the highest CVSS severity among the advisory's CVEs,
+// or SeverityUnknown if none of them have CVSS data.
+func advisorySeverity(adv v2.Advisory, data CVSSData) string {
+	severity := SeverityUnknown
+	for _, id := range adv.VulnerabilityIDs() {
+		r, ok := data[id]
+		if !ok {
+			continue
+		}
+
+		s := strings.ToUpper(r.Severity)
+		if severityRanks[s] > severityRanks[severity] {
+			severity = s
+		}
+	}
+	return severity
+}
+
+func ageBucket(age time.Duration) string {
+	for _, b := range OpenAdvisoryAgeBuckets {
+		if age < b.Max {
+			return b.Label
+		}
+	}
+	return openAdvisoryAgeBucketOldest
+}
+
+func isoWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// weeklyCounts returns the counts of the given weeks in chronological order.
+// If weeks is positive, the counts are limited to that many weeks up to and
+// including the week of now, with weeks without any advisories included as
+// zero counts.
+func weeklyCounts(counts map[string]int, now time.Time, weeks int) []Count {
+	var result []Count
+
+	if weeks > 0 {
+		for i := weeks - 1; i >= 0; i-- {
+			w := isoWeek(now.AddDate(0, 0, -7*i))
+			result = append(result, Count{Key: w, Count: counts[w]})
+		}
+		return result
+	}
+
+	for w, n := range counts {
+		result = append(result, Count{Key: w, Count: n})
+	}
+	// The "YYYY-Www" format sorts chronologically.
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
@@ -0,0 +1,91 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStats(t *testing.T) {
+	fsys := os.DirFS("testdata/stats")
+
+	cvss, err := ReadCVSSData(fsys)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("all weeks", func(t *testing.T) {
+		stats, err := ComputeStats(context.Background(), StatsOptions{
+			Getter: NewFSGetter(fsys),
+			CVSS:   cvss,
+			Now:    now,
+		})
+		require.NoError(t, err)
+
+		expected := &Stats{
+			Packages:   2,
+			Advisories: 5,
+			Open:       3,
+			ByEventType: []Count{
+				{Key: "detection", Count: 2},
+				{Key: "true-positive-determination", Count: 1},
+				{Key: "fixed", Count: 1},
+				{Key: "false-positive-determination", Count: 1},
+			},
+			BySeverity: []Count{
+				{Key: "CRITICAL", Count: 2},
+				{Key: "HIGH", Count: 1},
+				{Key: SeverityUnknown, Count: 2},
+			},
+			ByPackage: []PackageCount{
+				{Package: "ko", Advisories: 3, Open: 2},
+				{Package: "crane", Advisories: 2, Open: 1},
+			},
+			OpenByAge: []Count{
+				{Key: "<1w", Count: 1},
+				{Key: "1-4w", Count: 0},
+				{Key: "1-3mo", Count: 1},
+				{Key: "3-6mo", Count: 0},
+				{Key: ">6mo", Count: 1},
+			},
+			AddedPerWeek: []Count{
+				{Key: "2023-W22", Count: 2},
+				{Key: "2024-W01", Count: 1},
+				{Key: "2024-W08", Count: 1},
+				{Key: "2024-W09", Count: 1},
+			},
+		}
+		assert.Equal(t, expected, stats)
+	})
+
+	t.Run("recent weeks", func(t *testing.T) {
+		stats, err := ComputeStats(context.Background(), StatsOptions{
+			Getter: NewFSGetter(fsys),
+			CVSS:   cvss,
+			Now:    now,
+			Weeks:  3,
+		})
+		require.NoError(t, err)
+
+		expected := []Count{
+			{Key: "2024-W07", Count: 0},
+			{Key: "2024-W08", Count: 1},
+			{Key: "2024-W09", Count: 1},
+		}
+		assert.Equal(t, expected, stats.AddedPerWeek)
+	})
+
+	t.Run("without CVSS data", func(t *testing.T) {
+		stats, err := ComputeStats(context.Background(), StatsOptions{
+			Getter: NewFSGetter(fsys),
+			Now:    now,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []Count{{Key: SeverityUnknown, Count: 5}}, stats.BySeverity)
+	})
+}
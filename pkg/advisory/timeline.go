@@ -0,0 +1,158 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// TimelineEntry is an event of an advisory, in the context of the advisory's
+// other events.
+type TimelineEntry struct {
+	Event v2.Event `json:"event"`
+
+	// SincePrevious is the time between the previous event and this one. It's
+	// zero for the first event.
+	SincePrevious time.Duration `json:"sincePrevious"`
+
+	// Actor is who recorded the event, if known. Advisory data doesn't record
+	// this, but it can be found from the advisories repo's Git history (see
+	// GitEventActors).
+	Actor *EventActor `json:"actor,omitempty"`
+}
+
+// EventActor is who recorded an advisory event.
+type EventActor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+
+	// Commit is the hash of the commit that added the event.
+	Commit string `json:"commit"`
+
+	// Time is when the event was committed, which can be later than the event's
+	// timestamp.
+	Time time.Time `json:"time"`
+}
+
+func (a EventActor) String() string {
+	if a.Email == "" {
+		return a.Name
+	}
+	return fmt.Sprintf("%s <%s>", a.Name, a.Email)
+}
+
+// Timeline returns the events of the advisory in chronological order, with the
+// time between consecutive events.
+func Timeline(adv v2.Advisory) []TimelineEntry {
+	events := adv.SortedEvents()
+	timeline := make([]TimelineEntry, 0, len(events))
+	for i, e := range events {
+		entry := TimelineEntry{Event: e}
+		if i > 0 {
+			entry.SincePrevious = time.Time(e.Timestamp).Sub(time.Time(events[i-1].Timestamp))
+		}
+		timeline = append(timeline, entry)
+	}
+
+	return timeline
+}
+
+// GitEventActors finds who recorded advisory events by reading the Git history
+// of an advisories repo.
+type GitEventActors struct {
+	repo *git.Repository
+}
+
+// NewGitEventActors returns a GitEventActors for the advisories repo at the
+// given directory.
+func NewGitEventActors(repoDir string) (*GitEventActors, error) {
+	repo, err := git.PlainOpenWithOptions(repoDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("opening git repository %q: %w", repoDir, err)
+	}
+
+	return &GitEventActors{repo: repo}, nil
+}
+
+// Annotate sets the Actor of the timeline entries of the given package's
+// advisory. An event's actor is the author of the earliest commit (reachable
+// from HEAD) in which the package's advisory document has the event. Events
+// that aren't committed yet are left without an actor.
+func (a *GitEventActors) Annotate(ctx context.Context, pkgName, advisoryID string, timeline []TimelineEntry) error {
+	log := clog.FromContext(ctx)
+
+	head, err := a.repo.Head()
+	if err != nil {
+		return fmt.Errorf("resolving HEAD: %w", err)
+	}
+
+	path := pkgName + ".advisories.yaml"
+	commits, err := a.repo.Log(&git.LogOptions{From: head.Hash(), FileName: &path})
+	if err != nil {
+		return fmt.Errorf("reading git log for %q: %w", path, err)
+	}
+
+	// The log is newest first, so each event's actor ends up being the author of
+	// the oldest commit that has the event.
+	actors := make(map[string]EventActor)
+	err = commits.ForEach(func(c *object.Commit) error {
+		f, err := c.File(path)
+		if err != nil {
+			if errors.Is(err, object.ErrFileNotFound) {
+				// The commit deleted the file.
+				return nil
+			}
+			return fmt.Errorf("reading %q at commit %s: %w", path, c.Hash, err)
+		}
+
+		contents, err := f.Contents()
+		if err != nil {
+			return fmt.Errorf("reading %q at commit %s: %w", path, c.Hash, err)
+		}
+
+		doc, err := v2.DecodeDocument(strings.NewReader(contents))
+		if err != nil {
+			log.Warn("skipping undecodable advisory document", "path", path, "commit", c.Hash.String(), "error", err)
+			return nil
+		}
+
+		adv, ok := doc.Advisories.Get(advisoryID)
+		if !ok {
+			return nil
+		}
+
+		for _, e := range adv.Events {
+			actors[eventKey(e)] = EventActor{
+				Name:   c.Author.Name,
+				Email:  c.Author.Email,
+				Commit: c.Hash.String(),
+				Time:   c.Committer.When,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range timeline {
+		if actor, ok := actors[eventKey(timeline[i].Event)]; ok {
+			timeline[i].Actor = &actor
+		}
+	}
+
+	return nil
+}
+
+// eventKey identifies an event within an advisory across revisions of the
+// advisory data. Events' data can be edited, so it's not part of the key.
+func eventKey(e v2.Event) string {
+	return fmt.Sprintf("%s/%s", time.Time(e.Timestamp).UTC().Format(time.RFC3339Nano), e.Type)
+}
@@ -0,0 +1,102 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	adv := v2.Advisory{
+		ID: "CGA-2222-2222-2222",
+		Events: []v2.Event{
+			{Timestamp: v2.Timestamp(t0.Add(26 * time.Hour)), Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: "1.0.0-r1"}},
+			{Timestamp: v2.Timestamp(t0), Type: v2.EventTypeDetection, Data: v2.Detection{Type: v2.DetectionTypeManual}},
+			{Timestamp: v2.Timestamp(t0.Add(2 * time.Hour)), Type: v2.EventTypeTruePositiveDetermination},
+		},
+	}
+
+	timeline := Timeline(adv)
+	require.Len(t, timeline, 3)
+	assert.Equal(t, v2.EventTypeDetection, timeline[0].Event.Type)
+	assert.Zero(t, timeline[0].SincePrevious)
+	assert.Equal(t, v2.EventTypeTruePositiveDetermination, timeline[1].Event.Type)
+	assert.Equal(t, 2*time.Hour, timeline[1].SincePrevious)
+	assert.Equal(t, v2.EventTypeFixed, timeline[2].Event.Type)
+	assert.Equal(t, 24*time.Hour, timeline[2].SincePrevious)
+}
+
+func TestGitEventActors(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	const file = "ko.advisories.yaml"
+	commit := func(author, contents string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(contents), 0o600))
+		_, err := wt.Add(file)
+		require.NoError(t, err)
+		_, err = wt.Commit("update "+file, &git.CommitOptions{
+			Author: &object.Signature{Name: author, Email: author + "@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+	}
+
+	const detected = `schema-version: 2.0.2
+
+package:
+  name: ko
+
+advisories:
+  - id: CGA-2222-2222-2222
+    events:
+      - timestamp: 2024-01-01T00:00:00Z
+        type: detection
+        data:
+          type: manual
+`
+	const fixedEvent = `      - timestamp: 2024-01-02T00:00:00Z
+        type: fixed
+        data:
+          fixed-version: 1.0.0-r1
+`
+	commit("alice", detected)
+	commit("bob", detected+fixedEvent)
+	commit("carol", detected+fixedEvent+"      - timestamp: 2024-01-03T00:00:00Z\n        type: fix-not-planned\n        data:\n          note: uncommitted\n")
+
+	adv := v2.Advisory{
+		ID: "CGA-2222-2222-2222",
+		Events: []v2.Event{
+			{Timestamp: v2.Timestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), Type: v2.EventTypeDetection},
+			{Timestamp: v2.Timestamp(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)), Type: v2.EventTypeFixed},
+			{Timestamp: v2.Timestamp(time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)), Type: v2.EventTypeFixNotPlanned},
+		},
+	}
+	timeline := Timeline(adv)
+
+	actors, err := NewGitEventActors(dir)
+	require.NoError(t, err)
+	require.NoError(t, actors.Annotate(context.Background(), "ko", adv.ID, timeline))
+
+	require.NotNil(t, timeline[0].Actor)
+	assert.Equal(t, "alice", timeline[0].Actor.Name)
+	assert.Equal(t, "alice <alice@example.com>", timeline[0].Actor.String())
+	assert.Len(t, timeline[0].Actor.Commit, 40)
+	require.NotNil(t, timeline[1].Actor)
+	assert.Equal(t, "bob", timeline[1].Actor.Name)
+
+	// The event's timestamp differs from the committed event's.
+	assert.Nil(t, timeline[2].Actor)
+}
@@ -0,0 +1,260 @@
+package advisory
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	"gopkg.in/yaml.v3"
+)
+
+// OwnersFileName is the name of the file at the root of an advisories repo that
+// maps packages to the maintainers or teams who own them. The file is hidden so
+// that it's not mistaken for an advisory document.
+const OwnersFileName = ".owners.yaml"
+
+// AssignmentsFileName is the name of the file at the root of an advisories repo
+// that stores who is assigned to triage each open advisory. The advisory schema
+// doesn't have a place for this data, and it's not part of the advisories'
+// history, so it's kept separately. The file is hidden so that it's not
+// mistaken for an advisory document.
+const AssignmentsFileName = ".assignments.yaml"
+
+// OwnershipRule assigns owners to packages.
+type OwnershipRule struct {
+	// Packages are the names of the packages, or glob patterns (as in path.Match)
+	// matching them, e.g. "py3-*".
+	Packages []string `yaml:"packages"`
+
+	// Owners are the maintainers or teams who own the packages.
+	Owners []string `yaml:"owners"`
+}
+
+// Ownership is the list of rules that map packages to their owners. As with
+// CODEOWNERS files, the last rule that matches a package determines its owners.
+type Ownership []OwnershipRule
+
+// ReadOwnership reads the ownership rules from the OwnersFileName file in the
+// given filesystem. If the file doesn't exist, there are no rules.
+func ReadOwnership(fsys fs.FS) (Ownership, error) {
+	b, err := fs.ReadFile(fsys, OwnersFileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Ownership{}, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", OwnersFileName, err)
+	}
+
+	var o Ownership
+	if err := yaml.Unmarshal(b, &o); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", OwnersFileName, err)
+	}
+
+	var errs []error
+	for i, r := range o {
+		if len(r.Packages) == 0 {
+			errs = append(errs, fmt.Errorf("rule %d: packages are required", i+1))
+		}
+		if len(r.Owners) == 0 {
+			errs = append(errs, fmt.Errorf("rule %d: owners are required", i+1))
+		}
+		for _, pattern := range r.Packages {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("rule %d: invalid package pattern %q: %w", i+1, pattern, err))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", OwnersFileName, err)
+	}
+
+	return o, nil
+}
+
+// Owners returns the owners of the package, or nil if no rule matches it.
+func (o Ownership) Owners(pkg string) []string {
+	for i := len(o) - 1; i >= 0; i-- {
+		if slices.ContainsFunc(o[i].Packages, func(pattern string) bool {
+			matched, _ := path.Match(pattern, pkg) //nolint:errcheck // Patterns are validated when read.
+			return matched
+		}) {
+			return o[i].Owners
+		}
+	}
+	return nil
+}
+
+// Assignment is the assignment of an advisory to someone who triages it.
+type Assignment struct {
+	// Package is the name of the package the advisory is for.
+	Package string `yaml:"package"`
+
+	// Assignee is the maintainer or team the advisory is assigned to.
+	Assignee string `yaml:"assignee"`
+
+	// Assigned is when the advisory was assigned.
+	Assigned time.Time `yaml:"assigned"`
+}
+
+// Assignments are the assignments of advisories, keyed by advisory ID.
+type Assignments map[string]Assignment
+
+// ReadAssignments reads the assignments from the AssignmentsFileName file in
+// the given filesystem. If the file doesn't exist, there are no assignments.
+func ReadAssignments(fsys fs.FS) (Assignments, error) {
+	b, err := fs.ReadFile(fsys, AssignmentsFileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Assignments{}, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", AssignmentsFileName, err)
+	}
+
+	a := Assignments{}
+	if err := yaml.Unmarshal(b, &a); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", AssignmentsFileName, err)
+	}
+	return a, nil
+}
+
+// Encode writes the assignments as YAML, sorted by advisory ID.
+func (a Assignments) Encode(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(a); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Prune removes the assignments of advisories that aren't open anymore (or no
+// longer exist), since they don't need triage. It returns the IDs of the
+// removed assignments, sorted.
+func (a Assignments) Prune(docs *configs.Index[v2.Document]) []string {
+	open := make(map[string]struct{})
+	for _, doc := range docs.Select().Configurations() {
+		for _, adv := range doc.Advisories {
+			if isOpenAdvisory(adv) {
+				open[adv.ID] = struct{}{}
+			}
+		}
+	}
+
+	var pruned []string
+	for id := range a {
+		if _, ok := open[id]; !ok {
+			delete(a, id)
+			pruned = append(pruned, id)
+		}
+	}
+	sort.Strings(pruned)
+
+	return pruned
+}
+
+// TriageOptions configures the FindTriageItems operation.
+type TriageOptions struct {
+	// AdvisoryDocs is the Index of advisory documents on which to operate.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// SelectedPackages is the set of packages to operate on. If empty, all
+	// packages are operated on.
+	SelectedPackages map[string]struct{}
+
+	// Ownership maps the packages to their owners.
+	Ownership Ownership
+
+	// Assignments are the current assignments of the advisories.
+	Assignments Assignments
+}
+
+// TriageItem is an open advisory, i.e. one whose latest event isn't a fix, a
+// false positive determination, or a fix-not-planned event, which needs triage.
+type TriageItem struct {
+	// Package is the name of the package the advisory is for.
+	Package string
+
+	// Advisory is the open advisory.
+	Advisory v2.Advisory
+
+	// Owners are the owners of the package.
+	Owners []string
+
+	// Assignee is who the advisory is assigned to, or empty if it's unassigned.
+	Assignee string
+}
+
+// Queues returns the names of the triage queues the item is in: its assignee's,
+// or, if it's unassigned, those of each of its package's owners. An item that's
+// neither assigned nor owned is in no queue.
+func (i TriageItem) Queues() []string {
+	if i.Assignee != "" {
+		return []string{i.Assignee}
+	}
+	return i.Owners
+}
+
+// FindTriageItems returns the open advisories of the selected packages, sorted
+// by package and advisory ID, with their owners and assignees.
+func FindTriageItems(opts TriageOptions) ([]TriageItem, error) {
+	if opts.AdvisoryDocs == nil {
+		return nil, errors.New("advisory documents must be provided")
+	}
+
+	var items []TriageItem
+	for _, doc := range opts.AdvisoryDocs.Select().Configurations() {
+		pkg := doc.Package.Name
+		if len(opts.SelectedPackages) > 0 {
+			if _, ok := opts.SelectedPackages[pkg]; !ok {
+				continue
+			}
+		}
+
+		for _, adv := range doc.Advisories {
+			if !isOpenAdvisory(adv) {
+				continue
+			}
+
+			items = append(items, TriageItem{
+				Package:  pkg,
+				Advisory: adv,
+				Owners:   opts.Ownership.Owners(pkg),
+				Assignee: opts.Assignments[adv.ID].Assignee,
+			})
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Package != items[j].Package {
+			return items[i].Package < items[j].Package
+		}
+		return items[i].Advisory.ID < items[j].Advisory.ID
+	})
+
+	return items, nil
+}
+
+// TriageQueues groups the items by the queues they're in (see
+// TriageItem.Queues), keeping the items' order within each queue.
+func TriageQueues(items []TriageItem) map[string][]TriageItem {
+	queues := make(map[string][]TriageItem)
+	for _, item := range items {
+		for _, q := range item.Queues() {
+			queues[q] = append(queues[q], item)
+		}
+	}
+	return queues
+}
+
+// isOpenAdvisory returns true if the advisory has events and its latest event
+// doesn't conclude it.
+func isOpenAdvisory(adv v2.Advisory) bool {
+	return len(adv.Events) > 0 && !isTerminalEvent(adv.Latest())
+}
@@ -0,0 +1,86 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestTriage(t *testing.T) {
+	fsys := os.DirFS("testdata/triage")
+
+	docs, err := adv2.NewIndex(context.Background(), memfs.New(fsys))
+	require.NoError(t, err)
+
+	ownership, err := ReadOwnership(fsys)
+	require.NoError(t, err)
+	assignments, err := ReadAssignments(fsys)
+	require.NoError(t, err)
+
+	t.Run("ownership", func(t *testing.T) {
+		assert.Equal(t, []string{"@go-team", "alice"}, ownership.Owners("ko"))
+		assert.Equal(t, []string{"bob"}, ownership.Owners("py3-requests"))
+		assert.Equal(t, []string{"@security"}, ownership.Owners("zlib"))
+		assert.Nil(t, Ownership{}.Owners("zlib"))
+	})
+
+	t.Run("invalid ownership", func(t *testing.T) {
+		_, err := ReadOwnership(fstest.MapFS{OwnersFileName: {Data: []byte("- packages: [\"[\"]\n")}})
+		assert.ErrorContains(t, err, "owners are required")
+		assert.ErrorContains(t, err, `invalid package pattern "["`)
+	})
+
+	t.Run("items and queues", func(t *testing.T) {
+		items, err := FindTriageItems(TriageOptions{
+			AdvisoryDocs: docs,
+			Ownership:    ownership,
+			Assignments:  assignments,
+		})
+		require.NoError(t, err)
+
+		// CGA-4444-4444-4444 is fixed, so it's not open.
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.Advisory.ID)
+		}
+		assert.Equal(t, []string{"CGA-2222-2222-2222", "CGA-3333-3333-3333", "CGA-5555-5555-5555", "CGA-6666-6666-6666"}, ids)
+		assert.Equal(t, "carol", items[1].Assignee)
+
+		queues := TriageQueues(items)
+		queueIDs := make(map[string][]string)
+		for q, items := range queues {
+			for _, item := range items {
+				queueIDs[q] = append(queueIDs[q], item.Advisory.ID)
+			}
+		}
+		assert.Equal(t, map[string][]string{
+			"@go-team":  {"CGA-2222-2222-2222"},
+			"alice":     {"CGA-2222-2222-2222"},
+			"carol":     {"CGA-3333-3333-3333"},
+			"bob":       {"CGA-5555-5555-5555"},
+			"@security": {"CGA-6666-6666-6666"},
+		}, queueIDs)
+	})
+
+	t.Run("prune and encode", func(t *testing.T) {
+		a, err := ReadAssignments(fsys)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"CGA-4444-4444-4444"}, a.Prune(docs))
+
+		var sb strings.Builder
+		require.NoError(t, a.Encode(&sb))
+		assert.Equal(t, `CGA-3333-3333-3333:
+  package: ko
+  assignee: carol
+  assigned: 2024-05-02T00:00:00Z
+`, sb.String())
+	})
+}
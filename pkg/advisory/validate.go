@@ -55,6 +55,17 @@ type ValidateOptions struct {
 	// validating the advisories. This gets computed dynamically using APKIndex
 	// before validation happens.
 	apkIndexPackageMap map[string][]*apk.Package
+
+	// Deep enables semantic validation of the advisories beyond what the schema
+	// requires: event timestamps must be in order, aliases must be plausible CVE
+	// or GHSA IDs, false positive determinations must have a known type, and fixed
+	// versions must exist in the APKINDEX or in the package's version history.
+	Deep bool
+
+	// PackageVersionHistory is used during deep validation to look up the
+	// versions a package has ever had, for fixed versions that aren't in the
+	// APKINDEX. If nil, only the APKINDEX and PackageConfigurations are consulted.
+	PackageVersionHistory PackageVersionHistory
 }
 
 func Validate(ctx context.Context, opts ValidateOptions) error {
@@ -90,6 +101,10 @@ func Validate(ctx context.Context, opts ValidateOptions) error {
 		log.Info("skipping validation of alias set completeness, no alias finder provided")
 	}
 
+	if opts.Deep {
+		errs = append(errs, opts.validateDeep(ctx))
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -344,8 +359,11 @@ func (opts ValidateOptions) validateIndexDiff(ctx context.Context, diff IndexDif
 
 		var docErrs []error
 
-		// Modified documents must be for packages that are currently defined in the repo or still exist in APKINDEX entries.
-		docErrs = append(docErrs, opts.validateBuildConfigurationOrAPKIndexEntryExistence(docAdvs.Name))
+		// Modified documents must be for packages that are currently defined in the repo or still exist in APKINDEX entries,
+		// unless the modifications just resolve the advisories of a withdrawn package.
+		if !isWithdrawnPackageResolution(docAdvs) {
+			docErrs = append(docErrs, opts.validateBuildConfigurationOrAPKIndexEntryExistence(docAdvs.Name))
+		}
 
 		advsRemovedErrs := lo.Map(docAdvs.Removed, func(adv v2.Advisory, _ int) error {
 			return errorhelpers.LabelError(adv.ID, errors.New("advisory was removed"))
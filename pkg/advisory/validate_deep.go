@@ -0,0 +1,175 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/advisory-schema/pkg/vuln"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/internal/errorhelpers"
+)
+
+// firstCVEYear is the year of the earliest CVE IDs.
+const firstCVEYear = 1999
+
+func (opts ValidateOptions) validateDeep(ctx context.Context) error {
+	log := clog.FromContext(ctx)
+	log.Info("validating advisories deeply")
+
+	if opts.APKIndex == nil && opts.PackageConfigurations == nil && opts.PackageVersionHistory == nil {
+		log.Warn("not validating fixed version existence, no APKINDEX, package configurations, or package version history provided")
+	}
+
+	var errs []error
+
+	documents := opts.AdvisoryDocs.Select().Configurations()
+	for i := range documents {
+		doc := documents[i]
+
+		if len(opts.SelectedPackages) > 0 {
+			if _, ok := opts.SelectedPackages[doc.Name()]; !ok {
+				// Skip this document, since it's not in the set of selected packages.
+				continue
+			}
+		}
+
+		var docErrs []error
+		for i := range doc.Advisories {
+			adv := doc.Advisories[i]
+
+			advErrs := []error{
+				opts.validateEventOrder(adv),
+				opts.validateAliasPlausibility(adv),
+			}
+
+			for j, e := range adv.Events {
+				label := fmt.Sprintf("event %d", j+1)
+
+				switch e.Type {
+				case v2.EventTypeFalsePositiveDetermination:
+					fp, ok := e.Data.(v2.FalsePositiveDetermination)
+					if !ok {
+						advErrs = append(advErrs, errorhelpers.LabelError(label, errors.New("data is not of type FalsePositiveDetermination")))
+						continue
+					}
+					if !slices.Contains(v2.FPTypes, fp.Type) {
+						advErrs = append(advErrs, errorhelpers.LabelError(label, fmt.Errorf(
+							"false positive determination type %q is not one of [%s]",
+							fp.Type,
+							strings.Join(v2.FPTypes, ", "),
+						)))
+					}
+
+				case v2.EventTypeFixed:
+					fixed, ok := e.Data.(v2.Fixed)
+					if !ok {
+						advErrs = append(advErrs, errorhelpers.LabelError(label, errors.New("data is not of type Fixed")))
+						continue
+					}
+					advErrs = append(advErrs, errorhelpers.LabelError(label, opts.validateFixedVersionExists(ctx, doc.Name(), fixed.FixedVersion)))
+				}
+			}
+
+			docErrs = append(docErrs, errorhelpers.LabelError(adv.ID, errors.Join(advErrs...)))
+		}
+
+		errs = append(errs, errorhelpers.LabelError(doc.Name(), errors.Join(docErrs...)))
+	}
+
+	return errorhelpers.LabelError("deep validation failure(s)", errors.Join(errs...))
+}
+
+// validateEventOrder checks that the advisory's events are recorded in
+// chronological order, and that none of them are in the future.
+func (opts ValidateOptions) validateEventOrder(adv v2.Advisory) error {
+	var errs []error
+	for i, e := range adv.Events {
+		t := time.Time(e.Timestamp)
+
+		if i > 0 {
+			prev := time.Time(adv.Events[i-1].Timestamp)
+			if t.Before(prev) {
+				errs = append(errs, fmt.Errorf(
+					"event %d's timestamp (%s) is earlier than event %d's timestamp (%s); events must be in chronological order",
+					i+1,
+					e.Timestamp,
+					i,
+					adv.Events[i-1].Timestamp,
+				))
+			}
+		}
+
+		if !opts.Now.IsZero() && t.After(opts.Now) {
+			errs = append(errs, fmt.Errorf("event %d's timestamp (%s) is in the future", i+1, e.Timestamp))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateAliasPlausibility checks that the advisory's aliases are well-formed
+// CVE or GHSA IDs (or Go vulnerability IDs), and that CVE IDs are from a year
+// that CVE IDs could have been assigned in.
+func (opts ValidateOptions) validateAliasPlausibility(adv v2.Advisory) error {
+	var errs []error
+	for _, alias := range adv.Aliases {
+		switch {
+		case vuln.RegexCVE.MatchString(alias):
+			// The regex guarantees the year is made of digits.
+			year, _ := strconv.Atoi(strings.Split(alias, "-")[1])
+			if year < firstCVEYear || (!opts.Now.IsZero() && year > opts.Now.Year()) {
+				errs = append(errs, fmt.Errorf("alias %q has an implausible year", alias))
+			}
+
+		case vuln.RegexGHSA.MatchString(alias), vuln.RegexGO.MatchString(alias):
+			// Nothing more to check.
+
+		default:
+			errs = append(errs, fmt.Errorf("alias %q is not a well-formed CVE or GHSA ID", alias))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateFixedVersionExists checks that the package has had the fixed version,
+// according to the APKINDEX, the package's current build configuration, or the
+// package's version history.
+func (opts ValidateOptions) validateFixedVersionExists(ctx context.Context, pkgName, version string) error {
+	if opts.APKIndex == nil && opts.PackageConfigurations == nil && opts.PackageVersionHistory == nil {
+		// Not enough input information to drive this validation check.
+		return nil
+	}
+
+	for _, pkg := range opts.apkIndexPackageMap[pkgName] {
+		if pkg.Version == version {
+			return nil
+		}
+	}
+
+	if cfg, ok := opts.distroPackageMap[pkgName]; ok {
+		if fmt.Sprintf("%s-r%d", cfg.Package.Version, cfg.Package.Epoch) == version {
+			return nil
+		}
+	}
+
+	if opts.PackageVersionHistory != nil {
+		versions, err := opts.PackageVersionHistory.Versions(ctx, pkgName)
+		if err != nil {
+			return fmt.Errorf("getting version history of package %q: %w", pkgName, err)
+		}
+		if slices.Contains(versions, version) {
+			return nil
+		}
+
+		return fmt.Errorf("fixed version %q not found in APKINDEX or in the package's version history", version)
+	}
+
+	return fmt.Errorf("fixed version %q not found in APKINDEX or in the package's build configuration", version)
+}
@@ -178,6 +178,20 @@ func TestValidate(t *testing.T) {
 					apkindex:        &apk.APKIndex{},
 					shouldBeValid:   false,
 				},
+				{
+					name:            "added-event-withdrawn",
+					subcase:         "package not in distro or APKINDEX",
+					packageCfgsFunc: distroWithNothing,
+					apkindex:        &apk.APKIndex{},
+					shouldBeValid:   true,
+				},
+				{
+					name:            "added-event-fix-not-planned",
+					subcase:         "package not in distro or APKINDEX",
+					packageCfgsFunc: distroWithNothing,
+					apkindex:        &apk.APKIndex{},
+					shouldBeValid:   false,
+				},
 				{
 					name:            "added-event-fixed",
 					subcase:         "fixed version in APKINDEX but not distro",
@@ -348,6 +362,77 @@ func TestValidate(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("deep", func(t *testing.T) {
+		cases := []struct {
+			name          string
+			shouldBeValid bool
+		}{
+			{
+				name:          "deep-valid",
+				shouldBeValid: true,
+			},
+			{
+				name:          "deep-unordered-events",
+				shouldBeValid: false,
+			},
+			{
+				name:          "deep-implausible-alias",
+				shouldBeValid: false,
+			},
+			{
+				name:          "deep-fixed-version-missing",
+				shouldBeValid: false,
+			},
+		}
+
+		apkIndex := &apk.APKIndex{
+			Packages: []*apk.Package{
+				{
+					Name:    "ko",
+					Version: "1.0.0-r2",
+				},
+			},
+		}
+
+		history := mockPackageVersionHistory{
+			"ko": {"0.9.0-r0", "1.0.0-r2"},
+		}
+
+		for _, tt := range cases {
+			t.Run(tt.name, func(t *testing.T) {
+				dir := filepath.Join("testdata", "validate", tt.name)
+				fsys := rwos.DirFS(dir)
+				index, err := adv2.NewIndex(context.Background(), fsys)
+				require.NoError(t, err)
+
+				opts := ValidateOptions{
+					AdvisoryDocs:          index,
+					Now:                   now,
+					APKIndex:              apkIndex,
+					PackageVersionHistory: history,
+				}
+
+				// Deep validation issues aren't schema violations.
+				require.NoError(t, Validate(context.Background(), opts))
+
+				opts.Deep = true
+				err = Validate(context.Background(), opts)
+				if tt.shouldBeValid && err != nil {
+					t.Errorf("should be valid but got error: %v", err)
+				}
+				if !tt.shouldBeValid && err == nil {
+					t.Error("shouldn't be valid but got no error")
+				}
+			})
+		}
+	})
+}
+
+type mockPackageVersionHistory map[string][]string
+
+func (h mockPackageVersionHistory) Versions(_ context.Context, pkgName string) ([]string, error) {
+	return h[pkgName], nil
 }
 
 func distroWithKo(t *testing.T) *configs.Index[config.Configuration] {
@@ -0,0 +1,189 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"gopkg.in/yaml.v3"
+)
+
+// PackageVersionHistory looks up the versions that a distro package has ever
+// been defined with.
+type PackageVersionHistory interface {
+	// Versions returns the full versions (including the epoch, e.g. "1.2.3-r1") of
+	// the package.
+	Versions(ctx context.Context, pkgName string) ([]string, error)
+}
+
+// PackageVersionChange is a version that a distro package was defined with, and
+// the time that definition landed in the distro.
+type PackageVersionChange struct {
+	// Version is the full version (including the epoch) of the package.
+	Version string
+
+	// Time is when the package started being defined with the version.
+	Time time.Time
+
+	// Commit is the hash of the commit that changed the package's version, if the
+	// timeline comes from Git.
+	Commit string
+}
+
+// PackageVersionTimeline looks up when a distro package's version changed.
+type PackageVersionTimeline interface {
+	// Timeline returns the changes to the package's version, oldest first.
+	Timeline(ctx context.Context, pkgName string) ([]PackageVersionChange, error)
+}
+
+// GitPackageVersionHistory is a PackageVersionHistory and PackageVersionTimeline
+// that reads the versions from the Git history of the package's build
+// configuration in a distro repo.
+type GitPackageVersionHistory struct {
+	repo  *git.Repository
+	cache map[string][]PackageVersionChange
+}
+
+// NewGitPackageVersionHistory returns a GitPackageVersionHistory for the distro
+// repo at the given directory.
+func NewGitPackageVersionHistory(repoDir string) (*GitPackageVersionHistory, error) {
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return nil, fmt.Errorf("opening git repository %q: %w", repoDir, err)
+	}
+
+	return &GitPackageVersionHistory{
+		repo:  repo,
+		cache: make(map[string][]PackageVersionChange),
+	}, nil
+}
+
+// Versions returns the versions of the package in each commit (reachable from
+// HEAD) that changed the package's build configuration file, newest first.
+func (h *GitPackageVersionHistory) Versions(ctx context.Context, pkgName string) ([]string, error) {
+	timeline, err := h.Timeline(ctx, pkgName)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var versions []string
+	for i := len(timeline) - 1; i >= 0; i-- {
+		v := timeline[i].Version
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			versions = append(versions, v)
+		}
+	}
+
+	return versions, nil
+}
+
+// Timeline returns the package's version changes, using the commit time of the
+// commits (reachable from HEAD) that changed the package's build configuration
+// file. Commits that don't change the version aren't included.
+func (h *GitPackageVersionHistory) Timeline(ctx context.Context, pkgName string) ([]PackageVersionChange, error) {
+	if timeline, ok := h.cache[pkgName]; ok {
+		return timeline, nil
+	}
+
+	log := clog.FromContext(ctx)
+	log.Debug("reading package version history from git", "package", pkgName)
+
+	head, err := h.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("resolving HEAD: %w", err)
+	}
+
+	path := pkgName + ".yaml"
+	commits, err := h.repo.Log(&git.LogOptions{From: head.Hash(), FileName: &path})
+	if err != nil {
+		return nil, fmt.Errorf("reading git log for %q: %w", path, err)
+	}
+
+	var changes []PackageVersionChange
+	err = commits.ForEach(func(c *object.Commit) error {
+		f, err := c.File(path)
+		if err != nil {
+			if errors.Is(err, object.ErrFileNotFound) {
+				// The commit deleted the file.
+				return nil
+			}
+			return fmt.Errorf("reading %q at commit %s: %w", path, c.Hash, err)
+		}
+
+		contents, err := f.Contents()
+		if err != nil {
+			return fmt.Errorf("reading %q at commit %s: %w", path, c.Hash, err)
+		}
+
+		var cfg struct {
+			Package struct {
+				Version string `yaml:"version"`
+				Epoch   uint64 `yaml:"epoch"`
+			} `yaml:"package"`
+		}
+		if err := yaml.Unmarshal([]byte(contents), &cfg); err != nil {
+			log.Warn("skipping unparseable build configuration", "path", path, "commit", c.Hash.String(), "error", err)
+			return nil
+		}
+		if cfg.Package.Version == "" {
+			return nil
+		}
+
+		changes = append(changes, PackageVersionChange{
+			Version: cfg.Package.Version + "-r" + strconv.FormatUint(cfg.Package.Epoch, 10),
+			Time:    c.Committer.When,
+			Commit:  c.Hash.String(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The log is newest first, and commits can share a timestamp, so reverse it
+	// before sorting to keep the log's order for those.
+	slices.Reverse(changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+
+	// Only keep the commits that changed the version.
+	var timeline []PackageVersionChange
+	for _, c := range changes {
+		if len(timeline) > 0 && timeline[len(timeline)-1].Version == c.Version {
+			continue
+		}
+		timeline = append(timeline, c)
+	}
+
+	h.cache[pkgName] = timeline
+	return timeline, nil
+}
+
+// MultiPackageVersionTimeline is a PackageVersionTimeline that looks up a
+// package in each of its timelines (e.g. of several distro repos), returning the
+// first non-empty result.
+type MultiPackageVersionTimeline []PackageVersionTimeline
+
+func (m MultiPackageVersionTimeline) Timeline(ctx context.Context, pkgName string) ([]PackageVersionChange, error) {
+	for _, t := range m {
+		timeline, err := t.Timeline(ctx, pkgName)
+		if err != nil {
+			return nil, err
+		}
+		if len(timeline) > 0 {
+			return timeline, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,60 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitPackageVersionHistory(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	commit := func(file, contents string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(contents), 0o600))
+		_, err := wt.Add(file)
+		require.NoError(t, err)
+		_, err = wt.Commit("update "+file, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+	}
+
+	commit("ko.yaml", "package:\n  name: ko\n  version: 0.9.0\n  epoch: 0\n")
+	commit("other.yaml", "package:\n  name: other\n  version: 5.0.0\n  epoch: 0\n")
+	commit("ko.yaml", "package:\n  name: ko\n  version: 0.9.0\n  epoch: 1\n")
+	commit("ko.yaml", "package:\n  name: ko\n  version: 1.0.0\n  epoch: 0\n")
+	commit("ko.yaml", "package:\n  name: ko\n  version: 1.0.0\n  epoch: 0\n  description: ko\n")
+
+	h, err := NewGitPackageVersionHistory(dir)
+	require.NoError(t, err)
+
+	versions, err := h.Versions(context.Background(), "ko")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0.9.0-r0", "0.9.0-r1", "1.0.0-r0"}, versions)
+
+	timeline, err := h.Timeline(context.Background(), "ko")
+	require.NoError(t, err)
+	var timelineVersions []string
+	for _, c := range timeline {
+		assert.False(t, c.Time.IsZero())
+		assert.Len(t, c.Commit, 40)
+		timelineVersions = append(timelineVersions, c.Version)
+	}
+	assert.Equal(t, []string{"0.9.0-r0", "0.9.0-r1", "1.0.0-r0"}, timelineVersions)
+
+	versions, err = h.Versions(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
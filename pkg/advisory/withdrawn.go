@@ -0,0 +1,182 @@
+package advisory
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	"chainguard.dev/melange/pkg/config"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+)
+
+// WithdrawnPackageNote is the note of the fix-not-planned events that resolve
+// the open advisories of withdrawn packages.
+const WithdrawnPackageNote = "This package is no longer provided by the distro, so it won't receive a fix."
+
+// WithdrawnOptions configures the FindWithdrawnPackageResolutions operation.
+type WithdrawnOptions struct {
+	// AdvisoryDocs is the Index of advisory documents on which to operate.
+	AdvisoryDocs *configs.Index[v2.Document]
+
+	// Packages are the names of the withdrawn packages. If empty, the withdrawn
+	// packages are detected using PackageConfigurations and APKIndex.
+	Packages []string
+
+	// PackageConfigurations is the index of the distro's package build
+	// configurations.
+	PackageConfigurations *configs.Index[config.Configuration]
+
+	// APKIndex is the index of the distro's published APK packages.
+	APKIndex *apk.APKIndex
+}
+
+// WithdrawnPackageResolution is a fix-not-planned event that can be added to an
+// open advisory, because the advisory's package has been withdrawn from the
+// distro.
+type WithdrawnPackageResolution struct {
+	// Package is the name of the package the advisory is for.
+	Package string
+
+	// Advisory is the advisory to resolve.
+	Advisory v2.Advisory
+}
+
+// Request returns the advisory request that adds the fix-not-planned event to
+// the advisory.
+func (r WithdrawnPackageResolution) Request(timestamp v2.Timestamp) Request {
+	return Request{
+		Package:    r.Package,
+		AdvisoryID: r.Advisory.ID,
+		Aliases:    r.Advisory.Aliases,
+		Event: v2.Event{
+			Timestamp: timestamp,
+			Type:      v2.EventTypeFixNotPlanned,
+			Data: v2.FixNotPlanned{
+				Note: WithdrawnPackageNote,
+			},
+		},
+	}
+}
+
+// FindWithdrawnPackageResolutions finds the open advisories of withdrawn
+// packages, and returns the fix-not-planned event to add to each, since a
+// package that's no longer provided won't be fixed.
+//
+// An advisory is open unless its latest event is terminal, i.e. a fix, a false
+// positive determination, or a fix-not-planned event. If opts.Packages is
+// empty, a package is considered withdrawn when it has neither a build
+// configuration in the distro nor an entry in the APKINDEX.
+func FindWithdrawnPackageResolutions(ctx context.Context, opts WithdrawnOptions) ([]WithdrawnPackageResolution, error) {
+	if opts.AdvisoryDocs == nil {
+		return nil, errors.New("advisory documents must be provided")
+	}
+	if len(opts.Packages) == 0 && (opts.PackageConfigurations == nil || opts.APKIndex == nil) {
+		return nil, errors.New("package build configurations and an APKINDEX must be provided to detect withdrawn packages")
+	}
+
+	log := clog.FromContext(ctx)
+
+	withdrawn := make(map[string]struct{})
+	if len(opts.Packages) > 0 {
+		for _, pkg := range opts.Packages {
+			withdrawn[pkg] = struct{}{}
+		}
+	} else {
+		provided := providedPackages(opts.PackageConfigurations, opts.APKIndex)
+		for _, doc := range opts.AdvisoryDocs.Select().Configurations() {
+			if _, ok := provided[doc.Package.Name]; !ok {
+				log.Debug("detected withdrawn package", "package", doc.Package.Name)
+				withdrawn[doc.Package.Name] = struct{}{}
+			}
+		}
+	}
+
+	var resolutions []WithdrawnPackageResolution
+	for _, doc := range opts.AdvisoryDocs.Select().Configurations() {
+		pkg := doc.Package.Name
+		if _, ok := withdrawn[pkg]; !ok {
+			continue
+		}
+
+		for _, adv := range doc.Advisories {
+			if !isOpenAdvisory(adv) {
+				continue
+			}
+
+			resolutions = append(resolutions, WithdrawnPackageResolution{
+				Package:  pkg,
+				Advisory: adv,
+			})
+		}
+	}
+
+	sort.Slice(resolutions, func(i, j int) bool {
+		if resolutions[i].Package != resolutions[j].Package {
+			return resolutions[i].Package < resolutions[j].Package
+		}
+		return resolutions[i].Advisory.ID < resolutions[j].Advisory.ID
+	})
+
+	return resolutions, nil
+}
+
+// IsWithdrawnPackageEvent returns true if the event is one that resolves an
+// advisory because its package was withdrawn (see
+// FindWithdrawnPackageResolutions).
+func IsWithdrawnPackageEvent(e v2.Event) bool {
+	if e.Type != v2.EventTypeFixNotPlanned {
+		return false
+	}
+	d, ok := e.Data.(v2.FixNotPlanned)
+	return ok && d.Note == WithdrawnPackageNote
+}
+
+// isWithdrawnPackageResolution returns true if the only changes to the document
+// are withdrawn package events added to its existing advisories.
+func isWithdrawnPackageResolution(diff DocumentDiffResult) bool {
+	if len(diff.Added) > 0 || len(diff.Removed) > 0 || len(diff.Modified) == 0 {
+		return false
+	}
+
+	for _, adv := range diff.Modified {
+		if len(adv.RemovedEvents) > 0 || len(adv.AddedEvents) == 0 {
+			return false
+		}
+		for _, e := range adv.AddedEvents {
+			if !IsWithdrawnPackageEvent(e) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isTerminalEvent returns true if the event concludes the advisory, such that
+// no further events are expected.
+func isTerminalEvent(e v2.Event) bool {
+	switch e.Type {
+	case v2.EventTypeFixed, v2.EventTypeFalsePositiveDetermination, v2.EventTypeFixNotPlanned:
+		return true
+	}
+	return false
+}
+
+// providedPackages returns the set of package names that have a build
+// configuration in the distro, or an entry (by name or origin) in the APKINDEX.
+func providedPackages(cfgs *configs.Index[config.Configuration], apkindex *apk.APKIndex) map[string]struct{} {
+	provided := make(map[string]struct{})
+	for _, cfg := range cfgs.Select().Configurations() {
+		provided[cfg.Package.Name] = struct{}{}
+	}
+	for _, pkg := range apkindex.Packages {
+		provided[pkg.Name] = struct{}{}
+		if pkg.Origin != "" {
+			provided[pkg.Origin] = struct{}{}
+		}
+	}
+	return provided
+}
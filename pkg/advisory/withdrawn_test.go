@@ -0,0 +1,75 @@
+package advisory
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os/memfs"
+)
+
+func TestFindWithdrawnPackageResolutions(t *testing.T) {
+	advisoryDocs, err := adv2.NewIndex(context.Background(), memfs.New(os.DirFS("testdata/withdrawn")))
+	require.NoError(t, err)
+
+	ids := func(resolutions []WithdrawnPackageResolution) []string {
+		var ids []string
+		for _, r := range resolutions {
+			ids = append(ids, r.Package+"/"+r.Advisory.ID)
+		}
+		return ids
+	}
+
+	t.Run("detected", func(t *testing.T) {
+		resolutions, err := FindWithdrawnPackageResolutions(context.Background(), WithdrawnOptions{
+			AdvisoryDocs:          advisoryDocs,
+			PackageConfigurations: distroWithKo(t),
+			APKIndex: &apk.APKIndex{
+				Packages: []*apk.Package{{Name: "gone-doc", Origin: "ko"}},
+			},
+		})
+		require.NoError(t, err)
+
+		// The fixed advisory is already resolved.
+		assert.Equal(t, []string{"gone/CGA-3333-3333-3333", "gone/CGA-5555-5555-5555"}, ids(resolutions))
+
+		req := resolutions[0].Request(v2.Timestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		require.NoError(t, req.Validate())
+		assert.Equal(t, "CGA-3333-3333-3333", req.AdvisoryID)
+		assert.True(t, IsWithdrawnPackageEvent(req.Event))
+	})
+
+	t.Run("still in APKINDEX", func(t *testing.T) {
+		resolutions, err := FindWithdrawnPackageResolutions(context.Background(), WithdrawnOptions{
+			AdvisoryDocs:          advisoryDocs,
+			PackageConfigurations: distroWithKo(t),
+			APKIndex: &apk.APKIndex{
+				Packages: []*apk.Package{{Name: "gone", Origin: "gone"}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resolutions)
+	})
+
+	t.Run("given packages", func(t *testing.T) {
+		resolutions, err := FindWithdrawnPackageResolutions(context.Background(), WithdrawnOptions{
+			AdvisoryDocs: advisoryDocs,
+			Packages:     []string{"ko"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ko/CGA-2222-2222-2222"}, ids(resolutions))
+	})
+
+	t.Run("detection needs distro data", func(t *testing.T) {
+		_, err := FindWithdrawnPackageResolutions(context.Background(), WithdrawnOptions{
+			AdvisoryDocs: advisoryDocs,
+		})
+		assert.Error(t, err)
+	})
+}
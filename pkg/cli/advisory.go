@@ -3,19 +3,23 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"chainguard.dev/apko/pkg/apk/apk"
 	"chainguard.dev/melange/pkg/config"
 	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"github.com/wolfi-dev/wolfictl/pkg/advisory"
 	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
 	"github.com/wolfi-dev/wolfictl/pkg/configs"
 	"github.com/wolfi-dev/wolfictl/pkg/distro"
+	wgit "github.com/wolfi-dev/wolfictl/pkg/git"
 	"github.com/wolfi-dev/wolfictl/pkg/versions"
 )
 
@@ -37,20 +41,48 @@ func cmdAdvisory() *cobra.Command {
 
 	cmd.AddCommand(
 		cmdAdvisoryAlias(),
+		cmdAdvisoryArchive(),
+		cmdAdvisoryAutoResolve(),
+		cmdAdvisoryBulkEdit(),
+		cmdAdvisoryCarry(),
+		cmdAdvisoryChangelog(),
 		cmdAdvisoryCopy(),
 		cmdAdvisoryCreate(),
+		cmdAdvisoryCreateFromScan(),
+		cmdAdvisoryCrossCheck(),
+		cmdAdvisoryDedupe(),
 		cmdAdvisoryDiff(),
 		cmdAdvisoryDiscover(),
 		cmdAdvisoryExport(),
+		cmdAdvisoryFindFix(),
+		cmdAdvisoryGuard(),
 		cmdAdvisoryGuide(),
 		cmdAdvisoryID(),
+		cmdAdvisoryImportCSAF(),
+		cmdAdvisoryImportCSV(),
+		cmdAdvisoryImportOpenVEX(),
+		cmdAdvisoryImportOSV(),
+		cmdAdvisoryImportSecDB(),
+		cmdAdvisoryLint(),
 		cmdAdvisoryList(),
+		cmdAdvisoryMerge(),
 		cmdAdvisoryMigrateIDs(),
+		cmdAdvisoryMigrateSchema(),
 		cmdAdvisoryOSV(),
+		cmdAdvisoryOSVPublish(),
 		cmdAdvisoryRebase(),
+		cmdAdvisoryResolveWithdrawn(),
+		cmdAdvisorySearch(),
 		cmdAdvisorySecDB(),
+		cmdAdvisoryServe(),
+		cmdAdvisoryShow(),
+		cmdAdvisorySLA(),
+		cmdAdvisoryStats(),
+		cmdAdvisorySyncCVSS(),
+		cmdAdvisoryTriage(),
 		cmdAdvisoryUpdate(),
 		cmdAdvisoryValidate(),
+		cmdAdvisoryVerify(),
 	)
 
 	return cmd
@@ -95,6 +127,30 @@ func addFlagsForAdvisoryRequestParams(p *advisory.RequestParams, cmd *cobra.Comm
 	cmd.Flags().StringVar(&p.FixedVersion, "fixed-version", "", "package version where fix was applied (used only for 'fixed' event type)")
 }
 
+func addFalsePositiveTemplateFlag(val *string, cmd *cobra.Command) {
+	cmd.Flags().StringVar(val, "fp-template", "", fmt.Sprintf("name of a false positive template from the advisories repo's %s file, used for the false positive type and note that aren't specified otherwise", advisory.FalsePositiveTemplatesFileName))
+}
+
+// applyFalsePositiveTemplate fills in the request params using the named false
+// positive template of the advisories repo, if a template name was given.
+func applyFalsePositiveTemplate(name, advisoriesRepoDir string, p *advisory.RequestParams) error {
+	if name == "" {
+		return nil
+	}
+
+	templates, err := advisory.ReadFalsePositiveTemplates(os.DirFS(advisoriesRepoDir))
+	if err != nil {
+		return err
+	}
+
+	template, err := templates.Get(name)
+	if err != nil {
+		return err
+	}
+
+	return template.ApplyTo(p)
+}
+
 const (
 	flagNamePackage           = "package"
 	flagNameVuln              = "vuln"
@@ -129,6 +185,57 @@ func addAdvisoriesDirFlag(val *string, cmd *cobra.Command) {
 	cmd.Flags().StringVarP(val, flagNameAdvisoriesRepoDir, "a", "", "directory containing the advisories repository")
 }
 
+func addAdvisoriesDirsFlag(val *[]string, cmd *cobra.Command) {
+	cmd.Flags().StringSliceVarP(val, flagNameAdvisoriesRepoDir, "a", nil, "directory containing an advisories repository (can be repeated to combine the advisories of multiple repositories)")
+}
+
+func addAsOfFlag(val *string, cmd *cobra.Command) {
+	cmd.Flags().StringVar(val, flagNameAsOf, "", "use the advisory data as of this git ref of the advisories repository, or as of this time (RFC 3339 timestamp, or YYYY-MM-DD date for the end of that day in UTC), instead of the working tree")
+}
+
+const flagNameAsOf = "as-of"
+
+// advisoriesFSAsOf returns the files of the advisories repo at dir as of asOf,
+// which is either a git ref or a time (see addAsOfFlag), read from the repo's
+// git history without checking it out. If asOf is empty, the files of the
+// working tree are returned.
+func advisoriesFSAsOf(ctx context.Context, dir, asOf string) (fs.FS, error) {
+	if asOf == "" {
+		return os.DirFS(dir), nil
+	}
+
+	rev := asOf
+	if t, err := parseAsOfTime(asOf); err == nil {
+		hash, err := wgit.RevisionAsOf(dir, "HEAD", t)
+		if err != nil {
+			return nil, fmt.Errorf("finding the state of the advisories repo as of %s: %w", asOf, err)
+		}
+		rev = hash
+	}
+
+	fsys, hash, err := wgit.RevisionFS(dir, rev)
+	if err != nil {
+		return nil, fmt.Errorf("reading the advisories repo as of %q: %w", asOf, err)
+	}
+
+	clog.FromContext(ctx).Info("using advisory data as of commit", "dir", dir, "asOf", asOf, "commit", hash)
+	return fsys, nil
+}
+
+// parseAsOfTime parses the time given to --as-of. A date means the end of that
+// day in UTC, so that the day's changes are included.
+func parseAsOfTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
 func addNoPromptFlag(val *bool, cmd *cobra.Command) {
 	cmd.Flags().BoolVar(val, flagNameNoPrompt, false, "do not prompt the user for input")
 }
@@ -13,6 +13,7 @@ func cmdAdvisoryAlias() *cobra.Command {
 
 	cmd.AddCommand(
 		cmdAdvisoryAliasDiscover(),
+		cmdAdvisoryAliasFill(),
 		cmdAdvisoryAliasFind(),
 	)
 
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryAliasFill() *cobra.Command {
+	p := &aliasFillParams{}
+	cmd := &cobra.Command{
+		Use:        "fill",
+		Short:      "Fill in missing CVE and GHSA aliases across the advisory data",
+		Deprecated: advisoryDeprecationMessage,
+		Long: `Fill in missing CVE and GHSA aliases across the advisory data.
+
+For each advisory with at least one CVE or GHSA alias, this command resolves
+the complete set of aliases using the GitHub Advisory Database (i.e. the CVE of
+each GHSA, and the GHSAs of each CVE, repeatedly), and adds any that are
+missing to the advisory.
+
+An alias that's already used by another advisory for the same package isn't
+added, and is reported as a conflict instead. Conflicts need to be resolved
+manually, typically by merging the advisories.
+
+Resolved aliases are cached locally (see --cache-path) and reused for the time
+given by --cache-max-age, so repeated runs across the entire data set don't
+hit the GitHub API for every alias again. As with "wolfictl adv alias
+discover", setting GITHUB_TOKEN to authenticate the API calls is highly
+recommended.
+
+Use --dry-run to see which aliases are missing without changing anything.`,
+		Example: `
+wolfictl adv alias fill --dry-run
+
+wolfictl adv alias fill -p ko -p crane`,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := adv2.NewIndex(ctx, rwos.DirFS(advisoriesRepoDir))
+			if err != nil {
+				return err
+			}
+
+			var af advisory.AliasFinder = advisory.NewHTTPAliasFinder(http.DefaultClient)
+			if !p.noCache {
+				cachePath := p.cachePath
+				if cachePath == "" {
+					cachePath = advisory.DefaultAliasCachePath
+				}
+
+				caf, err := advisory.NewCachingAliasFinder(af, cachePath, p.cacheMaxAge)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					if err := caf.Save(); err != nil {
+						clog.FromContext(ctx).Warnf("unable to save alias cache: %v", err)
+					}
+				}()
+				af = caf
+			}
+
+			selectedPackageSet := make(map[string]struct{})
+			for _, pkg := range p.packages {
+				selectedPackageSet[pkg] = struct{}{}
+			}
+
+			fills, err := advisory.FillAliases(ctx, advisory.FillAliasesOptions{
+				AdvisoryDocs:     advisoryDocs,
+				AliasFinder:      af,
+				SelectedPackages: selectedPackageSet,
+				DryRun:           p.dryRun,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(fills) == 0 {
+				fmt.Fprintln(os.Stderr, "No missing aliases found.")
+				return nil
+			}
+
+			added, conflicts := 0, 0
+			for _, f := range fills {
+				if len(f.Added) > 0 {
+					fmt.Printf(
+						"%s: %s + %s\n",
+						styles.Bold().Render(f.Package),
+						styles.Bold().Render(f.AdvisoryID),
+						strings.Join(f.Added, ", "),
+					)
+					added += len(f.Added)
+				}
+				if len(f.Conflicts) > 0 {
+					fmt.Printf(
+						"%s: %s ⚠️  already used by another advisory: %s\n",
+						styles.Bold().Render(f.Package),
+						styles.Bold().Render(f.AdvisoryID),
+						strings.Join(f.Conflicts, ", "),
+					)
+					conflicts += len(f.Conflicts)
+				}
+			}
+
+			verb := "added"
+			if p.dryRun {
+				verb = "would be added (dry run)"
+			}
+			fmt.Fprintf(os.Stderr, "\n%d aliases %s, %d conflicts.\n", added, verb, conflicts)
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type aliasFillParams struct {
+	advisoriesRepoDir string
+	doNotDetectDistro bool
+
+	packages []string
+	dryRun   bool
+
+	noCache     bool
+	cachePath   string
+	cacheMaxAge time.Duration
+}
+
+func (p *aliasFillParams) addFlagsTo(cmd *cobra.Command) {
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+
+	cmd.Flags().StringSliceVarP(&p.packages, flagNamePackage, "p", nil, "packages to operate on")
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "print the missing aliases without adding them")
+
+	cmd.Flags().BoolVar(&p.noCache, "no-cache", false, "don't read or write the local cache of resolved aliases")
+	cmd.Flags().StringVar(&p.cachePath, "cache-path", "", "path to the local cache of resolved aliases (defaults to aliases/ghsa.json in wolfictl's cache directory)")
+	cmd.Flags().DurationVar(&p.cacheMaxAge, "cache-max-age", advisory.DefaultAliasCacheMaxAge, "how long resolved aliases are reused before being resolved again")
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryArchive() *cobra.Command {
+	p := &archiveParams{}
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Move long-concluded advisories out of the working set into the archive",
+		Long: fmt.Sprintf(`Move long-concluded advisories out of the working set into the archive.
+
+Advisories whose latest event concludes them (fixed, false-positive-determination,
+or fix-not-planned) and is older than the horizon (--horizon, in days) are moved
+from the advisory documents at the root of the advisories repo to the documents
+in its %q directory, which have the same format. A package's document is
+removed once all of its advisories are archived.
+
+Commands that modify advisory data, such as "auto-resolve" and "dedupe", only
+operate on the advisory documents at the root of the repo, so archiving keeps
+them fast and the documents small. Commands that read advisory data, such as
+"list", "show", "search", "validate", "export", and "osv", and the advisory
+filtering of "wolfictl scan", include the archived advisories, so their results
+don't change.
+
+Adding an event to an archived advisory (with "create" or "update") fails. If a
+vulnerability regresses, move its advisory back to the package's document at
+the root of the repo before updating it.`, advisory.ArchiveDirName),
+		Example: `
+wolfictl adv archive --dry-run
+
+wolfictl adv archive --horizon 730 -p ko`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if p.horizonDays <= 0 {
+				return fmt.Errorf("invalid horizon: must be a positive number of days")
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := adv2.NewIndexForPackages(ctx, rwos.DirFS(advisoriesRepoDir), p.packages...)
+			if err != nil {
+				return fmt.Errorf("unable to create index of advisories repo: %w", err)
+			}
+
+			selectedPackages := make(map[string]struct{})
+			for _, pkg := range p.packages {
+				selectedPackages[pkg] = struct{}{}
+			}
+
+			groups, err := advisory.FindArchivable(advisory.ArchiveOptions{
+				AdvisoryDocs:     advisoryDocs,
+				SelectedPackages: selectedPackages,
+				Horizon:          time.Now().AddDate(0, 0, -p.horizonDays),
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(groups) == 0 {
+				fmt.Fprintln(os.Stderr, "No advisories to archive.")
+				return nil
+			}
+
+			count := renderArchiveGroups(os.Stdout, groups)
+
+			if p.dryRun {
+				fmt.Fprintf(os.Stderr, "\n%d advisories would be archived (dry run).\n", count)
+				return nil
+			}
+
+			archiveDir := filepath.Join(advisoriesRepoDir, advisory.ArchiveDirName)
+			if err := os.MkdirAll(archiveDir, 0o755); err != nil {
+				return fmt.Errorf("creating archive directory: %w", err)
+			}
+			archivedDocs, err := adv2.NewIndex(ctx, rwos.DirFS(archiveDir))
+			if err != nil {
+				return fmt.Errorf("unable to create index of archived advisories: %w", err)
+			}
+
+			emptied, err := advisory.ArchiveAdvisories(ctx, advisoryDocs, archivedDocs, groups)
+			if err != nil {
+				return err
+			}
+			for _, path := range emptied {
+				if err := os.Remove(filepath.Join(advisoriesRepoDir, path)); err != nil {
+					return fmt.Errorf("removing archived advisory document: %w", err)
+				}
+			}
+
+			fmt.Fprintf(os.Stderr, "\n%d advisories archived.\n", count)
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type archiveParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	packages          []string
+	horizonDays       int
+	dryRun            bool
+}
+
+func (p *archiveParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addMultiPackageFlag(&p.packages, cmd)
+	cmd.Flags().IntVar(&p.horizonDays, "horizon", 365, "number of days since an advisory was concluded after which it's archived")
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "print the advisories that would be archived without archiving them")
+}
+
+// renderArchiveGroups prints the advisories to archive, grouped by package, and
+// returns their number.
+func renderArchiveGroups(w io.Writer, groups []advisory.ArchiveGroup) int {
+	count := 0
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, styles.Bold().Render(g.Package))
+		for _, adv := range g.Advisories {
+			latest := adv.Latest()
+			fmt.Fprintf(w, "  %s  %s  %s\n", adv.ID, latest.Type, latest.Timestamp)
+		}
+		count += len(g.Advisories)
+	}
+	return count
+}
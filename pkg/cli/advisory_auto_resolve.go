@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"chainguard.dev/apko/pkg/apk/client"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryAutoResolve() *cobra.Command {
+	p := &autoResolveParams{}
+	cmd := &cobra.Command{
+		Use:   "auto-resolve",
+		Short: "Add fixed events to open advisories whose vulnerable version has been superseded",
+		Long: `Add fixed events to open advisories whose vulnerable version has been superseded.
+
+An advisory is open when its latest event is a detection or a pending upstream
+fix. The package's version at the time of that event is found using the Git
+history of the package's build configuration in the distro repo. If a build of
+the package with a newer upstream version (not just a newer epoch) has since
+been published to the APKINDEX, a fixed event with the version of the first
+such build is added to the advisory.
+
+Detections of components other than the package itself (e.g. a Go module found
+by a scan) are skipped, since updating the package doesn't necessarily update
+the component.
+
+Use --dry-run to see which advisories would be resolved without changing
+anything.`,
+		Example: `
+wolfictl adv auto-resolve --dry-run
+
+wolfictl adv auto-resolve -p ko -p crane`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			packageRepositoryURL := p.packageRepositoryURL
+
+			distroRepoDir := resolveDistroDir(p.distroRepoDir)
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if distroRepoDir == "" || advisoriesRepoDir == "" || packageRepositoryURL == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("distro repo dir, advisories repo dir, and/or package repo URL was left unspecified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("distro repo dir, advisories repo dir, and/or package repo URL was left unspecified, and distro auto-detection failed: %w", err)
+				}
+
+				if distroRepoDir == "" {
+					distroRepoDir = d.Local.PackagesRepo.Dir
+				}
+				if advisoriesRepoDir == "" {
+					advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				}
+				if packageRepositoryURL == "" {
+					packageRepositoryURL = d.Absolute.APKRepositoryURL
+				}
+
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := adv2.NewIndexForPackages(ctx, rwos.DirFS(advisoriesRepoDir), p.packages...)
+			if err != nil {
+				return fmt.Errorf("unable to create index of advisories repo: %w", err)
+			}
+
+			c := client.New(http.DefaultClient)
+			apkIndex, err := c.GetRemoteIndex(ctx, packageRepositoryURL, p.arch)
+			if err != nil {
+				return fmt.Errorf("unable to load APKINDEX: %w", err)
+			}
+
+			timeline, err := advisory.NewGitPackageVersionHistory(distroRepoDir)
+			if err != nil {
+				return fmt.Errorf("unable to read package version history from distro repo: %w", err)
+			}
+
+			selectedPackages := make(map[string]struct{})
+			for _, pkg := range p.packages {
+				selectedPackages[pkg] = struct{}{}
+			}
+
+			resolutions, err := advisory.FindAutoResolutions(ctx, advisory.AutoResolveOptions{
+				AdvisoryDocs:           advisoryDocs,
+				SelectedPackages:       selectedPackages,
+				APKIndex:               apkIndex,
+				PackageVersionTimeline: timeline,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(resolutions) == 0 {
+				fmt.Fprintln(os.Stderr, "No open advisories have a superseded vulnerable version.")
+				return nil
+			}
+
+			for _, r := range resolutions {
+				if !p.dryRun {
+					err := advisory.Update(ctx, r.Request(v2.Now()), advisory.UpdateOptions{
+						AdvisoryDocs: advisoryDocs,
+					})
+					if err != nil {
+						return fmt.Errorf("resolving advisory %s for %s: %w", r.Advisory.ID, r.Package, err)
+					}
+				}
+
+				fmt.Printf(
+					"%s: %s fixed in %s (was %s)\n",
+					styles.Bold().Render(r.Package),
+					styles.Bold().Render(r.Advisory.ID),
+					r.FixedVersion,
+					r.VulnerableVersion,
+				)
+			}
+
+			if p.dryRun {
+				fmt.Fprintf(os.Stderr, "\n%d advisories would be resolved (dry run).\n", len(resolutions))
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type autoResolveParams struct {
+	doNotDetectDistro    bool
+	distroRepoDir        string
+	advisoriesRepoDir    string
+	packageRepositoryURL string
+	arch                 string
+	packages             []string
+	dryRun               bool
+}
+
+func (p *autoResolveParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addDistroDirFlag(&p.distroRepoDir, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addPackageRepoURLFlag(&p.packageRepositoryURL, cmd)
+	addMultiPackageFlag(&p.packages, cmd)
+	cmd.Flags().StringVar(&p.arch, "arch", "x86_64", "architecture of the APKINDEX to check for published versions")
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "print the advisories that would be resolved without changing them")
+}
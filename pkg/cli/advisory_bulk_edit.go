@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryBulkEdit() *cobra.Command {
+	p := &bulkEditParams{}
+	cmd := &cobra.Command{
+		Use:   "bulk-edit",
+		Short: "Apply a declarative patch to many advisories at once",
+		Long: `Apply a declarative patch to many advisories at once.
+
+A patch is a YAML file with a list of edits. Each edit has a "match" query
+expression (see "wolfictl adv search --help") that selects the advisories to
+edit, and an event to "append" to each of them, in the same format as events
+in advisory documents. If the event has no timestamp, the current time is used.
+For example:
+
+  edits:
+    - match: vuln=CVE-2024-1234 AND (package=a OR package=b OR package=c)
+      append:
+        type: false-positive-determination
+        data:
+          type: vulnerable-code-not-included-in-package
+          note: The vulnerable code was removed upstream before the first release.
+
+Edits are applied in order, so an edit's query sees the events appended by the
+edits before it.
+
+The patch is applied as a single transaction: all edits are made in memory
+first, and the changed advisory documents are only written if every edit
+succeeds and every changed document is still valid.
+
+Use --dry-run to print a diff of the changes without writing them.`,
+		Example: `
+wolfictl adv bulk-edit -f patch.yaml --dry-run
+
+wolfictl adv bulk-edit -f patch.yaml`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if p.patchFile == "" {
+				return fmt.Errorf("a patch file (--file) is required")
+			}
+
+			var r io.Reader = os.Stdin
+			if p.patchFile != "-" {
+				f, err := os.Open(p.patchFile)
+				if err != nil {
+					return fmt.Errorf("unable to open patch file: %w", err)
+				}
+				defer f.Close()
+				r = f
+			}
+
+			patch, err := advisory.ParseBulkEditPatch(r)
+			if err != nil {
+				return fmt.Errorf("invalid patch: %w", err)
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			cvss, err := advisory.ReadCVSSData(os.DirFS(advisoriesRepoDir))
+			if err != nil {
+				return err
+			}
+
+			plan, err := advisory.PlanBulkEdit(ctx, os.DirFS(advisoriesRepoDir), advisory.BulkEditOptions{
+				Patch: patch,
+				CVSS:  cvss,
+				Now:   v2.Now(),
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(plan.Changes) == 0 {
+				fmt.Fprintln(os.Stderr, "No advisories matched the patch.")
+				return nil
+			}
+
+			if p.dryRun {
+				diff, err := plan.Diff()
+				if err != nil {
+					return err
+				}
+				fmt.Print(diff)
+				fmt.Fprintf(os.Stderr, "\n%d advisories in %d files would be changed (dry run).\n", len(plan.Changes), len(plan.Files))
+				return nil
+			}
+
+			if err := plan.Apply(advisoriesRepoDir); err != nil {
+				return err
+			}
+
+			for _, c := range plan.Changes {
+				fmt.Printf(
+					"%s: %s += %s\n",
+					styles.Bold().Render(c.Package),
+					styles.Bold().Render(c.AdvisoryID),
+					c.Event.Type,
+				)
+			}
+			fmt.Fprintf(os.Stderr, "\n%d advisories in %d files changed.\n", len(plan.Changes), len(plan.Files))
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type bulkEditParams struct {
+	advisoriesRepoDir string
+	doNotDetectDistro bool
+
+	patchFile string
+	dryRun    bool
+}
+
+func (p *bulkEditParams) addFlagsTo(cmd *cobra.Command) {
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+
+	cmd.Flags().StringVarP(&p.patchFile, "file", "f", "", "path to the patch file (\"-\" for stdin)")
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "print a diff of the changes without writing them")
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryCarry() *cobra.Command {
+	p := &carryParams{}
+	cmd := &cobra.Command{
+		Use:   "carry OLD-PACKAGE NEW-PACKAGE [NEW-PACKAGE...]",
+		Short: "Carry a package's advisories over to its new name, or to the packages it was split into",
+		Long: `Carry a package's advisories over to its new name, or to the packages it was split into.
+
+When a package is renamed, or split into several packages (e.g. subpackages
+that become packages of their own), its advisories would otherwise need to be
+recreated for the new package names, losing their history. This command
+carries the advisories of OLD-PACKAGE over to each NEW-PACKAGE instead.
+
+Each carried advisory gets a new advisory ID, since advisory IDs are unique per
+package and vulnerability, and keeps its aliases and all of its events. To
+record where the advisory came from, a copy of its latest event is added, with
+a note naming the original advisory and package. This doesn't change the
+advisory's status. Events that can't have a note, like "fixed" events, aren't
+copied.
+
+The advisory documents of the new packages are created if they don't exist
+yet. Archived advisories of OLD-PACKAGE are carried over to the archived
+advisory documents of each NEW-PACKAGE. Advisories for vulnerabilities that a
+new package already has an advisory for, archived or not, are skipped.
+
+The advisories of OLD-PACKAGE are left as they are, since they still apply to
+the versions of the package published under the old name.`,
+		Example: `
+# After renaming "py3-foo" to "py3.12-foo"
+wolfictl adv carry py3-foo py3.12-foo
+
+# After splitting "libfoo" into "libfoo-libs" and "libfoo-utils"
+wolfictl adv carry libfoo libfoo-libs libfoo-utils`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			from := strings.TrimSuffix(args[0], ".advisories.yaml")
+			to := make([]string, 0, len(args)-1)
+			for _, arg := range args[1:] {
+				to = append(to, strings.TrimSuffix(arg, ".advisories.yaml"))
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := adv2.NewIndex(ctx, rwos.DirFS(advisoriesRepoDir))
+			if err != nil {
+				return err
+			}
+
+			var archivedDocs *configs.Index[v2.Document]
+			archiveDir := filepath.Join(advisoriesRepoDir, advisory.ArchiveDirName)
+			if _, err := os.Stat(archiveDir); err == nil {
+				archivedDocs, err = adv2.NewIndex(ctx, rwos.DirFS(archiveDir))
+				if err != nil {
+					return fmt.Errorf("unable to create index of archived advisories: %w", err)
+				}
+			}
+
+			result, err := advisory.Carry(ctx, advisory.CarryOptions{
+				AdvisoryDocs: advisoryDocs,
+				ArchivedDocs: archivedDocs,
+				From:         from,
+				To:           to,
+				Now:          v2.Now(),
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, c := range result.Carried {
+				fmt.Printf(
+					"%s: %s (%s, carried over from %s)\n",
+					styles.Bold().Render(c.Package),
+					styles.Bold().Render(c.ID),
+					strings.Join(c.Aliases, ", "),
+					c.SourceID,
+				)
+			}
+			for _, c := range result.Skipped {
+				fmt.Fprintf(
+					os.Stderr,
+					"Skipped %s (%s): %s already has an advisory for it.\n",
+					c.SourceID,
+					strings.Join(c.Aliases, ", "),
+					c.Package,
+				)
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type carryParams struct {
+	advisoriesRepoDir string
+	doNotDetectDistro bool
+}
+
+func (p *carryParams) addFlagsTo(cmd *cobra.Command) {
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+}
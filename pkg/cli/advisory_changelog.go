@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryChangelog() *cobra.Command {
+	p := &changelogParams{}
+	cmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Write a Markdown digest of the advisory changes between two points in time",
+		Long: `Write a Markdown digest of the advisory changes between two points in time.
+
+The changelog compares the advisory data at --from to the advisory data at --to
+(by default, the working tree). Each of them is a git ref of the advisories repo
+(such as a branch, tag, or commit hash), or a time (an RFC 3339 timestamp, or a
+YYYY-MM-DD date for the end of that day in UTC), which means the state of the
+repo's HEAD at that time.
+
+Each advisory that gained events is listed in one section of the changelog,
+according to the latest of those events:
+
+  Fixes               fixed
+  False positives     false-positive-determination
+  Fixes not planned   fix-not-planned
+  Investigations      any other event, e.g. a detection or a true positive
+                      determination
+
+Changes that don't add events, like new aliases, aren't included. The output
+is suitable for security bulletins, e.g. a weekly one:
+
+  wolfictl adv changelog --from "$(date -d '7 days ago' +%F)" > bulletin.md`,
+		Example: `
+wolfictl adv changelog --from 2024-05-01 --to 2024-05-08
+
+wolfictl adv changelog -a ../advisories --from v1.2.0 --to v1.3.0 --title "Changes in v1.3.0"`,
+		Deprecated:    advisoryDeprecationMessage,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if p.from == "" {
+				return fmt.Errorf("--%s is required", flagNameDiffFrom)
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			fromIndex, err := advisoriesIndexAsOf(ctx, advisoriesRepoDir, p.from)
+			if err != nil {
+				return err
+			}
+			toIndex, err := advisoriesIndexAsOf(ctx, advisoriesRepoDir, p.to)
+			if err != nil {
+				return err
+			}
+
+			title := p.title
+			if title == "" {
+				to := p.to
+				if to == "" {
+					to = "now"
+				}
+				title = fmt.Sprintf("Security changelog: %s to %s", p.from, to)
+			}
+
+			changelog := advisory.NewChangelog(advisory.IndexDiff(fromIndex, toIndex))
+			return changelog.WriteMarkdown(os.Stdout, title)
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type changelogParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	from, to          string
+	title             string
+}
+
+func (p *changelogParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().StringVar(&p.from, flagNameDiffFrom, "", "git ref or time of the advisory data to compare from")
+	cmd.Flags().StringVar(&p.to, flagNameDiffTo, "", "git ref or time of the advisory data to compare to (default: the working tree)")
+	cmd.Flags().StringVar(&p.title, "title", "", "title of the changelog (default: describes --from and --to)")
+}
+
+// advisoriesIndexAsOf returns an index of the advisory documents of the
+// advisories repo at dir as of asOf (see advisoriesFSAsOf), including its
+// archived advisories.
+func advisoriesIndexAsOf(ctx context.Context, dir, asOf string) (*configs.Index[v2.Document], error) {
+	fsys, err := advisoriesFSAsOf(ctx, dir, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := advisory.IndexWithArchive(ctx, fsys)
+	if err != nil {
+		return nil, fmt.Errorf("unable to index advisory configs for directory %q as of %q: %w", dir, asOf, err)
+	}
+
+	return index, nil
+}
@@ -53,7 +53,18 @@ advisory will be skipped, and a warning will be logged. This is to prevent
 adding redundant events to advisories that already have the same type of event.
 
 This command also performs a follow-up operation to discover aliases for the
-newly created advisory and any other advisories for the same package.`,
+newly created advisory and any other advisories for the same package.
+
+Commonly used false positive determinations can be kept as named templates in
+the advisories repo's .false-positive-templates.yaml file, e.g.:
+
+  fix-backported:
+    type: vulnerable-code-version-not-used
+    note: The fix for this vulnerability was backported to the packaged version.
+
+Use --fp-template to add a false positive determination event from a template.
+The --fp-type and --note flags, if specified, take precedence over the
+template's values.`,
 		SilenceErrors: true,
 		Args:          cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
@@ -133,6 +144,9 @@ newly created advisory and any other advisories for the same package.`,
 			}
 
 			reqParams := p.requestParams
+			if err := applyFalsePositiveTemplate(p.fpTemplate, advisoriesRepoDir, &reqParams); err != nil {
+				return err
+			}
 
 			c := client.New(http.DefaultClient)
 			var apkindexes []*apk.APKIndex
@@ -262,6 +276,7 @@ type createParams struct {
 	doNotPrompt       bool
 
 	requestParams                    advisory.RequestParams
+	fpTemplate                       string
 	distroRepoDir, advisoriesRepoDir string
 	archs                            []string
 	packageRepositoryURL             string
@@ -272,6 +287,7 @@ func (p *createParams) addFlagsTo(cmd *cobra.Command) {
 	addNoPromptFlag(&p.doNotPrompt, cmd)
 
 	addFlagsForAdvisoryRequestParams(&p.requestParams, cmd)
+	addFalsePositiveTemplateFlag(&p.fpTemplate, cmd)
 	addDistroDirFlag(&p.distroRepoDir, cmd)
 	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
 	cmd.Flags().StringSliceVar(&p.archs, "arch", []string{"x86_64", "aarch64"}, "package architectures to find published versions for")
@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory/question"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/components/ctrlcwrapper"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/components/interview"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/internal/wrapped"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+	wgit "github.com/wolfi-dev/wolfictl/pkg/git"
+	question2 "github.com/wolfi-dev/wolfictl/pkg/question"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+)
+
+//nolint:gocyclo
+func cmdAdvisoryCreateFromScan() *cobra.Command {
+	p := &createFromScanParams{}
+	cmd := &cobra.Command{
+		Use:   "create-from-scan [apk]...",
+		Short: "Interactively create advisories for the unaddressed findings of a scan",
+		Long: `Interactively create advisories for the unaddressed findings of a scan.
+
+The findings come from a "wolfictl scan" JSON results file given with
+--results, or from scanning the given APKs (anything "wolfictl scan" accepts as
+an APK input). Findings that already have a concluded advisory are skipped.
+
+For each remaining vulnerability in each origin package, you're asked the same
+questions as in "wolfictl adv guide". The advisory is pre-filled with the
+package, the vulnerability's ID and aliases, and a detection event for the
+finding. Once you've answered, the detection event (if the advisory is new) and
+your determination are written to the package's advisory document, and the
+document is committed to the advisories repo, unless --no-commit is used.
+
+Press ctrl+c at any time to stop. Advisories you've already completed are kept.`,
+		Example: `
+wolfictl scan ./packages/x86_64/foo-1.2.3-r0.apk -o json > results.json
+wolfictl adv create-from-scan --results results.json
+
+wolfictl adv create-from-scan ./packages/x86_64/foo-1.2.3-r0.apk -a ../advisories --no-commit`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if (p.resultsFile == "") == (len(args) == 0) {
+				return errors.New("either a results file (--results) or APKs to scan must be given, but not both")
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advGetter := advisory.NewFSGetter(os.DirFS(advisoriesRepoDir))
+			advPutter := advisory.NewFSPutterWithAutomaticEncoder(rwos.DirFS(advisoriesRepoDir))
+			af := advisory.NewHTTPAliasFinder(http.DefaultClient)
+
+			var results []scan.Result
+			if p.resultsFile != "" {
+				var err error
+				results, err = readScanResults(p.resultsFile)
+				if err != nil {
+					return err
+				}
+			} else {
+				sp := &scanParams{
+					localDBFilePath:  p.localDBFilePath,
+					grypeDBURL:       p.grypeDBURL,
+					outputFormat:     outputFormatJSON,
+					distro:           p.distro,
+					disableSBOMCache: p.disableSBOMCache,
+					jobs:             2,
+				}
+
+				var err error
+				results, _, err = scanEverything(ctx, sp, args, advGetter, nil)
+				if err != nil {
+					return err
+				}
+			}
+
+			items, err := unaddressedScanFindings(ctx, results, advGetter)
+			if err != nil {
+				return err
+			}
+
+			if len(items) == 0 {
+				wrapped.Println("🎉 All findings are already addressed by advisories.")
+				return nil
+			}
+
+			for i, item := range items {
+				req := scan.DetectionRequest(item.result, item.finding, v2.Now())
+
+				fmt.Printf(
+					"\n(%d/%d) %s in %s (%s %s)\n\n",
+					i+1,
+					len(items),
+					styles.Bold().Render(item.finding.Vulnerability.ID),
+					styles.Bold().Render(req.Package),
+					item.finding.Package.Name,
+					item.finding.Package.Version,
+				)
+
+				resolvedReq, err := req.ResolveAliases(ctx, af)
+				if err != nil {
+					clog.FromContext(ctx).Warnf("resolving aliases for advisory request: %v", err)
+				} else {
+					req = *resolvedReq
+				}
+				detectionReq := req
+
+				iv, err := interview.New(question.IsFalsePositive, req)
+				if err != nil {
+					return fmt.Errorf("creating interview for advisory request: %w", err)
+				}
+				ivTea, err := tea.NewProgram(ctrlcwrapper.New(iv)).Run()
+				if err != nil {
+					return fmt.Errorf("running interview for advisory request: %w", err)
+				}
+				if ivCtrlC, ok := ivTea.(ctrlcwrapper.Model[interview.Model[advisory.Request]]); ok {
+					if ivCtrlC.UserWantsToExit() {
+						return nil
+					}
+
+					iv = ivCtrlC.Unwrap()
+				}
+
+				req, err = iv.State()
+				if err != nil {
+					if errors.Is(err, question2.ErrTerminate) {
+						wrapped.Println("👀 Skipping this one for now.\n")
+						continue
+					}
+
+					return fmt.Errorf("getting data back from interview: %w", err)
+				}
+
+				advs, err := advGetter.Advisories(ctx, req.Package)
+				if err != nil {
+					return fmt.Errorf("getting advisories for package %q: %w", req.Package, err)
+				}
+
+				action := "update"
+				if advisory.MatchToRequest(advs, req) == nil {
+					action = "create"
+
+					id, err := advPutter.Upsert(ctx, detectionReq)
+					if err != nil {
+						return fmt.Errorf("recording detection for %s in %s: %w", item.finding.Vulnerability.ID, req.Package, err)
+					}
+					req.AdvisoryID = id
+				}
+
+				// Timestamps have second precision, so make sure the determination doesn't
+				// appear to have happened at the same time as the detection.
+				detectedAt := time.Time(detectionReq.Event.Timestamp)
+				if !time.Time(req.Event.Timestamp).After(detectedAt) {
+					req.Event.Timestamp = v2.Timestamp(detectedAt.Add(time.Second))
+				}
+
+				id, err := advPutter.Upsert(ctx, req)
+				if err != nil {
+					return fmt.Errorf("adding advisory data for %s in %s: %w", item.finding.Vulnerability.ID, req.Package, err)
+				}
+
+				if !p.noCommit {
+					msg := fmt.Sprintf("%s: %s advisory %s", req.Package, action, id)
+					if err := wgit.CommitFiles(advisoriesRepoDir, msg, req.Package+".advisories.yaml"); err != nil {
+						return fmt.Errorf("committing advisory document for %s: %w", req.Package, err)
+					}
+				}
+
+				wrapped.Println(fmt.Sprintf(
+					"🙌 Marked %s in %s as %s.\n",
+					styles.Bold().Render(id),
+					styles.Bold().Render(req.Package),
+					styles.Bold().Render(humanizeAdvisoryEventType(req.Event.Type)),
+				))
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type createFromScanParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	resultsFile       string
+	noCommit          bool
+
+	localDBFilePath  string
+	grypeDBURL       string
+	distro           string
+	disableSBOMCache bool
+}
+
+func (p *createFromScanParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().StringVar(&p.resultsFile, "results", "", "path to a scan results file in JSON format (\"-\" for stdin), instead of scanning APKs")
+	cmd.Flags().BoolVar(&p.noCommit, "no-commit", false, "don't commit each advisory document after updating it")
+
+	cmd.Flags().StringVar(&p.localDBFilePath, "local-file-grype-db", "", "import a local grype db file")
+	addGrypeDBURLFlag(&p.grypeDBURL, cmd)
+	cmd.Flags().StringVar(&p.distro, "distro", "wolfi", "distro to use during vulnerability matching")
+	cmd.Flags().BoolVarP(&p.disableSBOMCache, "disable-sbom-cache", "D", false, "don't use the SBOM cache")
+}
+
+func readScanResults(path string) ([]scan.Result, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening scan results file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	results, err := scan.DecodeResults(r)
+	if err != nil {
+		return nil, fmt.Errorf("decoding scan results from %q: %w", path, err)
+	}
+	return results, nil
+}
+
+// unaddressedScanFinding is a finding that doesn't have a concluded advisory
+// yet, along with the scan result it came from.
+type unaddressedScanFinding struct {
+	result  scan.Result
+	finding scan.Finding
+}
+
+// unaddressedScanFindings returns the findings from the results that don't have
+// a concluded advisory, with only one finding per vulnerability per origin
+// package, since they'd share an advisory.
+func unaddressedScanFindings(ctx context.Context, results []scan.Result, advGetter advisory.Getter) ([]unaddressedScanFinding, error) {
+	seen := make(map[string]bool)
+	var items []unaddressedScanFinding
+
+	for _, result := range results {
+		findings, err := scan.FilterWithAdvisories(ctx, result, advGetter, scan.AdvisoriesSetConcluded)
+		if err != nil {
+			return nil, fmt.Errorf("filtering findings for %s: %w", result.TargetAPK.Name, err)
+		}
+
+		for _, f := range findings {
+			key := result.TargetAPK.Origin() + "/" + f.Vulnerability.ID
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			items = append(items, unaddressedScanFinding{result: result, finding: f})
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory/secdb"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/build"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+const (
+	crossCheckTrackerAlpine = "alpine"
+	crossCheckTrackerDebian = "debian"
+	crossCheckTrackerOSV    = "osv"
+)
+
+var validCrossCheckTrackers = []string{crossCheckTrackerAlpine, crossCheckTrackerDebian, crossCheckTrackerOSV}
+
+func cmdAdvisoryCrossCheck() *cobra.Command {
+	p := &crossCheckParams{}
+	cmd := &cobra.Command{
+		Use:   "cross-check [<package>...]",
+		Short: "Find vulnerabilities that other distros have fixed or triaged, but that have no advisory",
+		Long: `Find vulnerabilities that other distros have fixed or triaged, but that have no advisory.
+
+Other distros' security trackers are a useful signal of vulnerabilities that
+affect upstream projects we also package. For each package, this command lists
+the vulnerabilities that another distro's tracker knows about for its package
+of the same name, but that aren't the ID or an alias of any of our package's
+advisories.
+
+The trackers (--tracker) are:
+
+  alpine   Alpine's security databases (--alpine-secdb)
+  debian   the Debian security tracker (--debian-tracker), by source package
+  osv      the OSV database's distro ecosystems (--osv-ecosystem)
+
+The Alpine and Debian data can be given as URLs or local file paths.
+
+Each vulnerability is shown with its status in each tracker: "fixed" (with the
+other distro's fixed version), "not-affected", or "triaged" (tracked, but not
+resolved yet).
+
+The packages can be given as arguments. Otherwise, all packages with a build
+configuration in the distro repo are checked.`,
+		Example: `
+wolfictl adv cross-check ko crane
+
+wolfictl adv cross-check --tracker debian --debian-tracker ./debian.json -o json`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatTable
+			}
+
+			if !slices.Contains(validCrossCheckOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validCrossCheckOutputFormats, ", "),
+				)
+			}
+
+			for _, t := range p.trackers {
+				if !slices.Contains(validCrossCheckTrackers, t) {
+					return fmt.Errorf("invalid tracker %q, must be one of [%s]", t, strings.Join(validCrossCheckTrackers, ", "))
+				}
+			}
+
+			distroRepoDir := resolveDistroDir(p.distroRepoDir)
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" || (len(args) == 0 && distroRepoDir == "") {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("distro repo dir and/or advisories repo dir was left unspecified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("distro repo dir and/or advisories repo dir was left unspecified, and distro auto-detection failed: %w", err)
+				}
+
+				if distroRepoDir == "" {
+					distroRepoDir = d.Local.PackagesRepo.Dir
+				}
+				if advisoriesRepoDir == "" {
+					advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				}
+
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := advisory.IndexWithArchive(ctx, os.DirFS(advisoriesRepoDir))
+			if err != nil {
+				return fmt.Errorf("unable to create index of advisories repo: %w", err)
+			}
+
+			packages := args
+			if len(packages) == 0 {
+				buildCfgs, err := build.NewIndex(ctx, rwos.DirFS(distroRepoDir))
+				if err != nil {
+					return fmt.Errorf("unable to create index of distro package configurations: %w", err)
+				}
+				for _, cfg := range buildCfgs.Select().Configurations() {
+					packages = append(packages, cfg.Package.Name)
+				}
+				sort.Strings(packages)
+			}
+
+			trackers, err := p.externalTrackers(ctx)
+			if err != nil {
+				return err
+			}
+
+			findings, err := advisory.CrossCheck(ctx, advisory.CrossCheckOptions{
+				AdvisoryDocs: advisoryDocs,
+				Packages:     packages,
+				Trackers:     trackers,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch p.outputFormat {
+			case outputFormatJSON:
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if findings == nil {
+					findings = []advisory.CrossCheckFinding{}
+				}
+				if err := enc.Encode(findings); err != nil {
+					return fmt.Errorf("encoding JSON: %w", err)
+				}
+
+			case outputFormatTable:
+				if len(findings) == 0 {
+					fmt.Fprintln(os.Stderr, "No vulnerabilities found that other distros know about but that have no advisory.")
+					return nil
+				}
+				renderCrossCheckFindings(os.Stdout, findings)
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type crossCheckParams struct {
+	doNotDetectDistro bool
+	distroRepoDir     string
+	advisoriesRepoDir string
+	trackers          []string
+	alpineSecDBs      []string
+	debianTracker     string
+	osvEcosystems     []string
+	outputFormat      string
+}
+
+var validCrossCheckOutputFormats = []string{outputFormatTable, outputFormatJSON}
+
+func (p *crossCheckParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addDistroDirFlag(&p.distroRepoDir, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().StringSliceVar(&p.trackers, "tracker", validCrossCheckTrackers, fmt.Sprintf("trackers to compare against (%s)", strings.Join(validCrossCheckTrackers, "|")))
+	cmd.Flags().StringSliceVar(&p.alpineSecDBs, "alpine-secdb", []string{
+		"https://secdb.alpinelinux.org/edge/main.json",
+		"https://secdb.alpinelinux.org/edge/community.json",
+	}, "URL or path of an Alpine security database (can be repeated)")
+	cmd.Flags().StringVar(&p.debianTracker, "debian-tracker", advisory.DebianTrackerURL, "URL or path of the Debian security tracker's JSON data")
+	cmd.Flags().StringSliceVar(&p.osvEcosystems, "osv-ecosystem", []string{"Ubuntu:24.04:LTS"}, "OSV ecosystem of a distro to compare against (can be repeated)")
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validCrossCheckOutputFormats, "|"), outputFormatTable))
+}
+
+func (p *crossCheckParams) externalTrackers(ctx context.Context) ([]advisory.ExternalTracker, error) {
+	var trackers []advisory.ExternalTracker
+
+	if slices.Contains(p.trackers, crossCheckTrackerAlpine) {
+		var t advisory.AlpineSecDBTracker
+		for _, location := range p.alpineSecDBs {
+			var db secdb.Database
+			if err := readURLOrFile(ctx, location, func(r io.Reader) error {
+				return json.NewDecoder(r).Decode(&db)
+			}); err != nil {
+				return nil, fmt.Errorf("reading Alpine security database: %w", err)
+			}
+			t.Databases = append(t.Databases, db)
+		}
+		trackers = append(trackers, t)
+	}
+
+	if slices.Contains(p.trackers, crossCheckTrackerDebian) {
+		var t *advisory.DebianTracker
+		if err := readURLOrFile(ctx, p.debianTracker, func(r io.Reader) (err error) {
+			t, err = advisory.NewDebianTracker(r)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("reading Debian security tracker data: %w", err)
+		}
+		trackers = append(trackers, t)
+	}
+
+	if slices.Contains(p.trackers, crossCheckTrackerOSV) {
+		for _, ecosystem := range p.osvEcosystems {
+			trackers = append(trackers, advisory.OSVTracker{Ecosystem: ecosystem})
+		}
+	}
+
+	return trackers, nil
+}
+
+// readURLOrFile calls read with the data at location, which is a URL or a
+// local file path.
+func readURLOrFile(ctx context.Context, location string, read func(io.Reader) error) error {
+	if !strings.HasPrefix(location, "https://") && !strings.HasPrefix(location, "http://") {
+		f, err := os.Open(location)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return read(f)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading %s: unexpected status code %d", location, resp.StatusCode)
+	}
+
+	return read(resp.Body)
+}
+
+func renderCrossCheckFindings(w io.Writer, findings []advisory.CrossCheckFinding) {
+	pkg := ""
+	for _, f := range findings {
+		if f.Package != pkg {
+			if pkg != "" {
+				fmt.Fprintln(w)
+			}
+			pkg = f.Package
+			fmt.Fprintln(w, styles.Bold().Render(pkg))
+		}
+
+		statuses := make([]string, 0, len(f.Statuses))
+		for _, s := range f.Statuses {
+			status := s.Status
+			if s.FixedVersion != "" {
+				status += " in " + s.FixedVersion
+			}
+			statuses = append(statuses, fmt.Sprintf("%s: %s", s.Tracker, status))
+		}
+
+		fmt.Fprintf(w, "  %s  %s\n", f.Vulnerability, strings.Join(statuses, "; "))
+	}
+
+	fmt.Fprintf(w, "\n%d vulnerabilities have no advisory.\n", len(findings))
+}
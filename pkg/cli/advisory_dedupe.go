@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/components/ctrlcwrapper"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/components/interview"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+	"github.com/wolfi-dev/wolfictl/pkg/question"
+)
+
+func cmdAdvisoryDedupe() *cobra.Command {
+	p := &dedupeParams{}
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Merge advisories of a package that are about the same vulnerability",
+		Long: `Merge advisories of a package that are about the same vulnerability.
+
+The same vulnerability can end up with more than one advisory in a package,
+e.g. when one scanner reports it by its CVE ID and another by its GHSA ID.
+Advisories of a package are duplicates when they have an alias in common,
+directly or through other advisories of the package. With --resolve-aliases,
+each advisory's aliases are first completed using the GitHub and NVD APIs, so
+that e.g. an advisory for a GHSA duplicates an advisory for the GHSA's CVE.
+
+Each set of duplicates is merged into the advisory that was created first,
+which gets the union of the duplicates' aliases and events. The other
+advisories are removed.
+
+Use --interactive to confirm each merge, or --dry-run to see the duplicates
+without changing anything.`,
+		Example: `
+wolfictl adv dedupe --dry-run
+
+wolfictl adv dedupe -i --resolve-aliases -p ko -p crane`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if p.dryRun && p.interactive {
+				return errors.New("--dry-run and --interactive can't be used together")
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := adv2.NewIndexForPackages(ctx, rwos.DirFS(advisoriesRepoDir), p.packages...)
+			if err != nil {
+				return fmt.Errorf("unable to create index of advisories repo: %w", err)
+			}
+
+			selectedPackages := make(map[string]struct{})
+			for _, pkg := range p.packages {
+				selectedPackages[pkg] = struct{}{}
+			}
+
+			opts := advisory.DedupeOptions{
+				AdvisoryDocs:     advisoryDocs,
+				SelectedPackages: selectedPackages,
+			}
+			if p.resolveAliases {
+				opts.AliasFinder = advisory.NewHTTPAliasFinder(http.DefaultClient)
+			}
+
+			groups, err := advisory.FindDuplicates(ctx, opts)
+			if err != nil {
+				return err
+			}
+
+			if len(groups) == 0 {
+				fmt.Fprintln(os.Stderr, "No duplicate advisories found.")
+				return nil
+			}
+
+			merged := 0
+			for i, g := range groups {
+				fmt.Println(renderDuplicateGroup(g))
+
+				if p.dryRun {
+					continue
+				}
+
+				if p.interactive {
+					confirmed, exit, err := confirmDedupe(i+1, len(groups))
+					if err != nil {
+						return err
+					}
+					if exit {
+						break
+					}
+					if !confirmed {
+						fmt.Println("👀 Skipping this one.")
+						continue
+					}
+				}
+
+				if err := advisory.MergeDuplicates(ctx, advisoryDocs, []advisory.DuplicateGroup{g}); err != nil {
+					return err
+				}
+				merged++
+			}
+
+			if p.dryRun {
+				fmt.Fprintf(os.Stderr, "\n%d sets of duplicate advisories would be merged (dry run).\n", len(groups))
+			} else {
+				fmt.Fprintf(os.Stderr, "\n%d sets of duplicate advisories merged.\n", merged)
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type dedupeParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	packages          []string
+	resolveAliases    bool
+	interactive       bool
+	dryRun            bool
+}
+
+func (p *dedupeParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addMultiPackageFlag(&p.packages, cmd)
+	cmd.Flags().BoolVar(&p.resolveAliases, "resolve-aliases", false, "complete advisories' aliases using the GitHub and NVD APIs before looking for duplicates")
+	cmd.Flags().BoolVarP(&p.interactive, "interactive", "i", false, "confirm each merge")
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "print the duplicate advisories without merging them")
+}
+
+// renderDuplicateGroup describes the merge of the group's advisories, e.g.
+// "ko: CGA-2222-2222-2222 ← CGA-3333-3333-3333 (CVE-2024-1111, GHSA-...)".
+func renderDuplicateGroup(g advisory.DuplicateGroup) string {
+	ids := make([]string, 0, len(g.Advisories)-1)
+	for _, adv := range g.Advisories[1:] {
+		ids = append(ids, adv.ID)
+	}
+
+	return fmt.Sprintf(
+		"%s: %s ← %s (%s)",
+		styles.Bold().Render(g.Package),
+		styles.Bold().Render(g.Advisories[0].ID),
+		strings.Join(ids, ", "),
+		strings.Join(g.Merged().Aliases, ", "),
+	)
+}
+
+// confirmDedupe asks the user whether to merge the current group of duplicate
+// advisories. It returns exit as true if the user wants to stop.
+func confirmDedupe(n, total int) (confirmed, exit bool, err error) {
+	q := question.Question[bool]{
+		Text: fmt.Sprintf("(%d/%d) Merge these advisories?", n, total),
+		Answer: question.MultipleChoice[bool]{
+			{
+				Text: "Yes, merge them",
+				Choose: func(bool) (bool, *question.Question[bool], error) {
+					return true, nil, nil
+				},
+			},
+			{
+				Text: "No, skip them",
+				Choose: func(bool) (bool, *question.Question[bool], error) {
+					return false, nil, question.ErrTerminate
+				},
+			},
+		},
+	}
+
+	iv, err := interview.New(q, false)
+	if err != nil {
+		return false, false, fmt.Errorf("creating interview for duplicate advisories: %w", err)
+	}
+	ivTea, err := tea.NewProgram(ctrlcwrapper.New(iv)).Run()
+	if err != nil {
+		return false, false, fmt.Errorf("running interview for duplicate advisories: %w", err)
+	}
+	if ivCtrlC, ok := ivTea.(ctrlcwrapper.Model[interview.Model[bool]]); ok {
+		if ivCtrlC.UserWantsToExit() {
+			return false, true, nil
+		}
+
+		iv = ivCtrlC.Unwrap()
+	}
+
+	confirmed, err = iv.State()
+	if err != nil {
+		if errors.Is(err, question.ErrTerminate) {
+			return false, false, nil
+		}
+
+		return false, false, fmt.Errorf("getting data back from interview: %w", err)
+	}
+
+	return confirmed, false, nil
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -10,50 +11,126 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"github.com/wolfi-dev/wolfictl/pkg/advisory"
-	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
-	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
 	"github.com/wolfi-dev/wolfictl/pkg/distro"
 	wgit "github.com/wolfi-dev/wolfictl/pkg/git"
+	"golang.org/x/exp/slices"
 )
 
 func cmdAdvisoryDiff() *cobra.Command {
+	p := &diffParams{}
 	cmd := &cobra.Command{
-		Use:           "diff",
-		Short:         "See the advisory data differences introduced by your local changes",
+		Use:   "diff",
+		Short: "See the advisory data differences introduced by your local changes",
+		Long: `See the advisory data differences introduced by your local changes.
+
+By default, this command compares the advisories repo's working tree to the
+upstream repo's state at the fork point of your local branch, as found by
+distro auto-detection.
+
+Use --from and --to to compare the advisory data at two git refs (such as
+branches, tags, or commit hashes) of the advisories repo instead. If --to is
+omitted, the working tree is compared to the --from ref.
+
+The differences are the added, removed, and modified advisory documents, and
+the added, removed, and modified advisories and events within them. Use
+"--output json" to get them in a machine-readable form, e.g. for PR review
+automation.`,
+		Example: `  # Compare your local changes to the upstream fork point
+  wolfictl adv diff
+
+  # Compare the working tree to the main branch
+  wolfictl adv diff --from main
+
+  # Compare two commits, as JSON
+  wolfictl adv diff -a ./advisories --from HEAD~1 --to HEAD -o json`,
 		Deprecated:    advisoryDeprecationMessage,
 		SilenceErrors: true,
 		Args:          cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			d, err := distro.Detect()
-			if err != nil {
-				return fmt.Errorf("distro auto-detection failed: %w", err)
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatOutline
 			}
 
-			fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			if !slices.Contains(validAdvDiffOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validAdvDiffOutputFormats, ", "),
+				)
+			}
 
-			advisoriesRepoURL, err := getAdvisoriesHTTPSRemoteURL(d)
-			if err != nil {
-				return err
+			if p.to != "" && p.from == "" {
+				return fmt.Errorf("using --%s requires --%s", flagNameDiffTo, flagNameDiffFrom)
 			}
 
-			// Clone the upstream repo to a temp directory
-			useAuth := d.Absolute.Name != "Wolfi"
-			baseRef := d.Local.AdvisoriesRepo.ForkPoint
-			cloneDir, err := wgit.TempClone(advisoriesRepoURL, baseRef, useAuth)
-			defer os.RemoveAll(cloneDir)
-			if err != nil {
-				return fmt.Errorf("unable to produce a base repo state for comparison: %w", err)
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+
+			var d *distro.Distro
+			if advisoriesRepoDir == "" || p.from == "" {
+				if p.doNotDetectDistro {
+					if advisoriesRepoDir == "" {
+						return fmt.Errorf("no advisories repo dir specified")
+					}
+					return fmt.Errorf("need --%s when --%s is specified", flagNameDiffFrom, flagNameNoDistroDetection)
+				}
+
+				detected, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("distro auto-detection failed: %w", err)
+				}
+				d = &detected
+
+				fmt.Fprint(os.Stderr, renderDetectedDistro(detected))
+
+				if advisoriesRepoDir == "" {
+					advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				}
+			}
+
+			var baseDir string
+			if p.from != "" {
+				dir, err := wgit.TempExport(advisoriesRepoDir, p.from)
+				defer os.RemoveAll(dir)
+				if err != nil {
+					return fmt.Errorf("unable to produce the advisory data at %q for comparison: %w", p.from, err)
+				}
+				baseDir = dir
+			} else {
+				advisoriesRepoURL, err := getAdvisoriesHTTPSRemoteURL(*d)
+				if err != nil {
+					return err
+				}
+
+				// Clone the upstream repo to a temp directory
+				useAuth := d.Absolute.Name != "Wolfi"
+				baseRef := d.Local.AdvisoriesRepo.ForkPoint
+				cloneDir, err := wgit.TempClone(advisoriesRepoURL, baseRef, useAuth)
+				defer os.RemoveAll(cloneDir)
+				if err != nil {
+					return fmt.Errorf("unable to produce a base repo state for comparison: %w", err)
+				}
+				baseDir = cloneDir
 			}
 
-			baseAdvisoriesFsys := rwos.DirFS(cloneDir)
-			baseAdvisoriesIndex, err := adv2.NewIndex(cmd.Context(), baseAdvisoriesFsys)
+			baseAdvisoriesIndex, err := advisory.IndexWithArchive(cmd.Context(), os.DirFS(baseDir))
 			if err != nil {
 				return err
 			}
 
-			currentAdvisoriesIndex, err := adv2.NewIndex(cmd.Context(), rwos.DirFS(d.Local.AdvisoriesRepo.Dir))
+			currentDir := advisoriesRepoDir
+			if p.to != "" {
+				dir, err := wgit.TempExport(advisoriesRepoDir, p.to)
+				defer os.RemoveAll(dir)
+				if err != nil {
+					return fmt.Errorf("unable to produce the advisory data at %q for comparison: %w", p.to, err)
+				}
+				currentDir = dir
+			}
+
+			currentAdvisoriesIndex, err := advisory.IndexWithArchive(cmd.Context(), os.DirFS(currentDir))
 			if err != nil {
 				return err
 			}
@@ -61,15 +138,48 @@ func cmdAdvisoryDiff() *cobra.Command {
 			// Diff!
 
 			diff := advisory.IndexDiff(baseAdvisoriesIndex, currentAdvisoriesIndex)
+
+			if p.outputFormat == outputFormatJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(newDiffJSON(diff)); err != nil {
+					return fmt.Errorf("encoding JSON: %w", err)
+				}
+				return nil
+			}
+
 			fmt.Println(renderDiff(diff))
 
 			return nil
 		},
 	}
 
+	p.addFlagsTo(cmd)
 	return cmd
 }
 
+type diffParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	from, to          string
+	outputFormat      string
+}
+
+const (
+	flagNameDiffFrom = "from"
+	flagNameDiffTo   = "to"
+)
+
+var validAdvDiffOutputFormats = []string{outputFormatOutline, outputFormatJSON}
+
+func (p *diffParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().StringVar(&p.from, flagNameDiffFrom, "", "git ref of the advisories repo to compare from (default: the upstream fork point)")
+	cmd.Flags().StringVar(&p.to, flagNameDiffTo, "", "git ref of the advisories repo to compare to (default: the working tree)")
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validAdvDiffOutputFormats, "|"), outputFormatOutline))
+}
+
 func getAdvisoriesHTTPSRemoteURL(d distro.Distro) (string, error) {
 	for _, u := range d.Absolute.AdvisoriesRemoteURLs() {
 		if strings.HasPrefix(u, "https://") {
@@ -137,6 +247,98 @@ func renderDiff(result advisory.IndexDiffResult) string {
 	return sb.String()
 }
 
+// diffJSON is the JSON representation of an advisory index diff.
+type diffJSON struct {
+	Documents []documentDiffJSON `json:"documents"`
+}
+
+type documentDiffJSON struct {
+	Package    string             `json:"package"`
+	Change     string             `json:"change"`
+	Advisories []advisoryDiffJSON `json:"advisories,omitempty"`
+}
+
+type advisoryDiffJSON struct {
+	ID             string     `json:"id"`
+	Change         string     `json:"change"`
+	Aliases        []string   `json:"aliases,omitempty"`
+	AddedAliases   []string   `json:"addedAliases,omitempty"`
+	RemovedAliases []string   `json:"removedAliases,omitempty"`
+	AddedEvents    []v2.Event `json:"addedEvents,omitempty"`
+	RemovedEvents  []v2.Event `json:"removedEvents,omitempty"`
+}
+
+const (
+	diffChangeAdded    = "added"
+	diffChangeRemoved  = "removed"
+	diffChangeModified = "modified"
+)
+
+func newDiffJSON(result advisory.IndexDiffResult) diffJSON {
+	out := diffJSON{Documents: []documentDiffJSON{}}
+
+	for _, doc := range result.Removed {
+		docDiff := documentDiffJSON{Package: doc.Name(), Change: diffChangeRemoved}
+		for _, adv := range doc.Advisories {
+			docDiff.Advisories = append(docDiff.Advisories, advisoryDiffJSON{
+				ID:            adv.ID,
+				Change:        diffChangeRemoved,
+				Aliases:       adv.Aliases,
+				RemovedEvents: adv.Events,
+			})
+		}
+		out.Documents = append(out.Documents, docDiff)
+	}
+
+	for _, doc := range result.Added {
+		docDiff := documentDiffJSON{Package: doc.Name(), Change: diffChangeAdded}
+		for _, adv := range doc.Advisories {
+			docDiff.Advisories = append(docDiff.Advisories, advisoryDiffJSON{
+				ID:          adv.ID,
+				Change:      diffChangeAdded,
+				Aliases:     adv.Aliases,
+				AddedEvents: adv.Events,
+			})
+		}
+		out.Documents = append(out.Documents, docDiff)
+	}
+
+	for _, modified := range result.Modified {
+		docDiff := documentDiffJSON{Package: modified.Name, Change: diffChangeModified}
+		for _, adv := range modified.Removed {
+			docDiff.Advisories = append(docDiff.Advisories, advisoryDiffJSON{
+				ID:            adv.ID,
+				Change:        diffChangeRemoved,
+				Aliases:       adv.Aliases,
+				RemovedEvents: adv.Events,
+			})
+		}
+		for _, adv := range modified.Added {
+			docDiff.Advisories = append(docDiff.Advisories, advisoryDiffJSON{
+				ID:          adv.ID,
+				Change:      diffChangeAdded,
+				Aliases:     adv.Aliases,
+				AddedEvents: adv.Events,
+			})
+		}
+		for i := range modified.Modified {
+			advDiff := modified.Modified[i]
+			docDiff.Advisories = append(docDiff.Advisories, advisoryDiffJSON{
+				ID:             advDiff.ID,
+				Change:         diffChangeModified,
+				Aliases:        advDiff.Added.Aliases,
+				AddedAliases:   lo.Without(advDiff.Added.Aliases, advDiff.Removed.Aliases...),
+				RemovedAliases: lo.Without(advDiff.Removed.Aliases, advDiff.Added.Aliases...),
+				AddedEvents:    advDiff.AddedEvents,
+				RemovedEvents:  advDiff.RemovedEvents,
+			})
+		}
+		out.Documents = append(out.Documents, docDiff)
+	}
+
+	return out
+}
+
 // renderCmpDiffOutput renders the output of a cmp.Diff call, by filtering out
 // any unchanged lines, and by coloring lines whose first non-space character is
 // prefixed with a + or -.
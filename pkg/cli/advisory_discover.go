@@ -70,7 +70,7 @@ func cmdAdvisoryDiscover() *cobra.Command {
 			}
 
 			selectedPackages := getSelectedOrDistroPackages(p.packageName, buildCfgs)
-			apiKey := p.resolveNVDAPIKey()
+			apiKey := resolveNVDAPIKey(p.nvdAPIKey)
 
 			ctx := cmd.Context()
 			g, ctx := errgroup.WithContext(ctx)
@@ -146,14 +146,18 @@ func (p *discoverParams) addFlagsTo(cmd *cobra.Command) {
 
 	cmd.Flags().StringVarP(&p.packageRepositoryURL, "package-repo-url", "r", "", "URL of the APK package repository")
 
-	cmd.Flags().StringVar(&p.nvdAPIKey, "nvd-api-key", "", fmt.Sprintf("NVD API key (Can also be set via the environment variable '%s'. Using an API key significantly increases the rate limit for API requests. If you need an NVD API key, go to https://nvd.nist.gov/developers/request-an-api-key.)", envVarNameForNVDAPIKey))
+	addNVDAPIKeyFlag(&p.nvdAPIKey, cmd)
 }
 
-func (p *discoverParams) resolveNVDAPIKey() string {
+func addNVDAPIKeyFlag(val *string, cmd *cobra.Command) {
+	cmd.Flags().StringVar(val, "nvd-api-key", "", fmt.Sprintf("NVD API key (Can also be set via the environment variable '%s'. Using an API key significantly increases the rate limit for API requests. If you need an NVD API key, go to https://nvd.nist.gov/developers/request-an-api-key.)", envVarNameForNVDAPIKey))
+}
+
+func resolveNVDAPIKey(flagValue string) string {
 	// TODO: use Viper for this!
 
-	if p.nvdAPIKey != "" {
-		return p.nvdAPIKey
+	if flagValue != "" {
+		return flagValue
 	}
 
 	keyFromEnv := os.Getenv(envVarNameForNVDAPIKey)
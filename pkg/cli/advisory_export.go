@@ -1,19 +1,25 @@
 package cli
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
+	"chainguard.dev/apko/pkg/build/types"
 	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
 	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
 
 	"github.com/wolfi-dev/wolfictl/pkg/advisory"
 	"github.com/wolfi-dev/wolfictl/pkg/configs"
-	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
-	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
 	"github.com/wolfi-dev/wolfictl/pkg/distro"
+	"github.com/wolfi-dev/wolfictl/pkg/sbom"
 )
 
 func cmdAdvisoryExport() *cobra.Command {
@@ -26,6 +32,29 @@ func cmdAdvisoryExport() *cobra.Command {
 		Args:          cobra.NoArgs,
 		Hidden:        true,
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !slices.Contains(validExportFormats, p.format) {
+				return fmt.Errorf("unrecognized format: %q. Valid formats are: [%s]", p.format, strings.Join(validExportFormats, ", "))
+			}
+			if p.format == OutputOSV && p.outputLocation == "" {
+				return fmt.Errorf("an output directory or zip file (--output) is required for %s format", OutputOSV)
+			}
+			if p.format == OutputParquet && p.outputLocation == "" {
+				return fmt.Errorf("an output file (--output) is required for %s format", OutputParquet)
+			}
+			if p.format == OutputHTMLSite && p.outputLocation == "" {
+				return fmt.Errorf("an output directory (--output) is required for %s format", OutputHTMLSite)
+			}
+			for i, status := range p.statuses {
+				p.statuses[i] = translateEventTypeAlternativeNames(status)
+				if !slices.Contains(v2.EventTypes, p.statuses[i]) {
+					return fmt.Errorf("invalid status %q, must be one of [%s]", status, strings.Join(v2.EventTypes, ", "))
+				}
+			}
+			if err := p.validate(p.outputLocation); err != nil {
+				return err
+			}
+
+			var detected *distro.Distro
 			if len(p.advisoriesRepoDirs) == 0 {
 				if p.doNotDetectDistro {
 					return fmt.Errorf("no advisories repo dir specified")
@@ -38,12 +67,21 @@ func cmdAdvisoryExport() *cobra.Command {
 
 				p.advisoriesRepoDirs = append(p.advisoriesRepoDirs, d.Local.AdvisoriesRepo.Dir)
 				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+				detected = &d
 			}
 
 			indices := make([]*configs.Index[v2.Document], 0, len(p.advisoriesRepoDirs))
 			for _, dir := range p.advisoriesRepoDirs {
-				advisoryFsys := rwos.DirFS(dir)
-				index, err := adv2.NewIndex(cmd.Context(), advisoryFsys)
+				advisoryFsys := os.DirFS(dir)
+				if p.asOf != "" {
+					fsys, err := advisoriesFSAsOf(cmd.Context(), dir, p.asOf)
+					if err != nil {
+						return err
+					}
+					advisoryFsys = fsys
+				}
+
+				index, err := advisory.IndexWithArchive(cmd.Context(), advisoryFsys)
 				if err != nil {
 					return fmt.Errorf("unable to index advisory configs for directory %q: %w", dir, err)
 				}
@@ -53,6 +91,94 @@ func cmdAdvisoryExport() *cobra.Command {
 
 			opts := advisory.ExportOptions{
 				AdvisoryDocIndices: indices,
+				Ecosystem:          p.ecosystem,
+				Packages:           p.packages,
+				Statuses:           p.statuses,
+			}
+
+			if p.packagesFile != "" {
+				packages, err := readPackageListFile(p.packagesFile)
+				if err != nil {
+					return err
+				}
+				opts.Packages = append(opts.Packages, packages...)
+			}
+
+			if p.image != "" {
+				origins, err := imageOriginPackages(cmd.Context(), p.image, p.arch)
+				if err != nil {
+					return err
+				}
+				opts.Packages = append(opts.Packages, origins...)
+			}
+
+			if (p.packagesFile != "" || p.image != "") && len(opts.Packages) == 0 {
+				return fmt.Errorf("no packages to export")
+			}
+
+			if p.modifiedSince != "" {
+				t, err := parseModifiedSince(p.modifiedSince)
+				if err != nil {
+					return err
+				}
+				opts.ModifiedSince = t
+			}
+
+			if (p.format == OutputOSV || p.format == OutputOpenVEX || p.format == OutputCSAF) && opts.Ecosystem == "" {
+				if detected == nil {
+					return fmt.Errorf("no ecosystem specified for %s format (use --ecosystem)", p.format)
+				}
+				opts.Ecosystem = detected.Absolute.Name
+			}
+
+			if p.format == OutputCSAF {
+				opts.PublisherNamespace = p.publisherNamespace
+				if opts.PublisherNamespace == "" {
+					if detected == nil {
+						return fmt.Errorf("no publisher namespace specified for %s format (use --publisher-namespace)", p.format)
+					}
+					opts.PublisherNamespace = fmt.Sprintf("https://github.com/%s", detected.Absolute.DistroRepoOwner)
+				}
+			}
+
+			if p.format == OutputOSV || p.format == OutputCSAF {
+				if p.deriveRanges {
+					distroRepoDir := resolveDistroDir(p.distroRepoDir)
+					if distroRepoDir == "" {
+						if detected == nil {
+							return fmt.Errorf("no distro repo dir specified for --%s (use --%s)", flagNameDeriveRanges, flagNameDistroRepoDir)
+						}
+						distroRepoDir = detected.Local.PackagesRepo.Dir
+					}
+
+					timeline, err := advisory.NewGitPackageVersionHistory(distroRepoDir)
+					if err != nil {
+						return fmt.Errorf("unable to read package version history from distro repo: %w", err)
+					}
+					opts.PackageVersionTimeline = timeline
+				}
+			}
+
+			if p.format == OutputOSV {
+				if err := exportOSV(cmd.Context(), opts, p.outputLocation); err != nil {
+					return err
+				}
+				return p.signOutput(cmd.Context(), p.outputLocation)
+			}
+
+			if p.format == OutputParquet || p.format == OutputHTMLSite {
+				cvss, err := readCVSSDataFromDirs(p.advisoriesRepoDirs)
+				if err != nil {
+					return err
+				}
+				opts.CVSS = cvss
+			}
+
+			if p.format == OutputHTMLSite {
+				if err := advisory.WriteHTMLSite(p.outputLocation, opts); err != nil {
+					return fmt.Errorf("unable to export advisory data: %w", err)
+				}
+				return p.signOutput(cmd.Context(), p.outputLocation)
 			}
 
 			var export io.Reader
@@ -62,8 +188,12 @@ func cmdAdvisoryExport() *cobra.Command {
 				export, err = advisory.ExportYAML(opts)
 			case OutputCSV:
 				export, err = advisory.ExportCSV(opts)
-			default:
-				return fmt.Errorf("unrecognized format: %q. Valid formats are: [%s]", p.format, strings.Join([]string{OutputYAML, OutputCSV}, ", "))
+			case OutputOpenVEX:
+				export, err = advisory.ExportOpenVEX(opts)
+			case OutputCSAF:
+				export, err = advisory.ExportCSAF(cmd.Context(), opts)
+			case OutputParquet:
+				export, err = advisory.ExportParquet(opts)
 			}
 			if err != nil {
 				return fmt.Errorf("unable to export advisory data: %w", err)
@@ -85,7 +215,7 @@ func cmdAdvisoryExport() *cobra.Command {
 				return fmt.Errorf("unable to export data to specified location: %w", err)
 			}
 
-			return nil
+			return p.signOutput(cmd.Context(), p.outputLocation)
 		},
 	}
 
@@ -93,12 +223,118 @@ func cmdAdvisoryExport() *cobra.Command {
 	return cmd
 }
 
+// exportOSV writes the advisory data as OSV vulnerabilities to the output
+// location, which is a zip file if it has a ".zip" extension, and otherwise a
+// directory.
+func exportOSV(ctx context.Context, opts advisory.ExportOptions, outputLocation string) error {
+	vulnerabilities, err := advisory.ExportOSV(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("unable to export advisory data: %w", err)
+	}
+
+	if filepath.Ext(outputLocation) != ".zip" {
+		if err := advisory.WriteOSVDirectory(outputLocation, vulnerabilities); err != nil {
+			return fmt.Errorf("unable to export data to specified location: %w", err)
+		}
+		return nil
+	}
+
+	outputFile, err := os.Create(outputLocation)
+	if err != nil {
+		return fmt.Errorf("unable to create output file: %w", err)
+	}
+	defer outputFile.Close()
+
+	if err := advisory.WriteOSVZip(outputFile, vulnerabilities); err != nil {
+		return fmt.Errorf("unable to export data to specified location: %w", err)
+	}
+
+	return outputFile.Close()
+}
+
+// readPackageListFile returns the package names listed in the file, one per
+// line. Blank lines and lines starting with "#" are ignored.
+func readPackageListFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open package list: %w", err)
+	}
+	defer f.Close()
+
+	var packages []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		packages = append(packages, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read package list %q: %w", path, err)
+	}
+
+	return packages, nil
+}
+
+// parseModifiedSince parses the value of --modified-since, which is either an
+// RFC 3339 timestamp or a date.
+func parseModifiedSince(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing modified-since timestamp %q: must be an RFC 3339 timestamp or a date (YYYY-MM-DD)", s)
+	}
+	return t, nil
+}
+
+// imageOriginPackages returns the names of the origin packages of the APKs
+// installed in the container image, whose advisories apply to the image.
+func imageOriginPackages(ctx context.Context, ref, arch string) ([]string, error) {
+	imageAPKs, err := sbom.APKsFromImage(ctx, ref, arch)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read installed APKs from image %q: %w", ref, err)
+	}
+
+	var origins []string
+	for _, pkg := range imageAPKs.Packages {
+		origin := pkg.Origin
+		if origin == "" {
+			origin = pkg.Name
+		}
+		if !slices.Contains(origins, origin) {
+			origins = append(origins, origin)
+		}
+	}
+
+	return origins, nil
+}
+
 type exportParams struct {
+	signParams
+
 	doNotDetectDistro  bool
 	advisoriesRepoDirs []string
 	outputLocation     string
 	// format controls how commands will produce their output.
-	format string
+	format    string
+	ecosystem string
+	packages  []string
+	image     string
+	arch      string
+
+	packagesFile  string
+	modifiedSince string
+	statuses      []string
+	asOf          string
+
+	publisherNamespace string
+
+	deriveRanges  bool
+	distroRepoDir string
 }
 
 const (
@@ -106,12 +342,45 @@ const (
 	OutputYAML = "yaml"
 	// OutputCSV CSV output.
 	OutputCSV = "csv"
+	// OutputOSV OSV output, with one JSON file per advisory.
+	OutputOSV = "osv"
+	// OutputOpenVEX OpenVEX output.
+	OutputOpenVEX = "openvex"
+	// OutputCSAF CSAF VEX output, with the affected version ranges of each
+	// advisory.
+	OutputCSAF = "csaf"
+	// OutputParquet Parquet output, with one row per advisory event.
+	OutputParquet = "parquet"
+	// OutputHTMLSite static website output, with a page per package and per
+	// vulnerability.
+	OutputHTMLSite = "html-site"
 )
 
+var validExportFormats = []string{OutputYAML, OutputCSV, OutputOSV, OutputOpenVEX, OutputCSAF, OutputParquet, OutputHTMLSite}
+
+const flagNameDeriveRanges = "derive-ranges"
+
+func addDeriveRangesFlag(val *bool, cmd *cobra.Command) {
+	cmd.Flags().BoolVar(val, flagNameDeriveRanges, false, "derive the affected version ranges of advisories from the packages' version history in the distro repo, so that e.g. regressions after a fix are included (slower)")
+}
+
 func (p *exportParams) addFlagsTo(cmd *cobra.Command) {
 	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
 
 	cmd.Flags().StringSliceVarP(&p.advisoriesRepoDirs, "advisories-repo-dir", "a", nil, "directory containing an advisories repository")
-	cmd.Flags().StringVarP(&p.outputLocation, "output", "o", "", "output location (default: stdout). In case using OSV format this will be the output directory.")
-	cmd.Flags().StringVarP(&p.format, "format", "f", OutputCSV, fmt.Sprintf("Output format. One of: [%s]", strings.Join([]string{OutputYAML, OutputCSV}, ", ")))
+	addAsOfFlag(&p.asOf, cmd)
+	cmd.Flags().StringVarP(&p.outputLocation, "output", "o", "", "output location (default: stdout). In case using OSV format this will be the output directory, or a zip file if it has a \".zip\" extension. Required for Parquet format, and the output directory for HTML site format.")
+	cmd.Flags().StringVarP(&p.format, "format", "f", OutputCSV, fmt.Sprintf("Output format. One of: [%s]", strings.Join(validExportFormats, ", ")))
+	cmd.Flags().StringVar(&p.ecosystem, "ecosystem", "", "OSV ecosystem of the exported packages, used with OSV, OpenVEX, and CSAF formats (default: the name of the detected distro)")
+	cmd.Flags().StringVar(&p.publisherNamespace, "publisher-namespace", "", "URL of the publisher's namespace, used with CSAF format (default: the GitHub organization of the detected distro)")
+	cmd.Flags().StringSliceVar(&p.packages, "package", nil, "only export the advisories of these packages")
+	cmd.Flags().StringVar(&p.packagesFile, "packages-file", "", "only export the advisories of the packages listed in this file, one per line")
+	cmd.Flags().StringVar(&p.image, "image", "", "only export the advisories of the origin packages of the APKs installed in this container image")
+	cmd.Flags().StringVar(&p.modifiedSince, "modified-since", "", "only export advisories whose latest event is at or after this time (RFC 3339 timestamp or YYYY-MM-DD date)")
+	cmd.Flags().StringSliceVar(&p.statuses, "status", nil, fmt.Sprintf("only export advisories whose latest event has one of these types [%s]", strings.Join(v2.EventTypes, ", ")))
+	cmd.Flags().StringVar(&p.arch, "arch", types.ParseArchitecture(runtime.GOARCH).ToAPK(), "architecture of the image to use with --image")
+	addDeriveRangesFlag(&p.deriveRanges, cmd)
+	addDistroDirFlag(&p.distroRepoDir, cmd)
+
+	p.signParams.addFlagsTo(cmd)
 }
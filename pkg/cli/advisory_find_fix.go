@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryFindFix() *cobra.Command {
+	p := &findFixParams{}
+	cmd := &cobra.Command{
+		Use:   "find-fix",
+		Short: "Add a fixed event to an advisory, using the package's version history",
+		Long: `Add a fixed event to an advisory, using the package's version history.
+
+Given the first upstream version of the package that isn't vulnerable
+(--fixed-in), the Git history of the package's build configuration in the
+distro repo is searched for the commit that moved the package's version from
+the vulnerable range (below --fixed-in) past it. A fixed event is added to the
+advisory with the version the package was defined with by that commit, and the
+commit's time as the event's timestamp. (If the commit is older than the
+advisory's latest event, the fixed event is timestamped just after that event
+instead, so that it becomes the advisory's latest event.)
+
+If the package's version went back into the vulnerable range later, the last
+commit that moved it out of the range is used.
+
+Use --dry-run to see the proposed fixed event without changing anything.`,
+		Example: `
+wolfictl adv find-fix -p ko -V CVE-2024-1234 --fixed-in 0.15.2 --dry-run`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if p.packageName == "" || p.vuln == "" || p.fixedIn == "" {
+				return fmt.Errorf("a package (-p), vulnerability (-V) and upstream fixed version (--fixed-in) are required")
+			}
+
+			distroRepoDir := resolveDistroDir(p.distroRepoDir)
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if distroRepoDir == "" || advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("distro repo dir and/or advisories repo dir was left unspecified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("distro repo dir and/or advisories repo dir was left unspecified, and distro auto-detection failed: %w", err)
+				}
+
+				if distroRepoDir == "" {
+					distroRepoDir = d.Local.PackagesRepo.Dir
+				}
+				if advisoriesRepoDir == "" {
+					advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				}
+
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := adv2.NewIndexForPackages(ctx, rwos.DirFS(advisoriesRepoDir), p.packageName)
+			if err != nil {
+				return fmt.Errorf("unable to create index of advisories repo: %w", err)
+			}
+
+			timeline, err := advisory.NewGitPackageVersionHistory(distroRepoDir)
+			if err != nil {
+				return fmt.Errorf("unable to read package version history from distro repo: %w", err)
+			}
+
+			proposal, err := advisory.FindFix(ctx, advisory.FindFixOptions{
+				AdvisoryDocs:           advisoryDocs,
+				PackageVersionTimeline: timeline,
+				Package:                p.packageName,
+				Vulnerability:          p.vuln,
+				UpstreamFixedVersion:   p.fixedIn,
+			})
+			if err != nil {
+				return err
+			}
+
+			req := proposal.Request()
+			if !p.dryRun {
+				err := advisory.Update(ctx, req, advisory.UpdateOptions{
+					AdvisoryDocs: advisoryDocs,
+				})
+				if err != nil {
+					return fmt.Errorf("resolving advisory %s for %s: %w", proposal.Advisory.ID, proposal.Package, err)
+				}
+			}
+
+			fmt.Printf(
+				"%s: %s fixed in %s (was %s), by commit %s at %s\n",
+				styles.Bold().Render(proposal.Package),
+				styles.Bold().Render(proposal.Advisory.ID),
+				proposal.FixedVersion,
+				proposal.VulnerableVersion,
+				proposal.Commit,
+				req.Event.Timestamp,
+			)
+
+			if p.dryRun {
+				fmt.Fprintln(os.Stderr, "\nThe advisory was not changed (dry run).")
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type findFixParams struct {
+	doNotDetectDistro bool
+	distroRepoDir     string
+	advisoriesRepoDir string
+	packageName       string
+	vuln              string
+	fixedIn           string
+	dryRun            bool
+}
+
+func (p *findFixParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addDistroDirFlag(&p.distroRepoDir, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addPackageFlag(&p.packageName, cmd)
+	addVulnFlag(&p.vuln, cmd)
+	cmd.Flags().StringVar(&p.fixedIn, "fixed-in", "", "first upstream version of the package that isn't vulnerable (the end of the vulnerable range)")
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "print the proposed fixed event without adding it")
+}
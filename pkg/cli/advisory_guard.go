@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+	wgit "github.com/wolfi-dev/wolfictl/pkg/git"
+)
+
+func cmdAdvisoryGuard() *cobra.Command {
+	p := &guardParams{}
+	cmd := &cobra.Command{
+		Use:   "guard",
+		Short: "Check a change to the advisory data for regressions",
+		Long: `Check a change to the advisory data for regressions.
+
+This command compares the advisory data of the advisories repo's working tree
+(or the --head ref) to the advisory data at the --base ref, e.g. the target
+branch of a pull request, and fails if the change:
+
+* removes an advisory document or an advisory
+* modifies or removes existing events (i.e. rewrites history)
+* adds an event with a timestamp before the advisory's latest event
+* downgrades a fixed advisory, i.e. its latest event is no longer a fix or a
+  false positive determination, or it's a fix in a lower version
+
+Each regression is reported with the package and advisory it was found in.
+
+Archived advisories (see "wolfictl adv archive") are included on both sides, so
+moving an advisory into the archive isn't a removal.
+
+Unlike "wolfictl adv validate", this command only needs the advisories repo, so
+it's cheap to run on every pull request.`,
+		Example: `  # Check the working tree against the main branch
+  wolfictl adv guard --base main
+
+  # Check a pull request's head commit against its base commit
+  wolfictl adv guard -a ./advisories --base origin/main --head HEAD`,
+		Deprecated:    advisoryDeprecationMessage,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			log := clog.FromContext(cmd.Context())
+
+			if p.base == "" {
+				return fmt.Errorf("a base ref (--%s) is required", flagNameGuardBase)
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			baseDir, err := wgit.TempExport(advisoriesRepoDir, p.base)
+			defer os.RemoveAll(baseDir)
+			if err != nil {
+				return fmt.Errorf("unable to produce the advisory data at %q for comparison: %w", p.base, err)
+			}
+
+			baseAdvisoriesIndex, err := advisory.IndexWithArchive(cmd.Context(), os.DirFS(baseDir))
+			if err != nil {
+				return err
+			}
+
+			headDir := advisoriesRepoDir
+			if p.head != "" {
+				dir, err := wgit.TempExport(advisoriesRepoDir, p.head)
+				defer os.RemoveAll(dir)
+				if err != nil {
+					return fmt.Errorf("unable to produce the advisory data at %q for comparison: %w", p.head, err)
+				}
+				headDir = dir
+			}
+
+			headAdvisoriesIndex, err := advisory.IndexWithArchive(cmd.Context(), os.DirFS(headDir))
+			if err != nil {
+				return err
+			}
+
+			diff := advisory.IndexDiff(baseAdvisoriesIndex, headAdvisoriesIndex)
+			if err := advisory.Guard(diff); err != nil {
+				return fmt.Errorf("advisory data regression(s) found:\n%w", err)
+			}
+
+			log.Info("no advisory data regressions found", "base", p.base, "added", len(diff.Added), "modified", len(diff.Modified))
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type guardParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	base, head        string
+}
+
+const flagNameGuardBase = "base"
+
+func (p *guardParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().StringVar(&p.base, flagNameGuardBase, "", "git ref of the advisories repo to compare against, e.g. the pull request's target branch (required)")
+	cmd.Flags().StringVar(&p.head, "head", "", "git ref of the advisories repo to check (default: the working tree)")
+}
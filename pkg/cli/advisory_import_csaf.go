@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryImportCSAF() *cobra.Command {
+	p := &importCSAFParams{}
+	cmd := &cobra.Command{
+		Use:   "import-csaf <path/to/csaf.json>...",
+		Short: "Import triage decisions from CSAF VEX documents into the advisories repo",
+		Long: `Import triage decisions from CSAF VEX documents into the advisories repo.
+
+Each product status in the documents becomes an event on the advisory for the
+product's package and vulnerability, creating the advisory if it doesn't exist.
+Products are matched to packages by their APK package URLs ("purl"
+identification helpers), including products that are relationships of APKs
+(e.g. an APK installed in an image). The statuses become events as follows:
+
+  known_not_affected    false-positive-determination (typed by the VEX
+                        justification flag, with the impact statement as note)
+  fixed, first_fixed    fixed (in the version of the product's package URL)
+  known_affected,       fix-not-planned if the remediation is "no_fix_planned",
+  first_affected,       otherwise true-positive-determination
+  last_affected
+  under_investigation   detection
+
+Events are timestamped with the document's current release date. An event isn't
+added if the advisory's latest event already has the same type.`,
+		Example: `
+wolfictl adv import-csaf ./customer-triage.json
+
+wolfictl adv import-csaf ./vex/*.json -a ../advisories`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			log := clog.FromContext(ctx)
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advGetter := advisory.NewFSGetter(os.DirFS(advisoriesRepoDir))
+
+			advPutter := advisory.NewFSPutterWithAutomaticEncoder(rwos.DirFS(advisoriesRepoDir))
+
+			for _, path := range args {
+				f, err := os.Open(path)
+				if err != nil {
+					return fmt.Errorf("opening CSAF document: %w", err)
+				}
+				doc, err := advisory.DecodeCSAF(f)
+				f.Close()
+				if err != nil {
+					return fmt.Errorf("reading %q: %w", path, err)
+				}
+
+				requests, err := advisory.CSAFRequests(ctx, doc)
+				if err != nil {
+					return fmt.Errorf("translating CSAF document %q: %w", path, err)
+				}
+
+				imported := 0
+				for _, r := range requests {
+					skip, err := doesRequestRepeatEventType(ctx, advGetter, r)
+					if err != nil {
+						return fmt.Errorf("checking for redundant event type for package %q: %w", r.Package, err)
+					}
+					if skip {
+						log.Warn(
+							"skipping CSAF product status with same event type as existing advisory's latest event",
+							"package", r.Package,
+							"aliases", r.Aliases,
+							"eventType", r.Event.Type,
+						)
+						continue
+					}
+
+					if _, err := advPutter.Upsert(ctx, r); err != nil {
+						return fmt.Errorf("importing advisory data for %q (%v): %w", r.Package, r.Aliases, err)
+					}
+					imported++
+				}
+
+				log.Info("imported CSAF document", "path", path, "id", doc.Document.Tracking.ID, "events", imported, "skipped", len(requests)-imported)
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type importCSAFParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+}
+
+func (p *importCSAFParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+}
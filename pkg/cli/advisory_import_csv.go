@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryImportCSV() *cobra.Command {
+	p := &importCSVParams{}
+	cmd := &cobra.Command{
+		Use:   "import-csv <path/to/advisories.csv>...",
+		Short: "Import CSV advisory data into the advisories repo",
+		Long: `Import CSV advisory data into the advisories repo.
+
+This is the reverse of "wolfictl adv export -f csv". It's useful for merging
+triage work done in a spreadsheet back into the advisory documents.
+
+Each row is an event of an advisory, with the same columns as the CSV export:
+
+  package, advisory_id, event_timestamp, event_type, false_positive_type,
+  note, fixed_version
+
+The columns can be in any order, and other columns are ignored. To create new
+advisories, leave advisory_id empty and add an aliases column with the
+vulnerability's IDs, separated by spaces. Rows without an event_timestamp are
+timestamped with --timestamp.
+
+All rows are validated before anything is imported, and invalid rows are
+reported by line number.
+
+Rows whose event is already in the advisory (the same type at the same time)
+are skipped, so an edited export can be imported as a whole. A row conflicts
+with an advisory when it contradicts the advisory's latest event, e.g. it's
+fixed in a different version. Conflicting rows, and rows for advisory IDs that
+don't exist, aren't imported, and are reported as errors. Use --force to
+import conflicting rows anyway.`,
+		Example: `
+wolfictl adv export -f csv -o advisories.csv
+# (edit advisories.csv in a spreadsheet)
+wolfictl adv import-csv ./advisories.csv`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			log := clog.FromContext(ctx)
+
+			timestamp := v2.Now()
+			if p.timestamp != "now" {
+				t, err := time.Parse(time.RFC3339, p.timestamp)
+				if err != nil {
+					return fmt.Errorf("unable to parse timestamp: %w", err)
+				}
+				timestamp = v2.Timestamp(t)
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			// Validate every file before importing any of them.
+			requestsByPath := make(map[string][]advisory.Request)
+			for _, path := range args {
+				f, err := os.Open(path)
+				if err != nil {
+					return fmt.Errorf("reading CSV advisory data: %w", err)
+				}
+				requests, err := advisory.CSVRequests(f, timestamp)
+				f.Close()
+				if err != nil {
+					return fmt.Errorf("invalid CSV advisory data in %q:\n%w", path, err)
+				}
+				requestsByPath[path] = requests
+			}
+
+			opts := advisory.ImportOptions{
+				Getter: advisory.NewFSGetter(os.DirFS(advisoriesRepoDir)),
+				Putter: advisory.NewFSPutterWithAutomaticEncoder(rwos.DirFS(advisoriesRepoDir)),
+				Force:  p.force,
+			}
+
+			var conflicts []error
+			for _, path := range args {
+				requests := requestsByPath[path]
+
+				result, err := advisory.ImportCSVRequests(ctx, requests, opts)
+				if err != nil {
+					var conflictErr *advisory.ConflictError
+					var unknownErr *advisory.UnknownAdvisoryError
+					if !errors.As(err, &conflictErr) && !errors.As(err, &unknownErr) {
+						return fmt.Errorf("importing CSV advisory data %q: %w", path, err)
+					}
+					conflicts = append(conflicts, err)
+				}
+
+				log.Info("imported CSV advisory data", "path", path, "rows", len(requests), "created", result.Created, "updated", result.Updated, "skipped", result.Skipped)
+			}
+
+			if len(conflicts) > 0 {
+				return fmt.Errorf("some rows weren't imported:\n%w", errors.Join(conflicts...))
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type importCSVParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	force             bool
+	timestamp         string
+}
+
+func (p *importCSVParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().BoolVar(&p.force, "force", false, "import rows that conflict with the latest event of an existing advisory")
+	cmd.Flags().StringVar(&p.timestamp, "timestamp", "now", "timestamp of the imported events that have none (RFC3339 format)")
+}
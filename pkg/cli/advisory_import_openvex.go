@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/openvex/go-vex/pkg/vex"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryImportOpenVEX() *cobra.Command {
+	p := &importOpenVEXParams{}
+	cmd := &cobra.Command{
+		Use:   "import-openvex <path/to/document.openvex.json>...",
+		Short: "Import OpenVEX statements into the advisories repo",
+		Long: `Import OpenVEX statements into the advisories repo.
+
+Each statement becomes an event on the advisory for each of its products'
+packages and its vulnerability, creating the advisory if it doesn't exist.
+Products are matched to packages by their APK package URLs; for a product that
+isn't an APK (e.g. a container image), its APK subcomponents are used. The
+statuses become events as follows:
+
+  not_affected          false-positive-determination (typed by the
+                        justification, with the impact statement as note)
+  fixed                 fixed (in the version of the product's package URL)
+  affected              true-positive-determination
+  under_investigation   detection
+
+Statements whose event is the same as the advisory's latest event are skipped.
+A statement conflicts with an advisory when its status contradicts the
+advisory's latest event, e.g. "not_affected" for an advisory that's fixed, or
+"fixed" in a different version. Conflicting statements aren't imported, and are
+reported as errors, unless --force is used.`,
+		Example: `
+wolfictl adv import-openvex ./triage.openvex.json
+
+wolfictl adv import-openvex ./vex/*.json -a ../advisories --force`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			log := clog.FromContext(ctx)
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			opts := advisory.ImportOptions{
+				Getter: advisory.NewFSGetter(os.DirFS(advisoriesRepoDir)),
+				Putter: advisory.NewFSPutterWithAutomaticEncoder(rwos.DirFS(advisoriesRepoDir)),
+				Force:  p.force,
+			}
+
+			var conflicts []error
+			for _, path := range args {
+				b, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("reading OpenVEX document: %w", err)
+				}
+				doc, err := vex.Parse(b)
+				if err != nil {
+					return fmt.Errorf("parsing OpenVEX document %q: %w", path, err)
+				}
+
+				requests, err := advisory.OpenVEXRequests(ctx, doc)
+				if err != nil {
+					return fmt.Errorf("translating OpenVEX document %q: %w", path, err)
+				}
+
+				result, err := advisory.ImportRequests(ctx, requests, opts)
+				if err != nil {
+					var conflictErr *advisory.ConflictError
+					if !errors.As(err, &conflictErr) {
+						return fmt.Errorf("importing OpenVEX document %q: %w", path, err)
+					}
+					conflicts = append(conflicts, err)
+				}
+
+				log.Info("imported OpenVEX document", "path", path, "id", doc.ID, "created", result.Created, "updated", result.Updated, "skipped", result.Skipped)
+			}
+
+			if len(conflicts) > 0 {
+				return fmt.Errorf("some statements weren't imported (use --force to import them anyway):\n%w", errors.Join(conflicts...))
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type importOpenVEXParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	force             bool
+}
+
+func (p *importOpenVEXParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().BoolVar(&p.force, "force", false, "import statements that conflict with the latest event of an existing advisory")
+}
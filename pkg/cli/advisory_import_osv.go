@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryImportOSV() *cobra.Command {
+	p := &importOSVParams{}
+	cmd := &cobra.Command{
+		Use:   "import-osv <path/to/record.json>...",
+		Short: "Import OSV vulnerability records into the advisories repo",
+		Long: `Import OSV vulnerability records into the advisories repo.
+
+This is useful for bootstrapping the advisory data of packages forked from
+another distro, using the OSV records it publishes (e.g. on osv.dev). Each file
+is a single OSV record or a JSON array of them. A directory is read as all of
+its ".json" files, e.g. an extracted osv.dev ecosystem archive.
+
+Each record becomes an event on the advisory for each of its affected packages
+and its vulnerability, creating the advisory if it doesn't exist. Advisories are
+matched by the record's CVE, GHSA, and Go vulnerability IDs, and records without
+any are skipped. The event is derived from the affected package's ECOSYSTEM
+ranges, and is timestamped with the record's modification time:
+
+  fixed at version "0"   false-positive-determination (no version was affected)
+  fixed                  fixed (in the latest fixed version)
+  not fixed              true-positive-determination
+
+Use --ecosystem to only import the records' affected packages in that OSV
+ecosystem (e.g. "Alpine", which also matches "Alpine:v3.20"), and --package to
+only import the given packages.
+
+Records whose event is the same as the advisory's latest event are skipped. A
+record conflicts with an advisory when its event contradicts the advisory's
+latest event, e.g. it's fixed in a different version. Conflicting records aren't
+imported, and are reported as errors, unless --force is used.`,
+		Example: `
+wolfictl adv import-osv ./CVE-2024-1234.json
+
+unzip all.zip -d alpine-osv
+wolfictl adv import-osv ./alpine-osv --ecosystem Alpine -p curl -p openssl -a ../advisories`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			log := clog.FromContext(ctx)
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			var vulnerabilities []models.Vulnerability
+			for _, path := range args {
+				records, err := readOSVRecords(path)
+				if err != nil {
+					return err
+				}
+				vulnerabilities = append(vulnerabilities, records...)
+			}
+
+			requests, err := advisory.OSVRequests(ctx, vulnerabilities, advisory.ImportOSVOptions{
+				Ecosystem: p.ecosystem,
+				Packages:  p.packages,
+			})
+			if err != nil {
+				return fmt.Errorf("translating OSV records: %w", err)
+			}
+
+			result, err := advisory.ImportRequests(ctx, requests, advisory.ImportOptions{
+				Getter: advisory.NewFSGetter(os.DirFS(advisoriesRepoDir)),
+				Putter: advisory.NewFSPutterWithAutomaticEncoder(rwos.DirFS(advisoriesRepoDir)),
+				Force:  p.force,
+			})
+			if err != nil {
+				var conflictErr *advisory.ConflictError
+				if !errors.As(err, &conflictErr) {
+					return fmt.Errorf("importing OSV records: %w", err)
+				}
+				return fmt.Errorf("some records weren't imported (use --force to import them anyway):\n%w", err)
+			}
+
+			log.Info("imported OSV records", "records", len(vulnerabilities), "created", result.Created, "updated", result.Updated, "skipped", result.Skipped)
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type importOSVParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	ecosystem         string
+	packages          []string
+	force             bool
+}
+
+func (p *importOSVParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addMultiPackageFlag(&p.packages, cmd)
+	cmd.Flags().StringVar(&p.ecosystem, "ecosystem", "", "only import affected packages in this OSV ecosystem (default: any ecosystem)")
+	cmd.Flags().BoolVar(&p.force, "force", false, "import records that conflict with the latest event of an existing advisory")
+}
+
+// readOSVRecords reads the OSV records in the file at path, or in the ".json"
+// files in the directory at path.
+func readOSVRecords(path string) ([]models.Vulnerability, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading OSV records: %w", err)
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading OSV records: %w", err)
+		}
+
+		paths = nil
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+				continue
+			}
+			paths = append(paths, filepath.Join(path, entry.Name()))
+		}
+	}
+
+	var vulnerabilities []models.Vulnerability
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading OSV records: %w", err)
+		}
+
+		records, err := advisory.DecodeOSVRecords(b)
+		if err != nil {
+			return nil, fmt.Errorf("parsing OSV records in %q: %w", p, err)
+		}
+		vulnerabilities = append(vulnerabilities, records...)
+	}
+
+	return vulnerabilities, nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+func TestAdvisoryImportOSV(t *testing.T) {
+	ctx := context.Background()
+
+	advisoriesDir := t.TempDir()
+	recordsDir := t.TempDir()
+	for name, record := range map[string]string{
+		"GHSA-2222-3333-4444.json": `{
+  "id": "GHSA-2222-3333-4444",
+  "aliases": ["CVE-2024-1234"],
+  "modified": "2024-05-03T00:00:00Z",
+  "affected": [{
+    "package": {"ecosystem": "Alpine:v3.20", "name": "curl"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "8.6.0-r0"}]}]
+  }]
+}`,
+		"CVE-2024-5678.json": `{
+  "id": "CVE-2024-5678",
+  "modified": "2024-05-04T00:00:00Z",
+  "affected": [{
+    "package": {"ecosystem": "Alpine:v3.20", "name": "openssl"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "3.3.1-r0"}]}]
+  }]
+}`,
+		"README.md": "not a record",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(recordsDir, name), []byte(record), 0o600))
+	}
+
+	cmd := cmdAdvisoryImportOSV()
+	cmd.SetArgs([]string{recordsDir, "--no-distro-detection", "-a", advisoriesDir, "--ecosystem", "Alpine", "-p", "curl"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	require.NoError(t, cmd.ExecuteContext(ctx))
+
+	index, err := adv2.NewIndex(ctx, rwos.DirFS(advisoriesDir))
+	require.NoError(t, err)
+	docs := index.Select().Configurations()
+	require.Len(t, docs, 1)
+	assert.Equal(t, "curl", docs[0].Name())
+	require.NoError(t, docs[0].Validate())
+
+	adv, ok := docs[0].Advisories.GetByVulnerability("CVE-2024-1234")
+	require.True(t, ok)
+	assert.Equal(t, []string{"CVE-2024-1234", "GHSA-2222-3333-4444"}, adv.Aliases)
+}
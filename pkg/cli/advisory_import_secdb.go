@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory/secdb"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryImportSecDB() *cobra.Command {
+	p := &importSecDBParams{}
+	cmd := &cobra.Command{
+		Use:   "import-secdb <path/to/security.json>...",
+		Short: "Import an Alpine-style security database into the advisories repo",
+		Long: `Import an Alpine-style security database into the advisories repo.
+
+This is the reverse of "wolfictl adv secdb". It's useful for onboarding a distro
+whose only machine-readable security history is its security database.
+
+Each vulnerability in a package's secfixes becomes an event on the package's
+advisory for the vulnerability, creating the advisory if it doesn't exist:
+
+  <version>   fixed (in that version)
+  0           false-positive-determination (vulnerable code not included in
+              package)
+
+An entry can list several IDs separated by spaces, which become the aliases of
+one advisory. If a vulnerability is listed under several versions, the highest
+version is used.
+
+The database doesn't record when vulnerabilities were fixed, so all events are
+timestamped with --timestamp.
+
+Entries whose event is the same as the advisory's latest event are skipped. An
+entry conflicts with an advisory when it contradicts the advisory's latest
+event, e.g. it's fixed in a different version. Conflicting entries aren't
+imported, and are reported as errors, unless --force is used.`,
+		Example: `
+wolfictl adv import-secdb ./security.json
+
+curl -sSfL https://secdb.alpinelinux.org/v3.20/main.json -o main.json
+wolfictl adv import-secdb ./main.json -a ../advisories --timestamp 2024-05-22T00:00:00Z`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			log := clog.FromContext(ctx)
+
+			timestamp := v2.Now()
+			if p.timestamp != "now" {
+				t, err := time.Parse(time.RFC3339, p.timestamp)
+				if err != nil {
+					return fmt.Errorf("unable to parse timestamp: %w", err)
+				}
+				timestamp = v2.Timestamp(t)
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			opts := advisory.ImportOptions{
+				Getter: advisory.NewFSGetter(os.DirFS(advisoriesRepoDir)),
+				Putter: advisory.NewFSPutterWithAutomaticEncoder(rwos.DirFS(advisoriesRepoDir)),
+				Force:  p.force,
+			}
+
+			var conflicts []error
+			for _, path := range args {
+				b, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("reading security database: %w", err)
+				}
+				var db secdb.Database
+				if err := json.Unmarshal(b, &db); err != nil {
+					return fmt.Errorf("parsing security database %q: %w", path, err)
+				}
+
+				requests, err := advisory.SecurityDatabaseRequests(ctx, db, timestamp)
+				if err != nil {
+					return fmt.Errorf("translating security database %q: %w", path, err)
+				}
+
+				result, err := advisory.ImportRequests(ctx, requests, opts)
+				if err != nil {
+					var conflictErr *advisory.ConflictError
+					if !errors.As(err, &conflictErr) {
+						return fmt.Errorf("importing security database %q: %w", path, err)
+					}
+					conflicts = append(conflicts, err)
+				}
+
+				log.Info("imported security database", "path", path, "packages", len(db.Packages), "created", result.Created, "updated", result.Updated, "skipped", result.Skipped)
+			}
+
+			if len(conflicts) > 0 {
+				return fmt.Errorf("some entries weren't imported (use --force to import them anyway):\n%w", errors.Join(conflicts...))
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type importSecDBParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	force             bool
+	timestamp         string
+}
+
+func (p *importSecDBParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().BoolVar(&p.force, "force", false, "import entries that conflict with the latest event of an existing advisory")
+	cmd.Flags().StringVar(&p.timestamp, "timestamp", "now", "timestamp of the imported events (RFC3339 format)")
+}
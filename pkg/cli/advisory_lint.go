@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+	"github.com/wolfi-dev/wolfictl/pkg/lint"
+	"github.com/wolfi-dev/wolfictl/pkg/lint/advisories"
+)
+
+func cmdAdvisoryLint() *cobra.Command {
+	p := &advisoryLintParams{}
+	cmd := &cobra.Command{
+		Use:   "lint [path/to/package.advisories.yaml]...",
+		Short: "Lint the formatting and structure of advisory documents",
+		Long: `Lint the formatting and structure of advisory documents.
+
+This command checks advisory documents for issues that the advisory schema
+allows, but that reviewers would otherwise need to point out:
+
+* advisories that aren't sorted by ID
+* fields that aren't in the schema's order
+* trailing whitespace
+* event timestamps that aren't in UTC
+* advisories and events missing the fields required by their type
+
+If no documents are given, all documents in the advisories repo are linted.
+
+Use --list to see all rules, and --skip-rule to skip some of them. The command
+fails only if there are error-level issues.`,
+		Example: `
+wolfictl adv lint
+
+wolfictl adv lint ./glibc.advisories.yaml --severity error`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if p.list {
+				advisories.New().PrintRules(ctx)
+				return nil
+			}
+
+			paths := args
+			if len(paths) == 0 {
+				advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+				if advisoriesRepoDir == "" {
+					if p.doNotDetectDistro {
+						return fmt.Errorf("no advisories repo dir specified")
+					}
+
+					d, err := distro.Detect()
+					if err != nil {
+						return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+					}
+
+					advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+					_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+				}
+				paths = []string{advisoriesRepoDir}
+			}
+
+			var result lint.Result
+			for _, path := range paths {
+				linter := advisories.New(lint.WithPath(path), lint.WithSkipRules(p.skipRules))
+				r, err := linter.Lint(ctx, parseLintSeverity(p.severity))
+				if err != nil {
+					return err
+				}
+				result = append(result, r...)
+			}
+
+			advisories.New().Print(ctx, result)
+
+			// only count errors as failures, not warnings.
+			if lintResultFailed(result) {
+				return errors.New("linting failed")
+			}
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type advisoryLintParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	list              bool
+	skipRules         []string
+	severity          string
+}
+
+func (p *advisoryLintParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().BoolVarP(&p.list, "list", "l", false, "prints all of the available rules and exits")
+	cmd.Flags().StringArrayVarP(&p.skipRules, "skip-rule", "", []string{}, "list of rules to skip")
+	cmd.Flags().StringVarP(&p.severity, "severity", "s", "warning", "minimum severity level to report (error, warning, info)")
+}
@@ -158,7 +158,12 @@ flag. This will report just the count, not the full list of advisories.
 				updatedBefore = &ts
 			}
 
-			var getter advisory.Getter = advisory.NewFSGetter(os.DirFS(p.advisoriesRepoDir))
+			advisoriesFsys, err := advisoriesFSAsOf(ctx, p.advisoriesRepoDir, p.asOf)
+			if err != nil {
+				return err
+			}
+
+			var getter advisory.Getter = advisory.NewFSGetter(advisoriesFsys)
 
 			var packageNames []string
 			if p.packageName != "" {
@@ -304,6 +309,7 @@ flag. This will report just the count, not the full list of advisories.
 
 type listParams struct {
 	advisoriesRepoDir string
+	asOf              string
 
 	packageName   string
 	vuln          string
@@ -325,6 +331,7 @@ var validAdvListOutputFormats = []string{outputFormatTable, outputFormatJSON}
 
 func (p *listParams) addFlagsTo(cmd *cobra.Command) {
 	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addAsOfFlag(&p.asOf, cmd)
 
 	addPackageFlag(&p.packageName, cmd)
 	addVulnFlag(&p.vuln, cmd)
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+)
+
+func cmdAdvisoryMerge() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge BASE OURS THEIRS [PATH]",
+		Short: "Merge concurrent changes to an advisory document (for use as a git merge driver)",
+		Long: `Merge concurrent changes to an advisory document.
+
+This command is meant to be used as a git merge driver, so that concurrent
+changes to the same advisory document are merged by their contents instead of
+their text, which would otherwise often conflict, e.g. when both sides of a
+merge add an event to the end of the same advisory.
+
+BASE, OURS and THEIRS are the paths of the common ancestor's version of the
+document, our version and their version. The merged document is written to
+OURS. PATH, the path of the document in the repo, is only used in messages.
+
+The merged document has the advisories of both sides, and for advisories on
+both sides, the aliases and events of both sides, with the events ordered by
+timestamp. Advisories, aliases and events removed on one side are removed, as
+long as the other side didn't change them. If an advisory was removed on one
+side and changed on the other, or the merged document wouldn't be valid (e.g.
+both sides added an advisory for the same vulnerability, under different
+advisory IDs), the merge fails and OURS is left unchanged, so that git reports
+a conflict.
+
+To use this command as the merge driver of an advisories repo, run this in the
+repo:
+
+  git config merge.wolfictl-advisory.name "wolfictl advisory merge driver"
+  git config merge.wolfictl-advisory.driver "wolfictl advisory merge %O %A %B %P"
+  echo '*.advisories.yaml merge=wolfictl-advisory' >> .gitattributes
+
+The merged document is formatted using the repo's yam configuration, if it's
+found in the current directory, which git sets to the root of the repo.`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.RangeArgs(3, 4),
+		RunE: func(_ *cobra.Command, args []string) error {
+			basePath, oursPath, theirsPath := args[0], args[1], args[2]
+
+			name := oursPath
+			if len(args) == 4 {
+				name = args[3]
+			}
+
+			base, err := readMergeDocument(basePath)
+			if err != nil {
+				return err
+			}
+			ours, err := readMergeDocument(oursPath)
+			if err != nil {
+				return err
+			}
+			theirs, err := readMergeDocument(theirsPath)
+			if err != nil {
+				return err
+			}
+
+			merged, err := advisory.MergeDocuments(base, ours, theirs)
+			if err != nil {
+				return fmt.Errorf("unable to merge %s: %w", name, err)
+			}
+
+			buf := new(bytes.Buffer)
+			enc := advisory.NewAutomaticYamDocumentEncoder(os.DirFS("."))
+			if err := enc(buf, merged); err != nil {
+				return fmt.Errorf("unable to encode merged %s: %w", name, err)
+			}
+
+			if err := os.WriteFile(oursPath, buf.Bytes(), 0o644); err != nil {
+				return fmt.Errorf("unable to write merged %s: %w", name, err)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// readMergeDocument reads an advisory document given to the merge driver. An
+// empty file, which git uses for a missing common ancestor, is read as the zero
+// Document.
+func readMergeDocument(path string) (v2.Document, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return v2.Document{}, fmt.Errorf("unable to read %q: %w", path, err)
+	}
+
+	if len(bytes.TrimSpace(b)) == 0 {
+		return v2.Document{}, nil
+	}
+
+	doc, err := v2.DecodeDocument(bytes.NewReader(b))
+	if err != nil {
+		return v2.Document{}, fmt.Errorf("unable to decode advisory document %q: %w", path, err)
+	}
+
+	return *doc, nil
+}
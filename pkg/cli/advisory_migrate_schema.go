@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/advisory/migrate"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryMigrateSchema() *cobra.Command {
+	p := &migrateSchemaParams{}
+	cmd := &cobra.Command{
+		Use:   "migrate-schema",
+		Short: "Upgrade advisory documents to a later schema version",
+		Long: fmt.Sprintf(`Upgrade advisory documents to a later schema version.
+
+This command upgrades every advisory document in the advisories repo from its
+schema version to the target schema version (by default, %s, the latest
+schema version known to this version of wolfictl), one schema version at a
+time. Each step applies the transforms needed for that schema version's
+changes, and sets the document's schema version.
+
+Documents that are already at the target version are left as they are. If any
+document can't be migrated (e.g. its schema version is too old), no documents
+are changed.
+
+Use --dry-run to print the changes without writing them.`, v2.SchemaVersion),
+		Example: `
+wolfictl adv migrate-schema --dry-run
+
+wolfictl adv migrate-schema -a ../advisories --to 2.0.1`,
+		Deprecated:    advisoryDeprecationMessage,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			report, err := migrate.Run(cmd.Context(), migrate.Options{
+				FS:     rwos.DirFS(advisoriesRepoDir),
+				Target: p.to,
+				DryRun: p.dryRun,
+			})
+			if err != nil {
+				return fmt.Errorf("migrating advisory documents: %w", err)
+			}
+
+			for _, doc := range report.Migrated {
+				fmt.Printf("%s: %s -> %s\n", styles.Bold().Render(doc.Path), doc.From, doc.To)
+				for _, c := range doc.Changes {
+					fmt.Printf("  [%s] %s: %s\n", c.Migration, c.Rule, c.Description)
+				}
+			}
+
+			if p.dryRun {
+				fmt.Fprintf(os.Stderr, "\n%d documents would be migrated, %d are already up to date (dry run).\n", len(report.Migrated), report.Unchanged)
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "\n%d documents migrated, %d already up to date.\n", len(report.Migrated), report.Unchanged)
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type migrateSchemaParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	to                string
+	dryRun            bool
+}
+
+func (p *migrateSchemaParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().StringVar(&p.to, "to", "", fmt.Sprintf("schema version to migrate the documents to (default %q)", v2.SchemaVersion))
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "print the changes without writing them")
+}
@@ -2,13 +2,13 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
 	"chainguard.dev/melange/pkg/config"
 	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
 	"github.com/spf13/cobra"
 	"github.com/wolfi-dev/wolfictl/pkg/advisory"
 	"github.com/wolfi-dev/wolfictl/pkg/configs"
-	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
 	"github.com/wolfi-dev/wolfictl/pkg/configs/build"
 	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
 )
@@ -37,6 +37,11 @@ and subpackages.
 
 The output directory for the OSV dataset is specified using the --output flag. This
 directory must already exist before running the command.
+
+With --derive-ranges, the affected version ranges of fixed advisories are derived from
+the git history of the package repositories: an advisory that was reopened after a fix
+(e.g. for a regression) gets a range for each time it was affected, rather than only
+"fixed in" its latest fixed version.
 `,
 		SilenceErrors: true,
 		Args:          cobra.NoArgs,
@@ -55,10 +60,13 @@ directory must already exist before running the command.
 				return fmt.Errorf("output directory must be specified")
 			}
 
+			if err := p.validate(p.outputDirectory); err != nil {
+				return err
+			}
+
 			advisoryIndices := make([]*configs.Index[v2.Document], 0, len(p.advisoriesRepoDirs))
 			for _, dir := range p.advisoriesRepoDirs {
-				fsys := rwos.DirFS(dir)
-				index, err := adv2.NewIndex(cmd.Context(), fsys)
+				index, err := advisory.IndexWithArchive(cmd.Context(), os.DirFS(dir))
 				if err != nil {
 					return fmt.Errorf("indexing advisory documents for directory %q: %w", dir, err)
 				}
@@ -93,12 +101,24 @@ directory must already exist before running the command.
 				OutputDirectory:      p.outputDirectory,
 			}
 
+			if p.deriveRanges {
+				var timelines advisory.MultiPackageVersionTimeline
+				for _, dir := range p.packagesRepoDirs {
+					timeline, err := advisory.NewGitPackageVersionHistory(dir)
+					if err != nil {
+						return fmt.Errorf("unable to read package version history from package repository %q: %w", dir, err)
+					}
+					timelines = append(timelines, timeline)
+				}
+				opts.PackageVersionTimeline = timelines
+			}
+
 			err := advisory.BuildOSVDataset(ctx, opts)
 			if err != nil {
 				return fmt.Errorf("building OSV dataset: %w", err)
 			}
 
-			return nil
+			return p.signOutput(ctx, p.outputDirectory)
 		},
 	}
 
@@ -107,13 +127,19 @@ directory must already exist before running the command.
 }
 
 type osvParams struct {
+	signParams
+
 	advisoriesRepoDirs []string
 	packagesRepoDirs   []string
 	outputDirectory    string
+	deriveRanges       bool
 }
 
 func (p *osvParams) addFlagsTo(cmd *cobra.Command) {
 	cmd.Flags().StringSliceVarP(&p.advisoriesRepoDirs, "advisories-repo-dir", "a", nil, "path to the directory(ies) containing Chainguard advisory data")
 	cmd.Flags().StringSliceVarP(&p.packagesRepoDirs, "packages-repo-dir", "p", nil, "path to the directory(ies) containing Chainguard package data")
 	cmd.Flags().StringVarP(&p.outputDirectory, "output", "o", "", "path to a local directory in which the OSV dataset will be written")
+	addDeriveRangesFlag(&p.deriveRanges, cmd)
+
+	p.signParams.addFlagsTo(cmd)
 }
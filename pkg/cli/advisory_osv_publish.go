@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryOSVPublish() *cobra.Command {
+	p := &osvPublishParams{}
+	cmd := &cobra.Command{
+		Use:   "osv-publish",
+		Short: "Validate the OSV export of the advisory data and publish the changes for osv.dev",
+		Long: `Validate the OSV export of the advisory data and publish the changes for osv.dev.
+
+osv.dev imports the OSV records of a data source from a GCS bucket that the
+source owns. This command exports the advisory data as OSV records (as in
+"wolfictl adv export -f osv"), and then:
+
+  1. Validates the records against osv.dev's requirements: IDs with the
+     source's prefix (--id-prefix), a modified time that isn't in the future,
+     and affected packages whose ranges start with an introduced event.
+
+  2. Compares the records to those osv.dev has published for the ecosystem
+     (--published, by default the ecosystem's all.zip archive on osv.dev). A
+     record whose content changed must have a later modified time, or osv.dev
+     would ignore the change. Published records that are no longer exported are
+     withdrawn, since osv.dev doesn't delete records.
+
+  3. Uploads the added, updated, and withdrawn records to the bucket (--bucket),
+     as <ID>.json.
+
+Use --dry-run to only validate and compare the records. Use --published="" for
+an ecosystem that osv.dev hasn't published any records for yet.`,
+		Example: `
+wolfictl adv osv-publish --dry-run
+
+wolfictl adv osv-publish --bucket gs://my-osv-records/advisories --derive-ranges`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if !p.dryRun && !strings.HasPrefix(p.bucket, "gs://") {
+				return fmt.Errorf("a GCS bucket (--bucket gs://<bucket>[/<path>]) is required unless --dry-run is used")
+			}
+
+			distroRepoDir := resolveDistroDir(p.distroRepoDir)
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			ecosystem := p.ecosystem
+			if advisoriesRepoDir == "" || ecosystem == "" || (p.deriveRanges && distroRepoDir == "") {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("distro repo dir, advisories repo dir, and/or ecosystem was left unspecified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("distro repo dir, advisories repo dir, and/or ecosystem was left unspecified, and distro auto-detection failed: %w", err)
+				}
+
+				if distroRepoDir == "" {
+					distroRepoDir = d.Local.PackagesRepo.Dir
+				}
+				if advisoriesRepoDir == "" {
+					advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				}
+				if ecosystem == "" {
+					ecosystem = d.Absolute.Name
+				}
+
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := advisory.IndexWithArchive(ctx, os.DirFS(advisoriesRepoDir))
+			if err != nil {
+				return fmt.Errorf("unable to create index of advisories repo: %w", err)
+			}
+
+			opts := advisory.ExportOptions{
+				AdvisoryDocIndices: []*configs.Index[v2.Document]{advisoryDocs},
+				Ecosystem:          ecosystem,
+			}
+			if p.deriveRanges {
+				timeline, err := advisory.NewGitPackageVersionHistory(distroRepoDir)
+				if err != nil {
+					return fmt.Errorf("unable to read package version history from distro repo: %w", err)
+				}
+				opts.PackageVersionTimeline = timeline
+			}
+
+			exported, err := advisory.ExportOSV(ctx, opts)
+			if err != nil {
+				return fmt.Errorf("unable to export advisory data: %w", err)
+			}
+
+			now := time.Now().UTC().Truncate(time.Second)
+			if err := advisory.ValidateOSVSubmission(exported, p.idPrefix, now); err != nil {
+				return fmt.Errorf("OSV records don't meet osv.dev's requirements:\n%w", err)
+			}
+
+			published := p.published
+			if !cmd.Flags().Changed(flagNameOSVPublished) {
+				published = advisory.OSVPublishedRecordsURL(ecosystem)
+			}
+
+			var publishedRecords []models.Vulnerability
+			if published != "" {
+				if err := readURLOrFile(ctx, published, func(r io.Reader) error {
+					b, err := io.ReadAll(r)
+					if err != nil {
+						return err
+					}
+					publishedRecords, err = advisory.ReadOSVZip(bytes.NewReader(b), int64(len(b)))
+					return err
+				}); err != nil {
+					return fmt.Errorf("reading published OSV records: %w", err)
+				}
+			}
+
+			diff, err := advisory.DiffOSVRecords(exported, publishedRecords, now)
+			if err != nil {
+				return err
+			}
+
+			renderOSVRecordsDiff(os.Stdout, diff)
+
+			if err := diff.Validate(); err != nil {
+				return fmt.Errorf("OSV records don't meet osv.dev's requirements:\n%w", err)
+			}
+
+			if p.dryRun {
+				fmt.Fprintf(os.Stderr, "\n%d records would be uploaded (dry run).\n", len(diff.Changed()))
+				return nil
+			}
+
+			changed := diff.Changed()
+			if len(changed) == 0 {
+				return nil
+			}
+
+			bucket, prefix, _ := strings.Cut(strings.TrimPrefix(p.bucket, "gs://"), "/")
+			client, err := storage.NewClient(ctx)
+			if err != nil {
+				return fmt.Errorf("creating GCS client: %w", err)
+			}
+			defer client.Close()
+
+			for i := range changed {
+				v := changed[i]
+
+				b, err := json.MarshalIndent(v, "", "  ")
+				if err != nil {
+					return fmt.Errorf("encoding OSV vulnerability %q to JSON: %w", v.ID, err)
+				}
+
+				object := path.Join(prefix, v.ID+".json")
+				w := client.Bucket(bucket).Object(object).NewWriter(ctx)
+				w.ContentType = "application/json"
+				if _, err := w.Write(append(b, '\n')); err != nil {
+					w.Close()
+					return fmt.Errorf("uploading gs://%s/%s: %w", bucket, object, err)
+				}
+				if err := w.Close(); err != nil {
+					return fmt.Errorf("uploading gs://%s/%s: %w", bucket, object, err)
+				}
+			}
+
+			fmt.Fprintf(os.Stderr, "\n%d records uploaded to %s.\n", len(changed), p.bucket)
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type osvPublishParams struct {
+	doNotDetectDistro bool
+	distroRepoDir     string
+	advisoriesRepoDir string
+	ecosystem         string
+	idPrefix          string
+	published         string
+	bucket            string
+	deriveRanges      bool
+	dryRun            bool
+}
+
+const flagNameOSVPublished = "published"
+
+func (p *osvPublishParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addDistroDirFlag(&p.distroRepoDir, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	cmd.Flags().StringVar(&p.ecosystem, "ecosystem", "", "OSV ecosystem of the packages (default: the name of the detected distro)")
+	cmd.Flags().StringVar(&p.idPrefix, "id-prefix", "CGA-", "prefix that osv.dev requires the IDs of the records to have")
+	cmd.Flags().StringVar(&p.published, flagNameOSVPublished, "", "URL or path of a zip archive of the published OSV records (default: the ecosystem's all.zip on osv.dev)")
+	cmd.Flags().StringVar(&p.bucket, "bucket", "", "GCS bucket and optional path to upload the records to, as gs://<bucket>[/<path>]")
+	addDeriveRangesFlag(&p.deriveRanges, cmd)
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "validate and compare the records without uploading them")
+}
+
+func renderOSVRecordsDiff(w io.Writer, diff advisory.OSVRecordsDiff) {
+	for _, v := range diff.Added {
+		fprintAddedf(w, "+ %s", v.ID)
+	}
+	for _, v := range diff.Updated {
+		fprintModifiedf(w, "~ %s", v.ID)
+	}
+	for _, v := range diff.Withdrawn {
+		fprintRemovedf(w, "- %s (withdrawn)", v.ID)
+	}
+
+	fmt.Fprintf(
+		w,
+		"\n%d added, %d updated, %d withdrawn, %d unchanged\n",
+		len(diff.Added), len(diff.Updated), len(diff.Withdrawn), diff.Unchanged,
+	)
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"chainguard.dev/apko/pkg/apk/client"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/build"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryResolveWithdrawn() *cobra.Command {
+	p := &resolveWithdrawnParams{}
+	cmd := &cobra.Command{
+		Use:   "resolve-withdrawn [<package>...]",
+		Short: "Add fix-not-planned events to the open advisories of withdrawn packages",
+		Long: fmt.Sprintf(`Add fix-not-planned events to the open advisories of withdrawn packages.
+
+A package that's no longer provided by the distro won't receive fixes, so its
+open advisories (those whose latest event isn't a fix, a false positive
+determination, or a fix-not-planned event) are resolved with a fix-not-planned
+event with the note:
+
+  %s
+
+This keeps the advisory data, and the security database built from it,
+consistent with the packages the distro actually provides.
+
+The withdrawn packages can be given as arguments. Otherwise, they're detected:
+a package is withdrawn when it has an advisory document, but neither a build
+configuration in the distro repo nor an entry in the APKINDEX (e.g. after
+"wolfictl withdraw"). Advisory validation allows adding these events to the
+documents of packages that no longer exist.
+
+Use --dry-run to see which advisories would be resolved without changing
+anything.`, advisory.WithdrawnPackageNote),
+		Example: `
+wolfictl adv resolve-withdrawn --dry-run
+
+wolfictl adv resolve-withdrawn -a ../advisories some-old-package`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			detect := len(args) == 0
+			packageRepositoryURL := p.packageRepositoryURL
+
+			distroRepoDir := resolveDistroDir(p.distroRepoDir)
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" || (detect && (distroRepoDir == "" || packageRepositoryURL == "")) {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("distro repo dir, advisories repo dir, and/or package repo URL was left unspecified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("distro repo dir, advisories repo dir, and/or package repo URL was left unspecified, and distro auto-detection failed: %w", err)
+				}
+
+				if distroRepoDir == "" {
+					distroRepoDir = d.Local.PackagesRepo.Dir
+				}
+				if advisoriesRepoDir == "" {
+					advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				}
+				if packageRepositoryURL == "" {
+					packageRepositoryURL = d.Absolute.APKRepositoryURL
+				}
+
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := adv2.NewIndex(ctx, rwos.DirFS(advisoriesRepoDir))
+			if err != nil {
+				return fmt.Errorf("unable to create index of advisories repo: %w", err)
+			}
+
+			opts := advisory.WithdrawnOptions{
+				AdvisoryDocs: advisoryDocs,
+				Packages:     args,
+			}
+
+			if detect {
+				opts.PackageConfigurations, err = build.NewIndex(ctx, rwos.DirFS(distroRepoDir))
+				if err != nil {
+					return fmt.Errorf("unable to create index of distro package configurations: %w", err)
+				}
+
+				c := client.New(http.DefaultClient)
+				opts.APKIndex, err = c.GetRemoteIndex(ctx, packageRepositoryURL, p.arch)
+				if err != nil {
+					return fmt.Errorf("unable to load APKINDEX: %w", err)
+				}
+			}
+
+			resolutions, err := advisory.FindWithdrawnPackageResolutions(ctx, opts)
+			if err != nil {
+				return err
+			}
+
+			if len(resolutions) == 0 {
+				fmt.Fprintln(os.Stderr, "No withdrawn packages have open advisories.")
+				return nil
+			}
+
+			for _, r := range resolutions {
+				if !p.dryRun {
+					err := advisory.Update(ctx, r.Request(v2.Now()), advisory.UpdateOptions{
+						AdvisoryDocs: advisoryDocs,
+					})
+					if err != nil {
+						return fmt.Errorf("resolving advisory %s for %s: %w", r.Advisory.ID, r.Package, err)
+					}
+				}
+
+				fmt.Printf(
+					"%s: %s fix not planned (was %s)\n",
+					styles.Bold().Render(r.Package),
+					styles.Bold().Render(r.Advisory.ID),
+					r.Advisory.Latest().Type,
+				)
+			}
+
+			if p.dryRun {
+				fmt.Fprintf(os.Stderr, "\n%d advisories would be resolved (dry run).\n", len(resolutions))
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type resolveWithdrawnParams struct {
+	doNotDetectDistro    bool
+	distroRepoDir        string
+	advisoriesRepoDir    string
+	packageRepositoryURL string
+	arch                 string
+	dryRun               bool
+}
+
+func (p *resolveWithdrawnParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addDistroDirFlag(&p.distroRepoDir, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addPackageRepoURLFlag(&p.packageRepositoryURL, cmd)
+	cmd.Flags().StringVar(&p.arch, "arch", "x86_64", "architecture of the APKINDEX to check for withdrawn packages")
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "print the advisories that would be resolved without changing them")
+}
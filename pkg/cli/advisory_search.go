@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+func cmdAdvisorySearch() *cobra.Command {
+	p := &searchParams{}
+	cmd := &cobra.Command{
+		Use:   "search QUERY",
+		Short: "Search advisories using a query expression",
+		Long: `Search advisories using a query expression.
+
+A query is made of comparisons of the form FIELD OP VALUE, combined using AND,
+OR, NOT and parentheses. Adjacent comparisons without an operator between them
+are combined using AND. Values containing spaces or special characters can be
+double-quoted.
+
+FIELDS
+
+  package        the name of the package
+  id             the advisory ID
+  alias          any of the advisory's aliases
+  vuln           the advisory ID or any of its aliases
+  status         the type of the advisory's latest event, e.g. "fixed"
+  fixed.version  the fixed version, if the latest event is a fixed event
+  severity       the highest CVSS severity among the advisory's CVEs
+  created        the time of the advisory's first event
+  updated        the time of the advisory's latest event
+
+OPERATORS
+
+The operators are =, !=, <, <=, > and >=. The text fields (package, id, alias
+and vuln) only support = and !=, which accept "*" wildcards. The other fields
+are compared by their natural order: fixed.version by APK version, severity by
+rank, and created and updated by time, given as a date (YYYY-MM-DD) or an RFC
+3339 timestamp.
+
+A comparison with a field that an advisory doesn't have a value for (e.g.
+fixed.version for an advisory that isn't fixed) never matches.
+
+Severity data is read from the "` + advisory.CVSSFileName + `" file of the advisories repo
+(see "wolfictl adv sync-cvss").
+
+OUTPUT FORMAT
+
+Using the --output (-o) flag, you can select the output format. By default,
+results are rendered as a "table" of the advisories' latest events. The "json"
+and "yaml" formats include the advisories' full history; "yaml" output uses
+the format of advisory documents.`,
+		Example: `
+wolfictl adv search 'package=openssl AND status=fixed AND fixed.version>=3.3.0'
+
+wolfictl adv search 'vuln=GHSA-* AND NOT (status=fixed OR status=false-positive-determination)'
+
+wolfictl adv search 'severity>=high created>=2024-01-01' -o json`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatTable
+			}
+
+			if !slices.Contains(validSearchOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validSearchOutputFormats, ", "),
+				)
+			}
+
+			q, err := advisory.ParseQuery(args[0])
+			if err != nil {
+				return fmt.Errorf("parsing query: %w", err)
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := advisory.IndexWithArchive(ctx, os.DirFS(advisoriesRepoDir))
+			if err != nil {
+				return fmt.Errorf("unable to create index of advisories repo: %w", err)
+			}
+
+			cvss, err := advisory.ReadCVSSData(os.DirFS(advisoriesRepoDir))
+			if err != nil {
+				return err
+			}
+
+			results, err := advisory.Search(advisory.SearchOptions{
+				AdvisoryDocs: advisoryDocs,
+				Query:        q,
+				CVSS:         cvss,
+			})
+			if err != nil {
+				return err
+			}
+
+			if p.count {
+				fmt.Printf("%d\n", len(results))
+				return nil
+			}
+
+			switch p.outputFormat {
+			case outputFormatTable:
+				table := &advisoryListTableRenderer{showAliases: p.showAliases}
+				for _, adv := range results {
+					table.add(adv.PackageName, adv.ID, adv.Aliases, adv.Latest(), true)
+				}
+				fmt.Printf("%s\n", table)
+
+			case outputFormatJSON:
+				if results == nil {
+					results = []v2.PackageAdvisory{}
+				}
+				if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+					return fmt.Errorf("encoding JSON: %w", err)
+				}
+
+			case outputFormatYAML:
+				if err := encodeSearchResultsYAML(os.Stdout, results); err != nil {
+					return fmt.Errorf("encoding YAML: %w", err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type searchParams struct {
+	advisoriesRepoDir string
+	doNotDetectDistro bool
+
+	showAliases  bool
+	count        bool
+	outputFormat string
+}
+
+var validSearchOutputFormats = []string{outputFormatTable, outputFormatJSON, outputFormatYAML}
+
+func (p *searchParams) addFlagsTo(cmd *cobra.Command) {
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+
+	cmd.Flags().BoolVar(&p.showAliases, "aliases", true, "show other known vulnerability IDs for each advisory")
+	cmd.Flags().BoolVar(&p.count, "count", false, "show only the count of advisories that match the query")
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validSearchOutputFormats, "|"), outputFormatTable))
+}
+
+// encodeSearchResultsYAML writes the results as advisory documents, one per
+// package, separated by "---". The results must be sorted by package.
+func encodeSearchResultsYAML(w io.Writer, results []v2.PackageAdvisory) error {
+	var docs []v2.Document
+	for _, r := range results {
+		if len(docs) == 0 || docs[len(docs)-1].Package.Name != r.PackageName {
+			docs = append(docs, v2.Document{
+				SchemaVersion: v2.SchemaVersion,
+				Package:       v2.Package{Name: r.PackageName},
+			})
+		}
+
+		adv := r.Advisory
+		adv.Events = adv.SortedEvents()
+		docs[len(docs)-1].Advisories = append(docs[len(docs)-1].Advisories, adv)
+	}
+
+	for i, doc := range docs {
+		if i != 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+
+		b, err := yaml.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("marshaling package %q: %w", doc.Package.Name, err)
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
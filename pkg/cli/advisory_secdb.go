@@ -4,29 +4,53 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 
 	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
 	"github.com/chainguard-dev/clog"
 	"github.com/spf13/cobra"
 	"github.com/wolfi-dev/wolfictl/pkg/advisory"
 	"github.com/wolfi-dev/wolfictl/pkg/configs"
-	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
-	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
 	"github.com/wolfi-dev/wolfictl/pkg/distro"
 )
 
 func cmdAdvisorySecDB() *cobra.Command {
 	p := &dbParams{}
 	cmd := &cobra.Command{
-		Use:           "secdb",
-		Aliases:       []string{"db"},
-		Short:         "Build an Alpine-style security database from advisory data",
+		Use:     "secdb",
+		Aliases: []string{"db"},
+		Short:   "Build an Alpine-style security database from advisory data",
+		Long: `Build an Alpine-style security database from advisory data.
+
+By default, the security database is written as a single file. For large
+advisory repos, use --shard-by to split the database into shards, each of
+which is a complete security database for a range of packages, along with an
+"index.json" file that lists the shards. The output location (-o) is then a
+directory.
+
+Shards can be made of the packages whose names share a prefix (--shard-by
+prefix, see --shard-prefix-length), or of a fixed number of packages (--shard-by
+chunk, see --shard-size). Prefix shards stay stable as packages are added and
+removed; chunk shards have similar sizes.
+
+When the output directory already has an index, only the shards whose content
+changed are written, and shards that no longer exist are removed. The changed
+files are listed, so that only they need to be published.`,
+		Example: `
+wolfictl adv secdb -o security.json
+
+wolfictl adv secdb --shard-by prefix --shard-prefix-length 2 -o secdb/`,
 		SilenceErrors: true,
 		Deprecated:    advisoryDeprecationMessage,
 		Args:          cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			logger := clog.NewLogger(slog.Default())
 			ctx := clog.WithLogger(cmd.Context(), logger)
+
+			if err := p.validate(p.outputLocation); err != nil {
+				return err
+			}
+
 			if len(p.advisoriesRepoDirs) == 0 {
 				if p.doNotDetectDistro {
 					return fmt.Errorf("no advisories repo dir specified")
@@ -43,8 +67,7 @@ func cmdAdvisorySecDB() *cobra.Command {
 
 			indices := make([]*configs.Index[v2.Document], 0, len(p.advisoriesRepoDirs))
 			for _, dir := range p.advisoriesRepoDirs {
-				advisoryFsys := rwos.DirFS(dir)
-				index, err := adv2.NewIndex(cmd.Context(), advisoryFsys)
+				index, err := advisory.IndexWithArchive(cmd.Context(), os.DirFS(dir))
 				if err != nil {
 					return fmt.Errorf("unable to index advisory configs for directory %q: %w", dir, err)
 				}
@@ -59,6 +82,42 @@ func cmdAdvisorySecDB() *cobra.Command {
 				Repo:               p.repo,
 			}
 
+			if p.shardBy != "" {
+				if p.outputLocation == "" {
+					return fmt.Errorf("an output directory (-o) must be specified when sharding")
+				}
+
+				db, err := advisory.BuildShardedSecurityDatabase(ctx, opts, advisory.ShardOptions{
+					By:           p.shardBy,
+					PrefixLength: p.shardPrefixLength,
+					ChunkSize:    p.shardSize,
+				})
+				if err != nil {
+					return err
+				}
+
+				result, err := advisory.WriteShardedSecurityDatabase(p.outputLocation, db)
+				if err != nil {
+					return err
+				}
+
+				for _, file := range result.Written {
+					fmt.Printf("+ %s\n", file)
+				}
+				for _, file := range result.Removed {
+					fmt.Printf("- %s\n", file)
+				}
+				fmt.Fprintf(
+					os.Stderr,
+					"%d shards written, %d removed, %d unchanged.\n",
+					len(result.Written),
+					len(result.Removed),
+					result.Unchanged,
+				)
+
+				return p.signOutput(ctx, p.outputLocation)
+			}
+
 			database, err := advisory.BuildSecurityDatabase(ctx, opts)
 			if err != nil {
 				return err
@@ -80,7 +139,7 @@ func cmdAdvisorySecDB() *cobra.Command {
 				return fmt.Errorf("unable to write the security database to specified location: %w", err)
 			}
 
-			return nil
+			return p.signOutput(ctx, p.outputLocation)
 		},
 	}
 
@@ -89,6 +148,8 @@ func cmdAdvisorySecDB() *cobra.Command {
 }
 
 type dbParams struct {
+	signParams
+
 	doNotDetectDistro bool
 
 	advisoriesRepoDirs []string
@@ -98,6 +159,10 @@ type dbParams struct {
 	urlPrefix string
 	archs     []string
 	repo      string
+
+	shardBy           string
+	shardPrefixLength int
+	shardSize         int
 }
 
 func (p *dbParams) addFlagsTo(cmd *cobra.Command) {
@@ -105,9 +170,15 @@ func (p *dbParams) addFlagsTo(cmd *cobra.Command) {
 
 	cmd.Flags().StringSliceVarP(&p.advisoriesRepoDirs, "advisories-repo-dir", "a", nil, "directory containing an advisories repository")
 
-	cmd.Flags().StringVarP(&p.outputLocation, "output", "o", "", "output location (default: stdout)")
+	cmd.Flags().StringVarP(&p.outputLocation, "output", "o", "", "output location (default: stdout), or the output directory when sharding")
 
 	cmd.Flags().StringVar(&p.urlPrefix, "url-prefix", "https://packages.wolfi.dev", "URL scheme and hostname for the package repository")
 	cmd.Flags().StringSliceVar(&p.archs, "arch", []string{"x86_64"}, "the package architectures the security database is for")
 	cmd.Flags().StringVar(&p.repo, "repo", "os", "the name of the package repository")
+
+	cmd.Flags().StringVar(&p.shardBy, "shard-by", "", fmt.Sprintf("split the security database into shards (%s)", strings.Join(advisory.ShardStrategies, "|")))
+	cmd.Flags().IntVar(&p.shardPrefixLength, "shard-prefix-length", 1, "length of the package name prefix that shards are made of, when sharding by prefix")
+	cmd.Flags().IntVar(&p.shardSize, "shard-size", 500, "number of packages per shard, when sharding by chunk")
+
+	p.signParams.addFlagsTo(cmd)
 }
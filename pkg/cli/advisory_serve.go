@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+	"github.com/wolfi-dev/wolfictl/pkg/git"
+)
+
+func cmdAdvisoryServe() *cobra.Command {
+	p := &advisoryServeParams{}
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run a read-only HTTP API server over advisory data",
+		Long: `Run a read-only HTTP API server over advisory data.
+
+The advisories repo is loaded into memory when the server starts, and is
+reloaded periodically (see --refresh-interval). Before each reload, the latest
+changes are pulled from the repo's "origin" remote, unless --no-pull is given.
+If a refresh fails, the server keeps serving the previously loaded data.
+
+The server exposes these endpoints, which all respond with JSON:
+
+- GET /v1/advisories: List the advisories matching all the given query
+  parameters: "package", "vuln" (an advisory ID or alias), "status" (the type
+  of the advisory's latest event), and "q" (a query expression, as used by
+  "wolfictl adv search").
+
+- GET /v1/packages/{package}/advisories: List the package's advisories.
+
+- GET /v1/vulnerabilities/{id}/advisories: List the advisories for the
+  vulnerability (by advisory ID or alias) across all packages.
+
+- GET /v1/packages/{package}/vulnerabilities/{id}: Describe the package's
+  advisory for the vulnerability, including whether it's resolved. Use the
+  "version" query parameter to also check whether the vulnerability is
+  resolved at a particular version of the package.
+
+- GET /v1/status: Describe the loaded advisory data.
+
+- GET /healthz: Respond with 200 OK once the data has been loaded.
+`,
+		Example: `
+# Start the server
+wolfictl adv serve -a ~/code/advisories --addr :8080
+
+# List the open advisories for a package
+curl 'localhost:8080/v1/advisories?package=openssl&status=detection'
+
+# Check whether a vulnerability is resolved at a given package version
+curl 'localhost:8080/v1/packages/openssl/vulnerabilities/CVE-2024-2511?version=3.3.0-r0'
+`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			logger := clog.FromContext(ctx)
+
+			if p.refreshInterval < 0 {
+				return fmt.Errorf("refresh interval must not be negative")
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			server := advisory.NewServer(func(ctx context.Context) (*configs.Index[v2.Document], advisory.CVSSData, error) {
+				advisoryDocs, err := advisory.IndexWithArchive(ctx, os.DirFS(advisoriesRepoDir))
+				if err != nil {
+					return nil, nil, fmt.Errorf("unable to create index of advisories repo: %w", err)
+				}
+
+				cvss, err := advisory.ReadCVSSData(os.DirFS(advisoriesRepoDir))
+				if err != nil {
+					return nil, nil, err
+				}
+
+				return advisoryDocs, cvss, nil
+			})
+
+			if err := server.Reload(ctx); err != nil {
+				return err
+			}
+
+			if p.refreshInterval > 0 {
+				go refreshAdvisoryServer(ctx, server, advisoriesRepoDir, p.refreshInterval, !p.noPull)
+			}
+
+			srv := &http.Server{
+				Addr:              p.addr,
+				Handler:           server,
+				ReadHeaderTimeout: 10 * time.Second,
+				BaseContext:       func(_ net.Listener) context.Context { return ctx },
+			}
+
+			go func() {
+				<-ctx.Done()
+
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+
+				if err := srv.Shutdown(shutdownCtx); err != nil {
+					logger.Warn("failed to shut down server gracefully", "error", err)
+				}
+			}()
+
+			logger.Info("advisory server listening", "addr", p.addr, "advisoriesRepo", advisoriesRepoDir)
+
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("advisory server failed: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+// refreshAdvisoryServer reloads the server's data every interval until the
+// context is done, pulling the advisories repo first if pull is true. Failures
+// are logged, and the server keeps serving the data it has.
+func refreshAdvisoryServer(ctx context.Context, server *advisory.Server, dir string, interval time.Duration, pull bool) {
+	logger := clog.FromContext(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if pull {
+				updated, err := git.Pull(dir)
+				if err != nil {
+					logger.Warn("failed to pull advisories repo", "error", err)
+					continue
+				}
+				if !updated {
+					logger.Debug("advisories repo is already up to date")
+					continue
+				}
+			}
+
+			if err := server.Reload(ctx); err != nil {
+				logger.Warn("failed to reload advisory data", "error", err)
+			}
+		}
+	}
+}
+
+type advisoryServeParams struct {
+	advisoriesRepoDir string
+	doNotDetectDistro bool
+
+	addr            string
+	refreshInterval time.Duration
+	noPull          bool
+}
+
+func (p *advisoryServeParams) addFlagsTo(cmd *cobra.Command) {
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+
+	cmd.Flags().StringVar(&p.addr, "addr", ":8080", "address on which to listen for HTTP requests")
+	cmd.Flags().DurationVar(&p.refreshInterval, "refresh-interval", 5*time.Minute, "how often to refresh the advisory data (0 to never refresh)")
+	cmd.Flags().BoolVar(&p.noPull, "no-pull", false, "don't pull the advisories repo before refreshing the advisory data")
+}
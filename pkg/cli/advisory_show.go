@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryShow() *cobra.Command {
+	p := &showParams{}
+	cmd := &cobra.Command{
+		Use:   "show <package> <vulnerability>",
+		Short: "Show the event timeline of an advisory",
+		Long: `Show the event timeline of an advisory.
+
+The advisory is the given package's advisory for the given vulnerability, which
+can be the advisory's ID or any of its aliases. Its events are shown in
+chronological order, with the time since the previous event, the event's
+details and its note, if any.
+
+Advisory data doesn't record who added each event, so if the advisories repo is
+a Git repository, each event's actor is taken from its history: it's the
+author of the earliest commit in which the package's advisory document has the
+event. Events that aren't committed yet have no actor. Use --no-actors to skip
+reading the Git history.
+
+Use "-o json" to get the timeline as JSON. Durations in the JSON output are in
+seconds.`,
+		Example: `
+wolfictl adv show glibc CVE-2023-4911
+
+wolfictl adv show -a ../advisories ko CGA-2222-2222-2222 -o json`,
+		Deprecated:    advisoryDeprecationMessage,
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			log := clog.FromContext(ctx)
+			pkg, vuln := args[0], args[1]
+
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatTable
+			}
+
+			if !slices.Contains(validShowOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validShowOutputFormats, ", "),
+				)
+			}
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			getter := advisory.NewFSGetter(os.DirFS(advisoriesRepoDir))
+			advs, err := getter.Advisories(ctx, pkg)
+			if err != nil {
+				return fmt.Errorf("getting advisories for %q: %w", pkg, err)
+			}
+
+			i := slices.IndexFunc(advs, func(adv v2.PackageAdvisory) bool {
+				return adv.ID == vuln || adv.DescribesVulnerability(vuln)
+			})
+			if i < 0 {
+				return fmt.Errorf("no advisory found for %s in package %q", vuln, pkg)
+			}
+			adv := advs[i]
+
+			timeline := advisory.Timeline(adv.Advisory)
+
+			if !p.noActors {
+				actors, err := advisory.NewGitEventActors(advisoriesRepoDir)
+				if err != nil {
+					log.Warn("not showing event actors", "error", err)
+				} else if err := actors.Annotate(ctx, adv.PackageName, adv.ID, timeline); err != nil {
+					log.Warn("not showing event actors", "error", err)
+				}
+			}
+
+			switch p.outputFormat {
+			case outputFormatJSON:
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(newAdvisoryTimelineJSON(adv, timeline)); err != nil {
+					return fmt.Errorf("encoding JSON: %w", err)
+				}
+
+			case outputFormatTable:
+				renderAdvisoryTimeline(os.Stdout, adv, timeline)
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type showParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+
+	noActors     bool
+	outputFormat string
+}
+
+var validShowOutputFormats = []string{outputFormatTable, outputFormatJSON}
+
+func (p *showParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+
+	cmd.Flags().BoolVar(&p.noActors, "no-actors", false, "don't read the advisories repo's Git history to find who added each event")
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validShowOutputFormats, "|"), outputFormatTable))
+}
+
+type advisoryTimelineJSON struct {
+	Package string                      `json:"package"`
+	ID      string                      `json:"id"`
+	Aliases []string                    `json:"aliases,omitempty"`
+	Status  string                      `json:"status"`
+	Events  []advisoryTimelineEntryJSON `json:"events"`
+}
+
+type advisoryTimelineEntryJSON struct {
+	v2.Event
+	SincePreviousSeconds int64                `json:"sincePreviousSeconds"`
+	Note                 string               `json:"note,omitempty"`
+	Actor                *advisory.EventActor `json:"actor,omitempty"`
+}
+
+func newAdvisoryTimelineJSON(adv v2.PackageAdvisory, timeline []advisory.TimelineEntry) advisoryTimelineJSON {
+	out := advisoryTimelineJSON{
+		Package: adv.PackageName,
+		ID:      adv.ID,
+		Aliases: adv.Aliases,
+		Status:  adv.Latest().Type,
+		Events:  make([]advisoryTimelineEntryJSON, 0, len(timeline)),
+	}
+
+	for _, entry := range timeline {
+		out.Events = append(out.Events, advisoryTimelineEntryJSON{
+			Event:                entry.Event,
+			SincePreviousSeconds: int64(entry.SincePrevious.Seconds()),
+			Note:                 entry.Event.Note(),
+			Actor:                entry.Actor,
+		})
+	}
+
+	return out
+}
+
+func renderAdvisoryTimeline(w io.Writer, adv v2.PackageAdvisory, timeline []advisory.TimelineEntry) {
+	title := fmt.Sprintf("%s %s", styles.Bold().Render(adv.PackageName), styles.Bold().Render(adv.ID))
+	if len(adv.Aliases) > 0 {
+		title += fmt.Sprintf(" (%s)", strings.Join(adv.Aliases, ", "))
+	}
+	fmt.Fprintln(w, title)
+
+	if len(timeline) > 0 {
+		first := time.Time(timeline[0].Event.Timestamp)
+		last := time.Time(timeline[len(timeline)-1].Event.Timestamp)
+		fmt.Fprintf(w, "%s, %d events over %s\n", renderListItem(adv.Latest()), len(timeline), formatTimelineDuration(last.Sub(first)))
+	}
+
+	for _, entry := range timeline {
+		fmt.Fprintln(w)
+
+		since := ""
+		if entry.SincePrevious > 0 {
+			since = styles.Faint().Render(fmt.Sprintf(" (+%s)", formatTimelineDuration(entry.SincePrevious)))
+		}
+		fmt.Fprintf(w, "%s%s\n", styles.Secondary().Render(entry.Event.Timestamp.String()), since)
+		fmt.Fprintf(w, "  %s\n", styles.Bold().Render(renderTimelineEvent(entry.Event)))
+
+		if entry.Actor != nil {
+			fmt.Fprintf(w, "  by %s in %s\n", entry.Actor, entry.Actor.Commit[:min(12, len(entry.Actor.Commit))])
+		}
+
+		if note := entry.Event.Note(); note != "" {
+			for _, line := range strings.Split(strings.TrimSpace(note), "\n") {
+				fmt.Fprintf(w, "  │ %s\n", line)
+			}
+		}
+	}
+}
+
+// renderTimelineEvent is like renderListItem, but without true positive
+// determinations' notes, since the timeline shows every event's note on its own.
+func renderTimelineEvent(e v2.Event) string {
+	if e.Type == v2.EventTypeTruePositiveDetermination {
+		return "true positive"
+	}
+	return renderListItem(e)
+}
+
+// formatTimelineDuration returns the duration in its two largest units among
+// days, hours, minutes and seconds, e.g. "3d 4h" or "5m 10s".
+func formatTimelineDuration(d time.Duration) string {
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	for i, u := range units {
+		n := d / u.size
+		if n == 0 {
+			continue
+		}
+
+		s := fmt.Sprintf("%d%s", n, u.suffix)
+		if i+1 < len(units) {
+			next := units[i+1]
+			if m := (d - n*u.size) / next.size; m > 0 {
+				s += fmt.Sprintf(" %d%s", m, next.suffix)
+			}
+		}
+		return s
+	}
+
+	return "0s"
+}
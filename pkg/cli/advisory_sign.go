@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+)
+
+// signParams are the flags for signing the output of a command that exports
+// advisory data.
+type signParams struct {
+	sign    bool
+	signKey string
+}
+
+func (p *signParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&p.sign, "sign", false, "sign the output using cosign, and write the Sigstore bundle next to it (with the suffix \""+advisory.SignatureBundleSuffix+"\"), or for a directory, sign its "+advisory.ChecksumsFileName+" file")
+	cmd.Flags().StringVar(&p.signKey, "sign-key", "", "cosign signing key (path or KMS URI) for --sign (if not specified, keyless signing is used)")
+}
+
+func (p *signParams) validate(outputLocation string) error {
+	if p.signKey != "" && !p.sign {
+		return errors.New("cannot use --sign-key without --sign")
+	}
+	if p.sign && outputLocation == "" {
+		return errors.New("an output location (--output) is required with --sign")
+	}
+	return nil
+}
+
+// signOutput signs the output at the given location, if signing was requested.
+func (p *signParams) signOutput(ctx context.Context, outputLocation string) error {
+	if !p.sign {
+		return nil
+	}
+
+	bundle, err := advisory.SignArtifact(ctx, outputLocation, advisory.SignOptions{Key: p.signKey})
+	if err != nil {
+		return fmt.Errorf("unable to sign %q: %w", outputLocation, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote signature bundle %s\n", bundle)
+	return nil
+}
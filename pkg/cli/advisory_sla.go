@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	"golang.org/x/exp/slices"
+)
+
+func cmdAdvisorySLA() *cobra.Command {
+	p := &slaParams{}
+	cmd := &cobra.Command{
+		Use:   "sla",
+		Short: "Report the time it takes to remediate advisories",
+		Long: fmt.Sprintf(`Report the time it takes to remediate advisories.
+
+The time to remediation of an advisory is the time from its first detection
+event to the first subsequent fixed or false positive determination event.
+Advisories that haven't been remediated yet, or that don't have a detection
+event, aren't included.
+
+The 50th, 90th and 95th percentiles and the maximum time to remediation are
+reported overall, by severity and by package. The severity of an advisory is
+the highest CVSS severity among its CVEs, as stored in the %q file of the
+advisories repo (see "wolfictl adv sync-cvss").
+
+Use --sla to specify the number of days within which advisories of a given
+severity are expected to be remediated. The report then also shows how many
+advisories were remediated within their SLA.
+
+Use --since to only include advisories remediated on or after a given date, and
+"-o json" for output that's suitable for dashboards. Durations in the JSON
+output are in days.`, advisory.CVSSFileName),
+		Example: `
+wolfictl adv sla --sla CRITICAL=7 --sla HIGH=30
+
+wolfictl adv sla --since 2024-01-01 -o json`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatTable
+			}
+
+			if !slices.Contains(validSLAOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validSLAOutputFormats, ", "),
+				)
+			}
+
+			var since time.Time
+			if p.since != "" {
+				t, err := time.Parse("2006-01-02", p.since)
+				if err != nil {
+					return fmt.Errorf("parsing since date %q: %w", p.since, err)
+				}
+				since = t
+			}
+
+			slas := make(map[string]time.Duration, len(p.slaDays))
+			for severity, n := range p.slaDays {
+				if n <= 0 {
+					return fmt.Errorf("invalid SLA for %s: must be a positive number of days", severity)
+				}
+				slas[strings.ToUpper(severity)] = time.Duration(n) * 24 * time.Hour
+			}
+
+			dirs, err := resolveAdvisoriesDirsInput(p.advisoriesRepoDirs, p.doNotDetectDistro)
+			if err != nil {
+				return err
+			}
+
+			cvss, err := readCVSSDataFromDirs(dirs)
+			if err != nil {
+				return err
+			}
+
+			remediations, err := advisory.FindRemediations(ctx, advisory.RemediationOptions{
+				Getter: newAdvisoriesGetter(dirs),
+				CVSS:   cvss,
+				Since:  since,
+			})
+			if err != nil {
+				return err
+			}
+
+			report := advisory.SummarizeRemediations(remediations, slas)
+
+			switch p.outputFormat {
+			case outputFormatJSON:
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(newSLAReportJSON(report, slas)); err != nil {
+					return fmt.Errorf("encoding JSON: %w", err)
+				}
+
+			case outputFormatTable:
+				renderSLAReportTable(os.Stdout, report, p.top)
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type slaParams struct {
+	advisoriesRepoDirs []string
+	doNotDetectDistro  bool
+
+	slaDays      map[string]int
+	since        string
+	outputFormat string
+	top          int
+}
+
+var validSLAOutputFormats = []string{outputFormatTable, outputFormatJSON}
+
+func (p *slaParams) addFlagsTo(cmd *cobra.Command) {
+	addAdvisoriesDirsFlag(&p.advisoriesRepoDirs, cmd)
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+
+	cmd.Flags().StringToIntVar(&p.slaDays, "sla", nil, "number of days within which advisories of a severity are expected to be remediated, as SEVERITY=DAYS (can be repeated)")
+	cmd.Flags().StringVar(&p.since, "since", "", "only include advisories remediated on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validSLAOutputFormats, "|"), outputFormatTable))
+	cmd.Flags().IntVar(&p.top, "top", 10, "number of packages with the most remediated advisories to show in the table output")
+}
+
+type slaReportJSON struct {
+	SLADays    map[string]float64 `json:"slaDays,omitempty"`
+	Overall    slaSummaryJSON     `json:"overall"`
+	BySeverity []slaSummaryJSON   `json:"bySeverity"`
+	ByPackage  []slaSummaryJSON   `json:"byPackage"`
+}
+
+type slaSummaryJSON struct {
+	Key       string  `json:"key"`
+	Count     int     `json:"count"`
+	P50Days   float64 `json:"p50Days"`
+	P90Days   float64 `json:"p90Days"`
+	P95Days   float64 `json:"p95Days"`
+	MaxDays   float64 `json:"maxDays"`
+	WithSLA   int     `json:"withSLA"`
+	WithinSLA int     `json:"withinSLA"`
+}
+
+func newSLAReportJSON(report advisory.RemediationReport, slas map[string]time.Duration) slaReportJSON {
+	out := slaReportJSON{
+		Overall:    newSLASummaryJSON(report.Overall),
+		BySeverity: []slaSummaryJSON{},
+		ByPackage:  []slaSummaryJSON{},
+	}
+
+	if len(slas) > 0 {
+		out.SLADays = make(map[string]float64, len(slas))
+		for severity, d := range slas {
+			out.SLADays[severity] = durationDays(d)
+		}
+	}
+
+	for _, s := range report.BySeverity {
+		out.BySeverity = append(out.BySeverity, newSLASummaryJSON(s))
+	}
+	for _, s := range report.ByPackage {
+		out.ByPackage = append(out.ByPackage, newSLASummaryJSON(s))
+	}
+
+	return out
+}
+
+func newSLASummaryJSON(s advisory.RemediationSummary) slaSummaryJSON {
+	return slaSummaryJSON{
+		Key:       s.Key,
+		Count:     s.Count,
+		P50Days:   durationDays(s.P50),
+		P90Days:   durationDays(s.P90),
+		P95Days:   durationDays(s.P95),
+		MaxDays:   durationDays(s.Max),
+		WithSLA:   s.WithSLA,
+		WithinSLA: s.WithinSLA,
+	}
+}
+
+// durationDays returns the duration in days, rounded to one decimal place.
+func durationDays(d time.Duration) float64 {
+	return float64(d.Round(time.Hour*24/10)) / float64(24*time.Hour)
+}
+
+func renderSLAReportTable(w io.Writer, report advisory.RemediationReport, top int) {
+	fmt.Fprintf(w, "%s advisories remediated\n", styles.Bold().Render(fmt.Sprint(report.Overall.Count)))
+	if report.Overall.Count == 0 {
+		return
+	}
+
+	packages := report.ByPackage
+	if top > 0 && len(packages) > top {
+		packages = packages[:top]
+	}
+
+	renderSLASummaries(w, "Overall", []advisory.RemediationSummary{report.Overall})
+	renderSLASummaries(w, "By severity", report.BySeverity)
+	renderSLASummaries(w, "Packages with the most remediated advisories", packages)
+}
+
+func renderSLASummaries(w io.Writer, title string, summaries []advisory.RemediationSummary) {
+	width := 0
+	for _, s := range summaries {
+		if l := len(s.Key); l > width {
+			width = l
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", styles.Bold().Render(title))
+	fmt.Fprintf(w, "  %-*s  %6s  %8s  %8s  %8s  %8s  %s\n", width, "", "COUNT", "P50", "P90", "P95", "MAX", "WITHIN SLA")
+	for _, s := range summaries {
+		withinSLA := "-"
+		if s.WithSLA > 0 {
+			withinSLA = fmt.Sprintf("%d/%d (%.0f%%)", s.WithinSLA, s.WithSLA, 100*float64(s.WithinSLA)/float64(s.WithSLA))
+		}
+
+		fmt.Fprintf(
+			w,
+			"  %-*s  %6d  %7.1fd  %7.1fd  %7.1fd  %7.1fd  %s\n",
+			width, s.Key, s.Count, durationDays(s.P50), durationDays(s.P90), durationDays(s.P95), durationDays(s.Max), withinSLA,
+		)
+	}
+}
@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+	"golang.org/x/exp/slices"
+)
+
+func cmdAdvisoryStats() *cobra.Command {
+	p := &statsParams{}
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show aggregate statistics over the advisory data",
+		Long: fmt.Sprintf(`Show aggregate statistics over the advisory data.
+
+The statistics include:
+
+  - the number of advisories by the type of their latest event
+  - the number of advisories by severity
+  - the packages with the most advisories
+  - the age of open advisories (those still being investigated or awaiting a fix)
+  - the number of advisories added per week
+
+The severity of an advisory is the highest CVSS severity among its CVEs, as
+stored in the %q file of the advisories repo (see "wolfictl adv
+sync-cvss"). Advisories without CVSS data have an unknown severity.
+
+The advisories of multiple repos can be combined by specifying -a more than
+once. Advisories for the same vulnerability in the same package are counted
+once.
+
+Use "-o json" for output that's suitable for dashboards. The JSON output always
+includes all packages.`, advisory.CVSSFileName),
+		Example: `
+wolfictl adv stats
+
+wolfictl adv stats -a ../wolfi-advisories -a ../enterprise-advisories -o json`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatTable
+			}
+
+			if !slices.Contains(validStatsOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validStatsOutputFormats, ", "),
+				)
+			}
+
+			dirs, err := resolveAdvisoriesDirsInput(p.advisoriesRepoDirs, p.doNotDetectDistro)
+			if err != nil {
+				return err
+			}
+
+			cvss, err := readCVSSDataFromDirs(dirs)
+			if err != nil {
+				return err
+			}
+
+			stats, err := advisory.ComputeStats(ctx, advisory.StatsOptions{
+				Getter: newAdvisoriesGetter(dirs),
+				CVSS:   cvss,
+				Weeks:  p.weeks,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch p.outputFormat {
+			case outputFormatJSON:
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(stats); err != nil {
+					return fmt.Errorf("encoding JSON: %w", err)
+				}
+
+			case outputFormatTable:
+				renderStatsTable(os.Stdout, stats, p.top)
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type statsParams struct {
+	advisoriesRepoDirs []string
+	doNotDetectDistro  bool
+
+	outputFormat string
+	top          int
+	weeks        int
+}
+
+var validStatsOutputFormats = []string{outputFormatTable, outputFormatJSON}
+
+func (p *statsParams) addFlagsTo(cmd *cobra.Command) {
+	addAdvisoriesDirsFlag(&p.advisoriesRepoDirs, cmd)
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validStatsOutputFormats, "|"), outputFormatTable))
+	cmd.Flags().IntVar(&p.top, "top", 10, "number of packages with the most advisories to show in the table output")
+	cmd.Flags().IntVar(&p.weeks, "weeks", 12, "number of most recent weeks for which to count the added advisories (0 for all weeks)")
+}
+
+func renderStatsTable(w io.Writer, stats *advisory.Stats, top int) {
+	fmt.Fprintf(
+		w,
+		"%s packages, %s advisories, %s open\n",
+		styles.Bold().Render(fmt.Sprint(stats.Packages)),
+		styles.Bold().Render(fmt.Sprint(stats.Advisories)),
+		styles.Bold().Render(fmt.Sprint(stats.Open)),
+	)
+
+	renderStatsCounts(w, "By latest event type", stats.ByEventType)
+	renderStatsCounts(w, "By severity", stats.BySeverity)
+
+	packages := stats.ByPackage
+	if top > 0 && len(packages) > top {
+		packages = packages[:top]
+	}
+
+	width := len("PACKAGE")
+	for _, pc := range packages {
+		if l := len(pc.Package); l > width {
+			width = l
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", styles.Bold().Render("Packages with the most advisories"))
+	fmt.Fprintf(w, "  %-*s  %10s  %6s\n", width, "PACKAGE", "ADVISORIES", "OPEN")
+	for _, pc := range packages {
+		fmt.Fprintf(w, "  %-*s  %10d  %6d\n", width, pc.Package, pc.Advisories, pc.Open)
+	}
+
+	renderStatsCounts(w, "Open advisories by age", stats.OpenByAge)
+	renderStatsCounts(w, "Advisories added per week", stats.AddedPerWeek)
+}
+
+func renderStatsCounts(w io.Writer, title string, counts []advisory.Count) {
+	width := 0
+	for _, c := range counts {
+		if l := len(c.Key); l > width {
+			width = l
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", styles.Bold().Render(title))
+	for _, c := range counts {
+		fmt.Fprintf(w, "  %-*s  %6d\n", width, c.Key, c.Count)
+	}
+}
+
+// resolveAdvisoriesDirsInput returns the given advisories repo dirs, or if
+// there are none, the advisories repo dir of the auto-detected distro.
+func resolveAdvisoriesDirsInput(dirs []string, doNotDetectDistro bool) ([]string, error) {
+	if len(dirs) > 0 {
+		return dirs, nil
+	}
+
+	if doNotDetectDistro {
+		return nil, fmt.Errorf("no advisories repo dir specified")
+	}
+
+	d, err := distro.Detect()
+	if err != nil {
+		return nil, fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+	}
+
+	_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+	return []string{d.Local.AdvisoriesRepo.Dir}, nil
+}
+
+// readCVSSDataFromDirs reads and combines the CVSS data of the advisories repo
+// dirs. When more than one repo has data for the same CVE, the data from the
+// first one is used.
+func readCVSSDataFromDirs(dirs []string) (advisory.CVSSData, error) {
+	cvss := advisory.CVSSData{}
+	for _, dir := range dirs {
+		data, err := advisory.ReadCVSSData(os.DirFS(dir))
+		if err != nil {
+			return nil, err
+		}
+		for id, r := range data {
+			if _, ok := cvss[id]; !ok {
+				cvss[id] = r
+			}
+		}
+	}
+	return cvss, nil
+}
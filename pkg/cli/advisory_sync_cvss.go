@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+	"github.com/wolfi-dev/wolfictl/pkg/vuln/nvdapi"
+)
+
+func cmdAdvisorySyncCVSS() *cobra.Command {
+	p := &syncCVSSParams{}
+	cmd := &cobra.Command{
+		Use:   "sync-cvss",
+		Short: "Sync the CVSS data of the CVEs referenced by advisories with NVD",
+		Long: fmt.Sprintf(`Sync the CVSS data of the CVEs referenced by advisories with NVD.
+
+The CVSS score, severity and vector of each CVE in the advisories' aliases are
+looked up using the NVD API, and stored in the %q file at the root of the
+advisories repo. NVD's own score for the newest CVSS version is preferred.
+
+The changes to the stored data are printed as a diff, so they can be reviewed
+before committing them. Use --dry-run to print the changes without writing
+them.
+
+Requests to the NVD API are rate limited. Using an API key significantly
+increases the rate limit.`, advisory.CVSSFileName),
+		Example: `
+wolfictl adv sync-cvss --dry-run
+
+wolfictl adv sync-cvss -p ko -p crane`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+			if advisoriesRepoDir == "" {
+				if p.doNotDetectDistro {
+					return fmt.Errorf("no advisories repo dir specified")
+				}
+
+				d, err := distro.Detect()
+				if err != nil {
+					return fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+				}
+
+				advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+				_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+			}
+
+			advisoryDocs, err := adv2.NewIndexForPackages(ctx, rwos.DirFS(advisoriesRepoDir), p.packages...)
+			if err != nil {
+				return fmt.Errorf("unable to create index of advisories repo: %w", err)
+			}
+
+			current, err := advisory.ReadCVSSData(os.DirFS(advisoriesRepoDir))
+			if err != nil {
+				return err
+			}
+
+			selectedPackages := make(map[string]struct{})
+			for _, pkg := range p.packages {
+				selectedPackages[pkg] = struct{}{}
+			}
+
+			detector := nvdapi.NewDetector(http.DefaultClient, nvdapi.DefaultHost, resolveNVDAPIKey(p.nvdAPIKey))
+
+			updated, changes, err := advisory.SyncCVSS(ctx, advisory.SyncCVSSOptions{
+				AdvisoryDocs:     advisoryDocs,
+				SelectedPackages: selectedPackages,
+				Source:           advisory.NewNVDCVSSSource(detector),
+				Current:          current,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(changes) == 0 {
+				fmt.Fprintln(os.Stderr, "CVSS data is up to date.")
+				return nil
+			}
+
+			printCVSSChanges(os.Stdout, changes)
+
+			if p.dryRun {
+				fmt.Fprintf(os.Stderr, "\n%d CVEs would be updated (dry run).\n", len(changes))
+				return nil
+			}
+
+			var buf bytes.Buffer
+			if err := updated.Encode(&buf); err != nil {
+				return fmt.Errorf("encoding CVSS data: %w", err)
+			}
+			if err := os.WriteFile(filepath.Join(advisoriesRepoDir, advisory.CVSSFileName), buf.Bytes(), os.FileMode(0o644)); err != nil {
+				return fmt.Errorf("writing CVSS data: %w", err)
+			}
+
+			fmt.Fprintf(os.Stderr, "\n%d CVEs updated in %s.\n", len(changes), advisory.CVSSFileName)
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type syncCVSSParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	packages          []string
+	nvdAPIKey         string
+	dryRun            bool
+}
+
+func (p *syncCVSSParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addMultiPackageFlag(&p.packages, cmd)
+	addNVDAPIKeyFlag(&p.nvdAPIKey, cmd)
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "print the changes without writing them")
+}
+
+// printCVSSChanges prints the changes as a diff of the CVSS data, with the old
+// data of each CVE on "-" lines and the new data on "+" lines.
+func printCVSSChanges(w io.Writer, changes []advisory.CVSSChange) {
+	for _, c := range changes {
+		fmt.Fprintln(w, c.CVE)
+		if c.Old != nil {
+			fmt.Fprintf(w, "- %s\n", c.Old)
+		}
+		if c.New != nil {
+			fmt.Fprintf(w, "+ %s\n", c.New)
+		}
+	}
+}
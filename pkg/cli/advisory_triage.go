@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"github.com/wolfi-dev/wolfictl/pkg/distro"
+)
+
+func cmdAdvisoryTriage() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "triage",
+		Short: "Commands for assigning open advisories to maintainers for triage",
+		Long: fmt.Sprintf(`Commands for assigning open advisories to maintainers for triage.
+
+An advisory is open until its latest event concludes it, e.g. as fixed or as a
+false positive. Open advisories need triage by the maintainers of their
+packages.
+
+The %q file at the root of the advisories repo maps packages to the
+maintainers or teams who own them. It's a list of rules, and as with CODEOWNERS
+files, the last rule that matches a package determines its owners:
+
+  - packages: ["*"]
+    owners: ["@security"]
+  - packages: ["py3-*"]
+    owners: ["@python-team"]
+
+Assignments of open advisories are stored in the %q file at the root of
+the advisories repo. An advisory that's unassigned is in the triage queue of
+each of its package's owners; an assigned advisory is in its assignee's queue.`,
+			advisory.OwnersFileName, advisory.AssignmentsFileName),
+		Deprecated: advisoryDeprecationMessage,
+	}
+
+	cmd.AddCommand(
+		cmdAdvisoryTriageAssign(),
+		cmdAdvisoryTriageQueues(),
+		cmdAdvisoryTriageUnassigned(),
+	)
+
+	return cmd
+}
+
+type triageParams struct {
+	doNotDetectDistro bool
+	advisoriesRepoDir string
+	packages          []string
+}
+
+func (p *triageParams) addFlagsTo(cmd *cobra.Command) {
+	addNoDistroDetectionFlag(&p.doNotDetectDistro, cmd)
+	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
+	addMultiPackageFlag(&p.packages, cmd)
+}
+
+// triageData is the data that the triage commands operate on.
+type triageData struct {
+	advisoriesRepoDir string
+	advisoryDocs      *configs.Index[v2.Document]
+	ownership         advisory.Ownership
+	assignments       advisory.Assignments
+}
+
+func (p *triageParams) load(ctx context.Context) (*triageData, error) {
+	advisoriesRepoDir := resolveAdvisoriesDirInput(p.advisoriesRepoDir)
+	if advisoriesRepoDir == "" {
+		if p.doNotDetectDistro {
+			return nil, fmt.Errorf("no advisories repo dir specified")
+		}
+
+		d, err := distro.Detect()
+		if err != nil {
+			return nil, fmt.Errorf("no advisories repo dir specified, and distro auto-detection failed: %w", err)
+		}
+
+		advisoriesRepoDir = d.Local.AdvisoriesRepo.Dir
+		_, _ = fmt.Fprint(os.Stderr, renderDetectedDistro(d))
+	}
+
+	advisoryDocs, err := adv2.NewIndex(ctx, rwos.DirFS(advisoriesRepoDir))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create index of advisories repo: %w", err)
+	}
+
+	fsys := os.DirFS(advisoriesRepoDir)
+	ownership, err := advisory.ReadOwnership(fsys)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := advisory.ReadAssignments(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &triageData{
+		advisoriesRepoDir: advisoriesRepoDir,
+		advisoryDocs:      advisoryDocs,
+		ownership:         ownership,
+		assignments:       assignments,
+	}, nil
+}
+
+func (d *triageData) items(packages []string) ([]advisory.TriageItem, error) {
+	selectedPackages := make(map[string]struct{})
+	for _, pkg := range packages {
+		selectedPackages[pkg] = struct{}{}
+	}
+
+	return advisory.FindTriageItems(advisory.TriageOptions{
+		AdvisoryDocs:     d.advisoryDocs,
+		SelectedPackages: selectedPackages,
+		Ownership:        d.ownership,
+		Assignments:      d.assignments,
+	})
+}
+
+func (d *triageData) writeAssignments() error {
+	var buf bytes.Buffer
+	if err := d.assignments.Encode(&buf); err != nil {
+		return fmt.Errorf("encoding assignments: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(d.advisoriesRepoDir, advisory.AssignmentsFileName), buf.Bytes(), os.FileMode(0o644)); err != nil {
+		return fmt.Errorf("writing assignments: %w", err)
+	}
+	return nil
+}
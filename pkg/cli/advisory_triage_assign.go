@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+)
+
+func cmdAdvisoryTriageAssign() *cobra.Command {
+	p := &triageAssignParams{}
+	cmd := &cobra.Command{
+		Use:   "assign [<package> <vulnerability>]",
+		Short: "Assign open advisories to maintainers for triage",
+		Long: `Assign open advisories to maintainers for triage.
+
+Given a package and a vulnerability (an advisory ID or an alias), the package's
+advisory for the vulnerability is assigned. Otherwise, every open advisory that
+isn't assigned yet is assigned (limited to the packages given with -p).
+
+Advisories are assigned to --to, or, by default, to the first owner of their
+package. Advisories whose package has no owner are left unassigned.
+
+Assignments of advisories that are no longer open are removed.`,
+		Example: `
+wolfictl adv triage assign ko CVE-2024-1234 --to alice
+
+wolfictl adv triage assign -p ko -p crane`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 0 && len(args) != 2 {
+				return fmt.Errorf("expected a package and a vulnerability, or no arguments")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 2 && len(p.packages) > 0 {
+				return fmt.Errorf("packages can't be selected with -p when assigning a single advisory")
+			}
+
+			data, err := p.load(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			packages := p.packages
+			if len(args) == 2 {
+				packages = args[:1]
+			}
+
+			items, err := data.items(packages)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 2 {
+				pkg, vuln := args[0], args[1]
+				var match *advisory.TriageItem
+				for i := range items {
+					if adv := items[i].Advisory; adv.ID == vuln || adv.DescribesVulnerability(vuln) {
+						match = &items[i]
+						break
+					}
+				}
+				if match == nil {
+					return fmt.Errorf("no open advisory found for %q in package %q", vuln, pkg)
+				}
+				// An explicitly requested assignment replaces the current one.
+				match.Assignee = ""
+				items = []advisory.TriageItem{*match}
+			}
+
+			now := time.Now().UTC().Truncate(time.Second)
+			assigned := 0
+			for _, item := range items {
+				if item.Assignee != "" {
+					continue
+				}
+
+				assignee := p.to
+				if assignee == "" {
+					if len(item.Owners) == 0 {
+						fmt.Fprintf(os.Stderr, "%s: %s has no owner, leaving it unassigned\n", item.Package, item.Advisory.ID)
+						continue
+					}
+					assignee = item.Owners[0]
+				}
+
+				data.assignments[item.Advisory.ID] = advisory.Assignment{
+					Package:  item.Package,
+					Assignee: assignee,
+					Assigned: now,
+				}
+				fprintAddedf(os.Stdout, "%s: %s assigned to %s", item.Package, item.Advisory.ID, assignee)
+				assigned++
+			}
+
+			for _, id := range data.assignments.Prune(data.advisoryDocs) {
+				fprintRemovedf(os.Stdout, "%s: assignment removed, advisory is no longer open", id)
+			}
+
+			if err := data.writeAssignments(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "\n%d advisories assigned.\n", assigned)
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type triageAssignParams struct {
+	triageParams
+	to string
+}
+
+func (p *triageAssignParams) addFlagsTo(cmd *cobra.Command) {
+	p.triageParams.addFlagsTo(cmd)
+	cmd.Flags().StringVar(&p.to, "to", "", "maintainer or team to assign the advisories to (default: the first owner of each advisory's package)")
+}
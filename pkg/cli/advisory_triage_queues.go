@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+)
+
+func cmdAdvisoryTriageQueues() *cobra.Command {
+	p := &triageQueuesParams{}
+	cmd := &cobra.Command{
+		Use:   "queues",
+		Short: "Export the triage queue of each maintainer or team",
+		Long: `Export the triage queue of each maintainer or team.
+
+An assigned open advisory is in its assignee's queue. An unassigned one is in
+the queue of each of its package's owners.
+
+The CSV output has one row per advisory in a queue, with the columns: queue,
+package, advisory, aliases, status, assignee. It can be imported into a
+spreadsheet.`,
+		Example: `
+wolfictl adv triage queues
+
+wolfictl adv triage queues --owner @go-team -o csv > go-team.csv`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatTable
+			}
+
+			if !slices.Contains(validTriageQueuesOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validTriageQueuesOutputFormats, ", "),
+				)
+			}
+
+			data, err := p.load(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			items, err := data.items(p.packages)
+			if err != nil {
+				return err
+			}
+
+			queues := advisory.TriageQueues(items)
+			if len(p.owners) > 0 {
+				for q := range queues {
+					if !slices.Contains(p.owners, q) {
+						delete(queues, q)
+					}
+				}
+			}
+
+			switch p.outputFormat {
+			case outputFormatJSON:
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(queues); err != nil {
+					return fmt.Errorf("encoding JSON: %w", err)
+				}
+
+			case outputFormatCSV:
+				if err := writeTriageQueuesCSV(os.Stdout, queues); err != nil {
+					return fmt.Errorf("encoding CSV: %w", err)
+				}
+
+			case outputFormatTable:
+				if len(queues) == 0 {
+					fmt.Fprintln(os.Stderr, "No open advisories in any triage queue.")
+					return nil
+				}
+				for i, q := range sortedTriageQueueNames(queues) {
+					if i > 0 {
+						fmt.Fprintln(os.Stdout)
+					}
+					fmt.Fprintln(os.Stdout, styles.Bold().Render(fmt.Sprintf("%s (%d)", q, len(queues[q]))))
+					for _, item := range queues[q] {
+						fmt.Fprintf(os.Stdout, "  %s  %s  %s\n", item.Package, item.Advisory.ID, item.Advisory.Latest().Type)
+					}
+				}
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type triageQueuesParams struct {
+	triageParams
+	owners       []string
+	outputFormat string
+}
+
+var validTriageQueuesOutputFormats = []string{outputFormatTable, outputFormatJSON, outputFormatCSV}
+
+func (p *triageQueuesParams) addFlagsTo(cmd *cobra.Command) {
+	p.triageParams.addFlagsTo(cmd)
+	cmd.Flags().StringSliceVar(&p.owners, "owner", nil, "only export the queues of these maintainers or teams (can be repeated)")
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validTriageQueuesOutputFormats, "|"), outputFormatTable))
+}
+
+func sortedTriageQueueNames(queues map[string][]advisory.TriageItem) []string {
+	names := make([]string, 0, len(queues))
+	for q := range queues {
+		names = append(names, q)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func writeTriageQueuesCSV(w io.Writer, queues map[string][]advisory.TriageItem) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"queue", "package", "advisory", "aliases", "status", "assignee"}); err != nil {
+		return err
+	}
+
+	for _, q := range sortedTriageQueueNames(queues) {
+		for _, item := range queues[q] {
+			if err := cw.Write([]string{
+				q,
+				item.Package,
+				item.Advisory.ID,
+				strings.Join(item.Advisory.Aliases, " "),
+				item.Advisory.Latest().Type,
+				item.Assignee,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+)
+
+func cmdAdvisoryTriageUnassigned() *cobra.Command {
+	p := &triageUnassignedParams{}
+	cmd := &cobra.Command{
+		Use:   "unassigned",
+		Short: "List the open advisories that aren't assigned to anyone",
+		Example: `
+wolfictl adv triage unassigned
+
+wolfictl adv triage unassigned -p ko -o json`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatTable
+			}
+
+			if !slices.Contains(validTriageUnassignedOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validTriageUnassignedOutputFormats, ", "),
+				)
+			}
+
+			data, err := p.load(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			items, err := data.items(p.packages)
+			if err != nil {
+				return err
+			}
+
+			unassigned := []advisory.TriageItem{}
+			for _, item := range items {
+				if item.Assignee == "" {
+					unassigned = append(unassigned, item)
+				}
+			}
+
+			switch p.outputFormat {
+			case outputFormatJSON:
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(unassigned); err != nil {
+					return fmt.Errorf("encoding JSON: %w", err)
+				}
+
+			case outputFormatTable:
+				if len(unassigned) == 0 {
+					fmt.Fprintln(os.Stderr, "No unassigned open advisories.")
+					return nil
+				}
+				renderTriageItems(os.Stdout, unassigned)
+				fmt.Fprintf(os.Stdout, "\n%d open advisories are unassigned.\n", len(unassigned))
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type triageUnassignedParams struct {
+	triageParams
+	outputFormat string
+}
+
+var validTriageUnassignedOutputFormats = []string{outputFormatTable, outputFormatJSON}
+
+func (p *triageUnassignedParams) addFlagsTo(cmd *cobra.Command) {
+	p.triageParams.addFlagsTo(cmd)
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validTriageUnassignedOutputFormats, "|"), outputFormatTable))
+}
+
+// renderTriageItems prints the items grouped by package, with each advisory's
+// latest event type, and its assignee or, if it's unassigned, its owners.
+func renderTriageItems(w io.Writer, items []advisory.TriageItem) {
+	pkg := ""
+	for _, item := range items {
+		if item.Package != pkg {
+			if pkg != "" {
+				fmt.Fprintln(w)
+			}
+			pkg = item.Package
+			fmt.Fprintln(w, styles.Bold().Render(pkg))
+		}
+
+		who := "assigned to " + item.Assignee
+		if item.Assignee == "" {
+			who = "owned by " + strings.Join(item.Owners, ", ")
+			if len(item.Owners) == 0 {
+				who = "no owner"
+			}
+		}
+
+		fmt.Fprintf(w, "  %s  %s  (%s)\n", item.Advisory.ID, item.Advisory.Latest().Type, who)
+	}
+}
@@ -50,7 +50,18 @@ advisory will be skipped, and a warning will be logged. This is to prevent
 adding redundant events to advisories that already have the same type of event.
 
 If the --no-prompt flag is specified, then the command will fail if any
-required fields are missing.`,
+required fields are missing.
+
+Commonly used false positive determinations can be kept as named templates in
+the advisories repo's .false-positive-templates.yaml file, e.g.:
+
+  fix-backported:
+    type: vulnerable-code-version-not-used
+    note: The fix for this vulnerability was backported to the packaged version.
+
+Use --fp-template to add a false positive determination event from a template.
+The --fp-type and --note flags, if specified, take precedence over the
+template's values.`,
 		SilenceErrors: true,
 		Deprecated:    advisoryDeprecationMessage,
 		Args:          cobra.NoArgs,
@@ -131,6 +142,9 @@ required fields are missing.`,
 			}
 
 			reqParams := p.requestParams
+			if err := applyFalsePositiveTemplate(p.fpTemplate, advisoriesRepoDir, &reqParams); err != nil {
+				return err
+			}
 
 			c := client.New(http.DefaultClient)
 			var apkindexes []*apk.APKIndex
@@ -273,6 +287,7 @@ type updateParams struct {
 	doNotPrompt       bool
 
 	requestParams                    advisory.RequestParams
+	fpTemplate                       string
 	distroRepoDir, advisoriesRepoDir string
 	archs                            []string
 	packageRepositoryURL             string
@@ -283,6 +298,7 @@ func (p *updateParams) addFlagsTo(cmd *cobra.Command) {
 	addNoPromptFlag(&p.doNotPrompt, cmd)
 
 	addFlagsForAdvisoryRequestParams(&p.requestParams, cmd)
+	addFalsePositiveTemplateFlag(&p.fpTemplate, cmd)
 	addDistroDirFlag(&p.distroRepoDir, cmd)
 	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
 	cmd.Flags().StringSliceVar(&p.archs, "arch", []string{"x86_64", "aarch64"}, "package architectures to find published versions for")
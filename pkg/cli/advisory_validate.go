@@ -15,7 +15,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/wolfi-dev/wolfictl/pkg/advisory"
 	"github.com/wolfi-dev/wolfictl/pkg/configs"
-	adv2 "github.com/wolfi-dev/wolfictl/pkg/configs/advisory/v2"
 	"github.com/wolfi-dev/wolfictl/pkg/configs/build"
 	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
 	"github.com/wolfi-dev/wolfictl/pkg/distro"
@@ -38,6 +37,12 @@ It looks for issues like:
 * Enum fields with an unrecognized value
 * Basic business logic checks
 
+With --deep, it also checks the semantics of the advisory data: event
+timestamps must be in chronological order, aliases must be well-formed CVE or
+GHSA IDs, false positive determinations must have a known type, and fixed
+versions must exist in the APKINDEX or in the Git history of the package's
+build configuration in the distro repo.
+
 It also looks for issues in the _changes_ introduced by the current state of the
 advisories repo, relative to a "base state" (such as the last known state of
 the upstream repo's main branch). For example, it will detect if an advisory
@@ -156,13 +161,13 @@ print an error message that specifies where and how the data is invalid.`,
 
 				logger.Debug("cloned upstream advisories repo for comparison", "dir", cloneDir)
 
-				baseAdvisoriesIndex, err = adv2.NewIndex(cmd.Context(), rwos.DirFS(cloneDir))
+				baseAdvisoriesIndex, err = advisory.IndexWithArchive(cmd.Context(), os.DirFS(cloneDir))
 				if err != nil {
 					return fmt.Errorf("unable to create index of upstream advisories for comparison: %w", err)
 				}
 			}
 
-			advisoriesIndex, err := adv2.NewIndex(cmd.Context(), rwos.DirFS(advisoriesRepoDir))
+			advisoriesIndex, err := advisory.IndexWithArchive(cmd.Context(), os.DirFS(advisoriesRepoDir))
 			if err != nil {
 				return fmt.Errorf("unable to create index of advisories repo: %w", err)
 			}
@@ -191,6 +196,14 @@ print an error message that specifies where and how the data is invalid.`,
 				selectedPackageSet[pkg] = struct{}{}
 			}
 
+			var history advisory.PackageVersionHistory
+			if p.deep {
+				history, err = advisory.NewGitPackageVersionHistory(packagesRepoDir)
+				if err != nil {
+					return fmt.Errorf("unable to read package version history from distro repo: %w", err)
+				}
+			}
+
 			opts := advisory.ValidateOptions{
 				AdvisoryDocs:          advisoriesIndex,
 				BaseAdvisoryDocs:      baseAdvisoriesIndex,
@@ -199,6 +212,8 @@ print an error message that specifies where and how the data is invalid.`,
 				AliasFinder:           af,
 				PackageConfigurations: packageConfigurationsIndex,
 				APKIndex:              apkIndex,
+				Deep:                  p.deep,
+				PackageVersionHistory: history,
 			}
 
 			validationErr := advisory.Validate(ctx, opts)
@@ -234,6 +249,7 @@ type validateParams struct {
 	skipAliasCompletenessValidation bool
 	skipPackageExistenceValidation  bool
 	packageRepositoryURL            string
+	deep                            bool
 }
 
 const (
@@ -242,6 +258,7 @@ const (
 	flagNameSkipDiffValidation     = "skip-diff"
 	flagNameSkipAliasCompleteness  = "skip-alias"
 	flagNameSkipPackageExistence   = "skip-existence"
+	flagNameDeep                   = "deep"
 )
 
 func (p *validateParams) addFlagsTo(cmd *cobra.Command) {
@@ -255,6 +272,7 @@ func (p *validateParams) addFlagsTo(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&p.skipAliasCompletenessValidation, flagNameSkipAliasCompleteness, true, "skip alias completeness validation")
 	cmd.Flags().BoolVar(&p.skipPackageExistenceValidation, flagNameSkipPackageExistence, false, "skip package configuration existence validation")
 	addPackageRepoURLFlag(&p.packageRepositoryURL, cmd)
+	cmd.Flags().BoolVar(&p.deep, flagNameDeep, false, "also validate the semantics of the advisory data, such as event order and fixed version existence")
 }
 
 func renderValidationError(err error, depth int) string {
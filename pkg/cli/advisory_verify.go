@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+)
+
+func cmdAdvisoryVerify() *cobra.Command {
+	p := &verifyParams{}
+	cmd := &cobra.Command{
+		Use:   "verify ARTIFACT",
+		Short: "Verify the signature of exported advisory data",
+		Long: `Verify the signature of exported advisory data.
+
+ARTIFACT is a file or directory written and signed (using --sign) by one of the
+commands that export advisory data, like "wolfictl adv secdb", "wolfictl adv
+osv" and "wolfictl adv export". Its Sigstore bundle is expected next to it,
+with the suffix "` + advisory.SignatureBundleSuffix + `".
+
+For a directory, the signature of its ` + advisory.ChecksumsFileName + ` file is verified, followed by
+the digests of the files in the directory. Files that aren't listed in the
+checksums, or that are listed but missing, fail the verification.
+
+The expected signer is given either as a public key (--key), or for keyless
+signatures, as the identity in the signing certificate and the OIDC issuer of
+that identity (--certificate-identity and --certificate-oidc-issuer).
+
+Verification requires the cosign executable to be in the PATH.`,
+		Example: `
+wolfictl adv verify security.json --key cosign.pub
+
+wolfictl adv verify osv/ \
+  --certificate-identity https://github.com/wolfi-dev/advisories/.github/workflows/release.yaml@refs/heads/main \
+  --certificate-oidc-issuer https://token.actions.githubusercontent.com`,
+		SilenceErrors: true,
+		Deprecated:    advisoryDeprecationMessage,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if p.key != "" && (p.certificateIdentity != "" || p.certificateOIDCIssuer != "") {
+				return fmt.Errorf("cannot use --key with --certificate-identity or --certificate-oidc-issuer")
+			}
+			if p.key == "" && (p.certificateIdentity == "" || p.certificateOIDCIssuer == "") {
+				return fmt.Errorf("either --key, or both --certificate-identity and --certificate-oidc-issuer, must be specified")
+			}
+
+			artifact := args[0]
+			err := advisory.VerifyArtifact(cmd.Context(), artifact, advisory.VerifyOptions{
+				Key:                   p.key,
+				CertificateIdentity:   p.certificateIdentity,
+				CertificateOIDCIssuer: p.certificateOIDCIssuer,
+			})
+			if err != nil {
+				return fmt.Errorf("verification of %q failed: %w", artifact, err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Verified %s\n", artifact)
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type verifyParams struct {
+	key                   string
+	certificateIdentity   string
+	certificateOIDCIssuer string
+}
+
+func (p *verifyParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.key, "key", "", "cosign public key (path or KMS URI) to verify the signature with")
+	cmd.Flags().StringVar(&p.certificateIdentity, "certificate-identity", "", "identity expected in the signing certificate of a keyless signature")
+	cmd.Flags().StringVar(&p.certificateOIDCIssuer, "certificate-oidc-issuer", "", "OIDC issuer of the identity expected in the signing certificate of a keyless signature")
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/sbom"
+)
+
+func cmdCache() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage wolfictl's local caches",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(
+		cmdCacheGC(),
+	)
+
+	return cmd
+}
+
+func cmdCacheGC() *cobra.Command {
+	p := &cacheGCParams{}
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove stale entries from the SBOM cache",
+		Long: `Remove stale entries from the SBOM cache, which is stored in
+"wolfictl/sbom/apk" within the user's XDG cache directory.
+
+SBOMs generated by "wolfictl sbom" and "wolfictl scan" are cached by the APK's
+digest, the distro, and the configuration used to catalog the APK's contents.
+Since the cache is never cleaned up automatically, hosts that scan many APKs
+(such as CI runners) should run this command periodically.
+
+Entries that haven't been used for longer than --max-age are removed. Then, if
+the cache is still larger than --max-size, the least recently used entries are
+removed until it fits. At least one of these limits must be specified.
+`,
+		Example: `
+# Remove cached SBOMs that haven't been used in the last week
+wolfictl cache gc --max-age 168h
+
+# Keep the cache under 5 GB, and show what would be removed
+wolfictl cache gc --max-size 5GB --dry-run
+`,
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if p.maxAge == 0 && p.maxSize == "" {
+				return errors.New("at least one of --max-age or --max-size must be specified")
+			}
+			if p.maxAge < 0 {
+				return fmt.Errorf("invalid max age %q, must not be negative", p.maxAge)
+			}
+
+			opts := sbom.CacheGCOptions{
+				MaxAge: p.maxAge,
+				DryRun: p.dryRun,
+			}
+
+			if p.maxSize != "" {
+				maxSize, err := humanize.ParseBytes(p.maxSize)
+				if err != nil {
+					return fmt.Errorf("invalid max size %q: %w", p.maxSize, err)
+				}
+				opts.MaxSize = int64(maxSize) //nolint:gosec // sizes larger than MaxInt64 aren't meaningful here
+			}
+
+			result, err := sbom.GarbageCollectCache(ctx, "", opts)
+			if err != nil {
+				return err
+			}
+
+			verb := "Removed"
+			if p.dryRun {
+				verb = "Would remove"
+			}
+			fmt.Printf(
+				"%s %d cached SBOMs (%s), %d remaining (%s)\n",
+				verb,
+				result.RemovedEntries,
+				humanize.Bytes(uint64(result.RemovedBytes)), //nolint:gosec // sizes aren't negative
+				result.RemainingEntries,
+				humanize.Bytes(uint64(result.RemainingBytes)), //nolint:gosec // sizes aren't negative
+			)
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type cacheGCParams struct {
+	maxAge  time.Duration
+	maxSize string
+	dryRun  bool
+}
+
+func (p *cacheGCParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&p.maxAge, "max-age", 0, "remove entries that haven't been used for longer than this duration (e.g. 168h)")
+	cmd.Flags().StringVar(&p.maxSize, "max-size", "", "maximum total size of the cache (e.g. 5GB, 500MiB)")
+	cmd.Flags().BoolVar(&p.dryRun, "dry-run", false, "show how many entries would be removed, without removing them")
+}
@@ -28,6 +28,7 @@ func New() *cobra.Command {
 		cmdAdvisory(),
 		cmdApk(),
 		cmdBump(),
+		cmdCache(),
 		cmdCheck(),
 		cmdGh(),
 		cmdImage(),
@@ -89,6 +89,62 @@ func renderAdvisoryPathParts(adv *v2.Advisory) []string {
 	return parts
 }
 
+func renderAdvisoryStatus(advisoryID, status string) string {
+	return fmt.Sprintf("📝 %s: %s", vulnid.Hyperlink(advisoryID), styles.Bold().Render(status))
+}
+
+func renderArches(arches []string) string {
+	if len(arches) == 0 {
+		return ""
+	}
+
+	return styles.Faint().Render(" [" + strings.Join(arches, ", ") + "]")
+}
+
+func renderSources(sources []string) string {
+	if len(sources) == 0 {
+		return ""
+	}
+
+	return styles.Faint().Render(" via " + strings.Join(sources, ", "))
+}
+
+func renderKEV(entry *scan.KEVEntry) string {
+	return fmt.Sprintf(
+		"🔥 %s %s",
+		styles.Bold().Render("Known exploited"),
+		styles.Faint().Render("(CISA KEV, added "+entry.DateAdded+")"),
+	)
+}
+
+func renderReachability(r *scan.Reachability) string {
+	if r.Status == scan.ReachabilityReachable {
+		return fmt.Sprintf(
+			"🎯 %s %s",
+			styles.Bold().Render("Reachable"),
+			styles.Faint().Render("(govulncheck: "+strings.Join(r.Symbols, ", ")+")"),
+		)
+	}
+
+	text := "Vulnerable package not imported"
+	if r.Status == scan.ReachabilityImportedNotReachable {
+		text = "Imported but not reachable"
+	}
+	if r.DemotedFrom != "" {
+		text += ", demoted from " + r.DemotedFrom
+	}
+
+	return "💤 " + styles.Faint().Render(text+" (govulncheck)")
+}
+
+func renderTriageSuggestion(suggestion *scan.TriageSuggestion) string {
+	return fmt.Sprintf(
+		"💡 %s %s",
+		styles.Italic().Render("Suggested triage: "+suggestion.Reason),
+		styles.Faint().Render("("+suggestion.EventType+")"),
+	)
+}
+
 func daysAgo(t time.Time) int {
 	now := time.Now()
 	duration := now.Sub(t)
@@ -96,6 +152,57 @@ func daysAgo(t time.Time) int {
 	return days
 }
 
+// RenderSuppressed renders the given suppressed findings as a list, including
+// the justification for each suppression.
+func RenderSuppressed(suppressed []scan.SuppressedFinding) string {
+	if len(suppressed) == 0 {
+		return ""
+	}
+
+	lines := []string{fmt.Sprintf("🔇 Suppressed %d finding(s) using ignore rules:", len(suppressed))}
+	for i := range suppressed {
+		f := &suppressed[i].Finding
+		rule := &suppressed[i].Rule
+
+		line := fmt.Sprintf(
+			"  - %s %s in %s %s: %s",
+			renderSeverity(f.Vulnerability.Severity),
+			renderVulnerabilityID(f.Vulnerability),
+			f.Package.Name,
+			f.Package.Version,
+			styles.Italic().Render(rule.Justification),
+		)
+		if rule.Expires != "" {
+			line += styles.Faint().Render(" (until " + rule.Expires + ")")
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// RenderTrendFindings renders the given findings from a scan trend as a list,
+// including when each finding was first seen.
+func RenderTrendFindings(findings []scan.TrendFinding) string {
+	lines := make([]string, 0, len(findings))
+	for i := range findings {
+		f := &findings[i].Finding
+		firstSeen := findings[i].FirstSeen
+
+		lines = append(lines, fmt.Sprintf(
+			"  - %s %s in %s %s %s",
+			renderSeverity(f.Vulnerability.Severity),
+			renderVulnerabilityID(f.Vulnerability),
+			f.Package.Name,
+			f.Package.Version,
+			styles.Faint().Render(fmt.Sprintf("(since %s, %d days)", firstSeen.Format("2006-01-02"), daysAgo(firstSeen))),
+		))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 func Render(findings []scan.Finding) (string, error) {
 	if len(findings) == 0 {
 		return noVulnerabilitiesFound, nil
@@ -114,15 +221,39 @@ func Render(findings []scan.Finding) (string, error) {
 				styles.Faint().Render("("+f.Package.Type+")"),
 			),
 			fmt.Sprintf(
-				"%s %s%s",
+				"%s %s%s%s%s",
 				renderSeverity(f.Vulnerability.Severity),
 				renderVulnerabilityID(f.Vulnerability),
 				renderFixedIn(f.Vulnerability),
+				renderArches(f.Arches),
+				renderSources(f.Sources),
 			),
 		}
 
-		if f.Advisory != nil { //nolint:staticcheck // TODO: use advisory.Getter to lookup the advisory instead.
+		if f.KEV != nil {
+			pathParts = append(pathParts, renderKEV(f.KEV))
+		}
+
+		if f.Reachability != nil {
+			pathParts = append(pathParts, renderReachability(f.Reachability))
+		}
+
+		switch {
+		case f.Advisory != nil: //nolint:staticcheck // TODO: use advisory.Getter to lookup the advisory instead.
 			pathParts = append(pathParts, renderAdvisoryPathParts(f.Advisory)...) //nolint:staticcheck // TODO: use advisory.Getter to lookup the advisory instead.
+
+		case f.AdvisoryStatus != "" && f.AdvisoryStatus != scan.AdvisoryStatusNone:
+			pathParts = append(pathParts, renderAdvisoryStatus(f.CGAID, f.AdvisoryStatus))
+
+		default:
+			if f.AdvisoryStatus == scan.AdvisoryStatusNone {
+				pathParts = append(pathParts, "⚠️ "+styles.Bold().Render("No advisory"))
+			}
+
+			// Only suggest triage for findings that haven't been triaged yet.
+			if f.TriageSuggestion != nil {
+				pathParts = append(pathParts, renderTriageSuggestion(f.TriageSuggestion))
+			}
 		}
 
 		return pathParts
@@ -0,0 +1,30 @@
+package cli
+
+import "fmt"
+
+const (
+	// exitCodeFindings is the exit code used when a command completes
+	// successfully, but finds something it was asked to fail on (e.g.
+	// vulnerabilities at or above a severity threshold). Other errors result in an
+	// exit code of 1.
+	exitCodeFindings = 2
+)
+
+// ExitError is an error that should cause wolfictl to exit with a specific
+// status code, rather than the default of 1.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
@@ -49,36 +49,44 @@ func (o lintOptions) LintCmd(ctx context.Context) error {
 	}
 
 	// Run the linter.
-	minSeverity := lint.SeverityWarning
-	switch o.severity {
-	case "error", "ERROR":
-		minSeverity = lint.SeverityError
-	case "info", "INFO":
-		minSeverity = lint.SeverityInfo
-	}
-	result, err := linter.Lint(ctx, minSeverity)
+	result, err := linter.Lint(ctx, parseLintSeverity(o.severity))
 	if err != nil {
 		return err
 	}
 	if result.HasErrors() {
 		linter.Print(ctx, result)
 		// only count errors as failures, not warnings.
-		failed := false
-		for _, res := range result {
-			for _, e := range res.Errors {
-				if e.Rule.Severity.Value == lint.SeverityErrorLevel {
-					failed = true
-					break
-				}
-			}
-		}
-		if failed {
+		if lintResultFailed(result) {
 			return errors.New("linting failed")
 		}
 	}
 	return nil
 }
 
+// parseLintSeverity returns the minimum severity level named by the --severity
+// flag value, defaulting to warning.
+func parseLintSeverity(severity string) lint.Severity {
+	switch severity {
+	case "error", "ERROR":
+		return lint.SeverityError
+	case "info", "INFO":
+		return lint.SeverityInfo
+	}
+	return lint.SeverityWarning
+}
+
+// lintResultFailed returns true if the result has any error-level issues.
+func lintResultFailed(result lint.Result) bool {
+	for _, res := range result {
+		for _, e := range res.Errors {
+			if e.Rule.Severity.Value == lint.SeverityErrorLevel {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (o lintOptions) makeLintOptions() []lint.Option {
 	if len(o.args) == 0 {
 		// Lint the current directory by default.
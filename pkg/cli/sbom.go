@@ -1,84 +1,277 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 
+	"chainguard.dev/apko/pkg/build/types"
+	"chainguard.dev/melange/pkg/config"
 	sbomSyft "github.com/anchore/syft/syft/sbom"
+	"github.com/chainguard-dev/clog"
 	"github.com/spf13/cobra"
 	"github.com/wolfi-dev/wolfictl/pkg/cli/components/sbompackages"
 	"github.com/wolfi-dev/wolfictl/pkg/sbom"
 	"golang.org/x/exp/slices"
+	"sigs.k8s.io/release-utils/version"
 )
 
 const (
-	sbomFormatOutline  = "outline"
-	sbomFormatSyftJSON = "syft-json"
+	sbomFormatOutline       = "outline"
+	sbomFormatSyftJSON      = "syft-json"
+	sbomFormatSPDXJSON      = "spdx-json"
+	sbomFormatCycloneDXJSON = "cyclonedx-json"
 )
 
+var validSBOMFormats = []string{sbomFormatOutline, sbomFormatSyftJSON, sbomFormatSPDXJSON, sbomFormatCycloneDXJSON}
+
+// sbomFileExtensions are the file extensions of the SBOMs written to the
+// --output-dir directory, for each JSON output format.
+var sbomFileExtensions = map[string]string{
+	sbomFormatSyftJSON:      ".syft.json",
+	sbomFormatSPDXJSON:      ".spdx.json",
+	sbomFormatCycloneDXJSON: ".cdx.json",
+}
+
 func cmdSBOM() *cobra.Command {
 	p := &sbomParams{}
 	cmd := &cobra.Command{
-		Use:           "sbom <path/to/package.apk>",
-		Short:         "Generate a software bill of materials (SBOM) for an APK file",
+		Use:   "sbom <path/to/package.apk>...",
+		Short: "Generate a software bill of materials (SBOM) for an APK file",
+		Long: `Generate a software bill of materials (SBOM) for an APK file.
+
+When more than one APK file is given, their SBOMs are merged into a single SBOM
+for a filesystem (such as a container image) in which all of the APKs are
+installed. The merged SBOM has a package representing the image (named with
+--image-name), which contains each APK, which in turn contains the packages
+found in it. With --installed, the APKs listed in an image's installed database
+("lib/apk/db/installed") are used instead, and the arguments are the
+directories in which to find the APK files. With --image, the argument is
+instead a container image reference: the APKs installed in the image are
+downloaded from the image's APK repositories (and any given with --repository).
+With --output-dir, each APK's SBOM is written to the directory instead of a
+merged SBOM.
+
+With --build-time, the argument is instead a melange configuration file, and the
+SBOM describes the package's build-time dependencies: the packages installed in
+its build environment and the sources fetched by its pipelines, with their
+expected checksums or commits.
+
+With --vex, an OpenVEX document is also written, with statements about the
+vulnerabilities of the SBOM's APKs that are fixed in (or don't affect) their
+versions, according to the advisories in the repositories given with
+--advisories-repo-dir. The document's products are identified by the same
+package URLs as the APKs in the SBOM.`,
 		Hidden:        true,
 		SilenceErrors: true,
-		Args:          cobra.ExactArgs(1),
+		Args:          cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
-			if !slices.Contains([]string{sbomFormatOutline, sbomFormatSyftJSON}, p.outputFormat) {
-				return fmt.Errorf("invalid output format %q, must be one of [%s]", p.outputFormat, strings.Join([]string{sbomFormatOutline, sbomFormatSyftJSON}, ", "))
+			if !slices.Contains(validSBOMFormats, p.outputFormat) {
+				return fmt.Errorf("invalid output format %q, must be one of [%s]", p.outputFormat, strings.Join(validSBOMFormats, ", "))
+			}
+
+			if p.attest && p.outputFormat == sbomFormatOutline {
+				return fmt.Errorf("cannot use --attest with %s output, use a JSON output format", sbomFormatOutline)
+			}
+			if p.attestKey != "" && !p.attest {
+				return errors.New("cannot use --attest-key without --attest")
+			}
+			if p.attest && p.buildTime {
+				return errors.New("cannot use --attest with --build-time")
+			}
+			if cmd.Flags().Changed("arch") && !p.buildTime && !p.image {
+				return errors.New("cannot use --arch without --build-time or --image, the architecture of an APK is read from the APK")
+			}
+			if p.includeFiles && p.buildTime {
+				return errors.New("cannot use --files with --build-time")
+			}
+			if p.includeDependencies && p.buildTime {
+				return errors.New("cannot use --dependencies with --build-time")
+			}
+			if (len(p.enableCatalogers) > 0 || len(p.disableCatalogers) > 0) && p.buildTime {
+				return errors.New("cannot use --enable-catalogers or --disable-catalogers with --build-time")
+			}
+			if p.installedPath != "" && p.buildTime {
+				return errors.New("cannot use --installed with --build-time")
+			}
+
+			if p.image && (p.buildTime || p.installedPath != "") {
+				return errors.New("cannot use --image with --build-time or --installed")
+			}
+			if p.image && len(args) > 1 {
+				return errors.New("cannot use --image with more than one image reference")
+			}
+			if p.outputDir != "" && p.outputFormat == sbomFormatOutline {
+				return fmt.Errorf("cannot use --output-dir with %s output, use a JSON output format", sbomFormatOutline)
+			}
+			if p.outputDir != "" && (p.buildTime || p.attest) {
+				return errors.New("cannot use --output-dir with --build-time or --attest")
+			}
+			if p.vexPath != "" && len(p.advisoriesRepoDirs) == 0 {
+				return fmt.Errorf("cannot use --vex without --%s", flagNameAdvisoriesRepoDir)
+			}
+			if p.vexPath != "" && (p.buildTime || p.outputDir != "") {
+				return errors.New("cannot use --vex with --build-time or --output-dir")
+			}
+
+			compose := len(args) > 1 || p.installedPath != "" || p.image
+			if compose && p.buildTime {
+				return errors.New("cannot use --build-time with more than one melange configuration")
+			}
+			if compose && p.attest {
+				return errors.New("cannot use --attest with more than one APK")
 			}
 
 			// TODO: Bring input retrieval options in line with `wolfictl scan`.
 
-			apkFilePath := args[0]
-			apkFile, err := os.Open(apkFilePath)
-			if err != nil {
-				return fmt.Errorf("failed to open apk file: %w", err)
+			upstreamSources := make(sbom.UpstreamSourcesByPackage)
+			for _, path := range p.melangeConfigs {
+				cfg, err := config.ParseConfiguration(ctx, path)
+				if err != nil {
+					return fmt.Errorf("failed to parse melange configuration %q: %w", path, err)
+				}
+				upstreamSources.Add(cfg)
 			}
 
-			if p.outputFormat == outputFormatOutline {
-				fmt.Printf("🔎 Scanning %q\n", apkFilePath)
+			apkFilePaths := args
+			imageName := p.imageName
+			switch {
+			case p.image:
+				ref := args[0]
+				if !cmd.Flags().Changed("image-name") {
+					imageName = ref
+				}
+
+				imageAPKs, err := sbom.APKsFromImage(ctx, ref, p.arch)
+				if err != nil {
+					return fmt.Errorf("failed to read installed APKs: %w", err)
+				}
+
+				dir, err := os.MkdirTemp("", "wolfictl-sbom-image-*")
+				if err != nil {
+					return fmt.Errorf("failed to create temp directory: %w", err)
+				}
+				defer os.RemoveAll(dir)
+
+				if p.outputFormat == sbomFormatOutline {
+					fmt.Printf("📡 Downloading %d APKs installed in %q\n", len(imageAPKs.Packages), ref)
+				}
+				apkFilePaths, err = sbom.DownloadAPKs(ctx, imageAPKs.Packages, append(imageAPKs.Repositories, p.repositories...), dir)
+				if err != nil {
+					return fmt.Errorf("failed to download installed APKs: %w", err)
+				}
+
+			case p.installedPath != "":
+				installed, err := os.Open(p.installedPath)
+				if err != nil {
+					return fmt.Errorf("failed to open installed database: %w", err)
+				}
+				defer installed.Close()
+
+				apkFilePaths, err = sbom.APKsFromInstalled(installed, args...)
+				if err != nil {
+					return fmt.Errorf("failed to find installed APKs: %w", err)
+				}
 			}
 
-			var s *sbomSyft.SBOM
-			if p.disableSBOMCache {
-				s, err = sbom.Generate(ctx, apkFilePath, apkFile, p.distro)
-			} else {
-				s, err = sbom.CachedGenerate(ctx, apkFilePath, apkFile, p.distro)
+			if p.outputDir != "" {
+				return p.writeAPKSBOMs(ctx, apkFilePaths, upstreamSources)
+			}
+
+			apkFilePath := apkFilePaths[0]
+
+			var (
+				s   *sbomSyft.SBOM
+				err error
+			)
+			switch {
+			case p.buildTime:
+				if p.outputFormat == outputFormatOutline {
+					fmt.Printf("🔎 Scanning %q\n", apkFilePath)
+				}
+				s, err = sbom.GenerateForMelangeConfig(ctx, apkFilePath, p.distro, p.arch)
+
+			case compose:
+				apkSBOMs := make([]*sbomSyft.SBOM, 0, len(apkFilePaths))
+				for _, path := range apkFilePaths {
+					if p.outputFormat == outputFormatOutline {
+						fmt.Printf("🔎 Scanning %q\n", path)
+					}
+
+					apkSBOM, err := p.generateAPKSBOM(ctx, path)
+					if err != nil {
+						return fmt.Errorf("failed to generate SBOM for %q: %w", path, err)
+					}
+					apkSBOMs = append(apkSBOMs, apkSBOM)
+				}
+				s, err = sbom.Compose(ctx, imageName, p.distro, apkSBOMs...)
+
+			default:
+				if p.outputFormat == outputFormatOutline {
+					fmt.Printf("🔎 Scanning %q\n", apkFilePath)
+				}
+				s, err = p.generateAPKSBOM(ctx, apkFilePath)
 			}
 			if err != nil {
 				return fmt.Errorf("failed to generate SBOM: %w", err)
 			}
 
-			switch p.outputFormat {
-			case sbomFormatOutline:
+			if p.vexPath != "" {
+				if err := p.writeVEX(ctx, s); err != nil {
+					return err
+				}
+			}
+
+			if p.outputFormat == sbomFormatOutline {
 				tree, err := sbompackages.Render(s.Artifacts.Packages.Sorted())
 				if err != nil {
 					return fmt.Errorf("rendering package tree: %w", err)
 				}
 				fmt.Println(tree)
 
-			case sbomFormatSyftJSON:
-				jsonReader, err := sbom.ToSyftJSON(s)
-				if err != nil {
-					return fmt.Errorf("failed to encode SBOM: %w", err)
+				return nil
+			}
+
+			jsonReader, predicateType, err := p.encode(s, upstreamSources)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(os.Stdout, jsonReader)
+			if err != nil {
+				return fmt.Errorf("failed to write SBOM: %w", err)
+			}
+
+			if p.attest {
+				if _, err := jsonReader.Seek(0, io.SeekStart); err != nil {
+					return fmt.Errorf("failed to rewind SBOM: %w", err)
 				}
 
-				_, err = io.Copy(os.Stdout, jsonReader)
+				attestationPath, err := sbom.Attest(ctx, apkFilePath, jsonReader, predicateType, sbom.AttestOptions{
+					Key: p.attestKey,
+				})
 				if err != nil {
-					return fmt.Errorf("failed to write SBOM: %w", err)
+					return fmt.Errorf("failed to sign SBOM attestation: %w", err)
 				}
+
+				clog.FromContext(ctx).Info("wrote signed SBOM attestation", "path", attestationPath)
 			}
 
 			return nil
 		},
 	}
 
+	cmd.AddCommand(cmdSBOMValidate())
+	cmd.AddCommand(cmdSBOMVerify())
+	cmd.AddCommand(cmdSBOMExplain())
+
 	p.addFlagsTo(cmd)
 	return cmd
 }
@@ -87,10 +280,173 @@ type sbomParams struct {
 	outputFormat     string
 	distro           string
 	disableSBOMCache bool
+
+	spdxNamespaceBase string
+
+	attest    bool
+	attestKey string
+
+	buildTime bool
+	arch      string
+
+	installedPath string
+	imageName     string
+	image         bool
+	repositories  []string
+	outputDir     string
+
+	includeFiles        bool
+	includeDependencies bool
+	deduplicate         bool
+
+	melangeConfigs []string
+
+	vexPath            string
+	advisoriesRepoDirs []string
+
+	enableCatalogers  []string
+	disableCatalogers []string
+}
+
+// generateAPKSBOM generates the SBOM for the APK file at the given path, using
+// the SBOM cache unless it's disabled.
+func (p *sbomParams) generateAPKSBOM(ctx context.Context, apkFilePath string) (*sbomSyft.SBOM, error) {
+	apkFile, err := os.Open(apkFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open apk file: %w", err)
+	}
+	defer apkFile.Close()
+
+	opts := sbom.GenerateOptions{
+		IncludeFiles:        p.includeFiles,
+		IncludeDependencies: p.includeDependencies,
+		Deduplicate:         p.deduplicate,
+		EnableCatalogers:    p.enableCatalogers,
+		DisableCatalogers:   p.disableCatalogers,
+	}
+
+	if p.disableSBOMCache {
+		return sbom.GenerateWithOptions(ctx, apkFilePath, apkFile, p.distro, opts)
+	}
+	return sbom.CachedGenerateWithOptions(ctx, apkFilePath, apkFile, p.distro, opts)
+}
+
+// encode encodes the SBOM in the JSON output format, and returns it along with
+// the in-toto predicate type of the format.
+func (p *sbomParams) encode(s *sbomSyft.SBOM, upstreamSources sbom.UpstreamSourcesByPackage) (io.ReadSeeker, string, error) {
+	var (
+		jsonReader    io.ReadSeeker
+		predicateType string
+		err           error
+	)
+	switch p.outputFormat {
+	case sbomFormatSyftJSON:
+		jsonReader, err = sbom.ToSyftJSON(s)
+		predicateType = sbom.PredicateTypeSyft
+
+	case sbomFormatSPDXJSON:
+		jsonReader, err = sbom.ToSPDXJSON(s, sbom.SPDXOptions{
+			NamespaceBase:   p.spdxNamespaceBase,
+			ToolVersion:     version.GetVersionInfo().GitVersion,
+			UpstreamSources: upstreamSources,
+		})
+		predicateType = sbom.PredicateTypeSPDX
+
+	case sbomFormatCycloneDXJSON:
+		jsonReader, err = sbom.ToCycloneDXJSON(s, sbom.CycloneDXOptions{
+			ToolVersion:     version.GetVersionInfo().GitVersion,
+			UpstreamSources: upstreamSources,
+		})
+		predicateType = sbom.PredicateTypeCycloneDX
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode SBOM: %w", err)
+	}
+
+	return jsonReader, predicateType, nil
+}
+
+// writeAPKSBOMs writes the SBOM of each of the given APK files to the output
+// directory, named after the APK file.
+func (p *sbomParams) writeAPKSBOMs(ctx context.Context, apkFilePaths []string, upstreamSources sbom.UpstreamSourcesByPackage) error {
+	if err := os.MkdirAll(p.outputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, path := range apkFilePaths {
+		s, err := p.generateAPKSBOM(ctx, path)
+		if err != nil {
+			return fmt.Errorf("failed to generate SBOM for %q: %w", path, err)
+		}
+
+		jsonReader, _, err := p.encode(s, upstreamSources)
+		if err != nil {
+			return err
+		}
+
+		outputPath := filepath.Join(p.outputDir, strings.TrimSuffix(filepath.Base(path), ".apk")+sbomFileExtensions[p.outputFormat])
+		if err := writeFile(outputPath, jsonReader); err != nil {
+			return fmt.Errorf("failed to write SBOM: %w", err)
+		}
+
+		clog.FromContext(ctx).Info("wrote SBOM", "apk", path, "path", outputPath)
+	}
+
+	return nil
+}
+
+// writeVEX writes an OpenVEX document for the SBOM's APKs to the --vex path,
+// using the advisories in the given advisories repositories.
+func (p *sbomParams) writeVEX(ctx context.Context, s *sbomSyft.SBOM) error {
+	vexReader, err := sbom.ToOpenVEXJSON(ctx, s, newAdvisoriesGetter(p.advisoriesRepoDirs), sbom.OpenVEXOptions{
+		ToolVersion: version.GetVersionInfo().GitVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create OpenVEX document: %w", err)
+	}
+
+	if err := writeFile(p.vexPath, vexReader); err != nil {
+		return fmt.Errorf("failed to write OpenVEX document: %w", err)
+	}
+
+	clog.FromContext(ctx).Info("wrote OpenVEX document", "path", p.vexPath)
+	return nil
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
 }
 
 func (p *sbomParams) addFlagsTo(cmd *cobra.Command) {
-	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", sbomFormatOutline, "output format (outline, syft-json)")
-	cmd.Flags().StringVar(&p.distro, "distro", "wolfi", "distro to report in SBOM")
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", sbomFormatOutline, fmt.Sprintf("output format (%s)", strings.Join(validSBOMFormats, ", ")))
+	cmd.Flags().StringVar(&p.distro, "distro", "wolfi", "distro to report in SBOM and in the \"distro\" qualifier of APK package URLs")
 	cmd.Flags().BoolVarP(&p.disableSBOMCache, "disable-sbom-cache", "D", false, "don't use the SBOM cache")
+	cmd.Flags().StringVar(&p.spdxNamespaceBase, "spdx-namespace", sbom.DefaultSPDXNamespaceBase, "base URI of the SPDX document namespace, to which a unique suffix is appended (only used with spdx-json output)")
+	cmd.Flags().BoolVar(&p.attest, "attest", false, "sign the SBOM as an in-toto attestation using cosign, and write it next to the APK (with the suffix \""+sbom.AttestationSuffix+"\")")
+	cmd.Flags().BoolVar(&p.buildTime, "build-time", false, "treat the argument as a melange configuration file and generate an SBOM of the package's build-time dependencies")
+	cmd.Flags().StringVar(&p.arch, "arch", types.ParseArchitecture(runtime.GOARCH).ToAPK(), "architecture to report in package URLs with --build-time, or of the image to use with --image")
+	cmd.Flags().BoolVar(&p.includeFiles, "files", false, "include the APK's files in the SBOM, with their sha256 digests and their relationships to the APK")
+	cmd.Flags().BoolVar(&p.includeDependencies, "dependencies", false, "include the APK's runtime dependencies (its \"depend\" entries) in the SBOM, with their relationships to the APK")
+	cmd.Flags().BoolVar(&p.deduplicate, "deduplicate", false, "collapse packages found by more than one cataloger (with the same package URL) into one package, with the evidence of each")
+	cmd.Flags().StringSliceVar(&p.melangeConfigs, "melange-config", nil, "melange configuration files of the APKs, whose upstream sources (git repositories with their commits, and source archives with their checksums) are added to the APK components (only used with spdx-json and cyclonedx-json output)")
+	cmd.Flags().StringSliceVar(&p.enableCatalogers, "enable-catalogers", nil, fmt.Sprintf("names of Syft catalogers to run in addition to the defaults, including those disabled by default (%s)", strings.Join(sbom.DefaultDisabledCatalogers, ", ")))
+	cmd.Flags().StringSliceVar(&p.disableCatalogers, "disable-catalogers", nil, "names or tags of Syft catalogers not to run")
+	cmd.Flags().StringVar(&p.installedPath, "installed", "", "path to an image's APK installed database (\"lib/apk/db/installed\"), whose APKs are found in the directories given as arguments and merged into one SBOM")
+	cmd.Flags().StringVar(&p.imageName, "image-name", "image", "name of the image package in a merged SBOM (only used when merging the SBOMs of multiple APKs, defaults to the image reference with --image)")
+	cmd.Flags().BoolVar(&p.image, "image", false, "treat the argument as a container image reference, and generate an SBOM of the APKs installed in the image")
+	cmd.Flags().StringSliceVar(&p.repositories, "repository", nil, "APK repositories to download an image's APKs from, in addition to those configured in the image (only used with --image)")
+	cmd.Flags().StringVar(&p.outputDir, "output-dir", "", "directory to write the SBOM of each APK to, instead of writing a merged SBOM to stdout")
+	cmd.Flags().StringVar(&p.vexPath, "vex", "", "path to write an OpenVEX document to, with the fixed and not-affected statuses of the SBOM's APKs from the advisories repositories")
+	addAdvisoriesDirsFlag(&p.advisoriesRepoDirs, cmd)
+	cmd.Flags().StringVar(&p.attestKey, "attest-key", "", "cosign signing key (path or KMS URI) for --attest (if not specified, keyless signing is used)")
 }
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/styles"
+	"github.com/wolfi-dev/wolfictl/pkg/sbom"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	sbomExplainFormatText = "text"
+	sbomExplainFormatJSON = "json"
+)
+
+var validSBOMExplainFormats = []string{sbomExplainFormatText, sbomExplainFormatJSON}
+
+func cmdSBOMExplain() *cobra.Command {
+	p := &sbomExplainParams{}
+	cmd := &cobra.Command{
+		Use:   "explain <path/to/package.apk> <component name or purl>",
+		Short: "Show the evidence for a component of an APK's SBOM",
+		Long: `Show the evidence for a component of an APK's SBOM.
+
+This command generates the SBOM of the APK and shows how each matching
+component was detected: the catalogers that found it, the files it was found in,
+the metadata that the catalogers read from those files, and the package URL and
+CPEs generated from that metadata. This is useful for understanding why a
+vulnerability scanner matched (or didn't match) a vulnerability to the
+component.
+
+The component is given either as a package URL, whose version is optional (e.g.
+"pkg:golang/github.com/spf13/cobra"), or as a name, which can be a glob pattern
+(e.g. "golang.org/x/*").
+`,
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if !slices.Contains(validSBOMExplainFormats, p.outputFormat) {
+				return fmt.Errorf("invalid output format %q, must be one of [%s]", p.outputFormat, strings.Join(validSBOMExplainFormats, ", "))
+			}
+
+			generator := &sbomParams{
+				distro:            p.distro,
+				disableSBOMCache:  p.disableSBOMCache,
+				enableCatalogers:  p.enableCatalogers,
+				disableCatalogers: p.disableCatalogers,
+			}
+			s, err := generator.generateAPKSBOM(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to generate SBOM: %w", err)
+			}
+
+			evidence, err := sbom.Explain(s, args[1])
+			if err != nil {
+				return err
+			}
+
+			if p.outputFormat == sbomExplainFormatJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(evidence)
+			}
+
+			for i, e := range evidence {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Print(renderEvidence(e))
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type sbomExplainParams struct {
+	outputFormat      string
+	distro            string
+	disableSBOMCache  bool
+	enableCatalogers  []string
+	disableCatalogers []string
+}
+
+func (p *sbomExplainParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", sbomExplainFormatText, fmt.Sprintf("output format (%s)", strings.Join(validSBOMExplainFormats, ", ")))
+	cmd.Flags().StringVar(&p.distro, "distro", "wolfi", "distro to report in SBOM and in the \"distro\" qualifier of APK package URLs")
+	cmd.Flags().BoolVarP(&p.disableSBOMCache, "disable-sbom-cache", "D", false, "don't use the SBOM cache")
+	cmd.Flags().StringSliceVar(&p.enableCatalogers, "enable-catalogers", nil, "names of Syft catalogers to run in addition to the defaults")
+	cmd.Flags().StringSliceVar(&p.disableCatalogers, "disable-catalogers", nil, "names or tags of Syft catalogers not to run")
+}
+
+// renderEvidence renders the evidence for a component as text.
+func renderEvidence(e sbom.Evidence) string {
+	b := new(strings.Builder)
+
+	fmt.Fprintf(b, "📦 %s %s %s\n", e.Name, e.Version, styles.Faint().Render("("+e.Type+")"))
+
+	field := func(label string, values ...string) {
+		if len(values) == 0 {
+			return
+		}
+		fmt.Fprintf(b, "  %s\n", styles.Secondary().Render(label+":"))
+		for _, v := range values {
+			fmt.Fprintf(b, "    %s\n", v)
+		}
+	}
+
+	field("found by", e.FoundBy...)
+
+	var locations []string
+	for _, l := range e.Locations {
+		location := "/" + strings.TrimPrefix(l.Path, "/")
+		if l.AccessPath != "" {
+			location += fmt.Sprintf(" (via %s)", l.AccessPath)
+		}
+		if l.Evidence != "" {
+			location += " " + styles.Faint().Render("["+l.Evidence+" evidence]")
+		}
+		locations = append(locations, location)
+	}
+	field("locations", locations...)
+
+	if e.PURL != "" {
+		field("purl", e.PURL)
+	}
+
+	var cpes []string
+	for _, c := range e.CPEs {
+		cpe := c.CPE
+		if c.Source != "" {
+			cpe += " " + styles.Faint().Render("("+c.Source+")")
+		}
+		cpes = append(cpes, cpe)
+	}
+	field("cpes", cpes...)
+
+	field("licenses", e.Licenses...)
+	field("relationships", e.Relationships...)
+
+	if len(e.Metadata) > 0 {
+		indented := new(bytes.Buffer)
+		if err := json.Indent(indented, e.Metadata, "    ", "  "); err != nil {
+			indented.Write(e.Metadata)
+		}
+		fmt.Fprintf(b, "  %s\n    %s\n", styles.Secondary().Render("metadata ("+e.MetadataType+"):"), indented.String())
+	}
+
+	return b.String()
+}
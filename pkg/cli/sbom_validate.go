@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/sbom"
+)
+
+func cmdSBOMValidate() *cobra.Command {
+	p := &sbomValidateParams{}
+	cmd := &cobra.Command{
+		Use:   "validate <path/to/sbom.json>",
+		Short: "Validate an SBOM against the NTIA minimum elements",
+		Long: `Validate an SBOM against the NTIA minimum elements.
+
+This command checks that an SBOM (generated by wolfictl or by another tool) has
+the data fields required by the NTIA's "minimum elements for a software bill of
+materials": the author of the SBOM data, a timestamp, dependency relationships,
+and, for each component, its name, version, supplier name, and a unique
+identifier.
+
+Unless --ntia-only is specified, it also checks wolfictl's own conventions: each
+component must have a package URL (purl) and a checksum.
+
+For each problem found, the field of the SBOM's format that needs to be set is
+shown. SPDX 2.x JSON and CycloneDX JSON documents are supported.
+`,
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open SBOM: %w", err)
+			}
+			defer f.Close()
+
+			validationErr := sbom.Validate(f, sbom.ValidateOptions{
+				NTIAOnly: p.ntiaOnly,
+			})
+			if validationErr != nil {
+				fmt.Fprintf(
+					os.Stderr,
+					"❌ SBOM is not valid.\n\n%s\n",
+					renderValidationError(validationErr, 0),
+				)
+				os.Exit(1)
+			}
+
+			fmt.Fprint(os.Stderr, "✅ SBOM is valid.\n")
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type sbomValidateParams struct {
+	ntiaOnly bool
+}
+
+func (p *sbomValidateParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&p.ntiaOnly, "ntia-only", false, "check only the NTIA minimum elements, and not wolfictl's conventions")
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/sbom"
+)
+
+func cmdSBOMVerify() *cobra.Command {
+	p := &sbomVerifyParams{}
+	cmd := &cobra.Command{
+		Use:   "verify --expected <path/to/manifest.yaml> <path/to/package.apk>",
+		Short: "Verify that an APK's SBOM matches an expected manifest",
+		Long: `Verify that an APK's SBOM matches an expected manifest.
+
+This command generates the SBOM of the APK and checks its components against a
+manifest of the components expected in the APK, so that changes to what's
+shipped in a package (such as a new vendored dependency) can be caught in CI.
+The command fails if the SBOM has a component that the manifest doesn't allow,
+or if it's missing a component that the manifest requires.
+
+The manifest is a YAML file with "allowed" and "required" lists of components,
+each matched by any of "purl" (a package URL, whose version is optional),
+"name" (a glob pattern), and "type" (a Syft package type). If there's no
+"allowed" list, any component is allowed. For example:
+
+  allowed:
+    - purl: pkg:golang/github.com/spf13/cobra
+    - type: go-module
+      name: golang.org/x/*
+  required:
+    - purl: pkg:golang/stdlib
+`,
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if p.expectedPath == "" {
+				return errors.New("manifest must be specified with --expected")
+			}
+
+			mf, err := os.Open(p.expectedPath)
+			if err != nil {
+				return fmt.Errorf("failed to open manifest: %w", err)
+			}
+			defer mf.Close()
+
+			manifest, err := sbom.ParseManifest(mf)
+			if err != nil {
+				return fmt.Errorf("failed to parse manifest %q: %w", p.expectedPath, err)
+			}
+
+			generator := &sbomParams{
+				distro:           p.distro,
+				disableSBOMCache: p.disableSBOMCache,
+			}
+			s, err := generator.generateAPKSBOM(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to generate SBOM: %w", err)
+			}
+
+			if verifyErr := sbom.Verify(s, manifest); verifyErr != nil {
+				fmt.Fprintf(
+					os.Stderr,
+					"❌ SBOM doesn't match the expected manifest.\n\n%s\n",
+					renderValidationError(verifyErr, 0),
+				)
+				os.Exit(1)
+			}
+
+			fmt.Fprint(os.Stderr, "✅ SBOM matches the expected manifest.\n")
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type sbomVerifyParams struct {
+	expectedPath     string
+	distro           string
+	disableSBOMCache bool
+}
+
+func (p *sbomVerifyParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.expectedPath, "expected", "", "path to the manifest of the components expected in the APK's SBOM")
+	cmd.Flags().StringVar(&p.distro, "distro", "wolfi", "distro to report in SBOM and in the \"distro\" qualifier of APK package URLs")
+	cmd.Flags().BoolVarP(&p.disableSBOMCache, "disable-sbom-cache", "D", false, "don't use the SBOM cache")
+}
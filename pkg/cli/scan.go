@@ -14,10 +14,12 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"chainguard.dev/apko/pkg/apk/apk"
 	"chainguard.dev/apko/pkg/apk/auth"
 	"chainguard.dev/apko/pkg/apk/client"
+	"chainguard.dev/melange/pkg/config"
 	sbomSyft "github.com/anchore/syft/syft/sbom"
 	"github.com/chainguard-dev/clog"
 	"github.com/samber/lo"
@@ -36,20 +38,28 @@ const (
 	outputFormatOutline = "outline"
 	outputFormatTable   = "table"
 	outputFormatJSON    = "json"
+	outputFormatYAML    = "yaml"
+	outputFormatNDJSON  = "ndjson"
+
+	outputFormatCycloneDXVDR = "cyclonedx-vdr"
+	outputFormatOSV          = "osv"
+	outputFormatHTML         = "html"
+	outputFormatCSV          = "csv"
+	outputFormatTSV          = "tsv"
 )
 
-var validScanOutputFormats = []string{outputFormatOutline, outputFormatJSON}
+var validScanOutputFormats = []string{outputFormatOutline, outputFormatJSON, outputFormatNDJSON, outputFormatCycloneDXVDR, outputFormatOSV, outputFormatHTML, outputFormatCSV, outputFormatTSV}
 
 func cmdScan() *cobra.Command {
 	p := &scanParams{}
 	cmd := &cobra.Command{
-		Use:   "scan [ --sbom | --build-log | --remote ] [ --advisory-filter <type> --advisories-repo-dir <path> ] target...",
+		Use:   "scan [ --sbom | --build-log | --remote ] [ --advisory-filter <type> --advisories-repo-dir <path> ] { target... | --package <name> [ --arch <arch> ] | --melange-config <path> | --watch <dir> }",
 		Short: "Scan a package for vulnerabilities",
 		Long: `This command scans one or more distro packages for vulnerabilities.
 
 ## SCANNING
 
-There are four ways to specify the package(s) to scan:
+There are six ways to specify what to scan:
 
 1. Specify the path to the APK file(s) to scan.
 
@@ -61,9 +71,105 @@ There are four ways to specify the package(s) to scan:
    contains a build log file named "packages.log"). The build log file will be
    parsed to find the APK files to scan.
 
-4. Specify the name(s) of package(s) in the Wolfi package repository. The
-   latest versions of the package(s) for all supported architectures will be
-   downloaded from the Wolfi package repository and scanned.
+4. Specify the name(s) of package(s) in the Wolfi package repository, either
+   as arguments with the --remote flag, or with the --package flag. The latest
+   versions of the package(s) are resolved using the repository's APKINDEX,
+   then downloaded and scanned. By default, all supported architectures are
+   scanned; use the --arch flag to scan only specific architectures.
+
+5. Specify the path to a melange config with the --melange-config flag. All
+   the packages the config produces (the main package and its subpackages)
+   are scanned. Each package's APKs are looked for in the local packages
+   directory (set with --packages-dir), using the version in the config. Any
+   package that isn't found locally is resolved from the Wolfi package
+   repository instead, as with --package. The results are reported together,
+   grouped by package.
+
+6. Specify the path to one or more directories with the --rootfs flag, such as
+   the unpacked root filesystem of a container image. Every package found in
+   each directory is scanned, using the same matching rules as APK scans
+   (including which CPE matches are trusted). Since a directory isn't a single
+   package, advisory-based filtering and reachability analysis aren't
+   available, and directory results aren't cached.
+
+When scanning many packages, use the --jobs (or "-j") flag to scan multiple
+packages concurrently. The vulnerability database is loaded once and shared by
+all concurrent scans, and results are still reported in the order the packages
+were specified.
+
+Some very large packages can take a long time to catalog. Use --target-timeout
+to limit the time spent generating each package's SBOM, and matching its
+vulnerabilities. A package that exceeds the limit is skipped, and the rest of
+the packages are still scanned and reported; the command then fails, listing
+the packages that timed out.
+
+Scan results are cached on disk, keyed by the digest of each scanned file and
+by the vulnerability database in use, so re-scanning unchanged packages is
+fast. Cached results are discarded automatically whenever the vulnerability
+database is updated. Use --disable-result-cache to always perform a full scan.
+
+## WATCH MODE
+
+Use the --watch flag to watch a build output directory (such as the
+"packages" directory that melange writes to) instead of specifying targets.
+APKs that are written to the directory or any of its subdirectories are scanned
+as soon as they're complete, and their findings are printed as each build
+finishes. APKs that already exist when the command starts aren't scanned. The
+vulnerability database is loaded once, and the command runs until it's
+interrupted.
+
+In watch mode, only the "outline", "json", and "ndjson" output formats are
+supported. With "json" or "ndjson", each scan result is printed as a single
+line of JSON.
+
+## MATCHERS
+
+Grype uses a separate "matcher" for each package ecosystem (e.g. "apk" for
+distro packages, "golang" for Go modules, and "javascript" for npm packages).
+Use --matchers to only use the given matchers, or --disable-matchers to skip
+specific matchers. Packages that would be handled by a matcher that isn't used
+are skipped entirely. The "stock" matcher handles package types that don't have
+a dedicated matcher (such as binaries). For example, use "--matchers apk" to
+only look for distro-level vulnerabilities.
+
+To correct the CPEs used to match specific packages (beyond what their melange
+configurations provide), use the --cpe-overrides flag to specify a file of
+overrides, for example:
+
+    packages:
+      - package: github.com/foo/bar   # required
+        type: go-module               # optional, defaults to any type
+        cpes:                         # replaces the package's CPEs
+          - cpe:2.3:a:foo:bar:*:*:*:*:*:go:*:*
+      - package: libbaz
+        suppress: true                # removes the package's CPEs
+
+If an override's CPE doesn't specify a version, the package's version is used.
+CPEs from the overrides file are always trusted during matching.
+
+To find vulnerabilities that the Grype database doesn't know about yet, use the
+--vuln-source flag to also query other sources of vulnerability data for the
+scanned language packages (such as Go modules and Python packages):
+
+- "osv": The OSV API (https://osv.dev).
+
+- "github": The GitHub Advisory Database. Set GITHUB_TOKEN to avoid GitHub's
+  rate limit for unauthenticated requests.
+
+Findings from the additional sources are merged with the Grype database's
+findings, treating findings for the same package that share a vulnerability ID
+or alias as one finding. Each finding lists the sources that reported it (e.g.
+"via grype, osv"). Results aren't cached when additional sources are used, and
+additional sources can't be used in offline mode.
+
+## OFFLINE SCANNING
+
+Use the --offline flag to scan without accessing the network. In offline mode,
+the vulnerability database (and KEV catalog, if requested) must already be
+installed, and the database's age isn't enforced. To prepare an air-gapped
+environment, create a bundle with "wolfictl scan db export", and then either
+install it with "wolfictl scan db import" or pass it to this command using the
+--db-bundle flag (which implies --offline).
 
 ## FILTERING
 
@@ -85,22 +191,161 @@ filtering. The following sets of advisories are available:
 - "concluded": Only filter out all vulnerabilities that have been fixed, or those
   where no change is planned to fix the vulnerability.
 
+To triage packages from more than one distro in a single run (e.g. Wolfi and
+an enterprise distro), specify --advisories-repo-dir more than once. The
+advisories from all of the repositories are combined: advisories for the same
+vulnerability in the same package are merged, and the most recent event across
+all of the repositories determines whether a finding is filtered out.
+
+To see where advisory coverage is missing instead of (or in addition to)
+filtering, use the --annotate-advisories flag. Every remaining finding is
+annotated with the type of the latest event of its advisory (for example,
+"under-investigation", "false-positive-determination", or "fixed"), or with
+"none" when no advisory exists for the vulnerability yet. The annotation is
+included in all output formats.
+
+Use the --only-fixed flag to only report findings whose vulnerability has a
+fixed version available upstream, or the --only-unfixed flag to only report
+findings whose vulnerability doesn't. Like --kev-only, these are applied before
+--require-zero and --fail-on-severity are evaluated.
+
+To tell newly published vulnerabilities apart from vulnerabilities introduced
+by a package change, use the --baseline-db flag to specify an older Grype
+vulnerability database archive (for example, one that was pinned when the
+package last passed its scan). Each package is scanned with both databases,
+and only the findings that the baseline database doesn't produce are reported.
+
+## SUPPRESSING FINDINGS
+
+To suppress findings that aren't (yet) covered by advisory data, such as known
+false positives, create a ".wolfictl-scan-ignore.yaml" file in the current
+directory, or specify the path to such a file using the --ignore-file flag. The
+file contains a list of rules, for example:
+
+    rules:
+      - vulnerability: CVE-2024-1234    # required, matches the ID or any alias
+        package: github.com/foo/bar     # optional
+        version: ">= 1.2.0, < 1.2.5"    # optional
+        expires: 2025-01-31             # optional, YYYY-MM-DD
+        justification: The vulnerable function is never called.  # required
+
+A finding is suppressed if it matches all of a rule's criteria, and the rule
+hasn't expired. Expired rules are reported as warnings. Suppressed findings
+aren't considered by --require-zero and --fail-on-severity, and they're
+reported in a separate "suppressed" section of the output (or, for JSON output,
+in the "Suppressed" field of each result).
+
+## REACHABILITY ANALYSIS
+
+Use the --reachability flag to check whether the vulnerable code of findings
+in Go binaries can actually be reached. The binaries are analyzed with
+govulncheck (which must be installed, and needs network access to the Go
+vulnerability database), and each finding it has an assessment for is marked
+as "reachable", "imported-not-reachable" (the vulnerable package is in the
+binary, but none of its vulnerable symbols are), or "not-imported" (the
+vulnerable package isn't in the binary at all).
+
+By default, findings are only annotated. Use --reachability-action demote to
+lower the severity of unreachable findings to "Negligible", or
+--reachability-action filter to remove them. Either way, this happens before
+--require-zero and --fail-on-severity are evaluated.
+
+## KNOWN EXPLOITED VULNERABILITIES
+
+Use the --kev flag to mark findings whose vulnerabilities are listed in the
+CISA Known Exploited Vulnerabilities (KEV) catalog. The catalog is downloaded at
+most once a day and cached locally; if it can't be downloaded, the cached copy
+is used. To use a specific copy of the catalog (e.g. in an offline
+environment), use the --kev-catalog flag.
+
+Use the --kev-only flag to only report findings that are listed in the KEV
+catalog. This is applied before --require-zero and --fail-on-severity are
+evaluated.
+
 ## OUTPUT
 
-When a scan finishes, the command will print the results to stdout. There are
-two modes of output that can be specified with the --output (or "-o") flag:
+When a scan finishes, the command will print the results to stdout. The
+following modes of output can be specified with the --output (or "-o") flag:
 
 - "outline": This is the default output mode. It prints the results in a
-  human-readable outline format.
+  human-readable outline format. Findings without an advisory include a
+  heuristic triage suggestion (e.g. "likely false positive: CPE-only match
+  with no trusted source"), which is also included in the JSON output formats
+  as the TriageSuggestion field.
 
 - "json": This mode prints the results in JSON format. This mode is useful for
-  machine processing of the results.
+  machine processing of the results. The format is versioned (see each
+  result's "schemaVersion" field), and described by the JSON Schema printed by
+  "wolfictl scan schema".
+
+- "ndjson": This mode prints each package's result as a single line of JSON
+  (newline-delimited JSON), as soon as the package has been scanned. Results
+  are still printed in the order the packages were specified. Since results
+  aren't buffered, this mode is recommended for scanning many packages.
+
+- "cyclonedx-vdr": This mode prints the results as a CycloneDX 1.5
+  Vulnerability Disclosure Report (VDR), which can be ingested by tools like
+  Dependency-Track. When advisory data is available, each vulnerability's
+  analysis reflects the latest advisory event.
+
+- "osv": This mode prints a JSON array with one OSV record per finding. Each
+  record identifies the affected component by its purl and describes the
+  affected version range using the finding's fixed version, if any.
+
+- "html": This mode prints a standalone HTML report, with a summary of each
+  package's findings by severity, and sortable tables of the findings that
+  link to each vulnerability's NVD or GitHub page and to its advisory. This
+  mode is suited to attaching to release sign-off tickets, e.g. with
+  "-o html > report.html".
+
+- "csv" and "tsv": These modes print a table with a header row and one row per
+  finding, with comma-separated or tab-separated fields respectively. The
+  columns are the scanned APK's name, version, and architecture; the affected
+  component's name, version, and type; the vulnerability ID and its aliases;
+  the severity; the fixed version; how the vulnerability was matched (e.g.
+  "exact-direct-match" or "cpe-match"); the advisory ID, if any; the
+  advisory status, with --annotate-advisories; and the sources that reported
+  the finding, with --vuln-source.
+
+Regardless of the output mode, the --summary-output flag writes a small JSON
+summary of the run to the given file: the number of packages and findings,
+the findings counted by severity and by fix state ("fixed" or "not-fixed"),
+the same counts for each package, and whether each package and the run as a
+whole pass the --require-zero and --fail-on-severity thresholds (the "Pass"
+field). This is useful for CI systems that only need the verdict and the
+totals.
+
+To scan the builds of a package for multiple architectures at once, specify an
+APK for each architecture (or use --package, which scans all supported
+architectures by default) along with the --merge-arches flag. Results for the
+same package name and version are merged into one, and each finding lists the
+architectures in which it was found. Only the "outline" and "json" output
+formats are supported with --merge-arches.
+
+To feed dashboards, use --metrics-file to write metrics about the scan run
+(the number of packages scanned, the number of findings by severity, the age of
+the vulnerability database, and how long the run took) to a file in OpenMetrics
+format, such as for the Prometheus node exporter's textfile collector. Use
+--metrics-push-url to push the same metrics to a Prometheus Pushgateway instead
+(under the job name given by --metrics-job). Metrics are reported even when the
+scan fails.
+
+Use --record-history to record each package's scan results in a local
+database, and then use "wolfictl scan trends" to see which findings were
+introduced, resolved, or have been around for a long time, across scans.
 
 The command will exit with a non-zero exit code if any errors occur during the
 scan.
 
-The command will also exit with a non-zero exit code if any vulnerabilities are
-found and the --require-zero flag is specified.
+The command will also exit with code 2 if any vulnerabilities are found and the
+--require-zero flag is specified. (Previously, this was exit code 1, the same as
+for scan errors.)
+
+To use the command as a CI gate, specify a severity with the --fail-on-severity
+flag (one of negligible, low, medium, high, or critical). The command will exit
+with code 2 if any vulnerabilities at or above that severity are found (after
+any advisory filtering), and with code 1 if the scan itself fails. Findings
+whose severity is unknown don't trigger a failure.
 
 `,
 		Example: `
@@ -121,13 +366,74 @@ wolfictl scan package-name --remote
 
 # Scan multiple packages in the Wolfi package repository
 wolfictl scan package1 package2 --remote
+
+# Scan the latest aarch64 build of a package in the Wolfi package repository
+wolfictl scan --package crane --arch aarch64
+
+# Scan a package and all its subpackages after building them with melange
+wolfictl scan --melange-config crane.yaml --packages-dir ./packages
+
+# Scan an unpacked container image root filesystem
+wolfictl scan --rootfs /path/to/rootfs
+
+# Scan all APKs in a directory, four at a time
+wolfictl scan --jobs 4 /path/to/packages/*.apk
+
+# Only report vulnerabilities that are known to be exploited in the wild
+wolfictl scan /path/to/package.apk --kev-only
+
+# Only report vulnerabilities that can be fixed by updating
+wolfictl scan /path/to/package.apk --only-fixed
+
+# Don't report vulnerabilities in Go binaries that govulncheck finds unreachable
+wolfictl scan /path/to/package.apk --reachability --reachability-action filter
+
+# Show which findings still need an advisory
+wolfictl scan /path/to/package.apk --advisories-repo-dir ../wolfi-advisories --annotate-advisories
+
+# Only report vulnerabilities published since a pinned vulnerability database
+wolfictl scan /path/to/package.apk --baseline-db /path/to/older-grype-db.tar.zst
+
+# Fail a CI job only when high or critical vulnerabilities are found
+wolfictl scan /path/to/package.apk --fail-on-severity high
+
+# Scan packages as they're built by melange
+wolfictl scan --watch ./packages
+
+# Scan in an air-gapped environment using a previously exported bundle
+wolfictl scan --db-bundle wolfictl-scan-db.tar.gz /path/to/package.apk
+
+# Scan the x86_64 and aarch64 builds of a package, merging the results
+wolfictl scan --merge-arches packages/x86_64/crane-0.19.1-r6.apk packages/aarch64/crane-0.19.1-r6.apk
+
+# Only look for distro-level vulnerabilities
+wolfictl scan /path/to/package.apk --matchers apk
+
+# Filter findings using the advisories of more than one distro
+wolfictl scan /path/to/package.apk -f resolved -a /path/to/advisories -a /path/to/enterprise-advisories
+
+# Write metrics about a nightly scan for Prometheus to collect
+wolfictl scan --metrics-file /var/lib/node_exporter/wolfictl_scan.prom /path/to/packages/*.apk
+
+# Write a summary with the pass/fail verdict for CI alongside the full results
+wolfictl scan /path/to/package.apk --fail-on-severity high --summary-output summary.json -o json > results.json
+
+# Also look for vulnerabilities in OSV and the GitHub Advisory Database
+wolfictl scan /path/to/package.apk --vuln-source osv,github
+
+# Produce a CycloneDX VDR for import into Dependency-Track
+wolfictl scan /path/to/package.apk -a /path/to/advisories -o cyclonedx-vdr
 `,
-		Args:          cobra.MinimumNArgs(1),
+		Args:          cobra.ArbitraryArgs,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			logger := clog.FromContext(ctx)
 
+			if len(args) == 0 && len(p.packages) == 0 && p.melangeConfigPath == "" && p.watchDir == "" {
+				return errors.New("must specify at least one target to scan, a package name using --package, a melange config using --melange-config, or a directory using --watch")
+			}
+
 			if p.outputFormat == "" {
 				p.outputFormat = outputFormatOutline
 			}
@@ -148,25 +454,130 @@ wolfictl scan package1 package2 --remote
 				return errors.New("cannot specify more than one of [--build-log, --sbom, --remote]")
 			}
 
-			if p.advisoryFilterSet != "" {
-				if !slices.Contains(scan.ValidAdvisoriesSets, p.advisoryFilterSet) {
+			if p.rootfsInput {
+				if p.packageBuildLogInput || p.sbomInput || p.remoteScanning || len(p.packages) > 0 || p.melangeConfigPath != "" || p.watchDir != "" {
+					return errors.New("cannot use --rootfs with --build-log, --sbom, --remote, --package, --melange-config, or --watch")
+				}
+
+				if p.advisoryFilterSet != "" || p.annotateAdvisories {
+					return errors.New("cannot use --advisory-filter or --annotate-advisories with --rootfs, since advisories are specific to a package")
+				}
+
+				if p.reachability {
+					return errors.New("cannot use --reachability with --rootfs")
+				}
+
+				// Directories aren't content-addressed like APKs, so their results can't be
+				// cached.
+				p.disableResultCache = true
+			}
+
+			if p.melangeConfigPath != "" {
+				if len(args) > 0 || len(p.packages) > 0 || p.packageBuildLogInput || p.sbomInput || p.remoteScanning {
+					return errors.New("cannot specify targets, --package, --build-log, --sbom, or --remote with --melange-config")
+				}
+			}
+
+			if len(p.packages) > 0 {
+				if p.packageBuildLogInput || p.sbomInput {
+					return errors.New("cannot use --package with --build-log or --sbom")
+				}
+
+				if len(args) > 0 && !p.remoteScanning {
+					return errors.New("cannot mix file targets with --package (use --remote to specify additional package names as arguments)")
+				}
+			}
+
+			if p.watchDir != "" {
+				if len(args) > 0 || len(p.packages) > 0 || p.packageBuildLogInput || p.sbomInput || p.remoteScanning || p.melangeConfigPath != "" {
+					return errors.New("cannot specify targets, --package, --build-log, --sbom, --remote, or --melange-config with --watch")
+				}
+
+				if !slices.Contains(validScanWatchOutputFormats, p.outputFormat) {
 					return fmt.Errorf(
-						"invalid advisory filter set %q, must be one of [%s]",
-						p.advisoryFilterSet,
-						strings.Join(scan.ValidAdvisoriesSets, ", "),
+						"invalid output format %q for --watch, must be one of [%s]",
+						p.outputFormat,
+						strings.Join(validScanWatchOutputFormats, ", "),
 					)
 				}
 
-				if p.advisoriesRepoDir == "" {
-					return errors.New("advisory-based filtering requested, but no advisories repo dir was provided")
+				if p.requireZeroFindings || p.failOnSeverity != "" {
+					return errors.New("cannot use --require-zero or --fail-on-severity with --watch")
+				}
+
+				if p.metricsFilePath != "" || p.metricsPushURL != "" {
+					return errors.New("cannot use --metrics-file or --metrics-push-url with --watch")
 				}
 
-				logger.Info("scan results will be filtered using advisory data", "filterSet", p.advisoryFilterSet, "advisoriesRepoDir", p.advisoriesRepoDir)
+				if p.summaryOutputPath != "" {
+					return errors.New("cannot use --summary-output with --watch")
+				}
+
+				if p.baselineDBPath != "" {
+					return errors.New("cannot use --baseline-db with --watch")
+				}
+			}
+
+			if p.mergeArches {
+				if p.outputFormat != outputFormatOutline && p.outputFormat != outputFormatJSON {
+					return fmt.Errorf(
+						"invalid output format %q for --merge-arches, must be one of [%s]",
+						p.outputFormat,
+						strings.Join([]string{outputFormatOutline, outputFormatJSON}, ", "),
+					)
+				}
+
+				if p.watchDir != "" {
+					return errors.New("cannot use --merge-arches with --watch")
+				}
+			}
+
+			if err := p.validateScanningFlags(ctx); err != nil {
+				return err
+			}
+
+			if p.offline && (p.remoteScanning || len(p.packages) > 0) {
+				return errors.New("cannot scan packages from the package repository in offline mode")
+			}
+
+			for _, arch := range p.arches {
+				if !slices.Contains(supportedRemoteArches, arch) {
+					return fmt.Errorf(
+						"invalid arch %q, must be one of [%s]",
+						arch,
+						strings.Join(supportedRemoteArches, ", "),
+					)
+				}
+			}
+
+			if p.reachability && p.sbomInput {
+				return errors.New("cannot use --reachability with --sbom, since the binaries aren't available")
 			}
 
-			var advGetter advisory.Getter
-			if p.advisoriesRepoDir != "" {
-				advGetter = advisory.NewFSGetter(os.DirFS(p.advisoriesRepoDir))
+			if p.failOnSeverity != "" && !slices.Contains(scan.ValidSeverities, strings.ToLower(p.failOnSeverity)) {
+				return fmt.Errorf(
+					"invalid severity %q, must be one of [%s]",
+					p.failOnSeverity,
+					strings.Join(scan.ValidSeverities, ", "),
+				)
+			}
+
+			advGetter, kevCatalog, err := p.prepareScanning(ctx)
+			if err != nil {
+				return err
+			}
+
+			if p.recordHistory {
+				history, err := scan.OpenHistory(ctx, p.historyDBPath)
+				if err != nil {
+					return err
+				}
+				defer history.Close()
+				p.history = history
+			}
+
+			if p.watchDir != "" {
+				return p.watch(ctx, advGetter, kevCatalog)
 			}
 
 			// TODO: This is a bit of a hack because MultiAuthenticator uses Basic auth to
@@ -188,38 +599,255 @@ wolfictl scan package1 package2 --remote
 				}()
 			}
 
-			scans, inputPathsFailingRequireZero, err := scanEverything(ctx, p, inputs, advGetter)
+			if p.metricsFilePath != "" || p.metricsPushURL != "" {
+				p.runStats = scan.NewRunStats()
+			}
+
+			start := time.Now()
+			scans, inputPathsFailingRequireZero, err := scanEverything(ctx, p, inputs, advGetter, kevCatalog)
+			if p.runStats != nil {
+				// Report metrics even if the scan failed, so that failing runs are visible
+				// on dashboards too.
+				p.runStats.Duration = time.Since(start)
+				err = errors.Join(err, p.reportMetrics(ctx))
+			}
 			if err != nil {
 				return err
 			}
 
-			if p.outputFormat == outputFormatJSON {
+			scans, inputs = p.withoutTimedOutInputs(scans, inputs)
+
+			switch {
+			case p.mergeArches:
+				if err := renderMultiArchResults(p.outputFormat, scan.MergeArches(scans)); err != nil {
+					return err
+				}
+
+			case p.outputFormat == outputFormatJSON:
 				enc := json.NewEncoder(os.Stdout)
 				err := enc.Encode(scans)
 				if err != nil {
 					return fmt.Errorf("failed to marshal scans to JSON: %w", err)
 				}
+
+			case p.outputFormat == outputFormatCycloneDXVDR:
+				if err := scan.EncodeCycloneDXVDR(os.Stdout, scans, p.distro); err != nil {
+					return err
+				}
+
+			case p.outputFormat == outputFormatOSV:
+				if err := scan.EncodeOSV(os.Stdout, scans, p.distro); err != nil {
+					return err
+				}
+
+			case p.outputFormat == outputFormatHTML:
+				if err := scan.EncodeHTML(os.Stdout, scans, time.Now()); err != nil {
+					return err
+				}
+
+			case p.outputFormat == outputFormatCSV:
+				if err := scan.EncodeCSV(os.Stdout, scans, ','); err != nil {
+					return fmt.Errorf("failed to write CSV: %w", err)
+				}
+
+			case p.outputFormat == outputFormatTSV:
+				if err := scan.EncodeCSV(os.Stdout, scans, '\t'); err != nil {
+					return fmt.Errorf("failed to write TSV: %w", err)
+				}
 			}
 
-			if len(inputPathsFailingRequireZero) > 0 {
-				return fmt.Errorf("vulnerabilities found in the following package(s):\n%s", strings.Join(inputPathsFailingRequireZero, "\n"))
+			if p.summaryOutputPath != "" {
+				if err := p.writeSummary(ctx, scans); err != nil {
+					return err
+				}
 			}
 
-			return nil
+			return p.resultError(scans, inputs, inputPathsFailingRequireZero)
 		},
 	}
 
 	p.addFlagsTo(cmd)
+	cmd.AddCommand(
+		cmdScanCrossValidate(),
+		cmdScanDB(),
+		cmdScanDiff(),
+		cmdScanSchema(),
+		cmdScanServe(),
+		cmdScanTrends(),
+	)
 	return cmd
 }
 
-func scanEverything(ctx context.Context, p *scanParams, inputs []string, advGetter advisory.Getter) ([]scan.Result, []string, error) {
-	// We're going to generate the SBOMs concurrently, then scan them sequentially.
+// resultError returns the error the scan command should exit with, given the
+// completed scans. Timed-out inputs are a scan error, and take precedence over
+// findings, so that an incomplete scan isn't reported as only having findings.
+func (p *scanParams) resultError(scans []scan.Result, inputs, inputPathsFailingRequireZero []string) error {
+	if len(p.timedOutInputs) > 0 {
+		return fmt.Errorf("scans of the following package(s) timed out after %s:\n%s", p.targetTimeout, strings.Join(p.timedOutInputs, "\n"))
+	}
+
+	return p.thresholdError(scans, inputs, inputPathsFailingRequireZero)
+}
+
+// thresholdError returns an error with exitCodeFindings if the scans of the
+// inputs fail the --require-zero or --fail-on-severity thresholds, and nil
+// otherwise. inputPathsFailingRequireZero are the inputs whose scans have
+// findings, when --require-zero is specified.
+func (p *scanParams) thresholdError(scans []scan.Result, inputs, inputPathsFailingRequireZero []string) error {
+	if len(inputPathsFailingRequireZero) > 0 {
+		return &ExitError{
+			Code: exitCodeFindings,
+			Err:  fmt.Errorf("vulnerabilities found in the following package(s):\n%s", strings.Join(inputPathsFailingRequireZero, "\n")),
+		}
+	}
+
+	if p.failOnSeverity != "" {
+		var failing []string
+		for i := range scans {
+			if len(scan.FindingsAtOrAboveSeverity(scans[i].Findings, p.failOnSeverity)) > 0 {
+				failing = append(failing, inputs[i])
+			}
+		}
+
+		if len(failing) > 0 {
+			return &ExitError{
+				Code: exitCodeFindings,
+				Err: fmt.Errorf(
+					"vulnerabilities with severity %q or higher found in the following package(s):\n%s",
+					strings.ToLower(p.failOnSeverity),
+					strings.Join(failing, "\n"),
+				),
+			}
+		}
+	}
+
+	return nil
+}
+
+// renderMultiArchResults prints the merged results in the given output format,
+// which must be outline or JSON.
+func renderMultiArchResults(outputFormat string, results []scan.MultiArchResult) error {
+	if outputFormat == outputFormatJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+			return fmt.Errorf("failed to marshal merged scans to JSON: %w", err)
+		}
+		return nil
+	}
+
+	for i := range results {
+		r := &results[i]
+		fmt.Printf("🔎 Scanning %s %s (%s)\n", r.TargetAPK.Name, r.TargetAPK.Version, strings.Join(r.Arches, ", "))
+
+		render, err := scanfindings.Render(r.Findings)
+		if err != nil {
+			return err
+		}
+		fmt.Println(render)
+
+		if suppressed := scanfindings.RenderSuppressed(r.Suppressed); suppressed != "" {
+			fmt.Println(suppressed)
+		}
+	}
+
+	return nil
+}
+
+// errTargetTimedOut means that a step of scanning a target took longer than the
+// per-target timeout.
+var errTargetTimedOut = errors.New("timed out")
+
+// withTargetTimeout calls f with a context that's canceled after the given
+// timeout (if it's positive). Since some cataloging work doesn't stop when its
+// context is canceled, withTargetTimeout doesn't wait for f to return once the
+// timeout has elapsed, and returns errTargetTimedOut instead.
+//
+// f is still running in that case, so it's tracked by running. Callers must
+// wait on running before releasing anything f uses (e.g. closing the scanner).
+func withTargetTimeout[T any](ctx context.Context, timeout time.Duration, running *sync.WaitGroup, f func(context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return f(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	running.Add(1)
+	go func() {
+		defer running.Done()
+		value, err := f(ctx)
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+
+	case <-ctx.Done():
+		var zero T
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return zero, errTargetTimedOut
+		}
+		return zero, ctx.Err()
+	}
+}
+
+// newBaselineScanner returns a scanner that uses the Grype DB archive at
+// dbArchivePath, which is imported into a new directory within tmpdir. Other
+// than the database, the scanner is configured using opts.
+func newBaselineScanner(opts scan.Options, dbArchivePath, tmpdir string) (*scan.Scanner, error) {
+	dbDir, err := os.MkdirTemp(tmpdir, "baseline-db-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create baseline database directory: %w", err)
+	}
+
+	opts.PathOfDatabaseArchiveToImport = dbArchivePath
+	opts.PathOfDatabaseDestinationDirectory = dbDir
+	opts.Offline = true
+
+	// The baseline is old on purpose.
+	opts.DisableDatabaseAgeValidation = true
+
+	scanner, err := scan.NewScanner(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create baseline scanner: %w", err)
+	}
+
+	return scanner, nil
+}
+
+// withoutTimedOutInputs removes the results of the inputs listed in
+// p.timedOutInputs, which weren't actually scanned, keeping scans and inputs
+// aligned.
+func (p *scanParams) withoutTimedOutInputs(scans []scan.Result, inputs []string) ([]scan.Result, []string) {
+	if len(p.timedOutInputs) == 0 {
+		return scans, inputs
+	}
+
+	var keptScans []scan.Result
+	var keptInputs []string
+	for i := range scans {
+		if slices.Contains(p.timedOutInputs, inputs[i]) {
+			continue
+		}
+		keptScans = append(keptScans, scans[i])
+		keptInputs = append(keptInputs, inputs[i])
+	}
+
+	return keptScans, keptInputs
+}
+
+func scanEverything(ctx context.Context, p *scanParams, inputs []string, advGetter advisory.Getter, kevCatalog *scan.KEVCatalog) ([]scan.Result, []string, error) {
+	// We're going to generate the SBOMs concurrently, then scan them using a pool
+	// of p.jobs workers that share a single scanner.
 	var g errgroup.Group
 	g.SetLimit(runtime.GOMAXPROCS(0) + 1)
 
 	// done is a slice of pseudo-promises that get closed when sboms[i] and files[i] are ready to scan.
-	// We do this to keep a deterministic scan order, which maybe we don't actually care about.
 	done := make([]chan struct{}, len(inputs))
 	for i := range inputs {
 		done[i] = make(chan struct{})
@@ -232,126 +860,512 @@ func scanEverything(ctx context.Context, p *scanParams, inputs []string, advGett
 
 	var inputPathsFailingRequireZero []string
 
-	opts := scan.DefaultOptions
-	opts.UseCPEs = p.useCPEMatching
-	opts.PathOfDatabaseArchiveToImport = p.localDBFilePath
+	// ndjsonEnc is used to print each result as soon as it's available, when
+	// NDJSON output is requested.
+	ndjsonEnc := json.NewEncoder(os.Stdout)
+
+	// digests[i] and cachedResults[i] are only populated when the result cache is
+	// enabled. A non-nil cachedResults[i] means inputs[i] doesn't need to be scanned.
+	digests := make([]string, len(inputs))
+	cachedResults := make([]*scan.Result, len(inputs))
+
+	// paths[i] is the path of the file that was resolved for inputs[i], which is
+	// needed again for reachability analysis.
+	paths := make([]string, len(inputs))
+
+	// resultCache is set (if enabled and available) before scannerReady is closed.
+	var resultCache *scan.ResultCache
+	scannerReady := make(chan struct{})
+
+	opts := p.scannerOptions()
+
+	tmpdir, err := os.MkdirTemp("", "wolfictl-scan-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	// generating tracks SBOM generation that's still running after it timed out,
+	// which uses the files in tmpdir.
+	var generating sync.WaitGroup
+	defer generating.Wait()
 
 	// Immediately start a goroutine, so we can initialize the vulnerability database.
 	// Once that's finished, we will start to pull sboms off of done as they become ready.
 	g.Go(func() error {
 		scanner, err := scan.NewScanner(opts)
 		if err != nil {
+			close(scannerReady)
 			return fmt.Errorf("failed to create scanner: %w", err)
 		}
 		defer scanner.Close()
 
-		for i, ch := range done {
+		var baselineScanner *scan.Scanner
+		if p.baselineDBPath != "" {
+			baselineScanner, err = newBaselineScanner(opts, p.baselineDBPath, tmpdir)
+			if err != nil {
+				close(scannerReady)
+				return err
+			}
+			defer baselineScanner.Close()
+		}
+
+		// scanning tracks scans that are still using the scanners after they timed
+		// out, so the scanners aren't closed until they've returned.
+		var scanning sync.WaitGroup
+		defer scanning.Wait()
+
+		if !p.disableResultCache {
+			// The result cache is namespaced by the scanner's database, so it can only be
+			// set up once the database is loaded.
+			resultCache, err = scan.NewResultCache(ctx, scan.DefaultResultCacheDir, scanner)
+			if err != nil {
+				clog.FromContext(ctx).Warn("scan result cache unavailable, continuing without it", "error", err)
+				resultCache = nil
+			}
+		}
+		close(scannerReady)
+
+		scanFunc := func(ctx context.Context, i int) (*scan.Result, error) {
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
-			case <-ch:
+				return nil, ctx.Err()
+			case <-done[i]:
+			}
+
+			if errs[i] != nil {
+				// SBOM generation failed, this is handled when the result is consumed.
+				return nil, nil
+			}
+
+			result := cachedResults[i]
+			if result == nil {
+				var err error
+				result, err = withTargetTimeout(ctx, p.targetTimeout, &scanning, func(ctx context.Context) (*scan.Result, error) {
+					return p.doScanCommandForSingleInput(ctx, scanner, files[i], sboms[i])
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				if resultCache != nil {
+					if err := resultCache.Put(ctx, digests[i], p.distro, result); err != nil {
+						clog.FromContext(ctx).Warn("failed to cache scan result", "input", inputs[i], "error", err)
+					}
+				}
 			}
 
+			if baselineScanner != nil {
+				baseline, err := withTargetTimeout(ctx, p.targetTimeout, &scanning, func(ctx context.Context) (*scan.Result, error) {
+					return p.scanSBOM(ctx, baselineScanner, sboms[i])
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to scan with baseline database: %w", err)
+				}
+
+				result.Findings = scan.DiffResults(baseline, result).Added
+				result.BaselineDataSource = &baseline.DataSource
+			}
+
+			// The SBOM is no longer needed, so don't hold onto it until every input
+			// has been scanned.
+			sboms[i] = nil
+
+			if err := p.analyzeReachability(ctx, paths[i], result); err != nil {
+				return nil, err
+			}
+
+			if err := p.enrichResult(ctx, result, advGetter, kevCatalog); err != nil {
+				return nil, err
+			}
+
+			return result, nil
+		}
+
+		resultFunc := func(i int, result *scan.Result, err error) error {
 			input := inputs[i]
 
+			if errors.Is(errs[i], errTargetTimedOut) || errors.Is(err, errTargetTimedOut) {
+				if p.runStats != nil {
+					p.runStats.AddTimeout()
+				}
+
+				clog.FromContext(ctx).Warn("scan timed out, skipping", "input", input, "timeout", p.targetTimeout)
+				if p.outputFormat == outputFormatOutline {
+					fmt.Printf("⏱️ Skipping scan because it took longer than %s for %q\n", p.targetTimeout, input)
+				}
+
+				// The rest of the run carries on, and the timeout is reported at the end.
+				errs[i] = nil
+				p.timedOutInputs = append(p.timedOutInputs, input)
+				return nil
+			}
+
 			if err := errs[i]; err != nil {
+				if p.runStats != nil {
+					p.runStats.AddFailure()
+				}
+
 				if p.outputFormat == outputFormatOutline {
 					fmt.Printf("❌ Skipping scan because SBOM generation failed for %q: %v\n", input, err)
 				}
 
 				// All errs will get joined and returned at the end of the outer function.
-				continue
+				return nil
 			}
 
-			file := files[i]
-			apkSBOM := sboms[i]
+			if err != nil {
+				if p.runStats != nil {
+					p.runStats.AddFailure()
+				}
+				return fmt.Errorf("failed to scan %q: %w", input, err)
+			}
 
-			if p.outputFormat == outputFormatOutline {
-				fmt.Printf("🔎 Scanning %q\n", input)
+			if p.runStats != nil {
+				p.runStats.Add(result)
 			}
 
-			result, err := p.doScanCommandForSingleInput(ctx, scanner, file, apkSBOM, advGetter)
-			if err != nil {
-				return fmt.Errorf("failed to scan %q: %w", input, err)
+			p.addToHistory(ctx, result)
+
+			if p.outputFormat == outputFormatOutline && !p.mergeArches {
+				fmt.Printf("🔎 Scanning %q\n", input)
+
+				render, err := scanfindings.Render(result.Findings)
+				if err != nil {
+					return err
+				}
+				fmt.Println(render)
+
+				if suppressed := scanfindings.RenderSuppressed(result.Suppressed); suppressed != "" {
+					fmt.Println(suppressed)
+				}
 			}
 
-			scans[i] = *result
+			if p.outputFormat == outputFormatNDJSON {
+				if err := ndjsonEnc.Encode(result); err != nil {
+					return fmt.Errorf("failed to marshal scan of %q to JSON: %w", input, err)
+				}
+
+				// Results have already been printed, so only keep them if they're needed
+				// later.
+				if p.failOnSeverity != "" || p.summaryOutputPath != "" {
+					scans[i] = *result
+				}
+			} else {
+				scans[i] = *result
+			}
 
 			if p.requireZeroFindings && len(result.Findings) > 0 {
 				// Accumulate the list of failures to be returned at the end, but we still want to complete all scans
-				inputPathsFailingRequireZero = append(inputPathsFailingRequireZero, inputs[i])
+				inputPathsFailingRequireZero = append(inputPathsFailingRequireZero, input)
 			}
+
+			return nil
 		}
 
-		return nil
+		return scan.ScanOrdered(ctx, len(inputs), p.jobs, scanFunc, resultFunc)
 	})
 
-	tmpdir, err := os.MkdirTemp("", "wolfictl-scan-")
-	if err != nil {
-		return nil, nil, err
-	}
-	defer os.RemoveAll(tmpdir)
-
 	for i, input := range inputs {
 		i, input := i, input
 
 		g.Go(func() error {
 			f := func() error {
+				if p.rootfsInput {
+					dirSBOM, err := withTargetTimeout(ctx, p.targetTimeout, &generating, func(ctx context.Context) (*sbomSyft.SBOM, error) {
+						return sbom.GenerateForDirectory(ctx, input, p.distro)
+					})
+					if err != nil {
+						return fmt.Errorf("failed to generate SBOM: %w", err)
+					}
+
+					sboms[i] = dirSBOM
+					return nil
+				}
+
 				inputFile, err := resolveInputFileFromArg(ctx, tmpdir, input)
 				if err != nil {
 					return fmt.Errorf("failed to open input file: %w", err)
 				}
+				paths[i] = inputFile.Name()
+
+				if !p.disableResultCache {
+					<-scannerReady
+
+					if resultCache != nil {
+						cached, err := lookUpCachedResult(ctx, resultCache, inputFile, p.distro, &digests[i])
+						if err != nil {
+							return err
+						}
+						if cached != nil {
+							cachedResults[i] = cached
+
+							// Scanning with a baseline database still needs the SBOM.
+							if p.baselineDBPath == "" {
+								inputFile.Close()
+								return nil
+							}
+						}
+					}
+				}
+
+				// Get the SBOM of the APK
+				apkSBOM, err := withTargetTimeout(ctx, p.targetTimeout, &generating, func(ctx context.Context) (*sbomSyft.SBOM, error) {
+					return p.generateSBOM(ctx, inputFile)
+				})
+				if err != nil {
+					return fmt.Errorf("failed to generate SBOM: %w", err)
+				}
+
+				sboms[i] = apkSBOM
+				files[i] = inputFile
+
+				return nil
+			}
+
+			errs[i] = f()
+
+			// Signals to the other goroutine that inputs[i] is ready to scan.
+			close(done[i])
+
+			return nil
+		})
+	}
+
+	return scans, inputPathsFailingRequireZero, errors.Join(g.Wait(), errors.Join(errs...))
+}
+
+type scanParams struct {
+	requireZeroFindings  bool
+	failOnSeverity       string
+	localDBFilePath      string
+	grypeDBURL           string
+	maxDBAge             time.Duration
+	dbAgePolicy          string
+	outputFormat         string
+	sbomInput            bool
+	packageBuildLogInput bool
+	rootfsInput          bool
+	distro               string
+	advisoryFilterSet    string
+	advisoriesRepoDirs   []string
+	annotateAdvisories   bool
+	disableSBOMCache     bool
+	disableResultCache   bool
+	packages             []string
+	arches               []string
+	melangeConfigPath    string
+	packagesDir          string
+	offline              bool
+	dbBundlePath         string
+	watchDir             string
+	mergeArches          bool
+	metricsFilePath      string
+	summaryOutputPath    string
+	metricsPushURL       string
+	metricsJob           string
+	recordHistory        bool
+	historyDBPath        string
+	ignoreFilePath       string
+	cpeOverridesPath     string
+	vulnSourceNames      []string
+	kev                  bool
+	kevOnly              bool
+	onlyFixed            bool
+	onlyUnfixed          bool
+	reachability         bool
+	reachabilityAction   string
+	kevCatalogPath       string
+	remoteScanning       bool
+	useCPEMatching       bool
+	matchers             []string
+	disabledMatchers     []string
+	jobs                 int
+	targetTimeout        time.Duration
+	baselineDBPath       string
+
+	// ignoreFile holds the suppression rules loaded from ignoreFilePath (or from
+	// the default ignore file), if any.
+	ignoreFile *scan.IgnoreFile
+
+	// cpeOverrides holds the CPE overrides loaded from cpeOverridesPath, if any.
+	cpeOverrides *scan.CPEOverrides
+
+	// vulnSources are the additional vulnerability sources named by
+	// vulnSourceNames.
+	vulnSources []scan.VulnerabilitySource
+
+	// runStats accumulates metrics about the scan run, when they're requested.
+	runStats *scan.RunStats
+
+	// history is where scan results are recorded, when requested.
+	history *scan.History
+
+	// timedOutInputs lists the inputs that were skipped because they took longer
+	// than targetTimeout to scan.
+	timedOutInputs []string
+}
+
+func (p *scanParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&p.requireZeroFindings, "require-zero", false, fmt.Sprintf("exit %d if any vulnerabilities are found", exitCodeFindings))
+	cmd.Flags().StringVar(&p.failOnSeverity, "fail-on-severity", "", fmt.Sprintf("exit %d if any vulnerabilities at or above the given severity are found (%s)", exitCodeFindings, strings.Join(scan.ValidSeverities, "|")))
+	cmd.Flags().StringVar(&p.baselineDBPath, "baseline-db", "", "path to an older grype db archive to also scan with, only reporting vulnerabilities that it doesn't find")
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validScanOutputFormats, "|"), outputFormatOutline))
+	cmd.Flags().BoolVarP(&p.sbomInput, "sbom", "s", false, "treat input(s) as SBOM(s) of APK(s) instead of as actual APK(s)")
+	cmd.Flags().BoolVar(&p.packageBuildLogInput, "build-log", false, "treat input as a package build log file (or a directory that contains a packages.log file)")
+	cmd.Flags().BoolVar(&p.rootfsInput, "rootfs", false, "treat input(s) as directories to scan, such as unpacked container image root filesystems, instead of as APK(s)")
+	cmd.Flags().BoolVarP(&p.remoteScanning, "remote", "r", false, "treat input(s) as the name(s) of package(s) in the Wolfi package repository to download and scan the latest versions of")
+	cmd.Flags().StringSliceVar(&p.packages, "package", nil, "name of a package in the Wolfi package repository to download and scan the latest version of (can be repeated)")
+	cmd.Flags().StringSliceVar(&p.arches, "arch", supportedRemoteArches, "architecture(s) to scan when scanning packages from the Wolfi package repository or a melange config")
+	cmd.Flags().StringVar(&p.melangeConfigPath, "melange-config", "", "path to a melange config whose packages (including subpackages) should all be scanned")
+	cmd.Flags().StringVar(&p.packagesDir, "packages-dir", "packages", "directory in which to look for the APKs built from the melange config given by --melange-config")
+	cmd.Flags().BoolVar(&p.mergeArches, "merge-arches", false, "merge the results for different architectures' builds of the same package, tagging each finding with the architectures it appears in")
+	cmd.Flags().StringVar(&p.summaryOutputPath, "summary-output", "", "write a JSON summary of the scan run (finding counts and pass/fail) to the given file")
+	cmd.Flags().StringVar(&p.metricsFilePath, "metrics-file", "", "write metrics about the scan run to the given file, in OpenMetrics format")
+	cmd.Flags().StringVar(&p.metricsPushURL, "metrics-push-url", "", "push metrics about the scan run to the Prometheus Pushgateway at the given URL")
+	cmd.Flags().StringVar(&p.metricsJob, "metrics-job", "wolfictl_scan", "job name to use when pushing metrics to a Pushgateway")
+	cmd.Flags().BoolVar(&p.recordHistory, "record-history", false, "record the scan results in the scan history database, for use with 'wolfictl scan trends'")
+	addHistoryDBFlag(&p.historyDBPath, cmd)
+	cmd.Flags().StringVar(&p.watchDir, "watch", "", "watch the given directory and scan APKs as they're written to it (e.g. by melange)")
+	cmd.Flags().IntVarP(&p.jobs, "jobs", "j", 1, "number of packages to scan concurrently (results are still reported in input order)")
+	p.addScanningFlagsTo(cmd)
+}
+
+// addScanningFlagsTo adds the flags that configure how each input is scanned,
+// which are shared by the commands that scan APKs.
+func (p *scanParams) addScanningFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.localDBFilePath, "local-file-grype-db", "", "import a local grype db file")
+	addGrypeDBURLFlag(&p.grypeDBURL, cmd)
+	addDBAgeFlags(&p.maxDBAge, &p.dbAgePolicy, cmd)
+	cmd.Flags().StringVar(&p.distro, "distro", "wolfi", "distro to use during vulnerability matching")
+	cmd.Flags().StringVarP(&p.advisoryFilterSet, "advisory-filter", "f", "", fmt.Sprintf("exclude vulnerability matches that are referenced from the specified set of advisories (%s)", strings.Join(scan.ValidAdvisoriesSets, "|")))
+	addAdvisoriesDirsFlag(&p.advisoriesRepoDirs, cmd)
+	cmd.Flags().BoolVar(&p.annotateAdvisories, "annotate-advisories", false, "annotate each finding with the latest event type of its advisory, or \"none\" if it has no advisory")
+	cmd.Flags().BoolVarP(&p.disableSBOMCache, "disable-sbom-cache", "D", false, "don't use the SBOM cache")
+	cmd.Flags().BoolVar(&p.disableResultCache, "disable-result-cache", false, "don't use the scan result cache")
+	cmd.Flags().BoolVar(&p.useCPEMatching, "use-cpes", false, "turn on all CPE matching in Grype")
+	cmd.Flags().StringSliceVar(&p.matchers, "matchers", nil, fmt.Sprintf("only use the given Grype matchers, skipping packages that other matchers would handle (%s)", strings.Join(scan.ValidMatchers, "|")))
+	cmd.Flags().StringSliceVar(&p.disabledMatchers, "disable-matchers", nil, fmt.Sprintf("don't use the given Grype matchers, skipping packages that they would handle (%s)", strings.Join(scan.ValidMatchers, "|")))
+	cmd.Flags().BoolVar(&p.kev, "kev", false, "mark findings that are listed in the CISA Known Exploited Vulnerabilities (KEV) catalog")
+	cmd.Flags().BoolVar(&p.kevOnly, "kev-only", false, "only report findings that are listed in the CISA KEV catalog (implies --kev)")
+	cmd.Flags().BoolVar(&p.onlyFixed, "only-fixed", false, "only report findings whose vulnerability has a fixed version available")
+	cmd.Flags().BoolVar(&p.onlyUnfixed, "only-unfixed", false, "only report findings whose vulnerability has no fixed version available")
+	cmd.Flags().BoolVar(&p.reachability, "reachability", false, "use govulncheck to assess whether vulnerable code in Go binaries is reachable")
+	cmd.Flags().StringVar(&p.reachabilityAction, "reachability-action", scan.ReachabilityActionAnnotate, fmt.Sprintf("how to handle findings that govulncheck determines aren't reachable (%s)", strings.Join(scan.ValidReachabilityActions, "|")))
+	cmd.Flags().StringVar(&p.kevCatalogPath, "kev-catalog", "", "path to a local copy of the CISA KEV catalog JSON file to use instead of downloading it (implies --kev)")
+	cmd.Flags().BoolVar(&p.offline, "offline", false, "don't access the network to update the vulnerability database or enrichment feeds")
+	cmd.Flags().StringVar(&p.dbBundlePath, "db-bundle", "", "install the vulnerability database bundle at the given path (see 'wolfictl scan db export') and scan offline")
+	addCPEOverridesFlag(&p.cpeOverridesPath, cmd)
+	cmd.Flags().StringSliceVar(&p.vulnSourceNames, "vuln-source", nil, fmt.Sprintf("additional vulnerability source(s) to query and merge with the Grype database's findings (%s)", strings.Join(scan.ValidVulnerabilitySources, "|")))
+	cmd.Flags().StringVar(&p.ignoreFilePath, "ignore-file", "", fmt.Sprintf("path to a file of rules for suppressing findings (defaults to %s in the current directory, if it exists)", scan.DefaultIgnoreFileName))
+	cmd.Flags().DurationVar(&p.targetTimeout, "target-timeout", 0, "skip (and report) any package whose SBOM generation or vulnerability matching takes longer than this (e.g. 10m); 0 means no limit")
+}
+
+// validateScanningFlags validates the flags added by addScanningFlagsTo.
+func (p *scanParams) validateScanningFlags(ctx context.Context) error {
+	if p.dbBundlePath != "" {
+		p.offline = true
+	}
+
+	for _, m := range append(slices.Clone(p.matchers), p.disabledMatchers...) {
+		if !slices.Contains(scan.ValidMatchers, m) {
+			return fmt.Errorf(
+				"invalid matcher %q, must be one of [%s]",
+				m,
+				strings.Join(scan.ValidMatchers, ", "),
+			)
+		}
+	}
+
+	if err := validateDBAgePolicy(p.dbAgePolicy); err != nil {
+		return err
+	}
+
+	if !slices.Contains(scan.ValidReachabilityActions, p.reachabilityAction) {
+		return fmt.Errorf(
+			"invalid reachability action %q, must be one of [%s]",
+			p.reachabilityAction,
+			strings.Join(scan.ValidReachabilityActions, ", "),
+		)
+	}
+
+	if !p.reachability && p.reachabilityAction != scan.ReachabilityActionAnnotate {
+		return errors.New("cannot use --reachability-action without --reachability")
+	}
+
+	if p.reachability && p.offline {
+		return errors.New("cannot use --reachability in offline mode, since govulncheck needs to access the Go vulnerability database")
+	}
+
+	if len(p.vulnSourceNames) > 0 {
+		if p.offline {
+			return errors.New("cannot use --vuln-source in offline mode")
+		}
+
+		// Findings from online sources change independently of the vulnerability
+		// database, so they can't be cached.
+		p.disableResultCache = true
+	}
+
+	if p.onlyFixed && p.onlyUnfixed {
+		return errors.New("cannot use both --only-fixed and --only-unfixed")
+	}
+
+	if p.advisoryFilterSet != "" {
+		if !slices.Contains(scan.ValidAdvisoriesSets, p.advisoryFilterSet) {
+			return fmt.Errorf(
+				"invalid advisory filter set %q, must be one of [%s]",
+				p.advisoryFilterSet,
+				strings.Join(scan.ValidAdvisoriesSets, ", "),
+			)
+		}
+
+		if len(p.advisoriesRepoDirs) == 0 {
+			return errors.New("advisory-based filtering requested, but no advisories repo dir was provided")
+		}
+
+		clog.FromContext(ctx).Info("scan results will be filtered using advisory data", "filterSet", p.advisoryFilterSet, "advisoriesRepoDirs", p.advisoriesRepoDirs)
+	}
 
-				// Get the SBOM of the APK
-				apkSBOM, err := p.generateSBOM(ctx, inputFile)
-				if err != nil {
-					return fmt.Errorf("failed to generate SBOM: %w", err)
-				}
+	if p.annotateAdvisories && len(p.advisoriesRepoDirs) == 0 {
+		return errors.New("advisory annotation requested, but no advisories repo dir was provided")
+	}
 
-				sboms[i] = apkSBOM
-				files[i] = inputFile
+	return nil
+}
 
-				return nil
-			}
+// prepareScanning loads what the flags added by addScanningFlagsTo call for,
+// and returns the advisory getter and KEV catalog (which may be nil) to scan
+// with.
+func (p *scanParams) prepareScanning(ctx context.Context) (advisory.Getter, *scan.KEVCatalog, error) {
+	advGetter := newAdvisoriesGetter(p.advisoriesRepoDirs)
 
-			errs[i] = f()
+	if p.dbBundlePath != "" {
+		if _, err := importScanDBBundle(ctx, p.dbBundlePath); err != nil {
+			return nil, nil, err
+		}
+	}
 
-			// Signals to the other goroutine that inputs[i] is ready to scan.
-			close(done[i])
+	kevCatalog, err := p.loadKEVCatalog(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 
-			return nil
-		})
+	if err := p.loadIgnoreFile(ctx); err != nil {
+		return nil, nil, err
 	}
 
-	return scans, inputPathsFailingRequireZero, errors.Join(g.Wait(), errors.Join(errs...))
-}
+	p.cpeOverrides, err = loadCPEOverrides(ctx, p.cpeOverridesPath)
+	if err != nil {
+		return nil, nil, err
+	}
 
-type scanParams struct {
-	requireZeroFindings  bool
-	localDBFilePath      string
-	outputFormat         string
-	sbomInput            bool
-	packageBuildLogInput bool
-	distro               string
-	advisoryFilterSet    string
-	advisoriesRepoDir    string
-	disableSBOMCache     bool
-	remoteScanning       bool
-	useCPEMatching       bool
-}
+	p.vulnSources, err = newVulnerabilitySources(p.vulnSourceNames)
+	if err != nil {
+		return nil, nil, err
+	}
 
-func (p *scanParams) addFlagsTo(cmd *cobra.Command) {
-	cmd.Flags().BoolVar(&p.requireZeroFindings, "require-zero", false, "exit 1 if any vulnerabilities are found")
-	cmd.Flags().StringVar(&p.localDBFilePath, "local-file-grype-db", "", "import a local grype db file")
-	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validScanOutputFormats, "|"), outputFormatOutline))
-	cmd.Flags().BoolVarP(&p.sbomInput, "sbom", "s", false, "treat input(s) as SBOM(s) of APK(s) instead of as actual APK(s)")
-	cmd.Flags().BoolVar(&p.packageBuildLogInput, "build-log", false, "treat input as a package build log file (or a directory that contains a packages.log file)")
-	cmd.Flags().StringVar(&p.distro, "distro", "wolfi", "distro to use during vulnerability matching")
-	cmd.Flags().StringVarP(&p.advisoryFilterSet, "advisory-filter", "f", "", fmt.Sprintf("exclude vulnerability matches that are referenced from the specified set of advisories (%s)", strings.Join(scan.ValidAdvisoriesSets, "|")))
-	addAdvisoriesDirFlag(&p.advisoriesRepoDir, cmd)
-	cmd.Flags().BoolVarP(&p.disableSBOMCache, "disable-sbom-cache", "D", false, "don't use the SBOM cache")
-	cmd.Flags().BoolVarP(&p.remoteScanning, "remote", "r", false, "treat input(s) as the name(s) of package(s) in the Wolfi package repository to download and scan the latest versions of")
-	cmd.Flags().BoolVar(&p.useCPEMatching, "use-cpes", false, "turn on all CPE matching in Grype")
+	return advGetter, kevCatalog, nil
 }
 
 func (p *scanParams) resolveInputsToScan(ctx context.Context, args []string) (inputs []string, cleanup func() error, err error) {
@@ -370,14 +1384,19 @@ func (p *scanParams) resolveInputsToScan(ctx context.Context, args []string) (in
 		}
 		logger.Debug("resolved inputs from build log", "inputs", strings.Join(inputs, ", "))
 
-	case p.remoteScanning:
+	case p.remoteScanning || len(p.packages) > 0:
 		// For each input, download the APK from the Wolfi package repository and update `inputs` to point to the downloaded APKs
 
 		if p.outputFormat == outputFormatOutline {
 			fmt.Println("📡 Finding remote packages")
 		}
 
-		return resolveInputsForRemoteTarget(ctx, args)
+		names := append(slices.Clone(args), p.packages...)
+
+		return resolveInputsForRemoteTarget(ctx, names, p.arches)
+
+	case p.melangeConfigPath != "":
+		return p.resolveInputsFromMelangeConfig(ctx)
 
 	default:
 		inputs = args
@@ -391,28 +1410,114 @@ func (p *scanParams) doScanCommandForSingleInput(
 	scanner *scan.Scanner,
 	inputFile *os.File,
 	apkSBOM *sbomSyft.SBOM,
-	advGetter advisory.Getter,
 ) (*scan.Result, error) {
-	log := clog.FromContext(ctx)
-
-	result, err := scanner.APKSBOM(ctx, apkSBOM)
+	result, err := p.scanSBOM(ctx, scanner, apkSBOM)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan APK: %w", err)
 	}
 
-	inputFile.Close()
+	if inputFile != nil {
+		inputFile.Close()
+	}
+
+	return result, nil
+}
+
+// scanSBOM scans the given SBOM, which is of a directory when scanning with
+// --rootfs, or of an APK otherwise.
+func (p *scanParams) scanSBOM(ctx context.Context, scanner *scan.Scanner, ssbom *sbomSyft.SBOM) (*scan.Result, error) {
+	if p.rootfsInput {
+		return scanner.DirectorySBOM(ctx, ssbom)
+	}
+
+	return scanner.APKSBOM(ctx, ssbom)
+}
+
+func (p *scanParams) scannerOptions() scan.Options {
+	opts := scan.DefaultOptions
+	opts.UseCPEs = p.useCPEMatching
+	opts.PathOfDatabaseArchiveToImport = p.localDBFilePath
+	opts.DatabaseURL = resolveGrypeDBURL(p.grypeDBURL)
+	opts.MaxDatabaseAge = p.maxDBAge
+	opts.DatabaseAgePolicy = p.dbAgePolicy
+	opts.Offline = p.offline
+	opts.EnabledMatchers = p.matchers
+	opts.DisabledMatchers = p.disabledMatchers
+	opts.CPEOverrides = p.cpeOverrides
+	opts.Sources = p.vulnSources
+
+	return opts
+}
+
+// enrichResult applies advisory data and KEV data (where available) to the
+// given scan result.
+func (p *scanParams) enrichResult(ctx context.Context, result *scan.Result, advGetter advisory.Getter, kevCatalog *scan.KEVCatalog) error {
+	if err := p.applyAdvisoryData(ctx, result, advGetter); err != nil {
+		return err
+	}
+
+	if p.ignoreFile != nil {
+		result.Findings, result.Suppressed = p.ignoreFile.Apply(result.Findings, time.Now())
+	}
+
+	if kevCatalog != nil {
+		kevCatalog.Annotate(result.Findings)
+
+		if p.kevOnly {
+			result.Findings = scan.KnownExploitedFindings(result.Findings)
+		}
+	}
+
+	switch {
+	case p.onlyFixed:
+		result.Findings = scan.FixedFindings(result.Findings)
+	case p.onlyUnfixed:
+		result.Findings = scan.UnfixedFindings(result.Findings)
+	}
+
+	return nil
+}
+
+// newAdvisoriesGetter returns a getter for the advisories in the given
+// advisories repository directories, combining them if there's more than one.
+// It returns nil if no directories are given.
+func newAdvisoriesGetter(dirs []string) advisory.Getter {
+	switch len(dirs) {
+	case 0:
+		return nil
+	case 1:
+		return advisory.NewFSGetter(os.DirFS(dirs[0]))
+	}
+
+	getters := make([]advisory.Getter, 0, len(dirs))
+	for _, dir := range dirs {
+		getters = append(getters, advisory.NewFSGetter(os.DirFS(dir)))
+	}
+	return advisory.NewMultiGetter(getters...)
+}
+
+// applyAdvisoryData filters (if requested) and annotates the given scan result
+// using the available advisory data.
+func (p *scanParams) applyAdvisoryData(ctx context.Context, result *scan.Result, advGetter advisory.Getter) error {
+	log := clog.FromContext(ctx)
 
 	// If requested, filter scan results using advisories
 
 	if set := p.advisoryFilterSet; set != "" {
 		findings, err := scan.FilterWithAdvisories(ctx, *result, advGetter, set)
 		if err != nil {
-			return nil, fmt.Errorf("failed to filter scan results with advisories: %w", err)
+			return fmt.Errorf("failed to filter scan results with advisories: %w", err)
 		}
 
 		result.Findings = findings
 	}
 
+	if p.annotateAdvisories {
+		if err := scan.AnnotateWithAdvisories(ctx, result, advGetter); err != nil {
+			return fmt.Errorf("failed to annotate scan results with advisories: %w", err)
+		}
+	}
+
 	if advGetter != nil {
 		log.Debug("advisory data available for adding context to findings")
 
@@ -438,19 +1543,210 @@ func (p *scanParams) doScanCommandForSingleInput(
 		}
 	}
 
-	// Handle CLI options
+	return nil
+}
+
+// lookUpCachedResult computes the digest of the given input file, storing it in
+// digest, and returns the cached scan result for that digest, if there is one.
+// The input file is rewound so that it can still be used for SBOM generation.
+func lookUpCachedResult(ctx context.Context, resultCache *scan.ResultCache, inputFile *os.File, distro string, digest *string) (*scan.Result, error) {
+	d, err := scan.Digest(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute digest of input file: %w", err)
+	}
+	if _, err := inputFile.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind input file: %w", err)
+	}
+	*digest = d
+
+	cached, err := resultCache.Get(ctx, d, distro)
+	if err != nil {
+		// A bad cache entry shouldn't fail the scan, it'll just be overwritten.
+		clog.FromContext(ctx).Warn("failed to read cached scan result", "input", inputFile.Name(), "error", err)
+		return nil, nil
+	}
+
+	return cached, nil
+}
+
+// loadKEVCatalog returns the CISA KEV catalog if KEV enrichment was requested,
+// otherwise it returns nil.
+func (p *scanParams) loadKEVCatalog(ctx context.Context) (*scan.KEVCatalog, error) {
+	if p.kevCatalogPath != "" {
+		catalog, err := scan.ReadKEVCatalog(p.kevCatalogPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load KEV catalog: %w", err)
+		}
+		return catalog, nil
+	}
+
+	if !p.kev && !p.kevOnly {
+		return nil, nil
+	}
 
-	findings := result.Findings
-	if p.outputFormat == outputFormatOutline {
-		// Print output immediately
-		render, err := scanfindings.Render(findings)
+	kevOpts := scan.DefaultKEVOptions
+	kevOpts.Offline = p.offline
+
+	catalog, err := scan.LoadKEVCatalog(ctx, kevOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load KEV catalog: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// analyzeReachability assesses the reachability of the Go module findings in
+// the given result, if requested, using the APK at apkPath.
+func (p *scanParams) analyzeReachability(ctx context.Context, apkPath string, result *scan.Result) error {
+	if !p.reachability {
+		return nil
+	}
+
+	if err := scan.AnalyzeReachability(ctx, apkPath, result.Findings); err != nil {
+		return fmt.Errorf("failed to analyze reachability: %w", err)
+	}
+
+	result.Findings = scan.ApplyReachabilityAction(result.Findings, p.reachabilityAction)
+	return nil
+}
+
+// addToHistory records the given scan result in p.history, if history is being
+// recorded. Failing to record history doesn't fail the scan.
+func (p *scanParams) addToHistory(ctx context.Context, result *scan.Result) {
+	if p.history == nil {
+		return
+	}
+
+	if err := p.history.Record(ctx, result, time.Now()); err != nil {
+		clog.FromContext(ctx).Warn("failed to record scan history", "package", result.TargetAPK.Name, "error", err)
+	}
+}
+
+// reportMetrics writes and/or pushes the metrics accumulated in p.runStats, as
+// requested.
+func (p *scanParams) reportMetrics(ctx context.Context) error {
+	now := time.Now()
+
+	if p.metricsFilePath != "" {
+		f, err := os.Create(p.metricsFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to create metrics file: %w", err)
+		}
+		defer f.Close()
+
+		if err := p.runStats.WriteOpenMetrics(f, now); err != nil {
+			return fmt.Errorf("failed to write metrics file: %w", err)
+		}
+
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write metrics file: %w", err)
+		}
+	}
+
+	if p.metricsPushURL != "" {
+		if err := p.runStats.Push(ctx, p.metricsPushURL, p.metricsJob, now); err != nil {
+			return err
+		}
+	}
+
+	clog.FromContext(ctx).Debug("reported scan metrics", "file", p.metricsFilePath, "pushURL", p.metricsPushURL)
+	return nil
+}
+
+// writeSummary writes a summary of the given scan results, evaluated against the
+// configured thresholds, to the summary output file.
+func (p *scanParams) writeSummary(ctx context.Context, scans []scan.Result) error {
+	summary := scan.Summarize(scans, scan.Thresholds{
+		RequireZero:    p.requireZeroFindings,
+		FailOnSeverity: strings.ToLower(p.failOnSeverity),
+	})
+
+	f, err := os.Create(p.summaryOutputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create summary file: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(summary); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+
+	clog.FromContext(ctx).Debug("wrote scan summary", "file", p.summaryOutputPath, "pass", summary.Pass)
+	return nil
+}
+
+// loadIgnoreFile loads the suppression rules from the ignore file, if one was
+// specified or the default ignore file exists.
+func (p *scanParams) loadIgnoreFile(ctx context.Context) error {
+	logger := clog.FromContext(ctx)
+
+	path := p.ignoreFilePath
+	if path == "" {
+		path = scan.DefaultIgnoreFileName
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+
+	ignoreFile, err := scan.LoadIgnoreFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range ignoreFile.ExpiredRules(time.Now()) {
+		logger.Warn("ignore rule has expired and will not suppress findings", "vulnerability", rule.Vulnerability, "package", rule.Package, "expires", rule.Expires)
+	}
+
+	logger.Info("scan findings will be suppressed using ignore rules", "path", path, "rules", len(ignoreFile.Rules))
+	p.ignoreFile = ignoreFile
+	return nil
+}
+
+func addCPEOverridesFlag(val *string, cmd *cobra.Command) {
+	cmd.Flags().StringVar(val, "cpe-overrides", "", "path to a file that overrides or suppresses the CPEs used to match specific packages")
+}
+
+// loadCPEOverrides loads the CPE overrides file at the given path. If path is
+// empty, it returns nil, which means no overrides.
+func loadCPEOverrides(ctx context.Context, path string) (*scan.CPEOverrides, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	overrides, err := scan.LoadCPEOverrides(path)
+	if err != nil {
+		return nil, err
+	}
+
+	clog.FromContext(ctx).Info("package CPEs will be overridden during matching", "path", path, "overrides", len(overrides.Packages))
+	return overrides, nil
+}
+
+// newVulnerabilitySources returns the additional vulnerability sources with the
+// given names. The GitHub source is authenticated using the GITHUB_TOKEN
+// environment variable, if it's set.
+func newVulnerabilitySources(names []string) ([]scan.VulnerabilitySource, error) {
+	sources := make([]scan.VulnerabilitySource, 0, len(names))
+	for _, name := range names {
+		src, err := scan.NewVulnerabilitySource(name)
 		if err != nil {
 			return nil, err
 		}
-		fmt.Println(render)
+
+		if gh, ok := src.(*scan.GitHubAdvisorySource); ok {
+			gh.Token = os.Getenv("GITHUB_TOKEN")
+		}
+
+		sources = append(sources, src)
 	}
 
-	return result, nil
+	return sources, nil
 }
 
 func (p *scanParams) generateSBOM(ctx context.Context, f *os.File) (*sbomSyft.SBOM, error) {
@@ -465,6 +1761,65 @@ func (p *scanParams) generateSBOM(ctx context.Context, f *os.File) (*sbomSyft.SB
 	return sbom.CachedGenerate(ctx, f.Name(), f, p.distro)
 }
 
+// resolveInputsFromMelangeConfig finds the APKs of every package produced by
+// the melange config at p.melangeConfigPath, for each of p.arches. APKs are
+// looked for in p.packagesDir first, and packages that have no local APKs are
+// downloaded from the package repository (unless scanning offline).
+func (p *scanParams) resolveInputsFromMelangeConfig(ctx context.Context) (inputs []string, cleanup func() error, err error) {
+	logger := clog.FromContext(ctx)
+
+	cfg, err := config.ParseConfiguration(ctx, p.melangeConfigPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse melange config: %w", err)
+	}
+
+	names := []string{cfg.Package.Name}
+	for i := range cfg.Subpackages {
+		names = append(names, cfg.Subpackages[i].Name)
+	}
+
+	fullVersion := fmt.Sprintf("%s-r%d", cfg.Package.Version, cfg.Package.Epoch)
+
+	var remoteNames []string
+	for _, name := range names {
+		found := false
+		for _, arch := range p.arches {
+			apkPath := filepath.Join(p.packagesDir, arch, fmt.Sprintf("%s-%s.apk", name, fullVersion))
+			if _, err := os.Stat(apkPath); err != nil {
+				continue
+			}
+
+			inputs = append(inputs, apkPath)
+			found = true
+		}
+
+		if !found {
+			remoteNames = append(remoteNames, name)
+		}
+	}
+
+	if p.outputFormat == outputFormatOutline {
+		fmt.Printf("📦 Scanning %d package(s) from %s (%s): %s\n", len(names), p.melangeConfigPath, fullVersion, strings.Join(names, ", "))
+	}
+
+	if len(remoteNames) == 0 {
+		return inputs, nil, nil
+	}
+
+	if p.offline {
+		return nil, nil, fmt.Errorf("no APKs found in %q for package(s) %s, and can't download them in offline mode", p.packagesDir, strings.Join(remoteNames, ", "))
+	}
+
+	logger.Info("packages not found locally, using their latest versions from the package repository", "packages", strings.Join(remoteNames, ", "), "packagesDir", p.packagesDir)
+
+	remoteInputs, cleanup, err := resolveInputsForRemoteTarget(ctx, remoteNames, p.arches)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append(inputs, remoteInputs...), cleanup, nil
+}
+
 // resolveInputFilePathsFromBuildLog takes the given path to a Melange build log
 // file (or a directory that contains the build log as a "packages.log" file).
 // Once it finds the build log, it parses it, and returns a slice of file paths
@@ -658,6 +2013,15 @@ func resolveInputForRemoteTarget(ctx context.Context, indices map[string]map[str
 	}
 	apkTmpFilePath := tmpFile.Name()
 
+	// Don't leave the temp file behind if the download fails.
+	downloaded := false
+	defer func() {
+		if !downloaded {
+			_ = tmpFile.Close()
+			_ = os.Remove(apkTmpFilePath)
+		}
+	}()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("creating request for %q: %w", downloadURL, err)
@@ -672,6 +2036,7 @@ func resolveInputForRemoteTarget(ctx context.Context, indices map[string]map[str
 	if err != nil {
 		return "", fmt.Errorf("downloading %q: %w", downloadURL, err)
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
@@ -684,16 +2049,28 @@ func resolveInputForRemoteTarget(ctx context.Context, indices map[string]map[str
 	if err != nil {
 		return "", fmt.Errorf("saving contents of %q to %q: %w", downloadURL, apkTmpFilePath, err)
 	}
-	resp.Body.Close()
 	if err := tmpFile.Close(); err != nil {
 		return "", fmt.Errorf("closing %s: %w", apkTempFileName, err)
 	}
+	downloaded = true
 
 	logger.Info("downloaded APK", "path", apkTmpFilePath)
 
 	return apkTmpFilePath, nil
 }
 
+// supportedRemoteArches are the architectures for which packages can be scanned
+// from the Wolfi package repository.
+var supportedRemoteArches = []string{"x86_64", "aarch64"}
+
+// remoteAPKRepositoryURLs are the APK repositories searched for packages to
+// scan remotely.
+var remoteAPKRepositoryURLs = []string{
+	"https://packages.wolfi.dev/os",
+	"https://apk.cgr.dev/chainguard-private",
+	"https://apk.cgr.dev/extra-packages",
+}
+
 // resolveInputsForRemoteTarget takes the given input strings, which are expected
 // to be the name of a package (or subpackage), and it queries the APK repositories
 // to find the latest version of the packages for each architecture.
@@ -702,7 +2079,7 @@ func resolveInputForRemoteTarget(ctx context.Context, indices map[string]map[str
 // For example, given the input value []string{"calico"}, this function will find the
 // latest version of the package (e.g. "calico-3.26.3-r3.apk") and download it
 // for each architecture.
-func resolveInputsForRemoteTarget(ctx context.Context, inputs []string) (downloadedAPKFilePaths []string, cleanup func() error, err error) {
+func resolveInputsForRemoteTarget(ctx context.Context, inputs, arches []string) (downloadedAPKFilePaths []string, cleanup func() error, err error) {
 	var (
 		mu sync.Mutex
 		ig errgroup.Group
@@ -710,13 +2087,9 @@ func resolveInputsForRemoteTarget(ctx context.Context, inputs []string) (downloa
 
 	c := client.New(http.DefaultClient)
 	indices := map[string]map[string]*apk.APKIndex{}
-	for _, apkRepositoryURL := range []string{
-		"https://packages.wolfi.dev/os",
-		"https://apk.cgr.dev/chainguard-private",
-		"https://apk.cgr.dev/extra-packages",
-	} {
+	for _, apkRepositoryURL := range remoteAPKRepositoryURLs {
 		byArch := map[string]*apk.APKIndex{}
-		for _, arch := range []string{"x86_64", "aarch64"} {
+		for _, arch := range arches {
 			ig.Go(func() error {
 				apkindex, err := c.GetRemoteIndex(ctx, apkRepositoryURL, arch)
 				if err != nil {
@@ -739,50 +2112,43 @@ func resolveInputsForRemoteTarget(ctx context.Context, inputs []string) (downloa
 		return nil, nil, err
 	}
 
-	var (
-		ag          errgroup.Group
-		archesFound = map[string]int{}
-	)
+	var ag errgroup.Group
+
+	// pathsByInput[i][j] is the downloaded APK for inputs[i] and arches[j], if
+	// any. This keeps the results in a predictable order, with each input's
+	// arches together.
+	pathsByInput := make([][]string, len(inputs))
+	for i := range inputs {
+		pathsByInput[i] = make([]string, len(arches))
+	}
 
-	for _, input := range inputs {
-		for _, arch := range []string{"x86_64", "aarch64"} {
+	for i, input := range inputs {
+		for j, arch := range arches {
 			ag.Go(func() error {
 				apkTmpFilePath, err := resolveInputForRemoteTarget(ctx, indices, arch, input)
 				if err != nil {
 					return err
 				}
 
-				if apkTmpFilePath == "" {
-					return nil
-				}
-
-				mu.Lock()
-				defer mu.Unlock()
-
-				// we found a package for this arch
-				archesFound[input]++
-				downloadedAPKFilePaths = append(downloadedAPKFilePaths, apkTmpFilePath)
+				pathsByInput[i][j] = apkTmpFilePath
 
 				return nil
 			})
 		}
 	}
 
-	if err := ag.Wait(); err != nil {
-		return nil, nil, err
-	}
-
-	for input, archesFound := range archesFound {
-		if archesFound == 0 {
-			return nil, nil, fmt.Errorf("no packages found with name %q in any arch", input)
-		}
-	}
-
+	// cleanup removes all of the downloaded APKs, including those for the other
+	// inputs if one of them fails.
 	cleanup = func() error {
 		var errs []error
-		for _, path := range downloadedAPKFilePaths {
-			if err := os.Remove(path); err != nil {
-				errs = append(errs, err)
+		for _, paths := range pathsByInput {
+			for _, path := range paths {
+				if path == "" {
+					continue
+				}
+				if err := os.Remove(path); err != nil {
+					errs = append(errs, err)
+				}
 			}
 		}
 		if len(errs) > 0 {
@@ -791,6 +2157,27 @@ func resolveInputsForRemoteTarget(ctx context.Context, inputs []string) (downloa
 		return nil
 	}
 
+	if err := ag.Wait(); err != nil {
+		return nil, nil, errors.Join(err, cleanup())
+	}
+
+	for i, input := range inputs {
+		archesFound := 0
+		for _, path := range pathsByInput[i] {
+			if path == "" {
+				continue
+			}
+
+			archesFound++
+			downloadedAPKFilePaths = append(downloadedAPKFilePaths, path)
+		}
+
+		if archesFound == 0 {
+			err := fmt.Errorf("no packages found with name %q in any of the requested arches (%s)", input, strings.Join(arches, ", "))
+			return nil, nil, errors.Join(err, cleanup())
+		}
+	}
+
 	return downloadedAPKFilePaths, cleanup, nil
 }
 
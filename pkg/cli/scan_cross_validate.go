@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/components/scanfindings"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+	"golang.org/x/exp/slices"
+)
+
+func cmdScanCrossValidate() *cobra.Command {
+	p := &scanCrossValidateParams{}
+	cmd := &cobra.Command{
+		Use:   "cross-validate --backend <backend> <apk>...",
+		Short: "Compare the findings of the scanner with those of another scanner",
+		Long: `Scan APKs with both wolfictl's scanner (which uses Grype) and a secondary
+scanner backend, and report the findings that are unique to each engine.
+
+This is useful for detecting matching regressions, e.g. after a vulnerability
+database update. The following backends are supported, and their executables
+must be on the PATH:
+
+- "trivy": Trivy (https://trivy.dev)
+
+- "osv-scanner": OSV-Scanner (https://google.github.io/osv-scanner/)
+
+Each APK's contents are extracted and scanned by the secondary backend as a
+filesystem. Findings are considered the same if they affect a package with the
+same name and share a vulnerability ID or alias. Note that backends differ in
+which kinds of packages they detect, so some differences are expected.
+
+Each argument must be the path to a local APK file.
+`,
+		Example: `
+# Compare findings with Trivy
+wolfictl scan cross-validate --backend trivy crane-0.19.1-r6.apk
+
+# Compare findings with OSV-Scanner, as JSON
+wolfictl scan cross-validate --backend osv-scanner -o json packages/x86_64/*.apk
+`,
+		Args:          cobra.MinimumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatOutline
+			}
+
+			if !slices.Contains(validScanCrossValidateOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validScanCrossValidateOutputFormats, ", "),
+				)
+			}
+
+			backend, err := scan.NewBackend(p.backend)
+			if err != nil {
+				return err
+			}
+
+			for _, arg := range args {
+				if _, err := os.Stat(arg); err != nil {
+					return fmt.Errorf("APK %q must be a local file: %w", arg, err)
+				}
+			}
+
+			// Reuse the scan command's machinery, but keep it quiet, since we're only
+			// interested in the comparison.
+			sp := &scanParams{
+				localDBFilePath:  p.localDBFilePath,
+				grypeDBURL:       p.grypeDBURL,
+				outputFormat:     outputFormatJSON,
+				distro:           p.distro,
+				disableSBOMCache: p.disableSBOMCache,
+				useCPEMatching:   p.useCPEMatching,
+				jobs:             p.jobs,
+			}
+
+			scans, _, err := scanEverything(ctx, sp, args, nil, nil)
+			if err != nil {
+				return err
+			}
+
+			reports := make([]scan.CrossValidationReport, 0, len(args))
+			for i, apkPath := range args {
+				secondary, err := backend.ScanAPK(ctx, apkPath)
+				if err != nil {
+					return fmt.Errorf("failed to scan %q with %s: %w", apkPath, backend.Name(), err)
+				}
+
+				reports = append(reports, scan.CrossValidate(&scans[i], "grype", secondary, backend.Name()))
+			}
+
+			if p.outputFormat == outputFormatJSON {
+				enc := json.NewEncoder(os.Stdout)
+				if err := enc.Encode(reports); err != nil {
+					return fmt.Errorf("failed to marshal cross-validation reports to JSON: %w", err)
+				}
+				return nil
+			}
+
+			for i := range reports {
+				if err := renderCrossValidationReport(args[i], &reports[i]); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+var validScanCrossValidateOutputFormats = []string{outputFormatOutline, outputFormatJSON}
+
+type scanCrossValidateParams struct {
+	backend          string
+	localDBFilePath  string
+	grypeDBURL       string
+	outputFormat     string
+	distro           string
+	disableSBOMCache bool
+	useCPEMatching   bool
+	jobs             int
+}
+
+func (p *scanCrossValidateParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.backend, "backend", scan.BackendTrivy, fmt.Sprintf("secondary scanner backend to compare with (%s)", strings.Join(scan.ValidBackends, "|")))
+	cmd.Flags().StringVar(&p.localDBFilePath, "local-file-grype-db", "", "import a local grype db file")
+	addGrypeDBURLFlag(&p.grypeDBURL, cmd)
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validScanCrossValidateOutputFormats, "|"), outputFormatOutline))
+	cmd.Flags().StringVar(&p.distro, "distro", "wolfi", "distro to use during vulnerability matching")
+	cmd.Flags().BoolVarP(&p.disableSBOMCache, "disable-sbom-cache", "D", false, "don't use the SBOM cache")
+	cmd.Flags().BoolVar(&p.useCPEMatching, "use-cpes", false, "turn on all CPE matching in Grype")
+	cmd.Flags().IntVarP(&p.jobs, "jobs", "j", 1, "number of packages to scan concurrently")
+}
+
+func renderCrossValidationReport(apkPath string, report *scan.CrossValidationReport) error {
+	fmt.Printf("🔎 Cross-validating %q (%s vs. %s)\n", apkPath, report.Primary, report.Secondary)
+
+	sections := []struct {
+		heading  string
+		findings []scan.Finding
+	}{
+		{"🤝 Reported by both", report.Agreed},
+		{fmt.Sprintf("⬅️  Only reported by %s", report.Primary), report.OnlyPrimary},
+		{fmt.Sprintf("➡️  Only reported by %s", report.Secondary), report.OnlySecondary},
+	}
+
+	for _, s := range sections {
+		fmt.Printf("\n%s (%d)\n", s.heading, len(s.findings))
+
+		if len(s.findings) == 0 {
+			continue
+		}
+
+		render, err := scanfindings.Render(s.findings)
+		if err != nil {
+			return err
+		}
+		fmt.Println(render)
+	}
+
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+)
+
+func cmdScanDB() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the vulnerability database used for scanning",
+		Long: `Manage the vulnerability database used for scanning.
+
+To scan packages in an environment without network access (an "air-gapped"
+environment), first use "wolfictl scan db export" somewhere with network
+access to create a bundle that contains the vulnerability database and any
+enrichment feeds (such as the CISA KEV catalog). Carry the bundle into the
+offline environment, and then either install it using "wolfictl scan db
+import", or pass it directly to "wolfictl scan" using the --db-bundle flag.
+
+To download the vulnerability database from an internal mirror of Grype's
+database location (https://grype.anchore.io/databases), use the --grype-db-url
+flag of the scan commands, or set the WOLFICTL_GRYPE_DB_URL environment
+variable. The mirror must have the same layout as the upstream location.
+Downloaded database archives are verified against the checksums in the
+mirror's listing file, and interrupted downloads are resumed.
+
+By default, the scan commands refresh the vulnerability database when it's
+older than --max-db-age (48 hours, unless specified), including a database
+imported using --local-file-grype-db, and fail if a new enough database can't
+be downloaded. Use --db-age-policy to fail immediately instead ("fail"), or to
+only warn about the database's age ("warn"). In offline mode, the "refresh"
+policy only warns.
+`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(
+		cmdScanDBExport(),
+		cmdScanDBImport(),
+	)
+
+	return cmd
+}
+
+func cmdScanDBExport() *cobra.Command {
+	p := &scanDBExportParams{}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the vulnerability database and enrichment feeds as a bundle for offline use",
+		Example: `
+# Create a bundle for use in an air-gapped environment
+wolfictl scan db export -o wolfictl-scan-db.tar.gz
+`,
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if p.outputPath == "" {
+				return errors.New("an output file path must be specified with --output")
+			}
+
+			opts := scan.DefaultOptions
+			opts.PathOfDatabaseArchiveToImport = p.localDBFilePath
+			opts.DatabaseURL = resolveGrypeDBURL(p.grypeDBURL)
+
+			scanner, err := scan.NewScanner(opts)
+			if err != nil {
+				return fmt.Errorf("failed to create scanner: %w", err)
+			}
+			defer scanner.Close()
+
+			var exportOpts scan.ExportBundleOptions
+			if !p.excludeKEV {
+				kevOpts := scan.DefaultKEVOptions
+				if _, err := scan.LoadKEVCatalog(ctx, kevOpts); err != nil {
+					return fmt.Errorf("failed to load KEV catalog: %w", err)
+				}
+				exportOpts.KEVCatalogPath = kevOpts.CachePath
+			}
+
+			f, err := os.Create(p.outputPath)
+			if err != nil {
+				return fmt.Errorf("failed to create bundle file: %w", err)
+			}
+			defer f.Close()
+
+			manifest, err := scanner.ExportBundle(ctx, f, exportOpts)
+			if err != nil {
+				return err
+			}
+
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write bundle file: %w", err)
+			}
+
+			fmt.Fprintf(os.Stderr, "📦 Exported vulnerability database (built %s) to %s\n", manifest.GrypeDB.Built.Format("2006-01-02T15:04:05Z"), p.outputPath)
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type scanDBExportParams struct {
+	outputPath      string
+	localDBFilePath string
+	grypeDBURL      string
+	excludeKEV      bool
+}
+
+func (p *scanDBExportParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&p.outputPath, "output", "o", "", "path of the bundle file to create")
+	cmd.Flags().StringVar(&p.localDBFilePath, "local-file-grype-db", "", "import a local grype db file instead of using the latest available database")
+	addGrypeDBURLFlag(&p.grypeDBURL, cmd)
+	cmd.Flags().BoolVar(&p.excludeKEV, "no-kev", false, "don't include the CISA KEV catalog in the bundle")
+}
+
+func cmdScanDBImport() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <bundle>",
+		Short: "Install a vulnerability database bundle for offline scanning",
+		Long: `Install the vulnerability database and enrichment feeds from a bundle created
+by "wolfictl scan db export".
+
+Once the bundle is installed, use "wolfictl scan --offline" to scan without
+any network access.
+`,
+		Example: `
+# Install a bundle, then scan offline
+wolfictl scan db import wolfictl-scan-db.tar.gz
+wolfictl scan --offline --kev /path/to/package.apk
+`,
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifest, err := importScanDBBundle(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "📦 Installed vulnerability database (built %s)\n", manifest.GrypeDB.Built.Format("2006-01-02T15:04:05Z"))
+			for _, feed := range manifest.Feeds {
+				fmt.Fprintf(os.Stderr, "📦 Installed %s feed (version %s)\n", feed.Name, feed.Version)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// envVarNameForGrypeDBURL names the environment variable that sets the default
+// for the --grype-db-url flag, so that a mirror can be configured once for a
+// whole build environment.
+const envVarNameForGrypeDBURL = "WOLFICTL_GRYPE_DB_URL"
+
+func addGrypeDBURLFlag(val *string, cmd *cobra.Command) {
+	cmd.Flags().StringVar(val, "grype-db-url", "", fmt.Sprintf("URL of a mirror from which to download the Grype vulnerability database (defaults to $%s, or to Grype's upstream URL if unset)", envVarNameForGrypeDBURL))
+}
+
+func addDBAgeFlags(maxAge *time.Duration, policy *string, cmd *cobra.Command) {
+	cmd.Flags().DurationVar(maxAge, "max-db-age", scan.DefaultMaxDatabaseAge, "maximum allowed age of the vulnerability database")
+	cmd.Flags().StringVar(policy, "db-age-policy", scan.DatabaseAgePolicyRefresh, fmt.Sprintf("what to do when the vulnerability database is older than --max-db-age (%s)", strings.Join(scan.ValidDatabaseAgePolicies, "|")))
+}
+
+func validateDBAgePolicy(policy string) error {
+	if !slices.Contains(scan.ValidDatabaseAgePolicies, policy) {
+		return fmt.Errorf(
+			"invalid database age policy %q, must be one of [%s]",
+			policy,
+			strings.Join(scan.ValidDatabaseAgePolicies, ", "),
+		)
+	}
+
+	return nil
+}
+
+func resolveGrypeDBURL(cliFlagValue string) string {
+	if v := cliFlagValue; v != "" {
+		return v
+	}
+
+	return os.Getenv(envVarNameForGrypeDBURL)
+}
+
+func importScanDBBundle(ctx context.Context, bundlePath string) (*scan.BundleManifest, error) {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	manifest, err := scan.ImportBundle(ctx, f, scan.ImportBundleOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import bundle %q: %w", bundlePath, err)
+	}
+
+	clog.FromContext(ctx).Debug("imported scan database bundle", "path", bundlePath, "created", manifest.Created)
+	return manifest, nil
+}
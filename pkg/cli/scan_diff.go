@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/components/scanfindings"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+	"golang.org/x/exp/slices"
+)
+
+func cmdScanDiff() *cobra.Command {
+	p := &scanDiffParams{}
+	cmd := &cobra.Command{
+		Use:   "diff <before> <after>",
+		Short: "Compare the vulnerability findings of two builds of a package",
+		Long: `Scan two APKs (typically two builds of the same package) and report which
+findings were added, which were removed, and which are unchanged.
+
+This is useful for verifying that a version bump actually resolves the
+vulnerabilities it was expected to resolve.
+
+Findings are matched by vulnerability ID and by the name and type of the
+affected component, so a vulnerability that's still present in a newer version
+of the same component is reported as unchanged.
+
+Each argument can be anything that "wolfictl scan" accepts as an APK input: a
+local file path, an https:// URL, or "-" for stdin.
+`,
+		Example: `
+# Verify a version bump
+wolfictl scan diff foo-1.2.3-r0.apk foo-1.2.4-r0.apk
+
+# Ignore vulnerabilities that already have resolved advisories
+wolfictl scan diff foo-1.2.3-r0.apk foo-1.2.4-r0.apk -a ../advisories -f resolved
+`,
+		Args:          cobra.ExactArgs(2),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatOutline
+			}
+
+			if !slices.Contains(validScanDiffOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validScanDiffOutputFormats, ", "),
+				)
+			}
+
+			// Reuse the scan command's machinery, but keep it quiet, since we're only
+			// interested in the comparison.
+			sp := &p.scanParams
+			sp.outputFormat = outputFormatJSON
+			sp.jobs = 2
+
+			if err := sp.validateScanningFlags(ctx); err != nil {
+				return err
+			}
+
+			advGetter, kevCatalog, err := sp.prepareScanning(ctx)
+			if err != nil {
+				return err
+			}
+
+			scans, _, err := scanEverything(ctx, sp, args, advGetter, kevCatalog)
+			if err != nil {
+				return err
+			}
+
+			// A comparison with a scan that didn't finish would be misleading.
+			if len(sp.timedOutInputs) > 0 {
+				return fmt.Errorf("scans of the following package(s) timed out after %s:\n%s", sp.targetTimeout, strings.Join(sp.timedOutInputs, "\n"))
+			}
+
+			diff := scan.DiffResults(&scans[0], &scans[1])
+
+			if p.outputFormat == outputFormatJSON {
+				enc := json.NewEncoder(os.Stdout)
+				if err := enc.Encode(diff); err != nil {
+					return fmt.Errorf("failed to marshal scan diff to JSON: %w", err)
+				}
+				return nil
+			}
+
+			return renderScanDiff(diff)
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+var validScanDiffOutputFormats = []string{outputFormatOutline, outputFormatJSON}
+
+type scanDiffParams struct {
+	// scanParams configures the scans of the two APKs, using the same scanning
+	// flags as the scan command.
+	scanParams scanParams
+
+	outputFormat string
+}
+
+func (p *scanDiffParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validScanDiffOutputFormats, "|"), outputFormatOutline))
+	p.scanParams.addScanningFlagsTo(cmd)
+}
+
+func renderScanDiff(diff scan.ResultDiff) error {
+	fmt.Printf(
+		"🔎 Comparing %s %s → %s %s\n",
+		diff.Before.Name, diff.Before.Version,
+		diff.After.Name, diff.After.Version,
+	)
+
+	sections := []struct {
+		heading  string
+		findings []scan.Finding
+	}{
+		{"➕ Added", diff.Added},
+		{"➖ Removed", diff.Removed},
+		{"🟰 Unchanged", diff.Unchanged},
+	}
+
+	for _, s := range sections {
+		fmt.Printf("\n%s (%d)\n", s.heading, len(s.findings))
+
+		if len(s.findings) == 0 {
+			continue
+		}
+
+		render, err := scanfindings.Render(s.findings)
+		if err != nil {
+			return err
+		}
+		fmt.Println(render)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanDiffFlagValidation(t *testing.T) {
+	cases := []struct {
+		name        string
+		args        []string
+		expectedErr string
+	}{
+		{
+			name:        "invalid matcher",
+			args:        []string{"--matchers", "foo"},
+			expectedErr: `invalid matcher "foo"`,
+		},
+		{
+			name:        "--vuln-source in offline mode",
+			args:        []string{"--offline", "--vuln-source", "osv"},
+			expectedErr: "cannot use --vuln-source in offline mode",
+		},
+		{
+			name:        "--only-fixed with --only-unfixed",
+			args:        []string{"--only-fixed", "--only-unfixed"},
+			expectedErr: "cannot use both --only-fixed and --only-unfixed",
+		},
+		{
+			name:        "missing CPE overrides file",
+			args:        []string{"--cpe-overrides", "testdata/does-not-exist.yaml", "--target-timeout", "10m"},
+			expectedErr: "does-not-exist.yaml",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := cmdScanDiff()
+			cmd.SetArgs(append([]string{"before.apk", "after.apk"}, tt.args...))
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			err := cmd.ExecuteContext(context.Background())
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+)
+
+func cmdScanSchema() *cobra.Command {
+	p := &scanSchemaParams{}
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the scan command's JSON output",
+		Long: fmt.Sprintf(`Print the JSON Schema that describes the output of "wolfictl scan -o json".
+Each line of "wolfictl scan -o ndjson" output is a single result, as described
+by the schema's "Result" definition.
+
+Every result includes a "schemaVersion" field. The current schema version is
+%q. The version is incremented whenever the output changes in a way that could
+break consumers, so consumers can validate results against the schema, and pin
+the version they support.
+
+To upgrade results that were saved using an older version of wolfictl to the
+current schema version, use the --migrate flag with the path to the saved
+results ("-" for stdin), which can be JSON or NDJSON. The migrated results are
+printed as JSON. Results saved before results were versioned (i.e. without a
+"schemaVersion" field) are supported too.
+`, scan.ResultSchemaVersion),
+		Example: `
+# Validate scan results in CI
+wolfictl scan schema > scan.schema.json
+wolfictl scan -o json /path/to/package.apk > results.json
+check-jsonschema --schemafile scan.schema.json results.json
+
+# Upgrade results saved by an older version of wolfictl
+wolfictl scan schema --migrate old-results.json > results.json
+`,
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if p.migratePath == "" {
+				_, err := os.Stdout.Write(scan.ResultJSONSchema())
+				return err
+			}
+
+			var r io.Reader = os.Stdin
+			if p.migratePath != "-" {
+				f, err := os.Open(p.migratePath)
+				if err != nil {
+					return fmt.Errorf("failed to open scan results: %w", err)
+				}
+				defer f.Close()
+				r = f
+			}
+
+			results, err := scan.DecodeResults(r)
+			if err != nil {
+				return err
+			}
+
+			if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+				return fmt.Errorf("failed to marshal scans to JSON: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type scanSchemaParams struct {
+	migratePath string
+}
+
+func (p *scanSchemaParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.migratePath, "migrate", "", "path to saved scan results (or \"-\" for stdin) to upgrade to the current schema version, instead of printing the schema")
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+	"github.com/wolfi-dev/wolfictl/pkg/scan/scangrpc"
+	scanv1 "github.com/wolfi-dev/wolfictl/pkg/scan/scangrpc/v1"
+	"google.golang.org/grpc"
+)
+
+func cmdScanServe() *cobra.Command {
+	p := &scanServeParams{}
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run an HTTP server that scans APKs using a vulnerability database kept in memory",
+		Long: `Run an HTTP server that scans APKs for vulnerabilities.
+
+The vulnerability database is loaded once, when the server starts, which avoids
+the cost of loading it for every scan. The server exposes these endpoints:
+
+- POST /v1/scan: Scan an APK, responding with the scan result as JSON (in the
+  same format as "wolfictl scan -o json"). The APK can be sent as the raw
+  request body, as the "apk" file of a multipart form, or by URL, using a JSON
+  request body like {"url": "https://..."}. Use the "distro" query parameter
+  to override the distro used during vulnerability matching.
+
+- GET /v1/status: Describe the loaded vulnerability database.
+
+- GET /healthz: Respond with 200 OK once the server is ready.
+
+Use --grpc-addr to also serve the gRPC scanning API (the wolfictl.scan.v1.Scanner
+service, defined in pkg/scan/scangrpc/v1/scan.proto), which additionally
+supports scanning container images.
+
+By default, the server only listens on localhost. Use --addr (and --grpc-addr)
+to listen on other interfaces, and only expose the server to trusted clients.
+
+APKs specified by URL are only downloaded from the URL prefixes given by
+--allowed-url-prefix, which are the APK repositories searched by
+"wolfictl scan --remote" by default. Use --allowed-url-prefix="" to disallow
+scanning APKs by URL.
+`,
+		Example: `
+# Start the server
+wolfictl scan serve
+
+# Scan an APK by uploading it
+curl --data-binary @crane-0.19.1-r6.apk localhost:8080/v1/scan
+
+# Also serve the gRPC API
+wolfictl scan serve --grpc-addr localhost:9090
+
+# Scan an APK by URL
+curl -H 'Content-Type: application/json' \
+  -d '{"url": "https://packages.wolfi.dev/os/x86_64/crane-0.19.1-r6.apk"}' \
+  localhost:8080/v1/scan
+`,
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			logger := clog.FromContext(ctx)
+
+			if err := validateDBAgePolicy(p.dbAgePolicy); err != nil {
+				return err
+			}
+
+			cpeOverrides, err := loadCPEOverrides(ctx, p.cpeOverridesPath)
+			if err != nil {
+				return err
+			}
+
+			opts := scan.DefaultOptions
+			opts.UseCPEs = p.useCPEMatching
+			opts.PathOfDatabaseArchiveToImport = p.localDBFilePath
+			opts.DatabaseURL = resolveGrypeDBURL(p.grypeDBURL)
+			opts.MaxDatabaseAge = p.maxDBAge
+			opts.DatabaseAgePolicy = p.dbAgePolicy
+			opts.DisableSBOMCache = p.disableSBOMCache
+			opts.Offline = p.offline
+			opts.EnabledMatchers = p.matchers
+			opts.DisabledMatchers = p.disabledMatchers
+			opts.CPEOverrides = cpeOverrides
+
+			scanner, err := scan.NewScanner(opts)
+			if err != nil {
+				return fmt.Errorf("failed to create scanner: %w", err)
+			}
+			defer scanner.Close()
+
+			serverOpts := scan.DefaultServerOptions
+			serverOpts.DistroID = p.distro
+			serverOpts.MaxConcurrentScans = p.jobs
+			serverOpts.MaxUploadSize = p.maxUploadSize
+			serverOpts.AllowedURLPrefixes = p.allowedURLPrefixes
+
+			srv := &http.Server{
+				Addr:              p.addr,
+				Handler:           scan.NewServer(scanner, serverOpts),
+				ReadHeaderTimeout: 10 * time.Second,
+				BaseContext:       func(_ net.Listener) context.Context { return ctx },
+			}
+
+			var grpcSrv *grpc.Server
+			if p.grpcAddr != "" {
+				lis, err := net.Listen("tcp", p.grpcAddr)
+				if err != nil {
+					return fmt.Errorf("failed to listen for gRPC requests: %w", err)
+				}
+
+				grpcOpts := scangrpc.DefaultOptions
+				grpcOpts.DistroID = p.distro
+				grpcOpts.MaxAPKSize = p.maxUploadSize
+				grpcOpts.AllowedURLPrefixes = p.allowedURLPrefixes
+
+				// Leave some room beyond the APK itself for the rest of the request.
+				grpcSrv = grpc.NewServer(grpc.MaxRecvMsgSize(int(p.maxUploadSize) + 1<<20))
+				scanv1.RegisterScannerServer(grpcSrv, scangrpc.NewServer(scanner, grpcOpts))
+
+				go func() {
+					logger.Info("scan gRPC server listening", "addr", p.grpcAddr)
+					if err := grpcSrv.Serve(lis); err != nil {
+						logger.Error("scan gRPC server failed", "error", err)
+					}
+				}()
+			}
+
+			go func() {
+				<-ctx.Done()
+
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+
+				if grpcSrv != nil {
+					grpcSrv.GracefulStop()
+				}
+				if err := srv.Shutdown(shutdownCtx); err != nil {
+					logger.Warn("failed to shut down server gracefully", "error", err)
+				}
+			}()
+
+			logger.Info("scan server listening", "addr", p.addr, "dbBuilt", scanner.DataSource().Date)
+
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("scan server failed: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+type scanServeParams struct {
+	addr               string
+	grpcAddr           string
+	distro             string
+	localDBFilePath    string
+	grypeDBURL         string
+	maxDBAge           time.Duration
+	dbAgePolicy        string
+	disableSBOMCache   bool
+	useCPEMatching     bool
+	offline            bool
+	matchers           []string
+	disabledMatchers   []string
+	cpeOverridesPath   string
+	jobs               int
+	maxUploadSize      int64
+	allowedURLPrefixes []string
+}
+
+func (p *scanServeParams) addFlagsTo(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.addr, "addr", "localhost:8080", "address on which to listen for HTTP requests")
+	cmd.Flags().StringVar(&p.grpcAddr, "grpc-addr", "", "address on which to listen for gRPC requests (if empty, the gRPC API isn't served)")
+	cmd.Flags().StringVar(&p.distro, "distro", "wolfi", "default distro to use during vulnerability matching")
+	cmd.Flags().StringVar(&p.localDBFilePath, "local-file-grype-db", "", "import a local grype db file")
+	addGrypeDBURLFlag(&p.grypeDBURL, cmd)
+	addDBAgeFlags(&p.maxDBAge, &p.dbAgePolicy, cmd)
+	cmd.Flags().BoolVarP(&p.disableSBOMCache, "disable-sbom-cache", "D", false, "don't use the SBOM cache")
+	cmd.Flags().BoolVar(&p.useCPEMatching, "use-cpes", false, "turn on all CPE matching in Grype")
+	cmd.Flags().StringSliceVar(&p.matchers, "matchers", nil, fmt.Sprintf("only use the given Grype matchers (%s)", strings.Join(scan.ValidMatchers, "|")))
+	cmd.Flags().StringSliceVar(&p.disabledMatchers, "disable-matchers", nil, fmt.Sprintf("don't use the given Grype matchers (%s)", strings.Join(scan.ValidMatchers, "|")))
+	addCPEOverridesFlag(&p.cpeOverridesPath, cmd)
+	cmd.Flags().BoolVar(&p.offline, "offline", false, "don't access the network to update the vulnerability database")
+	cmd.Flags().IntVarP(&p.jobs, "jobs", "j", scan.DefaultServerOptions.MaxConcurrentScans, "maximum number of scans to run concurrently")
+	cmd.Flags().Int64Var(&p.maxUploadSize, "max-apk-size", scan.DefaultServerOptions.MaxUploadSize, "maximum size of an APK to accept, in bytes")
+	cmd.Flags().StringSliceVar(&p.allowedURLPrefixes, "allowed-url-prefix", remoteAPKRepositoryURLs, "URL prefixes (e.g. of APK repositories) from which APKs can be downloaded, when specified by URL")
+}
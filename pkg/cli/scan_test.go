@@ -0,0 +1,316 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+)
+
+// serveTestAPKRepositories serves APK repositories with the given packages (by
+// repository name and arch), and makes them the repositories that remote scans
+// search. Each served APK file's content is its path on the server.
+func serveTestAPKRepositories(t *testing.T, repos map[string]map[string][]*apk.Package) {
+	t.Helper()
+
+	files := make(map[string][]byte)
+	for repo, byArch := range repos {
+		for arch, pkgs := range byArch {
+			archive, err := apk.ArchiveFromIndex(&apk.APKIndex{Packages: pkgs})
+			require.NoError(t, err)
+			b, err := io.ReadAll(archive)
+			require.NoError(t, err)
+			files[path.Join("/", repo, arch, "APKINDEX.tar.gz")] = b
+
+			for _, pkg := range pkgs {
+				p := path.Join("/", repo, arch, pkg.Filename())
+				files[p] = []byte(p)
+			}
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(b)
+	}))
+	t.Cleanup(srv.Close)
+
+	original := remoteAPKRepositoryURLs
+	t.Cleanup(func() { remoteAPKRepositoryURLs = original })
+	remoteAPKRepositoryURLs = nil
+	for repo := range repos {
+		remoteAPKRepositoryURLs = append(remoteAPKRepositoryURLs, srv.URL+"/"+repo)
+	}
+}
+
+// readDownloadedAPKs returns the contents of the downloaded APK files, i.e.
+// their paths on the test server.
+func readDownloadedAPKs(t *testing.T, paths []string) []string {
+	t.Helper()
+
+	var contents []string
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		require.NoError(t, err)
+		contents = append(contents, string(b))
+	}
+	return contents
+}
+
+func TestScanPackageFlagValidation(t *testing.T) {
+	cases := []struct {
+		name        string
+		args        []string
+		expectedErr string
+	}{
+		{
+			name:        "unsupported arch",
+			args:        []string{"--package", "foo", "--arch", "s390x"},
+			expectedErr: `invalid arch "s390x"`,
+		},
+		{
+			name:        "file targets with --package",
+			args:        []string{"foo.apk", "--package", "foo"},
+			expectedErr: "cannot mix file targets with --package",
+		},
+		{
+			name:        "--package with --build-log",
+			args:        []string{"--package", "foo", "--build-log"},
+			expectedErr: "cannot use --package with --build-log or --sbom",
+		},
+		{
+			name:        "--package in offline mode",
+			args:        []string{"--package", "foo", "--offline"},
+			expectedErr: "cannot scan packages from the package repository in offline mode",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := cmdScan()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			err := cmd.ExecuteContext(context.Background())
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestResolveInputsForRemoteTarget(t *testing.T) {
+	ctx := context.Background()
+
+	serveTestAPKRepositories(t, map[string]map[string][]*apk.Package{
+		"os": {
+			"x86_64": {
+				{Name: "foo", Version: "1.0.0-r0", Arch: "x86_64"},
+				{Name: "foo", Version: "1.1.0-r0", Arch: "x86_64"},
+				{Name: "foo-doc", Version: "1.1.0-r0", Arch: "x86_64"},
+			},
+			"aarch64": {
+				{Name: "foo", Version: "1.1.0-r0", Arch: "aarch64"},
+			},
+		},
+		"extra-packages": {
+			"x86_64": {
+				{Name: "foo", Version: "1.0.5-r3", Arch: "x86_64"},
+				{Name: "bar", Version: "2.0.0-r1", Arch: "x86_64"},
+			},
+			"aarch64": {
+				{Name: "bar", Version: "2.0.0-r2", Arch: "aarch64"},
+			},
+		},
+	})
+
+	t.Run("latest version in any repository, for each arch", func(t *testing.T) {
+		paths, cleanup, err := resolveInputsForRemoteTarget(ctx, []string{"foo", "bar"}, []string{"x86_64", "aarch64"})
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, cleanup()) })
+
+		assert.Equal(t, []string{
+			"/os/x86_64/foo-1.1.0-r0.apk",
+			"/os/aarch64/foo-1.1.0-r0.apk",
+			"/extra-packages/x86_64/bar-2.0.0-r1.apk",
+			"/extra-packages/aarch64/bar-2.0.0-r2.apk",
+		}, readDownloadedAPKs(t, paths))
+	})
+
+	t.Run("package missing for some arches", func(t *testing.T) {
+		paths, cleanup, err := resolveInputsForRemoteTarget(ctx, []string{"foo-doc"}, []string{"x86_64", "aarch64"})
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, cleanup()) })
+
+		assert.Equal(t, []string{"/os/x86_64/foo-doc-1.1.0-r0.apk"}, readDownloadedAPKs(t, paths))
+	})
+
+	t.Run("package missing for all arches", func(t *testing.T) {
+		_, _, err := resolveInputsForRemoteTarget(ctx, []string{"foo", "baz"}, []string{"x86_64", "aarch64"})
+		assert.ErrorContains(t, err, `no packages found with name "baz"`)
+	})
+
+	t.Run("only the requested arches", func(t *testing.T) {
+		paths, cleanup, err := resolveInputsForRemoteTarget(ctx, []string{"bar"}, []string{"aarch64"})
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, cleanup()) })
+
+		assert.Equal(t, []string{"/extra-packages/aarch64/bar-2.0.0-r2.apk"}, readDownloadedAPKs(t, paths))
+	})
+}
+
+func TestResolveInputsForRemoteTargetCleanup(t *testing.T) {
+	ctx := context.Background()
+
+	// The downloaded APKs are temp files.
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	assertNoDownloadedAPKs := func(t *testing.T) {
+		t.Helper()
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	}
+
+	t.Run("package missing for all arches", func(t *testing.T) {
+		serveTestAPKRepositories(t, map[string]map[string][]*apk.Package{
+			"os": {
+				"x86_64":  {{Name: "foo", Version: "1.0.0-r0", Arch: "x86_64"}},
+				"aarch64": {{Name: "foo", Version: "1.0.0-r0", Arch: "aarch64"}},
+			},
+		})
+
+		_, _, err := resolveInputsForRemoteTarget(ctx, []string{"foo", "baz"}, []string{"x86_64", "aarch64"})
+		assert.ErrorContains(t, err, `no packages found with name "baz"`)
+		assertNoDownloadedAPKs(t)
+	})
+
+	t.Run("download failure", func(t *testing.T) {
+		serveTestAPKRepositories(t, map[string]map[string][]*apk.Package{
+			"os": {
+				"x86_64": {{Name: "foo", Version: "1.0.0-r0", Arch: "x86_64"}},
+			},
+		})
+
+		// bar is in the index, but its APK can't be downloaded.
+		archive, err := apk.ArchiveFromIndex(&apk.APKIndex{Packages: []*apk.Package{{Name: "bar", Version: "2.0.0-r0", Arch: "x86_64"}}})
+		require.NoError(t, err)
+		index, err := io.ReadAll(archive)
+		require.NoError(t, err)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if path.Base(r.URL.Path) != "APKINDEX.tar.gz" {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write(index)
+		}))
+		t.Cleanup(srv.Close)
+		remoteAPKRepositoryURLs = append(remoteAPKRepositoryURLs, srv.URL+"/broken")
+
+		_, _, err = resolveInputsForRemoteTarget(ctx, []string{"foo", "bar"}, []string{"x86_64"})
+		assert.ErrorContains(t, err, "status: 503")
+		assertNoDownloadedAPKs(t)
+	})
+}
+
+func TestScanThresholdError(t *testing.T) {
+	inputs := []string{"foo.apk", "bar.apk"}
+	scans := []scan.Result{
+		{Findings: []scan.Finding{{Vulnerability: scan.Vulnerability{ID: "CVE-2024-1111", Severity: "Low"}}}},
+		{},
+	}
+
+	cases := []struct {
+		name                         string
+		params                       scanParams
+		inputPathsFailingRequireZero []string
+		expectedErr                  string
+	}{
+		{
+			name: "no thresholds",
+		},
+		{
+			name:                         "require zero",
+			params:                       scanParams{requireZeroFindings: true},
+			inputPathsFailingRequireZero: []string{"foo.apk"},
+			expectedErr:                  "vulnerabilities found in the following package(s):\nfoo.apk",
+		},
+		{
+			name:        "fail on severity",
+			params:      scanParams{failOnSeverity: "low"},
+			expectedErr: "vulnerabilities with severity \"low\" or higher found in the following package(s):\nfoo.apk",
+		},
+		{
+			name:   "below severity",
+			params: scanParams{failOnSeverity: "high"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.params.thresholdError(scans, inputs, tt.inputPathsFailingRequireZero)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var exitErr *ExitError
+			require.True(t, errors.As(err, &exitErr))
+			assert.Equal(t, exitCodeFindings, exitErr.Code)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestScanResultErrorTimedOut(t *testing.T) {
+	p := scanParams{
+		requireZeroFindings: true,
+		targetTimeout:       time.Minute,
+		timedOutInputs:      []string{"bar.apk"},
+	}
+
+	err := p.resultError([]scan.Result{{}}, []string{"foo.apk"}, []string{"foo.apk"})
+	require.Error(t, err)
+
+	// A scan error takes precedence over the findings of the completed scans.
+	var exitErr *ExitError
+	assert.False(t, errors.As(err, &exitErr))
+	assert.ErrorContains(t, err, "timed out after 1m0s:\nbar.apk")
+}
+
+func TestWithTargetTimeout(t *testing.T) {
+	var running sync.WaitGroup
+	release := make(chan struct{})
+	returned := make(chan struct{})
+
+	_, err := withTargetTimeout(context.Background(), 10*time.Millisecond, &running, func(context.Context) (int, error) {
+		// Ignore the context, like cataloging work that doesn't stop when it's canceled.
+		<-release
+		close(returned)
+		return 0, nil
+	})
+	assert.ErrorIs(t, err, errTargetTimedOut)
+
+	// The abandoned call is still tracked, so its resources aren't released early.
+	close(release)
+	running.Wait()
+	select {
+	case <-returned:
+	default:
+		t.Fatal("running.Wait returned before the timed-out call did")
+	}
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/components/scanfindings"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+	"golang.org/x/exp/slices"
+)
+
+func cmdScanTrends() *cobra.Command {
+	p := &scanTrendsParams{}
+	cmd := &cobra.Command{
+		Use:   "trends [package...]",
+		Short: "Report how packages' findings have changed across recorded scans",
+		Long: `Report how packages' vulnerability findings have changed across the scans
+recorded using "wolfictl scan --record-history".
+
+For each package (and architecture), the latest scan is compared with an
+earlier scan, and the command reports:
+
+- Introduced findings, which are in the latest scan but not the earlier one.
+
+- Resolved findings, which are in the earlier scan but not the latest one.
+
+- Long-standing findings, which have been in every scan for at least the
+  duration given by --long-standing.
+
+By default, the latest scan is compared with the scan before it. Use --since
+to compare with the latest scan from at least that long ago instead.
+
+Findings are matched across scans in the same way as in "wolfictl scan diff".
+If no packages are specified, all packages in the history are reported.
+`,
+		Example: `
+# Record scan results during a nightly job
+wolfictl scan --record-history /path/to/packages/*.apk
+
+# Report trends for all packages
+wolfictl scan trends
+
+# Report what changed for a package over the last week
+wolfictl scan trends crane --since 168h
+`,
+		Args:          cobra.ArbitraryArgs,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if p.outputFormat == "" {
+				p.outputFormat = outputFormatOutline
+			}
+
+			if !slices.Contains(validScanTrendsOutputFormats, p.outputFormat) {
+				return fmt.Errorf(
+					"invalid output format %q, must be one of [%s]",
+					p.outputFormat,
+					strings.Join(validScanTrendsOutputFormats, ", "),
+				)
+			}
+
+			history, err := scan.OpenHistory(ctx, p.historyDBPath)
+			if err != nil {
+				return err
+			}
+			defer history.Close()
+
+			pkgs, err := history.Packages(ctx)
+			if err != nil {
+				return err
+			}
+
+			opts := scan.TrendOptions{
+				Now:             time.Now(),
+				Since:           p.since,
+				LongStandingAge: p.longStandingAge,
+			}
+
+			trends := []scan.PackageTrend{}
+			for _, pkg := range pkgs {
+				if len(args) > 0 && !slices.Contains(args, pkg.Name) {
+					continue
+				}
+
+				runs, err := history.Runs(ctx, pkg)
+				if err != nil {
+					return err
+				}
+
+				trends = append(trends, scan.ComputeTrend(runs, opts))
+			}
+
+			if p.outputFormat == outputFormatJSON {
+				enc := json.NewEncoder(os.Stdout)
+				if err := enc.Encode(trends); err != nil {
+					return fmt.Errorf("failed to marshal scan trends to JSON: %w", err)
+				}
+				return nil
+			}
+
+			if len(trends) == 0 {
+				fmt.Println("No recorded scans found (use \"wolfictl scan --record-history\" to record scans)")
+				return nil
+			}
+
+			for i := range trends {
+				renderScanTrend(&trends[i])
+			}
+
+			return nil
+		},
+	}
+
+	p.addFlagsTo(cmd)
+	return cmd
+}
+
+var validScanTrendsOutputFormats = []string{outputFormatOutline, outputFormatJSON}
+
+type scanTrendsParams struct {
+	historyDBPath   string
+	outputFormat    string
+	since           time.Duration
+	longStandingAge time.Duration
+}
+
+func (p *scanTrendsParams) addFlagsTo(cmd *cobra.Command) {
+	addHistoryDBFlag(&p.historyDBPath, cmd)
+	cmd.Flags().StringVarP(&p.outputFormat, "output", "o", "", fmt.Sprintf("output format (%s), defaults to %s", strings.Join(validScanTrendsOutputFormats, "|"), outputFormatOutline))
+	cmd.Flags().DurationVar(&p.since, "since", 0, "compare the latest scan with the latest scan from at least this long ago (defaults to comparing with the previous scan)")
+	cmd.Flags().DurationVar(&p.longStandingAge, "long-standing", 30*24*time.Hour, "how long a finding must have been present to be reported as long-standing")
+}
+
+func addHistoryDBFlag(val *string, cmd *cobra.Command) {
+	cmd.Flags().StringVar(val, "history-db", "", "path to the scan history database (defaults to history.db in wolfictl's data directory)")
+}
+
+func renderScanTrend(trend *scan.PackageTrend) {
+	fmt.Printf(
+		"📦 %s (%s) %s, %d scan(s), latest on %s\n",
+		trend.Package.Name,
+		trend.Package.Arch,
+		trend.LatestVersion,
+		trend.Runs,
+		trend.LatestScan.Format("2006-01-02"),
+	)
+
+	if !trend.Baseline.IsZero() {
+		fmt.Printf("   compared with the scan on %s\n", trend.Baseline.Format("2006-01-02"))
+	}
+
+	sections := []struct {
+		heading  string
+		findings []scan.TrendFinding
+	}{
+		{"🆕 Introduced", trend.Introduced},
+		{"✅ Resolved", trend.Resolved},
+		{"⏳ Long-standing", trend.LongStanding},
+	}
+
+	for _, s := range sections {
+		fmt.Printf("\n%s (%d)\n", s.heading, len(s.findings))
+
+		if len(s.findings) > 0 {
+			fmt.Println(scanfindings.RenderTrendFindings(s.findings))
+		}
+	}
+
+	fmt.Println()
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+	"github.com/wolfi-dev/wolfictl/pkg/cli/components/scanfindings"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+)
+
+// validScanWatchOutputFormats are the output formats that can be used with
+// --watch. Both JSON formats print each result on its own line.
+var validScanWatchOutputFormats = []string{outputFormatOutline, outputFormatJSON, outputFormatNDJSON}
+
+// watchSettleDuration is how long an APK must go unchanged before it's
+// considered completely written and is scanned.
+const watchSettleDuration = 2 * time.Second
+
+// watch scans APKs as they're written to p.watchDir, until ctx is canceled.
+func (p *scanParams) watch(ctx context.Context, advGetter advisory.Getter, kevCatalog *scan.KEVCatalog) error {
+	logger := clog.FromContext(ctx)
+
+	scanner, err := scan.NewScanner(p.scannerOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create scanner: %w", err)
+	}
+	defer scanner.Close()
+
+	// running tracks scans that are still using the scanner after they timed out.
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	if p.outputFormat == outputFormatOutline {
+		fmt.Printf("👀 Watching %q for new APKs (press Ctrl+C to stop)\n", p.watchDir)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+
+	return scan.WatchAPKs(ctx, p.watchDir, watchSettleDuration, func(ctx context.Context, apkPath string) {
+		result, err := withTargetTimeout(ctx, p.targetTimeout, &running, func(ctx context.Context) (*scan.Result, error) {
+			return p.scanWatchedAPK(ctx, scanner, apkPath, advGetter, kevCatalog)
+		})
+		if err != nil {
+			// A failed scan shouldn't stop the watch, since later builds can still be
+			// scanned.
+			logger.Error("failed to scan APK", "path", apkPath, "error", err)
+			if p.outputFormat == outputFormatOutline {
+				fmt.Printf("❌ Failed to scan %q: %v\n", apkPath, err)
+			}
+			return
+		}
+
+		p.addToHistory(ctx, result)
+
+		switch p.outputFormat {
+		case outputFormatOutline:
+			fmt.Printf("🔎 Scanning %q\n", apkPath)
+
+			render, err := scanfindings.Render(result.Findings)
+			if err != nil {
+				logger.Error("failed to render findings", "path", apkPath, "error", err)
+				return
+			}
+			fmt.Println(render)
+
+			if suppressed := scanfindings.RenderSuppressed(result.Suppressed); suppressed != "" {
+				fmt.Println(suppressed)
+			}
+
+		case outputFormatJSON, outputFormatNDJSON:
+			if err := enc.Encode(result); err != nil {
+				logger.Error("failed to marshal scan result to JSON", "path", apkPath, "error", err)
+			}
+		}
+	})
+}
+
+func (p *scanParams) scanWatchedAPK(ctx context.Context, scanner *scan.Scanner, apkPath string, advGetter advisory.Getter, kevCatalog *scan.KEVCatalog) (*scan.Result, error) {
+	f, err := os.Open(apkPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open APK: %w", err)
+	}
+	defer f.Close()
+
+	apkSBOM, err := p.generateSBOM(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SBOM: %w", err)
+	}
+
+	result, err := p.doScanCommandForSingleInput(ctx, scanner, f, apkSBOM)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.analyzeReachability(ctx, apkPath, result); err != nil {
+		return nil, err
+	}
+
+	if err := p.enrichResult(ctx, result, advGetter, kevCatalog); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
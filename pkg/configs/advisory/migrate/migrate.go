@@ -0,0 +1,354 @@
+// Package migrate upgrades advisory documents from one schema version to a
+// later one.
+//
+// Each schema version bump is described by a Migration from the previous
+// version, with the Rules that transform documents of the previous version into
+// documents of the new version. The rules operate on documents' YAML ASTs, and
+// not on the v2.Document type, since the type only describes the latest schema
+// version. Migrations are applied in sequence, so a document is upgraded across
+// several schema versions one step at a time.
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/chainguard-dev/yam/pkg/yam/formatted"
+	"github.com/hashicorp/go-version"
+	"github.com/wolfi-dev/wolfictl/pkg/configs/rwfs"
+	"gopkg.in/yaml.v3"
+)
+
+// A Rule is one transform of a Migration.
+type Rule struct {
+	// Name identifies the rule in reports.
+	Name string
+
+	// Apply transforms the document, given as the root mapping node of its YAML
+	// AST, in place. It returns a description of each change it made, and no
+	// descriptions if the document didn't need changing.
+	Apply func(doc *yaml.Node) ([]string, error)
+}
+
+// A Migration upgrades documents from schema version From to schema version To
+// by applying its Rules in order.
+type Migration struct {
+	From, To string
+	Rules    []Rule
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%s -> %s", m.From, m.To)
+}
+
+// Migrations are the known migrations, in order. Each migration's From version
+// is the previous migration's To version, and the last migration's To version
+// is v2.SchemaVersion.
+//
+// When the schema version is bumped, add a migration here, with rules for any
+// changes to the document structure.
+var Migrations = []Migration{
+	{
+		// 2.0.1 added the "pending-upstream-fix" event type, which doesn't need
+		// changes to existing documents.
+		From: "2",
+		To:   "2.0.1",
+	},
+	{
+		// 2.0.2 added the "scan/v1" detection type, which doesn't need changes to
+		// existing documents.
+		From: "2.0.1",
+		To:   "2.0.2",
+	},
+}
+
+// Options configures Run.
+type Options struct {
+	// FS is the advisories repo whose documents are migrated.
+	FS rwfs.FS
+
+	// Migrations are the migrations to use. If nil, Migrations is used.
+	Migrations []Migration
+
+	// Target is the schema version to migrate documents to. If empty,
+	// v2.SchemaVersion is used. Documents already at or beyond the target
+	// version are left as they are.
+	Target string
+
+	// DryRun reports the changes the migrations would make, without writing
+	// them.
+	DryRun bool
+}
+
+// Change is a change made to a document by a migration rule.
+type Change struct {
+	Migration   string
+	Rule        string
+	Description string
+}
+
+// DocumentReport describes the migration of a document.
+type DocumentReport struct {
+	// Path is the document's path in the advisories repo.
+	Path string
+
+	// From and To are the schema versions of the document before and after the
+	// migration.
+	From, To string
+
+	// Changes are the changes made by the rules of the migrations. It can be
+	// empty even though the document's schema version changed.
+	Changes []Change
+}
+
+// Report describes the outcome of Run.
+type Report struct {
+	// Migrated are the reports of the documents that were migrated (or would be,
+	// for a dry run).
+	Migrated []DocumentReport
+
+	// Unchanged is the number of documents that were already at the target
+	// version.
+	Unchanged int
+}
+
+// Run migrates the advisory documents in opts.FS to the target schema version.
+//
+// Every document is migrated in memory before any document is written, so an
+// error in one document (e.g. a schema version with no migration path to the
+// target) means no documents are written. After the migrations, each document
+// must decode and validate as a v2.Document, to catch faulty rules.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	log := clog.FromContext(ctx)
+
+	migrations := opts.Migrations
+	if migrations == nil {
+		migrations = Migrations
+	}
+	target := opts.Target
+	if target == "" {
+		target = v2.SchemaVersion
+	}
+	targetVersion, err := version.NewVersion(target)
+	if err != nil {
+		return nil, fmt.Errorf("parsing target schema version %q: %w", target, err)
+	}
+
+	paths, err := documentPaths(opts.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	migrated := make(map[string]*yaml.Node)
+	var errs []error
+
+	for _, p := range paths {
+		root, docReport, err := migrateDocument(opts.FS, p, migrations, targetVersion)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p, err))
+			continue
+		}
+		if docReport == nil {
+			report.Unchanged++
+			continue
+		}
+
+		log.Debug("migrated document", "path", p, "from", docReport.From, "to", docReport.To, "changes", len(docReport.Changes))
+		report.Migrated = append(report.Migrated, *docReport)
+		migrated[p] = root
+	}
+
+	if len(errs) > 0 {
+		return report, errors.Join(errs...)
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	encodeOptions := readEncodeOptions(opts.FS)
+	for _, r := range report.Migrated {
+		if err := writeDocument(opts.FS, r.Path, migrated[r.Path], encodeOptions); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// migrateDocument migrates the document at path, returning its migrated YAML
+// AST and a report of the migration, or a nil report if the document doesn't
+// need migrating.
+func migrateDocument(fsys fs.FS, path string, migrations []Migration, target *version.Version) (*yaml.Node, *DocumentReport, error) {
+	b, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(yaml.Node)
+	if err := yaml.Unmarshal(b, root); err != nil {
+		return nil, nil, fmt.Errorf("parsing YAML: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, errors.New("document isn't a YAML mapping")
+	}
+	doc := root.Content[0]
+
+	schemaVersionNode := MapValue(doc, "schema-version")
+	if schemaVersionNode == nil {
+		return nil, nil, errors.New("document has no schema version")
+	}
+	from := schemaVersionNode.Value
+
+	current, err := version.NewVersion(from)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing schema version %q: %w", from, err)
+	}
+	if !current.LessThan(target) {
+		return nil, nil, nil
+	}
+
+	report := &DocumentReport{Path: path, From: from}
+	for current.LessThan(target) {
+		m, ok, err := migrationFrom(migrations, current)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			return nil, nil, fmt.Errorf("no migration from schema version %q", current.Original())
+		}
+
+		for _, rule := range m.Rules {
+			descriptions, err := rule.Apply(doc)
+			if err != nil {
+				return nil, nil, fmt.Errorf("migration %s: rule %q: %w", m, rule.Name, err)
+			}
+			for _, d := range descriptions {
+				report.Changes = append(report.Changes, Change{Migration: m.String(), Rule: rule.Name, Description: d})
+			}
+		}
+
+		current, err = version.NewVersion(m.To)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing schema version %q of migration %s: %w", m.To, m, err)
+		}
+		schemaVersionNode.Value = m.To
+		schemaVersionNode.Style = 0
+	}
+	report.To = schemaVersionNode.Value
+
+	// Make sure the migrated document is valid.
+	buf := new(bytes.Buffer)
+	if err := yaml.NewEncoder(buf).Encode(root); err != nil {
+		return nil, nil, fmt.Errorf("encoding migrated document: %w", err)
+	}
+	migratedDoc, err := v2.DecodeDocument(buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decoding migrated document: %w", err)
+	}
+	if err := migratedDoc.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("migrated document is invalid: %w", err)
+	}
+
+	return root, report, nil
+}
+
+func migrationFrom(migrations []Migration, v *version.Version) (Migration, bool, error) {
+	for _, m := range migrations {
+		from, err := version.NewVersion(m.From)
+		if err != nil {
+			return Migration{}, false, fmt.Errorf("parsing schema version %q of migration %s: %w", m.From, m, err)
+		}
+		if from.Equal(v) {
+			return m, true, nil
+		}
+	}
+
+	return Migration{}, false, nil
+}
+
+// documentPaths returns the paths of the advisory documents in the advisories
+// repo, which are the same files that are indexed by adv2.NewIndex.
+func documentPaths(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading advisories repo: %w", err)
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") || !strings.HasSuffix(e.Name(), ".yaml") {
+			continue
+		}
+		paths = append(paths, e.Name())
+	}
+
+	return paths, nil
+}
+
+// readEncodeOptions returns the formatting options from the `.yam.yaml` file at
+// the root of the advisories repo, or default options if there isn't one.
+func readEncodeOptions(fsys fs.FS) formatted.EncodeOptions {
+	opts := formatted.EncodeOptions{
+		Indent:         2,
+		GapExpressions: []string{".", ".advisories"},
+	}
+
+	f, err := fsys.Open(".yam.yaml")
+	if err != nil {
+		return opts
+	}
+	defer f.Close()
+
+	if cfg, err := formatted.ReadConfigFrom(f); err == nil {
+		opts = *cfg
+	}
+
+	return opts
+}
+
+func writeDocument(fsys rwfs.FS, path string, root *yaml.Node, opts formatted.EncodeOptions) error {
+	buf := new(bytes.Buffer)
+	enc, err := formatted.NewEncoder(buf).UseOptions(opts)
+	if err != nil {
+		return fmt.Errorf("using yam options: %w", err)
+	}
+	if err := enc.Encode(root); err != nil {
+		return fmt.Errorf("encoding %q: %w", path, err)
+	}
+
+	if err := fsys.Truncate(path, 0); err != nil {
+		return fmt.Errorf("truncating %q: %w", path, err)
+	}
+	f, err := fsys.OpenAsWritable(path)
+	if err != nil {
+		return fmt.Errorf("opening %q as writeable: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, buf); err != nil {
+		return fmt.Errorf("writing %q: %w", path, err)
+	}
+
+	return f.Close()
+}
+
+// MapValue returns the value node of the key in the YAML mapping node m, or nil
+// if m doesn't have the key. It's a helper for writing rules.
+func MapValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+
+	return nil
+}
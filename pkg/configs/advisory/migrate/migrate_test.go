@@ -0,0 +1,156 @@
+package migrate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"gopkg.in/yaml.v3"
+)
+
+const testDocument = `schema-version: "2"
+
+package:
+  name: ko
+
+advisories:
+  - id: CGA-2222-2222-2222
+    aliases:
+      - CVE-2024-2222
+    events:
+      - timestamp: 2024-05-06T07:08:09Z
+        type: fixed
+        data:
+          fixed-version: 0.15.2-r1 # the first fixed version
+`
+
+func writeTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestMigrations(t *testing.T) {
+	// The known migrations must form a chain to the latest schema version.
+	require.NotEmpty(t, Migrations)
+	for i := 1; i < len(Migrations); i++ {
+		assert.Equal(t, Migrations[i-1].To, Migrations[i].From)
+	}
+
+	last, err := version.NewVersion(Migrations[len(Migrations)-1].To)
+	require.NoError(t, err)
+	latest, err := version.NewVersion(v2.SchemaVersion)
+	require.NoError(t, err)
+	assert.True(t, last.Equal(latest))
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	upperCaseNotes := Rule{
+		Name: "upper-case-package-name",
+		Apply: func(doc *yaml.Node) ([]string, error) {
+			name := MapValue(MapValue(doc, "package"), "name")
+			if name.Value == "KO" {
+				return nil, nil
+			}
+			name.Value = "KO"
+			return []string{"upper-cased package name"}, nil
+		},
+	}
+	migrations := []Migration{
+		{From: "2", To: "2.0.1", Rules: []Rule{upperCaseNotes}},
+		{From: "2.0.1", To: "2.0.2"},
+	}
+
+	t.Run("migrates documents", func(t *testing.T) {
+		dir := writeTestRepo(t, map[string]string{
+			"ko.advisories.yaml":    testDocument,
+			"other.advisories.yaml": "schema-version: 2.0.2\n\npackage:\n  name: other\n\nadvisories: []\n",
+			".yam.yaml":             "indent: 2\n",
+		})
+
+		report, err := Run(ctx, Options{FS: rwos.DirFS(dir), Migrations: migrations})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Unchanged)
+		assert.Equal(t, []DocumentReport{{
+			Path: "ko.advisories.yaml",
+			From: "2",
+			To:   "2.0.2",
+			Changes: []Change{
+				{Migration: "2 -> 2.0.1", Rule: "upper-case-package-name", Description: "upper-cased package name"},
+			},
+		}}, report.Migrated)
+
+		b, err := os.ReadFile(filepath.Join(dir, "ko.advisories.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(b), "schema-version: 2.0.2")
+		assert.Contains(t, string(b), "name: KO")
+		assert.Contains(t, string(b), "# the first fixed version")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		dir := writeTestRepo(t, map[string]string{"ko.advisories.yaml": testDocument})
+
+		report, err := Run(ctx, Options{FS: rwos.DirFS(dir), Migrations: migrations, DryRun: true})
+		require.NoError(t, err)
+		require.Len(t, report.Migrated, 1)
+		assert.Len(t, report.Migrated[0].Changes, 1)
+
+		b, err := os.ReadFile(filepath.Join(dir, "ko.advisories.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, testDocument, string(b))
+	})
+
+	t.Run("target version", func(t *testing.T) {
+		dir := writeTestRepo(t, map[string]string{"ko.advisories.yaml": testDocument})
+
+		report, err := Run(ctx, Options{FS: rwos.DirFS(dir), Migrations: migrations, Target: "2.0.1"})
+		require.NoError(t, err)
+		require.Len(t, report.Migrated, 1)
+		assert.Equal(t, "2.0.1", report.Migrated[0].To)
+	})
+
+	t.Run("no migration path", func(t *testing.T) {
+		dir := writeTestRepo(t, map[string]string{
+			"ko.advisories.yaml":  testDocument,
+			"old.advisories.yaml": "schema-version: \"1.9\"\n\npackage:\n  name: old\n",
+		})
+
+		_, err := Run(ctx, Options{FS: rwos.DirFS(dir), Migrations: migrations})
+		assert.ErrorContains(t, err, `old.advisories.yaml: no migration from schema version "1.9"`)
+
+		// No documents are written if any document can't be migrated.
+		b, err := os.ReadFile(filepath.Join(dir, "ko.advisories.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, testDocument, string(b))
+	})
+
+	t.Run("invalid result", func(t *testing.T) {
+		dir := writeTestRepo(t, map[string]string{"ko.advisories.yaml": testDocument})
+
+		dropPackageName := Rule{
+			Name: "drop-package-name",
+			Apply: func(doc *yaml.Node) ([]string, error) {
+				MapValue(MapValue(doc, "package"), "name").Value = ""
+				return []string{"dropped package name"}, nil
+			},
+		}
+
+		_, err := Run(ctx, Options{FS: rwos.DirFS(dir), Migrations: []Migration{
+			{From: "2", To: "2.0.2", Rules: []Rule{dropPackageName}},
+		}})
+		assert.ErrorContains(t, err, "migrated document is invalid")
+	})
+}
@@ -13,6 +13,29 @@ func NewIndex(ctx context.Context, fsys rwfs.FS) (*configs.Index[v2.Document], e
 	return configs.NewIndex[v2.Document](ctx, fsys, newConfigurationDecodeFunc(fsys))
 }
 
+// NewIndexForPackages returns a new Index of the advisory documents of the
+// given packages only, which is much faster than NewIndex for a large advisories
+// repo. If no packages are given, all advisory documents are indexed, as with
+// NewIndex.
+//
+// The documents are found by their file names, "<package>.advisories.yaml",
+// which is how documents are named when they're created.
+func NewIndexForPackages(ctx context.Context, fsys rwfs.FS, packages ...string) (*configs.Index[v2.Document], error) {
+	if len(packages) == 0 {
+		return NewIndex(ctx, fsys)
+	}
+
+	paths := make(map[string]struct{}, len(packages))
+	for _, pkg := range packages {
+		paths[pkg+".advisories.yaml"] = struct{}{}
+	}
+
+	return configs.NewIndexWhere[v2.Document](ctx, fsys, newConfigurationDecodeFunc(fsys), func(path string) bool {
+		_, ok := paths[path]
+		return ok
+	})
+}
+
 func NewIndexFromPaths(ctx context.Context, fsys rwfs.FS, paths ...string) (*configs.Index[v2.Document], error) {
 	return configs.NewIndexFromPaths[v2.Document](ctx, fsys, newConfigurationDecodeFunc(fsys), paths...)
 }
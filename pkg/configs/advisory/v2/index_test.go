@@ -0,0 +1,87 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+// writeAdvisoriesRepo writes an advisories repo with the given number of
+// packages, each with a few advisories, and returns its directory.
+func writeAdvisoriesRepo(t testing.TB, packages int) string {
+	dir := t.TempDir()
+
+	for i := range packages {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "schema-version: 2.0.2\n\npackage:\n  name: pkg-%d\n\nadvisories:\n", i)
+		for j := range 10 {
+			fmt.Fprintf(&sb, `  - id: CGA-%04d-%04d-2222
+    aliases:
+      - CVE-2024-%04d
+    events:
+      - timestamp: 2024-05-01T00:00:00Z
+        type: detection
+        data:
+          type: manual
+      - timestamp: 2024-05-02T00:00:00Z
+        type: fixed
+        data:
+          fixed-version: 1.2.%d-r0
+`, i, j, j, j)
+		}
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("pkg-%d.advisories.yaml", i)), []byte(sb.String()), 0o600))
+	}
+
+	return dir
+}
+
+func TestNewIndexForPackages(t *testing.T) {
+	ctx := context.Background()
+	fsys := rwos.DirFS(writeAdvisoriesRepo(t, 5))
+
+	index, err := NewIndexForPackages(ctx, fsys, "pkg-1", "pkg-3", "not-a-package")
+	require.NoError(t, err)
+
+	var names []string
+	for _, doc := range index.Select().Configurations() {
+		names = append(names, doc.Name())
+	}
+	assert.Equal(t, []string{"pkg-1", "pkg-3"}, names)
+
+	all, err := NewIndexForPackages(ctx, fsys)
+	require.NoError(t, err)
+	assert.Equal(t, 5, all.Select().Len())
+}
+
+// BenchmarkNewIndex and BenchmarkNewIndexForPackages compare indexing a whole
+// advisories repo to indexing the documents of a few of its packages.
+
+func BenchmarkNewIndex(b *testing.B) {
+	ctx := context.Background()
+	fsys := rwos.DirFS(writeAdvisoriesRepo(b, 1000))
+
+	for b.Loop() {
+		if _, err := NewIndex(ctx, fsys); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewIndexForPackages(b *testing.B) {
+	ctx := context.Background()
+	fsys := rwos.DirFS(writeAdvisoriesRepo(b, 1000))
+
+	for b.Loop() {
+		if _, err := NewIndexForPackages(ctx, fsys, "pkg-1", "pkg-500", "pkg-999"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
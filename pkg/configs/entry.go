@@ -2,6 +2,7 @@ package configs
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 
 	"gopkg.in/yaml.v3"
@@ -33,6 +34,9 @@ type entry[T Configuration] struct {
 	path     string
 	yamlRoot *yaml.Node
 	cfg      T
+
+	// digest is the SHA-256 digest of the configuration file when it was indexed.
+	digest [sha256.Size]byte
 }
 
 func (e entry[T]) getIndex() *Index[T] {
@@ -1,9 +1,12 @@
 package configs
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"path"
 	"strings"
@@ -30,6 +33,7 @@ type Index[T Configuration] struct {
 	paths         []string
 	yamlRoots     []*yaml.Node
 	cfgs          []T
+	digests       [][sha256.Size]byte
 	cfgDecodeFunc func(context.Context, string) (*T, error)
 	byID          map[string]int
 	byName        map[string]int
@@ -41,6 +45,15 @@ type Index[T Configuration] struct {
 // fs.FS, decode the file to type T, and return a reference the "type T" data,
 // or an error if there was a problem.
 func NewIndex[T Configuration](ctx context.Context, fsys rwfs.FS, cfgDecodeFunc func(context.Context, string) (*T, error)) (*Index[T], error) {
+	return NewIndexWhere(ctx, fsys, cfgDecodeFunc, nil)
+}
+
+// NewIndexWhere is like NewIndex, but it only decodes the configuration files
+// whose paths the include function returns true for, and the Index only has
+// their configurations. If include is nil, all configuration files are decoded.
+// Decoding is by far the most expensive part of creating an Index, so this is
+// much faster for large filesystems when only a few configurations are needed.
+func NewIndexWhere[T Configuration](ctx context.Context, fsys rwfs.FS, cfgDecodeFunc func(context.Context, string) (*T, error), include func(path string) bool) (*Index[T], error) {
 	if cfgDecodeFunc == nil {
 		return nil, errors.New("must supply a cfgDecodeFunc")
 	}
@@ -69,6 +82,10 @@ func NewIndex[T Configuration](ctx context.Context, fsys rwfs.FS, cfgDecodeFunc
 			return nil
 		}
 
+		if include != nil && !include(path) {
+			return nil
+		}
+
 		err = index.processAndAdd(ctx, path)
 		if err != nil {
 			return err
@@ -130,9 +147,27 @@ func (i *Index[T]) Select() Selection[T] {
 	}
 }
 
+// ErrConcurrentModification is returned when a configuration file is updated,
+// but it has changed since it was indexed, e.g. because another process updated
+// it. The update isn't applied, since it would overwrite the other changes.
+var ErrConcurrentModification = errors.New("configuration file was modified since it was indexed")
+
 // Create creates a new configuration file at the given path, with the given
-// cfg. The new configuration is automatically added to the Index.
+// cfg. The new configuration is automatically added to the Index. If the file
+// already exists (e.g. because another process created it after the Index was
+// created), an error wrapping fs.ErrExist is returned.
 func (i *Index[T]) Create(ctx context.Context, filepath string, cfg T) error {
+	unlock, err := rwfs.Lock(i.fsys, filepath)
+	if err != nil {
+		return err
+	}
+	defer unlock() //nolint:errcheck
+
+	if f, err := i.fsys.Open(filepath); err == nil {
+		f.Close()
+		return fmt.Errorf("creating file %q: %w", filepath, fs.ErrExist)
+	}
+
 	file, err := i.fsys.Create(filepath)
 	if err != nil {
 		return fmt.Errorf("creating file %q: %w", filepath, err)
@@ -196,8 +231,20 @@ func (i *Index[T]) format(filepath string) error {
 }
 
 // update updates the given entry in the index using the provided EntryUpdater.
+// The entry's file is locked while it's updated, and if the file has changed
+// since it was indexed, ErrConcurrentModification is returned.
 func (i *Index[T]) update(ctx context.Context, entry Entry[T], entryUpdater EntryUpdater[T]) error {
-	err := entryUpdater(i, entry)
+	unlock, err := rwfs.Lock(i.fsys, entry.Path())
+	if err != nil {
+		return err
+	}
+	defer unlock() //nolint:errcheck
+
+	if err := i.checkUnmodified(entry.Path()); err != nil {
+		return err
+	}
+
+	err = entryUpdater(i, entry)
 	if err != nil {
 		return err
 	}
@@ -211,6 +258,26 @@ func (i *Index[T]) update(ctx context.Context, entry Entry[T], entryUpdater Entr
 	return nil
 }
 
+// checkUnmodified returns ErrConcurrentModification if the file at the given
+// path has changed since it was indexed.
+func (i *Index[T]) checkUnmodified(filepath string) error {
+	idx, ok := i.byPath[filepath]
+	if !ok {
+		return fmt.Errorf("no index entry for %q", filepath)
+	}
+
+	b, err := fs.ReadFile(i.fsys, filepath)
+	if err != nil {
+		return fmt.Errorf("reading %q: %w", filepath, err)
+	}
+
+	if sha256.Sum256(b) != i.digests[idx] {
+		return fmt.Errorf("%q: %w", filepath, ErrConcurrentModification)
+	}
+
+	return nil
+}
+
 // processAndAdd decodes the configuration file at the given path into both a
 // YAML AST and a configuration (type T), and it then adds a new entry to the
 // Index.
@@ -228,12 +295,14 @@ func (i *Index[T]) processAndAdd(ctx context.Context, filepath string) error {
 }
 
 func (i *Index[T]) processAndUpdate(ctx context.Context, filepath string, entryIndex int) error {
-	entry, err := i.process(ctx, filepath)
+	// Format before processing, so that the entry's digest is of the file as it's
+	// left.
+	err := i.format(filepath)
 	if err != nil {
 		return err
 	}
 
-	err = i.format(filepath)
+	entry, err := i.process(ctx, filepath)
 	if err != nil {
 		return err
 	}
@@ -250,9 +319,14 @@ func (i *Index[T]) process(ctx context.Context, filepath string) (*entry[T], err
 	if err != nil {
 		return nil, fmt.Errorf("unable to open configuration at %q: %w", filepath, err)
 	}
+	b, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read configuration at %q: %w", filepath, err)
+	}
 
 	yamlRoot := &yaml.Node{}
-	err = yaml.NewDecoder(f).Decode(yamlRoot)
+	err = yaml.NewDecoder(bytes.NewReader(b)).Decode(yamlRoot)
 	if err != nil {
 		return nil, fmt.Errorf("unable to decode YAML at %q: %w", filepath, err)
 	}
@@ -267,6 +341,7 @@ func (i *Index[T]) process(ctx context.Context, filepath string) (*entry[T], err
 		path:     filepath,
 		yamlRoot: yamlRoot,
 		cfg:      *cfg,
+		digest:   sha256.Sum256(b),
 	}, nil
 }
 
@@ -285,6 +360,7 @@ func (i *Index[T]) add(e *entry[T]) error {
 	i.paths = append(i.paths, e.path)
 	i.yamlRoots = append(i.yamlRoots, e.yamlRoot)
 	i.cfgs = append(i.cfgs, e.cfg)
+	i.digests = append(i.digests, e.digest)
 
 	i.byID[e.id()] = nextIndex
 	i.byPath[e.path] = nextIndex
@@ -297,6 +373,7 @@ func (i *Index[T]) updateAtIndex(e *entry[T], entryIndex int) {
 	i.paths[entryIndex] = e.path
 	i.yamlRoots[entryIndex] = e.yamlRoot
 	i.cfgs[entryIndex] = e.cfg
+	i.digests[entryIndex] = e.digest
 	i.byID[e.id()] = entryIndex
 	i.byName[(*e.Configuration()).Name()] = entryIndex
 	i.byPath[e.Path()] = entryIndex
@@ -308,5 +385,6 @@ func (i *Index[T]) entry(idx int) Entry[T] {
 		path:     i.paths[idx],
 		yamlRoot: i.yamlRoots[idx],
 		cfg:      i.cfgs[idx],
+		digest:   i.digests[idx],
 	}
 }
@@ -2,12 +2,16 @@ package configs
 
 import (
 	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"chainguard.dev/melange/pkg/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"gopkg.in/yaml.v3"
 )
 
 func TestNewIndex(t *testing.T) {
@@ -32,3 +36,60 @@ func TestNewIndex(t *testing.T) {
 		assert.NotContains(t, index.paths, ".not-a-config.yaml")
 	})
 }
+
+type testConfig struct {
+	Package struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	} `yaml:"package"`
+}
+
+func (c testConfig) Name() string { return c.Package.Name }
+
+func TestIndexConcurrentModification(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("package:\n  name: a\n  version: 1.0.0\n"), 0o600))
+	fsys := rwos.DirFS(dir)
+
+	newIndex := func() *Index[testConfig] {
+		index, err := NewIndex[testConfig](ctx, fsys, func(_ context.Context, path string) (*testConfig, error) {
+			b, err := fs.ReadFile(fsys, path)
+			if err != nil {
+				return nil, err
+			}
+			cfg := new(testConfig)
+			return cfg, yaml.Unmarshal(b, cfg)
+		})
+		require.NoError(t, err)
+		return index
+	}
+	setVersion := func(version string) EntryUpdater[testConfig] {
+		return NewYAMLUpdateFunc(func(_ testConfig, root *yaml.Node) error {
+			return yamlNodeForKey(root, "package").Encode(map[string]string{"name": "a", "version": version})
+		})
+	}
+
+	first, second := newIndex(), newIndex()
+
+	// Consecutive updates through the same index don't conflict with each other.
+	require.NoError(t, first.Select().WhereName("a").Update(ctx, setVersion("1.1.0")))
+	require.NoError(t, first.Select().WhereName("a").Update(ctx, setVersion("1.2.0")))
+
+	err := second.Select().WhereName("a").Update(ctx, setVersion("2.0.0"))
+	assert.ErrorIs(t, err, ErrConcurrentModification)
+
+	b, err := os.ReadFile(filepath.Join(dir, "a.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "version: 1.2.0")
+
+	t.Run("create", func(t *testing.T) {
+		var cfg testConfig
+		cfg.Package.Name = "b"
+		require.NoError(t, first.Create(ctx, "b.yaml", cfg))
+
+		err := second.Create(ctx, "b.yaml", cfg)
+		assert.ErrorIs(t, err, fs.ErrExist)
+	})
+}
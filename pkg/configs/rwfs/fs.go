@@ -16,3 +16,21 @@ type File interface {
 	fs.File
 	io.Writer
 }
+
+// A Locker is an FS that can lock its files, so that processes writing to the
+// same files don't overwrite each other's changes. Locks are advisory: they only
+// exclude other callers of Lock.
+type Locker interface {
+	// Lock blocks until it holds an exclusive lock on the named file, and returns a
+	// function that releases the lock. If the file doesn't exist, the lock is on
+	// the creation of the file instead, i.e. on its directory.
+	Lock(name string) (unlock func() error, err error)
+}
+
+// Lock locks the named file if fsys is a Locker, and otherwise does nothing.
+func Lock(fsys FS, name string) (unlock func() error, err error) {
+	if l, ok := fsys.(Locker); ok {
+		return l.Lock(name)
+	}
+	return func() error { return nil }, nil
+}
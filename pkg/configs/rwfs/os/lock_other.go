@@ -0,0 +1,9 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package os
+
+// Lock doesn't lock anything on this platform, since flock(2) isn't available.
+// Concurrent changes are still detected when documents are updated by an Index.
+func (fsys FS) Lock(string) (func() error, error) {
+	return func() error { return nil }, nil
+}
@@ -0,0 +1,73 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package os
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Lock locks the named file with flock(2). The lock is released when the
+// process exits, even if it doesn't unlock it.
+//
+// A file that doesn't exist yet can't be locked, so creating it is locked by an
+// exclusive lock on its directory instead, held until the caller unlocks it.
+// Since the file exists as soon as it's created, but isn't written until later,
+// other callers hold a shared lock on the directory while they open the file,
+// so that they don't lock a file that's still being written.
+func (fsys FS) Lock(name string) (func() error, error) {
+	p := fsys.fullPath(name)
+
+	for {
+		dir, err := os.Open(filepath.Dir(p))
+		if err != nil {
+			return nil, fmt.Errorf("opening directory of %q to lock it: %w", name, err)
+		}
+		if err := flock(dir, syscall.LOCK_SH); err != nil {
+			dir.Close()
+			return nil, fmt.Errorf("locking directory of %q: %w", name, err)
+		}
+
+		f, err := os.Open(p)
+		if err == nil {
+			dir.Close()
+			if err := flock(f, syscall.LOCK_EX); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("locking %q: %w", name, err)
+			}
+			// Closing the file releases the lock.
+			return f.Close, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			dir.Close()
+			return nil, fmt.Errorf("opening %q to lock it: %w", name, err)
+		}
+
+		if err := flock(dir, syscall.LOCK_EX); err != nil {
+			dir.Close()
+			return nil, fmt.Errorf("locking directory of %q: %w", name, err)
+		}
+
+		// Upgrading the lock isn't atomic, so the file might have been created in
+		// the meantime.
+		if _, err := os.Stat(p); err == nil {
+			dir.Close()
+			continue
+		}
+
+		return dir.Close, nil
+	}
+}
+
+func flock(f *os.File, how int) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), how)
+		if !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+	}
+}
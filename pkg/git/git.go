@@ -1,11 +1,14 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -237,6 +240,154 @@ func TempClone(gitURL, hash string, useAuth bool) (repoDir string, err error) {
 	return dir, nil
 }
 
+// TempExport writes the files of the local repo at repoDir, as of the given
+// revision (e.g. a branch, tag, or commit hash), to a new temp directory, and
+// returns the path to the directory. The directory isn't a git repo.
+//
+// The caller is responsible for cleaning up the temp directory.
+func TempExport(repoDir, rev string) (dir string, err error) {
+	dir, err = os.MkdirTemp("", "wolfictl-git-export-*")
+	if err != nil {
+		return dir, fmt.Errorf("unable to create temp directory for git export: %w", err)
+	}
+
+	repo, err := git.PlainOpenWithOptions(repoDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return dir, fmt.Errorf("unable to open git repo %q: %w", repoDir, err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return dir, fmt.Errorf("unable to resolve revision %q in repo %q: %w", rev, repoDir, err)
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return dir, fmt.Errorf("unable to get commit %s: %w", hash, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return dir, fmt.Errorf("unable to get tree of commit %s: %w", hash, err)
+	}
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if !f.Mode.IsFile() {
+			return nil
+		}
+
+		p := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+
+		r, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		out, err := os.Create(p)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		_, err = io.Copy(out, r)
+		return err
+	})
+	if err != nil {
+		return dir, fmt.Errorf("unable to export files of revision %q: %w", rev, err)
+	}
+
+	return dir, nil
+}
+
+// CommitFiles stages the given files (relative to dir, which can be anywhere in
+// a repo's worktree) and commits them with the given message. The commit author
+// is taken from the GIT_AUTHOR_NAME and GIT_AUTHOR_EMAIL environment variables
+// if they're set, and from the git config otherwise.
+func CommitFiles(dir, message string, paths ...string) error {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return fmt.Errorf("unable to open git repo %q: %w", dir, err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("unable to get worktree for repo %q: %w", dir, err)
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	absDir, err = filepath.EvalSymlinks(absDir)
+	if err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(wt.Filesystem.Root())
+	if err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		rel, err := filepath.Rel(root, filepath.Join(absDir, p))
+		if err != nil {
+			return err
+		}
+		if _, err := wt.Add(filepath.ToSlash(rel)); err != nil {
+			return fmt.Errorf("unable to stage %q: %w", p, err)
+		}
+	}
+
+	_, err = wt.Commit(message, &git.CommitOptions{Author: GetGitAuthorSignature()})
+	if err != nil {
+		return fmt.Errorf("unable to commit: %w", err)
+	}
+
+	return nil
+}
+
+// Pull fetches the current branch's upstream changes from the "origin" remote
+// and merges them into the worktree of the repo that contains dir, which must
+// fast-forward. It returns true if the worktree was updated, and false if it was
+// already up to date.
+//
+// For github.com remotes, a personal access token is read in from the
+// GITHUB_TOKEN environment variable, if it's set.
+func Pull(dir string) (bool, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return false, fmt.Errorf("unable to open git repo %q: %w", dir, err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("unable to get worktree for repo %q: %w", dir, err)
+	}
+
+	opts := &git.PullOptions{RemoteName: "origin"}
+
+	// Remotes that aren't recognized as hosted git URLs (e.g. local paths) are
+	// pulled without authentication.
+	if remoteURL, err := GetRemoteURL(repo); err == nil && remoteURL.Host == "github.com" {
+		if auth, err := GetGitAuth(remoteURL.RawURL); err == nil && auth != nil {
+			opts.Auth = auth
+		}
+	}
+
+	err = wt.Pull(opts)
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to pull repo %q: %w", dir, err)
+	}
+
+	return true, nil
+}
+
 // FindForkPoint finds the fork point between the local branch and the upstream
 // branch.
 //
@@ -1,11 +1,19 @@
 package git
 
 import (
+	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	gitHttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseGitURL(t *testing.T) {
@@ -139,3 +147,189 @@ func TestGetGitAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestTempExport(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	commit := func(file, contents string) {
+		t.Helper()
+		p := filepath.Join(repoDir, filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(contents), 0o600))
+		_, err := wt.Add(file)
+		require.NoError(t, err)
+		_, err = wt.Commit("update "+file, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+	}
+
+	commit("a.yaml", "first")
+	commit("dir/b.yaml", "second")
+	commit("a.yaml", "third")
+
+	// The working tree isn't exported.
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "a.yaml"), []byte("uncommitted"), 0o600))
+
+	cases := []struct {
+		rev      string
+		expected map[string]string
+	}{
+		{
+			rev:      "HEAD",
+			expected: map[string]string{"a.yaml": "third", "dir/b.yaml": "second"},
+		},
+		{
+			rev:      "HEAD~2",
+			expected: map[string]string{"a.yaml": "first"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.rev, func(t *testing.T) {
+			dir, err := TempExport(repoDir, tt.rev)
+			t.Cleanup(func() { os.RemoveAll(dir) })
+			require.NoError(t, err)
+
+			actual := make(map[string]string)
+			require.NoError(t, filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				b, err := os.ReadFile(p)
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(dir, p)
+				if err != nil {
+					return err
+				}
+				actual[filepath.ToSlash(rel)] = string(b)
+				return nil
+			}))
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+
+	t.Run("unknown revision", func(t *testing.T) {
+		dir, err := TempExport(repoDir, "nope")
+		t.Cleanup(func() { os.RemoveAll(dir) })
+		assert.Error(t, err)
+	})
+}
+
+func TestCommitFiles(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+
+	// The files are given relative to a subdirectory of the worktree.
+	subDir := filepath.Join(repoDir, "advisories")
+	require.NoError(t, os.MkdirAll(subDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(subDir, "foo.advisories.yaml"), []byte("foo"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(subDir, "bar.advisories.yaml"), []byte("bar"), 0o600))
+
+	require.NoError(t, CommitFiles(subDir, "foo: create advisory", "foo.advisories.yaml"))
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	c, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "foo: create advisory", c.Message)
+	assert.Equal(t, "test", c.Author.Name)
+
+	_, err = c.File("advisories/foo.advisories.yaml")
+	assert.NoError(t, err)
+	_, err = c.File("advisories/bar.advisories.yaml")
+	assert.ErrorIs(t, err, object.ErrFileNotFound)
+}
+
+func TestPull(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+
+	originDir := t.TempDir()
+	_, err := git.PlainInit(originDir, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(originDir, "foo.advisories.yaml"), []byte("foo"), 0o600))
+	require.NoError(t, CommitFiles(originDir, "foo: create advisory", "foo.advisories.yaml"))
+
+	cloneDir := t.TempDir()
+	_, err = git.PlainClone(cloneDir, false, &git.CloneOptions{URL: originDir})
+	require.NoError(t, err)
+
+	updated, err := Pull(cloneDir)
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	require.NoError(t, os.WriteFile(filepath.Join(originDir, "bar.advisories.yaml"), []byte("bar"), 0o600))
+	require.NoError(t, CommitFiles(originDir, "bar: create advisory", "bar.advisories.yaml"))
+
+	updated, err = Pull(cloneDir)
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.FileExists(t, filepath.Join(cloneDir, "bar.advisories.yaml"))
+}
+
+func TestRevisionFS(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	start := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	commit := func(file, contents string, day int) {
+		t.Helper()
+		p := filepath.Join(repoDir, filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(contents), 0o600))
+		_, err := wt.Add(file)
+		require.NoError(t, err)
+		sig := &object.Signature{Name: "test", Email: "test@example.com", When: start.AddDate(0, 0, day)}
+		_, err = wt.Commit("update "+file, &git.CommitOptions{Author: sig, Committer: sig})
+		require.NoError(t, err)
+	}
+
+	commit("a.yaml", "first", 0)
+	commit("dir/b.yaml", "second", 1)
+	commit("a.yaml", "third", 2)
+
+	// The working tree isn't read.
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "a.yaml"), []byte("uncommitted"), 0o600))
+
+	t.Run("revision", func(t *testing.T) {
+		fsys, hash, err := RevisionFS(repoDir, "HEAD~1")
+		require.NoError(t, err)
+		assert.Len(t, hash, 40)
+		require.NoError(t, fstest.TestFS(fsys, "a.yaml", "dir/b.yaml"))
+
+		b, err := fs.ReadFile(fsys, "a.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "first", string(b))
+
+		_, err = fs.ReadFile(fsys, "nope.yaml")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("as of", func(t *testing.T) {
+		hash, err := RevisionAsOf(repoDir, "HEAD", start.AddDate(0, 0, 1).Add(time.Hour))
+		require.NoError(t, err)
+
+		fsys, _, err := RevisionFS(repoDir, hash)
+		require.NoError(t, err)
+		b, err := fs.ReadFile(fsys, "a.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "first", string(b))
+
+		_, err = RevisionAsOf(repoDir, "HEAD", start.Add(-time.Hour))
+		assert.ErrorContains(t, err, "no commit of revision")
+	})
+}
@@ -0,0 +1,225 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RevisionFS returns a read-only fs.FS of the files of the local repo at
+// repoDir as of the given revision (e.g. a branch, tag, or commit hash), and the
+// hash of the revision's commit. Unlike TempExport, the files are read from the
+// repo's object database on demand, so nothing is written to disk.
+func RevisionFS(repoDir, rev string) (fs.FS, string, error) {
+	repo, err := git.PlainOpenWithOptions(repoDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to open git repo %q: %w", repoDir, err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to resolve revision %q in repo %q: %w", rev, repoDir, err)
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to get commit %s: %w", hash, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to get tree of commit %s: %w", hash, err)
+	}
+
+	return treeFS{tree: tree, modTime: commit.Committer.When}, hash.String(), nil
+}
+
+// RevisionAsOf returns the hash of the commit that the given revision of the
+// local repo at repoDir pointed to at time t, i.e. the latest commit in the
+// revision's first-parent history that was committed at or before t.
+func RevisionAsOf(repoDir, rev string, t time.Time) (string, error) {
+	repo, err := git.PlainOpenWithOptions(repoDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("unable to open git repo %q: %w", repoDir, err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve revision %q in repo %q: %w", rev, repoDir, err)
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return "", fmt.Errorf("unable to get commit %s: %w", hash, err)
+	}
+
+	for commit.Committer.When.After(t) {
+		if commit.NumParents() == 0 {
+			return "", fmt.Errorf("no commit of revision %q in repo %q is at or before %s", rev, repoDir, t.Format(time.RFC3339))
+		}
+
+		commit, err = commit.Parent(0)
+		if err != nil {
+			return "", fmt.Errorf("unable to get parent of commit %s: %w", commit.Hash, err)
+		}
+	}
+
+	return commit.Hash.String(), nil
+}
+
+// treeFS is an fs.FS of the files of a git tree.
+type treeFS struct {
+	tree    *object.Tree
+	modTime time.Time
+}
+
+func (t treeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return t.openDir(name)
+	}
+
+	entry, err := t.tree.FindEntry(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if entry.Mode == filemode.Dir {
+		return t.openDir(name)
+	}
+
+	file, err := t.tree.TreeEntryFile(entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &treeFile{
+		Reader: bytes.NewReader([]byte(contents)),
+		info:   t.info(name, file.Size, t.mode(entry.Mode)),
+	}, nil
+}
+
+func (t treeFS) openDir(name string) (fs.File, error) {
+	entries, err := t.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &treeDir{info: t.info(name, 0, fs.ModeDir|0o755), entries: entries}, nil
+}
+
+func (t treeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	tree := t.tree
+	if name != "." {
+		sub, err := t.tree.Tree(name)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+		}
+		tree = sub
+	}
+
+	entries := make([]fs.DirEntry, 0, len(tree.Entries))
+	for _, e := range tree.Entries {
+		var size int64
+		if e.Mode != filemode.Dir {
+			if f, err := tree.TreeEntryFile(&e); err == nil {
+				size = f.Size
+			}
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(t.info(e.Name, size, t.mode(e.Mode))))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+func (t treeFS) info(name string, size int64, mode fs.FileMode) fileInfo {
+	return fileInfo{name: path.Base(name), size: size, mode: mode, modTime: t.modTime}
+}
+
+func (treeFS) mode(m filemode.FileMode) fs.FileMode {
+	switch m {
+	case filemode.Dir:
+		return fs.ModeDir | 0o755
+	case filemode.Symlink:
+		return fs.ModeSymlink | 0o777
+	case filemode.Executable:
+		return 0o755
+	case filemode.Submodule:
+		return fs.ModeIrregular
+	}
+
+	return 0o644
+}
+
+type treeFile struct {
+	*bytes.Reader
+	info fileInfo
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *treeFile) Close() error               { return nil }
+
+type treeDir struct {
+	info    fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *treeDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *treeDir) Close() error               { return nil }
+func (d *treeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *treeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) Mode() fs.FileMode  { return i.mode }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i fileInfo) Sys() any           { return nil }
@@ -0,0 +1,165 @@
+// Package advisories lints the formatting and structure of advisory documents,
+// so that issues that the advisory schema allows but that reviewers would flag
+// are caught locally.
+package advisories
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/lint"
+	"golang.org/x/exp/slices"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+const advisoriesFileSuffix = ".advisories.yaml"
+
+// Linter represents an advisory document linter instance.
+type Linter struct {
+	// options are the options to configure the linter.
+	options lint.Options
+}
+
+// New initializes a new instance of Linter. The path option can be an advisory
+// document or a directory of advisory documents.
+func New(opts ...lint.Option) *Linter {
+	o := lint.Options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Linter{options: o}
+}
+
+// Lint evaluates all rules against the advisory documents and returns the
+// result.
+func (l *Linter) Lint(ctx context.Context, minSeverity lint.Severity) (lint.Result, error) {
+	log := clog.FromContext(ctx)
+
+	paths, err := l.paths()
+	if err != nil {
+		return lint.Result{}, err
+	}
+
+	results := make(lint.Result, 0)
+
+	for _, p := range paths {
+		f, err := readFile(p)
+		if err != nil {
+			return lint.Result{}, err
+		}
+
+		failedRules := make(lint.EvalRuleErrors, 0)
+		for _, rule := range AllRules {
+			// Allow users to override rules when running lint command
+			if slices.Contains(l.options.SkipRules, rule.Name) {
+				log.Debugf("%s: skipping rule %s because --skip-rule flag set\n", p, rule.Name)
+				continue
+			}
+
+			if err := rule.LintFunc(f); err != nil {
+				// Only add to failedRules if the severity is inclusive of the minSeverity
+				if rule.Severity.Value <= minSeverity.Value {
+					msg := fmt.Sprintf("[%s]: %s (%s)", rule.Name, err.Error(), rule.Severity.Name)
+
+					failedRules = append(failedRules, lint.EvalRuleError{
+						Rule: lint.Rule{
+							Name:        rule.Name,
+							Description: rule.Description,
+							Severity:    rule.Severity,
+						},
+						Error: fmt.Errorf("%s", msg),
+					})
+				}
+			}
+		}
+
+		// If we have errors we append them to the result.
+		if failedRules.WrapErrors() != nil {
+			results = append(results, lint.EvalResult{
+				File:   strings.TrimSuffix(filepath.Base(p), advisoriesFileSuffix),
+				Errors: failedRules,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// Print prints the result to the logger.
+func (l *Linter) Print(ctx context.Context, result lint.Result) {
+	log := clog.FromContext(ctx)
+	foundAny := false
+	for _, res := range result {
+		if res.Errors.WrapErrors() != nil {
+			foundAny = true
+			log.Errorf("Advisories: %s: %s", res.File, res.Errors.WrapErrors())
+		}
+	}
+	if !foundAny {
+		log.Infof("No linting issues found!")
+	}
+}
+
+// PrintRules prints the rules to the logger.
+func (l *Linter) PrintRules(ctx context.Context) {
+	log := clog.FromContext(ctx)
+	log.Info("Available rules:")
+	for _, rule := range AllRules {
+		log.Infof("* %s: %s\n", rule.Name, cases.Title(language.Und).String(rule.Description))
+	}
+}
+
+// paths returns the sorted paths of the advisory documents to lint.
+func (l *Linter) paths() ([]string, error) {
+	info, err := os.Stat(l.options.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{l.options.Path}, nil
+	}
+
+	entries, err := os.ReadDir(l.options.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasSuffix(e.Name(), advisoriesFileSuffix) {
+			paths = append(paths, filepath.Join(l.options.Path, e.Name()))
+		}
+	}
+
+	// sort for consistent ordering
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func readFile(p string) (File, error) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return File{}, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return File{}, fmt.Errorf("parsing %s: %w", p, err)
+	}
+
+	root := &doc
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+
+	return File{Path: p, Content: content, Root: root}, nil
+}
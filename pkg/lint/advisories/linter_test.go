@@ -0,0 +1,101 @@
+package advisories
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/lint"
+)
+
+func TestLinter_File(t *testing.T) {
+	tests := []struct {
+		file          string
+		expectedRules []string
+	}{
+		{
+			file: "valid.advisories.yaml",
+		},
+		{
+			file:          "unsorted.advisories.yaml",
+			expectedRules: []string{"advisories-sorted-by-id"},
+		},
+		{
+			file:          "field-order.advisories.yaml",
+			expectedRules: []string{"canonical-field-order"},
+		},
+		{
+			file:          "trailing-whitespace.advisories.yaml",
+			expectedRules: []string{"no-trailing-whitespace"},
+		},
+		{
+			file:          "non-utc-timestamp.advisories.yaml",
+			expectedRules: []string{"timestamps-in-utc"},
+		},
+		{
+			file:          "missing-fields.advisories.yaml",
+			expectedRules: []string{"required-fields"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			l := New(lint.WithPath(filepath.Join("testdata", tt.file)))
+			result, err := l.Lint(context.Background(), lint.SeverityInfo)
+			require.NoError(t, err)
+
+			var rules []string
+			for _, res := range result {
+				for _, e := range res.Errors {
+					rules = append(rules, e.Rule.Name)
+				}
+			}
+			assert.Equal(t, tt.expectedRules, rules)
+		})
+	}
+}
+
+func TestLinter_Dir(t *testing.T) {
+	t.Run("all documents", func(t *testing.T) {
+		l := New(lint.WithPath("testdata"))
+		result, err := l.Lint(context.Background(), lint.SeverityInfo)
+		require.NoError(t, err)
+
+		var files []string
+		for _, res := range result {
+			files = append(files, res.File)
+		}
+		assert.Equal(t, []string{
+			"field-order",
+			"missing-fields",
+			"non-utc-timestamp",
+			"trailing-whitespace",
+			"unsorted",
+		}, files)
+	})
+
+	t.Run("minimum severity", func(t *testing.T) {
+		l := New(lint.WithPath("testdata"))
+		result, err := l.Lint(context.Background(), lint.SeverityError)
+		require.NoError(t, err)
+
+		for _, res := range result {
+			for _, e := range res.Errors {
+				assert.Equal(t, lint.SeverityError, e.Rule.Severity)
+			}
+		}
+		assert.Len(t, result, 3)
+	})
+
+	t.Run("skipped rules", func(t *testing.T) {
+		l := New(
+			lint.WithPath("testdata"),
+			lint.WithSkipRules([]string{"advisories-sorted-by-id", "canonical-field-order"}),
+		)
+		result, err := l.Lint(context.Background(), lint.SeverityInfo)
+		require.NoError(t, err)
+		assert.Len(t, result, 3)
+	})
+}
@@ -0,0 +1,285 @@
+package advisories
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/wolfi-dev/wolfictl/pkg/lint"
+	"gopkg.in/yaml.v3"
+)
+
+// File is an advisory document to lint.
+type File struct {
+	// Path is the path of the file.
+	Path string
+
+	// Content is the raw content of the file.
+	Content []byte
+
+	// Root is the top-level mapping node of the parsed file.
+	Root *yaml.Node
+}
+
+// Function is a function that lints a single advisory document.
+type Function func(File) error
+
+// Rule represents a linter rule for advisory documents.
+type Rule struct {
+	// Name is the name of the rule.
+	Name string
+
+	// Description is the description of the rule.
+	Description string
+
+	// Severity is the severity of the rule.
+	Severity lint.Severity
+
+	// LintFunc is the function that lints a single advisory document.
+	LintFunc Function
+}
+
+// Rules is a list of Rule.
+type Rules []Rule
+
+var (
+	documentFieldOrder = []string{"schema-version", "package", "advisories"}
+	packageFieldOrder  = []string{"name"}
+	advisoryFieldOrder = []string{"id", "aliases", "events"}
+	eventFieldOrder    = []string{"timestamp", "type", "data"}
+
+	// eventDataFieldOrder is the field order of each event type's data.
+	eventDataFieldOrder = map[string][]string{
+		v2.EventTypeDetection:                  {"type", "data"},
+		v2.EventTypeTruePositiveDetermination:  {"note"},
+		v2.EventTypeFixed:                      {"fixed-version"},
+		v2.EventTypeFalsePositiveDetermination: {"type", "note"},
+		v2.EventTypeAnalysisNotPlanned:         {"note"},
+		v2.EventTypeFixNotPlanned:              {"note"},
+		v2.EventTypePendingUpstreamFix:         {"note"},
+	}
+
+	// detectionDataFieldOrder is the field order of each detection type's data.
+	detectionDataFieldOrder = map[string][]string{
+		v2.DetectionTypeNVDAPI: {"cpeSearched", "cpeFound"},
+		v2.DetectionTypeScanV1: {
+			"subpackageName",
+			"componentID",
+			"componentName",
+			"componentVersion",
+			"componentType",
+			"componentLocation",
+			"scanner",
+		},
+	}
+
+	// eventDataRequiredFields is the data fields that each event type requires.
+	eventDataRequiredFields = map[string][]string{
+		v2.EventTypeDetection:                  {"type"},
+		v2.EventTypeFixed:                      {"fixed-version"},
+		v2.EventTypeFalsePositiveDetermination: {"type"},
+		v2.EventTypeAnalysisNotPlanned:         {"note"},
+		v2.EventTypeFixNotPlanned:              {"note"},
+		v2.EventTypePendingUpstreamFix:         {"note"},
+	}
+)
+
+// AllRules is a list of all available rules to evaluate.
+var AllRules = Rules{
+	{
+		Name:        "advisories-sorted-by-id",
+		Description: "advisories should be sorted by ID",
+		Severity:    lint.SeverityWarning,
+		LintFunc: func(f File) error {
+			var ids []string
+			for _, adv := range advisoryNodes(f.Root) {
+				ids = append(ids, scalarValue(adv, "id"))
+			}
+
+			for i := 1; i < len(ids); i++ {
+				if ids[i] < ids[i-1] {
+					return fmt.Errorf("advisory %q should come before advisory %q", ids[i], ids[i-1])
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "canonical-field-order",
+		Description: "fields should be in the same order as in the advisory schema",
+		Severity:    lint.SeverityWarning,
+		LintFunc: func(f File) error {
+			errs := []error{
+				checkFieldOrder("document", f.Root, documentFieldOrder),
+				checkFieldOrder("package", mappingValue(f.Root, "package"), packageFieldOrder),
+			}
+
+			for _, adv := range advisoryNodes(f.Root) {
+				id := scalarValue(adv, "id")
+				errs = append(errs, checkFieldOrder(fmt.Sprintf("advisory %q", id), adv, advisoryFieldOrder))
+
+				for i, event := range eventNodes(adv) {
+					where := fmt.Sprintf("advisory %q event %d", id, i+1)
+					errs = append(errs, checkFieldOrder(where, event, eventFieldOrder))
+
+					typ := scalarValue(event, "type")
+					data := mappingValue(event, "data")
+					errs = append(errs, checkFieldOrder(where+" data", data, eventDataFieldOrder[typ]))
+
+					if typ == v2.EventTypeDetection {
+						detectionData := mappingValue(data, "data")
+						errs = append(errs, checkFieldOrder(where+" detection data", detectionData, detectionDataFieldOrder[scalarValue(data, "type")]))
+					}
+				}
+			}
+
+			return errors.Join(errs...)
+		},
+	},
+	{
+		Name:        "no-trailing-whitespace",
+		Description: "lines should not end with whitespace",
+		Severity:    lint.SeverityError,
+		LintFunc: func(f File) error {
+			var lines []string
+			for i, line := range bytes.Split(f.Content, []byte("\n")) {
+				if len(bytes.TrimRight(line, " \t\r")) != len(line) {
+					lines = append(lines, fmt.Sprint(i+1))
+				}
+			}
+
+			if len(lines) > 0 {
+				return fmt.Errorf("trailing whitespace on line(s) %s", strings.Join(lines, ", "))
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "timestamps-in-utc",
+		Description: "event timestamps should be RFC 3339 timestamps in UTC",
+		Severity:    lint.SeverityError,
+		LintFunc: func(f File) error {
+			var errs []error
+			for _, adv := range advisoryNodes(f.Root) {
+				for i, event := range eventNodes(adv) {
+					ts := scalarValue(event, "timestamp")
+					if ts == "" {
+						continue
+					}
+
+					t, err := time.Parse(time.RFC3339, ts)
+					if err != nil || !strings.HasSuffix(ts, "Z") || t.Location() != time.UTC {
+						errs = append(errs, fmt.Errorf("advisory %q event %d: timestamp %q is not in UTC (e.g. %q)", scalarValue(adv, "id"), i+1, ts, "2006-01-02T15:04:05Z"))
+					}
+				}
+			}
+			return errors.Join(errs...)
+		},
+	},
+	{
+		Name:        "required-fields",
+		Description: "advisories and events should have the fields required by their type",
+		Severity:    lint.SeverityError,
+		LintFunc: func(f File) error {
+			var errs []error
+			for i, adv := range advisoryNodes(f.Root) {
+				id := scalarValue(adv, "id")
+				where := fmt.Sprintf("advisory %q", id)
+				if id == "" {
+					where = fmt.Sprintf("advisory %d", i+1)
+				}
+				errs = append(errs, checkRequiredFields(where, adv, []string{"id", "events"}))
+
+				for j, event := range eventNodes(adv) {
+					where := fmt.Sprintf("%s event %d", where, j+1)
+					errs = append(errs, checkRequiredFields(where, event, []string{"timestamp", "type"}))
+
+					required := eventDataRequiredFields[scalarValue(event, "type")]
+					if len(required) == 0 {
+						continue
+					}
+					data := mappingValue(event, "data")
+					if data == nil {
+						errs = append(errs, fmt.Errorf("%s: missing data", where))
+						continue
+					}
+					errs = append(errs, checkRequiredFields(where+" data", data, required))
+				}
+			}
+			return errors.Join(errs...)
+		},
+	},
+}
+
+// checkFieldOrder returns an error if the keys of the mapping node that are in
+// the given order aren't in that order. Other keys are ignored.
+func checkFieldOrder(where string, node *yaml.Node, order []string) error {
+	if node == nil || node.Kind != yaml.MappingNode || len(order) == 0 {
+		return nil
+	}
+
+	var keys []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if k := node.Content[i].Value; slices.Contains(order, k) {
+			keys = append(keys, k)
+		}
+	}
+
+	for i := 1; i < len(keys); i++ {
+		if slices.Index(order, keys[i]) < slices.Index(order, keys[i-1]) {
+			return fmt.Errorf("%s: field %q should come before field %q", where, keys[i], keys[i-1])
+		}
+	}
+	return nil
+}
+
+func checkRequiredFields(where string, node *yaml.Node, required []string) error {
+	var errs []error
+	for _, k := range required {
+		if v := mappingValue(node, k); v == nil || (v.Kind == yaml.ScalarNode && v.Value == "") {
+			errs = append(errs, fmt.Errorf("%s: missing required field %q", where, k))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mappingValue returns the value node for the key in the mapping node, or nil
+// if there isn't one.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarValue(node *yaml.Node, key string) string {
+	if v := mappingValue(node, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+func sequenceItems(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+func advisoryNodes(root *yaml.Node) []*yaml.Node {
+	return sequenceItems(mappingValue(root, "advisories"))
+}
+
+func eventNodes(adv *yaml.Node) []*yaml.Node {
+	return sequenceItems(mappingValue(adv, "events"))
+}
@@ -0,0 +1,95 @@
+package sbom
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/chainguard-dev/clog"
+)
+
+// In-toto predicate types for SBOM attestations, in the form expected by
+// cosign's --type flag.
+const (
+	PredicateTypeSPDX      = "spdxjson"
+	PredicateTypeCycloneDX = "cyclonedx"
+	PredicateTypeSyft      = "https://syft.dev/bom"
+)
+
+// AttestationSuffix is appended to the path of an APK to get the path of the
+// attestation that Attest writes for the APK's SBOM.
+const AttestationSuffix = ".sbom.sigstore.json"
+
+// AttestOptions configures Attest.
+type AttestOptions struct {
+	// Key is the cosign signing key to use, which can be a path to a key file or a
+	// KMS URI. If empty, keyless signing is used, which requires an OIDC identity
+	// (e.g. from an interactive login, or ambient CI credentials).
+	Key string
+
+	// CosignPath is the path to the cosign executable. If empty, "cosign" is found
+	// in the PATH.
+	CosignPath string
+}
+
+// Attest signs the SBOM of the APK at apkPath as an in-toto attestation using
+// cosign. The SBOM is wrapped in an in-toto statement whose subject is the APK
+// (by its sha256 digest) and whose predicate is the SBOM, with the given
+// predicate type. The signed attestation is written as a Sigstore bundle next
+// to the APK, and its path is returned.
+//
+// Consumers can verify the attestation with "cosign verify-blob-attestation
+// --bundle <attestation> --type <predicate type> <apk>".
+func Attest(ctx context.Context, apkPath string, sbom io.Reader, predicateType string, opts AttestOptions) (string, error) {
+	logger := clog.FromContext(ctx)
+
+	cosign := opts.CosignPath
+	if cosign == "" {
+		cosign = "cosign"
+	}
+
+	predicate, err := os.CreateTemp("", "wolfictl-sbom-predicate-*.json")
+	if err != nil {
+		return "", fmt.Errorf("creating SBOM predicate file: %w", err)
+	}
+	defer os.Remove(predicate.Name())
+
+	if _, err := io.Copy(predicate, sbom); err != nil {
+		predicate.Close()
+		return "", fmt.Errorf("writing SBOM predicate file: %w", err)
+	}
+	if err := predicate.Close(); err != nil {
+		return "", fmt.Errorf("writing SBOM predicate file: %w", err)
+	}
+
+	attestationPath := apkPath + AttestationSuffix
+
+	args := []string{
+		"attest-blob",
+		"--predicate", predicate.Name(),
+		"--type", predicateType,
+		"--bundle", attestationPath,
+		// Don't prompt for confirmation before uploading to the transparency log.
+		"--yes",
+	}
+	if opts.Key != "" {
+		args = append(args, "--key", opts.Key)
+	}
+	args = append(args, apkPath)
+
+	logger.Debug("signing SBOM attestation", "apk", apkPath, "predicateType", predicateType, "keyless", opts.Key == "")
+
+	// Cosign's output is passed through, since signing can be interactive (e.g. to
+	// complete an OIDC login, or to enter the key's password).
+	cmd := exec.CommandContext(ctx, cosign, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running cosign: %w", err)
+	}
+
+	return attestationPath, nil
+}
@@ -0,0 +1,84 @@
+package sbom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCosign writes an executable that records its arguments and the predicate
+// it was given, and writes a placeholder bundle.
+func fakeCosign(t *testing.T) (cosignPath, argsPath, predicatePath string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	cosignPath = filepath.Join(dir, "cosign")
+	argsPath = filepath.Join(dir, "args")
+	predicatePath = filepath.Join(dir, "predicate")
+
+	script := `#!/bin/sh
+printf '%s\n' "$@" > ` + argsPath + `
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --predicate) cp "$2" ` + predicatePath + `; shift ;;
+    --bundle) echo '{}' > "$2"; shift ;;
+  esac
+  shift
+done
+`
+	require.NoError(t, os.WriteFile(cosignPath, []byte(script), 0o700)) //nolint:gosec // the fake cosign must be executable
+
+	return cosignPath, argsPath, predicatePath
+}
+
+func TestAttest(t *testing.T) {
+	ctx := context.Background()
+
+	apkPath := filepath.Join(t.TempDir(), "crane-0.19.1-r6.apk")
+	require.NoError(t, os.WriteFile(apkPath, []byte("not really an APK"), 0o600))
+
+	t.Run("keyless", func(t *testing.T) {
+		cosign, argsPath, predicatePath := fakeCosign(t)
+
+		attestationPath, err := Attest(ctx, apkPath, strings.NewReader(`{"spdxVersion":"SPDX-2.3"}`), PredicateTypeSPDX, AttestOptions{CosignPath: cosign})
+		require.NoError(t, err)
+
+		assert.Equal(t, apkPath+AttestationSuffix, attestationPath)
+		assert.FileExists(t, attestationPath)
+
+		predicate, err := os.ReadFile(predicatePath)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"spdxVersion":"SPDX-2.3"}`, string(predicate))
+
+		args, err := os.ReadFile(argsPath)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(args)), "\n")
+
+		assert.Equal(t, "attest-blob", lines[0])
+		assert.Contains(t, lines, PredicateTypeSPDX)
+		assert.Contains(t, lines, "--yes")
+		assert.NotContains(t, lines, "--key")
+		assert.Equal(t, apkPath, lines[len(lines)-1], "the APK should be the attestation's subject")
+	})
+
+	t.Run("key-based", func(t *testing.T) {
+		cosign, argsPath, _ := fakeCosign(t)
+
+		_, err := Attest(ctx, apkPath, strings.NewReader(`{}`), PredicateTypeCycloneDX, AttestOptions{Key: "cosign.key", CosignPath: cosign})
+		require.NoError(t, err)
+
+		args, err := os.ReadFile(argsPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(args), "--key\ncosign.key\n")
+	})
+
+	t.Run("cosign fails", func(t *testing.T) {
+		_, err := Attest(ctx, apkPath, strings.NewReader(`{}`), PredicateTypeSyft, AttestOptions{CosignPath: "/bin/false"})
+		assert.ErrorContains(t, err, "running cosign")
+	})
+}
@@ -0,0 +1,157 @@
+package sbom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"chainguard.dev/melange/pkg/config"
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/sbom/catalogers"
+)
+
+// FoundByMelangeConfig is the "found by" value of packages in SBOMs created by
+// GenerateForMelangeConfig.
+const FoundByMelangeConfig = "wolfictl-melange-config"
+
+// GenerateForMelangeConfig creates an SBOM of the build-time dependencies of the
+// package defined by the melange configuration at configPath, rather than of
+// the contents of the package's APKs.
+//
+// The SBOM contains the package itself, the packages installed in its build
+// environment (including packages needed by its pipelines), and the sources
+// fetched by its pipelines (the "fetch" and "git-checkout" pipelines), with
+// their expected checksums or commits. Each dependency has a "dependency of"
+// relationship to the package.
+//
+// Since a melange configuration can be built for several architectures, the
+// architecture used in the package URLs of the package and of its build
+// environment's packages is given by arch.
+func GenerateForMelangeConfig(ctx context.Context, configPath, distroID, arch string) (*sbom.SBOM, error) {
+	log := clog.FromContext(ctx)
+
+	log.Info("generating build-time SBOM for melange configuration", "path", configPath, "distroID", distroID, "arch", arch)
+
+	cfg, err := config.ParseConfiguration(ctx, configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse melange configuration: %w", err)
+	}
+
+	fullVersion := fmt.Sprintf("%s-r%d", cfg.Package.Version, cfg.Package.Epoch)
+
+	root := pkg.Package{
+		Name:      cfg.Package.Name,
+		Version:   fullVersion,
+		FoundBy:   FoundByMelangeConfig,
+		Locations: file.NewLocationSet(file.NewLocation(configPath)),
+		Type:      pkg.ApkPkg,
+		PURL:      apkPURL(distroID, cfg.Package.Name, fullVersion, arch, cfg.Package.Name),
+		Metadata: pkg.ApkDBEntry{
+			Package:       cfg.Package.Name,
+			OriginPackage: cfg.Package.Name,
+			Version:       fullVersion,
+			Architecture:  arch,
+			URL:           cfg.Package.URL,
+			Description:   cfg.Package.Description,
+			GitCommit:     cfg.Package.Commit,
+		},
+	}
+	if ex := cfg.Package.LicenseExpression(); ex != "" {
+		root.Licenses = pkg.NewLicenseSet(pkg.NewLicense(ex))
+	}
+	root.SetID()
+
+	deps := environmentPackages(cfg, configPath, distroID, arch)
+	deps = append(deps, catalogers.MelangeSourcePackages(cfg, file.NewLocation(configPath), FoundByMelangeConfig)...)
+
+	collection := pkg.NewCollection(root)
+	var relationships []artifact.Relationship
+	for i := range deps {
+		deps[i].SetID()
+		collection.Add(deps[i])
+
+		relationships = append(relationships, artifact.Relationship{
+			From: deps[i],
+			To:   root,
+			Type: artifact.DependencyOfRelationship,
+		})
+	}
+
+	log.Info("finished build-time SBOM generation", "packageCount", collection.PackageCount())
+
+	s := sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			Packages: collection,
+			LinuxDistribution: &linux.Release{
+				ID: distroID,
+			},
+		},
+		Relationships: relationships,
+		Source: source.Description{
+			ID:      "(redacted for determinism)",
+			Name:    cfg.Package.Name,
+			Version: fullVersion,
+			Metadata: source.FileMetadata{
+				Path: configPath,
+			},
+		},
+		Descriptor: newDescriptor(nil),
+	}
+
+	return &s, nil
+}
+
+// environmentPackages returns the packages installed in the build environment
+// of the given configuration, including the packages explicitly needed by its
+// pipelines. Only packages pinned to an exact version ("name=version") have a
+// version.
+func environmentPackages(cfg *config.Configuration, configPath, distroID, arch string) []pkg.Package {
+	specs := append([]string{}, cfg.Environment.Contents.Packages...)
+	catalogers.WalkMelangePipelines(cfg, func(p *config.Pipeline) {
+		if p.Needs != nil {
+			specs = append(specs, p.Needs.Packages...)
+		}
+	})
+
+	seen := make(map[string]bool)
+	var packages []pkg.Package
+	for _, spec := range specs {
+		if seen[spec] {
+			continue
+		}
+		seen[spec] = true
+
+		name, version := parsePackageSpec(spec)
+		packages = append(packages, pkg.Package{
+			Name:      name,
+			Version:   version,
+			FoundBy:   FoundByMelangeConfig,
+			Locations: file.NewLocationSet(file.NewLocation(configPath)),
+			Type:      pkg.ApkPkg,
+			PURL:      apkPURL(distroID, name, version, arch, ""),
+		})
+	}
+
+	return packages
+}
+
+// parsePackageSpec returns the name and, if the spec pins an exact version, the
+// version of an apk package spec, such as "openssl", "openssl=3.3.0-r8", or
+// "openssl>3".
+func parsePackageSpec(spec string) (name, version string) {
+	if i := strings.IndexAny(spec, "=<>~"); i != -1 {
+		name = spec[:i]
+		if spec[i] == '=' {
+			version = spec[i+1:]
+		}
+		return name, version
+	}
+
+	return spec, ""
+}
@@ -0,0 +1,99 @@
+package sbom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMelangeConfig = `package:
+  name: crane
+  version: 0.19.1
+  epoch: 6
+  description: Tool for interacting with remote images and registries.
+  copyright:
+    - license: Apache-2.0
+
+environment:
+  contents:
+    packages:
+      - busybox
+      - go=1.22.3-r0
+      - ca-certificates-bundle
+
+pipeline:
+  - uses: git-checkout
+    with:
+      repository: https://github.com/google/go-containerregistry
+      tag: v${{package.version}}
+      expected-commit: 1b4e4078a545f2b6f0f0e6d4e7a3e4b5c3b2a1d0
+
+  - uses: fetch
+    with:
+      uri: https://example.com/extras/extras-${{package.version}}.tar.gz
+      expected-sha256: 0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9
+
+  - pipeline:
+      - uses: go/build
+        needs:
+          packages:
+            - busybox
+            - openssl>3
+        with:
+          packages: ./cmd/crane
+          output: crane
+`
+
+func TestGenerateForMelangeConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "crane.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(testMelangeConfig), 0o600))
+
+	s, err := GenerateForMelangeConfig(context.Background(), configPath, "wolfi", "aarch64")
+	require.NoError(t, err)
+
+	purls := make(map[string]string)
+	for _, p := range s.Artifacts.Packages.Sorted() {
+		purls[p.Name] = p.PURL
+	}
+	assert.Equal(t, map[string]string{
+		"crane":                  "pkg:apk/wolfi/crane@0.19.1-r6?arch=aarch64&distro=wolfi&origin=crane",
+		"busybox":                "pkg:apk/wolfi/busybox?arch=aarch64&distro=wolfi",
+		"go":                     "pkg:apk/wolfi/go@1.22.3-r0?arch=aarch64&distro=wolfi",
+		"ca-certificates-bundle": "pkg:apk/wolfi/ca-certificates-bundle?arch=aarch64&distro=wolfi",
+		"openssl":                "pkg:apk/wolfi/openssl?arch=aarch64&distro=wolfi",
+		"go-containerregistry":   "pkg:github/google/go-containerregistry@1b4e4078a545f2b6f0f0e6d4e7a3e4b5c3b2a1d0",
+		"extras-0.19.1.tar.gz":   "pkg:generic/extras-0.19.1.tar.gz@0.19.1?checksum=sha256%3A0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9&download_url=https%3A%2F%2Fexample.com%2Fextras%2Fextras-0.19.1.tar.gz",
+	}, purls)
+
+	require.Len(t, s.Relationships, len(purls)-1)
+	for _, r := range s.Relationships {
+		assert.Equal(t, artifact.DependencyOfRelationship, r.Type)
+		assert.Equal(t, "crane", s.Artifacts.Packages.Package(r.To.ID()).Name)
+	}
+}
+
+func TestParsePackageSpec(t *testing.T) {
+	cases := []struct {
+		spec            string
+		expectedName    string
+		expectedVersion string
+	}{
+		{spec: "openssl", expectedName: "openssl"},
+		{spec: "openssl=3.3.0-r8", expectedName: "openssl", expectedVersion: "3.3.0-r8"},
+		{spec: "openssl>3", expectedName: "openssl"},
+		{spec: "openssl~3.3", expectedName: "openssl"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.spec, func(t *testing.T) {
+			name, version := parsePackageSpec(tt.spec)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedVersion, version)
+		})
+	}
+}
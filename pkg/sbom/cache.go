@@ -1,33 +1,67 @@
 package sbom
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/anchore/syft/syft/sbom"
 	"github.com/chainguard-dev/clog"
 )
 
-var sbomCacheDirectory = path.Join(xdg.CacheHome, "wolfictl", "sbom", "apk")
+// DefaultCacheDir is the directory used to cache generated SBOMs.
+var DefaultCacheDir = path.Join(xdg.CacheHome, "wolfictl", "sbom", "apk")
 
-func cachedSBOMPath(inputFilePath string, f io.Reader) (string, error) {
-	h := sha256.New()
-	_, err := io.Copy(h, f)
+// sbomCacheFormat should be incremented whenever the shape of the cached data
+// (or the meaning of the cache key) changes in an incompatible way.
+const sbomCacheFormat = "4"
+
+// cachedSBOMPath returns the path of the cached SBOM for the APK with the given
+// sha256 digest. The path is content-addressed: it doesn't depend on the name of
+// the APK file, only on its digest, the distro reported in the SBOM, and the
+// configuration used to catalog the APK's contents.
+func cachedSBOMPath(digest []byte, distroID string, opts GenerateOptions) string {
+	return path.Join(DefaultCacheDir, fmt.Sprintf("sha256-%x-%s-%s.syft.json", digest, distroID, catalogerConfigDigest(opts)))
+}
+
+// syftVersion returns the version of the Syft module wolfictl was built with.
+var syftVersion = sync.OnceValue(func() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range bi.Deps {
+			if dep.Path == "github.com/anchore/syft" {
+				return dep.Version
+			}
+		}
+	}
+
+	return "unknown"
+})
+
+// catalogerConfigDigest returns an identifier for everything other than the APK
+// itself that can affect a generated SBOM: the generation options, the
+// resulting cataloger configuration, and the version of Syft.
+func catalogerConfigDigest(opts GenerateOptions) string {
+	cfg, err := json.Marshal(newCreateSBOMConfig(opts))
 	if err != nil {
-		return "", fmt.Errorf("failed to hash input file: %w", err)
+		// This isn't expected, but a cache key that's too coarse would be worse
+		// than one that's always different.
+		cfg = []byte(fmt.Sprintf("unencodable config: %s", err))
 	}
 
-	digest := h.Sum(nil)
-	apkFilename := path.Base(inputFilePath)
-	apkFilename = apkFilename[:len(apkFilename)-len(path.Ext(apkFilename))]
+	h := sha256.New()
+	fmt.Fprintf(h, "format=%s\nsyft=%s\nfiles=%t\ndependencies=%t\ndeduplicate=%t\nconfig=%s\n", sbomCacheFormat, syftVersion(), opts.IncludeFiles, opts.IncludeDependencies, opts.Deduplicate, cfg)
 
-	return path.Join(sbomCacheDirectory, fmt.Sprintf("%s-sha256-%x.syft.json", apkFilename, digest)), nil
+	return fmt.Sprintf("%x", h.Sum(nil))[:16]
 }
 
 // CachedGenerate behaves similarly to Generate, but it caches the result of the
@@ -35,21 +69,38 @@ func cachedSBOMPath(inputFilePath string, f io.Reader) (string, error) {
 // if a generated SBOM is already available in the cache for the given APK,
 // CachedGenerate will return the cached SBOM immediately instead of generating
 // a new SBOM.
+//
+// Cached SBOMs are keyed by the APK's digest, the distro, and the cataloger
+// configuration. Use GarbageCollectCache to limit the size of the cache.
 func CachedGenerate(ctx context.Context, inputFilePath string, f io.Reader, distroID string) (*sbom.SBOM, error) {
+	return CachedGenerateWithOptions(ctx, inputFilePath, f, distroID, GenerateOptions{})
+}
+
+// CachedGenerateWithOptions behaves like CachedGenerate, but it generates SBOMs
+// using GenerateWithOptions. SBOMs generated with different options are cached
+// separately.
+func CachedGenerateWithOptions(ctx context.Context, inputFilePath string, f io.Reader, distroID string, opts GenerateOptions) (*sbom.SBOM, error) {
 	logger := clog.FromContext(ctx)
 
 	// Check cache first
 
-	// The cache check needs to read the input file, so we need to tee the input to
-	// provide ourselves with a buffered copy that we'll use in the event of a cache
-	// miss.
-	buf := new(bytes.Buffer)
-	tee := io.TeeReader(f, buf)
-
-	cachedPath, err := cachedSBOMPath(inputFilePath, tee)
+	// The cache check needs to read the input file, so we need to be able to read
+	// it again in the event of a cache miss. The input isn't buffered in memory,
+	// since APKs can be very large.
+	input, start, cleanup, err := seekableInput(f)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compute cached SBOM path: %w", err)
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+	defer cleanup()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, input); err != nil {
+		return nil, fmt.Errorf("failed to hash input file: %w", err)
 	}
+	if _, err := input.Seek(start, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind input file: %w", err)
+	}
+	cachedPath := cachedSBOMPath(h.Sum(nil), distroID, opts)
 
 	logger.Debug("checking cache for SBOM", "expectedPath", cachedPath)
 
@@ -63,32 +114,15 @@ func CachedGenerate(ctx context.Context, inputFilePath string, f io.Reader, dist
 
 		// Cache miss. Generate the SBOM.
 
-		s, err := Generate(ctx, inputFilePath, buf, distroID)
+		s, err := GenerateWithOptions(ctx, inputFilePath, input, distroID, opts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate SBOM: %w", err)
 		}
 
 		// Cache the new SBOM for retrieval later.
 
-		err = os.MkdirAll(path.Dir(cachedPath), 0o755)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create cache directory: %w", err)
-		}
-
-		cached, err := os.Create(cachedPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create cached SBOM file: %w", err)
-		}
-		defer cached.Close()
-
-		jsonReader, err := ToSyftJSON(s)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert SBOM to Syft JSON: %w", err)
-		}
-
-		_, err = io.Copy(cached, jsonReader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to write SBOM to cache: %w", err)
+		if err := putCachedSBOM(cachedPath, s); err != nil {
+			return nil, err
 		}
 
 		// Finally, return the SBOM.
@@ -106,5 +140,159 @@ func CachedGenerate(ctx context.Context, inputFilePath string, f io.Reader, dist
 		return nil, fmt.Errorf("failed to decode cached SBOM (%s): %w", cachedPath, err)
 	}
 
+	// Record that the entry was used, so that garbage collection by age removes
+	// the entries that haven't been used recently, rather than the entries that
+	// were created first.
+	now := time.Now()
+	if err := os.Chtimes(cachedPath, now, now); err != nil {
+		logger.Warn("failed to update cached SBOM's modification time", "cachedPath", cachedPath, "error", err)
+	}
+
 	return s, nil
 }
+
+// putCachedSBOM stores the SBOM at the given cache path.
+func putCachedSBOM(cachedPath string, s *sbom.SBOM) error {
+	err := os.MkdirAll(path.Dir(cachedPath), 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	jsonReader, err := ToSyftJSON(s)
+	if err != nil {
+		return fmt.Errorf("failed to convert SBOM to Syft JSON: %w", err)
+	}
+
+	// Write to a temp file first, so that concurrent readers never see a partially
+	// written entry.
+	tmp, err := os.CreateTemp(path.Dir(cachedPath), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cached SBOM file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, jsonReader); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write SBOM to cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write SBOM to cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), cachedPath); err != nil {
+		return fmt.Errorf("failed to store SBOM in cache: %w", err)
+	}
+
+	return nil
+}
+
+// CacheGCOptions configures GarbageCollectCache. A zero value for a limit means
+// the limit isn't enforced.
+type CacheGCOptions struct {
+	// MaxAge is the maximum time since a cache entry was last used. Older entries
+	// are removed.
+	MaxAge time.Duration
+
+	// MaxSize is the maximum total size of the cache entries, in bytes. When the
+	// cache is larger, the least recently used entries are removed until it fits.
+	MaxSize int64
+
+	// DryRun, if true, reports the entries that would be removed, without
+	// removing them.
+	DryRun bool
+}
+
+// CacheGCResult summarizes the outcome of GarbageCollectCache.
+type CacheGCResult struct {
+	RemovedEntries   int
+	RemovedBytes     int64
+	RemainingEntries int
+	RemainingBytes   int64
+}
+
+// GarbageCollectCache removes entries from the SBOM cache in dir (or
+// DefaultCacheDir, if dir is empty) according to the given policies. Entries
+// that don't match the current cache key format (such as SBOMs cached by older
+// versions of wolfictl) are treated like any other entries, so they're removed
+// once they exceed the configured age.
+func GarbageCollectCache(ctx context.Context, dir string, opts CacheGCOptions) (*CacheGCResult, error) {
+	logger := clog.FromContext(ctx)
+
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &CacheGCResult{}, nil
+		}
+		return nil, fmt.Errorf("reading SBOM cache directory: %w", err)
+	}
+
+	var entries []entry
+	for _, de := range dirEntries {
+		if !de.Type().IsRegular() {
+			continue
+		}
+
+		info, err := de.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// Removed concurrently.
+				continue
+			}
+			return nil, fmt.Errorf("reading SBOM cache entry: %w", err)
+		}
+
+		entries = append(entries, entry{
+			path:    path.Join(dir, de.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+
+	// Least recently used first.
+	slices.SortFunc(entries, func(a, b entry) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	result := &CacheGCResult{}
+	for i := range entries {
+		result.RemainingEntries++
+		result.RemainingBytes += entries[i].size
+	}
+
+	now := time.Now()
+	for i := range entries {
+		e := entries[i]
+
+		tooOld := opts.MaxAge > 0 && now.Sub(e.modTime) > opts.MaxAge
+		tooBig := opts.MaxSize > 0 && result.RemainingBytes > opts.MaxSize
+		if !tooOld && !tooBig {
+			// Entries are sorted by age, so no later entry is too old, and the cache
+			// is already within its size limit.
+			break
+		}
+
+		logger.Debug("removing cached SBOM", "path", e.path, "lastUsed", e.modTime, "size", e.size, "dryRun", opts.DryRun)
+		if !opts.DryRun {
+			if err := os.Remove(e.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("removing cached SBOM: %w", err)
+			}
+		}
+
+		result.RemovedEntries++
+		result.RemovedBytes += e.size
+		result.RemainingEntries--
+		result.RemainingBytes -= e.size
+	}
+
+	return result, nil
+}
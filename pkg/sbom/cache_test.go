@@ -0,0 +1,103 @@
+package sbom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedSBOMPath(t *testing.T) {
+	digest := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	p := cachedSBOMPath(digest, "wolfi", GenerateOptions{})
+	assert.Equal(t, DefaultCacheDir, filepath.Dir(p))
+	assert.True(t, strings.HasPrefix(filepath.Base(p), "sha256-deadbeef-wolfi-"), "unexpected path %q", p)
+	assert.Equal(t, p, cachedSBOMPath(digest, "wolfi", GenerateOptions{}), "path should be stable")
+	assert.NotEqual(t, p, cachedSBOMPath(digest, "chainguard", GenerateOptions{}), "path should depend on the distro")
+	assert.NotEqual(t, p, cachedSBOMPath(digest, "wolfi", GenerateOptions{IncludeFiles: true}), "path should depend on the options")
+	assert.NotEqual(t, p, cachedSBOMPath(digest, "wolfi", GenerateOptions{DisableCatalogers: []string{"java"}}), "path should depend on the cataloger selection")
+}
+
+func TestGarbageCollectCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	// Each entry is 100 bytes.
+	entries := map[string]time.Duration{
+		"sha256-aaaa-wolfi-config.syft.json": 0,
+		"sha256-bbbb-wolfi-config.syft.json": 2 * time.Hour,
+		"sha256-cccc-wolfi-config.syft.json": 48 * time.Hour,
+		"crane-sha256-dddd.syft.json":        96 * time.Hour,
+		"sha256-eeee-chainguard-x.syft.json": 30 * time.Minute,
+	}
+
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		for name, age := range entries {
+			p := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(p, []byte(strings.Repeat("x", 100)), 0o600))
+			require.NoError(t, os.Chtimes(p, now.Add(-age), now.Add(-age)))
+		}
+		return dir
+	}
+
+	remaining := func(t *testing.T, dir string) []string {
+		des, err := os.ReadDir(dir)
+		require.NoError(t, err)
+
+		var names []string
+		for _, de := range des {
+			names = append(names, de.Name())
+		}
+		return names
+	}
+
+	t.Run("max age", func(t *testing.T) {
+		dir := setup(t)
+
+		result, err := GarbageCollectCache(ctx, dir, CacheGCOptions{MaxAge: 24 * time.Hour})
+		require.NoError(t, err)
+
+		assert.Equal(t, &CacheGCResult{RemovedEntries: 2, RemovedBytes: 200, RemainingEntries: 3, RemainingBytes: 300}, result)
+		assert.ElementsMatch(t, []string{
+			"sha256-aaaa-wolfi-config.syft.json",
+			"sha256-bbbb-wolfi-config.syft.json",
+			"sha256-eeee-chainguard-x.syft.json",
+		}, remaining(t, dir))
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		dir := setup(t)
+
+		result, err := GarbageCollectCache(ctx, dir, CacheGCOptions{MaxSize: 250})
+		require.NoError(t, err)
+
+		assert.Equal(t, &CacheGCResult{RemovedEntries: 3, RemovedBytes: 300, RemainingEntries: 2, RemainingBytes: 200}, result)
+		assert.ElementsMatch(t, []string{
+			"sha256-aaaa-wolfi-config.syft.json",
+			"sha256-eeee-chainguard-x.syft.json",
+		}, remaining(t, dir))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		dir := setup(t)
+
+		result, err := GarbageCollectCache(ctx, dir, CacheGCOptions{MaxAge: time.Hour, MaxSize: 1000, DryRun: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, result.RemovedEntries)
+		assert.Len(t, remaining(t, dir), len(entries))
+	})
+
+	t.Run("missing cache directory", func(t *testing.T) {
+		result, err := GarbageCollectCache(ctx, filepath.Join(t.TempDir(), "nope"), CacheGCOptions{MaxAge: time.Hour})
+		require.NoError(t, err)
+		assert.Equal(t, &CacheGCResult{}, result)
+	})
+}
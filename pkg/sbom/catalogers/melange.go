@@ -0,0 +1,161 @@
+package catalogers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"chainguard.dev/melange/pkg/config"
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/cataloging/pkgcataloging"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/chainguard-dev/clog"
+	"github.com/package-url/packageurl-go"
+	"gopkg.in/yaml.v3"
+)
+
+// MelangeSources is a cataloger for the upstream sources of an APK, as pinned by
+// the "fetch" and "git-checkout" pipelines of the melange configuration that
+// melange embeds in the APK (".melange.yaml"). Each source is a package whose
+// package URL includes the source's location and its expected checksum or
+// commit, so that this provenance data is kept in the APK's SBOM.
+type MelangeSources struct{}
+
+func (m MelangeSources) Name() string {
+	return "melange-source-cataloger"
+}
+
+func (m MelangeSources) Catalog(ctx context.Context, resolver file.Resolver) ([]pkg.Package, []artifact.Relationship, error) {
+	log := clog.FromContext(ctx)
+
+	locations, err := resolver.FilesByGlob("**/.melange.yaml")
+	if err != nil {
+		return nil, nil, fmt.Errorf("finding melange configuration files: %w", err)
+	}
+
+	var pkgs []pkg.Package
+	for _, l := range locations {
+		rc, err := resolver.FileContentsByLocation(l)
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting file contents: %w", err)
+		}
+
+		var cfg config.Configuration
+		err = yaml.NewDecoder(rc).Decode(&cfg)
+		rc.Close()
+		if err != nil {
+			log.Warnf("parsing melange configuration %q: %v", l.Path(), err)
+			continue
+		}
+
+		evidence := l.WithAnnotation(pkg.EvidenceAnnotationKey, pkg.PrimaryEvidenceAnnotation)
+		pkgs = append(pkgs, MelangeSourcePackages(&cfg, evidence, m.Name())...)
+	}
+
+	return pkgs, nil, nil
+}
+
+var MelangeSourcesReference = pkgcataloging.CatalogerReference{
+	Cataloger: MelangeSources{},
+	Tags:      []string{"melange"},
+}
+
+// MelangeSourcePackages returns the sources fetched by the pipelines of the
+// given melange configuration, as "generic" or "github" packages, whose package
+// URLs include the source's location and its expected checksum or commit.
+func MelangeSourcePackages(cfg *config.Configuration, location file.Location, foundBy string) []pkg.Package {
+	var packages []pkg.Package
+	WalkMelangePipelines(cfg, func(p *config.Pipeline) {
+		var name, version, purl string
+
+		switch p.Uses {
+		case "fetch":
+			uri := p.With["uri"]
+			if uri == "" {
+				return
+			}
+
+			u, _, _ := strings.Cut(uri, "?")
+			name, version = path.Base(u), cfg.Package.Version
+
+			qualifiers := packageurl.Qualifiers{{Key: "download_url", Value: uri}}
+			switch {
+			case p.With["expected-sha256"] != "":
+				qualifiers = append(qualifiers, packageurl.Qualifier{Key: "checksum", Value: "sha256:" + p.With["expected-sha256"]})
+			case p.With["expected-sha512"] != "":
+				qualifiers = append(qualifiers, packageurl.Qualifier{Key: "checksum", Value: "sha512:" + p.With["expected-sha512"]})
+			}
+
+			purl = packageurl.NewPackageURL(packageurl.TypeGeneric, "", name, version, qualifiers, "").String()
+
+		case "git-checkout":
+			repo := p.With["repository"]
+			if repo == "" {
+				return
+			}
+
+			version = p.With["expected-commit"]
+			if version == "" {
+				version = p.With["tag"]
+			}
+			if version == "" {
+				version = p.With["branch"]
+			}
+
+			name, purl = gitSource(repo, version)
+
+		default:
+			return
+		}
+
+		packages = append(packages, pkg.Package{
+			Name:      name,
+			Version:   version,
+			FoundBy:   foundBy,
+			Locations: file.NewLocationSet(location),
+			Type:      pkg.UnknownPkg,
+			PURL:      purl,
+		})
+	})
+
+	return packages
+}
+
+// gitSource returns the name and package URL of a source checked out from the
+// given git repository at the given ref.
+func gitSource(repo, ref string) (name, purl string) {
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(repo, "https://"), "http://"), ".git")
+	name = path.Base(trimmed)
+
+	if rest, ok := strings.CutPrefix(trimmed, "github.com/"); ok {
+		if owner, repoName, ok := strings.Cut(rest, "/"); ok && !strings.Contains(repoName, "/") {
+			return name, packageurl.NewPackageURL(packageurl.TypeGithub, owner, repoName, ref, nil, "").String()
+		}
+	}
+
+	vcsURL := "git+" + repo
+	if ref != "" {
+		vcsURL += "@" + ref
+	}
+
+	return name, packageurl.NewPackageURL(packageurl.TypeGeneric, "", name, ref, packageurl.Qualifiers{{Key: "vcs_url", Value: vcsURL}}, "").String()
+}
+
+// WalkMelangePipelines calls fn for every pipeline (including nested pipelines)
+// of the configuration and of its subpackages.
+func WalkMelangePipelines(cfg *config.Configuration, fn func(*config.Pipeline)) {
+	var walk func([]config.Pipeline)
+	walk = func(pipelines []config.Pipeline) {
+		for i := range pipelines {
+			fn(&pipelines[i])
+			walk(pipelines[i].Pipeline)
+		}
+	}
+
+	walk(cfg.Pipeline)
+	for i := range cfg.Subpackages {
+		walk(cfg.Subpackages[i].Pipeline)
+	}
+}
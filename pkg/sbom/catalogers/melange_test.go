@@ -0,0 +1,43 @@
+package catalogers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMelangeSources(t *testing.T) {
+	pkgs, _, err := MelangeSources{}.Catalog(context.Background(), file.NewMockResolverForPaths(
+		"testdata/melange/.melange.yaml",
+	))
+	require.NoError(t, err)
+
+	purls := make(map[string]string)
+	for _, p := range pkgs {
+		purls[p.Name] = p.PURL
+
+		assert.Equal(t, pkg.UnknownPkg, p.Type)
+		assert.Equal(t, "melange-source-cataloger", p.FoundBy)
+
+		locations := p.Locations.ToSlice()
+		require.Len(t, locations, 1)
+		assert.Equal(t, "testdata/melange/.melange.yaml", locations[0].RealPath)
+		assert.Equal(t, pkg.PrimaryEvidenceAnnotation, locations[0].Annotations[pkg.EvidenceAnnotationKey])
+	}
+
+	assert.Equal(t, map[string]string{
+		"go-containerregistry": "pkg:github/google/go-containerregistry@1b4e4078a545f2b6f0f0e6d4e7a3e4b5c3b2a1d0",
+		"extras-0.19.1.tar.gz": "pkg:generic/extras-0.19.1.tar.gz@0.19.1?checksum=sha512%3A0a1b2c3d&download_url=https%3A%2F%2Fexample.com%2Fextras%2Fextras-0.19.1.tar.gz",
+		"docs":                 "pkg:generic/docs@main?vcs_url=git%2Bhttps%3A%2F%2Fgitlab.com%2Fgroup%2Fsub%2Fdocs.git%40main",
+	}, purls)
+}
+
+func TestGitSource(t *testing.T) {
+	name, purl := gitSource("https://gitlab.com/group/sub/project.git", "v1.2.3")
+	assert.Equal(t, "project", name)
+	assert.Equal(t, "pkg:generic/project@v1.2.3?vcs_url=git%2Bhttps%3A%2F%2Fgitlab.com%2Fgroup%2Fsub%2Fproject.git%40v1.2.3", purl)
+}
@@ -0,0 +1,140 @@
+package sbom
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+	"github.com/chainguard-dev/clog"
+)
+
+// FoundByCompose is the "found by" value of the image package in SBOMs created
+// by Compose.
+const FoundByCompose = "wolfictl-compose"
+
+// Compose merges the SBOMs of individual APKs (as created by Generate) into a
+// single SBOM for a filesystem in which all of the APKs are installed, such as a
+// container image.
+//
+// The merged SBOM has a package representing the image, with the given name, and
+// "contains" relationships from the image to each APK and from each APK to each
+// of the packages found in it. Packages found in more than one APK are merged.
+func Compose(ctx context.Context, name, distroID string, apkSBOMs ...*sbom.SBOM) (*sbom.SBOM, error) {
+	log := clog.FromContext(ctx)
+
+	log.Info("composing SBOM from APK SBOMs", "name", name, "apkCount", len(apkSBOMs))
+
+	image := pkg.Package{
+		Name:    name,
+		FoundBy: FoundByCompose,
+		Type:    pkg.UnknownPkg,
+	}
+	image.SetID()
+
+	collection := pkg.NewCollection(image)
+	fileDigests := make(map[file.Coordinates][]file.Digest)
+	var relationships []artifact.Relationship
+
+	for _, s := range apkSBOMs {
+		apks := s.Artifacts.Packages.Sorted(pkg.ApkPkg)
+		if len(apks) != 1 {
+			return nil, fmt.Errorf("SBOM for %q has %d APK packages, expected exactly 1", s.Source.Name, len(apks))
+		}
+		apkPackage := apks[0]
+
+		relationships = append(relationships, artifact.Relationship{
+			From: image,
+			To:   apkPackage,
+			Type: artifact.ContainsRelationship,
+		})
+
+		for _, p := range s.Artifacts.Packages.Sorted() {
+			collection.Add(p)
+		}
+
+		maps.Copy(fileDigests, s.Artifacts.FileDigests)
+
+		relationships = append(relationships, containsRelationships(apkPackage, s.Artifacts.Packages)...)
+		relationships = append(relationships, s.Relationships...)
+	}
+
+	log.Info("finished composing SBOM", "packageCount", collection.PackageCount())
+
+	s := sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			Packages:    collection,
+			FileDigests: fileDigests,
+			LinuxDistribution: &linux.Release{
+				ID: distroID,
+			},
+		},
+		Relationships: relationships,
+		Source: source.Description{
+			ID:   "(redacted for determinism)",
+			Name: name,
+		},
+		Descriptor: newDescriptor(nil),
+	}
+
+	return &s, nil
+}
+
+// containsRelationships returns "contains" relationships from the given APK
+// package to each of the other packages in the collection, except for the
+// packages that represent the APK's runtime dependencies, which aren't in the APK.
+func containsRelationships(apkPackage pkg.Package, packages *pkg.Collection) []artifact.Relationship {
+	var relationships []artifact.Relationship
+	for _, p := range packages.Sorted() {
+		if p.ID() == apkPackage.ID() || p.FoundBy == FoundByAPKDependencies {
+			continue
+		}
+		relationships = append(relationships, artifact.Relationship{
+			From: apkPackage,
+			To:   p,
+			Type: artifact.ContainsRelationship,
+		})
+	}
+
+	return relationships
+}
+
+// APKsFromInstalled returns the paths of the APK files for the packages listed
+// in the given APK installed database (i.e. an image's "lib/apk/db/installed"
+// file). Each APK is expected to be named "<name>-<version>.apk" and to be in
+// one of the given directories, which are searched in order.
+func APKsFromInstalled(installed io.Reader, dirs ...string) ([]string, error) {
+	packages, err := apk.ParsePackageIndex(installed)
+	if err != nil {
+		return nil, fmt.Errorf("parsing installed packages: %w", err)
+	}
+
+	var apkPaths []string
+	for _, p := range packages {
+		filename := p.Filename()
+
+		found := false
+		for _, dir := range dirs {
+			candidate := filepath.Join(dir, filename)
+			if _, err := os.Stat(candidate); err == nil {
+				apkPaths = append(apkPaths, candidate)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unable to find %q in any of the given directories", filename)
+		}
+	}
+
+	return apkPaths, nil
+}
@@ -0,0 +1,117 @@
+package sbom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAPKSBOM(apkName, apkVersion string, components ...pkg.Package) *sbom.SBOM {
+	apk := pkg.Package{
+		Name:    apkName,
+		Version: apkVersion,
+		Type:    pkg.ApkPkg,
+	}
+	apk.SetID()
+
+	for i := range components {
+		components[i].SetID()
+	}
+
+	return &sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			Packages: pkg.NewCollection(append([]pkg.Package{apk}, components...)...),
+		},
+		Source: source.Description{
+			Name:    apkName,
+			Version: apkVersion,
+		},
+	}
+}
+
+func TestCompose(t *testing.T) {
+	xNet := pkg.Package{Name: "golang.org/x/net", Version: "v0.25.0", Type: pkg.GoModulePkg}
+
+	crane := testAPKSBOM("crane", "0.19.1-r6",
+		pkg.Package{Name: "github.com/google/go-containerregistry", Version: "v0.19.1", Type: pkg.GoModulePkg},
+		xNet,
+	)
+	cosign := testAPKSBOM("cosign", "2.2.4-r3", xNet)
+
+	s, err := Compose(context.Background(), "my-image", "wolfi", crane, cosign)
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-image", s.Source.Name)
+	assert.Equal(t, "wolfi", s.Artifacts.LinuxDistribution.ID)
+
+	// The go module found in both APKs is merged.
+	assert.Equal(t, 5, s.Artifacts.Packages.PackageCount())
+
+	var contains []string
+	for _, r := range s.Relationships {
+		require.Equal(t, artifact.ContainsRelationship, r.Type)
+		from := s.Artifacts.Packages.Package(r.From.ID())
+		to := s.Artifacts.Packages.Package(r.To.ID())
+		require.NotNil(t, from)
+		require.NotNil(t, to)
+		contains = append(contains, from.Name+" -> "+to.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"my-image -> crane",
+		"my-image -> cosign",
+		"crane -> github.com/google/go-containerregistry",
+		"crane -> golang.org/x/net",
+		"cosign -> golang.org/x/net",
+	}, contains)
+
+	t.Run("SBOM without an APK", func(t *testing.T) {
+		dir := &sbom.SBOM{
+			Artifacts: sbom.Artifacts{Packages: pkg.NewCollection()},
+			Source:    source.Description{Name: "rootfs"},
+		}
+
+		_, err := Compose(context.Background(), "my-image", "wolfi", crane, dir)
+		assert.ErrorContains(t, err, `SBOM for "rootfs" has 0 APK packages`)
+	})
+}
+
+func TestAPKsFromInstalled(t *testing.T) {
+	const installed = `C:Q1hdjjQdnDv5l6NbSYxL9t6XG5xEE=
+P:crane
+V:0.19.1-r6
+A:x86_64
+F:usr
+F:usr/bin
+R:crane
+
+C:Q1Jq2w7UafFKI2Lyft8n2Ne3s1UOQ=
+P:ca-certificates-bundle
+V:20240315-r1
+A:x86_64
+
+`
+
+	first, second := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(first, "crane-0.19.1-r6.apk"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(second, "crane-0.19.1-r6.apk"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(second, "ca-certificates-bundle-20240315-r1.apk"), nil, 0o600))
+
+	paths, err := APKsFromInstalled(strings.NewReader(installed), first, second)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(first, "crane-0.19.1-r6.apk"),
+		filepath.Join(second, "ca-certificates-bundle-20240315-r1.apk"),
+	}, paths)
+
+	_, err = APKsFromInstalled(strings.NewReader(installed), first)
+	assert.ErrorContains(t, err, `unable to find "ca-certificates-bundle-20240315-r1.apk"`)
+}
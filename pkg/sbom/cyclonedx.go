@@ -0,0 +1,221 @@
+package sbom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/format/common/cyclonedxhelpers"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/package-url/packageurl-go"
+)
+
+// CycloneDXSpecVersion is the version of the CycloneDX specification used by
+// ToCycloneDXJSON.
+const CycloneDXSpecVersion = cyclonedx.SpecVersion1_5
+
+// CycloneDXOptions configures the CycloneDX documents created by
+// ToCycloneDXJSON. The zero value is a valid configuration.
+type CycloneDXOptions struct {
+	// ToolVersion is the version of wolfictl, which is included in the
+	// document's tool information.
+	ToolVersion string
+
+	// UpstreamSources, if set, are the upstream sources of the SBOM's APKs, which
+	// are added to the APK components' external references.
+	UpstreamSources UpstreamSourcesByPackage
+}
+
+// ToCycloneDXJSON returns the SBOM as a reader of a CycloneDX JSON document,
+// using the CycloneDXSpecVersion of the specification.
+//
+// In addition to the data Syft includes for each component (such as its
+// package URL), APK components include the hash of the APK's data section, and
+// pedigree data: the origin package the APK was built from (if it's a
+// subpackage), and the commit of the package's build configuration. Given their
+// upstream sources, APK components also reference the git repositories (as
+// "vcs" references) and source archives (as "distribution" references, with
+// their expected hashes) they were built from.
+//
+// The SBOM's provenance is included in the document's metadata as properties
+// prefixed with "wolfictl:provenance:".
+func ToCycloneDXJSON(s *sbom.SBOM, opts CycloneDXOptions) (io.ReadSeeker, error) {
+	withDescriptor := *s
+	withDescriptor.Descriptor = sbom.Descriptor{
+		Name:    "wolfictl",
+		Version: opts.ToolVersion,
+	}
+
+	bom := cyclonedxhelpers.ToFormatModel(withDescriptor)
+	if bom == nil || bom.Components == nil {
+		return nil, fmt.Errorf("unable to convert SBOM to CycloneDX document")
+	}
+
+	provenance, err := ProvenanceOf(s)
+	if err != nil {
+		return nil, err
+	}
+	if provenance != nil && bom.Metadata != nil {
+		properties := cycloneDXProvenanceProperties(provenance)
+		if bom.Metadata.Properties != nil {
+			properties = append(*bom.Metadata.Properties, properties...)
+		}
+		bom.Metadata.Properties = &properties
+	}
+
+	// Syft encodes the SBOM's packages first, in sorted order, followed by any
+	// other components (e.g. for the OS or files).
+	packages := s.Artifacts.Packages.Sorted()
+	components := *bom.Components
+	if len(components) < len(packages) {
+		return nil, fmt.Errorf("CycloneDX document has %d components, expected at least %d", len(components), len(packages))
+	}
+	for i := range packages {
+		if components[i].Name != packages[i].Name || components[i].Version != packages[i].Version {
+			return nil, fmt.Errorf("CycloneDX component %s@%s doesn't match package %s@%s", components[i].Name, components[i].Version, packages[i].Name, packages[i].Version)
+		}
+
+		if hashes := cycloneDXHashes(packages[i]); len(hashes) > 0 {
+			components[i].Hashes = &hashes
+		}
+		components[i].Pedigree = cycloneDXPedigree(packages[i])
+
+		if refs := cycloneDXSourceReferences(opts.UpstreamSources.sourcesOf(packages[i])); len(refs) > 0 {
+			if components[i].ExternalReferences != nil {
+				refs = append(*components[i].ExternalReferences, refs...)
+			}
+			components[i].ExternalReferences = &refs
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	enc := cyclonedx.NewBOMEncoder(buf, cyclonedx.BOMFileFormatJSON)
+	enc.SetEscapeHTML(false)
+	if err := enc.EncodeVersion(bom, CycloneDXSpecVersion); err != nil {
+		return nil, fmt.Errorf("failed to encode CycloneDX document: %w", err)
+	}
+
+	return bytes.NewReader(buf.Bytes()), nil
+}
+
+// cycloneDXHashAlgorithms maps Syft's digest algorithm names to CycloneDX hash
+// algorithms.
+var cycloneDXHashAlgorithms = map[string]cyclonedx.HashAlgorithm{
+	"md5":    cyclonedx.HashAlgoMD5,
+	"sha1":   cyclonedx.HashAlgoSHA1,
+	"sha256": cyclonedx.HashAlgoSHA256,
+	"sha384": cyclonedx.HashAlgoSHA384,
+	"sha512": cyclonedx.HashAlgoSHA512,
+}
+
+// cycloneDXHashes returns the hashes of the given package that are known from
+// its metadata.
+func cycloneDXHashes(p pkg.Package) []cyclonedx.Hash {
+	var digests []file.Digest
+
+	switch m := p.Metadata.(type) {
+	case pkg.ApkDBEntry:
+		// For APKs we generate SBOMs for, this is the "datahash" from the
+		// .PKGINFO file, i.e. the SHA-256 hash of the APK's data section. (Entries
+		// from an installed database instead have a "Q1"-prefixed SHA-1 checksum of
+		// the control section, which isn't the package's hash.)
+		if b, err := hex.DecodeString(m.Checksum); err == nil && len(b) == sha256.Size {
+			digests = append(digests, file.Digest{Algorithm: "sha256", Value: m.Checksum})
+		}
+
+	case pkg.JavaArchive:
+		digests = append(digests, m.ArchiveDigests...)
+	}
+
+	var hashes []cyclonedx.Hash
+	for _, d := range digests {
+		alg, ok := cycloneDXHashAlgorithms[strings.ToLower(d.Algorithm)]
+		if !ok {
+			continue
+		}
+		hashes = append(hashes, cyclonedx.Hash{Algorithm: alg, Value: d.Value})
+	}
+
+	return hashes
+}
+
+// cycloneDXPedigree returns the pedigree of the given package, or nil if none is
+// known. Only APK packages have known pedigrees.
+func cycloneDXPedigree(p pkg.Package) *cyclonedx.Pedigree {
+	m, ok := p.Metadata.(pkg.ApkDBEntry)
+	if !ok {
+		return nil
+	}
+
+	pedigree := &cyclonedx.Pedigree{}
+
+	if m.OriginPackage != "" && m.OriginPackage != p.Name {
+		ancestor := cyclonedx.Component{
+			Type:    cyclonedx.ComponentTypeLibrary,
+			Name:    m.OriginPackage,
+			Version: p.Version,
+		}
+
+		if purl, err := packageurl.FromString(p.PURL); err == nil {
+			purl.Name = m.OriginPackage
+
+			var qualifiers packageurl.Qualifiers
+			for _, q := range purl.Qualifiers {
+				if q.Key != "origin" {
+					qualifiers = append(qualifiers, q)
+				}
+			}
+			purl.Qualifiers = qualifiers
+
+			ancestor.PackageURL = purl.String()
+		}
+
+		pedigree.Ancestors = &[]cyclonedx.Component{ancestor}
+	}
+
+	if m.GitCommit != "" {
+		pedigree.Commits = &[]cyclonedx.Commit{{UID: m.GitCommit}}
+	}
+
+	if pedigree.Ancestors == nil && pedigree.Commits == nil {
+		return nil
+	}
+
+	return pedigree
+}
+
+// cycloneDXSourceReferences returns external references to the given upstream
+// sources.
+func cycloneDXSourceReferences(sources []UpstreamSource) []cyclonedx.ExternalReference {
+	refs := make([]cyclonedx.ExternalReference, 0, len(sources))
+	for _, s := range sources {
+		if s.VCS {
+			ref := cyclonedx.ExternalReference{URL: s.URL, Type: cyclonedx.ERTypeVCS}
+			if s.Commit != "" {
+				ref.Comment = "commit " + s.Commit
+			}
+			refs = append(refs, ref)
+			continue
+		}
+
+		ref := cyclonedx.ExternalReference{URL: s.URL, Type: cyclonedx.ERTypeDistribution, Comment: "source archive"}
+		var hashes []cyclonedx.Hash
+		for _, d := range s.Checksums {
+			if alg, ok := cycloneDXHashAlgorithms[d.Algorithm]; ok {
+				hashes = append(hashes, cyclonedx.Hash{Algorithm: alg, Value: d.Value})
+			}
+		}
+		if len(hashes) > 0 {
+			ref.Hashes = &hashes
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
@@ -0,0 +1,100 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToCycloneDXJSON(t *testing.T) {
+	const dataHash = "4d2a1b3a6a2c0ff1c1dd1cb1f1b1e4a3d0b6b2c8b1d8a0b4f5e6d7c8b9a0f1e2"
+
+	apk := pkg.Package{
+		Name:    "openjdk-21-jre",
+		Version: "21.0.3-r3",
+		Type:    pkg.ApkPkg,
+		PURL:    "pkg:apk/wolfi/openjdk-21-jre@21.0.3-r3?arch=x86_64&distro=wolfi&origin=openjdk-21",
+		Metadata: pkg.ApkDBEntry{
+			Package:       "openjdk-21-jre",
+			OriginPackage: "openjdk-21",
+			Version:       "21.0.3-r3",
+			Checksum:      dataHash,
+			GitCommit:     "0123456789abcdef0123456789abcdef01234567",
+		},
+	}
+	apk.SetID()
+
+	jar := pkg.Package{
+		Name:    "jrt-fs",
+		Version: "21.0.3",
+		Type:    pkg.JavaPkg,
+		PURL:    "pkg:maven/jrt-fs/jrt-fs@21.0.3",
+		Metadata: pkg.JavaArchive{
+			ArchiveDigests: []file.Digest{{Algorithm: "sha1", Value: "da39a3ee5e6b4b0d3255bfef95601890afd80709"}},
+		},
+	}
+	jar.SetID()
+
+	s := &sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			Packages: pkg.NewCollection(apk, jar),
+		},
+		Source: source.Description{
+			Name:    "openjdk-21-jre-21.0.3-r3.apk",
+			Version: "21.0.3-r3",
+		},
+	}
+
+	r, err := ToCycloneDXJSON(s, CycloneDXOptions{ToolVersion: "v1.2.3"})
+	require.NoError(t, err)
+
+	bom := new(cyclonedx.BOM)
+	require.NoError(t, cyclonedx.NewBOMDecoder(r, cyclonedx.BOMFileFormatJSON).Decode(bom))
+
+	assert.Equal(t, CycloneDXSpecVersion, bom.SpecVersion)
+
+	require.NotNil(t, bom.Metadata)
+	require.NotNil(t, bom.Metadata.Tools)
+	require.NotNil(t, bom.Metadata.Tools.Components)
+	assert.Equal(t, "wolfictl", (*bom.Metadata.Tools.Components)[0].Name)
+	assert.Equal(t, "v1.2.3", (*bom.Metadata.Tools.Components)[0].Version)
+
+	components := make(map[string]cyclonedx.Component)
+	require.NotNil(t, bom.Components)
+	for _, c := range *bom.Components {
+		components[c.Name] = c
+	}
+
+	apkComponent, ok := components["openjdk-21-jre"]
+	require.True(t, ok)
+	assert.Equal(t, apk.PURL, apkComponent.PackageURL)
+
+	require.NotNil(t, apkComponent.Hashes)
+	assert.Equal(t, []cyclonedx.Hash{{Algorithm: cyclonedx.HashAlgoSHA256, Value: dataHash}}, *apkComponent.Hashes)
+
+	require.NotNil(t, apkComponent.Pedigree)
+	require.NotNil(t, apkComponent.Pedigree.Ancestors)
+	ancestors := *apkComponent.Pedigree.Ancestors
+	require.Len(t, ancestors, 1)
+	assert.Equal(t, "openjdk-21", ancestors[0].Name)
+	assert.Equal(t, "pkg:apk/wolfi/openjdk-21@21.0.3-r3?arch=x86_64&distro=wolfi", ancestors[0].PackageURL)
+
+	require.NotNil(t, apkComponent.Pedigree.Commits)
+	if diff := cmp.Diff([]cyclonedx.Commit{{UID: "0123456789abcdef0123456789abcdef01234567"}}, *apkComponent.Pedigree.Commits); diff != "" {
+		t.Errorf("unexpected commits (-want +got):\n%s", diff)
+	}
+
+	jarComponent, ok := components["jrt-fs"]
+	require.True(t, ok)
+	assert.Equal(t, jar.PURL, jarComponent.PackageURL)
+	assert.Nil(t, jarComponent.Pedigree)
+	require.NotNil(t, jarComponent.Hashes)
+	assert.Equal(t, []cyclonedx.Hash{{Algorithm: cyclonedx.HashAlgoSHA1, Value: "da39a3ee5e6b4b0d3255bfef95601890afd80709"}}, *jarComponent.Hashes)
+}
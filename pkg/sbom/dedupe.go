@@ -0,0 +1,148 @@
+package sbom
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/cpe"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/chainguard-dev/clog"
+)
+
+// Deduplicate collapses the packages in the SBOM that have the same package URL
+// into a single canonical package. This happens when more than one cataloger
+// detects the same package (e.g. a Python package found by both its installed
+// metadata and a requirements file), and without it, each vulnerability in the
+// package would be counted once per duplicate.
+//
+// The canonical package is the APK package, if it's one of the duplicates, and
+// otherwise the first of the duplicates in Syft's sort order. It keeps its own
+// ID and metadata, and gains the evidence of the others: their locations,
+// licenses, and CPEs, and the names of the catalogers that found them.
+// Relationships to or from the other packages are moved to the canonical
+// package. Packages without a package URL are left as they are. It returns the
+// number of packages removed.
+func Deduplicate(ctx context.Context, s *sbom.SBOM) int {
+	log := clog.FromContext(ctx)
+
+	byPURL := make(map[string][]pkg.Package)
+	for _, p := range s.Artifacts.Packages.Sorted() {
+		if p.PURL == "" {
+			continue
+		}
+		byPURL[p.PURL] = append(byPURL[p.PURL], p)
+	}
+
+	replacements := make(map[artifact.ID]pkg.Package)
+	for purl, duplicates := range byPURL {
+		if len(duplicates) < 2 {
+			continue
+		}
+
+		slices.SortStableFunc(duplicates, func(a, b pkg.Package) int {
+			// The APK package comes first, so that it stays the only APK package in the
+			// SBOM.
+			return cmp.Compare(canonicalRank(a), canonicalRank(b))
+		})
+
+		canonical := duplicates[0]
+		for _, p := range duplicates[1:] {
+			mergePackage(&canonical, p)
+			s.Artifacts.Packages.Delete(p.ID())
+			log.Debug("removed duplicate package", "purl", purl, "foundBy", p.FoundBy, "id", string(p.ID()))
+		}
+
+		// The canonical package's ID doesn't change, so deleting and re-adding it
+		// replaces it in the collection.
+		s.Artifacts.Packages.Delete(canonical.ID())
+		s.Artifacts.Packages.Add(canonical)
+
+		for _, p := range duplicates {
+			replacements[p.ID()] = canonical
+		}
+	}
+
+	if len(replacements) == 0 {
+		return 0
+	}
+
+	s.Relationships = replaceRelationshipPackages(s.Relationships, replacements)
+
+	removed := 0
+	for id, p := range replacements {
+		if id != p.ID() {
+			removed++
+		}
+	}
+	log.Info("deduplicated SBOM packages", "removedCount", removed)
+
+	return removed
+}
+
+func canonicalRank(p pkg.Package) int {
+	if p.Type == pkg.ApkPkg {
+		return 0
+	}
+	return 1
+}
+
+// mergePackage adds the evidence for the duplicate package to the canonical
+// package.
+func mergePackage(canonical *pkg.Package, duplicate pkg.Package) {
+	canonical.Locations.Add(duplicate.Locations.ToSlice()...)
+	canonical.Licenses.Add(duplicate.Licenses.ToSlice()...)
+	canonical.CPEs = cpe.Merge(canonical.CPEs, duplicate.CPEs)
+
+	foundBy := strings.Split(canonical.FoundBy, ",")
+	for _, f := range strings.Split(duplicate.FoundBy, ",") {
+		if f != "" && !slices.Contains(foundBy, f) {
+			foundBy = append(foundBy, f)
+		}
+	}
+	canonical.FoundBy = strings.Join(slices.DeleteFunc(foundBy, func(f string) bool { return f == "" }), ",")
+}
+
+// replaceRelationshipPackages returns the relationships with the packages
+// replaced according to replacements, without the relationships that become
+// duplicates or relate a package to itself as a result.
+func replaceRelationshipPackages(relationships []artifact.Relationship, replacements map[artifact.ID]pkg.Package) []artifact.Relationship {
+	type key struct {
+		from, to artifact.ID
+		typ      artifact.RelationshipType
+	}
+	seen := make(map[key]bool)
+
+	result := make([]artifact.Relationship, 0, len(relationships))
+	for _, r := range relationships {
+		from, fromReplaced := replacements[r.From.ID()]
+		to, toReplaced := replacements[r.To.ID()]
+		if !fromReplaced && !toReplaced {
+			seen[key{from: r.From.ID(), to: r.To.ID(), typ: r.Type}] = true
+			result = append(result, r)
+			continue
+		}
+		if fromReplaced {
+			r.From = from
+		}
+		if toReplaced {
+			r.To = to
+		}
+
+		if r.From.ID() == r.To.ID() {
+			continue
+		}
+		k := key{from: r.From.ID(), to: r.To.ID(), typ: r.Type}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		result = append(result, r)
+	}
+
+	return result
+}
@@ -0,0 +1,104 @@
+package sbom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/cpe"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplicate(t *testing.T) {
+	ctx := context.Background()
+
+	apk := pkg.Package{
+		Name:      "py3-requests",
+		Version:   "2.32.3-r0",
+		Type:      pkg.ApkPkg,
+		PURL:      "pkg:apk/wolfi/py3-requests@2.32.3-r0?arch=x86_64&distro=wolfi",
+		Locations: file.NewLocationSet(file.NewLocation(pkginfoPath)),
+	}
+	apk.SetID()
+
+	installed := pkg.Package{
+		Name:      "requests",
+		Version:   "2.32.3",
+		Type:      pkg.PythonPkg,
+		FoundBy:   "python-installed-package-cataloger",
+		PURL:      "pkg:pypi/requests@2.32.3",
+		Locations: file.NewLocationSet(file.NewLocation("/usr/lib/python3.12/site-packages/requests-2.32.3.dist-info/METADATA")),
+		Licenses:  pkg.NewLicenseSet(pkg.NewLicense("Apache-2.0")),
+		CPEs:      []cpe.CPE{cpe.Must("cpe:2.3:a:python:requests:2.32.3:*:*:*:*:*:*:*", cpe.GeneratedSource)},
+	}
+	installed.SetID()
+
+	declared := pkg.Package{
+		Name:      "requests",
+		Version:   "2.32.3",
+		Type:      pkg.PythonPkg,
+		FoundBy:   "python-package-cataloger",
+		PURL:      "pkg:pypi/requests@2.32.3",
+		Locations: file.NewLocationSet(file.NewLocation("/usr/share/py3-requests/requirements.txt")),
+		CPEs:      []cpe.CPE{cpe.Must("cpe:2.3:a:psf:requests:2.32.3:*:*:*:*:*:*:*", cpe.GeneratedSource)},
+	}
+	declared.SetID()
+	require.NotEqual(t, installed.ID(), declared.ID())
+
+	other := pkg.Package{
+		Name:      "idna",
+		Version:   "3.7",
+		Type:      pkg.PythonPkg,
+		FoundBy:   "python-installed-package-cataloger",
+		PURL:      "pkg:pypi/idna@3.7",
+		Locations: file.NewLocationSet(file.NewLocation("/usr/lib/python3.12/site-packages/idna-3.7.dist-info/METADATA")),
+	}
+	other.SetID()
+
+	s := &sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			Packages: pkg.NewCollection(apk, installed, declared, other),
+		},
+		Relationships: []artifact.Relationship{
+			{From: installed, To: apk, Type: artifact.DependencyOfRelationship},
+			{From: declared, To: apk, Type: artifact.DependencyOfRelationship},
+			{From: other, To: declared, Type: artifact.DependencyOfRelationship},
+			{From: installed, To: declared, Type: artifact.DependencyOfRelationship},
+		},
+	}
+
+	removed := Deduplicate(ctx, s)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, 3, s.Artifacts.Packages.PackageCount())
+
+	requests := s.Artifacts.Packages.PackagesByName("requests")
+	require.Len(t, requests, 1)
+	canonical := requests[0]
+
+	assert.Equal(t, "python-installed-package-cataloger,python-package-cataloger", canonical.FoundBy)
+	assert.Len(t, canonical.Locations.ToSlice(), 2)
+	assert.Len(t, canonical.CPEs, 2)
+	assert.Len(t, canonical.Licenses.ToSlice(), 1)
+
+	var got []string
+	for _, r := range s.Relationships {
+		from := s.Artifacts.Packages.Package(r.From.ID())
+		to := s.Artifacts.Packages.Package(r.To.ID())
+		require.NotNil(t, from, "relationship from a removed package")
+		require.NotNil(t, to, "relationship to a removed package")
+		got = append(got, from.Name+" "+string(r.Type)+" "+to.Name)
+	}
+	assert.Equal(t, []string{
+		"requests dependency-of py3-requests",
+		"idna dependency-of requests",
+	}, got)
+
+	t.Run("no duplicates", func(t *testing.T) {
+		assert.Zero(t, Deduplicate(ctx, s))
+		assert.Equal(t, 3, s.Artifacts.Packages.PackageCount())
+	})
+}
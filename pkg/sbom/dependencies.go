@@ -0,0 +1,74 @@
+package sbom
+
+import (
+	"context"
+	"strings"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/chainguard-dev/clog"
+)
+
+// FoundByAPKDependencies is the "found by" value of the packages that represent
+// an APK's runtime dependencies (see GenerateOptions.IncludeDependencies).
+const FoundByAPKDependencies = "wolfictl-apk-dependencies"
+
+// apkDependencies returns a package for each of the runtime dependencies
+// declared in the APK's metadata, and "dependency of" relationships from each of
+// them to the APK package.
+//
+// Only dependencies on packages by name are included. Dependencies on virtual
+// packages (e.g. "so:libc.so.6" or "cmd:sh") name a capability rather than a
+// package, and resolving them requires the repository's index, so they're
+// skipped, as are conflicts (e.g. "!foo").
+//
+// The packages have an "apk" package URL, but their type is "unknown", so that
+// the APK package remains the only APK package in the SBOM, and so that they're
+// not matched against vulnerability data: a dependency's version isn't known
+// unless it's pinned.
+func apkDependencies(ctx context.Context, apkPackage pkg.Package, distroID string) ([]pkg.Package, []artifact.Relationship) {
+	log := clog.FromContext(ctx)
+
+	metadata, ok := apkPackage.Metadata.(pkg.ApkDBEntry)
+	if !ok {
+		return nil, nil
+	}
+
+	var (
+		packages      []pkg.Package
+		relationships []artifact.Relationship
+	)
+	seen := make(map[string]bool)
+	for _, spec := range metadata.Dependencies {
+		if strings.HasPrefix(spec, "!") || strings.Contains(spec, ":") {
+			log.Debug("skipping APK dependency that isn't on a package by name", "dependency", spec)
+			continue
+		}
+
+		name, version := parsePackageSpec(spec)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		p := pkg.Package{
+			Name:      name,
+			Version:   version,
+			FoundBy:   FoundByAPKDependencies,
+			Locations: file.NewLocationSet(file.NewLocation(pkginfoPath)),
+			Type:      pkg.UnknownPkg,
+			PURL:      apkPURL(distroID, name, version, metadata.Architecture, ""),
+		}
+		p.SetID()
+
+		packages = append(packages, p)
+		relationships = append(relationships, artifact.Relationship{
+			From: p,
+			To:   apkPackage,
+			Type: artifact.DependencyOfRelationship,
+		})
+	}
+
+	return packages, relationships
+}
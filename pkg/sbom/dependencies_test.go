@@ -0,0 +1,107 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPKDependencies(t *testing.T) {
+	ctx := context.Background()
+
+	apk := pkg.Package{
+		Name:      "crane",
+		Version:   "0.19.1-r6",
+		Type:      pkg.ApkPkg,
+		Locations: file.NewLocationSet(file.NewLocation(pkginfoPath)),
+		Metadata: pkg.ApkDBEntry{
+			Package:      "crane",
+			Version:      "0.19.1-r6",
+			Architecture: "x86_64",
+			Dependencies: []string{
+				"ca-certificates-bundle",
+				"so:libc.so.6",
+				"cmd:sh",
+				"glibc=2.39-r5",
+				"!crane-compat",
+				"ca-certificates-bundle",
+			},
+		},
+	}
+	apk.SetID()
+
+	dependencies, relationships := apkDependencies(ctx, apk, "wolfi")
+
+	var purls []string
+	for _, p := range dependencies {
+		assert.Equal(t, pkg.UnknownPkg, p.Type)
+		assert.Equal(t, FoundByAPKDependencies, p.FoundBy)
+		purls = append(purls, p.PURL)
+	}
+	assert.Equal(t, []string{
+		"pkg:apk/wolfi/ca-certificates-bundle?arch=x86_64&distro=wolfi",
+		"pkg:apk/wolfi/glibc@2.39-r5?arch=x86_64&distro=wolfi",
+	}, purls)
+
+	require.Len(t, relationships, 2)
+	for i, r := range relationships {
+		assert.Equal(t, dependencies[i].ID(), r.From.ID())
+		assert.Equal(t, apk.ID(), r.To.ID())
+		assert.Equal(t, artifact.DependencyOfRelationship, r.Type)
+	}
+
+	t.Run("encoded as SPDX", func(t *testing.T) {
+		s := &sbom.SBOM{
+			Artifacts: sbom.Artifacts{
+				Packages:          pkg.NewCollection(append(dependencies, apk)...),
+				LinuxDistribution: &linux.Release{ID: "wolfi"},
+			},
+			Relationships: relationships,
+		}
+
+		r, err := ToSPDXJSON(s, SPDXOptions{})
+		require.NoError(t, err)
+
+		var doc struct {
+			Packages []struct {
+				SPDXID string `json:"SPDXID"`
+				Name   string `json:"name"`
+			} `json:"packages"`
+			Relationships []struct {
+				Element      string `json:"spdxElementId"`
+				Related      string `json:"relatedSpdxElement"`
+				Relationship string `json:"relationshipType"`
+			} `json:"relationships"`
+		}
+		require.NoError(t, json.NewDecoder(r).Decode(&doc))
+
+		names := make(map[string]string)
+		for _, p := range doc.Packages {
+			names[p.SPDXID] = p.Name
+		}
+
+		var got []string
+		for _, r := range doc.Relationships {
+			// Only relationships between packages are of interest here.
+			if names[r.Element] == "" || names[r.Related] == "" {
+				continue
+			}
+			got = append(got, names[r.Element]+" "+r.Relationship+" "+names[r.Related])
+		}
+
+		// The dependencies aren't in the APK, so there are no "contains" relationships
+		// to them.
+		assert.ElementsMatch(t, []string{
+			"crane DEPENDS_ON ca-certificates-bundle",
+			"crane DEPENDS_ON glibc",
+		}, got)
+	})
+}
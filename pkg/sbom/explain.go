@@ -0,0 +1,190 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// Evidence is how a component of an SBOM was detected: the catalogers that
+// found it, the files they found it in, and the identifiers generated for it.
+type Evidence struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Type    string `json:"type"`
+
+	// FoundBy are the names of the catalogers that found the component (more than
+	// one if duplicates were collapsed, see Deduplicate).
+	FoundBy []string `json:"foundBy"`
+
+	// Locations are the files that the component was found in.
+	Locations []EvidenceLocation `json:"locations"`
+
+	PURL string        `json:"purl,omitempty"`
+	CPEs []EvidenceCPE `json:"cpes,omitempty"`
+
+	Licenses []string `json:"licenses,omitempty"`
+
+	// MetadataType is the Go type of the cataloger's metadata for the component,
+	// e.g. "pkg.GolangBinaryBuildinfoEntry".
+	MetadataType string `json:"metadataType,omitempty"`
+
+	// Metadata is the cataloger's metadata for the component, which is the data
+	// that its version and identifiers were derived from.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+
+	// Relationships describe the component's relationships to other packages in
+	// the SBOM, e.g. "dependency-of crane@0.19.1-r6 (apk)".
+	Relationships []string `json:"relationships,omitempty"`
+}
+
+// EvidenceLocation is a file that a component was found in.
+type EvidenceLocation struct {
+	Path string `json:"path"`
+
+	// AccessPath is the path by which the file was found, if it's different from
+	// Path (e.g. because of a symlink).
+	AccessPath string `json:"accessPath,omitempty"`
+
+	// Evidence is Syft's annotation of the role of the file in the detection:
+	// "primary" or "supporting".
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// EvidenceCPE is a CPE generated for a component.
+type EvidenceCPE struct {
+	CPE string `json:"cpe"`
+
+	// Source is where the CPE came from, e.g. "nvd-cpe-dictionary" or
+	// "syft-generated".
+	Source string `json:"source,omitempty"`
+}
+
+// Explain returns the evidence for each component of the SBOM that matches the
+// query, which is either a package URL (whose version is optional) or a
+// component name (which can be a glob pattern). It returns an error if the query
+// matches no components.
+func Explain(s *sbom.SBOM, query string) ([]Evidence, error) {
+	matcher := ComponentMatcher{Name: query}
+	if strings.HasPrefix(query, "pkg:") {
+		matcher = ComponentMatcher{PURL: query}
+	}
+	if err := matcher.validate(); err != nil {
+		return nil, err
+	}
+
+	var result []Evidence
+	for _, p := range s.Artifacts.Packages.Sorted() {
+		if !matcher.Matches(p) {
+			continue
+		}
+
+		e, err := evidenceOf(s, p)
+		if err != nil {
+			return nil, fmt.Errorf("explaining component %q: %w", packageDisplayName(p), err)
+		}
+		result = append(result, *e)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no component matching %q found in SBOM", query)
+	}
+
+	return result, nil
+}
+
+func evidenceOf(s *sbom.SBOM, p pkg.Package) (*Evidence, error) {
+	e := &Evidence{
+		Name:    p.Name,
+		Version: p.Version,
+		Type:    string(p.Type),
+		PURL:    p.PURL,
+	}
+
+	for _, f := range strings.Split(p.FoundBy, ",") {
+		if f != "" {
+			e.FoundBy = append(e.FoundBy, f)
+		}
+	}
+
+	for _, l := range p.Locations.ToSlice() {
+		el := EvidenceLocation{
+			Path:     l.RealPath,
+			Evidence: l.Annotations[pkg.EvidenceAnnotationKey],
+		}
+		if l.AccessPath != l.RealPath {
+			el.AccessPath = l.AccessPath
+		}
+		e.Locations = append(e.Locations, el)
+	}
+
+	for _, c := range p.CPEs {
+		e.CPEs = append(e.CPEs, EvidenceCPE{CPE: c.Attributes.String(), Source: c.Source.String()})
+	}
+
+	for _, l := range p.Licenses.ToSlice() {
+		e.Licenses = append(e.Licenses, l.Value)
+	}
+
+	if p.Metadata != nil {
+		metadata := p.Metadata
+		if m, ok := metadata.(pkg.ApkDBEntry); ok {
+			// The APK's file list isn't evidence for the APK, and it can be very long.
+			m.Files = nil
+			metadata = m
+		}
+
+		b, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("encoding metadata: %w", err)
+		}
+		e.MetadataType = fmt.Sprintf("%T", p.Metadata)
+		e.Metadata = b
+	}
+
+	e.Relationships = relationshipsOf(s, p.ID())
+
+	return e, nil
+}
+
+// relationshipsOf describes the relationships of the package with the given ID
+// to the other packages in the SBOM.
+func relationshipsOf(s *sbom.SBOM, id artifact.ID) []string {
+	describe := func(other artifact.ID) string {
+		p := s.Artifacts.Packages.Package(other)
+		if p == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s (%s)", packageNameVersion(*p), p.Type)
+	}
+
+	var result []string
+	for _, r := range s.Relationships {
+		switch id {
+		case r.From.ID():
+			if d := describe(r.To.ID()); d != "" {
+				result = append(result, fmt.Sprintf("%s %s", r.Type, d))
+			}
+
+		case r.To.ID():
+			if d := describe(r.From.ID()); d != "" {
+				result = append(result, fmt.Sprintf("%s %s it", d, r.Type))
+			}
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func packageNameVersion(p pkg.Package) string {
+	if p.Version == "" {
+		return p.Name
+	}
+	return p.Name + "@" + p.Version
+}
@@ -0,0 +1,101 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/cpe"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	apk := pkg.Package{
+		Name:      "crane",
+		Version:   "0.19.1-r6",
+		Type:      pkg.ApkPkg,
+		FoundBy:   "wolfictl",
+		PURL:      "pkg:apk/wolfi/crane@0.19.1-r6?arch=x86_64&distro=wolfi",
+		Locations: file.NewLocationSet(file.NewLocation(pkginfoPath)),
+		Metadata: pkg.ApkDBEntry{
+			Package: "crane",
+			Files:   []pkg.ApkFileRecord{{Path: "usr/bin/crane"}},
+		},
+	}
+	apk.SetID()
+
+	cobra := pkg.Package{
+		Name:    "github.com/spf13/cobra",
+		Version: "v1.8.1",
+		Type:    pkg.GoModulePkg,
+		FoundBy: "go-module-binary-cataloger",
+		PURL:    "pkg:golang/github.com/spf13/cobra@v1.8.1",
+		Locations: file.NewLocationSet(
+			file.NewVirtualLocation("usr/bin/crane", "/usr/local/bin/crane").WithAnnotation(pkg.EvidenceAnnotationKey, pkg.PrimaryEvidenceAnnotation),
+		),
+		CPEs: []cpe.CPE{cpe.Must("cpe:2.3:a:spf13:cobra:v1.8.1:*:*:*:*:*:*:*", cpe.GeneratedSource)},
+		Metadata: pkg.GolangBinaryBuildinfoEntry{
+			GoCompiledVersion: "go1.22.5",
+			MainModule:        "github.com/google/go-containerregistry",
+		},
+	}
+	cobra.SetID()
+
+	s := &sbom.SBOM{
+		Artifacts: sbom.Artifacts{Packages: pkg.NewCollection(apk, cobra)},
+		Relationships: []artifact.Relationship{
+			{From: cobra, To: apk, Type: artifact.DependencyOfRelationship},
+		},
+	}
+
+	t.Run("by purl", func(t *testing.T) {
+		evidence, err := Explain(s, "pkg:golang/github.com/spf13/cobra")
+		require.NoError(t, err)
+		require.Len(t, evidence, 1)
+		e := evidence[0]
+
+		assert.Equal(t, "github.com/spf13/cobra", e.Name)
+		assert.Equal(t, []string{"go-module-binary-cataloger"}, e.FoundBy)
+		assert.Equal(t, []EvidenceLocation{{
+			Path:       "usr/bin/crane",
+			AccessPath: "/usr/local/bin/crane",
+			Evidence:   pkg.PrimaryEvidenceAnnotation,
+		}}, e.Locations)
+		assert.Equal(t, []EvidenceCPE{{
+			CPE:    "cpe:2.3:a:spf13:cobra:v1.8.1:*:*:*:*:*:*:*",
+			Source: string(cpe.GeneratedSource),
+		}}, e.CPEs)
+		assert.Equal(t, "pkg.GolangBinaryBuildinfoEntry", e.MetadataType)
+		assert.Contains(t, string(e.Metadata), `"mainModule":"github.com/google/go-containerregistry"`)
+		assert.Equal(t, []string{"dependency-of crane@0.19.1-r6 (apk)"}, e.Relationships)
+	})
+
+	t.Run("by name", func(t *testing.T) {
+		evidence, err := Explain(s, "crane")
+		require.NoError(t, err)
+		require.Len(t, evidence, 1)
+		e := evidence[0]
+
+		assert.Equal(t, []string{"github.com/spf13/cobra@v1.8.1 (go-module) dependency-of it"}, e.Relationships)
+
+		var metadata pkg.ApkDBEntry
+		require.NoError(t, json.Unmarshal(e.Metadata, &metadata))
+		assert.Equal(t, "crane", metadata.Package)
+		assert.Empty(t, metadata.Files, "the APK's files should be omitted")
+	})
+
+	t.Run("by name pattern", func(t *testing.T) {
+		evidence, err := Explain(s, "github.com/*/*")
+		require.NoError(t, err)
+		assert.Len(t, evidence, 1)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := Explain(s, "pkg:golang/github.com/spf13/cobra@v1.9.0")
+		assert.ErrorContains(t, err, "no component matching")
+	})
+}
@@ -0,0 +1,85 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/pkg"
+)
+
+// fileDigests returns the sha256 digests of the regular files among the given
+// files (relative to root). Other files, such as directories and symlinks,
+// aren't included.
+func fileDigests(root string, includedFiles []string) (map[file.Coordinates][]file.Digest, error) {
+	digests := make(map[file.Coordinates][]file.Digest)
+
+	for _, f := range includedFiles {
+		p := filepath.Join(root, f)
+
+		// Use Lstat, so that symlinks (which may point outside of the root) aren't
+		// followed.
+		info, err := os.Lstat(p)
+		if err != nil {
+			return nil, fmt.Errorf("stat %q: %w", f, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		digest, err := sha256File(p)
+		if err != nil {
+			return nil, fmt.Errorf("hashing %q: %w", f, err)
+		}
+
+		digests[file.NewCoordinates(f, "")] = []file.Digest{
+			{Algorithm: "sha256", Value: digest},
+		}
+	}
+
+	return digests, nil
+}
+
+func sha256File(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// fileOwnershipRelationships returns "contains" relationships from the given
+// package to each of the files with the given digests, in a deterministic order.
+func fileOwnershipRelationships(owner pkg.Package, digests map[file.Coordinates][]file.Digest) []artifact.Relationship {
+	coordinates := make([]file.Coordinates, 0, len(digests))
+	for c := range digests {
+		coordinates = append(coordinates, c)
+	}
+	slices.SortFunc(coordinates, func(a, b file.Coordinates) int {
+		return strings.Compare(a.RealPath, b.RealPath)
+	})
+
+	relationships := make([]artifact.Relationship, 0, len(coordinates))
+	for _, c := range coordinates {
+		relationships = append(relationships, artifact.Relationship{
+			From: owner,
+			To:   c,
+			Type: artifact.ContainsRelationship,
+		})
+	}
+
+	return relationships
+}
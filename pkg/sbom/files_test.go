@@ -0,0 +1,71 @@
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileDigests(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "usr", "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "usr", "bin", "crane"), []byte("test"), 0o600))
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(root, "usr", "bin", "link")))
+
+	digests, err := fileDigests(root, []string{".", "usr", "usr/bin", "usr/bin/crane", "usr/bin/link"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[file.Coordinates][]file.Digest{
+		file.NewCoordinates("usr/bin/crane", ""): {
+			{Algorithm: "sha256", Value: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+		},
+	}, digests)
+
+	apk := pkg.Package{Name: "crane", Version: "0.19.1-r6", Type: pkg.ApkPkg}
+	apk.SetID()
+
+	relationships := fileOwnershipRelationships(apk, digests)
+	require.Len(t, relationships, 1)
+	assert.Equal(t, apk.ID(), relationships[0].From.ID())
+	assert.Equal(t, file.NewCoordinates("usr/bin/crane", ""), relationships[0].To)
+	assert.Equal(t, artifact.ContainsRelationship, relationships[0].Type)
+
+	t.Run("encoded as Syft JSON", func(t *testing.T) {
+		s := &sbom.SBOM{
+			Artifacts: sbom.Artifacts{
+				Packages:    pkg.NewCollection(apk),
+				FileDigests: digests,
+			},
+			Relationships: relationships,
+		}
+
+		r, err := ToSyftJSON(s)
+		require.NoError(t, err)
+
+		var doc struct {
+			Files []struct {
+				Location struct {
+					Path string `json:"path"`
+				} `json:"location"`
+				Digests []struct {
+					Algorithm string `json:"algorithm"`
+					Value     string `json:"value"`
+				} `json:"digests"`
+			} `json:"files"`
+		}
+		require.NoError(t, json.NewDecoder(r).Decode(&doc))
+
+		require.Len(t, doc.Files, 1)
+		assert.Equal(t, "usr/bin/crane", doc.Files[0].Location.Path)
+		require.Len(t, doc.Files[0].Digests, 1)
+		assert.Equal(t, "sha256", doc.Files[0].Digests[0].Algorithm)
+	})
+}
@@ -0,0 +1,95 @@
+package sbom
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/sbom/catalogers"
+)
+
+func TestNewCreateSBOMConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg := newCreateSBOMConfig(GenerateOptions{})
+
+		assert.Equal(t, DefaultDisabledCatalogers, cfg.CatalogerSelection.RemoveNamesOrTags)
+		assert.Empty(t, cfg.CatalogerSelection.AddNames)
+		assert.True(t, cfg.Relationships.ExcludeBinaryPackagesWithFileOwnershipOverlap)
+	})
+
+	t.Run("enable and disable", func(t *testing.T) {
+		cfg := newCreateSBOMConfig(GenerateOptions{
+			EnableCatalogers:  []string{"elf-binary-package-cataloger", binaryClassifierCataloger},
+			DisableCatalogers: []string{"java"},
+		})
+
+		assert.Equal(t, []string{"sbom-cataloger", "melange-source-cataloger", "java"}, cfg.CatalogerSelection.RemoveNamesOrTags)
+		assert.Equal(t, []string{"elf-binary-package-cataloger", binaryClassifierCataloger}, cfg.CatalogerSelection.AddNames)
+		assert.False(t, cfg.Relationships.ExcludeBinaryPackagesWithFileOwnershipOverlap, "binary packages should be kept")
+	})
+}
+
+func TestGenerateWithOptionsMelangeSources(t *testing.T) {
+	apk := testAPK(t, map[string]string{
+		pkginfoPath: `pkgname = crane
+pkgver = 0.19.1-r6
+arch = x86_64
+origin = crane
+license = Apache-2.0
+`,
+		melangeConfigurationPath: `package:
+  name: crane
+  version: 0.19.1
+  epoch: 6
+
+pipeline:
+  - uses: git-checkout
+    with:
+      repository: https://github.com/google/go-containerregistry
+      tag: v0.19.1
+      expected-commit: 1b4e4078a545f2b6f0f0e6d4e7a3e4b5c3b2a1d0
+`,
+	})
+
+	sources := func(opts GenerateOptions) []string {
+		s, err := GenerateWithOptions(context.Background(), "crane-0.19.1-r6.apk", bytes.NewReader(apk), "wolfi", opts)
+		require.NoError(t, err)
+
+		var purls []string
+		for _, p := range s.Artifacts.Packages.Sorted(pkg.UnknownPkg) {
+			if p.FoundBy == (catalogers.MelangeSources{}).Name() {
+				purls = append(purls, p.PURL)
+			}
+		}
+		return purls
+	}
+
+	assert.Empty(t, sources(GenerateOptions{}), "the cataloger should be disabled by default")
+
+	assert.Equal(t, []string{
+		"pkg:github/google/go-containerregistry@1b4e4078a545f2b6f0f0e6d4e7a3e4b5c3b2a1d0",
+	}, sources(GenerateOptions{EnableCatalogers: []string{"melange-source-cataloger"}}))
+}
+
+// testAPK returns a minimal APK (a gzipped tarball) with the given files.
+func testAPK(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(zw)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
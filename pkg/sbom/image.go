@@ -0,0 +1,191 @@
+package sbom
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	"chainguard.dev/apko/pkg/apk/auth"
+	"chainguard.dev/apko/pkg/build/types"
+	"github.com/anchore/stereoscope"
+	"github.com/anchore/stereoscope/pkg/file"
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/chainguard-dev/clog"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	installedDBPath  = "/lib/apk/db/installed"
+	repositoriesPath = "/etc/apk/repositories"
+)
+
+// ImageAPKs is the set of APKs installed in a container image.
+type ImageAPKs struct {
+	// Packages are the packages listed in the image's APK installed database.
+	Packages []*apk.Package
+
+	// Repositories are the APK repositories configured in the image (in its
+	// "etc/apk/repositories" file), from which the packages can be downloaded.
+	Repositories []string
+}
+
+// APKsFromImage reads the set of APKs installed in the container image with the
+// given reference, which is pulled from its registry. For a multi-platform
+// image, the image for the given APK architecture (e.g. "x86_64") is used.
+func APKsFromImage(ctx context.Context, ref, arch string) (*ImageAPKs, error) {
+	log := clog.FromContext(ctx)
+
+	platform := types.ParseArchitecture(arch).ToOCIPlatform().String()
+	log.Info("reading installed APKs from image", "ref", ref, "platform", platform)
+
+	img, err := stereoscope.GetImageFromSource(ctx, ref, image.OciRegistrySource, stereoscope.WithPlatform(platform))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get image %q: %w", ref, err)
+	}
+	defer func() {
+		if err := img.Cleanup(); err != nil {
+			log.Warn("failed to clean up image", "ref", ref, "error", err)
+		}
+	}()
+
+	installed, err := img.OpenPathFromSquash(file.Path(installedDBPath))
+	if err != nil {
+		return nil, fmt.Errorf("opening %q in image %q (is it an APK-based image?): %w", installedDBPath, ref, err)
+	}
+	defer installed.Close()
+
+	packages, err := apk.ParsePackageIndex(installed)
+	if err != nil {
+		return nil, fmt.Errorf("parsing installed packages: %w", err)
+	}
+
+	var repositories []string
+	if r, err := img.OpenPathFromSquash(file.Path(repositoriesPath)); err == nil {
+		defer r.Close()
+
+		repositories, err = parseRepositories(r)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q: %w", repositoriesPath, err)
+		}
+	} else {
+		log.Debug("image has no APK repositories file", "path", repositoriesPath, "error", err)
+	}
+
+	return &ImageAPKs{Packages: packages, Repositories: repositories}, nil
+}
+
+// parseRepositories parses the URLs of the repositories in an APK repositories
+// file. Comments, blank lines, and the "@tag" prefixes of tagged repositories
+// are ignored.
+func parseRepositories(r io.Reader) ([]string, error) {
+	var repositories []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "@") {
+			_, line, _ = strings.Cut(line, " ")
+			line = strings.TrimSpace(line)
+		}
+
+		repositories = append(repositories, strings.TrimSuffix(line, "/"))
+	}
+
+	return repositories, scanner.Err()
+}
+
+// DownloadAPKs downloads the given packages to dir, from the first of the given
+// APK repositories that has each package, and returns the paths of the
+// downloaded APK files, in the same order as the packages.
+func DownloadAPKs(ctx context.Context, packages []*apk.Package, repositories []string, dir string) ([]string, error) {
+	if len(repositories) == 0 {
+		return nil, errors.New("no APK repositories to download packages from")
+	}
+
+	paths := make([]string, len(packages))
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(8)
+	for i, p := range packages {
+		g.Go(func() error {
+			path, err := downloadAPK(ctx, p, repositories, dir)
+			if err != nil {
+				return fmt.Errorf("downloading %q: %w", p.Filename(), err)
+			}
+			paths[i] = path
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+func downloadAPK(ctx context.Context, p *apk.Package, repositories []string, dir string) (string, error) {
+	log := clog.FromContext(ctx)
+
+	for _, repository := range repositories {
+		downloadURL := fmt.Sprintf("%s/%s/%s", repository, p.Arch, p.Filename())
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+		if err != nil {
+			return "", fmt.Errorf("creating request for %q: %w", downloadURL, err)
+		}
+		if err := auth.DefaultAuthenticators.AddAuth(ctx, req); err != nil {
+			return "", fmt.Errorf("adding auth: %w", err)
+		}
+
+		log.Debug("downloading APK", "url", downloadURL)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("downloading %q: %w", downloadURL, err)
+		}
+
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return "", fmt.Errorf("downloading %q (status: %d)", downloadURL, resp.StatusCode)
+		}
+
+		path := filepath.Join(dir, p.Filename())
+		if err := writeResponse(resp, path); err != nil {
+			return "", fmt.Errorf("saving contents of %q: %w", downloadURL, err)
+		}
+
+		return path, nil
+	}
+
+	return "", fmt.Errorf("not found in any of the APK repositories (%s)", strings.Join(repositories, ", "))
+}
+
+func writeResponse(resp *http.Response, path string) error {
+	defer resp.Body.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
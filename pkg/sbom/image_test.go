@@ -0,0 +1,54 @@
+package sbom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepositories(t *testing.T) {
+	repositories, err := parseRepositories(strings.NewReader(`
+# The distro's repository.
+https://packages.wolfi.dev/os/
+
+@local /work/packages
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"https://packages.wolfi.dev/os", "/work/packages"}, repositories)
+}
+
+func TestDownloadAPKs(t *testing.T) {
+	// The first repository has no packages, and the second has only crane.
+	empty := httptest.NewServer(http.NotFoundHandler())
+	defer empty.Close()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/x86_64/crane-0.19.1-r6.apk", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("crane"))
+	})
+	repo := httptest.NewServer(mux)
+	defer repo.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	repositories := []string{empty.URL, repo.URL}
+
+	paths, err := DownloadAPKs(ctx, []*apk.Package{{Name: "crane", Version: "0.19.1-r6", Arch: "x86_64"}}, repositories, dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(dir, "crane-0.19.1-r6.apk")}, paths)
+	b, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, "crane", string(b))
+
+	_, err = DownloadAPKs(ctx, []*apk.Package{{Name: "git", Version: "2.45.1-r0", Arch: "x86_64"}}, repositories, dir)
+	assert.ErrorContains(t, err, `downloading "git-2.45.1-r0.apk": not found in any of the APK repositories`)
+}
@@ -0,0 +1,277 @@
+package sbom
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/license"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/chainguard-dev/clog"
+	"github.com/google/licensecheck"
+)
+
+// licenseFileCoverageThreshold is the minimum percentage of a license file's
+// text that must match known licenses for the file's licenses to be used. This
+// matches the threshold used by Syft.
+const licenseFileCoverageThreshold = 75
+
+// maxLicenseFileSize is the size of the largest license file that's scanned.
+const maxLicenseFileSize = 1 << 20
+
+// licenseAliases maps commonly used names and URLs of licenses (normalized with
+// normalizeLicenseAlias) to SPDX license expressions. These are the values
+// most often found in package metadata (e.g. Maven POMs and JAR manifests)
+// that aren't already SPDX expressions. Ambiguous names (e.g. "BSD") aren't
+// included.
+var licenseAliases = map[string]string{
+	"apache 2":                                 "Apache-2.0",
+	"apache 2.0":                               "Apache-2.0",
+	"apache license 2.0":                       "Apache-2.0",
+	"apache license v2":                        "Apache-2.0",
+	"apache license v2.0":                      "Apache-2.0",
+	"apache license version 2.0":               "Apache-2.0",
+	"apache license, version 2.0":              "Apache-2.0",
+	"apache software license 2.0":              "Apache-2.0",
+	"apache software license, version 2.0":     "Apache-2.0",
+	"the apache license, version 2.0":          "Apache-2.0",
+	"the apache software license, version 2.0": "Apache-2.0",
+	"asl 2.0":                            "Apache-2.0",
+	"apache.org/licenses/license-2.0":    "Apache-2.0",
+	"opensource.org/licenses/apache-2.0": "Apache-2.0",
+
+	"mit license":                             "MIT",
+	"the mit license":                         "MIT",
+	"the mit license (mit)":                   "MIT",
+	"opensource.org/licenses/mit":             "MIT",
+	"opensource.org/licenses/mit-license.php": "MIT",
+	"mit no attribution license":              "MIT-0",
+
+	"2-clause bsd license":                  "BSD-2-Clause",
+	"bsd 2-clause license":                  "BSD-2-Clause",
+	"3-clause bsd license":                  "BSD-3-Clause",
+	"bsd 3-clause license":                  "BSD-3-Clause",
+	"new bsd license":                       "BSD-3-Clause",
+	"the new bsd license":                   "BSD-3-Clause",
+	"modified bsd license":                  "BSD-3-Clause",
+	"revised bsd license":                   "BSD-3-Clause",
+	"eclipse distribution license - v 1.0":  "BSD-3-Clause",
+	"eclipse.org/org/documents/edl-v10.php": "BSD-3-Clause",
+
+	"eclipse public license 1.0":     "EPL-1.0",
+	"eclipse public license - v 1.0": "EPL-1.0",
+	"eclipse public license 2.0":     "EPL-2.0",
+	"eclipse public license - v 2.0": "EPL-2.0",
+	"eclipse.org/legal/epl-2.0":      "EPL-2.0",
+
+	"gplv2":                                "GPL-2.0-only",
+	"gplv2+":                               "GPL-2.0-or-later",
+	"gplv3":                                "GPL-3.0-only",
+	"gplv3+":                               "GPL-3.0-or-later",
+	"lgplv2.1":                             "LGPL-2.1-only",
+	"lgplv2.1+":                            "LGPL-2.1-or-later",
+	"lgplv3":                               "LGPL-3.0-only",
+	"lgplv3+":                              "LGPL-3.0-or-later",
+	"gnu general public license version 2": "GPL-2.0-only",
+	"gnu lesser general public license version 2.1":  "LGPL-2.1-only",
+	"gnu lesser general public license, version 2.1": "LGPL-2.1-only",
+	"gnu lesser general public license version 3":    "LGPL-3.0-only",
+	"gnu.org/software/classpath/license.html":        "GPL-2.0-only WITH Classpath-exception-2.0",
+	"oss.oracle.com/licenses/cddl+gpl-1.1":           "CDDL-1.1 OR GPL-2.0-only WITH Classpath-exception-2.0",
+
+	"mozilla public license 2.0":          "MPL-2.0",
+	"mozilla public license, version 2.0": "MPL-2.0",
+	"mozilla.org/mpl/2.0":                 "MPL-2.0",
+
+	"cc0 1.0 universal":                         "CC0-1.0",
+	"creativecommons.org/publicdomain/zero/1.0": "CC0-1.0",
+	"isc license":                               "ISC",
+	"python software foundation license":        "PSF-2.0",
+	"psfl":                                      "PSF-2.0",
+	"the unlicense":                             "Unlicense",
+}
+
+// normalizeLicenseAlias returns a canonical form of a license name or URL for
+// looking up in licenseAliases.
+func normalizeLicenseAlias(value string) string {
+	v := strings.ToLower(strings.TrimSpace(value))
+
+	// Maven POMs sometimes carry the license's URL in the same field, e.g.
+	// `"Apache-2.0";link="https://www.apache.org/licenses/LICENSE-2.0.txt"`.
+	v, _, _ = strings.Cut(v, ";link=")
+	v = strings.Trim(v, `"' `)
+
+	if u, ok := strings.CutPrefix(v, "https://"); ok {
+		v = u
+	} else if u, ok := strings.CutPrefix(v, "http://"); ok {
+		v = u
+	} else {
+		return v
+	}
+
+	v = strings.TrimPrefix(v, "www.")
+	v = strings.TrimSuffix(v, "/")
+	v = strings.TrimSuffix(v, ".txt")
+	v = strings.TrimSuffix(v, ".html")
+
+	// Some URLs differ from the aliases only in a file extension that we've
+	// removed.
+	if _, ok := licenseAliases[v+".html"]; ok {
+		return v + ".html"
+	}
+	if _, ok := licenseAliases[v+".php"]; ok {
+		return v + ".php"
+	}
+
+	return v
+}
+
+// normalizeLicenseExpression returns the SPDX license expression for the given
+// license value from package metadata, if one can be determined.
+//
+// Values that list multiple licenses separated by commas (such as OSGi
+// Bundle-License headers) are treated as alternatives, since that's how
+// multiple licenses are conventionally listed for dual-licensed projects.
+func normalizeLicenseExpression(value string) (string, bool) {
+	if ex, err := license.ParseExpression(strings.TrimSpace(value)); err == nil && ex != "" {
+		return ex, true
+	}
+
+	// Some license names contain commas themselves.
+	if ex, ok := licenseAliases[normalizeLicenseAlias(value)]; ok {
+		return ex, true
+	}
+
+	var expressions []string
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		ex, ok := licenseAliases[normalizeLicenseAlias(part)]
+		if !ok {
+			if ex2, err := license.ParseExpression(normalizeLicenseAlias(part)); err == nil && ex2 != "" {
+				ex, ok = ex2, true
+			}
+		}
+		if !ok {
+			return "", false
+		}
+
+		if !slices.Contains(expressions, ex) {
+			expressions = append(expressions, ex)
+		}
+	}
+
+	switch len(expressions) {
+	case 0:
+		return "", false
+
+	case 1:
+		return expressions[0], true
+	}
+
+	for i := range expressions {
+		if strings.Contains(expressions[i], " ") {
+			expressions[i] = "(" + expressions[i] + ")"
+		}
+	}
+
+	ex, err := license.ParseExpression(strings.Join(expressions, " OR "))
+	if err != nil || ex == "" {
+		return "", false
+	}
+
+	return ex, true
+}
+
+// normalizeLicenses sets the SPDX expression of each license in the collection
+// that doesn't have one, when the expression can be determined from the
+// license's value (see normalizeLicenseExpression).
+func normalizeLicenses(ctx context.Context, collection *pkg.Collection) {
+	log := clog.FromContext(ctx)
+
+	for _, p := range collection.Sorted() {
+		licenses := p.Licenses.ToSlice()
+
+		changed := false
+		for i := range licenses {
+			if licenses[i].SPDXExpression != "" || licenses[i].Value == "" {
+				continue
+			}
+
+			ex, ok := normalizeLicenseExpression(licenses[i].Value)
+			if !ok {
+				continue
+			}
+
+			log.Debug("normalized license to SPDX expression", "package", p.Name, "value", licenses[i].Value, "spdxExpression", ex)
+			licenses[i].SPDXExpression = ex
+			changed = true
+		}
+
+		if !changed {
+			continue
+		}
+
+		// Remove it, modify our local copy, and then add it back. The package's ID
+		// is kept, so that relationships to the package remain valid.
+
+		collection.Delete(p.ID())
+		p.Licenses = pkg.NewLicenseSet(licenses...)
+		collection.Add(p)
+	}
+}
+
+// licensesFromFiles returns the licenses detected in the APK's license files,
+// i.e. the files in "usr/share/licenses/<name>/", where name is the name of the
+// APK or of its origin package. The licenses are "concluded" licenses, since
+// they're determined from the license texts, rather than declared.
+func licensesFromFiles(ctx context.Context, fsys fs.FS, includedFiles []string, names ...string) []pkg.License {
+	log := clog.FromContext(ctx)
+
+	var licenses []pkg.License
+	for _, f := range includedFiles {
+		dir := path.Dir(f)
+		if path.Dir(dir) != "usr/share/licenses" || !slices.Contains(names, path.Base(dir)) {
+			continue
+		}
+
+		info, err := fs.Stat(fsys, f)
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxLicenseFileSize {
+			continue
+		}
+
+		text, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			log.Debug("unable to read license file", "path", f, "error", err)
+			continue
+		}
+
+		coverage := licensecheck.Scan(text)
+		if coverage.Percent < licenseFileCoverageThreshold {
+			log.Debug("license file doesn't match any known license", "path", f, "coverage", coverage.Percent)
+			continue
+		}
+
+		for _, m := range coverage.Match {
+			ex, err := license.ParseExpression(m.ID)
+			if err != nil || ex == "" {
+				continue
+			}
+
+			log.Debug("detected license in license file", "path", f, "spdxExpression", ex)
+			licenses = append(licenses, pkg.License{
+				Value:          m.ID,
+				SPDXExpression: ex,
+				Type:           license.Concluded,
+				Locations:      file.NewLocationSet(file.NewLocation(f)),
+			})
+		}
+	}
+
+	return licenses
+}
@@ -0,0 +1,113 @@
+package sbom
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/anchore/syft/syft/license"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeLicenseExpression(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "Apache-2.0", expected: "Apache-2.0"},
+		{value: "GPL-2.0-or-later AND MIT", expected: "GPL-2.0-or-later AND MIT"},
+		{value: "Apache License, Version 2.0", expected: "Apache-2.0"},
+		{value: "The MIT License (MIT)", expected: "MIT"},
+		{value: "http://www.apache.org/licenses/LICENSE-2.0.txt", expected: "Apache-2.0"},
+		{value: `"Apache-2.0";link="https://www.apache.org/licenses/LICENSE-2.0.txt"`, expected: "Apache-2.0"},
+		{value: "BSD-3-Clause;link=https://asm.ow2.io/LICENSE.txt", expected: "BSD-3-Clause"},
+		{
+			value:    "http://www.eclipse.org/legal/epl-2.0, https://www.gnu.org/software/classpath/license.html, http://www.eclipse.org/org/documents/edl-v10.php",
+			expected: "EPL-2.0 OR (GPL-2.0-only WITH Classpath-exception-2.0) OR BSD-3-Clause",
+		},
+		{value: "https://oss.oracle.com/licenses/CDDL+GPL-1.1, https://oss.oracle.com/licenses/CDDL+GPL-1.1", expected: "CDDL-1.1 OR GPL-2.0-only WITH Classpath-exception-2.0"},
+
+		// Ambiguous or unknown
+		{value: "BSD"},
+		{value: "Apache-2.0, some proprietary license"},
+		{value: "https://github.com/stleary/JSON-java/blob/master/LICENSE"},
+		{value: ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.value, func(t *testing.T) {
+			ex, ok := normalizeLicenseExpression(tt.value)
+			assert.Equal(t, tt.expected != "", ok)
+			assert.Equal(t, tt.expected, ex)
+		})
+	}
+}
+
+func TestNormalizeLicenses(t *testing.T) {
+	p := pkg.Package{
+		Name:     "commons-io",
+		Version:  "2.16.1",
+		Type:     pkg.JavaPkg,
+		Licenses: pkg.NewLicenseSet(pkg.NewLicense("Apache License, Version 2.0"), pkg.NewLicense("Some Custom License")),
+	}
+	p.SetID()
+	id := p.ID()
+
+	collection := pkg.NewCollection(p)
+	normalizeLicenses(context.Background(), collection)
+
+	got := collection.Package(id)
+	require.NotNil(t, got, "package ID should be unchanged")
+
+	expressions := make(map[string]string)
+	for _, l := range got.Licenses.ToSlice() {
+		expressions[l.Value] = l.SPDXExpression
+	}
+	assert.Equal(t, map[string]string{
+		"Apache License, Version 2.0": "Apache-2.0",
+		"Some Custom License":         "",
+	}, expressions)
+}
+
+func TestLicensesFromFiles(t *testing.T) {
+	const mitText = `Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
+	fsys := fstest.MapFS{
+		"usr/share/licenses/foo/LICENSE":      {Data: []byte(mitText)},
+		"usr/share/licenses/foo/README":       {Data: []byte("not a license")},
+		"usr/share/licenses/other/LICENSE":    {Data: []byte(mitText)},
+		"usr/lib/foo/vendor/bar/LICENSE":      {Data: []byte(mitText)},
+		"usr/share/licenses/foo-base/COPYING": {Data: []byte(mitText)},
+	}
+
+	var includedFiles []string
+	for f := range fsys {
+		includedFiles = append(includedFiles, f)
+	}
+
+	licenses := licensesFromFiles(context.Background(), fsys, includedFiles, "foo-libs", "foo")
+	require.Len(t, licenses, 1)
+
+	assert.Equal(t, "MIT", licenses[0].SPDXExpression)
+	assert.Equal(t, license.Concluded, licenses[0].Type)
+	assert.Equal(t, []string{"usr/share/licenses/foo/LICENSE"}, licenses[0].Locations.CoordinateSet().Paths())
+}
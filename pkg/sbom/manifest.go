@@ -0,0 +1,190 @@
+package sbom
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/package-url/packageurl-go"
+	"github.com/wolfi-dev/wolfictl/pkg/internal/errorhelpers"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is the expected content of an APK's SBOM, which Verify checks an SBOM
+// against. For example:
+//
+//	allowed:
+//	  - purl: pkg:golang/github.com/spf13/cobra
+//	  - type: go-module
+//	    name: golang.org/x/*
+//	required:
+//	  - purl: pkg:golang/stdlib
+type Manifest struct {
+	// Allowed are the components that the SBOM may contain. If it's empty, any
+	// component is allowed. The APK package itself and the required components are
+	// always allowed.
+	Allowed []ComponentMatcher `yaml:"allowed"`
+
+	// Required are the components that the SBOM must contain.
+	Required []ComponentMatcher `yaml:"required"`
+}
+
+// ComponentMatcher matches SBOM components. Each of its fields that's set must
+// match a component for the component to match.
+type ComponentMatcher struct {
+	// PURL matches the components with the same package URL type, namespace, and
+	// name. If the package URL has a version, the component's version must be the
+	// same too. Qualifiers and subpaths are ignored.
+	PURL string `yaml:"purl,omitempty"`
+
+	// Name matches the component names that match it as a glob pattern (see
+	// path.Match), e.g. "golang.org/x/*".
+	Name string `yaml:"name,omitempty"`
+
+	// Type matches the components of the Syft package type, e.g. "go-module".
+	Type string `yaml:"type,omitempty"`
+}
+
+// ParseManifest reads a Manifest from YAML.
+func ParseManifest(r io.Reader) (*Manifest, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	m := new(Manifest)
+	if err := dec.Decode(m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	for _, matchers := range [][]ComponentMatcher{m.Allowed, m.Required} {
+		for i := range matchers {
+			if err := matchers[i].validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (c ComponentMatcher) validate() error {
+	if c.PURL == "" && c.Name == "" && c.Type == "" {
+		return errors.New("component matcher must set at least one of purl, name, or type")
+	}
+
+	if c.PURL != "" {
+		if _, err := packageurl.FromString(c.PURL); err != nil {
+			return fmt.Errorf("invalid package URL %q: %w", c.PURL, err)
+		}
+	}
+
+	if c.Name != "" {
+		if _, err := path.Match(c.Name, ""); err != nil {
+			return fmt.Errorf("invalid name pattern %q: %w", c.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// String returns a description of the matcher for error messages.
+func (c ComponentMatcher) String() string {
+	switch {
+	case c.PURL != "":
+		return c.PURL
+	case c.Name != "" && c.Type != "":
+		return fmt.Sprintf("%s (%s)", c.Name, c.Type)
+	case c.Name != "":
+		return c.Name
+	}
+	return fmt.Sprintf("any %s", c.Type)
+}
+
+// Matches returns true if the package matches the matcher.
+func (c ComponentMatcher) Matches(p pkg.Package) bool {
+	if c.Type != "" && string(p.Type) != c.Type {
+		return false
+	}
+
+	if c.Name != "" {
+		if ok, _ := path.Match(c.Name, p.Name); !ok {
+			return false
+		}
+	}
+
+	if c.PURL != "" {
+		expected, err := packageurl.FromString(c.PURL)
+		if err != nil {
+			return false
+		}
+		purl, err := packageurl.FromString(p.PURL)
+		if err != nil {
+			return false
+		}
+		if purl.Type != expected.Type || purl.Namespace != expected.Namespace || purl.Name != expected.Name {
+			return false
+		}
+		if expected.Version != "" && purl.Version != expected.Version {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Verify checks the SBOM of an APK against the manifest. The returned error
+// describes every unexpected component and every missing required component. If
+// the SBOM matches the manifest, Verify returns nil.
+func Verify(s *sbom.SBOM, m *Manifest) error {
+	packages := s.Artifacts.Packages.Sorted()
+
+	allowed := slices.Concat(m.Allowed, m.Required)
+
+	var unexpectedErrs []error
+	if len(m.Allowed) > 0 {
+		for _, p := range packages {
+			if p.Type == pkg.ApkPkg || matchesAny(allowed, p) {
+				continue
+			}
+			unexpectedErrs = append(unexpectedErrs, fmt.Errorf("component %q is not allowed", packageDisplayName(p)))
+		}
+	}
+
+	var missingErrs []error
+	for _, r := range m.Required {
+		found := false
+		for _, p := range packages {
+			if r.Matches(p) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missingErrs = append(missingErrs, fmt.Errorf("required component %q not found", r))
+		}
+	}
+
+	return errors.Join(
+		errorhelpers.LabelError("unexpected component(s)", errors.Join(unexpectedErrs...)),
+		errorhelpers.LabelError("missing component(s)", errors.Join(missingErrs...)),
+	)
+}
+
+func matchesAny(matchers []ComponentMatcher, p pkg.Package) bool {
+	for _, m := range matchers {
+		if m.Matches(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func packageDisplayName(p pkg.Package) string {
+	if p.PURL != "" {
+		return p.PURL
+	}
+	return packageNameVersion(p)
+}
@@ -0,0 +1,110 @@
+package sbom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	packages := []pkg.Package{
+		{Name: "crane", Version: "0.19.1-r6", Type: pkg.ApkPkg, PURL: "pkg:apk/wolfi/crane@0.19.1-r6?arch=x86_64&distro=wolfi"},
+		{Name: "stdlib", Version: "go1.22.5", Type: pkg.GoModulePkg, PURL: "pkg:golang/stdlib@1.22.5"},
+		{Name: "github.com/spf13/cobra", Version: "v1.8.1", Type: pkg.GoModulePkg, PURL: "pkg:golang/github.com/spf13/cobra@v1.8.1"},
+		{Name: "golang.org/x/sync", Version: "v0.7.0", Type: pkg.GoModulePkg, PURL: "pkg:golang/golang.org/x/sync@v0.7.0"},
+	}
+	for i := range packages {
+		packages[i].SetID()
+	}
+	s := &sbom.SBOM{
+		Artifacts: sbom.Artifacts{Packages: pkg.NewCollection(packages...)},
+	}
+
+	cases := []struct {
+		name     string
+		manifest string
+		errs     []string
+	}{
+		{
+			name: "matches",
+			manifest: `
+allowed:
+  - purl: pkg:golang/github.com/spf13/cobra
+  - type: go-module
+    name: golang.org/x/*
+required:
+  - purl: pkg:golang/stdlib
+`,
+		},
+		{
+			name: "only required",
+			manifest: `
+required:
+  - purl: pkg:golang/github.com/spf13/cobra@v1.8.1
+`,
+		},
+		{
+			name: "unexpected component",
+			manifest: `
+allowed:
+  - purl: pkg:golang/github.com/spf13/cobra
+  - purl: pkg:golang/stdlib
+`,
+			errs: []string{`component "pkg:golang/golang.org/x/sync@v0.7.0" is not allowed`},
+		},
+		{
+			name: "missing component",
+			manifest: `
+required:
+  - purl: pkg:golang/stdlib
+  - purl: pkg:golang/github.com/spf13/cobra@v1.9.0
+  - type: python
+`,
+			errs: []string{
+				`required component "pkg:golang/github.com/spf13/cobra@v1.9.0" not found`,
+				`required component "any python" not found`,
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseManifest(strings.NewReader(tt.manifest))
+			require.NoError(t, err)
+
+			err = Verify(s, m)
+			if len(tt.errs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, e := range tt.errs {
+				assert.ErrorContains(t, err, e)
+			}
+		})
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	cases := []struct {
+		name     string
+		manifest string
+		err      string
+	}{
+		{name: "empty matcher", manifest: "allowed:\n  - {}\n", err: "must set at least one of"},
+		{name: "invalid purl", manifest: "required:\n  - purl: golang/stdlib\n", err: "invalid package URL"},
+		{name: "invalid pattern", manifest: "allowed:\n  - name: \"[\"\n", err: "invalid name pattern"},
+		{name: "unknown field", manifest: "allow:\n  - name: foo\n", err: "decoding manifest"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest(strings.NewReader(tt.manifest))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
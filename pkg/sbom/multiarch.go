@@ -0,0 +1,164 @@
+package sbom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+	"github.com/chainguard-dev/clog"
+)
+
+// ArchAnnotation is the key of the location annotation that lists the
+// architectures (comma-separated) whose APKs contain a package, in SBOMs created
+// by MergeArchitectures.
+const ArchAnnotation = "arch"
+
+// MergeArchitectures merges the SBOMs of the same APK (i.e. the same package
+// name and version) built for different architectures, as created by Generate,
+// into a single SBOM.
+//
+// The merged SBOM has one APK package per architecture, each of which has
+// "contains" relationships to the packages found in its APK. Packages found in
+// more than one architecture's APK are merged. Every location of every package
+// is annotated (see ArchAnnotation) with the architectures in which the package
+// was found. Files (see GenerateOptions.IncludeFiles) have the architecture as
+// their file system ID.
+func MergeArchitectures(ctx context.Context, apkSBOMs ...*sbom.SBOM) (*sbom.SBOM, error) {
+	log := clog.FromContext(ctx)
+
+	if len(apkSBOMs) == 0 {
+		return nil, errors.New("no SBOMs to merge")
+	}
+
+	var (
+		name, version, distroID string
+		relationships           []artifact.Relationship
+	)
+
+	packages := make(map[artifact.ID]pkg.Package)
+	locationsByID := make(map[artifact.ID]map[file.LocationData]file.LocationMetadata)
+	archesByID := make(map[artifact.ID]map[string]struct{})
+	seenArches := make(map[string]struct{})
+	fileDigests := make(map[file.Coordinates][]file.Digest)
+
+	for i, s := range apkSBOMs {
+		apks := s.Artifacts.Packages.Sorted(pkg.ApkPkg)
+		if len(apks) != 1 {
+			return nil, fmt.Errorf("SBOM for %q has %d APK packages, expected exactly 1", s.Source.Name, len(apks))
+		}
+		apkPackage := apks[0]
+
+		metadata, ok := apkPackage.Metadata.(pkg.ApkDBEntry)
+		if !ok || metadata.Architecture == "" {
+			return nil, fmt.Errorf("APK package %s-%s has no architecture", apkPackage.Name, apkPackage.Version)
+		}
+		arch := metadata.Architecture
+
+		var sbomDistroID string
+		if d := s.Artifacts.LinuxDistribution; d != nil {
+			sbomDistroID = d.ID
+		}
+
+		if i == 0 {
+			name, version, distroID = apkPackage.Name, apkPackage.Version, sbomDistroID
+		} else {
+			if apkPackage.Name != name || apkPackage.Version != version {
+				return nil, fmt.Errorf("cannot merge SBOMs of different APKs: %s-%s and %s-%s", name, version, apkPackage.Name, apkPackage.Version)
+			}
+			if sbomDistroID != distroID {
+				return nil, fmt.Errorf("cannot merge SBOMs of different distros: %q and %q", distroID, sbomDistroID)
+			}
+		}
+
+		if _, ok := seenArches[arch]; ok {
+			return nil, fmt.Errorf("more than one SBOM for architecture %q", arch)
+		}
+		seenArches[arch] = struct{}{}
+
+		for _, p := range s.Artifacts.Packages.Sorted() {
+			// Packages with the same ID have the same name, version, licenses, etc., so
+			// only their locations need to be merged.
+			if _, ok := packages[p.ID()]; !ok {
+				packages[p.ID()] = p
+				locationsByID[p.ID()] = make(map[file.LocationData]file.LocationMetadata)
+				archesByID[p.ID()] = make(map[string]struct{})
+			}
+			for _, l := range p.Locations.ToSlice() {
+				if _, ok := locationsByID[p.ID()][l.LocationData]; !ok {
+					locationsByID[p.ID()][l.LocationData] = l.LocationMetadata
+				}
+			}
+			archesByID[p.ID()][arch] = struct{}{}
+		}
+
+		// The same path usually has different contents in each architecture's APK, so
+		// files are distinguished by using the architecture as their file system ID.
+		for c, d := range s.Artifacts.FileDigests {
+			c.FileSystemID = arch
+			fileDigests[c] = d
+		}
+
+		relationships = append(relationships, containsRelationships(apkPackage, s.Artifacts.Packages)...)
+		for _, r := range s.Relationships {
+			if c, ok := r.To.(file.Coordinates); ok {
+				c.FileSystemID = arch
+				r.To = c
+			}
+			relationships = append(relationships, r)
+		}
+	}
+
+	collection := pkg.NewCollection()
+	for id, p := range packages {
+		arches := strings.Join(slices.Sorted(maps.Keys(archesByID[id])), ",")
+
+		var locations []file.Location
+		for data, metadata := range locationsByID[id] {
+			// Copy the annotations, so that the input SBOMs' locations aren't modified.
+			annotations := maps.Clone(metadata.Annotations)
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[ArchAnnotation] = arches
+
+			locations = append(locations, file.Location{
+				LocationData:     data,
+				LocationMetadata: file.LocationMetadata{Annotations: annotations},
+			})
+		}
+
+		// The ID doesn't depend on the locations' annotations, so it's unchanged.
+		p.Locations = file.NewLocationSet(locations...)
+		collection.Add(p)
+	}
+
+	log.Info("merged per-architecture SBOMs", "name", name, "version", version, "arches", slices.Sorted(maps.Keys(seenArches)), "packageCount", collection.PackageCount())
+
+	s := sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			Packages:    collection,
+			FileDigests: fileDigests,
+			LinuxDistribution: &linux.Release{
+				ID: distroID,
+			},
+		},
+		Relationships: relationships,
+		Source: source.Description{
+			ID:      "(redacted for determinism)",
+			Name:    name,
+			Version: version,
+		},
+		Descriptor: newDescriptor(nil),
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,119 @@
+package sbom
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testArchSBOM(name, version, arch string, components ...pkg.Package) *sbom.SBOM {
+	apk := pkg.Package{
+		Name:      name,
+		Version:   version,
+		Type:      pkg.ApkPkg,
+		Locations: file.NewLocationSet(file.NewLocation(pkginfoPath)),
+		Metadata: pkg.ApkDBEntry{
+			Package:      name,
+			Version:      version,
+			Architecture: arch,
+		},
+	}
+	apk.SetID()
+
+	for i := range components {
+		components[i].SetID()
+	}
+
+	return &sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			Packages:          pkg.NewCollection(append([]pkg.Package{apk}, components...)...),
+			LinuxDistribution: &linux.Release{ID: "wolfi"},
+		},
+	}
+}
+
+func TestMergeArchitectures(t *testing.T) {
+	ctx := context.Background()
+
+	ggcr := pkg.Package{
+		Name:      "github.com/google/go-containerregistry",
+		Version:   "v0.19.1",
+		Type:      pkg.GoModulePkg,
+		Locations: file.NewLocationSet(file.NewLocation("usr/bin/crane")),
+	}
+	sysX86 := pkg.Package{
+		Name:      "golang.org/x/sys",
+		Version:   "v0.20.0",
+		Type:      pkg.GoModulePkg,
+		Locations: file.NewLocationSet(file.NewLocation("usr/lib/x86_64/helper")),
+	}
+
+	x86 := testArchSBOM("crane", "0.19.1-r6", "x86_64", ggcr, sysX86)
+	arm := testArchSBOM("crane", "0.19.1-r6", "aarch64", ggcr)
+
+	s, err := MergeArchitectures(ctx, x86, arm)
+	require.NoError(t, err)
+
+	assert.Equal(t, "crane", s.Source.Name)
+	assert.Equal(t, "0.19.1-r6", s.Source.Version)
+	assert.Equal(t, "wolfi", s.Artifacts.LinuxDistribution.ID)
+
+	arches := make(map[string][]string)
+	for _, p := range s.Artifacts.Packages.Sorted() {
+		for _, l := range p.Locations.ToSlice() {
+			arches[p.Name] = append(arches[p.Name], l.Annotations[ArchAnnotation])
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"crane":                                  {"aarch64", "x86_64"},
+		"github.com/google/go-containerregistry": {"aarch64,x86_64"},
+		"golang.org/x/sys":                       {"x86_64"},
+	}, sortedValues(arches))
+
+	var contains []string
+	for _, r := range s.Relationships {
+		require.Equal(t, artifact.ContainsRelationship, r.Type)
+		from := s.Artifacts.Packages.Package(r.From.ID())
+		to := s.Artifacts.Packages.Package(r.To.ID())
+		require.NotNil(t, from)
+		require.NotNil(t, to)
+		contains = append(contains, from.Metadata.(pkg.ApkDBEntry).Architecture+" -> "+to.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"x86_64 -> github.com/google/go-containerregistry",
+		"x86_64 -> golang.org/x/sys",
+		"aarch64 -> github.com/google/go-containerregistry",
+	}, contains)
+
+	// The input SBOMs aren't modified.
+	for _, p := range x86.Artifacts.Packages.Sorted() {
+		for _, l := range p.Locations.ToSlice() {
+			assert.Empty(t, l.Annotations)
+		}
+	}
+
+	t.Run("different versions", func(t *testing.T) {
+		_, err := MergeArchitectures(ctx, x86, testArchSBOM("crane", "0.19.2-r0", "aarch64"))
+		assert.ErrorContains(t, err, "cannot merge SBOMs of different APKs")
+	})
+
+	t.Run("same architecture twice", func(t *testing.T) {
+		_, err := MergeArchitectures(ctx, x86, testArchSBOM("crane", "0.19.1-r6", "x86_64"))
+		assert.ErrorContains(t, err, `more than one SBOM for architecture "x86_64"`)
+	})
+}
+
+func sortedValues(m map[string][]string) map[string][]string {
+	for k := range m {
+		slices.Sort(m[k])
+	}
+	return m
+}
@@ -0,0 +1,121 @@
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/clog"
+	"github.com/openvex/go-vex/pkg/vex"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+)
+
+// OpenVEXOptions configures the OpenVEX documents created by ToOpenVEXJSON.
+type OpenVEXOptions struct {
+	// Author is the author of the document. If empty, "wolfictl" is used.
+	Author string
+
+	// AuthorRole is the role of the document's author.
+	AuthorRole string
+
+	// ToolVersion is the version of wolfictl, which is included in the document's
+	// tooling information.
+	ToolVersion string
+
+	// Timestamp is the document's timestamp. If zero, the current time is used.
+	Timestamp time.Time
+}
+
+// ToOpenVEXJSON returns a reader of an OpenVEX document with statements about
+// the vulnerabilities of the SBOM's APK packages, according to the advisories
+// for the APKs' origin packages.
+//
+// The statements' products are identified by the package URLs in the SBOM, so
+// that the document and the SBOM are consistent by construction. Only
+// conclusive statements are made: an APK is "not_affected" by a vulnerability
+// whose advisory's latest event is a false positive determination, and it's
+// "fixed" if the latest event is a fix in the APK's version or an earlier
+// version. Other advisories are omitted.
+func ToOpenVEXJSON(ctx context.Context, s *sbom.SBOM, advGetter advisory.Getter, opts OpenVEXOptions) (io.ReadSeeker, error) {
+	log := clog.FromContext(ctx)
+
+	doc := vex.New()
+	doc.Author = opts.Author
+	if doc.Author == "" {
+		doc.Author = "wolfictl"
+	}
+	doc.AuthorRole = opts.AuthorRole
+	doc.Tooling = "wolfictl"
+	if opts.ToolVersion != "" {
+		doc.Tooling += "-" + opts.ToolVersion
+	}
+	if !opts.Timestamp.IsZero() {
+		ts := opts.Timestamp.UTC()
+		doc.Timestamp = &ts
+	}
+
+	for _, p := range s.Artifacts.Packages.Sorted(pkg.ApkPkg) {
+		if p.PURL == "" {
+			continue
+		}
+
+		origin := p.Name
+		if m, ok := p.Metadata.(pkg.ApkDBEntry); ok && m.OriginPackage != "" {
+			origin = m.OriginPackage
+		}
+
+		advs, err := advGetter.Advisories(ctx, origin)
+		if err != nil {
+			return nil, fmt.Errorf("getting advisories for package %q: %w", origin, err)
+		}
+		log.Debug("found advisories for APK", "name", p.Name, "origin", origin, "advisoryCount", len(advs))
+
+		for _, adv := range advs {
+			statement, ok := openVEXStatement(adv.Advisory, p)
+			if !ok {
+				continue
+			}
+			doc.Statements = append(doc.Statements, statement)
+		}
+	}
+
+	if _, err := doc.GenerateCanonicalID(); err != nil {
+		return nil, fmt.Errorf("generating OpenVEX document ID: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := doc.ToJSON(buf); err != nil {
+		return nil, fmt.Errorf("failed to encode OpenVEX document: %w", err)
+	}
+
+	return bytes.NewReader(buf.Bytes()), nil
+}
+
+// openVEXStatement returns the statement about the given APK package that's
+// supported by the advisory, if the advisory is conclusive for the package.
+func openVEXStatement(adv v2.Advisory, p pkg.Package) (vex.Statement, bool) {
+	statement, ok := advisory.OpenVEXStatement(adv, p.Version)
+	if !ok {
+		return vex.Statement{}, false
+	}
+
+	switch statement.Status {
+	case vex.StatusFixed, vex.StatusNotAffected:
+	default:
+		return vex.Statement{}, false
+	}
+
+	statement.Products = []vex.Product{{
+		Component: vex.Component{
+			ID:          p.PURL,
+			Identifiers: map[vex.IdentifierType]string{vex.PURL: p.PURL},
+		},
+	}}
+
+	return statement, true
+}
@@ -0,0 +1,119 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/openvex/go-vex/pkg/vex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+)
+
+const testOpenVEXAdvisories = `schema-version: "2"
+
+package:
+  name: crane
+
+advisories:
+  - id: CGA-2222-2222-2222
+    aliases:
+      - GHSA-xxxx-xxxx-xxxx
+      - CVE-2024-0002
+    events:
+      - timestamp: 2024-02-01T00:00:00Z
+        type: fixed
+        data:
+          fixed-version: 0.19.1-r0
+  - id: CGA-3333-3333-3333
+    aliases:
+      - CVE-2024-0003
+    events:
+      - timestamp: 2024-03-01T00:00:00Z
+        type: fixed
+        data:
+          fixed-version: 0.20.0-r0
+  - id: CGA-4444-4444-4444
+    aliases:
+      - CVE-2024-0004
+    events:
+      - timestamp: 2024-04-01T00:00:00Z
+        type: false-positive-determination
+        data:
+          type: vulnerable-code-not-in-execution-path
+          note: The vulnerable function is never called.
+  - id: CGA-5555-5555-5555
+    aliases:
+      - CVE-2024-0005
+    events:
+      - timestamp: 2024-05-01T00:00:00Z
+        type: detection
+        data:
+          type: manual
+`
+
+func TestToOpenVEXJSON(t *testing.T) {
+	ctx := context.Background()
+
+	// The APK is a subpackage, so its advisories are those of its origin package.
+	p := pkg.Package{
+		Name:    "crane-compat",
+		Version: "0.19.1-r6",
+		Type:    pkg.ApkPkg,
+		PURL:    "pkg:apk/wolfi/crane-compat@0.19.1-r6?arch=x86_64&distro=wolfi",
+		Metadata: pkg.ApkDBEntry{
+			Package:       "crane-compat",
+			OriginPackage: "crane",
+			Version:       "0.19.1-r6",
+		},
+	}
+	p.SetID()
+
+	s := &sbom.SBOM{
+		Artifacts: sbom.Artifacts{Packages: pkg.NewCollection(p)},
+	}
+
+	getter := advisory.NewFSGetter(fstest.MapFS{
+		"crane.advisories.yaml": {Data: []byte(testOpenVEXAdvisories)},
+	})
+
+	r, err := ToOpenVEXJSON(ctx, s, getter, OpenVEXOptions{
+		Author:      "test",
+		ToolVersion: "v1.2.3",
+		Timestamp:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	var doc vex.VEX
+	require.NoError(t, json.NewDecoder(r).Decode(&doc))
+
+	assert.Equal(t, "test", doc.Author)
+	assert.Equal(t, "wolfictl-v1.2.3", doc.Tooling)
+	assert.NotEmpty(t, doc.ID)
+
+	// The advisories that aren't conclusive for this version of the APK are
+	// omitted: it's older than the fix for CVE-2024-0003, and CVE-2024-0005 is
+	// only detected.
+	require.Len(t, doc.Statements, 2)
+
+	fixed := doc.Statements[0]
+	assert.Equal(t, vex.VulnerabilityID("CVE-2024-0002"), fixed.Vulnerability.Name)
+	assert.Equal(t, []vex.VulnerabilityID{"CGA-2222-2222-2222", "GHSA-xxxx-xxxx-xxxx"}, fixed.Vulnerability.Aliases)
+	assert.Equal(t, vex.StatusFixed, fixed.Status)
+	require.Len(t, fixed.Products, 1)
+	assert.Equal(t, p.PURL, fixed.Products[0].ID)
+	assert.Equal(t, p.PURL, fixed.Products[0].Identifiers[vex.PURL])
+
+	notAffected := doc.Statements[1]
+	assert.Equal(t, vex.VulnerabilityID("CVE-2024-0004"), notAffected.Vulnerability.Name)
+	assert.Equal(t, vex.StatusNotAffected, notAffected.Status)
+	assert.Equal(t, vex.VulnerableCodeNotInExecutePath, notAffected.Justification)
+	assert.Equal(t, "The vulnerable function is never called.", notAffected.ImpactStatement)
+	require.NotNil(t, notAffected.Timestamp)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), notAffected.Timestamp.UTC())
+}
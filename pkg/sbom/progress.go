@@ -0,0 +1,123 @@
+package sbom
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// GenerateStage is a stage of SBOM generation for an APK.
+type GenerateStage string
+
+const (
+	// GenerateStageExtracting is the stage in which the APK is read and its files
+	// are extracted to a temporary directory.
+	GenerateStageExtracting GenerateStage = "extracting"
+
+	// GenerateStageCataloging is the stage in which Syft catalogs the APK's files.
+	GenerateStageCataloging GenerateStage = "cataloging"
+
+	// GenerateStageDigesting is the stage in which the digests of the APK's files
+	// are computed (see GenerateOptions.IncludeFiles).
+	GenerateStageDigesting GenerateStage = "digesting"
+
+	// GenerateStageDone is reported once the SBOM has been generated.
+	GenerateStageDone GenerateStage = "done"
+)
+
+// GenerateProgress is the progress of SBOM generation for an APK, as reported to
+// GenerateOptions.Progress.
+type GenerateProgress struct {
+	// Stage is the current stage.
+	Stage GenerateStage
+
+	// BytesRead is the number of bytes of the APK read so far.
+	BytesRead int64
+
+	// TotalBytes is the size of the APK in bytes, or 0 if it isn't known (i.e.
+	// the APK isn't read from a file).
+	TotalBytes int64
+}
+
+// progressTracker reports progress to a GenerateOptions.Progress function, if
+// there is one.
+type progressTracker struct {
+	fn      func(GenerateProgress)
+	current GenerateProgress
+}
+
+func newProgressTracker(fn func(GenerateProgress), r io.Reader) *progressTracker {
+	t := &progressTracker{fn: fn}
+	if f, ok := r.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			t.current.TotalBytes = info.Size()
+		}
+	}
+	return t
+}
+
+// setStage reports that generation has reached the given stage.
+func (t *progressTracker) setStage(stage GenerateStage) {
+	t.current.Stage = stage
+	t.report()
+}
+
+// reader returns a reader of r that reports the number of bytes read from it.
+func (t *progressTracker) reader(r io.Reader) io.Reader {
+	if t.fn == nil {
+		return r
+	}
+	return &progressReader{r: r, tracker: t}
+}
+
+func (t *progressTracker) report() {
+	if t.fn != nil {
+		t.fn(t.current)
+	}
+}
+
+type progressReader struct {
+	r       io.Reader
+	tracker *progressTracker
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.tracker.current.BytesRead += int64(n)
+		r.tracker.report()
+	}
+	return n, err
+}
+
+// seekableInput returns a reader of f that can be read again by seeking back to
+// the returned offset, along with a function to release it. Inputs that can't
+// seek are spooled to a temporary file rather than to memory, since APKs can be
+// several gigabytes in size.
+func seekableInput(f io.Reader) (io.ReadSeeker, int64, func(), error) {
+	if rs, ok := f.(io.ReadSeeker); ok {
+		if start, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			return rs, start, func() {}, nil
+		}
+	}
+
+	tmp, err := os.CreateTemp("", "wolfictl-sbom-input-*")
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("creating temp file for input: %w", err)
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+
+	if _, err := io.Copy(tmp, f); err != nil {
+		cleanup()
+		return nil, 0, nil, fmt.Errorf("copying input to temp file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, 0, nil, fmt.Errorf("rewinding temp file: %w", err)
+	}
+
+	return tmp, 0, cleanup, nil
+}
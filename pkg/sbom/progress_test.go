@@ -0,0 +1,93 @@
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateWithOptionsProgress(t *testing.T) {
+	apkPath := filepath.Join("..", "tar", "testdata", "hello-wolfi-2.12-r1.apk")
+	f, err := os.Open(apkPath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	var reports []GenerateProgress
+	_, err = GenerateWithOptions(context.Background(), apkPath, f, "wolfi", GenerateOptions{
+		IncludeFiles: true,
+		Progress: func(p GenerateProgress) {
+			reports = append(reports, p)
+		},
+	})
+	require.NoError(t, err)
+
+	var stages []GenerateStage
+	for i, p := range reports {
+		assert.Equal(t, info.Size(), p.TotalBytes)
+		if i > 0 {
+			assert.GreaterOrEqual(t, p.BytesRead, reports[i-1].BytesRead, "bytes read shouldn't decrease")
+		}
+		if len(stages) == 0 || stages[len(stages)-1] != p.Stage {
+			stages = append(stages, p.Stage)
+		}
+	}
+	assert.Equal(t, []GenerateStage{
+		GenerateStageExtracting,
+		GenerateStageCataloging,
+		GenerateStageDigesting,
+		GenerateStageDone,
+	}, stages)
+	assert.Positive(t, reports[len(reports)-1].BytesRead)
+}
+
+func TestSeekableInput(t *testing.T) {
+	t.Run("seeker", func(t *testing.T) {
+		r := strings.NewReader("skipped,content")
+		_, err := r.Seek(int64(len("skipped,")), io.SeekStart)
+		require.NoError(t, err)
+
+		rs, start, cleanup, err := seekableInput(r)
+		require.NoError(t, err)
+		defer cleanup()
+
+		assert.Same(t, r, rs)
+		assertReadTwice(t, rs, start, "content")
+	})
+
+	t.Run("not a seeker", func(t *testing.T) {
+		r := io.MultiReader(bytes.NewBufferString("content"))
+
+		rs, start, cleanup, err := seekableInput(r)
+		require.NoError(t, err)
+
+		tmp, ok := rs.(*os.File)
+		require.True(t, ok, "input should be spooled to a file")
+		assertReadTwice(t, rs, start, "content")
+
+		cleanup()
+		assert.NoFileExists(t, tmp.Name())
+	})
+}
+
+func assertReadTwice(t *testing.T, rs io.ReadSeeker, start int64, expected string) {
+	t.Helper()
+
+	for i := 0; i < 2; i++ {
+		_, err := rs.Seek(start, io.SeekStart)
+		require.NoError(t, err)
+
+		b, err := io.ReadAll(rs)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(b))
+	}
+}
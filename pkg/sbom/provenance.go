@@ -0,0 +1,113 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/anchore/syft/syft/sbom"
+	"sigs.k8s.io/release-utils/version"
+)
+
+// Provenance identifies the tools that generated an SBOM and how they were
+// configured, so that SBOMs generated by a tool version with a bug can be found
+// and regenerated. It's recorded as the configuration of the SBOM's descriptor,
+// and it's included in the SBOM's metadata in each output format.
+type Provenance struct {
+	// WolfictlVersion is the version of wolfictl that generated the SBOM.
+	WolfictlVersion string `json:"wolfictlVersion"`
+
+	// SyftVersion is the version of Syft used to catalog the SBOM's packages.
+	SyftVersion string `json:"syftVersion"`
+
+	// Catalogers is Syft's record of the catalogers that were requested and used.
+	// It's empty if Syft wasn't used directly (e.g. for an SBOM created by
+	// Compose). The rest of Syft's configuration isn't recorded, since it includes
+	// local paths (e.g. of the Go module cache).
+	Catalogers json.RawMessage `json:"catalogers,omitempty"`
+
+	// Generated is the time at which the SBOM was generated. For an SBOM from the
+	// cache, this is when it was generated, not when it was retrieved.
+	Generated time.Time `json:"generated"`
+}
+
+// newProvenance returns the provenance of an SBOM that's being generated now,
+// given Syft's record of its configuration (if any).
+func newProvenance(syftConfiguration any) Provenance {
+	p := Provenance{
+		WolfictlVersion: version.GetVersionInfo().GitVersion,
+		SyftVersion:     syftVersion(),
+		Generated:       time.Now().UTC(),
+	}
+
+	if syftConfiguration != nil {
+		var c struct {
+			Catalogers json.RawMessage `json:"catalogers"`
+		}
+		if b, err := json.Marshal(syftConfiguration); err == nil && json.Unmarshal(b, &c) == nil {
+			p.Catalogers = c.Catalogers
+		}
+	}
+
+	return p
+}
+
+// newDescriptor returns the descriptor of an SBOM that's being generated now.
+func newDescriptor(syftConfiguration any) sbom.Descriptor {
+	p := newProvenance(syftConfiguration)
+
+	return sbom.Descriptor{
+		Name:          "wolfictl",
+		Version:       p.WolfictlVersion,
+		Configuration: p,
+	}
+}
+
+// ProvenanceOf returns the provenance recorded in the SBOM, or nil if it has
+// none (e.g. because it was generated by an older version of wolfictl).
+func ProvenanceOf(s *sbom.SBOM) (*Provenance, error) {
+	switch c := s.Descriptor.Configuration.(type) {
+	case nil:
+		return nil, nil
+
+	case Provenance:
+		return &c, nil
+
+	case *Provenance:
+		return c, nil
+
+	default:
+		// The SBOM was decoded (e.g. from the cache), so its configuration is
+		// generic JSON data.
+		b, err := json.Marshal(c)
+		if err != nil {
+			return nil, fmt.Errorf("encoding SBOM descriptor configuration: %w", err)
+		}
+
+		var p Provenance
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, fmt.Errorf("decoding SBOM provenance: %w", err)
+		}
+		if p.WolfictlVersion == "" && p.SyftVersion == "" {
+			return nil, nil
+		}
+
+		return &p, nil
+	}
+}
+
+// cycloneDXProvenanceProperties returns the provenance as CycloneDX properties.
+func cycloneDXProvenanceProperties(p *Provenance) []cyclonedx.Property {
+	properties := []cyclonedx.Property{
+		{Name: "wolfictl:provenance:wolfictl-version", Value: p.WolfictlVersion},
+		{Name: "wolfictl:provenance:syft-version", Value: p.SyftVersion},
+		{Name: "wolfictl:provenance:generated", Value: p.Generated.UTC().Format(time.RFC3339)},
+	}
+
+	if len(p.Catalogers) > 0 {
+		properties = append(properties, cyclonedx.Property{Name: "wolfictl:provenance:syft-catalogers", Value: string(p.Catalogers)})
+	}
+
+	return properties
+}
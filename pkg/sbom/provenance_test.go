@@ -0,0 +1,92 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenance(t *testing.T) {
+	apkPath := filepath.Join("..", "tar", "testdata", "hello-wolfi-2.12-r1.apk")
+	f, err := os.Open(apkPath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	before := time.Now()
+	s, err := Generate(context.Background(), apkPath, f, "wolfi")
+	require.NoError(t, err)
+
+	p, err := ProvenanceOf(s)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, syftVersion(), p.SyftVersion)
+	assert.Contains(t, string(p.Catalogers), `"used":`)
+	assert.WithinRange(t, p.Generated, before.Add(-time.Second), time.Now())
+
+	t.Run("decoded", func(t *testing.T) {
+		// A decoded SBOM's descriptor configuration is generic JSON data.
+		b, err := json.Marshal(s.Descriptor.Configuration)
+		require.NoError(t, err)
+		var configuration any
+		require.NoError(t, json.Unmarshal(b, &configuration))
+
+		decoded := *s
+		decoded.Descriptor.Configuration = configuration
+
+		decodedProvenance, err := ProvenanceOf(&decoded)
+		require.NoError(t, err)
+		require.NotNil(t, decodedProvenance)
+		assert.Equal(t, p.WolfictlVersion, decodedProvenance.WolfictlVersion)
+		assert.Equal(t, p.SyftVersion, decodedProvenance.SyftVersion)
+		assert.JSONEq(t, string(p.Catalogers), string(decodedProvenance.Catalogers))
+		assert.True(t, p.Generated.Equal(decodedProvenance.Generated))
+	})
+
+	t.Run("SPDX", func(t *testing.T) {
+		r, err := ToSPDXJSON(s, SPDXOptions{})
+		require.NoError(t, err)
+
+		var doc struct {
+			CreationInfo struct {
+				Creators []string `json:"creators"`
+				Comment  string   `json:"comment"`
+			} `json:"creationInfo"`
+		}
+		require.NoError(t, json.NewDecoder(r).Decode(&doc))
+
+		assert.Contains(t, doc.CreationInfo.Creators, "Tool: syft-"+p.SyftVersion)
+
+		var commented Provenance
+		require.NoError(t, json.Unmarshal([]byte(doc.CreationInfo.Comment), &commented))
+		assert.Equal(t, p.SyftVersion, commented.SyftVersion)
+	})
+
+	t.Run("CycloneDX", func(t *testing.T) {
+		r, err := ToCycloneDXJSON(s, CycloneDXOptions{})
+		require.NoError(t, err)
+
+		var doc struct {
+			Metadata struct {
+				Properties []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"properties"`
+			} `json:"metadata"`
+		}
+		require.NoError(t, json.NewDecoder(r).Decode(&doc))
+
+		properties := make(map[string]string)
+		for _, prop := range doc.Metadata.Properties {
+			properties[prop.Name] = prop.Value
+		}
+		assert.Equal(t, p.SyftVersion, properties["wolfictl:provenance:syft-version"])
+		assert.Equal(t, p.Generated.Format(time.RFC3339), properties["wolfictl:provenance:generated"])
+		assert.JSONEq(t, string(p.Catalogers), properties["wolfictl:provenance:syft-catalogers"])
+	})
+}
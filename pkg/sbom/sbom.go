@@ -10,6 +10,7 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"slices"
 	"strings"
 
 	"chainguard.dev/melange/pkg/config"
@@ -41,8 +42,60 @@ const (
 	CPESourceMelangeConfiguration cpe.Source = "melange-configuration"
 )
 
+// binaryClassifierCataloger is the name of Syft's cataloger of binaries, whose
+// packages are removed from APK SBOMs unless it's explicitly enabled.
+const binaryClassifierCataloger = "binary-classifier-cataloger"
+
+// DefaultDisabledCatalogers are the names of the Syft catalogers that are
+// disabled by default, because their results are noise for our APKs.
+var DefaultDisabledCatalogers = []string{
+	"sbom-cataloger",
+	// TODO consider how to turn it on https://github.com/chainguard-dev/internal-dev/issues/8731
+	"elf-binary-package-cataloger",
+	// The sources are the APK's build inputs rather than its contents, and SBOMs
+	// already in use shouldn't change without being asked to.
+	catalogers.MelangeSources{}.Name(),
+}
+
+// GenerateOptions configures GenerateWithOptions.
+type GenerateOptions struct {
+	// IncludeFiles, if true, adds the APK's regular files to the SBOM, with their
+	// sha256 digests and "contains" relationships from the APK package.
+	IncludeFiles bool
+
+	// IncludeDependencies, if true, adds the APK's runtime dependencies (its
+	// "depend" entries) to the SBOM, with "dependency of" relationships to the APK
+	// package, which SPDX documents encode as DEPENDS_ON relationships.
+	IncludeDependencies bool
+
+	// Deduplicate, if true, collapses packages found by more than one cataloger
+	// into a single package (see Deduplicate).
+	Deduplicate bool
+
+	// EnableCatalogers are the names of Syft catalogers to run in addition to the
+	// default selection, including catalogers in DefaultDisabledCatalogers.
+	// Explicitly enabling the binary classifier cataloger
+	// ("binary-classifier-cataloger") also keeps the binary packages it finds,
+	// which are otherwise removed, since they're all owned by the APK.
+	EnableCatalogers []string
+
+	// DisableCatalogers are the names or tags of Syft catalogers not to run, in
+	// addition to DefaultDisabledCatalogers.
+	DisableCatalogers []string
+
+	// Progress, if set, is called as generation progresses, which is useful for
+	// very large APKs. It's called on the goroutine that's generating the SBOM, so
+	// it should return quickly.
+	Progress func(GenerateProgress)
+}
+
 // Generate creates an SBOM for the given APK file.
 func Generate(ctx context.Context, inputFilePath string, f io.Reader, distroID string) (*sbom.SBOM, error) {
+	return GenerateWithOptions(ctx, inputFilePath, f, distroID, GenerateOptions{})
+}
+
+// GenerateWithOptions behaves like Generate, but it's configured by opts.
+func GenerateWithOptions(ctx context.Context, inputFilePath string, f io.Reader, distroID string, opts GenerateOptions) (*sbom.SBOM, error) {
 	log := clog.FromContext(ctx)
 
 	log.Info("generating SBOM for APK file", "path", inputFilePath, "distroID", distroID)
@@ -59,8 +112,12 @@ func Generate(ctx context.Context, inputFilePath string, f io.Reader, distroID s
 
 	log.Debug("created temp directory to unpack APK", "path", tempDir)
 
-	// Unpack apk to temp directory
-	if err := tar.Untar(f, tempDir); err != nil {
+	progress := newProgressTracker(opts.Progress, f)
+	progress.setStage(GenerateStageExtracting)
+
+	// Unpack apk to temp directory. The APK is streamed to disk, rather than read
+	// into memory, since it can be very large.
+	if err := tar.Untar(progress.reader(f), tempDir); err != nil {
 		return nil, fmt.Errorf("failed to unpack apk file: %w", err)
 	}
 	log.Debug("unpacked APK file to temp directory", "apkFilePath", inputFilePath)
@@ -116,6 +173,14 @@ func Generate(ctx context.Context, inputFilePath string, f io.Reader, distroID s
 	}
 	log.Debug("synthesized APK package for SBOM", "name", apkPackage.Name, "version", apkPackage.Version, "id", string(apkPackage.ID()))
 
+	licenseNames := []string{apkPackage.Name}
+	if m, ok := apkPackage.Metadata.(pkg.ApkDBEntry); ok && m.OriginPackage != "" {
+		licenseNames = append(licenseNames, m.OriginPackage)
+	}
+	if detected := licensesFromFiles(ctx, tempFsys, includedFiles, licenseNames...); len(detected) > 0 {
+		apkPackage.Licenses.Add(detected...)
+	}
+
 	src, err := directorysource.New(
 		directorysource.Config{
 			Path: tempDir,
@@ -128,30 +193,9 @@ func Generate(ctx context.Context, inputFilePath string, f io.Reader, distroID s
 
 	syft.SetLogger(anchorelogger.NewSlogAdapter(log.Base()))
 
-	cfg := syft.DefaultCreateSBOMConfig().WithCatalogerSelection(
-		pkgcataloging.NewSelectionRequest().WithDefaults(
-			pkgcataloging.ImageTag,
-			filecataloging.FileTag, // see https://github.com/anchore/syft/pull/3505 for context
-		).WithRemovals(
-			"sbom",
-			// TODO consider how to turn it on https://github.com/chainguard-dev/internal-dev/issues/8731
-			"elf-package",
-		),
-	).WithCatalogers(
-		catalogers.AngularJSReference,
-		catalogers.PipVendorReference,
-		catalogers.WheelReference,
-	).WithLicenseConfig(cataloging.LicenseConfig{
-		// Syft 1.24.0 starts adding full license texts into the SBOM, and this option
-		// should prevent that (we don't need these huge license texts right now). But,
-		// emphasis on "should"... the config wasn't wired correctly in Syft until
-		// https://github.com/anchore/syft/pull/3900. So, our integration test golden
-		// files were regenerated to absorb this change (to include licenses), and then
-		// when that fix PR rolls out, the tests will fail again, and we'll need to
-		// regenerate the golden files to account for subtracting the license texts back
-		// out.
-		IncludeContent: cataloging.LicenseContentExcludeAll,
-	})
+	cfg := newCreateSBOMConfig(opts)
+
+	progress.setStage(GenerateStageCataloging)
 
 	createdSBOM, err := syft.CreateSBOM(ctx, src, cfg)
 	if err != nil {
@@ -163,6 +207,7 @@ func Generate(ctx context.Context, inputFilePath string, f io.Reader, distroID s
 		return nil, fmt.Errorf("refining CPE data for Go modules: %w", err)
 	}
 	packageCollection.Add(*apkPackage)
+	normalizeLicenses(ctx, packageCollection)
 
 	if cfg.Relationships.ExcludeBinaryPackagesWithFileOwnershipOverlap {
 		// This setting is enabled by default in Syft/Grype. If it's enabled here in
@@ -187,15 +232,131 @@ func Generate(ctx context.Context, inputFilePath string, f io.Reader, distroID s
 				ID: distroID,
 			},
 		},
-		Source: getDeterministicSourceDescription(src, inputFilePath, apkPackage.Name, apkPackage.Version),
-		Descriptor: sbom.Descriptor{
-			Name: "wolfictl",
+		Source:     getDeterministicSourceDescription(src, inputFilePath, apkPackage.Name, apkPackage.Version),
+		Descriptor: newDescriptor(createdSBOM.Descriptor.Configuration),
+	}
+
+	if opts.IncludeFiles {
+		progress.setStage(GenerateStageDigesting)
+
+		digests, err := fileDigests(tempDir, includedFiles)
+		if err != nil {
+			return nil, fmt.Errorf("computing file digests: %w", err)
+		}
+		log.Debug("computed digests of APK's files", "fileCount", len(digests))
+
+		s.Artifacts.FileDigests = digests
+		s.Relationships = append(s.Relationships, fileOwnershipRelationships(*apkPackage, digests)...)
+	}
+
+	if opts.IncludeDependencies {
+		dependencies, relationships := apkDependencies(ctx, *apkPackage, distroID)
+		log.Debug("found APK's runtime dependencies", "dependencyCount", len(dependencies))
+
+		packageCollection.Add(dependencies...)
+		s.Relationships = append(s.Relationships, relationships...)
+	}
+
+	if opts.Deduplicate {
+		Deduplicate(ctx, &s)
+	}
+
+	progress.setStage(GenerateStageDone)
+
+	return &s, nil
+}
+
+// GenerateForDirectory creates an SBOM for the contents of the given directory,
+// such as an unpacked container image rootfs. Unlike Generate, the directory
+// isn't expected to be a single APK; any APKs installed in the directory are
+// cataloged from its APK database, like the other packages found there.
+func GenerateForDirectory(ctx context.Context, dir, distroID string) (*sbom.SBOM, error) {
+	log := clog.FromContext(ctx)
+
+	log.Info("generating SBOM for directory", "path", dir, "distroID", distroID)
+
+	src, err := directorysource.New(
+		directorysource.Config{
+			Path: dir,
 		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source from directory: %w", err)
+	}
+
+	syft.SetLogger(anchorelogger.NewSlogAdapter(log.Base()))
+
+	createdSBOM, err := syft.CreateSBOM(ctx, src, newCreateSBOMConfig(GenerateOptions{}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SBOM: %w", err)
+	}
+
+	packageCollection := createdSBOM.Artifacts.Packages
+	if err := refineGoModuleCPEs(packageCollection); err != nil {
+		return nil, fmt.Errorf("refining CPE data for Go modules: %w", err)
+	}
+	normalizeLicenses(ctx, packageCollection)
+
+	log.Info("finished Syft SBOM generation", "packageCount", packageCollection.PackageCount())
+
+	s := sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			Packages: packageCollection,
+			LinuxDistribution: &linux.Release{
+				ID: distroID,
+			},
+		},
+		Source:     getDeterministicSourceDescription(src, dir, path.Base(dir), ""),
+		Descriptor: newDescriptor(createdSBOM.Descriptor.Configuration),
 	}
 
 	return &s, nil
 }
 
+// newCreateSBOMConfig returns the Syft configuration used to catalog the
+// contents of APKs and directories, with the cataloger selection of opts.
+func newCreateSBOMConfig(opts GenerateOptions) *syft.CreateSBOMConfig {
+	var removals []string
+	for _, c := range DefaultDisabledCatalogers {
+		if !slices.Contains(opts.EnableCatalogers, c) {
+			removals = append(removals, c)
+		}
+	}
+	removals = append(removals, opts.DisableCatalogers...)
+
+	cfg := syft.DefaultCreateSBOMConfig().WithCatalogerSelection(
+		pkgcataloging.NewSelectionRequest().WithDefaults(
+			pkgcataloging.ImageTag,
+			filecataloging.FileTag, // see https://github.com/anchore/syft/pull/3505 for context
+		).WithAdditions(
+			opts.EnableCatalogers...,
+		).WithRemovals(
+			removals...,
+		),
+	).WithCatalogers(
+		catalogers.AngularJSReference,
+		catalogers.PipVendorReference,
+		catalogers.WheelReference,
+		catalogers.MelangeSourcesReference,
+	).WithLicenseConfig(cataloging.LicenseConfig{
+		// Syft 1.24.0 starts adding full license texts into the SBOM, and this option
+		// should prevent that (we don't need these huge license texts right now). But,
+		// emphasis on "should"... the config wasn't wired correctly in Syft until
+		// https://github.com/anchore/syft/pull/3900. So, our integration test golden
+		// files were regenerated to absorb this change (to include licenses), and then
+		// when that fix PR rolls out, the tests will fail again, and we'll need to
+		// regenerate the golden files to account for subtracting the license texts back
+		// out.
+		IncludeContent: cataloging.LicenseContentExcludeAll,
+	})
+
+	if slices.Contains(opts.EnableCatalogers, binaryClassifierCataloger) {
+		cfg.Relationships.ExcludeBinaryPackagesWithFileOwnershipOverlap = false
+	}
+
+	return cfg
+}
+
 // refineGoModuleCPEs mutates the given collection to replace some Go module
 // (Syft) packages' lists of CPEs when we believe we have a better way to assign
 // CPEs. All updated CPEs cite their wolfictl as their source.
@@ -376,14 +537,26 @@ func extractCPEFromMelangeConfiguration(melangeConfigurationReader io.Reader) (*
 }
 
 func generatePURL(info pkgInfo, distroID string) string {
-	purlQualifiers := []packageurl.Qualifier{
-		{Key: pkg.PURLQualifierArch, Value: info.Arch},
+	return apkPURL(distroID, info.PkgName, info.PkgVer, info.Arch, info.Origin)
+}
+
+// apkPURL returns the package URL of an APK. Besides using the distro as the
+// namespace, the package URL has "distro" and (when known) "arch" qualifiers,
+// so that tools matching package URLs can distinguish the distro's builds from
+// upstream packages, and from the distro's builds for other architectures.
+func apkPURL(distroID, name, version, arch, origin string) string {
+	var purlQualifiers packageurl.Qualifiers
+	if arch != "" {
+		purlQualifiers = append(purlQualifiers, packageurl.Qualifier{Key: pkg.PURLQualifierArch, Value: arch})
+	}
+	if distroID != "" {
+		purlQualifiers = append(purlQualifiers, packageurl.Qualifier{Key: pkg.PURLQualifierDistro, Value: distroID})
 	}
-	if info.Origin != "" {
-		purlQualifiers = append(purlQualifiers, packageurl.Qualifier{Key: "origin", Value: info.Origin})
+	if origin != "" {
+		purlQualifiers = append(purlQualifiers, packageurl.Qualifier{Key: "origin", Value: origin})
 	}
 
-	return packageurl.NewPackageURL(packageurl.TypeApk, distroID, info.PkgName, info.PkgVer, purlQualifiers, "").String()
+	return packageurl.NewPackageURL(packageurl.TypeApk, distroID, name, version, purlQualifiers, "").String()
 }
 
 func generateSyftCPEs(apk pkgInfo, syftPkg pkg.Package) []cpe.CPE {
@@ -414,8 +587,9 @@ func ToSyftJSON(s *sbom.SBOM) (io.ReadSeeker, error) {
 }
 
 // ToSyftJSONSchemaRedacted returns the SBOM as a reader of the Syft JSON
-// format. The returned data has schema information redacted to enable easier
-// testing (less noisy diff comparisons).
+// format. The returned data has schema information and the SBOM's provenance
+// (which includes tool versions and the generation time) redacted to enable
+// easier testing (less noisy diff comparisons).
 //
 // For most use cases, prefer ToSyftJSON over this function.
 func ToSyftJSONSchemaRedacted(s *sbom.SBOM) (io.ReadSeeker, error) {
@@ -423,6 +597,8 @@ func ToSyftJSONSchemaRedacted(s *sbom.SBOM) (io.ReadSeeker, error) {
 
 	m := syftjson.ToFormatModel(*s, syftjson.DefaultEncoderConfig())
 	m.Schema = model.Schema{}
+	m.Descriptor.Version = ""
+	m.Descriptor.Configuration = nil
 	enc := json.NewEncoder(buf)
 	enc.SetEscapeHTML(false)
 	err := enc.Encode(m)
@@ -116,6 +116,26 @@ func TestGenerate(t *testing.T) {
 	}
 }
 
+func TestGenerateForDirectory(t *testing.T) {
+	s, err := GenerateForDirectory(context.Background(), filepath.Join("testdata", "rootfs"), "wolfi")
+	if err != nil {
+		t.Fatalf("generating SBOM: %v", err)
+	}
+
+	if s.Source.Name != "rootfs" {
+		t.Errorf("source name = %q, want %q", s.Source.Name, "rootfs")
+	}
+
+	if id := s.Artifacts.LinuxDistribution.ID; id != "wolfi" {
+		t.Errorf("distro ID = %q, want %q", id, "wolfi")
+	}
+
+	pkgs := s.Artifacts.Packages.Sorted()
+	if len(pkgs) != 1 || pkgs[0].Name != "busybox" || pkgs[0].Version != "1.36.1-r0" {
+		t.Errorf("expected only the installed busybox package, got %v", pkgs)
+	}
+}
+
 func formatJSON(t *testing.T, b []byte) []byte {
 	t.Helper()
 
@@ -0,0 +1,114 @@
+package sbom
+
+import (
+	"fmt"
+	"strings"
+
+	"chainguard.dev/melange/pkg/config"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/wolfi-dev/wolfictl/pkg/sbom/catalogers"
+)
+
+// UpstreamSource is a source of a package's upstream code, as fetched by a
+// pipeline of the package's melange configuration.
+type UpstreamSource struct {
+	// URL is the URL of the source archive, or of the git repository.
+	URL string
+
+	// VCS is true if URL is a git repository (fetched by "git-checkout"), rather
+	// than an archive (fetched by "fetch").
+	VCS bool
+
+	// Commit is the expected commit of a git repository, if it's pinned.
+	Commit string
+
+	// Checksums are the expected digests of a source archive.
+	Checksums []file.Digest
+}
+
+// downloadLocation returns the source's location in the format of an SPDX
+// package download location.
+func (s UpstreamSource) downloadLocation() string {
+	if !s.VCS {
+		return s.URL
+	}
+
+	location := "git+" + s.URL
+	if s.Commit != "" {
+		location += "@" + s.Commit
+	}
+	return location
+}
+
+// String returns a description of the source, including its commit or
+// checksums.
+func (s UpstreamSource) String() string {
+	var pins []string
+	if s.Commit != "" {
+		pins = append(pins, "commit "+s.Commit)
+	}
+	for _, d := range s.Checksums {
+		pins = append(pins, d.Algorithm+":"+d.Value)
+	}
+
+	if len(pins) == 0 {
+		return s.URL
+	}
+	return fmt.Sprintf("%s (%s)", s.URL, strings.Join(pins, ", "))
+}
+
+// UpstreamSourcesByPackage maps the names of melange configurations' packages to
+// their upstream sources.
+type UpstreamSourcesByPackage map[string][]UpstreamSource
+
+// Add adds the upstream sources fetched by the pipelines (the "fetch" and
+// "git-checkout" pipelines) of the given melange configuration.
+func (m UpstreamSourcesByPackage) Add(cfg *config.Configuration) {
+	var sources []UpstreamSource
+	catalogers.WalkMelangePipelines(cfg, func(p *config.Pipeline) {
+		switch p.Uses {
+		case "fetch":
+			if p.With["uri"] == "" {
+				return
+			}
+
+			s := UpstreamSource{URL: p.With["uri"]}
+			for _, alg := range []string{"sha256", "sha512"} {
+				if v := p.With["expected-"+alg]; v != "" {
+					s.Checksums = append(s.Checksums, file.Digest{Algorithm: alg, Value: v})
+				}
+			}
+			sources = append(sources, s)
+
+		case "git-checkout":
+			if p.With["repository"] == "" {
+				return
+			}
+
+			sources = append(sources, UpstreamSource{
+				URL:    p.With["repository"],
+				VCS:    true,
+				Commit: p.With["expected-commit"],
+			})
+		}
+	})
+
+	m[cfg.Package.Name] = append(m[cfg.Package.Name], sources...)
+}
+
+// sourcesOf returns the upstream sources of the given package, which are the
+// sources of its origin package if it's an APK subpackage.
+func (m UpstreamSourcesByPackage) sourcesOf(p pkg.Package) []UpstreamSource {
+	metadata, ok := p.Metadata.(pkg.ApkDBEntry)
+	if !ok || p.Type != pkg.ApkPkg {
+		return nil
+	}
+
+	origin := metadata.OriginPackage
+	if origin == "" {
+		origin = p.Name
+	}
+
+	return m[origin]
+}
@@ -0,0 +1,109 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chainguard.dev/melange/pkg/config"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpstreamSources(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "crane.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(testMelangeConfig), 0o600))
+
+	cfg, err := config.ParseConfiguration(context.Background(), configPath)
+	require.NoError(t, err)
+
+	upstreamSources := make(UpstreamSourcesByPackage)
+	upstreamSources.Add(cfg)
+
+	const commit = "1b4e4078a545f2b6f0f0e6d4e7a3e4b5c3b2a1d0"
+	const checksum = "0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9"
+
+	assert.Equal(t, UpstreamSourcesByPackage{
+		"crane": {
+			{URL: "https://github.com/google/go-containerregistry", VCS: true, Commit: commit},
+			{URL: "https://example.com/extras/extras-0.19.1.tar.gz", Checksums: []file.Digest{{Algorithm: "sha256", Value: checksum}}},
+		},
+	}, upstreamSources)
+
+	// A subpackage has the upstream sources of its origin package.
+	apk := pkg.Package{
+		Name:    "crane-docs",
+		Version: "0.19.1-r6",
+		Type:    pkg.ApkPkg,
+		PURL:    "pkg:apk/wolfi/crane-docs@0.19.1-r6?arch=x86_64&distro=wolfi&origin=crane",
+		Metadata: pkg.ApkDBEntry{
+			Package:       "crane-docs",
+			OriginPackage: "crane",
+			Version:       "0.19.1-r6",
+			Architecture:  "x86_64",
+		},
+	}
+	apk.SetID()
+
+	s := &sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			Packages:          pkg.NewCollection(apk),
+			LinuxDistribution: &linux.Release{ID: "wolfi"},
+		},
+	}
+
+	t.Run("SPDX", func(t *testing.T) {
+		r, err := ToSPDXJSON(s, SPDXOptions{UpstreamSources: upstreamSources})
+		require.NoError(t, err)
+
+		var doc struct {
+			Packages []struct {
+				Name             string `json:"name"`
+				DownloadLocation string `json:"downloadLocation"`
+				SourceInfo       string `json:"sourceInfo"`
+			} `json:"packages"`
+		}
+		require.NoError(t, json.NewDecoder(r).Decode(&doc))
+
+		require.NotEmpty(t, doc.Packages)
+		assert.Equal(t, "crane-docs", doc.Packages[0].Name)
+		assert.Equal(t, "git+https://github.com/google/go-containerregistry@"+commit, doc.Packages[0].DownloadLocation)
+		assert.Equal(t, "built from upstream sources: https://github.com/google/go-containerregistry (commit "+commit+"); https://example.com/extras/extras-0.19.1.tar.gz (sha256:"+checksum+")", doc.Packages[0].SourceInfo)
+	})
+
+	t.Run("CycloneDX", func(t *testing.T) {
+		r, err := ToCycloneDXJSON(s, CycloneDXOptions{UpstreamSources: upstreamSources})
+		require.NoError(t, err)
+
+		type hash struct {
+			Alg     string `json:"alg"`
+			Content string `json:"content"`
+		}
+		type externalReference struct {
+			URL     string `json:"url"`
+			Type    string `json:"type"`
+			Comment string `json:"comment"`
+			Hashes  []hash `json:"hashes"`
+		}
+		var doc struct {
+			Components []struct {
+				Name               string              `json:"name"`
+				ExternalReferences []externalReference `json:"externalReferences"`
+			} `json:"components"`
+		}
+		require.NoError(t, json.NewDecoder(r).Decode(&doc))
+
+		require.NotEmpty(t, doc.Components)
+		assert.Equal(t, "crane-docs", doc.Components[0].Name)
+		assert.Equal(t, []externalReference{
+			{URL: "https://github.com/google/go-containerregistry", Type: "vcs", Comment: "commit " + commit},
+			{URL: "https://example.com/extras/extras-0.19.1.tar.gz", Type: "distribution", Comment: "source archive", Hashes: []hash{{Alg: "SHA-256", Content: checksum}}},
+		}, doc.Components[0].ExternalReferences)
+	})
+}
@@ -0,0 +1,181 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/format/common/spdxhelpers"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/google/uuid"
+	"github.com/spdx/tools-golang/spdx"
+)
+
+// DefaultSPDXNamespaceBase is the base URI of the namespaces of SPDX documents
+// created by ToSPDXJSON, unless otherwise configured.
+const DefaultSPDXNamespaceBase = "https://wolfi.dev/spdxdocs"
+
+// SPDXOptions configures the SPDX documents created by ToSPDXJSON. The zero
+// value is a valid configuration.
+type SPDXOptions struct {
+	// NamespaceBase is the base URI of the document's namespace. A unique
+	// namespace is generated for each document, by appending the document's name
+	// and a random UUID. If empty, DefaultSPDXNamespaceBase is used.
+	NamespaceBase string
+
+	// ToolVersion is the version of wolfictl, which is included in the document's
+	// creator information.
+	ToolVersion string
+
+	// Created is the document's creation time. If zero, the current time is used.
+	Created time.Time
+
+	// UpstreamSources, if set, are the upstream sources of the SBOM's APKs, which
+	// are used as the APK packages' download locations and source information.
+	UpstreamSources UpstreamSourcesByPackage
+}
+
+// ToSPDXJSON returns the SBOM as a reader of an SPDX 2.3 JSON document.
+//
+// In addition to the relationships in the SBOM, the document states that the
+// APK package contains every other package in the SBOM, since those packages
+// were all found within the APK. Dependency relationships are stated from the
+// dependent package (as DEPENDS_ON), which is what graph-based consumers expect.
+//
+// Given their upstream sources, an APK package's download location is its first
+// upstream source (e.g. "git+https://github.com/org/repo@<commit>"), and its
+// source information lists all of them, with their commits or checksums.
+//
+// The SBOM's provenance is included in the document's creation information: the
+// version of Syft is listed as a creator, and the provenance is encoded as JSON
+// in the creator comment.
+func ToSPDXJSON(s *sbom.SBOM, opts SPDXOptions) (io.ReadSeeker, error) {
+	withAPKRelationships := *s
+	withAPKRelationships.Relationships = append(slices.Clone(s.Relationships), apkContainsRelationships(s)...)
+
+	doc := spdxhelpers.ToFormatModel(withAPKRelationships)
+	if doc == nil {
+		return nil, fmt.Errorf("unable to convert SBOM to SPDX document")
+	}
+
+	doc.DocumentNamespace = spdxDocumentNamespace(opts.NamespaceBase, doc.DocumentName)
+
+	for _, r := range doc.Relationships {
+		if r.Relationship == spdx.RelationshipDependencyOf {
+			r.RefA, r.RefB = r.RefB, r.RefA
+			r.Relationship = spdx.RelationshipDependsOn
+		}
+	}
+
+	addSPDXUpstreamSources(doc, s.Artifacts.Packages, opts.UpstreamSources)
+
+	tool := "wolfictl"
+	if opts.ToolVersion != "" {
+		tool += "-" + opts.ToolVersion
+	}
+	doc.CreationInfo.Creators = []spdx.Creator{
+		{CreatorType: "Tool", Creator: tool},
+	}
+
+	provenance, err := ProvenanceOf(s)
+	if err != nil {
+		return nil, err
+	}
+	if provenance != nil {
+		doc.CreationInfo.Creators = append(doc.CreationInfo.Creators, spdx.Creator{CreatorType: "Tool", Creator: "syft-" + provenance.SyftVersion})
+
+		b, err := json.Marshal(provenance)
+		if err != nil {
+			return nil, fmt.Errorf("encoding SBOM provenance: %w", err)
+		}
+		doc.CreationInfo.CreatorComment = string(b)
+	}
+
+	created := opts.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+	doc.CreationInfo.Created = created.UTC().Format(time.RFC3339)
+
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode SPDX document: %w", err)
+	}
+
+	return bytes.NewReader(buf.Bytes()), nil
+}
+
+// apkContainsRelationships returns "contains" relationships from the SBOM's APK
+// package to each of the SBOM's other packages. If the SBOM doesn't have exactly
+// one APK package (e.g. because it's an SBOM of a directory), or it already has
+// relationships from the APK package to other packages (e.g. because it was
+// created by Compose), there are none.
+func apkContainsRelationships(s *sbom.SBOM) []artifact.Relationship {
+	apks := s.Artifacts.Packages.Sorted(pkg.ApkPkg)
+	if len(apks) != 1 {
+		return nil
+	}
+	apk := apks[0]
+
+	for _, r := range s.Relationships {
+		if _, ok := r.To.(pkg.Package); ok && r.From.ID() == apk.ID() {
+			return nil
+		}
+	}
+
+	return containsRelationships(apk, s.Artifacts.Packages)
+}
+
+// addSPDXUpstreamSources sets the download location and source information of
+// the document's APK packages that have known upstream sources.
+func addSPDXUpstreamSources(doc *spdx.Document, packages *pkg.Collection, upstreamSources UpstreamSourcesByPackage) {
+	if len(upstreamSources) == 0 {
+		return
+	}
+
+	sourcesByNameVersion := make(map[string][]UpstreamSource)
+	for _, p := range packages.Sorted(pkg.ApkPkg) {
+		if sources := upstreamSources.sourcesOf(p); len(sources) > 0 {
+			sourcesByNameVersion[p.Name+"@"+p.Version] = sources
+		}
+	}
+
+	for _, p := range doc.Packages {
+		sources := sourcesByNameVersion[p.PackageName+"@"+p.PackageVersion]
+		if len(sources) == 0 {
+			continue
+		}
+
+		p.PackageDownloadLocation = sources[0].downloadLocation()
+
+		descriptions := make([]string, 0, len(sources))
+		for _, s := range sources {
+			descriptions = append(descriptions, s.String())
+		}
+		p.PackageSourceInfo = "built from upstream sources: " + strings.Join(descriptions, "; ")
+	}
+}
+
+// spdxDocumentNamespace returns a new, unique namespace URI for the SPDX
+// document with the given name.
+func spdxDocumentNamespace(base, name string) string {
+	if base == "" {
+		base = DefaultSPDXNamespaceBase
+	}
+
+	// The namespace can't contain "#", which SPDX uses to separate the namespace
+	// from element IDs.
+	name = strings.NewReplacer("#", "-", ":", "-", "/", "-").Replace(name)
+
+	return strings.TrimSuffix(base, "/") + "/" + url.PathEscape(path.Clean(name)+"-"+uuid.NewString())
+}
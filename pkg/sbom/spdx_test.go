@@ -0,0 +1,97 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSPDXJSON(t *testing.T) {
+	apk := pkg.Package{
+		Name:     "crane",
+		Version:  "0.19.1-r6",
+		Type:     pkg.ApkPkg,
+		Licenses: pkg.NewLicenseSet(pkg.NewLicense("Apache-2.0")),
+		PURL:     "pkg:apk/wolfi/crane@0.19.1-r6?arch=x86_64",
+	}
+	apk.SetID()
+
+	goModule := pkg.Package{
+		Name:     "github.com/google/go-containerregistry",
+		Version:  "v0.19.1",
+		Type:     pkg.GoModulePkg,
+		Licenses: pkg.NewLicenseSet(pkg.NewLicense("Apache-2.0")),
+		PURL:     "pkg:golang/github.com/google/go-containerregistry@v0.19.1",
+	}
+	goModule.SetID()
+
+	s := &sbom.SBOM{
+		Artifacts: sbom.Artifacts{
+			Packages: pkg.NewCollection(apk, goModule),
+		},
+		Source: source.Description{
+			Name:    "crane-0.19.1-r6.apk",
+			Version: "0.19.1-r6",
+		},
+	}
+
+	created := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	r, err := ToSPDXJSON(s, SPDXOptions{
+		NamespaceBase: "https://example.com/spdx/",
+		ToolVersion:   "v1.2.3",
+		Created:       created,
+	})
+	require.NoError(t, err)
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	var doc spdx.Document
+	require.NoError(t, json.Unmarshal(b, &doc))
+
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.True(t, strings.HasPrefix(doc.DocumentNamespace, "https://example.com/spdx/crane-0.19.1-r6.apk-"), "unexpected namespace %q", doc.DocumentNamespace)
+	assert.Equal(t, "2024-07-01T12:00:00Z", doc.CreationInfo.Created)
+	assert.Equal(t, []spdx.Creator{{CreatorType: "Tool", Creator: "wolfictl-v1.2.3"}}, doc.CreationInfo.Creators)
+
+	ids := make(map[string]spdx.ElementID)
+	for _, p := range doc.Packages {
+		ids[p.PackageName] = p.PackageSPDXIdentifier
+	}
+	require.Contains(t, ids, "crane")
+	require.Contains(t, ids, "github.com/google/go-containerregistry")
+
+	for _, p := range doc.Packages {
+		if p.PackageName == "crane" {
+			assert.Equal(t, "Apache-2.0", p.PackageLicenseDeclared)
+		}
+	}
+
+	var apkContainsGoModule bool
+	for _, rel := range doc.Relationships {
+		if rel.Relationship == "CONTAINS" && rel.RefA.ElementRefID == ids["crane"] && rel.RefB.ElementRefID == ids["github.com/google/go-containerregistry"] {
+			apkContainsGoModule = true
+		}
+	}
+	assert.True(t, apkContainsGoModule, "missing relationship: APK package CONTAINS Go module")
+
+	t.Run("unique namespaces", func(t *testing.T) {
+		r, err := ToSPDXJSON(s, SPDXOptions{})
+		require.NoError(t, err)
+
+		var other spdx.Document
+		require.NoError(t, json.NewDecoder(r).Decode(&other))
+
+		assert.True(t, strings.HasPrefix(other.DocumentNamespace, DefaultSPDXNamespaceBase+"/"))
+		assert.NotEqual(t, doc.DocumentNamespace, other.DocumentNamespace)
+	})
+}
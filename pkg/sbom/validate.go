@@ -0,0 +1,316 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	cyclonedx "github.com/CycloneDX/cyclonedx-go"
+	spdxjson "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/wolfi-dev/wolfictl/pkg/internal/errorhelpers"
+)
+
+// ValidateOptions configures Validate.
+type ValidateOptions struct {
+	// NTIAOnly, if true, checks only the NTIA minimum elements, and not
+	// wolfictl's own conventions (such as every component having a package URL
+	// and a checksum).
+	NTIAOnly bool
+}
+
+// validationDocument is the format-independent subset of an SBOM that Validate
+// checks.
+type validationDocument struct {
+	authors       []string
+	timestamp     string
+	relationships int
+	components    []validationComponent
+
+	// fields names the format's field for each element, for use in error
+	// messages.
+	fields validationFields
+}
+
+type validationComponent struct {
+	ref         string
+	name        string
+	version     string
+	supplier    string
+	purl        string
+	identifiers []string
+	checksums   int
+}
+
+type validationFields struct {
+	author, timestamp, relationships                     string
+	name, version, supplier, identifier, purl, checksums string
+}
+
+var spdxValidationFields = validationFields{
+	author:        "creationInfo.creators",
+	timestamp:     "creationInfo.created",
+	relationships: "relationships",
+	name:          "name",
+	version:       "versionInfo",
+	supplier:      "supplier",
+	identifier:    `externalRefs (of type "purl" or "cpe23Type")`,
+	purl:          `externalRefs (of type "purl")`,
+	checksums:     "checksums",
+}
+
+var cycloneDXValidationFields = validationFields{
+	author:        "metadata.authors (or metadata.tools)",
+	timestamp:     "metadata.timestamp",
+	relationships: "dependencies",
+	name:          "name",
+	version:       "version",
+	supplier:      "supplier.name",
+	identifier:    "purl (or cpe)",
+	purl:          "purl",
+	checksums:     "hashes",
+}
+
+// Validate checks the SBOM read from r against the NTIA minimum elements for an
+// SBOM (see https://www.ntia.gov/report/2021/minimum-elements-software-bill-materials-sbom)
+// and, unless opts.NTIAOnly is set, against wolfictl's conventions for SBOMs.
+// The SBOM can be an SPDX 2.x JSON or a CycloneDX JSON document.
+//
+// The returned error describes every problem found, grouped by component, and
+// names the field of the SBOM's format that's missing. If the SBOM is valid,
+// Validate returns nil.
+func Validate(r io.Reader, opts ValidateOptions) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("reading SBOM: %w", err)
+	}
+
+	doc, err := decodeValidationDocument(b)
+	if err != nil {
+		return err
+	}
+
+	var ntiaErrs, conventionErrs []error
+
+	var docErrs []error
+	if len(doc.authors) == 0 {
+		docErrs = append(docErrs, fmt.Errorf("missing author of SBOM data (set %s)", doc.fields.author))
+	}
+	if doc.timestamp == "" {
+		docErrs = append(docErrs, fmt.Errorf("missing timestamp (set %s)", doc.fields.timestamp))
+	}
+	if doc.relationships == 0 && len(doc.components) > 1 {
+		docErrs = append(docErrs, fmt.Errorf("missing dependency relationships between components (set %s)", doc.fields.relationships))
+	}
+	if len(doc.components) == 0 {
+		docErrs = append(docErrs, errors.New("SBOM has no components"))
+	}
+	ntiaErrs = append(ntiaErrs, errorhelpers.LabelError("document", errors.Join(docErrs...)))
+
+	for _, c := range doc.components {
+		var cNTIAErrs, cConventionErrs []error
+
+		if c.name == "" {
+			cNTIAErrs = append(cNTIAErrs, fmt.Errorf("missing component name (set %s)", doc.fields.name))
+		}
+		if c.version == "" {
+			cNTIAErrs = append(cNTIAErrs, fmt.Errorf("missing version (set %s)", doc.fields.version))
+		}
+		if c.supplier == "" {
+			cNTIAErrs = append(cNTIAErrs, fmt.Errorf("missing supplier name (set %s)", doc.fields.supplier))
+		}
+		if len(c.identifiers) == 0 {
+			cNTIAErrs = append(cNTIAErrs, fmt.Errorf("missing unique identifier (set %s)", doc.fields.identifier))
+		}
+
+		if c.purl == "" {
+			cConventionErrs = append(cConventionErrs, fmt.Errorf("missing package URL (set %s)", doc.fields.purl))
+		}
+		if c.checksums == 0 {
+			cConventionErrs = append(cConventionErrs, fmt.Errorf("missing checksum (set %s)", doc.fields.checksums))
+		}
+
+		label := fmt.Sprintf("component %q", c.displayName())
+		ntiaErrs = append(ntiaErrs, errorhelpers.LabelError(label, errors.Join(cNTIAErrs...)))
+		conventionErrs = append(conventionErrs, errorhelpers.LabelError(label, errors.Join(cConventionErrs...)))
+	}
+
+	errs := []error{
+		errorhelpers.LabelError("NTIA minimum element failure(s)", errors.Join(ntiaErrs...)),
+	}
+	if !opts.NTIAOnly {
+		errs = append(errs, errorhelpers.LabelError("wolfictl convention failure(s)", errors.Join(conventionErrs...)))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c validationComponent) displayName() string {
+	name := c.name
+	if name == "" {
+		name = c.ref
+	}
+	if c.version != "" {
+		name += "@" + c.version
+	}
+	return name
+}
+
+// decodeValidationDocument detects the format of the given SBOM and decodes it.
+func decodeValidationDocument(b []byte) (*validationDocument, error) {
+	var header struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(b, &header); err != nil {
+		return nil, fmt.Errorf("unable to decode SBOM as JSON: %w", err)
+	}
+
+	switch {
+	case strings.HasPrefix(header.SPDXVersion, "SPDX-2."):
+		doc, err := spdxjson.Read(bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("decoding SPDX document: %w", err)
+		}
+		return validationDocumentFromSPDX(doc), nil
+
+	case header.BOMFormat == cyclonedx.BOMFormat:
+		bom := new(cyclonedx.BOM)
+		if err := cyclonedx.NewBOMDecoder(bytes.NewReader(b), cyclonedx.BOMFileFormatJSON).Decode(bom); err != nil {
+			return nil, fmt.Errorf("decoding CycloneDX document: %w", err)
+		}
+		return validationDocumentFromCycloneDX(bom), nil
+	}
+
+	return nil, errors.New("unsupported SBOM format, expected an SPDX 2.x JSON or CycloneDX JSON document (Syft JSON doesn't have fields for all of the NTIA minimum elements)")
+}
+
+func validationDocumentFromSPDX(doc *spdx.Document) *validationDocument {
+	d := &validationDocument{fields: spdxValidationFields}
+
+	if ci := doc.CreationInfo; ci != nil {
+		for _, c := range ci.Creators {
+			if c.Creator != "" {
+				d.authors = append(d.authors, c.Creator)
+			}
+		}
+		d.timestamp = ci.Created
+	}
+
+	for _, r := range doc.Relationships {
+		if r == nil || r.Relationship == spdx.RelationshipDescribes {
+			continue
+		}
+		d.relationships++
+	}
+
+	for _, p := range doc.Packages {
+		if p == nil {
+			continue
+		}
+
+		c := validationComponent{
+			ref:       string(p.PackageSPDXIdentifier),
+			name:      p.PackageName,
+			version:   p.PackageVersion,
+			checksums: len(p.PackageChecksums),
+		}
+		if s := p.PackageSupplier; s != nil && s.Supplier != "NOASSERTION" {
+			c.supplier = s.Supplier
+		}
+		for _, ref := range p.PackageExternalReferences {
+			if ref == nil {
+				continue
+			}
+			switch ref.RefType {
+			case spdx.PackageManagerPURL:
+				c.purl = ref.Locator
+				c.identifiers = append(c.identifiers, ref.Locator)
+			case spdx.SecurityCPE23Type, spdx.SecurityCPE22Type:
+				c.identifiers = append(c.identifiers, ref.Locator)
+			}
+		}
+
+		d.components = append(d.components, c)
+	}
+
+	return d
+}
+
+func validationDocumentFromCycloneDX(bom *cyclonedx.BOM) *validationDocument {
+	d := &validationDocument{fields: cycloneDXValidationFields}
+
+	if m := bom.Metadata; m != nil {
+		if m.Authors != nil {
+			for _, a := range *m.Authors {
+				if a.Name != "" {
+					d.authors = append(d.authors, a.Name)
+				}
+			}
+		}
+		if m.Tools != nil && m.Tools.Components != nil {
+			for _, t := range *m.Tools.Components {
+				if t.Name != "" {
+					d.authors = append(d.authors, t.Name)
+				}
+			}
+		}
+		if m.Tools != nil && m.Tools.Tools != nil {
+			// Tools as they were listed before CycloneDX 1.5.
+			for _, t := range *m.Tools.Tools {
+				if t.Name != "" {
+					d.authors = append(d.authors, t.Name)
+				}
+			}
+		}
+		d.timestamp = m.Timestamp
+	}
+
+	if bom.Dependencies != nil {
+		for _, dep := range *bom.Dependencies {
+			if dep.Dependencies != nil {
+				d.relationships += len(*dep.Dependencies)
+			}
+		}
+	}
+
+	var walk func([]cyclonedx.Component)
+	walk = func(components []cyclonedx.Component) {
+		for i := range components {
+			cc := components[i]
+
+			c := validationComponent{
+				ref:     cc.BOMRef,
+				name:    cc.Name,
+				version: cc.Version,
+				purl:    cc.PackageURL,
+			}
+			if cc.Supplier != nil {
+				c.supplier = cc.Supplier.Name
+			}
+			if cc.PackageURL != "" {
+				c.identifiers = append(c.identifiers, cc.PackageURL)
+			}
+			if cc.CPE != "" {
+				c.identifiers = append(c.identifiers, cc.CPE)
+			}
+			if cc.Hashes != nil {
+				c.checksums = len(*cc.Hashes)
+			}
+
+			d.components = append(d.components, c)
+
+			if cc.Components != nil {
+				walk(*cc.Components)
+			}
+		}
+	}
+	if bom.Components != nil {
+		walk(*bom.Components)
+	}
+
+	return d
+}
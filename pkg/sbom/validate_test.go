@@ -0,0 +1,96 @@
+package sbom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validSPDXDocument = `{
+  "spdxVersion": "SPDX-2.3",
+  "dataLicense": "CC0-1.0",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "crane-0.19.1-r6.apk",
+  "documentNamespace": "https://wolfi.dev/spdxdocs/crane-0.19.1-r6.apk-1",
+  "creationInfo": {
+    "creators": ["Tool: wolfictl-v1.2.3", "Organization: Wolfi"],
+    "created": "2024-07-01T12:00:00Z"
+  },
+  "packages": [
+    {
+      "SPDXID": "SPDXRef-Package-apk-crane",
+      "name": "crane",
+      "versionInfo": "0.19.1-r6",
+      "supplier": "Organization: Wolfi",
+      "downloadLocation": "NOASSERTION",
+      "checksums": [{"algorithm": "SHA256", "checksumValue": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"}],
+      "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:apk/wolfi/crane@0.19.1-r6?arch=x86_64&distro=wolfi"}]
+    },
+    {
+      "SPDXID": "SPDXRef-Package-go-module-ggcr",
+      "name": "github.com/google/go-containerregistry",
+      "versionInfo": "v0.19.1",
+      "supplier": "Organization: Google LLC",
+      "downloadLocation": "NOASSERTION",
+      "checksums": [{"algorithm": "SHA256", "checksumValue": "60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"}],
+      "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:golang/github.com/google/go-containerregistry@v0.19.1"}]
+    }
+  ],
+  "relationships": [
+    {"spdxElementId": "SPDXRef-Package-apk-crane", "relatedSpdxElement": "SPDXRef-Package-go-module-ggcr", "relationshipType": "CONTAINS"}
+  ]
+}`
+
+const incompleteCycloneDXDocument = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "version": 1,
+  "metadata": {
+    "tools": {"components": [{"type": "application", "name": "wolfictl"}]}
+  },
+  "components": [
+    {
+      "bom-ref": "crane",
+      "type": "library",
+      "name": "crane",
+      "version": "0.19.1-r6",
+      "supplier": {"name": "Wolfi"},
+      "purl": "pkg:apk/wolfi/crane@0.19.1-r6?arch=x86_64&distro=wolfi"
+    },
+    {
+      "bom-ref": "ggcr",
+      "type": "library",
+      "name": "github.com/google/go-containerregistry",
+      "cpe": "cpe:2.3:a:google:go-containerregistry:v0.19.1:*:*:*:*:go:*:*"
+    }
+  ]
+}`
+
+func TestValidate(t *testing.T) {
+	t.Run("valid SPDX", func(t *testing.T) {
+		assert.NoError(t, Validate(strings.NewReader(validSPDXDocument), ValidateOptions{}))
+	})
+
+	t.Run("incomplete CycloneDX", func(t *testing.T) {
+		err := Validate(strings.NewReader(incompleteCycloneDXDocument), ValidateOptions{})
+		assert.EqualError(t, err, `NTIA minimum element failure(s): document: missing timestamp (set metadata.timestamp)
+missing dependency relationships between components (set dependencies)
+component "github.com/google/go-containerregistry": missing version (set version)
+missing supplier name (set supplier.name)
+wolfictl convention failure(s): component "crane@0.19.1-r6": missing checksum (set hashes)
+component "github.com/google/go-containerregistry": missing package URL (set purl)
+missing checksum (set hashes)`)
+	})
+
+	t.Run("NTIA only", func(t *testing.T) {
+		err := Validate(strings.NewReader(incompleteCycloneDXDocument), ValidateOptions{NTIAOnly: true})
+		assert.ErrorContains(t, err, "missing supplier name")
+		assert.NotContains(t, err.Error(), "convention")
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		err := Validate(strings.NewReader(`{"artifacts": [], "schema": {"version": "16.0.14"}}`), ValidateOptions{})
+		assert.ErrorContains(t, err, "unsupported SBOM format")
+	})
+}
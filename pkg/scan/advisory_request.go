@@ -0,0 +1,57 @@
+package scan
+
+import (
+	"slices"
+
+	cgaid "github.com/chainguard-dev/advisory-schema/pkg/advisory"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/chainguard-dev/advisory-schema/pkg/vuln"
+	"github.com/wolfi-dev/wolfictl/pkg/advisory"
+)
+
+// DetectionRequest returns an advisory request that records the detection of
+// the finding in the result's APK, for the APK's origin package.
+//
+// The request identifies the vulnerability by the finding's CGA ID, if it has
+// one, and otherwise by the finding's vulnerability ID and the aliases of it
+// that are valid advisory aliases. The event is a "scan/v1" detection of the
+// finding's component.
+func DetectionRequest(result Result, finding Finding, timestamp v2.Timestamp) advisory.Request {
+	req := advisory.Request{
+		Package: result.TargetAPK.Origin(),
+		Event: v2.Event{
+			Timestamp: timestamp,
+			Type:      v2.EventTypeDetection,
+			Data: v2.Detection{
+				Type: v2.DetectionTypeScanV1,
+				Data: v2.DetectionScanV1{
+					SubpackageName:    result.TargetAPK.Name,
+					ComponentID:       finding.Package.ID,
+					ComponentName:     finding.Package.Name,
+					ComponentVersion:  finding.Package.Version,
+					ComponentType:     finding.Package.Type,
+					ComponentLocation: finding.Package.Location,
+					Scanner:           v2.DetectionScannerGrype,
+				},
+			},
+		},
+	}
+
+	if finding.CGAID != "" {
+		req.AdvisoryID = finding.CGAID
+	}
+
+	for _, id := range append([]string{finding.Vulnerability.ID}, finding.Vulnerability.Aliases...) {
+		switch {
+		case cgaid.RegexCGA.MatchString(id):
+			if req.AdvisoryID == "" {
+				req.AdvisoryID = id
+			}
+
+		case vuln.ValidateID(id) == nil && !slices.Contains(req.Aliases, id):
+			req.Aliases = append(req.Aliases, id)
+		}
+	}
+
+	return req
+}
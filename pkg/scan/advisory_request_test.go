@@ -0,0 +1,68 @@
+package scan
+
+import (
+	"testing"
+	"time"
+
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectionRequest(t *testing.T) {
+	timestamp := v2.Timestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	result := Result{
+		TargetAPK: TargetAPK{
+			Name:              "libcrypto3",
+			Version:           "3.0.11-r0",
+			OriginPackageName: "openssl",
+		},
+	}
+	finding := Finding{
+		Package: Package{
+			ID:       "abc123",
+			Name:     "github.com/foo/bar",
+			Version:  "v1.2.3",
+			Type:     "go-module",
+			Location: "/usr/bin/bar",
+		},
+		Vulnerability: Vulnerability{
+			ID:      "GHSA-2222-2222-2222",
+			Aliases: []string{"CVE-2023-1234", "GHSA-2222-2222-2222", "not-an-id"},
+		},
+	}
+
+	t.Run("without CGA ID", func(t *testing.T) {
+		req := DetectionRequest(result, finding, timestamp)
+		require.NoError(t, req.Validate())
+
+		assert.Equal(t, "openssl", req.Package)
+		assert.Empty(t, req.AdvisoryID)
+		assert.Equal(t, []string{"GHSA-2222-2222-2222", "CVE-2023-1234"}, req.Aliases)
+		assert.Equal(t, v2.Event{
+			Timestamp: timestamp,
+			Type:      v2.EventTypeDetection,
+			Data: v2.Detection{
+				Type: v2.DetectionTypeScanV1,
+				Data: v2.DetectionScanV1{
+					SubpackageName:    "libcrypto3",
+					ComponentID:       "abc123",
+					ComponentName:     "github.com/foo/bar",
+					ComponentVersion:  "v1.2.3",
+					ComponentType:     "go-module",
+					ComponentLocation: "/usr/bin/bar",
+					Scanner:           v2.DetectionScannerGrype,
+				},
+			},
+		}, req.Event)
+	})
+
+	t.Run("with CGA ID", func(t *testing.T) {
+		f := finding
+		f.CGAID = "CGA-xxxx-xxxx-xxxx"
+
+		req := DetectionRequest(result, f, timestamp)
+		assert.Equal(t, "CGA-xxxx-xxxx-xxxx", req.AdvisoryID)
+		assert.Equal(t, []string{"GHSA-2222-2222-2222", "CVE-2023-1234"}, req.Aliases)
+	})
+}
@@ -3,6 +3,7 @@ package scan
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -12,6 +13,7 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/adrg/xdg"
@@ -49,9 +51,22 @@ const (
 var DefaultGrypeDBDir = path.Join(xdg.CacheHome, "wolfictl", "grype", "db")
 
 type Result struct {
+	// SchemaVersion is the version of the JSON encoding of the result (see
+	// ResultSchemaVersion and ResultJSONSchema).
+	SchemaVersion string `json:"schemaVersion"`
+
 	TargetAPK  TargetAPK
 	Findings   []Finding
 	DataSource DataSource
+
+	// Suppressed holds the findings that were removed from Findings by the rules
+	// of an IgnoreFile.
+	Suppressed []SuppressedFinding `json:",omitempty"`
+
+	// BaselineDataSource, if set, describes an older vulnerability database that
+	// the target was also scanned with. Findings only includes the findings that
+	// the baseline database didn't produce, i.e. newly published vulnerabilities.
+	BaselineDataSource *DataSource `json:",omitempty"`
 }
 
 // DataSource describes the underlying data used during the vulnerability scan,
@@ -130,12 +145,20 @@ func newTargetAPK(s *sbomSyft.SBOM) (TargetAPK, error) {
 	}, nil
 }
 
+// Scanner scans APKs for vulnerabilities. A Scanner is safe for concurrent
+// use, which allows multiple scans to share a single loaded vulnerability
+// database (see ScanOrdered).
 type Scanner struct {
 	vulnProvider         vulnerability.Provider
 	dbStatus             *vulnerability.ProviderStatus
 	dbChecksum           string
 	vulnerabilityMatcher *grype.VulnerabilityMatcher
 	disableSBOMCache     bool
+	useCPEs              bool
+	matchers             *matcherSelection
+	cpeOverrides         *CPEOverrides
+	sources              []VulnerabilitySource
+	setGrypeLoggerOnce   sync.Once
 }
 
 // Options determine the configuration for a new Scanner. The zero-value of this
@@ -163,41 +186,133 @@ type Options struct {
 	// DisableDatabaseAgeValidation controls whether the scanner will validate the
 	// age of the vulnerability database before using it. If true, the scanner will
 	// not validate the age of the database. This bool should always be set to false
-	// except for testing purposes.
+	// except for testing purposes, or for a database that's meant to be old (such
+	// as a baseline to compare against).
 	DisableDatabaseAgeValidation bool
 
+	// MaxDatabaseAge is the maximum allowed age of the vulnerability database, as
+	// measured from when the database was built. If zero, DefaultMaxDatabaseAge is
+	// used.
+	MaxDatabaseAge time.Duration
+
+	// DatabaseAgePolicy determines what happens when the vulnerability database is
+	// older than MaxDatabaseAge (see ValidDatabaseAgePolicies). If empty,
+	// DatabaseAgePolicyRefresh is used.
+	DatabaseAgePolicy string
+
 	// DisableSBOMCache controls whether the scanner will cache SBOMs generated from
 	// APKs. If true, the scanner will not cache SBOMs or use existing cached SBOMs.
 	DisableSBOMCache bool
+
+	// Offline prevents the scanner from checking for (or downloading) database
+	// updates. Since an offline database can't be kept up to date, its age isn't
+	// validated either; a warning is logged instead when the database is stale.
+	// The database must already be installed (e.g. using ImportBundle), or be
+	// provided using PathOfDatabaseArchiveToImport.
+	Offline bool
+
+	// EnabledMatchers, if not empty, limits vulnerability matching to the named
+	// Grype matchers (see ValidMatchers). Packages that would be handled by any
+	// other matcher are skipped. For example, to only look for distro-level
+	// vulnerabilities, use []string{"apk"}.
+	EnabledMatchers []string
+
+	// DatabaseURL, if set, is the URL from which to download the Grype
+	// vulnerability database, instead of Grype's default
+	// (https://grype.anchore.io/databases). This is useful for an internal mirror,
+	// which must have the same layout as the default location: the URL refers to
+	// the directory containing the schema-versioned listing files (e.g.
+	// "v6/latest.json"), or directly to a listing file. Database archives are
+	// resolved relative to the listing file, verified using the checksums it
+	// lists, and downloaded in a way that can be resumed if interrupted.
+	DatabaseURL string
+
+	// DisabledMatchers names the Grype matchers (see ValidMatchers) that won't be
+	// used during vulnerability matching. Packages that would be handled by these
+	// matchers are skipped.
+	DisabledMatchers []string
+
+	// CPEOverrides, if set, replaces or removes the CPEs of specific packages
+	// before they're matched against vulnerabilities.
+	CPEOverrides *CPEOverrides
+
+	// Sources are additional sources of vulnerability data, which are queried for
+	// the scanned packages (other than APKs) after matching against the Grype
+	// database. Their findings are merged with the Grype matches, and every
+	// finding's Sources lists where it came from.
+	Sources []VulnerabilitySource
 }
 
+// DefaultMaxDatabaseAge is the maximum allowed age of the vulnerability
+// database, unless otherwise configured.
+const DefaultMaxDatabaseAge = 48 * time.Hour
+
+const (
+	// DatabaseAgePolicyRefresh re-downloads a database that's too old, failing if
+	// a new enough database can't be downloaded. In offline mode, where
+	// downloading isn't possible, a warning is logged instead.
+	DatabaseAgePolicyRefresh = "refresh"
+
+	// DatabaseAgePolicyFail fails when the database is too old, without trying to
+	// download a new one first. (A newer database may still be downloaded by the
+	// scanner's regular update check.)
+	DatabaseAgePolicyFail = "fail"
+
+	// DatabaseAgePolicyWarn logs a warning when the database is too old, and uses
+	// it anyway.
+	DatabaseAgePolicyWarn = "warn"
+)
+
+// ValidDatabaseAgePolicies are the policies that can be used for
+// Options.DatabaseAgePolicy.
+var ValidDatabaseAgePolicies = []string{DatabaseAgePolicyRefresh, DatabaseAgePolicyFail, DatabaseAgePolicyWarn}
+
+// ErrDatabaseTooOld is returned by NewScanner when the vulnerability database is
+// older than the maximum allowed age.
+var ErrDatabaseTooOld = errors.New("vulnerability database is too old")
+
 // DefaultOptions is the recommended default configuration for a new Scanner.
 // These options are suitable for most use scanning cases.
 var DefaultOptions = Options{}
 
 // NewScanner initializes the grype DB for reuse across multiple scans.
 func NewScanner(opts Options) (*Scanner, error) {
-	dbDestDir := opts.PathOfDatabaseDestinationDirectory
-	if dbDestDir == "" {
-		dbDestDir = DefaultGrypeDBDir
+	matchers, err := newMatcherSelection(opts.EnabledMatchers, opts.DisabledMatchers)
+	if err != nil {
+		return nil, err
 	}
 
-	installCfg := installation.Config{
-		DBRootDir:               dbDestDir,
-		ValidateChecksum:        true,
-		ValidateAge:             !opts.DisableDatabaseAgeValidation,
-		MaxAllowedBuiltAge:      48 * time.Hour,
-		UpdateCheckMaxFrequency: 1 * time.Hour,
+	policy := opts.DatabaseAgePolicy
+	if policy == "" {
+		policy = DatabaseAgePolicyRefresh
 	}
+	if !slices.Contains(ValidDatabaseAgePolicies, policy) {
+		return nil, fmt.Errorf("invalid database age policy %q", policy)
+	}
+
+	installCfg := newInstallationConfig(opts.PathOfDatabaseDestinationDirectory)
+	if opts.MaxDatabaseAge > 0 {
+		installCfg.MaxAllowedBuiltAge = opts.MaxDatabaseAge
+	}
+
+	// When updating, Grype's age validation makes sure that a database that's too
+	// old gets replaced, even if an update check was done recently. But when
+	// loading the database, the age is checked below, according to the policy.
+	updateCfg := installCfg
+	installCfg.ValidateAge = false
 
 	distCfg := distribution.DefaultConfig()
+	if opts.DatabaseURL != "" {
+		distCfg.LatestURL = opts.DatabaseURL
+	}
 
-	distClient, err := distribution.NewClient(distCfg)
+	grypeDistClient, err := distribution.NewClient(distCfg)
 	if err != nil {
 		return nil, fmt.Errorf("creating distribution client: %w", err)
 	}
+	distClient := newResumableClient(grypeDistClient, distCfg.UpdateTimeout)
 
-	updateDB := true
+	updateDB := !opts.Offline
 	var checksum string
 	if dbArchivePath := opts.PathOfDatabaseArchiveToImport; dbArchivePath != "" {
 		fmt.Fprintf(os.Stderr, "using local grype DB archive %q...\n", dbArchivePath)
@@ -225,11 +340,65 @@ func NewScanner(opts Options) (*Scanner, error) {
 		updateDB = false
 	}
 
-	vulnProvider, dbStatus, err := grype.LoadVulnerabilityDB(distCfg, installCfg, updateDB)
+	var updateErr error
+	if updateDB {
+		// Grype would download the database itself, but we use our own client so
+		// that downloads can be resumed.
+		updateErr = updateDatabase(updateCfg, distClient)
+		if updateErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: unable to update vulnerability database from %s: %v\n", distCfg.LatestURL, updateErr)
+		}
+	}
+
+	vulnProvider, dbStatus, err := grype.LoadVulnerabilityDB(distCfg, installCfg, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load vulnerability database: %w", err)
 	}
 
+	if age := time.Since(dbStatus.Built); age > installCfg.MaxAllowedBuiltAge && !opts.DisableDatabaseAgeValidation {
+		tooOldErr := fmt.Errorf(
+			"%w: it was built %s ago (at %s), but the maximum allowed age is %s",
+			ErrDatabaseTooOld,
+			age.Round(time.Minute),
+			dbStatus.Built.Format(time.RFC3339),
+			installCfg.MaxAllowedBuiltAge,
+		)
+
+		switch {
+		case policy == DatabaseAgePolicyWarn, policy == DatabaseAgePolicyRefresh && opts.Offline:
+			fmt.Fprintf(os.Stderr, "warning: %v\n", tooOldErr)
+
+		case policy == DatabaseAgePolicyFail:
+			vulnProvider.Close()
+			return nil, tooOldErr
+
+		case updateDB:
+			// The regular update already tried to refresh the database.
+			vulnProvider.Close()
+			if updateErr != nil {
+				return nil, fmt.Errorf("%w, and it couldn't be refreshed: %w", tooOldErr, updateErr)
+			}
+			return nil, fmt.Errorf("%w, and no newer database is available from %s", tooOldErr, distCfg.LatestURL)
+
+		default:
+			// The database was imported, so it hasn't been refreshed yet.
+			fmt.Fprintf(os.Stderr, "warning: %v, refreshing it from %s...\n", tooOldErr, distCfg.LatestURL)
+			vulnProvider.Close()
+
+			if err := updateDatabase(updateCfg, distClient); err != nil {
+				return nil, fmt.Errorf("%w, and it couldn't be refreshed: %w", tooOldErr, err)
+			}
+
+			vulnProvider, dbStatus, err = grype.LoadVulnerabilityDB(distCfg, installCfg, false)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load vulnerability database: %w", err)
+			}
+
+			// The imported archive no longer describes the database.
+			checksum = ""
+		}
+	}
+
 	if checksum == "" {
 		metadata, err := v6.ReadImportMetadata(afero.NewOsFs(), filepath.Dir(dbStatus.Path))
 		if err != nil {
@@ -240,6 +409,7 @@ func NewScanner(opts Options) (*Scanner, error) {
 	}
 
 	vulnerabilityMatcher := NewGrypeVulnerabilityMatcher(vulnProvider, opts.UseCPEs)
+	vulnerabilityMatcher.Matchers = matchers.filter(vulnerabilityMatcher.Matchers)
 
 	return &Scanner{
 		vulnProvider:         vulnProvider,
@@ -247,9 +417,29 @@ func NewScanner(opts Options) (*Scanner, error) {
 		dbChecksum:           checksum,
 		vulnerabilityMatcher: vulnerabilityMatcher,
 		disableSBOMCache:     opts.DisableSBOMCache,
+		useCPEs:              opts.UseCPEs,
+		matchers:             matchers,
+		cpeOverrides:         opts.CPEOverrides,
+		sources:              opts.Sources,
 	}, nil
 }
 
+// newInstallationConfig returns the Grype DB installation configuration used
+// for the database rooted at dbDir (or DefaultGrypeDBDir, if dbDir is empty).
+func newInstallationConfig(dbDir string) installation.Config {
+	if dbDir == "" {
+		dbDir = DefaultGrypeDBDir
+	}
+
+	return installation.Config{
+		DBRootDir:               dbDir,
+		ValidateChecksum:        true,
+		ValidateAge:             true,
+		MaxAllowedBuiltAge:      48 * time.Hour,
+		UpdateCheckMaxFrequency: 1 * time.Hour,
+	}
+}
+
 // ScanAPK scans an APK file for vulnerabilities.
 func (s *Scanner) ScanAPK(ctx context.Context, apk fs.File, distroID string) (*Result, error) {
 	logger := clog.FromContext(ctx)
@@ -287,13 +477,48 @@ func (s *Scanner) APKSBOM(ctx context.Context, ssbom *sbomSyft.SBOM) (*Result, e
 		return nil, err
 	}
 
-	grype.SetLogger(anchorelogger.NewSlogAdapter(logger.Base()))
+	findings, err := s.findingsForSBOM(ctx, ssbom)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		SchemaVersion: ResultSchemaVersion,
+		TargetAPK:     apk,
+		Findings:      findings,
+		DataSource:    s.DataSource(),
+	}
+
+	return result, nil
+}
+
+// findingsForSBOM matches the packages in the given SBOM against the
+// vulnerability database.
+func (s *Scanner) findingsForSBOM(ctx context.Context, ssbom *sbomSyft.SBOM) ([]Finding, error) {
+	logger := clog.FromContext(ctx)
+
+	// Grype's logger is global, so avoid racing with concurrent scans.
+	s.setGrypeLoggerOnce.Do(func() {
+		grype.SetLogger(anchorelogger.NewSlogAdapter(logger.Base()))
+	})
 
 	syftPkgs := ssbom.Artifacts.Packages.Sorted()
 	grypePkgs := grypePkg.FromPackages(syftPkgs, grypePkg.SynthesisConfig{GenerateMissingCPEs: false})
 
 	logger.Info("converted packages to grype packages", "packageCount", len(grypePkgs))
 
+	s.cpeOverrides.apply(grypePkgs)
+
+	if s.matchers != nil {
+		countBefore := len(grypePkgs)
+		grypePkgs = slices.DeleteFunc(grypePkgs, func(p grypePkg.Package) bool {
+			return !s.matchers.allowsPackageType(p.Type)
+		})
+		if skipped := countBefore - len(grypePkgs); skipped > 0 {
+			logger.Debug("skipping packages not handled by enabled matchers", "skippedCount", skipped, "matchers", s.matchers.String())
+		}
+	}
+
 	// Find vulnerability matches
 	matchesCollection, _, err := s.vulnerabilityMatcher.FindMatches(grypePkgs, grypePkg.Context{
 		Source: &ssbom.Source,
@@ -335,21 +560,25 @@ func (s *Scanner) APKSBOM(ctx context.Context, ssbom *sbomSyft.SBOM) (*Result, e
 		if finding == nil {
 			return nil, fmt.Errorf("failed to map match to finding: nil")
 		}
+		finding.TriageSuggestion = suggestTriage(&m, finding)
 		findings = append(findings, *finding)
 	}
 
-	result := &Result{
-		TargetAPK: apk,
-		Findings:  findings,
-		DataSource: DataSource{
-			Kind:      "grype-db",
-			Schema:    s.dbStatus.SchemaVersion,
-			Integrity: s.dbChecksum,
-			Date:      s.dbStatus.Built,
-		},
+	if len(s.sources) > 0 {
+		return s.findingsFromSources(ctx, grypePkgs, findings)
 	}
 
-	return result, nil
+	return findings, nil
+}
+
+// DataSource describes the vulnerability data used by the Scanner.
+func (s *Scanner) DataSource() DataSource {
+	return DataSource{
+		Kind:      "grype-db",
+		Schema:    s.dbStatus.SchemaVersion,
+		Integrity: s.dbChecksum,
+		Date:      s.dbStatus.Built,
+	}
 }
 
 // shouldAllowMatch is a point where we can optionally filter out matches from
@@ -450,6 +679,7 @@ var trustedCPESources = []cpe.Source{
 	sbom.CPESourceWolfictl,
 	sbom.CPESourceMelangeConfiguration,
 	cpe.NVDDictionaryLookupSource,
+	CPESourceOverrides,
 }
 
 var regexGolangDateVersion = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
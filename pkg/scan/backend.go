@@ -0,0 +1,293 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/tar"
+)
+
+// Backend is a vulnerability scanning engine other than the Grype-based Scanner.
+// Backends are used to cross-validate the Scanner's results (see CrossValidate),
+// e.g. to detect matching regressions after vulnerability database updates.
+type Backend interface {
+	// Name identifies the backend, e.g. "trivy".
+	Name() string
+
+	// ScanAPK scans the APK file at the given path and returns the findings.
+	ScanAPK(ctx context.Context, apkPath string) ([]Finding, error)
+}
+
+const (
+	BackendTrivy      = "trivy"
+	BackendOSVScanner = "osv-scanner"
+)
+
+// ValidBackends are the names of the supported secondary scanner backends.
+var ValidBackends = []string{BackendTrivy, BackendOSVScanner}
+
+// NewBackend returns the backend with the given name (see ValidBackends). The
+// backend's executable is expected to be on the PATH.
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case BackendTrivy:
+		return &TrivyBackend{}, nil
+	case BackendOSVScanner:
+		return &OSVScannerBackend{}, nil
+	default:
+		return nil, fmt.Errorf("invalid backend %q, must be one of [%s]", name, strings.Join(ValidBackends, ", "))
+	}
+}
+
+// TrivyBackend scans APKs using Trivy (https://trivy.dev). Since Trivy can't scan
+// an APK file directly, the APK's contents are extracted and scanned as a root
+// filesystem.
+type TrivyBackend struct {
+	// Path is the path to the trivy executable. If empty, "trivy" is looked up
+	// on the PATH.
+	Path string
+}
+
+func (b *TrivyBackend) Name() string {
+	return BackendTrivy
+}
+
+func (b *TrivyBackend) ScanAPK(ctx context.Context, apkPath string) ([]Finding, error) {
+	out, err := runBackendOnAPK(ctx, b.Path, BackendTrivy, apkPath, func(dir string) []string {
+		return []string{"rootfs", "--format", "json", "--scanners", "vuln", "--quiet", dir}
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTrivyReport(bytes.NewReader(out))
+}
+
+// OSVScannerBackend scans APKs using OSV-Scanner (https://google.github.io/osv-scanner/).
+// The APK's contents are extracted and scanned recursively.
+type OSVScannerBackend struct {
+	// Path is the path to the osv-scanner executable. If empty, "osv-scanner" is
+	// looked up on the PATH.
+	Path string
+}
+
+func (b *OSVScannerBackend) Name() string {
+	return BackendOSVScanner
+}
+
+func (b *OSVScannerBackend) ScanAPK(ctx context.Context, apkPath string) ([]Finding, error) {
+	// osv-scanner exits with 1 when it finds vulnerabilities.
+	out, err := runBackendOnAPK(ctx, b.Path, BackendOSVScanner, apkPath, func(dir string) []string {
+		return []string{"--format", "json", "--recursive", dir}
+	}, []int{1})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseOSVScannerReport(bytes.NewReader(out))
+}
+
+// runBackendOnAPK extracts the APK into a temporary directory, and runs the
+// backend's executable with the arguments returned by args, returning the
+// executable's stdout.
+func runBackendOnAPK(ctx context.Context, path, defaultPath, apkPath string, args func(dir string) []string, okExitCodes []int) ([]byte, error) {
+	if path == "" {
+		path = defaultPath
+	}
+
+	dir, err := os.MkdirTemp("", "wolfictl-scan-backend-")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := os.Open(apkPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening APK: %w", err)
+	}
+	defer f.Close()
+
+	if err := tar.Untar(f, dir); err != nil {
+		return nil, fmt.Errorf("extracting APK: %w", err)
+	}
+
+	clog.FromContext(ctx).Debug("running secondary scanner", "backend", defaultPath, "apk", apkPath)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args(dir)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || !slices.Contains(okExitCodes, exitErr.ExitCode()) {
+			return nil, fmt.Errorf("running %s: %w: %s", defaultPath, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// trivyReport is the subset of Trivy's JSON report format that we use.
+type trivyReport struct {
+	Results []struct {
+		Target          string `json:"Target"`
+		Type            string `json:"Type"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string   `json:"VulnerabilityID"`
+			VendorIDs        []string `json:"VendorIDs"`
+			PkgName          string   `json:"PkgName"`
+			PkgPath          string   `json:"PkgPath"`
+			InstalledVersion string   `json:"InstalledVersion"`
+			FixedVersion     string   `json:"FixedVersion"`
+			Severity         string   `json:"Severity"`
+			PkgIdentifier    struct {
+				PURL string `json:"PURL"`
+			} `json:"PkgIdentifier"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+func parseTrivyReport(r io.Reader) ([]Finding, error) {
+	var report trivyReport
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("decoding trivy report: %w", err)
+	}
+
+	var findings []Finding
+	for _, result := range report.Results {
+		for i := range result.Vulnerabilities {
+			v := &result.Vulnerabilities[i]
+
+			location := v.PkgPath
+			if location == "" {
+				location = result.Target
+			}
+
+			findings = append(findings, Finding{
+				Package: Package{
+					Name:     v.PkgName,
+					Version:  v.InstalledVersion,
+					Type:     result.Type,
+					Location: location,
+					PURL:     v.PkgIdentifier.PURL,
+				},
+				Vulnerability: Vulnerability{
+					ID:           v.VulnerabilityID,
+					Severity:     normalizeSeverity(v.Severity),
+					Aliases:      v.VendorIDs,
+					FixedVersion: v.FixedVersion,
+				},
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// osvScannerReport is the subset of OSV-Scanner's JSON report format that we
+// use.
+type osvScannerReport struct {
+	Results []struct {
+		Source struct {
+			Path string `json:"path"`
+		} `json:"source"`
+		Packages []struct {
+			Package struct {
+				Name      string `json:"name"`
+				Version   string `json:"version"`
+				Ecosystem string `json:"ecosystem"`
+			} `json:"package"`
+			Vulnerabilities []struct {
+				ID      string   `json:"id"`
+				Aliases []string `json:"aliases"`
+			} `json:"vulnerabilities"`
+			Groups []struct {
+				IDs         []string `json:"ids"`
+				MaxSeverity string   `json:"max_severity"`
+			} `json:"groups"`
+		} `json:"packages"`
+	} `json:"results"`
+}
+
+func parseOSVScannerReport(r io.Reader) ([]Finding, error) {
+	var report osvScannerReport
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("decoding osv-scanner report: %w", err)
+	}
+
+	var findings []Finding
+	for _, result := range report.Results {
+		for i := range result.Packages {
+			p := &result.Packages[i]
+
+			severityByID := make(map[string]string)
+			for _, g := range p.Groups {
+				for _, id := range g.IDs {
+					severityByID[id] = severityFromCVSSScore(g.MaxSeverity)
+				}
+			}
+
+			for _, v := range p.Vulnerabilities {
+				findings = append(findings, Finding{
+					Package: Package{
+						Name:     p.Package.Name,
+						Version:  p.Package.Version,
+						Type:     p.Package.Ecosystem,
+						Location: result.Source.Path,
+					},
+					Vulnerability: Vulnerability{
+						ID:       v.ID,
+						Severity: severityByID[v.ID],
+						Aliases:  v.Aliases,
+					},
+				})
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// normalizeSeverity converts a severity like "HIGH" to the form used in
+// findings, e.g. "High".
+func normalizeSeverity(severity string) string {
+	if severity == "" || strings.EqualFold(severity, "unknown") {
+		return ""
+	}
+
+	s := strings.ToLower(severity)
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// severityFromCVSSScore converts a CVSS score to a severity, using the CVSS v3
+// qualitative rating scale.
+func severityFromCVSSScore(score string) string {
+	f, err := strconv.ParseFloat(score, 64)
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case f >= 9.0:
+		return "Critical"
+	case f >= 7.0:
+		return "High"
+	case f >= 4.0:
+		return "Medium"
+	case f > 0:
+		return "Low"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,126 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrivyReport(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "backends", "trivy.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	findings, err := parseTrivyReport(f)
+	require.NoError(t, err)
+
+	expected := []Finding{
+		{
+			Package: Package{
+				Name:     "golang.org/x/net",
+				Version:  "v0.15.0",
+				Type:     "gobinary",
+				Location: "usr/bin/crane",
+				PURL:     "pkg:golang/golang.org/x/net@v0.15.0",
+			},
+			Vulnerability: Vulnerability{
+				ID:           "CVE-2023-44487",
+				Severity:     "High",
+				Aliases:      []string{"GHSA-qppj-fm5r-hxr3"},
+				FixedVersion: "0.17.0",
+			},
+		},
+		{
+			Package: Package{
+				Name:     "stdlib",
+				Version:  "v1.21.0",
+				Type:     "gobinary",
+				Location: "usr/bin/crane",
+			},
+			Vulnerability: Vulnerability{
+				ID:           "CVE-2024-24790",
+				Severity:     "Critical",
+				FixedVersion: "1.21.11, 1.22.4",
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, findings); diff != "" {
+		t.Errorf("unexpected findings (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseOSVScannerReport(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "backends", "osv-scanner.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	findings, err := parseOSVScannerReport(f)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+
+	assert.Equal(t, "golang.org/x/net", findings[0].Package.Name)
+	assert.Equal(t, "Go", findings[0].Package.Type)
+	assert.Equal(t, "GHSA-qppj-fm5r-hxr3", findings[0].Vulnerability.ID)
+	assert.Equal(t, "High", findings[0].Vulnerability.Severity)
+	assert.Equal(t, []string{"CVE-2023-44487", "GO-2023-2102"}, findings[0].Vulnerability.Aliases)
+
+	assert.Equal(t, "GO-2023-2153", findings[1].Vulnerability.ID)
+	assert.Equal(t, "", findings[1].Vulnerability.Severity)
+}
+
+func TestNewBackend(t *testing.T) {
+	b, err := NewBackend("trivy")
+	require.NoError(t, err)
+	assert.Equal(t, "trivy", b.Name())
+
+	_, err = NewBackend("snyk")
+	assert.ErrorContains(t, err, `invalid backend "snyk"`)
+}
+
+func TestCrossValidate(t *testing.T) {
+	finding := func(pkgName, vulnID string, aliases ...string) Finding {
+		return Finding{
+			Package:       Package{Name: pkgName},
+			Vulnerability: Vulnerability{ID: vulnID, Aliases: aliases},
+		}
+	}
+
+	primary := &Result{
+		TargetAPK: TargetAPK{Name: "crane"},
+		Findings: []Finding{
+			finding("golang.org/x/net", "GHSA-qppj-fm5r-hxr3", "CVE-2023-44487"),
+			finding("golang.org/x/net", "GHSA-4374-p667-p6c8", "CVE-2023-39325"),
+			finding("stdlib", "CVE-2024-24790"),
+		},
+	}
+
+	secondary := []Finding{
+		finding("golang.org/x/net", "CVE-2023-44487", "GHSA-qppj-fm5r-hxr3"),
+		finding("stdlib", "CVE-2024-24790"),
+		finding("stdlib", "CVE-2024-24791"),
+		finding("golang.org/x/crypto", "CVE-2023-39325"), // different package
+	}
+
+	report := CrossValidate(primary, "grype", secondary, "trivy")
+
+	assert.Equal(t, "crane", report.TargetAPK.Name)
+	assert.Equal(t, "grype", report.Primary)
+	assert.Equal(t, "trivy", report.Secondary)
+
+	vulnIDs := func(findings []Finding) []string {
+		var ids []string
+		for i := range findings {
+			ids = append(ids, findings[i].Package.Name+" "+findings[i].Vulnerability.ID)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"golang.org/x/net GHSA-qppj-fm5r-hxr3", "stdlib CVE-2024-24790"}, vulnIDs(report.Agreed))
+	assert.ElementsMatch(t, []string{"golang.org/x/net GHSA-4374-p667-p6c8"}, vulnIDs(report.OnlyPrimary))
+	assert.ElementsMatch(t, []string{"stdlib CVE-2024-24791", "golang.org/x/crypto CVE-2023-39325"}, vulnIDs(report.OnlySecondary))
+}
@@ -0,0 +1,315 @@
+package scan
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/anchore/grype/grype/db/v6/distribution"
+	"github.com/anchore/grype/grype/db/v6/installation"
+	"github.com/chainguard-dev/clog"
+	wtar "github.com/wolfi-dev/wolfictl/pkg/tar"
+)
+
+// A bundle is a gzipped tarball that contains everything the scanner needs to
+// run without network access: the Grype vulnerability database, plus any feeds
+// used to enrich scan results (such as the KEV catalog). The bundle's contents
+// are described by its manifest.
+const (
+	bundleFormatVersion = 1
+
+	bundleManifestPath = "manifest.json"
+	bundleGrypeDBPath  = "grype/vulnerability.db"
+	bundleKEVPath      = "feeds/known_exploited_vulnerabilities.json"
+
+	// BundleFeedKEV is the name used for the KEV catalog in a bundle's manifest.
+	BundleFeedKEV = "kev"
+)
+
+// BundleManifest describes the contents of a scan database bundle.
+type BundleManifest struct {
+	FormatVersion int           `json:"formatVersion"`
+	Created       time.Time     `json:"created"`
+	GrypeDB       BundleGrypeDB `json:"grypeDB"`
+	Feeds         []BundleFeed  `json:"feeds,omitempty"`
+}
+
+// BundleGrypeDB describes the Grype vulnerability database in a bundle.
+type BundleGrypeDB struct {
+	SchemaVersion string    `json:"schemaVersion"`
+	Built         time.Time `json:"built"`
+	Checksum      string    `json:"checksum"`
+	Path          string    `json:"path"`
+}
+
+// BundleFeed describes an enrichment feed included in a bundle.
+type BundleFeed struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path"`
+}
+
+// ExportBundleOptions configures the creation of a scan database bundle.
+type ExportBundleOptions struct {
+	// KEVCatalogPath, if set, is the path to a KEV catalog file to include in the
+	// bundle.
+	KEVCatalogPath string
+}
+
+// ExportBundle writes a bundle containing the Scanner's vulnerability database
+// (and any feeds specified in opts) to w, for use in an environment without
+// network access. See ImportBundle.
+func (s *Scanner) ExportBundle(ctx context.Context, w io.Writer, opts ExportBundleOptions) (*BundleManifest, error) {
+	logger := clog.FromContext(ctx)
+
+	if s.dbStatus == nil || s.dbStatus.Path == "" {
+		return nil, errors.New("scanner has no vulnerability database to export")
+	}
+
+	manifest := &BundleManifest{
+		FormatVersion: bundleFormatVersion,
+		Created:       time.Now().UTC(),
+		GrypeDB: BundleGrypeDB{
+			SchemaVersion: s.dbStatus.SchemaVersion,
+			Built:         s.dbStatus.Built,
+			Checksum:      s.dbChecksum,
+			Path:          bundleGrypeDBPath,
+		},
+	}
+
+	files := map[string]string{
+		bundleGrypeDBPath: s.dbStatus.Path,
+	}
+
+	if opts.KEVCatalogPath != "" {
+		catalog, err := ReadKEVCatalog(opts.KEVCatalogPath)
+		if err != nil {
+			return nil, err
+		}
+
+		manifest.Feeds = append(manifest.Feeds, BundleFeed{
+			Name:    BundleFeedKEV,
+			Version: catalog.CatalogVersion,
+			Path:    bundleKEVPath,
+		})
+		files[bundleKEVPath] = opts.KEVCatalogPath
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding bundle manifest: %w", err)
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    bundleManifestPath,
+		Mode:    0o644,
+		Size:    int64(len(manifestData)),
+		ModTime: manifest.Created,
+	}); err != nil {
+		return nil, fmt.Errorf("writing bundle manifest: %w", err)
+	}
+	if _, err := tw.Write(manifestData); err != nil {
+		return nil, fmt.Errorf("writing bundle manifest: %w", err)
+	}
+
+	// Write the files in a stable order, with the manifest first.
+	for _, name := range []string{bundleGrypeDBPath, bundleKEVPath} {
+		src, ok := files[name]
+		if !ok {
+			continue
+		}
+
+		logger.Debug("adding file to bundle", "name", name, "source", src)
+		if err := addFileToTar(tw, name, src); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("finalizing bundle: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("finalizing bundle: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func addFileToTar(tw *tar.Writer, name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("opening %s for bundle: %w", src, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("inspecting %s for bundle: %w", src, err)
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}); err != nil {
+		return fmt.Errorf("adding %s to bundle: %w", name, err)
+	}
+
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("adding %s to bundle: %w", name, err)
+	}
+
+	return nil
+}
+
+// ImportBundleOptions configures the installation of a scan database bundle.
+type ImportBundleOptions struct {
+	// DBDir is the directory in which to install the bundle's vulnerability
+	// database. If empty, DefaultGrypeDBDir is used.
+	DBDir string
+
+	// KEVCachePath is where to install the bundle's KEV catalog, if the bundle has
+	// one. If empty, DefaultKEVCachePath is used.
+	KEVCachePath string
+}
+
+// ImportBundle installs the vulnerability database and feeds from a bundle
+// created by ExportBundle. After a successful import, a Scanner created with
+// Options.Offline set uses the bundle's database, and the bundle's KEV catalog
+// is available to LoadKEVCatalog in offline mode.
+func ImportBundle(ctx context.Context, r io.Reader, opts ImportBundleOptions) (*BundleManifest, error) {
+	logger := clog.FromContext(ctx)
+
+	tmpdir, err := os.MkdirTemp("", "wolfictl-scan-bundle-")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp directory for bundle: %w", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	if err := wtar.Untar(r, tmpdir); err != nil {
+		return nil, fmt.Errorf("extracting bundle: %w", err)
+	}
+
+	manifest, err := readBundleManifest(filepath.Join(tmpdir, bundleManifestPath))
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.FormatVersion != bundleFormatVersion {
+		return nil, fmt.Errorf("unsupported bundle format version %d (expected %d)", manifest.FormatVersion, bundleFormatVersion)
+	}
+
+	// Install the Grype DB. Grype's curator verifies and activates the database,
+	// replacing any database already installed.
+	installCfg := newInstallationConfig(opts.DBDir)
+	installCfg.ValidateAge = false
+
+	distClient, err := distribution.NewClient(distribution.DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("creating distribution client: %w", err)
+	}
+
+	curator, err := installation.NewCurator(installCfg, distClient)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the grype db import config: %w", err)
+	}
+
+	dbPath, err := bundleMemberPath(tmpdir, manifest.GrypeDB.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("importing vulnerability database from bundle", "built", manifest.GrypeDB.Built, "dir", installCfg.DBRootDir)
+	if err := curator.Import(dbPath); err != nil {
+		return nil, fmt.Errorf("unable to import vulnerability database: %w", err)
+	}
+
+	for _, feed := range manifest.Feeds {
+		switch feed.Name {
+		case BundleFeedKEV:
+			kevCachePath := opts.KEVCachePath
+			if kevCachePath == "" {
+				kevCachePath = DefaultKEVCachePath
+			}
+
+			src, err := bundleMemberPath(tmpdir, feed.Path)
+			if err != nil {
+				return nil, err
+			}
+
+			logger.Info("importing KEV catalog from bundle", "version", feed.Version, "path", kevCachePath)
+			if err := installBundleFile(src, kevCachePath); err != nil {
+				return nil, fmt.Errorf("importing KEV catalog: %w", err)
+			}
+
+		default:
+			logger.Warn("ignoring unknown feed in bundle", "name", feed.Name)
+		}
+	}
+
+	return manifest, nil
+}
+
+func readBundleManifest(p string) (*BundleManifest, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("opening bundle manifest: %w", err)
+	}
+	defer f.Close()
+
+	manifest := new(BundleManifest)
+	if err := json.NewDecoder(f).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("decoding bundle manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// bundleMemberPath returns the path of the extracted bundle file referenced by
+// the manifest, making sure it exists within the extracted bundle.
+func bundleMemberPath(tmpdir, name string) (string, error) {
+	p := filepath.Join(tmpdir, filepath.FromSlash(path.Clean("/"+name)))
+
+	if _, err := os.Stat(p); err != nil {
+		return "", fmt.Errorf("bundle is missing %q: %w", name, err)
+	}
+
+	return p, nil
+}
+
+func installBundleFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	tmp := dst + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, dst); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+
+	// An imported feed is as fresh as the bundle's copy of it, so make sure a
+	// later online run will attempt to refresh it.
+	epoch := time.Unix(0, 0)
+	return os.Chtimes(dst, epoch, epoch)
+}
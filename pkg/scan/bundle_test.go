@@ -0,0 +1,62 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	wtar "github.com/wolfi-dev/wolfictl/pkg/tar"
+)
+
+func TestExportBundle(t *testing.T) {
+	dir := t.TempDir()
+
+	dbPath := filepath.Join(dir, "vulnerability.db")
+	require.NoError(t, os.WriteFile(dbPath, []byte("not really a database"), 0o600))
+
+	kevPath := filepath.Join(dir, "kev.json")
+	require.NoError(t, os.WriteFile(kevPath, []byte(testKEVCatalog), 0o600))
+
+	built := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	s := &Scanner{
+		dbStatus: &vulnerability.ProviderStatus{
+			SchemaVersion: "v6.0.2",
+			Built:         built,
+			Path:          dbPath,
+		},
+		dbChecksum: "import_metadata_digest=xxh64:0123456789abcdef",
+	}
+
+	buf := new(bytes.Buffer)
+	manifest, err := s.ExportBundle(context.Background(), buf, ExportBundleOptions{KEVCatalogPath: kevPath})
+	require.NoError(t, err)
+
+	assert.Equal(t, "v6.0.2", manifest.GrypeDB.SchemaVersion)
+	assert.Equal(t, built, manifest.GrypeDB.Built)
+	require.Len(t, manifest.Feeds, 1)
+	assert.Equal(t, BundleFeedKEV, manifest.Feeds[0].Name)
+	assert.Equal(t, "2024.05.01", manifest.Feeds[0].Version)
+
+	// Make sure the bundle can be read back.
+	extracted := t.TempDir()
+	require.NoError(t, wtar.Untar(buf, extracted))
+
+	readManifest, err := readBundleManifest(filepath.Join(extracted, bundleManifestPath))
+	require.NoError(t, err)
+	assert.Equal(t, manifest.GrypeDB.Checksum, readManifest.GrypeDB.Checksum)
+
+	for _, name := range []string{manifest.GrypeDB.Path, manifest.Feeds[0].Path} {
+		p, err := bundleMemberPath(extracted, name)
+		require.NoError(t, err)
+		assert.FileExists(t, p)
+	}
+
+	_, err = bundleMemberPath(extracted, "../../etc/passwd")
+	assert.Error(t, err)
+}
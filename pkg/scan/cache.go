@@ -0,0 +1,211 @@
+package scan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"runtime/debug"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/chainguard-dev/clog"
+)
+
+// DefaultResultCacheDir is the default directory used to cache scan results.
+var DefaultResultCacheDir = path.Join(xdg.CacheHome, "wolfictl", "scan", "results")
+
+// resultCacheFormat should be incremented whenever the shape of the cached data
+// (or the meaning of the cache key) changes in an incompatible way.
+const resultCacheFormat = "3"
+
+// resultCacheMaxAge is how long a namespace of the cache is kept after it was
+// last used. Namespaces for other scanner configurations can be in use by other
+// processes sharing the cache directory, so they're only removed once they've
+// gone unused for this long, e.g. because the vulnerability database was
+// updated since.
+const resultCacheMaxAge = 7 * 24 * time.Hour
+
+// ResultCache is a disk-backed cache of scan results. Entries are keyed by the
+// digest of the scanned input (e.g. the APK's sha256) and the distro used
+// during matching, and they are namespaced by everything else that can affect
+// a scan's results: the vulnerability database build, the scanner's matching
+// configuration, and the version of wolfictl.
+//
+// Cached results are stored as they were produced by the Scanner, before any
+// advisory-based filtering, since advisory data can change independently of
+// the vulnerability database.
+type ResultCache struct {
+	dir string
+}
+
+// NewResultCache returns a ResultCache rooted at dir for results produced by
+// the given Scanner. Cached entries for other vulnerability database builds or
+// scanner configurations are removed once they haven't been used for
+// resultCacheMaxAge.
+func NewResultCache(ctx context.Context, dir string, s *Scanner) (*ResultCache, error) {
+	logger := clog.FromContext(ctx)
+
+	if dir == "" {
+		dir = DefaultResultCacheDir
+	}
+
+	namespace := s.resultCacheNamespace()
+
+	namespaceDir := path.Join(dir, namespace)
+	if err := os.MkdirAll(namespaceDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating scan result cache directory: %w", err)
+	}
+
+	// The modification time of a namespace's directory records when it was last
+	// used.
+	now := time.Now()
+	if err := os.Chtimes(namespaceDir, now, now); err != nil {
+		return nil, fmt.Errorf("updating scan result cache directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading scan result cache directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == namespace {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// Removed concurrently by another process.
+				continue
+			}
+			return nil, fmt.Errorf("reading scan result cache directory: %w", err)
+		}
+		if now.Sub(info.ModTime()) < resultCacheMaxAge {
+			continue
+		}
+
+		stale := path.Join(dir, entry.Name())
+		logger.Debug("removing stale scan result cache entries", "path", stale)
+		if err := os.RemoveAll(stale); err != nil {
+			return nil, fmt.Errorf("removing stale scan result cache entries: %w", err)
+		}
+	}
+
+	return &ResultCache{
+		dir: namespaceDir,
+	}, nil
+}
+
+// Get returns the cached result for the input with the given digest, scanned
+// for the given distro. If there's no cached result, Get returns nil and no
+// error.
+func (c *ResultCache) Get(ctx context.Context, digest, distroID string) (*Result, error) {
+	logger := clog.FromContext(ctx)
+
+	p := c.entryPath(digest, distroID)
+
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Debug("scan result cache miss", "path", p)
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("opening cached scan result: %w", err)
+	}
+	defer f.Close()
+
+	result := new(Result)
+	if err := json.NewDecoder(f).Decode(result); err != nil {
+		return nil, fmt.Errorf("decoding cached scan result (%s): %w", p, err)
+	}
+
+	logger.Debug("scan result cache hit", "path", p)
+	return result, nil
+}
+
+// Put stores the given result in the cache, keyed by the digest of the scanned
+// input and the distro used during the scan.
+func (c *ResultCache) Put(ctx context.Context, digest, distroID string, result *Result) error {
+	p := c.entryPath(digest, distroID)
+
+	// Write to a temp file first, so that concurrent readers never see a partially
+	// written entry.
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating cached scan result file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := json.NewEncoder(tmp).Encode(result); err != nil {
+		tmp.Close()
+		return fmt.Errorf("encoding scan result to cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing cached scan result file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("storing cached scan result: %w", err)
+	}
+
+	clog.FromContext(ctx).Debug("stored scan result in cache", "path", p)
+	return nil
+}
+
+func (c *ResultCache) entryPath(digest, distroID string) string {
+	return path.Join(c.dir, fmt.Sprintf("%s-sha256-%s.json", distroID, digest))
+}
+
+// Digest returns the hex-encoded sha256 digest of the data read from r.
+func Digest(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("hashing input: %w", err)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// resultCacheNamespace returns an identifier for everything about the scanner
+// (other than the scan target itself) that can affect scan results.
+func (s *Scanner) resultCacheNamespace() string {
+	key := fmt.Sprintf(
+		"format=%s\ndb=%s\nuseCPEs=%t\nmatchers=%s\ncpeOverrides=%s\nsources=%s\ntool=%s\n",
+		resultCacheFormat,
+		s.dbChecksum,
+		s.useCPEs,
+		s.matchers,
+		s.cpeOverrides.digest(),
+		s.sourceNames(),
+		toolVersion(),
+	)
+	h := sha256.Sum256([]byte(key))
+
+	return fmt.Sprintf("%x", h)[:16]
+}
+
+// toolVersion returns a best-effort identifier for the running build of
+// wolfictl, so that cached results aren't reused across changes to wolfictl's
+// own matching logic.
+func toolVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	v := bi.Main.Version
+	for _, setting := range bi.Settings {
+		if setting.Key == "vcs.revision" {
+			v += "+" + setting.Value
+		}
+	}
+
+	return v
+}
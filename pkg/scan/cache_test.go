@@ -0,0 +1,114 @@
+package scan
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	result := &Result{
+		TargetAPK: TargetAPK{
+			Name:              "crane",
+			Version:           "0.19.1-r6",
+			OriginPackageName: "crane",
+			Arch:              "x86_64",
+		},
+		Findings: []Finding{
+			{
+				Package: Package{
+					Name:    "golang.org/x/net",
+					Version: "v0.17.0",
+					Type:    "go-module",
+				},
+				Vulnerability: Vulnerability{
+					ID:       "GHSA-4374-p667-p6c8",
+					Severity: "High",
+				},
+			},
+		},
+	}
+
+	digest, err := Digest(strings.NewReader("not really an APK"))
+	require.NoError(t, err)
+
+	t.Run("round trip", func(t *testing.T) {
+		c, err := NewResultCache(ctx, dir, &Scanner{dbChecksum: "sha256:aaaa"})
+		require.NoError(t, err)
+
+		got, err := c.Get(ctx, digest, "wolfi")
+		require.NoError(t, err)
+		assert.Nil(t, got, "expected a cache miss before Put")
+
+		require.NoError(t, c.Put(ctx, digest, "wolfi", result))
+
+		got, err = c.Get(ctx, digest, "wolfi")
+		require.NoError(t, err)
+		if diff := cmp.Diff(result, got); diff != "" {
+			t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+		}
+
+		got, err = c.Get(ctx, digest, "chainguard")
+		require.NoError(t, err)
+		assert.Nil(t, got, "expected a cache miss for a different distro")
+	})
+
+	t.Run("database update invalidates cache", func(t *testing.T) {
+		c, err := NewResultCache(ctx, dir, &Scanner{dbChecksum: "sha256:bbbb"})
+		require.NoError(t, err)
+
+		got, err := c.Get(ctx, digest, "wolfi")
+		require.NoError(t, err)
+		assert.Nil(t, got, "expected a cache miss after the database changed")
+	})
+
+	t.Run("other configurations' entries are kept", func(t *testing.T) {
+		other, err := NewResultCache(ctx, dir, &Scanner{dbChecksum: "sha256:aaaa", useCPEs: true})
+		require.NoError(t, err)
+		require.NoError(t, other.Put(ctx, digest, "wolfi", result))
+
+		c, err := NewResultCache(ctx, dir, &Scanner{dbChecksum: "sha256:aaaa"})
+		require.NoError(t, err)
+		got, err := c.Get(ctx, digest, "wolfi")
+		require.NoError(t, err)
+		assert.NotNil(t, got, "expected a cache hit")
+
+		got, err = other.Get(ctx, digest, "wolfi")
+		require.NoError(t, err)
+		assert.NotNil(t, got, "expected a cache hit for the other configuration")
+	})
+
+	t.Run("unused entries are removed", func(t *testing.T) {
+		c, err := NewResultCache(ctx, dir, &Scanner{dbChecksum: "sha256:aaaa"})
+		require.NoError(t, err)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+
+		old := time.Now().Add(-resultCacheMaxAge - time.Hour)
+		for _, entry := range entries {
+			if entry.Name() != path.Base(c.dir) {
+				require.NoError(t, os.Chtimes(path.Join(dir, entry.Name()), old, old))
+			}
+		}
+
+		c, err = NewResultCache(ctx, dir, &Scanner{dbChecksum: "sha256:aaaa"})
+		require.NoError(t, err)
+
+		entries, err = os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1, "expected stale cache entries to be removed")
+		assert.Equal(t, path.Base(c.dir), entries[0].Name())
+	})
+}
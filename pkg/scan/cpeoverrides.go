@@ -0,0 +1,199 @@
+package scan
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	grypePkg "github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/syft/syft/cpe"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+	"gopkg.in/yaml.v3"
+)
+
+// CPESourceOverrides is the source of CPEs that were set by a CPEOverrides
+// file. These CPEs are trusted during matching.
+const CPESourceOverrides cpe.Source = "wolfictl-cpe-overrides"
+
+// CPEOverrides is a set of corrections to the CPEs used to match specific
+// packages against vulnerabilities. This allows recurring CPE mismatches (e.g.
+// the wrong vendor or product) to be fixed in one place, instead of in each
+// package's melange configuration.
+//
+// An example file:
+//
+//	packages:
+//	  - package: github.com/foo/bar
+//	    type: go-module
+//	    cpes:
+//	      - cpe:2.3:a:foo:bar:*:*:*:*:*:go:*:*
+//	  - package: libbaz
+//	    suppress: true
+type CPEOverrides struct {
+	Packages []CPEOverride `yaml:"packages"`
+}
+
+// CPEOverride replaces or removes the CPEs of the packages it applies to.
+type CPEOverride struct {
+	// Package is the name of the package whose CPEs are overridden (e.g. "crane"
+	// or "github.com/foo/bar"). Required.
+	Package string `yaml:"package"`
+
+	// Type is the type of the package (e.g. "apk" or "go-module"). If empty,
+	// packages of any type match.
+	Type string `yaml:"type,omitempty"`
+
+	// CPEs replace the package's CPEs. If a CPE's version is unspecified ("*"),
+	// the package's version is used.
+	CPEs []string `yaml:"cpes,omitempty"`
+
+	// Suppress removes all the package's CPEs, so that it's never matched using
+	// CPEs. It can't be combined with CPEs.
+	Suppress bool `yaml:"suppress,omitempty"`
+}
+
+// LoadCPEOverrides reads and validates the CPE overrides file at the given
+// path.
+func LoadCPEOverrides(path string) (*CPEOverrides, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening CPE overrides file: %w", err)
+	}
+	defer f.Close()
+
+	overrides, err := DecodeCPEOverrides(f)
+	if err != nil {
+		return nil, fmt.Errorf("loading CPE overrides file %q: %w", path, err)
+	}
+
+	return overrides, nil
+}
+
+// DecodeCPEOverrides decodes and validates a CPE overrides file from the given
+// reader.
+func DecodeCPEOverrides(r io.Reader) (*CPEOverrides, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	overrides := &CPEOverrides{}
+	if err := dec.Decode(overrides); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decoding CPE overrides file: %w", err)
+	}
+
+	if err := overrides.Validate(); err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
+
+// Validate returns an error if any of the overrides are invalid.
+func (o *CPEOverrides) Validate() error {
+	var errs []error
+	for i := range o.Packages {
+		if err := o.Packages[i].validate(); err != nil {
+			errs = append(errs, fmt.Errorf("override %d: %w", i+1, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (o *CPEOverride) validate() error {
+	var errs []error
+
+	if o.Package == "" {
+		errs = append(errs, errors.New("package must be specified"))
+	}
+
+	if o.Type != "" && !slices.Contains(syftPkg.AllPkgs, syftPkg.Type(o.Type)) {
+		errs = append(errs, fmt.Errorf("unknown package type %q", o.Type))
+	}
+
+	switch {
+	case o.Suppress && len(o.CPEs) > 0:
+		errs = append(errs, errors.New("cpes and suppress can't both be specified"))
+	case !o.Suppress && len(o.CPEs) == 0:
+		errs = append(errs, errors.New("either cpes or suppress must be specified"))
+	}
+
+	for _, c := range o.CPEs {
+		if _, err := cpe.NewAttributes(c); err != nil {
+			errs = append(errs, fmt.Errorf("invalid CPE %q: %w", c, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// appliesTo returns true if the override is for the given package.
+func (o *CPEOverride) appliesTo(p *grypePkg.Package) bool {
+	if o.Package != p.Name {
+		return false
+	}
+
+	return o.Type == "" || syftPkg.Type(o.Type) == p.Type
+}
+
+// apply replaces the CPEs of each package that has an override. When more than
+// one override applies to a package, the first one wins.
+func (o *CPEOverrides) apply(pkgs []grypePkg.Package) {
+	if o == nil {
+		return
+	}
+
+	for i := range pkgs {
+		p := &pkgs[i]
+
+		for j := range o.Packages {
+			override := &o.Packages[j]
+			if !override.appliesTo(p) {
+				continue
+			}
+
+			p.CPEs = override.cpesFor(p)
+			break
+		}
+	}
+}
+
+func (o *CPEOverride) cpesFor(p *grypePkg.Package) []cpe.CPE {
+	if o.Suppress {
+		return nil
+	}
+
+	cpes := make([]cpe.CPE, 0, len(o.CPEs))
+	for _, c := range o.CPEs {
+		// Already validated.
+		attr, err := cpe.NewAttributes(c)
+		if err != nil {
+			continue
+		}
+
+		if attr.Version == cpe.Any {
+			attr.Version = p.Version
+		}
+
+		cpes = append(cpes, cpe.CPE{Attributes: attr, Source: CPESourceOverrides})
+	}
+
+	return cpes
+}
+
+// digest returns an identifier for the overrides, for use in cache keys.
+func (o *CPEOverrides) digest() string {
+	if o == nil || len(o.Packages) == 0 {
+		return ""
+	}
+
+	h := sha256.New()
+	for i := range o.Packages {
+		override := &o.Packages[i]
+		fmt.Fprintf(h, "%q %q %t %q\n", override.Package, override.Type, override.Suppress, override.CPEs)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,105 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+
+	grypePkg "github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/syft/syft/cpe"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCPEOverrides(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		o, err := DecodeCPEOverrides(strings.NewReader(`
+packages:
+  - package: github.com/foo/bar
+    type: go-module
+    cpes:
+      - cpe:2.3:a:foo:bar:*:*:*:*:*:go:*:*
+  - package: libbaz
+    suppress: true
+`))
+		require.NoError(t, err)
+		require.Len(t, o.Packages, 2)
+		assert.Equal(t, "go-module", o.Packages[0].Type)
+		assert.True(t, o.Packages[1].Suppress)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		o, err := DecodeCPEOverrides(strings.NewReader(""))
+		require.NoError(t, err)
+		assert.Empty(t, o.Packages)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := DecodeCPEOverrides(strings.NewReader(`
+packages:
+  - type: not-a-type
+    suppress: true
+    cpes:
+      - not-a-cpe
+  - package: foo
+`))
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "package must be specified")
+		assert.ErrorContains(t, err, `unknown package type "not-a-type"`)
+		assert.ErrorContains(t, err, "cpes and suppress can't both be specified")
+		assert.ErrorContains(t, err, `invalid CPE "not-a-cpe"`)
+		assert.ErrorContains(t, err, "either cpes or suppress must be specified")
+	})
+}
+
+func TestCPEOverrides_apply(t *testing.T) {
+	original := cpe.Must("cpe:2.3:a:wrong:bar:1.2.3:*:*:*:*:*:*:*", cpe.GeneratedSource)
+
+	pkgs := []grypePkg.Package{
+		{Name: "github.com/foo/bar", Version: "1.2.3", Type: syftPkg.GoModulePkg, CPEs: []cpe.CPE{original}},
+		{Name: "github.com/foo/bar", Version: "1.2.3", Type: syftPkg.BinaryPkg, CPEs: []cpe.CPE{original}},
+		{Name: "libbaz", Version: "4.5.6", Type: syftPkg.ApkPkg, CPEs: []cpe.CPE{original}},
+		{Name: "unrelated", Version: "7.8.9", Type: syftPkg.ApkPkg, CPEs: []cpe.CPE{original}},
+	}
+
+	o := &CPEOverrides{
+		Packages: []CPEOverride{
+			{
+				Package: "github.com/foo/bar",
+				Type:    "go-module",
+				CPEs: []string{
+					"cpe:2.3:a:foo:bar:*:*:*:*:*:go:*:*",
+					"cpe:2.3:a:foo:bar:9.9.9:*:*:*:*:*:*:*",
+				},
+			},
+			{Package: "libbaz", Suppress: true},
+			{Package: "libbaz", CPEs: []string{"cpe:2.3:a:baz:libbaz:*:*:*:*:*:*:*:*"}},
+		},
+	}
+	require.NoError(t, o.Validate())
+
+	o.apply(pkgs)
+
+	require.Len(t, pkgs[0].CPEs, 2)
+	assert.Equal(t, "cpe:2.3:a:foo:bar:1.2.3:*:*:*:*:go:*:*", pkgs[0].CPEs[0].Attributes.BindToFmtString())
+	assert.Equal(t, "cpe:2.3:a:foo:bar:9.9.9:*:*:*:*:*:*:*", pkgs[0].CPEs[1].Attributes.BindToFmtString())
+	assert.Equal(t, CPESourceOverrides, pkgs[0].CPEs[0].Source)
+
+	// The type doesn't match.
+	assert.Equal(t, []cpe.CPE{original}, pkgs[1].CPEs)
+
+	// The first matching override wins.
+	assert.Empty(t, pkgs[2].CPEs)
+
+	assert.Equal(t, []cpe.CPE{original}, pkgs[3].CPEs)
+}
+
+func TestCPEOverrides_digest(t *testing.T) {
+	var none *CPEOverrides
+	assert.Empty(t, none.digest())
+
+	a := &CPEOverrides{Packages: []CPEOverride{{Package: "foo", Suppress: true}}}
+	b := &CPEOverrides{Packages: []CPEOverride{{Package: "bar", Suppress: true}}}
+	assert.NotEmpty(t, a.digest())
+	assert.NotEqual(t, a.digest(), b.digest())
+}
@@ -0,0 +1,94 @@
+package scan
+
+import (
+	"slices"
+	"sort"
+)
+
+// CrossValidationReport compares the findings of two scanner backends for the
+// same APK.
+type CrossValidationReport struct {
+	TargetAPK TargetAPK
+
+	// Primary and Secondary are the names of the compared backends.
+	Primary   string
+	Secondary string
+
+	// Agreed are the findings reported by both backends. The findings are taken
+	// from the primary backend.
+	Agreed []Finding
+
+	// OnlyPrimary are the findings reported only by the primary backend.
+	OnlyPrimary []Finding
+
+	// OnlySecondary are the findings reported only by the secondary backend.
+	OnlySecondary []Finding
+}
+
+// CrossValidate compares the findings from the primary scan result with those
+// from a secondary backend.
+//
+// Since different backends identify vulnerabilities differently (e.g. by GHSA
+// ID or by CVE ID), two findings are considered the same if they affect a
+// package with the same name and share any vulnerability ID or alias. Package
+// types, versions, and locations are ignored, since backends describe these
+// inconsistently.
+func CrossValidate(primary *Result, primaryName string, secondary []Finding, secondaryName string) CrossValidationReport {
+	report := CrossValidationReport{
+		TargetAPK: primary.TargetAPK,
+		Primary:   primaryName,
+		Secondary: secondaryName,
+	}
+
+	matchedSecondary := make([]bool, len(secondary))
+
+	for i := range primary.Findings {
+		f := &primary.Findings[i]
+
+		// A single finding from one backend can correspond to several from the
+		// other (e.g. one per alias), so look for all of them.
+		matched := false
+		for j := range secondary {
+			if sameFinding(f, &secondary[j]) {
+				matched = true
+				matchedSecondary[j] = true
+			}
+		}
+
+		if matched {
+			report.Agreed = append(report.Agreed, *f)
+		} else {
+			report.OnlyPrimary = append(report.OnlyPrimary, *f)
+		}
+	}
+
+	for i := range secondary {
+		if !matchedSecondary[i] {
+			report.OnlySecondary = append(report.OnlySecondary, secondary[i])
+		}
+	}
+
+	sort.Stable(Findings(report.Agreed))
+	sort.Stable(Findings(report.OnlyPrimary))
+	sort.Stable(Findings(report.OnlySecondary))
+
+	return report
+}
+
+func sameFinding(a, b *Finding) bool {
+	if a.Package.Name != b.Package.Name {
+		return false
+	}
+
+	for _, id := range vulnerabilityIDs(a) {
+		if slices.Contains(vulnerabilityIDs(b), id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func vulnerabilityIDs(f *Finding) []string {
+	return append([]string{f.Vulnerability.ID}, f.Vulnerability.Aliases...)
+}
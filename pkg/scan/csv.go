@@ -0,0 +1,69 @@
+package scan
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvHeader names the columns of the tabular encoding of scan findings.
+var csvHeader = []string{
+	"APK",
+	"APK Version",
+	"Arch",
+	"Component",
+	"Component Version",
+	"Component Type",
+	"Vulnerability",
+	"Aliases",
+	"Severity",
+	"Fixed Version",
+	"Match Type",
+	"Advisory",
+	"Advisory Status",
+	"Sources",
+}
+
+// EncodeCSV writes the findings of the given scan results to w as a table, with
+// a header row followed by one row per finding. Fields are separated by the
+// given delimiter, e.g. ',' for CSV or '\t' for TSV.
+func EncodeCSV(w io.Writer, results []Result, delimiter rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for i := range results {
+		result := &results[i]
+
+		for j := range result.Findings {
+			f := &result.Findings[j]
+
+			record := []string{
+				result.TargetAPK.Name,
+				result.TargetAPK.Version,
+				result.TargetAPK.Arch,
+				f.Package.Name,
+				f.Package.Version,
+				f.Package.Type,
+				f.Vulnerability.ID,
+				strings.Join(f.Vulnerability.Aliases, " "),
+				f.Vulnerability.Severity,
+				f.Vulnerability.FixedVersion,
+				f.Vulnerability.MatchType,
+				f.CGAID,
+				f.AdvisoryStatus,
+				strings.Join(f.Sources, " "),
+			}
+			if err := cw.Write(record); err != nil {
+				return fmt.Errorf("writing finding %s in %s: %w", f.Vulnerability.ID, result.TargetAPK.Name, err)
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,60 @@
+package scan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCSV(t *testing.T) {
+	results := []Result{
+		{
+			TargetAPK: TargetAPK{Name: "crane", Version: "0.19.1-r6", Arch: "x86_64"},
+			Findings: []Finding{
+				{
+					Package: Package{Name: "github.com/foo/bar", Version: "v1.2.3", Type: "go-module"},
+					Vulnerability: Vulnerability{
+						ID:           "GHSA-aaaa-bbbb-cccc",
+						Severity:     "Medium",
+						Aliases:      []string{"CVE-2024-1234", "GO-2024-0001"},
+						FixedVersion: "1.2.4",
+						MatchType:    "exact-direct-match",
+					},
+					CGAID:          "CGA-xxxx-yyyy-zzzz",
+					AdvisoryStatus: "fixed",
+					Sources:        []string{"grype", "osv"},
+				},
+				{
+					Package:       Package{Name: "libfoo, the library", Version: "1.0", Type: "binary"},
+					Vulnerability: Vulnerability{ID: "CVE-2024-5678", Severity: "High", MatchType: "cpe-match"},
+				},
+			},
+		},
+		{
+			TargetAPK: TargetAPK{Name: "empty", Version: "1.0.0-r0"},
+		},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.NoError(t, EncodeCSV(buf, results, ','))
+
+		expected := `APK,APK Version,Arch,Component,Component Version,Component Type,Vulnerability,Aliases,Severity,Fixed Version,Match Type,Advisory,Advisory Status,Sources
+crane,0.19.1-r6,x86_64,github.com/foo/bar,v1.2.3,go-module,GHSA-aaaa-bbbb-cccc,CVE-2024-1234 GO-2024-0001,Medium,1.2.4,exact-direct-match,CGA-xxxx-yyyy-zzzz,fixed,grype osv
+crane,0.19.1-r6,x86_64,"libfoo, the library",1.0,binary,CVE-2024-5678,,High,,cpe-match,,,
+`
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("tsv", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.NoError(t, EncodeCSV(buf, results, '\t'))
+
+		expected := "APK\tAPK Version\tArch\tComponent\tComponent Version\tComponent Type\tVulnerability\tAliases\tSeverity\tFixed Version\tMatch Type\tAdvisory\tAdvisory Status\tSources\n" +
+			"crane\t0.19.1-r6\tx86_64\tgithub.com/foo/bar\tv1.2.3\tgo-module\tGHSA-aaaa-bbbb-cccc\tCVE-2024-1234 GO-2024-0001\tMedium\t1.2.4\texact-direct-match\tCGA-xxxx-yyyy-zzzz\tfixed\tgrype osv\n" +
+			"crane\t0.19.1-r6\tx86_64\tlibfoo, the library\t1.0\tbinary\tCVE-2024-5678\t\tHigh\t\tcpe-match\t\t\t\n"
+		assert.Equal(t, expected, buf.String())
+	})
+}
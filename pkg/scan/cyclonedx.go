@@ -0,0 +1,298 @@
+package scan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	cyclonedx "github.com/CycloneDX/cyclonedx-go"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/package-url/packageurl-go"
+)
+
+// CycloneDXVDR returns a CycloneDX 1.5 Vulnerability Disclosure Report (VDR)
+// describing the given scan results. Each scanned APK and each vulnerable
+// component within it is listed as a component, and each finding is mapped to a
+// CycloneDX vulnerability that "affects" the component it was found in.
+//
+// If a finding has an associated advisory, the advisory's latest event is used
+// to populate the vulnerability's impact analysis.
+func CycloneDXVDR(results []Result, distroID string) *cyclonedx.BOM {
+	bom := cyclonedx.NewBOM()
+	bom.SpecVersion = cyclonedx.SpecVersion1_5
+	bom.Metadata = &cyclonedx.Metadata{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Tools: &cyclonedx.ToolsChoice{
+			Components: &[]cyclonedx.Component{
+				{
+					Type: cyclonedx.ComponentTypeApplication,
+					Name: "wolfictl",
+				},
+			},
+		},
+	}
+
+	var (
+		components      []cyclonedx.Component
+		vulnerabilities []cyclonedx.Vulnerability
+		seenComponents  = make(map[string]struct{})
+	)
+
+	addComponent := func(c cyclonedx.Component) {
+		if _, ok := seenComponents[c.BOMRef]; ok {
+			return
+		}
+		seenComponents[c.BOMRef] = struct{}{}
+		components = append(components, c)
+	}
+
+	for i := range results {
+		result := results[i]
+		apkComponent := cycloneDXComponentForAPK(result.TargetAPK, distroID)
+		addComponent(apkComponent)
+
+		for j := range result.Findings {
+			f := result.Findings[j]
+
+			ref := apkComponent.BOMRef
+			if f.Package.Type != "apk" {
+				c := cycloneDXComponentForPackage(f.Package)
+				addComponent(c)
+				ref = c.BOMRef
+			}
+
+			vulnerabilities = append(vulnerabilities, cycloneDXVulnerability(f, ref, result.DataSource))
+		}
+	}
+
+	bom.Components = &components
+	bom.Vulnerabilities = &vulnerabilities
+
+	return bom
+}
+
+// EncodeCycloneDXVDR writes the CycloneDX VDR for the given scan results to w,
+// encoded as JSON.
+func EncodeCycloneDXVDR(w io.Writer, results []Result, distroID string) error {
+	bom := CycloneDXVDR(results, distroID)
+
+	enc := cyclonedx.NewBOMEncoder(w, cyclonedx.BOMFileFormatJSON)
+	enc.SetPretty(true)
+	if err := enc.EncodeVersion(bom, cyclonedx.SpecVersion1_5); err != nil {
+		return fmt.Errorf("encoding CycloneDX VDR: %w", err)
+	}
+
+	return nil
+}
+
+func cycloneDXComponentForAPK(apk TargetAPK, distroID string) cyclonedx.Component {
+	var qualifiers packageurl.Qualifiers
+	if apk.Arch != "" {
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: "arch", Value: apk.Arch})
+	}
+	if apk.OriginPackageName != "" && apk.OriginPackageName != apk.Name {
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: "origin", Value: apk.OriginPackageName})
+	}
+
+	purl := packageurl.NewPackageURL(packageurl.TypeApk, distroID, apk.Name, apk.Version, qualifiers, "").String()
+
+	return cyclonedx.Component{
+		BOMRef:     purl,
+		Type:       cyclonedx.ComponentTypeApplication,
+		Name:       apk.Name,
+		Version:    apk.Version,
+		PackageURL: purl,
+	}
+}
+
+func cycloneDXComponentForPackage(p Package) cyclonedx.Component {
+	ref := p.PURL
+	if ref == "" {
+		ref = p.ID
+	}
+
+	c := cyclonedx.Component{
+		BOMRef:     ref,
+		Type:       cyclonedx.ComponentTypeLibrary,
+		Name:       p.Name,
+		Version:    p.Version,
+		PackageURL: p.PURL,
+	}
+
+	if p.Location != "" {
+		c.Properties = &[]cyclonedx.Property{
+			{Name: "wolfictl:package:location", Value: p.Location},
+		}
+	}
+
+	return c
+}
+
+func cycloneDXVulnerability(f Finding, affectedRef string, ds DataSource) cyclonedx.Vulnerability {
+	v := cyclonedx.Vulnerability{
+		BOMRef: fmt.Sprintf("%s/%s", f.Vulnerability.ID, affectedRef),
+		ID:     f.Vulnerability.ID,
+		Source: cycloneDXSource(f.Vulnerability.ID),
+		Ratings: &[]cyclonedx.VulnerabilityRating{
+			{Severity: cycloneDXSeverity(f.Vulnerability.Severity)},
+		},
+		Affects: &[]cyclonedx.Affects{
+			{
+				Ref: affectedRef,
+				Range: &[]cyclonedx.AffectedVersions{
+					{
+						Version: f.Package.Version,
+						Status:  cyclonedx.VulnerabilityStatusAffected,
+					},
+				},
+			},
+		},
+	}
+
+	if len(f.Vulnerability.Aliases) > 0 {
+		refs := make([]cyclonedx.VulnerabilityReference, 0, len(f.Vulnerability.Aliases))
+		for _, alias := range f.Vulnerability.Aliases {
+			refs = append(refs, cyclonedx.VulnerabilityReference{
+				ID:     alias,
+				Source: cycloneDXSource(alias),
+			})
+		}
+		v.References = &refs
+	}
+
+	if fixed := f.Vulnerability.FixedVersion; fixed != "" {
+		v.Recommendation = fmt.Sprintf("Upgrade %s to %s", f.Package.Name, fixed)
+	}
+
+	if f.Advisory != nil { //nolint:staticcheck // TODO: use CGAID to lookup the advisory instead.
+		v.Analysis = cycloneDXAnalysis(*f.Advisory) //nolint:staticcheck
+	}
+
+	properties := []cyclonedx.Property{
+		{Name: "wolfictl:datasource:kind", Value: ds.Kind},
+		{Name: "wolfictl:datasource:integrity", Value: ds.Integrity},
+	}
+	if f.CGAID != "" {
+		properties = append(properties, cyclonedx.Property{Name: "wolfictl:advisory:id", Value: f.CGAID})
+	}
+	v.Properties = &properties
+
+	return v
+}
+
+// cycloneDXAnalysis maps the latest event of the given advisory to a CycloneDX
+// impact analysis.
+func cycloneDXAnalysis(adv v2.Advisory) *cyclonedx.VulnerabilityAnalysis {
+	if len(adv.Events) == 0 {
+		return nil
+	}
+
+	latest := adv.Latest()
+	a := &cyclonedx.VulnerabilityAnalysis{
+		LastUpdated: time.Time(latest.Timestamp).UTC().Format(time.RFC3339),
+	}
+
+	switch latest.Type {
+	case v2.EventTypeDetection:
+		a.State = cyclonedx.IASInTriage
+
+	case v2.EventTypeTruePositiveDetermination:
+		a.State = cyclonedx.IASExploitable
+		if d, ok := latest.Data.(v2.TruePositiveDetermination); ok {
+			a.Detail = d.Note
+		}
+
+	case v2.EventTypeFixed:
+		a.State = cyclonedx.IASResolved
+		a.Response = &[]cyclonedx.ImpactAnalysisResponse{cyclonedx.IARUpdate}
+		if d, ok := latest.Data.(v2.Fixed); ok {
+			a.Detail = fmt.Sprintf("fixed in version %s", d.FixedVersion)
+		}
+
+	case v2.EventTypeFalsePositiveDetermination:
+		a.State = cyclonedx.IASNotAffected
+		if d, ok := latest.Data.(v2.FalsePositiveDetermination); ok {
+			a.Justification = cycloneDXJustification(d.Type)
+			a.Detail = d.Note
+			if d.Type == v2.FPTypeVulnerabilityRecordAnalysisContested || d.Type == v2.FPTypeComponentVulnerabilityMismatch {
+				a.State = cyclonedx.IASFalsePositive
+			}
+		}
+
+	case v2.EventTypeFixNotPlanned:
+		a.State = cyclonedx.IASExploitable
+		a.Response = &[]cyclonedx.ImpactAnalysisResponse{cyclonedx.IARWillNotFix}
+		if d, ok := latest.Data.(v2.FixNotPlanned); ok {
+			a.Detail = d.Note
+		}
+
+	case v2.EventTypeAnalysisNotPlanned:
+		a.State = cyclonedx.IASInTriage
+		if d, ok := latest.Data.(v2.AnalysisNotPlanned); ok {
+			a.Detail = d.Note
+		}
+
+	case v2.EventTypePendingUpstreamFix:
+		a.State = cyclonedx.IASExploitable
+		a.Response = &[]cyclonedx.ImpactAnalysisResponse{cyclonedx.IARCanNotFix}
+		if d, ok := latest.Data.(v2.PendingUpstreamFix); ok {
+			a.Detail = d.Note
+		}
+
+	default:
+		return nil
+	}
+
+	return a
+}
+
+func cycloneDXJustification(fpType string) cyclonedx.ImpactAnalysisJustification {
+	switch fpType {
+	case v2.FPTypeVulnerableCodeNotIncludedInPackage, v2.FPTypeVulnerableCodeVersionNotUsed:
+		return cyclonedx.IAJCodeNotPresent
+
+	case v2.FPTypeVulnerableCodeNotInExecutionPath:
+		return cyclonedx.IAJCodeNotReachable
+
+	case v2.FPTypeVulnerableCodeCannotBeControlledByAdversary:
+		return cyclonedx.IAJRequiresEnvironment
+
+	case v2.FPTypeInlineMitigationsExist:
+		return cyclonedx.IAJProtectedByMitigatingControl
+	}
+
+	return ""
+}
+
+func cycloneDXSeverity(severity string) cyclonedx.Severity {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return cyclonedx.SeverityCritical
+	case "high":
+		return cyclonedx.SeverityHigh
+	case "medium":
+		return cyclonedx.SeverityMedium
+	case "low":
+		return cyclonedx.SeverityLow
+	case "negligible":
+		return cyclonedx.SeverityInfo
+	}
+
+	return cyclonedx.SeverityUnknown
+}
+
+func cycloneDXSource(vulnID string) *cyclonedx.Source {
+	switch {
+	case strings.HasPrefix(vulnID, "CVE-"):
+		return &cyclonedx.Source{Name: "NVD", URL: fmt.Sprintf("https://nvd.nist.gov/vuln/detail/%s", vulnID)}
+
+	case strings.HasPrefix(vulnID, "GHSA-"):
+		return &cyclonedx.Source{Name: "GitHub", URL: fmt.Sprintf("https://github.com/advisories/%s", vulnID)}
+
+	case strings.HasPrefix(vulnID, "GO-"):
+		return &cyclonedx.Source{Name: "Go Vulnerability Database", URL: fmt.Sprintf("https://pkg.go.dev/vuln/%s", vulnID)}
+	}
+
+	return nil
+}
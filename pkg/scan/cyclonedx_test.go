@@ -0,0 +1,156 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	cyclonedx "github.com/CycloneDX/cyclonedx-go"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycloneDXVDR(t *testing.T) {
+	results := []Result{
+		{
+			TargetAPK: TargetAPK{
+				Name:              "ko",
+				Version:           "0.15.1-r0",
+				OriginPackageName: "ko",
+				Arch:              "x86_64",
+			},
+			Findings: []Finding{
+				{
+					Package: Package{
+						ID:      "abc123",
+						Name:    "golang.org/x/net",
+						Version: "v0.17.0",
+						Type:    "go-module",
+						PURL:    "pkg:golang/golang.org/x/net@v0.17.0",
+					},
+					Vulnerability: Vulnerability{
+						ID:           "GHSA-4374-p667-p6c8",
+						Severity:     "High",
+						Aliases:      []string{"CVE-2023-39325"},
+						FixedVersion: "0.17.0",
+					},
+					Advisory: &v2.Advisory{
+						ID: "CGA-xxxx-xxxx-xxxx",
+						Events: []v2.Event{
+							{
+								Timestamp: v2.Timestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+								Type:      v2.EventTypeFalsePositiveDetermination,
+								Data: v2.FalsePositiveDetermination{
+									Type: v2.FPTypeVulnerableCodeNotInExecutionPath,
+									Note: "not reachable",
+								},
+							},
+						},
+					},
+				},
+				{
+					Package: Package{
+						ID:      "def456",
+						Name:    "ko",
+						Version: "0.15.1-r0",
+						Type:    "apk",
+					},
+					Vulnerability: Vulnerability{
+						ID:       "CVE-2024-0001",
+						Severity: "Negligible",
+					},
+				},
+			},
+			DataSource: DataSource{Kind: "grype-db"},
+		},
+	}
+
+	bom := CycloneDXVDR(results, "wolfi")
+
+	assert.Equal(t, cyclonedx.SpecVersion1_5, bom.SpecVersion)
+
+	require.NotNil(t, bom.Components)
+	components := *bom.Components
+	require.Len(t, components, 2)
+	apkRef := "pkg:apk/wolfi/ko@0.15.1-r0?arch=x86_64"
+	assert.Equal(t, apkRef, components[0].BOMRef)
+	assert.Equal(t, "pkg:golang/golang.org/x/net@v0.17.0", components[1].BOMRef)
+
+	require.NotNil(t, bom.Vulnerabilities)
+	vulns := *bom.Vulnerabilities
+	require.Len(t, vulns, 2)
+
+	goVuln := vulns[0]
+	assert.Equal(t, "GHSA-4374-p667-p6c8", goVuln.ID)
+	assert.Equal(t, "Upgrade golang.org/x/net to 0.17.0", goVuln.Recommendation)
+	assert.Equal(t, cyclonedx.SeverityHigh, (*goVuln.Ratings)[0].Severity)
+	assert.Equal(t, "pkg:golang/golang.org/x/net@v0.17.0", (*goVuln.Affects)[0].Ref)
+	assert.Equal(t, "CVE-2023-39325", (*goVuln.References)[0].ID)
+	require.NotNil(t, goVuln.Analysis)
+	assert.Equal(t, cyclonedx.IASNotAffected, goVuln.Analysis.State)
+	assert.Equal(t, cyclonedx.IAJCodeNotReachable, goVuln.Analysis.Justification)
+	assert.Equal(t, "not reachable", goVuln.Analysis.Detail)
+
+	apkVuln := vulns[1]
+	assert.Equal(t, apkRef, (*apkVuln.Affects)[0].Ref)
+	assert.Equal(t, cyclonedx.SeverityInfo, (*apkVuln.Ratings)[0].Severity)
+	assert.Nil(t, apkVuln.Analysis)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, EncodeCycloneDXVDR(buf, results, "wolfi"))
+	assert.True(t, json.Valid(buf.Bytes()))
+}
+
+func TestCycloneDXAnalysis(t *testing.T) {
+	ts := v2.Timestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	cases := []struct {
+		name          string
+		event         v2.Event
+		expectedState cyclonedx.ImpactAnalysisState
+	}{
+		{
+			name:          "detection",
+			event:         v2.Event{Timestamp: ts, Type: v2.EventTypeDetection},
+			expectedState: cyclonedx.IASInTriage,
+		},
+		{
+			name:          "true positive",
+			event:         v2.Event{Timestamp: ts, Type: v2.EventTypeTruePositiveDetermination},
+			expectedState: cyclonedx.IASExploitable,
+		},
+		{
+			name:          "fixed",
+			event:         v2.Event{Timestamp: ts, Type: v2.EventTypeFixed, Data: v2.Fixed{FixedVersion: "1.2.3-r1"}},
+			expectedState: cyclonedx.IASResolved,
+		},
+		{
+			name: "false positive, record contested",
+			event: v2.Event{Timestamp: ts, Type: v2.EventTypeFalsePositiveDetermination, Data: v2.FalsePositiveDetermination{
+				Type: v2.FPTypeVulnerabilityRecordAnalysisContested,
+			}},
+			expectedState: cyclonedx.IASFalsePositive,
+		},
+		{
+			name:          "fix not planned",
+			event:         v2.Event{Timestamp: ts, Type: v2.EventTypeFixNotPlanned},
+			expectedState: cyclonedx.IASExploitable,
+		},
+		{
+			name:          "pending upstream fix",
+			event:         v2.Event{Timestamp: ts, Type: v2.EventTypePendingUpstreamFix},
+			expectedState: cyclonedx.IASExploitable,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			a := cycloneDXAnalysis(v2.Advisory{ID: "CGA-xxxx-xxxx-xxxx", Events: []v2.Event{tt.event}})
+			require.NotNil(t, a)
+			assert.Equal(t, tt.expectedState, a.State)
+			assert.Equal(t, "2024-01-01T00:00:00Z", a.LastUpdated)
+		})
+	}
+}
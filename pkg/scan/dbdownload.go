@@ -0,0 +1,219 @@
+package scan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/anchore/grype/grype/db/v6/distribution"
+	"github.com/anchore/grype/grype/db/v6/installation"
+	"github.com/wagoodman/go-progress"
+)
+
+const (
+	// downloadsDirName is the directory (within the Grype DB root directory) where
+	// database archives are kept while they're being downloaded, so that an
+	// interrupted download can be resumed by a later run.
+	downloadsDirName = "downloads"
+
+	// maxDownloadAttempts is the number of times a database archive download is
+	// attempted (resuming from where the last attempt stopped) before giving up.
+	maxDownloadAttempts = 3
+)
+
+// resumableClient is a Grype DB distribution client that downloads database
+// archives using HTTP range requests, so that an interrupted download picks up
+// where it left off instead of starting over. Checking for updates and
+// extracting the downloaded archive are left to the wrapped client.
+type resumableClient struct {
+	distribution.Client
+
+	httpClient *http.Client
+}
+
+func newResumableClient(c distribution.Client, timeout time.Duration) *resumableClient {
+	return &resumableClient{
+		Client:     c,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Download downloads the database archive at archiveURL and extracts it into a
+// new temporary directory within dest, returning the directory's path. If
+// archiveURL has a "checksum" query parameter (as added by ResolveArchiveURL),
+// the archive is verified against it before it's extracted.
+func (c *resumableClient) Download(archiveURL, dest string, downloadProgress *progress.Manual) (string, error) {
+	u, err := url.Parse(archiveURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing database archive URL %q: %w", archiveURL, err)
+	}
+
+	// The checksum is for us, not for the server.
+	query := u.Query()
+	checksum := query.Get("checksum")
+	query.Del("checksum")
+	u.RawQuery = query.Encode()
+
+	downloadsDir := filepath.Join(dest, downloadsDirName)
+	if err := os.MkdirAll(downloadsDir, 0o700); err != nil {
+		return "", fmt.Errorf("creating database downloads directory: %w", err)
+	}
+
+	// The archive's file extension must be kept, since it determines how the
+	// archive is extracted.
+	archivePath := filepath.Join(downloadsDir, path.Base(u.Path))
+
+	// Archive names are unique to each database build, so any other partial
+	// downloads are for outdated databases and won't be resumed.
+	entries, err := os.ReadDir(downloadsDir)
+	if err != nil {
+		return "", fmt.Errorf("reading database downloads directory: %w", err)
+	}
+	for _, entry := range entries {
+		if p := filepath.Join(downloadsDir, entry.Name()); p != archivePath {
+			os.RemoveAll(p)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = downloadToFile(context.Background(), c.httpClient, u.String(), archivePath, downloadProgress)
+		if err == nil {
+			break
+		}
+		if attempt == maxDownloadAttempts {
+			return "", fmt.Errorf("downloading database archive from %s: %w", u.Redacted(), err)
+		}
+	}
+
+	if checksum != "" {
+		if err := verifyChecksum(archivePath, checksum); err != nil {
+			// Don't resume from a corrupt download next time.
+			os.Remove(archivePath)
+			return "", fmt.Errorf("verifying database archive from %s: %w", u.Redacted(), err)
+		}
+	}
+
+	tempDir, err := c.Client.Download(archivePath, dest, downloadProgress)
+	if err != nil {
+		return "", err
+	}
+
+	os.Remove(archivePath)
+
+	return tempDir, nil
+}
+
+// downloadToFile downloads the resource at the given URL to the file at
+// filePath. If the file already exists, it's assumed to hold the first part of
+// the resource, and only the rest of the resource is requested.
+func downloadToFile(ctx context.Context, client *http.Client, rawURL, filePath string, downloadProgress *progress.Manual) error {
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// Resuming the download.
+
+	case http.StatusOK:
+		// Either there's nothing to resume, or the server doesn't support range
+		// requests. Either way, start over.
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		offset = 0
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The download was already complete.
+		return nil
+
+	default:
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var body io.Reader = resp.Body
+	if downloadProgress != nil {
+		downloadProgress.Set(offset)
+		if resp.ContentLength >= 0 {
+			downloadProgress.SetTotal(offset + resp.ContentLength)
+		}
+		body = progress.NewProxyReader(resp.Body, downloadProgress)
+	}
+
+	if _, err := io.Copy(f, body); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// verifyChecksum checks that the file at filePath has the given checksum, which
+// is of the form "sha256:<hex digest>".
+func verifyChecksum(filePath, checksum string) error {
+	algorithm, expected, ok := strings.Cut(checksum, ":")
+	if !ok || algorithm != "sha256" {
+		return fmt.Errorf("unsupported checksum %q", checksum)
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch: expected sha256:%s, got sha256:%s", expected, actual)
+	}
+
+	return nil
+}
+
+// updateDatabase downloads and installs the latest vulnerability database, if
+// it's newer than the installed database.
+func updateDatabase(installCfg installation.Config, client distribution.Client) error {
+	curator, err := installation.NewCurator(installCfg, client)
+	if err != nil {
+		return fmt.Errorf("creating database curator: %w", err)
+	}
+
+	_, err = curator.Update()
+	return err
+}
@@ -0,0 +1,104 @@
+package scan
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anchore/grype/grype/db/v6/distribution"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wagoodman/go-progress"
+)
+
+// fakeDistributionClient "extracts" an archive by recording its contents.
+type fakeDistributionClient struct {
+	distribution.Client
+
+	extracted []byte
+}
+
+func (c *fakeDistributionClient) Download(archivePath, dest string, _ *progress.Manual) (string, error) {
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		return "", err
+	}
+	c.extracted = data
+
+	return os.MkdirTemp(dest, "extracted")
+}
+
+func TestResumableClient_Download(t *testing.T) {
+	archive := bytes.Repeat([]byte("vulnerability data "), 1000)
+	sum := sha256.Sum256(archive)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "db.tar.zst", time.Time{}, bytes.NewReader(archive))
+	}))
+	defer srv.Close()
+
+	t.Run("resumes a partial download", func(t *testing.T) {
+		ranges = nil
+		dest := t.TempDir()
+
+		// Simulate an earlier, interrupted download, and a leftover download of an
+		// outdated database.
+		downloadsDir := filepath.Join(dest, downloadsDirName)
+		require.NoError(t, os.MkdirAll(downloadsDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(downloadsDir, "db.tar.zst"), archive[:100], 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(downloadsDir, "old.tar.zst"), []byte("old"), 0o600))
+
+		inner := &fakeDistributionClient{}
+		c := newResumableClient(inner, time.Minute)
+
+		tempDir, err := c.Download(srv.URL+"/v6/db.tar.zst?checksum="+checksum, dest, progress.NewManual(-1))
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(tempDir, dest))
+		assert.Equal(t, []string{"bytes=100-"}, ranges)
+		assert.Equal(t, archive, inner.extracted)
+
+		entries, err := os.ReadDir(downloadsDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		dest := t.TempDir()
+
+		inner := &fakeDistributionClient{}
+		c := newResumableClient(inner, time.Minute)
+
+		_, err := c.Download(srv.URL+"/v6/db.tar.zst?checksum=sha256:0000", dest, progress.NewManual(-1))
+		assert.ErrorContains(t, err, "checksum mismatch")
+		assert.Nil(t, inner.extracted)
+
+		// The corrupt download shouldn't be resumed later.
+		assert.NoFileExists(t, filepath.Join(dest, downloadsDirName, "db.tar.zst"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		notFound := httptest.NewServer(http.NotFoundHandler())
+		defer notFound.Close()
+
+		c := newResumableClient(&fakeDistributionClient{}, time.Minute)
+
+		_, err := c.Download(notFound.URL+"/v6/db.tar.zst", t.TempDir(), progress.NewManual(-1))
+		assert.ErrorContains(t, err, "unexpected status code 404")
+	})
+}
+
+func TestNewScanner_invalidDatabaseAgePolicy(t *testing.T) {
+	_, err := NewScanner(Options{DatabaseAgePolicy: "ignore"})
+	assert.ErrorContains(t, err, `invalid database age policy "ignore"`)
+}
@@ -0,0 +1,77 @@
+package scan
+
+import (
+	"sort"
+)
+
+// ResultDiff describes how the findings changed between two scan results,
+// typically from two builds of the same package.
+type ResultDiff struct {
+	Before TargetAPK
+	After  TargetAPK
+
+	// Added are findings present only in the "after" result.
+	Added []Finding
+
+	// Removed are findings present only in the "before" result.
+	Removed []Finding
+
+	// Unchanged are findings present in both results. The findings are taken
+	// from the "after" result.
+	Unchanged []Finding
+}
+
+// DiffResults compares the findings of two scan results.
+//
+// Findings are matched by their vulnerability ID and the name and type of the
+// affected package. The package version and location are ignored, since these
+// are expected to change between builds.
+func DiffResults(before, after *Result) ResultDiff {
+	diff := ResultDiff{
+		Before: before.TargetAPK,
+		After:  after.TargetAPK,
+	}
+
+	beforeKeys := make(map[findingDiffKey]struct{}, len(before.Findings))
+	for i := range before.Findings {
+		beforeKeys[diffKeyForFinding(&before.Findings[i])] = struct{}{}
+	}
+
+	afterKeys := make(map[findingDiffKey]struct{}, len(after.Findings))
+	for i := range after.Findings {
+		f := after.Findings[i]
+		k := diffKeyForFinding(&f)
+		afterKeys[k] = struct{}{}
+
+		if _, ok := beforeKeys[k]; ok {
+			diff.Unchanged = append(diff.Unchanged, f)
+		} else {
+			diff.Added = append(diff.Added, f)
+		}
+	}
+
+	for i := range before.Findings {
+		f := before.Findings[i]
+		if _, ok := afterKeys[diffKeyForFinding(&f)]; !ok {
+			diff.Removed = append(diff.Removed, f)
+		}
+	}
+
+	sort.Stable(Findings(diff.Added))
+	sort.Stable(Findings(diff.Removed))
+	sort.Stable(Findings(diff.Unchanged))
+
+	return diff
+}
+
+type findingDiffKey struct {
+	packageName, packageType, vulnerabilityID string
+}
+
+func diffKeyForFinding(f *Finding) findingDiffKey {
+	return findingDiffKey{
+		packageName:     f.Package.Name,
+		packageType:     f.Package.Type,
+		vulnerabilityID: f.Vulnerability.ID,
+	}
+}
@@ -0,0 +1,50 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffResults(t *testing.T) {
+	finding := func(pkgName, pkgVersion, vulnID string) Finding {
+		return Finding{
+			Package:       Package{Name: pkgName, Version: pkgVersion, Type: "go-module", Location: "/usr/bin/foo"},
+			Vulnerability: Vulnerability{ID: vulnID},
+		}
+	}
+
+	before := &Result{
+		TargetAPK: TargetAPK{Name: "foo", Version: "1.2.3-r0"},
+		Findings: []Finding{
+			finding("golang.org/x/net", "v0.17.0", "GHSA-4374-p667-p6c8"),
+			finding("golang.org/x/net", "v0.17.0", "GHSA-qppj-fm5r-hxr3"),
+			finding("stdlib", "go1.21.0", "CVE-2023-45283"),
+		},
+	}
+
+	after := &Result{
+		TargetAPK: TargetAPK{Name: "foo", Version: "1.2.4-r0"},
+		Findings: []Finding{
+			finding("golang.org/x/net", "v0.23.0", "GHSA-qppj-fm5r-hxr3"),
+			finding("stdlib", "go1.22.1", "CVE-2024-24790"),
+		},
+	}
+
+	ids := func(fs []Finding) []string {
+		var out []string
+		for i := range fs {
+			out = append(out, fs[i].Vulnerability.ID)
+		}
+		return out
+	}
+
+	diff := DiffResults(before, after)
+
+	assert.Equal(t, "1.2.3-r0", diff.Before.Version)
+	assert.Equal(t, "1.2.4-r0", diff.After.Version)
+	assert.Equal(t, []string{"CVE-2024-24790"}, ids(diff.Added))
+	assert.Equal(t, []string{"GHSA-4374-p667-p6c8", "CVE-2023-45283"}, ids(diff.Removed))
+	assert.Equal(t, []string{"GHSA-qppj-fm5r-hxr3"}, ids(diff.Unchanged))
+	assert.Equal(t, "v0.23.0", diff.Unchanged[0].Package.Version, "unchanged findings should come from the after result")
+}
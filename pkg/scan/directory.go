@@ -0,0 +1,47 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+
+	sbomSyft "github.com/anchore/syft/syft/sbom"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/sbom"
+)
+
+// ScanFS scans the contents of a directory, such as an unpacked container image
+// rootfs, for vulnerabilities. Every package found in the directory is matched,
+// using the same matching rules (e.g. trusted CPE sources) as ScanAPK.
+//
+// Since the directory isn't an APK, only the Name of the result's TargetAPK is
+// set, to the directory's base name.
+func (s *Scanner) ScanFS(ctx context.Context, dir, distroID string) (*Result, error) {
+	clog.FromContext(ctx).Info("scanning directory for vulnerabilities", "path", dir)
+
+	ssbom, err := sbom.GenerateForDirectory(ctx, dir, distroID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SBOM from directory: %w", err)
+	}
+
+	return s.DirectorySBOM(ctx, ssbom)
+}
+
+// DirectorySBOM scans an SBOM of a directory (see sbom.GenerateForDirectory) for
+// vulnerabilities.
+func (s *Scanner) DirectorySBOM(ctx context.Context, ssbom *sbomSyft.SBOM) (*Result, error) {
+	clog.FromContext(ctx).Debug("scanning directory SBOM for vulnerabilities", "packageCount", ssbom.Artifacts.Packages.PackageCount())
+
+	findings, err := s.findingsForSBOM(ctx, ssbom)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		SchemaVersion: ResultSchemaVersion,
+		TargetAPK:     TargetAPK{Name: ssbom.Source.Name},
+		Findings:      findings,
+		DataSource:    s.DataSource(),
+	}
+
+	return result, nil
+}
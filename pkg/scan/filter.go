@@ -19,6 +19,47 @@ const (
 
 var ValidAdvisoriesSets = []string{AdvisoriesSetResolved, AdvisoriesSetAll, AdvisoriesSetConcluded}
 
+// AdvisoryStatusNone is the AdvisoryStatus of a finding that has no advisory.
+const AdvisoryStatusNone = "none"
+
+// AnnotateWithAdvisories sets the CGAID and AdvisoryStatus of each finding in
+// the result, using the advisories for the target APK. Unlike
+// FilterWithAdvisories, it keeps every finding, so findings whose advisory is
+// missing (AdvisoryStatusNone) or not yet concluded stand out.
+func AnnotateWithAdvisories(ctx context.Context, result *Result, advGetter advisory.Getter) error {
+	if advGetter == nil {
+		return fmt.Errorf("advGetter cannot be nil")
+	}
+
+	packageAdvisories, err := advGetter.Advisories(ctx, result.TargetAPK.Origin())
+	if err != nil {
+		return fmt.Errorf("getting advisories for package %q: %w", result.TargetAPK.Origin(), err)
+	}
+
+	advsByVulnID := advisory.MapByVulnID(packageAdvisories)
+
+	for i := range result.Findings {
+		f := &result.Findings[i]
+
+		f.AdvisoryStatus = AdvisoryStatusNone
+
+		for _, id := range append([]string{f.Vulnerability.ID}, f.Vulnerability.Aliases...) {
+			adv, ok := advsByVulnID[id]
+			if !ok || len(adv.Events) == 0 {
+				continue
+			}
+
+			f.CGAID = adv.ID
+			f.AdvisoryStatus = adv.Latest().Type
+			break
+		}
+	}
+
+	clog.FromContext(ctx).Debug("annotated findings with advisory status", "targetAPKOrigin", result.TargetAPK.Origin(), "findingCount", len(result.Findings))
+
+	return nil
+}
+
 // FilterWithAdvisories filters the findings in the result based on the advisories for the target APK.
 func FilterWithAdvisories(ctx context.Context, result Result, advGetter advisory.Getter, advisoryFilterSet string) ([]Finding, error) {
 	log := clog.FromContext(ctx).With(
@@ -1,6 +1,7 @@
 package scan
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -416,6 +417,39 @@ func TestFilterWithAdvisories(t *testing.T) {
 			},
 			errAssertion: assert.NoError,
 		},
+		{
+			name: "archived false positive",
+			result: &Result{
+				TargetAPK: TargetAPK{
+					Name:    "ko",
+					Version: "42",
+				},
+				Findings: []Finding{
+					{
+						Vulnerability: Vulnerability{
+							ID: "CVE-1999-11111",
+						},
+					},
+					{
+						Vulnerability: Vulnerability{
+							ID: "CVE-2000-22222",
+						},
+					},
+				},
+			},
+			advisoryGetterFunc: func(_ *testing.T) advisory.Getter {
+				return advisory.NewFSGetter(os.DirFS(filepath.Join("testdata", "archived_advisories")))
+			},
+			advisoryFilterSet: "resolved",
+			expectedFindings: []Finding{
+				{
+					Vulnerability: Vulnerability{
+						ID: "CVE-1999-11111",
+					},
+				},
+			},
+			errAssertion: assert.NoError,
+		},
 	}
 
 	for _, tt := range cases {
@@ -438,6 +472,56 @@ func TestFilterWithAdvisories(t *testing.T) {
 	}
 }
 
+func TestAnnotateWithAdvisories(t *testing.T) {
+	result := &Result{
+		TargetAPK: TargetAPK{
+			Name:    "foo",
+			Version: "0.13.0-r2",
+		},
+		Findings: []Finding{
+			{
+				Vulnerability: Vulnerability{ID: "CVE-1999-11111"},
+			},
+			{
+				Vulnerability: Vulnerability{ID: "GHSA-xxxx-xxxx-xxxx", Aliases: []string{"GHSA-2h5h-59f5-c5x9"}},
+			},
+			{
+				Vulnerability: Vulnerability{ID: "CVE-2024-00000"},
+			},
+		},
+	}
+
+	err := AnnotateWithAdvisories(context.Background(), result, getSingleAdvisoriesGetter(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Finding{
+		{
+			Vulnerability:  Vulnerability{ID: "CVE-1999-11111"},
+			CGAID:          "CGA-9111-1111-1111",
+			AdvisoryStatus: "false-positive-determination",
+		},
+		{
+			Vulnerability:  Vulnerability{ID: "GHSA-xxxx-xxxx-xxxx", Aliases: []string{"GHSA-2h5h-59f5-c5x9"}},
+			CGAID:          "CGA-1999-9999-9999",
+			AdvisoryStatus: "fixed",
+		},
+		{
+			Vulnerability:  Vulnerability{ID: "CVE-2024-00000"},
+			AdvisoryStatus: AdvisoryStatusNone,
+		},
+	}
+
+	if diff := cmp.Diff(expected, result.Findings); diff != "" {
+		t.Errorf("unexpected findings (-want +got):\n%s", diff)
+	}
+
+	t.Run("nil advisory getter", func(t *testing.T) {
+		assert.Error(t, AnnotateWithAdvisories(context.Background(), &Result{}, nil))
+	})
+}
+
 func getSingleAdvisoriesGetter(t *testing.T) advisory.Getter {
 	t.Helper()
 
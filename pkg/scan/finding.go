@@ -15,6 +15,33 @@ type Finding struct {
 	Vulnerability Vulnerability
 	CGAID         string `json:",omitempty"`
 
+	// AdvisoryStatus is the type of the latest event of the finding's advisory
+	// (e.g. "fixed" or "false-positive-determination"), or AdvisoryStatusNone if
+	// there's no advisory for the finding. It's only set when findings are
+	// annotated using AnnotateWithAdvisories.
+	AdvisoryStatus string `json:",omitempty"`
+
+	// KEV is set when the vulnerability is listed in the CISA Known Exploited
+	// Vulnerabilities catalog. See KEVCatalog.Annotate.
+	KEV *KEVEntry `json:",omitempty"`
+
+	// Arches lists the architectures in which the finding was found, when results
+	// for multiple architectures have been merged. See MergeArches.
+	Arches []string `json:",omitempty"`
+
+	// TriageSuggestion is a heuristic suggestion for how to triage the finding,
+	// derived from the details of the vulnerability match.
+	TriageSuggestion *TriageSuggestion `json:",omitempty"`
+
+	// Sources lists the vulnerability sources that reported the finding (e.g.
+	// "grype" and "osv"), when sources other than the Grype database are used.
+	// See Options.Sources.
+	Sources []string `json:",omitempty"`
+
+	// Reachability is govulncheck's assessment of whether the vulnerable code can
+	// be reached, for Go module findings. See AnalyzeReachability.
+	Reachability *Reachability `json:",omitempty"`
+
 	// Deprecated: This field will be removed soon. Plan to use CGAID to lookup the
 	// associated advisory out-of-band, instead of using this pointer.
 	Advisory *v2.Advisory `json:",omitempty"`
@@ -66,6 +93,11 @@ type Vulnerability struct {
 	Severity     string
 	Aliases      []string
 	FixedVersion string
+
+	// MatchType is how the vulnerability was matched to the package (e.g.
+	// "exact-direct-match" or "cpe-match"). When the match was made in more than
+	// one way, the most specific way is used.
+	MatchType string `json:",omitempty"`
 }
 
 // Deprecated: This type will be removed soon.
@@ -143,12 +175,28 @@ func mapMatchToFinding(m match.Match, vulnProvider vulnerability.Provider) (*Fin
 			Severity:     metadata.Severity,
 			Aliases:      aliases,
 			FixedVersion: getFixedVersion(m.Vulnerability),
+			MatchType:    string(matchTypeOf(&m)),
 		},
 	}
 
 	return f, nil
 }
 
+// matchTypeOf returns the most specific type of the given match's details.
+func matchTypeOf(m *match.Match) match.Type {
+	var t match.Type
+	for _, d := range m.Details {
+		switch {
+		case d.Type == match.ExactDirectMatch:
+			return d.Type
+		case d.Type == match.ExactIndirectMatch, t == "":
+			t = d.Type
+		}
+	}
+
+	return t
+}
+
 func getFixedVersion(vuln vulnerability.Vulnerability) string {
 	if vuln.Fix.State != vulnerability.FixStateFixed {
 		return ""
@@ -156,3 +204,29 @@ func getFixedVersion(vuln vulnerability.Vulnerability) string {
 
 	return strings.Join(vuln.Fix.Versions, ", ")
 }
+
+// FixedFindings returns only the findings whose vulnerability has a fixed
+// version available.
+func FixedFindings(findings []Finding) []Finding {
+	var result []Finding
+	for i := range findings {
+		if findings[i].Vulnerability.FixedVersion != "" {
+			result = append(result, findings[i])
+		}
+	}
+
+	return result
+}
+
+// UnfixedFindings returns only the findings whose vulnerability has no fixed
+// version available.
+func UnfixedFindings(findings []Finding) []Finding {
+	var result []Finding
+	for i := range findings {
+		if findings[i].Vulnerability.FixedVersion == "" {
+			result = append(result, findings[i])
+		}
+	}
+
+	return result
+}
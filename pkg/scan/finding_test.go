@@ -0,0 +1,43 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedAndUnfixedFindings(t *testing.T) {
+	findings := []Finding{
+		{Vulnerability: Vulnerability{ID: "CVE-2024-0001", FixedVersion: "1.2.3"}},
+		{Vulnerability: Vulnerability{ID: "CVE-2024-0002"}},
+		{Vulnerability: Vulnerability{ID: "CVE-2024-0003", FixedVersion: "1.2.4, 2.0.1"}},
+	}
+
+	ids := func(findings []Finding) []string {
+		var result []string
+		for i := range findings {
+			result = append(result, findings[i].Vulnerability.ID)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"CVE-2024-0001", "CVE-2024-0003"}, ids(FixedFindings(findings)))
+	assert.Equal(t, []string{"CVE-2024-0002"}, ids(UnfixedFindings(findings)))
+	assert.Empty(t, FixedFindings(nil))
+}
+
+func TestMatchTypeOf(t *testing.T) {
+	matchWith := func(types ...match.Type) *match.Match {
+		m := &match.Match{}
+		for _, typ := range types {
+			m.Details = append(m.Details, match.Detail{Type: typ})
+		}
+		return m
+	}
+
+	assert.Equal(t, match.Type(""), matchTypeOf(matchWith()))
+	assert.Equal(t, match.CPEMatch, matchTypeOf(matchWith(match.CPEMatch)))
+	assert.Equal(t, match.ExactIndirectMatch, matchTypeOf(matchWith(match.CPEMatch, match.ExactIndirectMatch)))
+	assert.Equal(t, match.ExactDirectMatch, matchTypeOf(matchWith(match.ExactIndirectMatch, match.ExactDirectMatch, match.CPEMatch)))
+}
@@ -0,0 +1,389 @@
+package scan
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/adrg/xdg"
+	// Registers the "sqlite" database/sql driver (pure Go, like the one Grype uses).
+	_ "github.com/glebarez/go-sqlite"
+)
+
+// DefaultHistoryPath is the default location of the scan history database.
+var DefaultHistoryPath = path.Join(xdg.DataHome, "wolfictl", "scan", "history.db")
+
+const historySchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	package TEXT NOT NULL,
+	version TEXT NOT NULL,
+	arch TEXT NOT NULL,
+	scanned_at INTEGER NOT NULL,
+	db_built INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS runs_by_package ON runs (package, arch, scanned_at);
+CREATE TABLE IF NOT EXISTS findings (
+	run_id INTEGER NOT NULL REFERENCES runs (id),
+	vulnerability_id TEXT NOT NULL,
+	severity TEXT NOT NULL,
+	fixed_version TEXT NOT NULL,
+	package_name TEXT NOT NULL,
+	package_version TEXT NOT NULL,
+	package_type TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS findings_by_run ON findings (run_id);
+`
+
+// History is a local store of scan results over time, for reporting trends in
+// each package's findings across scans. It's safe for concurrent use.
+type History struct {
+	db *sql.DB
+}
+
+// HistoryPackage identifies a package (for a particular architecture) whose
+// scans have been recorded in a History.
+type HistoryPackage struct {
+	Name string
+	Arch string
+}
+
+// HistoryRun is a recorded scan of a package.
+type HistoryRun struct {
+	Package   HistoryPackage
+	Version   string
+	ScannedAt time.Time
+
+	// Findings are the findings of the scan. Only the findings' package name,
+	// version and type, and their vulnerability's ID, severity and fixed version,
+	// are recorded.
+	Findings []Finding
+}
+
+// OpenHistory opens the scan history database at the given path (or
+// DefaultHistoryPath, if dbPath is empty), creating it if it doesn't exist yet.
+func OpenHistory(ctx context.Context, dbPath string) (*History, error) {
+	if dbPath == "" {
+		dbPath = DefaultHistoryPath
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return nil, fmt.Errorf("creating scan history directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening scan history %q: %w", dbPath, err)
+	}
+
+	// SQLite only supports one writer at a time, so don't let concurrent scans
+	// compete for the database.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing scan history %q: %w", dbPath, err)
+	}
+
+	return &History{db: db}, nil
+}
+
+// Close closes the history's database.
+func (h *History) Close() error {
+	return h.db.Close()
+}
+
+// Record adds the given scan result to the history, as a scan done at the given
+// time.
+func (h *History) Record(ctx context.Context, result *Result, scannedAt time.Time) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("recording scan of %s: %w", result.TargetAPK.Name, err)
+	}
+	defer tx.Rollback() //nolint:errcheck // This is a no-op once the transaction is committed.
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO runs (package, version, arch, scanned_at, db_built) VALUES (?, ?, ?, ?, ?)`,
+		result.TargetAPK.Name,
+		result.TargetAPK.Version,
+		result.TargetAPK.Arch,
+		scannedAt.Unix(),
+		result.DataSource.Date.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("recording scan of %s: %w", result.TargetAPK.Name, err)
+	}
+
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("recording scan of %s: %w", result.TargetAPK.Name, err)
+	}
+
+	for i := range result.Findings {
+		f := &result.Findings[i]
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO findings (run_id, vulnerability_id, severity, fixed_version, package_name, package_version, package_type) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			runID,
+			f.Vulnerability.ID,
+			f.Vulnerability.Severity,
+			f.Vulnerability.FixedVersion,
+			f.Package.Name,
+			f.Package.Version,
+			f.Package.Type,
+		); err != nil {
+			return fmt.Errorf("recording scan of %s: %w", result.TargetAPK.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("recording scan of %s: %w", result.TargetAPK.Name, err)
+	}
+
+	return nil
+}
+
+// Packages returns the packages that have recorded scans, sorted by name and
+// architecture.
+func (h *History) Packages(ctx context.Context) ([]HistoryPackage, error) {
+	rows, err := h.db.QueryContext(ctx, `SELECT DISTINCT package, arch FROM runs ORDER BY package, arch`)
+	if err != nil {
+		return nil, fmt.Errorf("listing packages in scan history: %w", err)
+	}
+	defer rows.Close()
+
+	var pkgs []HistoryPackage
+	for rows.Next() {
+		var p HistoryPackage
+		if err := rows.Scan(&p.Name, &p.Arch); err != nil {
+			return nil, fmt.Errorf("listing packages in scan history: %w", err)
+		}
+		pkgs = append(pkgs, p)
+	}
+
+	return pkgs, rows.Err()
+}
+
+// Runs returns the recorded scans of the given package, oldest first.
+func (h *History) Runs(ctx context.Context, pkg HistoryPackage) ([]HistoryRun, error) {
+	rows, err := h.db.QueryContext(
+		ctx,
+		`SELECT id, version, scanned_at FROM runs WHERE package = ? AND arch = ? ORDER BY scanned_at, id`,
+		pkg.Name,
+		pkg.Arch,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("reading scan history of %s: %w", pkg.Name, err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	var runs []HistoryRun
+	for rows.Next() {
+		var id, scannedAt int64
+		run := HistoryRun{Package: pkg}
+		if err := rows.Scan(&id, &run.Version, &scannedAt); err != nil {
+			return nil, fmt.Errorf("reading scan history of %s: %w", pkg.Name, err)
+		}
+		run.ScannedAt = time.Unix(scannedAt, 0).UTC()
+
+		ids = append(ids, id)
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading scan history of %s: %w", pkg.Name, err)
+	}
+
+	for i, id := range ids {
+		findings, err := h.findings(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("reading scan history of %s: %w", pkg.Name, err)
+		}
+		runs[i].Findings = findings
+	}
+
+	return runs, nil
+}
+
+func (h *History) findings(ctx context.Context, runID int64) ([]Finding, error) {
+	rows, err := h.db.QueryContext(
+		ctx,
+		`SELECT vulnerability_id, severity, fixed_version, package_name, package_version, package_type FROM findings WHERE run_id = ?`,
+		runID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var findings []Finding
+	for rows.Next() {
+		var f Finding
+		if err := rows.Scan(
+			&f.Vulnerability.ID,
+			&f.Vulnerability.Severity,
+			&f.Vulnerability.FixedVersion,
+			&f.Package.Name,
+			&f.Package.Version,
+			&f.Package.Type,
+		); err != nil {
+			return nil, err
+		}
+		findings = append(findings, f)
+	}
+
+	return findings, rows.Err()
+}
+
+// TrendOptions configures ComputeTrend.
+type TrendOptions struct {
+	// Now is the time as of which the trend is computed.
+	Now time.Time
+
+	// Since, if nonzero, compares the latest scan with the latest scan done at
+	// least this long before Now. Otherwise, the latest scan is compared with the
+	// scan before it.
+	Since time.Duration
+
+	// LongStandingAge is how long a finding must have been present, without
+	// interruption, to be considered long-standing.
+	LongStandingAge time.Duration
+}
+
+// PackageTrend describes how a package's findings have changed across its
+// recorded scans.
+type PackageTrend struct {
+	Package HistoryPackage
+
+	// Runs is the number of recorded scans of the package.
+	Runs int
+
+	// Latest is the version and time of the latest scan.
+	LatestVersion string
+	LatestScan    time.Time
+
+	// Baseline is the time of the scan that the latest scan is compared with. It's
+	// zero if there's no earlier scan to compare with, in which case all findings
+	// of the latest scan are considered newly introduced.
+	Baseline time.Time
+
+	// Introduced are findings of the latest scan that weren't in the baseline
+	// scan.
+	Introduced []TrendFinding
+
+	// Resolved are findings of the baseline scan that aren't in the latest scan.
+	Resolved []TrendFinding
+
+	// LongStanding are findings of the latest scan that have been present for at
+	// least TrendOptions.LongStandingAge.
+	LongStanding []TrendFinding
+}
+
+// TrendFinding is a finding, along with when it first appeared.
+type TrendFinding struct {
+	Finding Finding
+
+	// FirstSeen is the time of the first scan in the uninterrupted sequence of
+	// scans (up to the scan the finding is taken from) that included the finding.
+	FirstSeen time.Time
+}
+
+// ComputeTrend computes the trend of a package's findings from its recorded
+// scans, which must be ordered oldest first, as returned by History.Runs.
+// Findings are matched across scans in the same way as in DiffResults.
+func ComputeTrend(runs []HistoryRun, opts TrendOptions) PackageTrend {
+	if len(runs) == 0 {
+		return PackageTrend{}
+	}
+
+	keys := make([]map[findingDiffKey]struct{}, len(runs))
+	for i := range runs {
+		keys[i] = make(map[findingDiffKey]struct{}, len(runs[i].Findings))
+		for j := range runs[i].Findings {
+			keys[i][diffKeyForFinding(&runs[i].Findings[j])] = struct{}{}
+		}
+	}
+
+	// firstSeen returns when the finding with key k was first seen in the
+	// uninterrupted sequence of scans ending with runs[i].
+	firstSeen := func(i int, k findingDiffKey) time.Time {
+		for i > 0 {
+			if _, ok := keys[i-1][k]; !ok {
+				break
+			}
+			i--
+		}
+		return runs[i].ScannedAt
+	}
+
+	latestIdx := len(runs) - 1
+	latest := &runs[latestIdx]
+
+	trend := PackageTrend{
+		Package:       latest.Package,
+		Runs:          len(runs),
+		LatestVersion: latest.Version,
+		LatestScan:    latest.ScannedAt,
+	}
+
+	baselineIdx := latestIdx - 1
+	if opts.Since > 0 {
+		cutoff := opts.Now.Add(-opts.Since)
+		for baselineIdx >= 0 && runs[baselineIdx].ScannedAt.After(cutoff) {
+			baselineIdx--
+		}
+	}
+
+	baselineKeys := map[findingDiffKey]struct{}{}
+	if baselineIdx >= 0 {
+		trend.Baseline = runs[baselineIdx].ScannedAt
+		baselineKeys = keys[baselineIdx]
+
+		for i := range runs[baselineIdx].Findings {
+			f := runs[baselineIdx].Findings[i]
+			k := diffKeyForFinding(&f)
+			if _, ok := keys[latestIdx][k]; !ok {
+				trend.Resolved = append(trend.Resolved, TrendFinding{Finding: f, FirstSeen: firstSeen(baselineIdx, k)})
+			}
+		}
+	}
+
+	for i := range latest.Findings {
+		f := latest.Findings[i]
+		k := diffKeyForFinding(&f)
+		tf := TrendFinding{Finding: f, FirstSeen: firstSeen(latestIdx, k)}
+
+		if _, ok := baselineKeys[k]; !ok {
+			trend.Introduced = append(trend.Introduced, tf)
+		}
+
+		if opts.Now.Sub(tf.FirstSeen) >= opts.LongStandingAge {
+			trend.LongStanding = append(trend.LongStanding, tf)
+		}
+	}
+
+	sortTrendFindings(trend.Introduced)
+	sortTrendFindings(trend.Resolved)
+	sortTrendFindings(trend.LongStanding)
+
+	return trend
+}
+
+func sortTrendFindings(findings []TrendFinding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if !findings[i].FirstSeen.Equal(findings[j].FirstSeen) {
+			return findings[i].FirstSeen.Before(findings[j].FirstSeen)
+		}
+		a, b := &findings[i].Finding, &findings[j].Finding
+		if a.Package.Name != b.Package.Name {
+			return a.Package.Name < b.Package.Name
+		}
+		return a.Vulnerability.ID < b.Vulnerability.ID
+	})
+}
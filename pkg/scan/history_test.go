@@ -0,0 +1,98 @@
+package scan
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := OpenHistory(ctx, filepath.Join(t.TempDir(), "nested", "history.db"))
+	require.NoError(t, err)
+	defer h.Close()
+
+	finding := func(pkg, id string) Finding {
+		return Finding{
+			Package:       Package{Name: pkg, Version: "1.0.0", Type: "go-module"},
+			Vulnerability: Vulnerability{ID: id, Severity: "High", FixedVersion: "1.0.1"},
+		}
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	record := func(name, arch, version string, scannedAt time.Time, findings ...Finding) {
+		t.Helper()
+		require.NoError(t, h.Record(ctx, &Result{
+			TargetAPK:  TargetAPK{Name: name, Version: version, Arch: arch},
+			Findings:   findings,
+			DataSource: DataSource{Date: scannedAt},
+		}, scannedAt))
+	}
+
+	record("crane", "x86_64", "0.19.0-r0", day(1), finding("stdlib", "CVE-2024-0001"), finding("golang.org/x/net", "GHSA-aaaa"))
+	record("crane", "x86_64", "0.19.0-r1", day(10), finding("stdlib", "CVE-2024-0001"), finding("golang.org/x/net", "GHSA-aaaa"))
+	record("crane", "x86_64", "0.19.1-r0", day(20), finding("stdlib", "CVE-2024-0001"), finding("stdlib", "CVE-2024-0002"))
+	record("crane", "aarch64", "0.19.1-r0", day(20))
+	record("apko", "x86_64", "0.14.0-r0", day(15), finding("stdlib", "CVE-2024-0002"))
+
+	pkgs, err := h.Packages(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []HistoryPackage{
+		{Name: "apko", Arch: "x86_64"},
+		{Name: "crane", Arch: "aarch64"},
+		{Name: "crane", Arch: "x86_64"},
+	}, pkgs)
+
+	runs, err := h.Runs(ctx, HistoryPackage{Name: "crane", Arch: "x86_64"})
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, "0.19.0-r0", runs[0].Version)
+	assert.Equal(t, day(1), runs[0].ScannedAt)
+	assert.Equal(t, []Finding{finding("stdlib", "CVE-2024-0001"), finding("golang.org/x/net", "GHSA-aaaa")}, runs[0].Findings)
+
+	t.Run("compared with previous scan", func(t *testing.T) {
+		trend := ComputeTrend(runs, TrendOptions{Now: day(21), LongStandingAge: 14 * 24 * time.Hour})
+
+		expected := PackageTrend{
+			Package:       HistoryPackage{Name: "crane", Arch: "x86_64"},
+			Runs:          3,
+			LatestVersion: "0.19.1-r0",
+			LatestScan:    day(20),
+			Baseline:      day(10),
+			Introduced:    []TrendFinding{{Finding: finding("stdlib", "CVE-2024-0002"), FirstSeen: day(20)}},
+			Resolved:      []TrendFinding{{Finding: finding("golang.org/x/net", "GHSA-aaaa"), FirstSeen: day(1)}},
+			LongStanding:  []TrendFinding{{Finding: finding("stdlib", "CVE-2024-0001"), FirstSeen: day(1)}},
+		}
+		if diff := cmp.Diff(expected, trend); diff != "" {
+			t.Errorf("ComputeTrend() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("compared with an earlier scan", func(t *testing.T) {
+		trend := ComputeTrend(runs, TrendOptions{Now: day(21), Since: 15 * 24 * time.Hour, LongStandingAge: 30 * 24 * time.Hour})
+
+		assert.Equal(t, day(1), trend.Baseline)
+		assert.Len(t, trend.Introduced, 1)
+		assert.Len(t, trend.Resolved, 1)
+		assert.Empty(t, trend.LongStanding)
+	})
+
+	t.Run("single scan", func(t *testing.T) {
+		apkoRuns, err := h.Runs(ctx, HistoryPackage{Name: "apko", Arch: "x86_64"})
+		require.NoError(t, err)
+
+		trend := ComputeTrend(apkoRuns, TrendOptions{Now: day(21), LongStandingAge: 30 * 24 * time.Hour})
+		assert.True(t, trend.Baseline.IsZero())
+		assert.Len(t, trend.Introduced, 1)
+		assert.Empty(t, trend.Resolved)
+	})
+}
@@ -0,0 +1,108 @@
+package scan
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed html_report.html.tmpl
+var htmlReportTemplateText string
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"vulnURL": vulnURL,
+}).Parse(htmlReportTemplateText))
+
+// htmlReport is the data used to render an HTML report.
+type htmlReport struct {
+	GeneratedAt time.Time
+	DataSource  *DataSource
+	Severities  []string
+	Results     []htmlReportResult
+}
+
+type htmlReportResult struct {
+	Result   *Result
+	Anchor   string
+	Counts   []int
+	Findings []htmlReportFinding
+}
+
+type htmlReportFinding struct {
+	Finding       *Finding
+	SeverityClass string
+	SeverityRank  int
+}
+
+// EncodeHTML writes a standalone HTML report of the given scan results to w. The
+// report summarizes the findings of each result by severity, and lists the
+// findings in tables that can be sorted by clicking their column headings.
+func EncodeHTML(w io.Writer, results []Result, generatedAt time.Time) error {
+	// Most severe first, with unknown severities last.
+	severities := slices.Clone(ValidSeverities)
+	slices.Reverse(severities)
+	severities = append(severities, severityUnknown)
+
+	report := htmlReport{
+		GeneratedAt: generatedAt,
+		Severities:  severities,
+	}
+
+	for i := range results {
+		result := &results[i]
+
+		if report.DataSource == nil && !result.DataSource.Date.IsZero() {
+			report.DataSource = &result.DataSource
+		}
+
+		findings := slices.Clone(result.Findings)
+		sort.SliceStable(findings, func(a, b int) bool {
+			return SeverityRank(findings[a].Vulnerability.Severity) > SeverityRank(findings[b].Vulnerability.Severity)
+		})
+
+		r := htmlReportResult{
+			Result: result,
+			Anchor: fmt.Sprintf("result-%d", i+1),
+			Counts: make([]int, len(severities)),
+		}
+
+		for j := range findings {
+			f := &findings[j]
+
+			severity := strings.ToLower(f.Vulnerability.Severity)
+			if SeverityRank(severity) == 0 {
+				severity = severityUnknown
+			}
+			r.Counts[slices.Index(severities, severity)]++
+
+			r.Findings = append(r.Findings, htmlReportFinding{
+				Finding:       f,
+				SeverityClass: severity,
+				SeverityRank:  SeverityRank(severity),
+			})
+		}
+
+		report.Results = append(report.Results, r)
+	}
+
+	if err := htmlReportTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("rendering HTML report: %w", err)
+	}
+
+	return nil
+}
+
+// vulnURL returns the web URL for the given vulnerability ID, or an empty string
+// if there isn't a known URL.
+func vulnURL(vulnID string) string {
+	if src := cycloneDXSource(vulnID); src != nil {
+		return src.URL
+	}
+
+	return ""
+}
@@ -0,0 +1,62 @@
+package scan
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeHTML(t *testing.T) {
+	results := []Result{
+		{
+			TargetAPK: TargetAPK{Name: "crane", Version: "0.19.1-r6", Arch: "x86_64"},
+			Findings: []Finding{
+				{
+					Package:       Package{Name: "github.com/foo/bar", Version: "v1.2.3", Type: "go-module", Location: "/usr/bin/crane"},
+					Vulnerability: Vulnerability{ID: "GHSA-aaaa-bbbb-cccc", Severity: "Medium", Aliases: []string{"CVE-2024-1234"}},
+				},
+				{
+					Package:       Package{Name: "stdlib", Version: "go1.22.1", Type: "go-module"},
+					Vulnerability: Vulnerability{ID: "CVE-2024-5678", Severity: "Critical", FixedVersion: "1.22.5"},
+					CGAID:         "CGA-xxxx-yyyy-zzzz",
+					KEV:           &KEVEntry{DateAdded: "2024-06-01"},
+				},
+				{
+					Package:       Package{Name: "<script>", Version: "1", Type: "binary"},
+					Vulnerability: Vulnerability{ID: "CVE-2024-9999"},
+				},
+			},
+			DataSource: DataSource{Kind: "grype-db", Date: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			TargetAPK: TargetAPK{Name: "empty", Version: "1.0.0-r0"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, EncodeHTML(buf, results, time.Date(2024, 7, 2, 12, 0, 0, 0, time.UTC)))
+	out := buf.String()
+
+	for _, s := range []string{
+		"Generated 2024-07-02 12:00:00 UTC",
+		"built 2024-07-01 00:00 UTC",
+		`<a href="#result-1">crane</a>`,
+		`<a href="https://nvd.nist.gov/vuln/detail/CVE-2024-5678">CVE-2024-5678</a>`,
+		`<a href="https://github.com/advisories/GHSA-aaaa-bbbb-cccc">GHSA-aaaa-bbbb-cccc</a>`,
+		`<a href="https://nvd.nist.gov/vuln/detail/CVE-2024-1234">CVE-2024-1234</a>`,
+		`<a href="https://images.chainguard.dev/security/CGA-xxxx-yyyy-zzzz">CGA-xxxx-yyyy-zzzz</a>`,
+		`class="severity severity-critical" data-sort="5"`,
+		`class="severity severity-unknown" data-sort="0"`,
+		"Known exploited (CISA KEV)",
+		"&lt;script&gt;",
+		"No vulnerabilities found.",
+	} {
+		assert.Contains(t, out, s)
+	}
+
+	// The most severe findings are listed first.
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("CVE-2024-5678")), bytes.Index(buf.Bytes(), []byte("GHSA-aaaa-bbbb-cccc")))
+}
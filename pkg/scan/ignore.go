@@ -0,0 +1,310 @@
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	"github.com/wolfi-dev/wolfictl/pkg/versions"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultIgnoreFileName is the name of the file that suppression rules are read
+// from when no other file is specified.
+const DefaultIgnoreFileName = ".wolfictl-scan-ignore.yaml"
+
+// IgnoreFile is a set of rules for suppressing scan findings, such as known
+// false positives that haven't (yet) been captured in advisory data.
+//
+// An example file:
+//
+//	rules:
+//	  - vulnerability: CVE-2024-1234
+//	    package: github.com/foo/bar
+//	    version: "< 1.2.3"
+//	    expires: 2025-01-31
+//	    justification: The vulnerable function is never called.
+type IgnoreFile struct {
+	Rules []IgnoreRule `yaml:"rules"`
+}
+
+// IgnoreRule describes the findings to suppress. A finding matches the rule if
+// all the rule's specified criteria match.
+type IgnoreRule struct {
+	// Vulnerability is the ID of the vulnerability (e.g. "CVE-2024-1234"). The
+	// finding matches if this is the ID of its vulnerability or any of its
+	// aliases. Required.
+	Vulnerability string `yaml:"vulnerability"`
+
+	// Package is the name of the package in which the vulnerability was found
+	// (e.g. "crane" or "github.com/foo/bar"). If empty, any package matches.
+	Package string `yaml:"package,omitempty" json:",omitempty"`
+
+	// Version is a constraint on the version of the package, made up of one or
+	// more comma-separated comparisons, e.g. ">= 1.2.0, < 1.2.5". The supported
+	// operators are =, !=, <, <=, >, and >=. If empty, any version matches.
+	Version string `yaml:"version,omitempty" json:",omitempty"`
+
+	// Expires is the date (in YYYY-MM-DD format) from which the rule no longer
+	// applies. If empty, the rule never expires.
+	Expires string `yaml:"expires,omitempty" json:",omitempty"`
+
+	// Justification explains why the findings are being suppressed. Required.
+	Justification string `yaml:"justification"`
+}
+
+// SuppressedFinding is a finding that was suppressed by a rule in an
+// IgnoreFile.
+type SuppressedFinding struct {
+	Finding Finding
+	Rule    IgnoreRule
+}
+
+// LoadIgnoreFile reads and validates the ignore file at the given path.
+func LoadIgnoreFile(path string) (*IgnoreFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening ignore file: %w", err)
+	}
+	defer f.Close()
+
+	ignoreFile, err := DecodeIgnoreFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("loading ignore file %q: %w", path, err)
+	}
+
+	return ignoreFile, nil
+}
+
+// DecodeIgnoreFile decodes and validates an ignore file from the given reader.
+func DecodeIgnoreFile(r io.Reader) (*IgnoreFile, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	ignoreFile := &IgnoreFile{}
+	if err := dec.Decode(ignoreFile); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decoding ignore file: %w", err)
+	}
+
+	if err := ignoreFile.Validate(); err != nil {
+		return nil, err
+	}
+
+	return ignoreFile, nil
+}
+
+// Validate returns an error if any of the ignore file's rules are invalid.
+func (f *IgnoreFile) Validate() error {
+	var errs []error
+	for i := range f.Rules {
+		if err := f.Rules[i].validate(); err != nil {
+			errs = append(errs, fmt.Errorf("rule %d: %w", i+1, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *IgnoreRule) validate() error {
+	var errs []error
+
+	if r.Vulnerability == "" {
+		errs = append(errs, errors.New("vulnerability must be specified"))
+	}
+
+	if strings.TrimSpace(r.Justification) == "" {
+		errs = append(errs, errors.New("justification must be specified"))
+	}
+
+	if _, err := parseVersionConstraint(r.Version); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := r.expiry(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Expired returns true if the rule has an expiry date that's at or before the
+// given time.
+func (r *IgnoreRule) Expired(now time.Time) bool {
+	expiry, err := r.expiry()
+	if err != nil || expiry.IsZero() {
+		return false
+	}
+
+	return !now.Before(expiry)
+}
+
+func (r *IgnoreRule) expiry() (time.Time, error) {
+	if r.Expires == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, r.Expires)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry date %q, must be in YYYY-MM-DD format", r.Expires)
+	}
+
+	return t, nil
+}
+
+// Matches returns true if the given finding matches all the rule's criteria.
+// Expiry isn't considered.
+func (r *IgnoreRule) Matches(finding *Finding) bool {
+	if finding.Vulnerability.ID != r.Vulnerability && !slices.Contains(finding.Vulnerability.Aliases, r.Vulnerability) {
+		return false
+	}
+
+	if r.Package != "" && finding.Package.Name != r.Package {
+		return false
+	}
+
+	constraint, err := parseVersionConstraint(r.Version)
+	if err != nil {
+		return false
+	}
+
+	return constraint.matches(finding.Package.Version)
+}
+
+// ExpiredRules returns the rules that have expired as of the given time.
+func (f *IgnoreFile) ExpiredRules(now time.Time) []IgnoreRule {
+	var expired []IgnoreRule
+	for i := range f.Rules {
+		if f.Rules[i].Expired(now) {
+			expired = append(expired, f.Rules[i])
+		}
+	}
+
+	return expired
+}
+
+// Apply splits the given findings into those that aren't matched by any of the
+// ignore file's unexpired rules, and those that are (which are suppressed).
+func (f *IgnoreFile) Apply(findings []Finding, now time.Time) ([]Finding, []SuppressedFinding) {
+	var (
+		kept       []Finding
+		suppressed []SuppressedFinding
+	)
+
+	for i := range findings {
+		finding := findings[i]
+
+		idx := slices.IndexFunc(f.Rules, func(r IgnoreRule) bool {
+			return !r.Expired(now) && r.Matches(&finding)
+		})
+		if idx == -1 {
+			kept = append(kept, finding)
+			continue
+		}
+
+		suppressed = append(suppressed, SuppressedFinding{
+			Finding: finding,
+			Rule:    f.Rules[idx],
+		})
+	}
+
+	return kept, suppressed
+}
+
+type versionComparison struct {
+	operator string
+	version  string
+}
+
+type versionConstraint []versionComparison
+
+// versionOperators is ordered so that longer operators are checked first.
+var versionOperators = []string{"<=", ">=", "!=", "<", ">", "="}
+
+func parseVersionConstraint(s string) (versionConstraint, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var constraint versionConstraint
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		c := versionComparison{operator: "="}
+		for _, op := range versionOperators {
+			if rest, ok := strings.CutPrefix(part, op); ok {
+				c.operator = op
+				part = strings.TrimSpace(rest)
+				break
+			}
+		}
+
+		if part == "" {
+			return nil, fmt.Errorf("invalid version constraint %q", s)
+		}
+		c.version = part
+
+		constraint = append(constraint, c)
+	}
+
+	return constraint, nil
+}
+
+func (c versionConstraint) matches(version string) bool {
+	for _, comparison := range c {
+		cmp, ok := compareVersions(version, comparison.version)
+		if !ok {
+			// If we can't tell, don't suppress the finding.
+			return false
+		}
+
+		var satisfied bool
+		switch comparison.operator {
+		case "=":
+			satisfied = cmp == 0
+		case "!=":
+			satisfied = cmp != 0
+		case "<":
+			satisfied = cmp < 0
+		case "<=":
+			satisfied = cmp <= 0
+		case ">":
+			satisfied = cmp > 0
+		case ">=":
+			satisfied = cmp >= 0
+		}
+
+		if !satisfied {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareVersions compares the two versions, using APK version semantics if
+// possible, since that's the most common type of package we scan, and falling
+// back to semver-like semantics otherwise. The second return value is false if
+// the versions can't be compared.
+func compareVersions(a, b string) (int, bool) {
+	if av, err := apk.ParseVersion(a); err == nil {
+		if bv, err := apk.ParseVersion(b); err == nil {
+			return apk.CompareVersions(av, bv), true
+		}
+	}
+
+	av, err := versions.NewVersion(a)
+	if err != nil {
+		return 0, false
+	}
+	bv, err := versions.NewVersion(b)
+	if err != nil {
+		return 0, false
+	}
+
+	return av.Compare(bv), true
+}
@@ -0,0 +1,105 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeIgnoreFile(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		f, err := DecodeIgnoreFile(strings.NewReader(`
+rules:
+  - vulnerability: CVE-2024-1234
+    package: github.com/foo/bar
+    version: ">= 1.2.0, < 1.2.5"
+    expires: 2025-01-31
+    justification: The vulnerable function is never called.
+`))
+		require.NoError(t, err)
+		require.Len(t, f.Rules, 1)
+		assert.Equal(t, "github.com/foo/bar", f.Rules[0].Package)
+		assert.Equal(t, "2025-01-31", f.Rules[0].Expires)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		f, err := DecodeIgnoreFile(strings.NewReader(""))
+		require.NoError(t, err)
+		assert.Empty(t, f.Rules)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := DecodeIgnoreFile(strings.NewReader(`
+rules:
+  - package: foo
+    version: "<"
+    expires: next week
+`))
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "vulnerability must be specified")
+		assert.ErrorContains(t, err, "justification must be specified")
+		assert.ErrorContains(t, err, `invalid version constraint "<"`)
+		assert.ErrorContains(t, err, `invalid expiry date "next week"`)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := DecodeIgnoreFile(strings.NewReader(`
+rules:
+  - vulnerability: CVE-2024-1234
+    justification: nope
+    reason: typo
+`))
+		assert.Error(t, err)
+	})
+}
+
+func TestIgnoreFile_Apply(t *testing.T) {
+	finding := func(vulnID, pkgName, version string, aliases ...string) Finding {
+		return Finding{
+			Package:       Package{Name: pkgName, Version: version},
+			Vulnerability: Vulnerability{ID: vulnID, Aliases: aliases},
+		}
+	}
+
+	f := &IgnoreFile{
+		Rules: []IgnoreRule{
+			{Vulnerability: "CVE-2024-0001", Justification: "any package"},
+			{Vulnerability: "CVE-2024-0002", Package: "bar", Justification: "only bar"},
+			{Vulnerability: "CVE-2024-0003", Version: ">= 1.2.0, < 1.2.5-r1", Justification: "version range"},
+			{Vulnerability: "CVE-2024-0004", Expires: "2024-06-01", Justification: "expired"},
+			{Vulnerability: "CVE-2024-0005", Version: "< 1.20.0", Justification: "Go module version"},
+		},
+	}
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	findings := []Finding{
+		finding("GHSA-xxxx-xxxx-xxxx", "foo", "1.0.0", "CVE-2024-0001"), // suppressed via alias
+		finding("CVE-2024-0002", "bar", "1.0.0"),                        // suppressed
+		finding("CVE-2024-0002", "baz", "1.0.0"),                        // wrong package
+		finding("CVE-2024-0003", "qux", "1.2.5-r0"),                     // suppressed
+		finding("CVE-2024-0003", "qux", "1.2.5-r1"),                     // outside range
+		finding("CVE-2024-0004", "foo", "1.0.0"),                        // rule expired
+		finding("CVE-2024-0005", "golang.org/x/net", "v1.19.0"),         // suppressed
+		finding("CVE-2024-0005", "golang.org/x/net", "not-a-version"),   // can't compare
+	}
+
+	kept, suppressed := f.Apply(findings, now)
+
+	assert.Equal(t, []Finding{findings[2], findings[4], findings[5], findings[7]}, kept)
+
+	require.Len(t, suppressed, 4)
+	assert.Equal(t, findings[0], suppressed[0].Finding)
+	assert.Equal(t, "any package", suppressed[0].Rule.Justification)
+	assert.Equal(t, findings[1], suppressed[1].Finding)
+	assert.Equal(t, findings[3], suppressed[2].Finding)
+	assert.Equal(t, findings[6], suppressed[3].Finding)
+
+	expired := f.ExpiredRules(now)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "CVE-2024-0004", expired[0].Vulnerability)
+	assert.Empty(t, f.ExpiredRules(now.AddDate(0, 0, -1)))
+}
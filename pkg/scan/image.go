@@ -0,0 +1,66 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anchore/stereoscope"
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/source/stereoscopesource"
+	"github.com/chainguard-dev/clog"
+	anchorelogger "github.com/wolfi-dev/wolfictl/pkg/anchorelog"
+)
+
+// ImageResult is the outcome of scanning a container image. Unlike Result, it
+// isn't about a single APK: its findings cover all packages in the image.
+type ImageResult struct {
+	// Image is the reference of the image that was scanned.
+	Image string
+
+	// Distro is the ID of the image's distro (e.g. "wolfi"), if it was detected.
+	Distro string
+
+	Findings   []Finding
+	DataSource DataSource
+}
+
+// ScanImage scans the container image with the given reference, which is pulled
+// from its OCI registry.
+func (s *Scanner) ScanImage(ctx context.Context, ref string) (*ImageResult, error) {
+	logger := clog.FromContext(ctx)
+
+	logger.Info("scanning image for vulnerabilities", "ref", ref)
+
+	img, err := stereoscope.GetImageFromSource(ctx, ref, image.OciRegistrySource)
+	if err != nil {
+		return nil, fmt.Errorf("unable to construct scan source for image %q: %w", ref, err)
+	}
+	imgSource := stereoscopesource.New(img, stereoscopesource.ImageConfig{
+		Reference: ref,
+	})
+	defer imgSource.Close()
+
+	syft.SetLogger(anchorelogger.NewSlogAdapter(logger.Base()))
+
+	imgSBOM, err := syft.CreateSBOM(ctx, imgSource, syft.DefaultCreateSBOMConfig())
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SBOM for image %q: %w", ref, err)
+	}
+
+	findings, err := s.findingsForSBOM(ctx, imgSBOM)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImageResult{
+		Image:      ref,
+		Findings:   findings,
+		DataSource: s.DataSource(),
+	}
+	if d := imgSBOM.Artifacts.LinuxDistribution; d != nil {
+		result.Distro = d.ID
+	}
+
+	return result, nil
+}
@@ -0,0 +1,217 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/chainguard-dev/clog"
+)
+
+// KEVCatalogURL is the location of the CISA Known Exploited Vulnerabilities
+// catalog, in JSON format.
+const KEVCatalogURL = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+
+// DefaultKEVCachePath is where the most recently downloaded copy of the KEV
+// catalog is stored.
+var DefaultKEVCachePath = path.Join(xdg.CacheHome, "wolfictl", "kev", "known_exploited_vulnerabilities.json")
+
+// KEVCatalog is the CISA Known Exploited Vulnerabilities catalog.
+type KEVCatalog struct {
+	Title           string     `json:"title"`
+	CatalogVersion  string     `json:"catalogVersion"`
+	DateReleased    string     `json:"dateReleased"`
+	Count           int        `json:"count"`
+	Vulnerabilities []KEVEntry `json:"vulnerabilities"`
+
+	byCVE map[string]*KEVEntry
+}
+
+// KEVEntry is a single vulnerability listed in the KEV catalog.
+type KEVEntry struct {
+	CVEID                      string `json:"cveID"`
+	VendorProject              string `json:"vendorProject"`
+	Product                    string `json:"product"`
+	VulnerabilityName          string `json:"vulnerabilityName"`
+	DateAdded                  string `json:"dateAdded"`
+	ShortDescription           string `json:"shortDescription"`
+	RequiredAction             string `json:"requiredAction"`
+	DueDate                    string `json:"dueDate"`
+	KnownRansomwareCampaignUse string `json:"knownRansomwareCampaignUse"`
+	Notes                      string `json:"notes"`
+}
+
+// KEVOptions configures how the KEV catalog is obtained.
+type KEVOptions struct {
+	// URL is the location from which to download the catalog.
+	URL string
+
+	// CachePath is where the downloaded catalog is stored for later (and offline)
+	// use.
+	CachePath string
+
+	// MaxAge is how old the cached catalog can be before a fresh copy is
+	// downloaded.
+	MaxAge time.Duration
+
+	// Offline prevents any download attempts. The cached catalog is used
+	// regardless of its age.
+	Offline bool
+
+	// HTTPClient is used to download the catalog. If nil, http.DefaultClient is
+	// used.
+	HTTPClient *http.Client
+}
+
+// DefaultKEVOptions are the recommended options for obtaining the KEV catalog.
+var DefaultKEVOptions = KEVOptions{
+	URL:       KEVCatalogURL,
+	CachePath: DefaultKEVCachePath,
+	MaxAge:    24 * time.Hour,
+}
+
+// LoadKEVCatalog returns the KEV catalog, preferring the cached copy as long as
+// it's newer than opts.MaxAge. If the catalog can't be downloaded, a cached copy
+// of any age is used instead.
+func LoadKEVCatalog(ctx context.Context, opts KEVOptions) (*KEVCatalog, error) {
+	logger := clog.FromContext(ctx)
+
+	info, statErr := os.Stat(opts.CachePath)
+	haveCache := statErr == nil
+
+	if haveCache && (opts.Offline || time.Since(info.ModTime()) < opts.MaxAge) {
+		logger.Debug("using cached KEV catalog", "path", opts.CachePath)
+		return ReadKEVCatalog(opts.CachePath)
+	}
+
+	if opts.Offline {
+		return nil, fmt.Errorf("no cached KEV catalog available at %s, and downloads are disabled", opts.CachePath)
+	}
+
+	if err := downloadKEVCatalog(ctx, opts); err != nil {
+		if !haveCache {
+			return nil, err
+		}
+
+		logger.Warn("unable to update KEV catalog, using cached copy", "error", err, "path", opts.CachePath)
+	}
+
+	return ReadKEVCatalog(opts.CachePath)
+}
+
+// ReadKEVCatalog reads the KEV catalog from the JSON file at the given path.
+func ReadKEVCatalog(p string) (*KEVCatalog, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("opening KEV catalog: %w", err)
+	}
+	defer f.Close()
+
+	return DecodeKEVCatalog(f)
+}
+
+// DecodeKEVCatalog decodes the KEV catalog from the given JSON data.
+func DecodeKEVCatalog(r io.Reader) (*KEVCatalog, error) {
+	catalog := new(KEVCatalog)
+	if err := json.NewDecoder(r).Decode(catalog); err != nil {
+		return nil, fmt.Errorf("decoding KEV catalog: %w", err)
+	}
+
+	catalog.byCVE = make(map[string]*KEVEntry, len(catalog.Vulnerabilities))
+	for i := range catalog.Vulnerabilities {
+		entry := &catalog.Vulnerabilities[i]
+		catalog.byCVE[entry.CVEID] = entry
+	}
+
+	return catalog, nil
+}
+
+func downloadKEVCatalog(ctx context.Context, opts KEVOptions) error {
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading KEV catalog: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading KEV catalog: unexpected status code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("downloading KEV catalog: %w", err)
+	}
+
+	// Make sure we don't replace a good cached copy with something unusable.
+	if _, err := DecodeKEVCatalog(bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(path.Dir(opts.CachePath), 0o755); err != nil {
+		return fmt.Errorf("creating KEV cache directory: %w", err)
+	}
+
+	tmp := opts.CachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing KEV catalog to cache: %w", err)
+	}
+	if err := os.Rename(tmp, opts.CachePath); err != nil {
+		return errors.Join(fmt.Errorf("storing KEV catalog in cache: %w", err), os.Remove(tmp))
+	}
+
+	clog.FromContext(ctx).Info("downloaded KEV catalog", "path", opts.CachePath)
+	return nil
+}
+
+// Lookup returns the catalog entry for the given CVE ID, or nil if the CVE
+// isn't listed in the catalog.
+func (c *KEVCatalog) Lookup(cveID string) *KEVEntry {
+	return c.byCVE[cveID]
+}
+
+// Annotate sets the KEV field of each of the given findings whose
+// vulnerability (or one of its aliases) is listed in the catalog.
+func (c *KEVCatalog) Annotate(findings []Finding) {
+	for i := range findings {
+		f := &findings[i]
+
+		for _, id := range append([]string{f.Vulnerability.ID}, f.Vulnerability.Aliases...) {
+			if entry := c.Lookup(id); entry != nil {
+				e := *entry
+				f.KEV = &e
+				break
+			}
+		}
+	}
+}
+
+// KnownExploitedFindings returns only the findings that have been marked as
+// known to be exploited.
+func KnownExploitedFindings(findings []Finding) []Finding {
+	var result []Finding
+	for i := range findings {
+		if findings[i].KEV != nil {
+			result = append(result, findings[i])
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,110 @@
+package scan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKEVCatalog = `{
+  "title": "CISA Catalog of Known Exploited Vulnerabilities",
+  "catalogVersion": "2024.05.01",
+  "dateReleased": "2024-05-01T15:00:00.0000Z",
+  "count": 1,
+  "vulnerabilities": [
+    {
+      "cveID": "CVE-2023-44487",
+      "vendorProject": "IETF",
+      "product": "HTTP/2",
+      "vulnerabilityName": "HTTP/2 Rapid Reset Attack Vulnerability",
+      "dateAdded": "2023-10-10",
+      "dueDate": "2023-10-31",
+      "knownRansomwareCampaignUse": "Unknown"
+    }
+  ]
+}`
+
+func TestLoadKEVCatalog(t *testing.T) {
+	ctx := context.Background()
+
+	requests := 0
+	available := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, err := w.Write([]byte(testKEVCatalog))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	opts := KEVOptions{
+		URL:       srv.URL,
+		CachePath: filepath.Join(t.TempDir(), "kev.json"),
+		MaxAge:    time.Hour,
+	}
+
+	t.Run("offline without a cached copy", func(t *testing.T) {
+		offline := opts
+		offline.Offline = true
+		_, err := LoadKEVCatalog(ctx, offline)
+		assert.Error(t, err)
+		assert.Equal(t, 0, requests)
+	})
+
+	t.Run("downloads and caches", func(t *testing.T) {
+		catalog, err := LoadKEVCatalog(ctx, opts)
+		require.NoError(t, err)
+		assert.Equal(t, "2024.05.01", catalog.CatalogVersion)
+		assert.NotNil(t, catalog.Lookup("CVE-2023-44487"))
+		assert.FileExists(t, opts.CachePath)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("uses a fresh cached copy", func(t *testing.T) {
+		_, err := LoadKEVCatalog(ctx, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("falls back to a stale cached copy", func(t *testing.T) {
+		stale := time.Now().Add(-2 * time.Hour)
+		require.NoError(t, os.Chtimes(opts.CachePath, stale, stale))
+		available = false
+
+		catalog, err := LoadKEVCatalog(ctx, opts)
+		require.NoError(t, err)
+		assert.NotNil(t, catalog.Lookup("CVE-2023-44487"))
+		assert.Equal(t, 2, requests)
+	})
+}
+
+func TestKEVCatalogAnnotate(t *testing.T) {
+	catalog, err := DecodeKEVCatalog(strings.NewReader(testKEVCatalog))
+	require.NoError(t, err)
+
+	findings := []Finding{
+		{Vulnerability: Vulnerability{ID: "GHSA-qppj-fm5r-hxr3", Aliases: []string{"CVE-2023-44487"}}},
+		{Vulnerability: Vulnerability{ID: "CVE-2024-0001"}},
+	}
+
+	catalog.Annotate(findings)
+
+	require.NotNil(t, findings[0].KEV)
+	assert.Equal(t, "CVE-2023-44487", findings[0].KEV.CVEID)
+	assert.Nil(t, findings[1].KEV)
+
+	kevOnly := KnownExploitedFindings(findings)
+	require.Len(t, kevOnly, 1)
+	assert.Equal(t, "GHSA-qppj-fm5r-hxr3", kevOnly[0].Vulnerability.ID)
+}
@@ -0,0 +1,114 @@
+package scan
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/syft/syft/pkg"
+)
+
+// matcherTypesByName maps the names used to refer to Grype's matchers (e.g. in
+// Options.EnabledMatchers) to the matchers' types.
+var matcherTypesByName = map[string]match.MatcherType{
+	"apk":        match.ApkMatcher,
+	"bitnami":    match.BitnamiMatcher,
+	"dotnet":     match.DotnetMatcher,
+	"dpkg":       match.DpkgMatcher,
+	"golang":     match.GoModuleMatcher,
+	"java":       match.JavaMatcher,
+	"javascript": match.JavascriptMatcher,
+	"msrc":       match.MsrcMatcher,
+	"portage":    match.PortageMatcher,
+	"python":     match.PythonMatcher,
+	"rpm":        match.RpmMatcher,
+	"ruby":       match.RubyGemMatcher,
+	"rust":       match.RustMatcher,
+	"stock":      match.StockMatcher,
+}
+
+// ValidMatchers are the names of the Grype matchers that can be enabled or
+// disabled using Options.EnabledMatchers and Options.DisabledMatchers. The
+// "stock" matcher handles all package types that don't have a dedicated
+// matcher.
+var ValidMatchers = func() []string {
+	names := make([]string, 0, len(matcherTypesByName))
+	for name := range matcherTypesByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}()
+
+// matcherSelection records which of Grype's matchers are enabled.
+type matcherSelection struct {
+	enabled map[match.MatcherType]bool
+
+	// dedicated maps each package type to the type of the matcher that handles
+	// it. Package types that aren't in this map are handled by the stock matcher.
+	dedicated map[pkg.Type]match.MatcherType
+}
+
+// newMatcherSelection returns the selection of matchers that results from only
+// enabling the named matchers (or all matchers, if none are named), and then
+// disabling the named disabled matchers.
+func newMatcherSelection(enabledNames, disabledNames []string) (*matcherSelection, error) {
+	for _, name := range slices.Concat(enabledNames, disabledNames) {
+		if _, ok := matcherTypesByName[name]; !ok {
+			return nil, fmt.Errorf("invalid matcher %q, must be one of [%s]", name, strings.Join(ValidMatchers, ", "))
+		}
+	}
+
+	s := &matcherSelection{
+		enabled:   make(map[match.MatcherType]bool),
+		dedicated: make(map[pkg.Type]match.MatcherType),
+	}
+
+	for name, t := range matcherTypesByName {
+		s.enabled[t] = (len(enabledNames) == 0 || slices.Contains(enabledNames, name)) && !slices.Contains(disabledNames, name)
+	}
+
+	for _, m := range createMatchers(false) {
+		if m.Type() == match.StockMatcher {
+			continue
+		}
+		for _, t := range m.PackageTypes() {
+			s.dedicated[t] = m.Type()
+		}
+	}
+
+	return s, nil
+}
+
+// filter returns the given matchers that are enabled.
+func (s *matcherSelection) filter(matchers []match.Matcher) []match.Matcher {
+	return slices.DeleteFunc(slices.Clone(matchers), func(m match.Matcher) bool {
+		return !s.enabled[m.Type()]
+	})
+}
+
+// allowsPackageType returns true if packages of the given type should be
+// matched. This is needed in addition to filtering the matchers themselves,
+// because Grype falls back to the stock matcher for packages whose type has no
+// matcher.
+func (s *matcherSelection) allowsPackageType(t pkg.Type) bool {
+	if mt, ok := s.dedicated[t]; ok {
+		return s.enabled[mt]
+	}
+
+	return s.enabled[match.StockMatcher]
+}
+
+// String returns a stable description of the selection.
+func (s *matcherSelection) String() string {
+	var names []string
+	for _, name := range ValidMatchers {
+		if s.enabled[matcherTypesByName[name]] {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
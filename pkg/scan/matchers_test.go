@@ -0,0 +1,62 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcherSelection(t *testing.T) {
+	matcherTypes := func(matchers []match.Matcher) []match.MatcherType {
+		var types []match.MatcherType
+		for _, m := range matchers {
+			types = append(types, m.Type())
+		}
+		return types
+	}
+
+	t.Run("all enabled by default", func(t *testing.T) {
+		s, err := newMatcherSelection(nil, nil)
+		require.NoError(t, err)
+
+		assert.Len(t, s.filter(createMatchers(false)), len(createMatchers(false)))
+		assert.True(t, s.allowsPackageType(pkg.ApkPkg))
+		assert.True(t, s.allowsPackageType(pkg.NpmPkg))
+		assert.True(t, s.allowsPackageType(pkg.BinaryPkg))
+	})
+
+	t.Run("only apk", func(t *testing.T) {
+		s, err := newMatcherSelection([]string{"apk"}, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []match.MatcherType{match.ApkMatcher}, matcherTypes(s.filter(createMatchers(false))))
+		assert.True(t, s.allowsPackageType(pkg.ApkPkg))
+		assert.False(t, s.allowsPackageType(pkg.GoModulePkg))
+		assert.False(t, s.allowsPackageType(pkg.BinaryPkg), "package types without a dedicated matcher need the stock matcher")
+		assert.Equal(t, "apk", s.String())
+	})
+
+	t.Run("disable javascript", func(t *testing.T) {
+		s, err := newMatcherSelection(nil, []string{"javascript"})
+		require.NoError(t, err)
+
+		assert.NotContains(t, matcherTypes(s.filter(createMatchers(false))), match.JavascriptMatcher)
+		assert.False(t, s.allowsPackageType(pkg.NpmPkg))
+		assert.True(t, s.allowsPackageType(pkg.PythonPkg))
+		assert.True(t, s.allowsPackageType(pkg.BinaryPkg))
+	})
+
+	t.Run("enable and disable", func(t *testing.T) {
+		s, err := newMatcherSelection([]string{"apk", "golang"}, []string{"golang"})
+		require.NoError(t, err)
+		assert.Equal(t, "apk", s.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := newMatcherSelection([]string{"cobol"}, nil)
+		assert.ErrorContains(t, err, `invalid matcher "cobol"`)
+	})
+}
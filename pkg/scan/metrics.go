@@ -0,0 +1,141 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/prometheus/common/expfmt"
+)
+
+// severityUnknown is the metric label used for findings without a known
+// severity.
+const severityUnknown = "unknown"
+
+// RunStats summarizes a scan run (i.e. the scans of one or more packages), for
+// reporting as metrics.
+type RunStats struct {
+	// PackagesScanned is the number of packages that were scanned successfully.
+	PackagesScanned int
+
+	// PackagesFailed is the number of packages that couldn't be scanned.
+	PackagesFailed int
+
+	// PackagesTimedOut is the number of packages whose scans were abandoned because
+	// they took too long.
+	PackagesTimedOut int
+
+	// FindingsBySeverity counts the findings by their lowercase severity (e.g.
+	// "high"), or "unknown" if the severity isn't known.
+	FindingsBySeverity map[string]int
+
+	// DataSource describes the vulnerability database used by the scans.
+	DataSource DataSource
+
+	// Duration is how long the run took.
+	Duration time.Duration
+}
+
+// NewRunStats returns a RunStats with no scans counted yet.
+func NewRunStats() *RunStats {
+	return &RunStats{
+		FindingsBySeverity: make(map[string]int),
+	}
+}
+
+// Add counts the given successful scan result.
+func (s *RunStats) Add(result *Result) {
+	s.PackagesScanned++
+
+	for i := range result.Findings {
+		severity := strings.ToLower(result.Findings[i].Vulnerability.Severity)
+		if SeverityRank(severity) == 0 {
+			severity = severityUnknown
+		}
+		s.FindingsBySeverity[severity]++
+	}
+
+	if !result.DataSource.Date.IsZero() {
+		s.DataSource = result.DataSource
+	}
+}
+
+// AddFailure counts a package that couldn't be scanned.
+func (s *RunStats) AddFailure() {
+	s.PackagesFailed++
+}
+
+// AddTimeout counts a package whose scan took too long.
+func (s *RunStats) AddTimeout() {
+	s.PackagesTimedOut++
+}
+
+// registry returns a registry of gauges that describe the run, as of now.
+func (s *RunStats) registry(now time.Time) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+
+	gauge := func(name, help string, value float64) {
+		g := prometheus.NewGauge(prometheus.GaugeOpts{Namespace: "wolfictl", Subsystem: "scan", Name: name, Help: help})
+		g.Set(value)
+		reg.MustRegister(g)
+	}
+
+	gauge("packages_scanned", "Number of packages scanned successfully.", float64(s.PackagesScanned))
+	gauge("packages_failed", "Number of packages that could not be scanned.", float64(s.PackagesFailed))
+	gauge("packages_timed_out", "Number of packages whose scans timed out.", float64(s.PackagesTimedOut))
+	gauge("duration_seconds", "How long the scan run took.", s.Duration.Seconds())
+	gauge("last_run_timestamp_seconds", "When the scan run finished.", float64(now.Unix()))
+
+	if date := s.DataSource.Date; !date.IsZero() {
+		gauge("db_built_timestamp_seconds", "When the vulnerability database was built.", float64(date.Unix()))
+		gauge("db_age_seconds", "Age of the vulnerability database at the end of the scan run.", now.Sub(date).Seconds())
+	}
+
+	findings := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Namespace: "wolfictl", Subsystem: "scan", Name: "findings", Help: "Number of findings, by severity."},
+		[]string{"severity"},
+	)
+	// Always report every severity, so that dashboards don't have gaps.
+	for _, severity := range ValidSeverities {
+		findings.WithLabelValues(severity).Set(float64(s.FindingsBySeverity[severity]))
+	}
+	findings.WithLabelValues(severityUnknown).Set(float64(s.FindingsBySeverity[severityUnknown]))
+	reg.MustRegister(findings)
+
+	return reg
+}
+
+// WriteOpenMetrics writes the run's metrics to w in the OpenMetrics text format.
+func (s *RunStats) WriteOpenMetrics(w io.Writer, now time.Time) error {
+	families, err := s.registry(now).Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics: %w", err)
+	}
+
+	enc := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeOpenMetrics))
+	for _, mf := range families {
+		if err := enc.Encode(mf); err != nil {
+			return fmt.Errorf("encoding metrics: %w", err)
+		}
+	}
+
+	if _, err := expfmt.FinalizeOpenMetrics(w); err != nil {
+		return fmt.Errorf("encoding metrics: %w", err)
+	}
+
+	return nil
+}
+
+// Push pushes the run's metrics to the Prometheus Pushgateway at the given URL,
+// replacing any metrics previously pushed for the given job.
+func (s *RunStats) Push(ctx context.Context, url, job string, now time.Time) error {
+	if err := push.New(url, job).Gatherer(s.registry(now)).PushContext(ctx); err != nil {
+		return fmt.Errorf("pushing metrics to %s: %w", url, err)
+	}
+
+	return nil
+}
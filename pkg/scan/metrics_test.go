@@ -0,0 +1,76 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRunStats() *RunStats {
+	finding := func(severity string) Finding {
+		return Finding{Vulnerability: Vulnerability{ID: "CVE-2024-0001", Severity: severity}}
+	}
+
+	stats := NewRunStats()
+	stats.Add(&Result{
+		Findings: []Finding{finding("High"), finding("critical"), finding("high"), finding("")},
+		DataSource: DataSource{
+			Kind: "grype-db",
+			Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	stats.Add(&Result{})
+	stats.AddFailure()
+	stats.AddTimeout()
+	stats.Duration = 90 * time.Second
+
+	return stats
+}
+
+func TestRunStats_WriteOpenMetrics(t *testing.T) {
+	now := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, testRunStats().WriteOpenMetrics(buf, now))
+	out := buf.String()
+
+	for _, line := range []string{
+		"wolfictl_scan_packages_scanned 2",
+		"wolfictl_scan_packages_failed 1",
+		"wolfictl_scan_packages_timed_out 1",
+		"wolfictl_scan_duration_seconds 90.0",
+		`wolfictl_scan_findings{severity="high"} 2.0`,
+		`wolfictl_scan_findings{severity="critical"} 1.0`,
+		`wolfictl_scan_findings{severity="negligible"} 0.0`,
+		`wolfictl_scan_findings{severity="unknown"} 1.0`,
+		"wolfictl_scan_db_age_seconds 86400.0",
+		"wolfictl_scan_db_built_timestamp_seconds 1.7145216e+09",
+		"# EOF",
+	} {
+		assert.Contains(t, out, line)
+	}
+}
+
+func TestRunStats_Push(t *testing.T) {
+	var gotPath string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		gotBody = body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	require.NoError(t, testRunStats().Push(context.Background(), srv.URL, "nightly", time.Now()))
+	assert.Equal(t, "/metrics/job/nightly", gotPath)
+	assert.Contains(t, string(gotBody), "wolfictl_scan_packages_scanned")
+}
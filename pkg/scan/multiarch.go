@@ -0,0 +1,125 @@
+package scan
+
+import (
+	"slices"
+	"sort"
+)
+
+// MultiArchResult is the combination of the scan results for builds of the same
+// package (i.e. the same name and version) for different architectures.
+type MultiArchResult struct {
+	// TargetAPK describes the package. Its Arch is empty, see Arches instead.
+	TargetAPK TargetAPK
+
+	// Arches are the architectures whose results were merged.
+	Arches []string
+
+	// Findings are the distinct findings across all architectures. Each finding's
+	// Arches field lists the architectures in which it was found.
+	Findings []Finding
+
+	DataSource DataSource
+
+	// Suppressed holds the suppressed findings from all architectures.
+	Suppressed []SuppressedFinding `json:",omitempty"`
+}
+
+// MergeArches combines the given scan results by package name and version, so
+// that the results for each architecture's build of a package become a single
+// MultiArchResult. The merged results are in the order in which each package
+// first appears in results.
+//
+// Findings are considered the same across architectures if they have the same
+// vulnerability ID and affect the same package (by name, version, type, and
+// location).
+func MergeArches(results []Result) []MultiArchResult {
+	type packageKey struct {
+		name, version string
+	}
+
+	type findingKey struct {
+		packageName, packageVersion, packageType, location, vulnerabilityID string
+	}
+
+	var merged []MultiArchResult
+	indexByPackage := make(map[packageKey]int)
+
+	// findingIndexes maps each merged result's findings to their index in the
+	// result's Findings, and suppressedKeys tracks each merged result's
+	// suppressed findings.
+	var (
+		findingIndexes []map[findingKey]int
+		suppressedKeys []map[findingKey]struct{}
+	)
+
+	keyFor := func(f *Finding) findingKey {
+		return findingKey{
+			packageName:     f.Package.Name,
+			packageVersion:  f.Package.Version,
+			packageType:     f.Package.Type,
+			location:        f.Package.Location,
+			vulnerabilityID: f.Vulnerability.ID,
+		}
+	}
+
+	for i := range results {
+		r := &results[i]
+		pk := packageKey{name: r.TargetAPK.Name, version: r.TargetAPK.Version}
+
+		idx, ok := indexByPackage[pk]
+		if !ok {
+			target := r.TargetAPK
+			target.Arch = ""
+
+			idx = len(merged)
+			indexByPackage[pk] = idx
+			merged = append(merged, MultiArchResult{
+				TargetAPK:  target,
+				DataSource: r.DataSource,
+			})
+			findingIndexes = append(findingIndexes, make(map[findingKey]int))
+			suppressedKeys = append(suppressedKeys, make(map[findingKey]struct{}))
+		}
+
+		m := &merged[idx]
+		arch := r.TargetAPK.Arch
+		if !slices.Contains(m.Arches, arch) {
+			m.Arches = append(m.Arches, arch)
+		}
+
+		for j := range r.Findings {
+			f := r.Findings[j]
+			fk := keyFor(&f)
+
+			if k, ok := findingIndexes[idx][fk]; ok {
+				if !slices.Contains(m.Findings[k].Arches, arch) {
+					m.Findings[k].Arches = append(m.Findings[k].Arches, arch)
+				}
+				continue
+			}
+
+			f.Arches = []string{arch}
+			findingIndexes[idx][fk] = len(m.Findings)
+			m.Findings = append(m.Findings, f)
+		}
+
+		for j := range r.Suppressed {
+			fk := keyFor(&r.Suppressed[j].Finding)
+			if _, ok := suppressedKeys[idx][fk]; ok {
+				continue
+			}
+			suppressedKeys[idx][fk] = struct{}{}
+			m.Suppressed = append(m.Suppressed, r.Suppressed[j])
+		}
+	}
+
+	for i := range merged {
+		sort.Strings(merged[i].Arches)
+		for j := range merged[i].Findings {
+			sort.Strings(merged[i].Findings[j].Arches)
+		}
+		sort.Stable(Findings(merged[i].Findings))
+	}
+
+	return merged
+}
@@ -0,0 +1,55 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeArches(t *testing.T) {
+	finding := func(pkgName, vulnID string) Finding {
+		return Finding{
+			Package:       Package{Name: pkgName, Version: "1.0.0", Type: "go-module", Location: "/usr/bin/crane"},
+			Vulnerability: Vulnerability{ID: vulnID},
+		}
+	}
+
+	withArches := func(f Finding, arches ...string) Finding {
+		f.Arches = arches
+		return f
+	}
+
+	results := []Result{
+		{
+			TargetAPK: TargetAPK{Name: "crane", Version: "0.19.1-r6", Arch: "x86_64"},
+			Findings:  []Finding{finding("stdlib", "CVE-2024-0001"), finding("golang.org/x/net", "CVE-2024-0002")},
+		},
+		{
+			TargetAPK: TargetAPK{Name: "jq", Version: "1.7.1-r0", Arch: "x86_64"},
+		},
+		{
+			TargetAPK: TargetAPK{Name: "crane", Version: "0.19.1-r6", Arch: "aarch64"},
+			Findings:  []Finding{finding("stdlib", "CVE-2024-0001"), finding("golang.org/x/net", "CVE-2024-0003")},
+		},
+	}
+
+	expected := []MultiArchResult{
+		{
+			TargetAPK: TargetAPK{Name: "crane", Version: "0.19.1-r6"},
+			Arches:    []string{"aarch64", "x86_64"},
+			Findings: []Finding{
+				withArches(finding("golang.org/x/net", "CVE-2024-0002"), "x86_64"),
+				withArches(finding("golang.org/x/net", "CVE-2024-0003"), "aarch64"),
+				withArches(finding("stdlib", "CVE-2024-0001"), "aarch64", "x86_64"),
+			},
+		},
+		{
+			TargetAPK: TargetAPK{Name: "jq", Version: "1.7.1-r0"},
+			Arches:    []string{"x86_64"},
+		},
+	}
+
+	if diff := cmp.Diff(expected, MergeArches(results)); diff != "" {
+		t.Errorf("unexpected merged results (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,150 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/google/osv-scanner/pkg/models"
+)
+
+// osvSchemaVersion is the version of the OSV schema that OSV records produced
+// from scan results conform to.
+const osvSchemaVersion = "1.6.0"
+
+// OSVRecords returns an OSV record for each finding in the given scan results.
+// Each record lists the affected component by its purl, along with an
+// ECOSYSTEM range derived from the finding's fixed version(s), if any.
+//
+// Scan-specific context (such as severity and the scanned APK) is stored in the
+// records' "database_specific" fields.
+func OSVRecords(results []Result, distroID string) []models.Vulnerability {
+	var records []models.Vulnerability
+
+	for i := range results {
+		result := results[i]
+
+		for j := range result.Findings {
+			records = append(records, osvRecordForFinding(result.Findings[j], result, distroID))
+		}
+	}
+
+	return records
+}
+
+// EncodeOSV writes the OSV records for the given scan results to w, encoded as
+// a JSON array.
+func EncodeOSV(w io.Writer, results []Result, distroID string) error {
+	records := OSVRecords(results, distroID)
+	if records == nil {
+		records = []models.Vulnerability{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return fmt.Errorf("encoding OSV records: %w", err)
+	}
+
+	return nil
+}
+
+func osvRecordForFinding(f Finding, result Result, distroID string) models.Vulnerability {
+	affected := models.Affected{
+		Package: models.Package{
+			Ecosystem: osvEcosystem(f.Package.Type, distroID),
+			Name:      f.Package.Name,
+			Purl:      f.Package.PURL,
+		},
+		Ranges:   osvRangesForFixedVersion(f.Vulnerability.FixedVersion),
+		Versions: []string{f.Package.Version},
+	}
+
+	if f.Package.Location != "" {
+		affected.EcosystemSpecific = map[string]interface{}{
+			"location": f.Package.Location,
+		}
+	}
+
+	databaseSpecific := map[string]interface{}{
+		"severity": f.Vulnerability.Severity,
+		"target_apk": map[string]interface{}{
+			"name":    result.TargetAPK.Name,
+			"version": result.TargetAPK.Version,
+			"origin":  result.TargetAPK.Origin(),
+			"arch":    result.TargetAPK.Arch,
+		},
+	}
+
+	if f.CGAID != "" {
+		databaseSpecific["advisory_id"] = f.CGAID
+	}
+	if adv := f.Advisory; adv != nil && len(adv.Events) > 0 { //nolint:staticcheck // TODO: use CGAID to lookup the advisory instead.
+		databaseSpecific["advisory_status"] = adv.Latest().Type
+	}
+
+	return models.Vulnerability{
+		SchemaVersion:    osvSchemaVersion,
+		ID:               f.Vulnerability.ID,
+		Modified:         result.DataSource.Date,
+		Aliases:          f.Vulnerability.Aliases,
+		Affected:         []models.Affected{affected},
+		DatabaseSpecific: databaseSpecific,
+	}
+}
+
+// osvRangesForFixedVersion returns the ECOSYSTEM ranges for the given
+// fixedVersion. Grype reports multiple fixed versions (e.g. fixes on different
+// release branches) as a comma-separated list, and since each "fixed" event
+// must close its own "introduced" event, there's a range starting at the
+// beginning of time for each fixed version. If fixedVersion is empty, the one
+// range has no end.
+func osvRangesForFixedVersion(fixedVersion string) []models.Range {
+	var ranges []models.Range
+	for _, v := range strings.Split(fixedVersion, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ranges = append(ranges, models.Range{
+				Type:   models.RangeEcosystem,
+				Events: []models.Event{{Introduced: "0"}, {Fixed: v}},
+			})
+		}
+	}
+
+	if len(ranges) == 0 {
+		ranges = append(ranges, models.Range{
+			Type:   models.RangeEcosystem,
+			Events: []models.Event{{Introduced: "0"}},
+		})
+	}
+
+	return ranges
+}
+
+// osvEcosystem maps the given Syft package type to the corresponding OSV
+// ecosystem. APK packages are mapped to an ecosystem named after the distro.
+func osvEcosystem(packageType, distroID string) models.Ecosystem {
+	switch pkg.Type(packageType) {
+	case pkg.ApkPkg:
+		return models.Ecosystem(distroID)
+	case pkg.GoModulePkg:
+		return models.EcosystemGo
+	case pkg.NpmPkg:
+		return models.EcosystemNPM
+	case pkg.PythonPkg:
+		return models.EcosystemPyPI
+	case pkg.GemPkg:
+		return models.EcosystemRubyGems
+	case pkg.RustPkg:
+		return models.EcosystemCratesIO
+	case pkg.PhpComposerPkg:
+		return models.EcosystemPackagist
+	case pkg.JavaPkg, pkg.JenkinsPluginPkg:
+		return models.EcosystemMaven
+	case pkg.DotnetPkg:
+		return models.EcosystemNuGet
+	}
+
+	return models.Ecosystem(packageType)
+}
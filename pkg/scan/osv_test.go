@@ -0,0 +1,172 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/osv-scanner/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOSVRecords(t *testing.T) {
+	dbDate := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	results := []Result{
+		{
+			TargetAPK: TargetAPK{
+				Name:              "crane",
+				Version:           "0.19.1-r6",
+				OriginPackageName: "crane",
+				Arch:              "aarch64",
+			},
+			Findings: []Finding{
+				{
+					Package: Package{
+						Name:     "golang.org/x/net",
+						Version:  "v0.17.0",
+						Type:     "go-module",
+						Location: "/usr/bin/crane",
+						PURL:     "pkg:golang/golang.org/x/net@v0.17.0",
+					},
+					Vulnerability: Vulnerability{
+						ID:           "GHSA-4374-p667-p6c8",
+						Severity:     "High",
+						Aliases:      []string{"CVE-2023-39325"},
+						FixedVersion: "0.17.0",
+					},
+				},
+				{
+					Package: Package{
+						Name:    "crane",
+						Version: "0.19.1-r6",
+						Type:    "apk",
+						PURL:    "pkg:apk/wolfi/crane@0.19.1-r6?arch=aarch64",
+					},
+					Vulnerability: Vulnerability{
+						ID:       "CVE-2024-0001",
+						Severity: "Low",
+					},
+					CGAID: "CGA-aaaa-bbbb-cccc",
+				},
+			},
+			DataSource: DataSource{Date: dbDate},
+		},
+	}
+
+	targetAPK := map[string]interface{}{
+		"name":    "crane",
+		"version": "0.19.1-r6",
+		"origin":  "crane",
+		"arch":    "aarch64",
+	}
+
+	expected := []models.Vulnerability{
+		{
+			SchemaVersion: osvSchemaVersion,
+			ID:            "GHSA-4374-p667-p6c8",
+			Modified:      dbDate,
+			Aliases:       []string{"CVE-2023-39325"},
+			Affected: []models.Affected{
+				{
+					Package: models.Package{
+						Ecosystem: models.EcosystemGo,
+						Name:      "golang.org/x/net",
+						Purl:      "pkg:golang/golang.org/x/net@v0.17.0",
+					},
+					Ranges: []models.Range{
+						{
+							Type:   models.RangeEcosystem,
+							Events: []models.Event{{Introduced: "0"}, {Fixed: "0.17.0"}},
+						},
+					},
+					Versions:          []string{"v0.17.0"},
+					EcosystemSpecific: map[string]interface{}{"location": "/usr/bin/crane"},
+				},
+			},
+			DatabaseSpecific: map[string]interface{}{
+				"severity":   "High",
+				"target_apk": targetAPK,
+			},
+		},
+		{
+			SchemaVersion: osvSchemaVersion,
+			ID:            "CVE-2024-0001",
+			Modified:      dbDate,
+			Affected: []models.Affected{
+				{
+					Package: models.Package{
+						Ecosystem: "wolfi",
+						Name:      "crane",
+						Purl:      "pkg:apk/wolfi/crane@0.19.1-r6?arch=aarch64",
+					},
+					Ranges: []models.Range{
+						{
+							Type:   models.RangeEcosystem,
+							Events: []models.Event{{Introduced: "0"}},
+						},
+					},
+					Versions: []string{"0.19.1-r6"},
+				},
+			},
+			DatabaseSpecific: map[string]interface{}{
+				"severity":    "Low",
+				"target_apk":  targetAPK,
+				"advisory_id": "CGA-aaaa-bbbb-cccc",
+			},
+		},
+	}
+
+	actual := OSVRecords(results, "wolfi")
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("OSVRecords() mismatch (-want +got):\n%s", diff)
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, EncodeOSV(buf, results, "wolfi"))
+
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded, 2)
+}
+
+func TestOSVRangesForFixedVersion(t *testing.T) {
+	cases := []struct {
+		name         string
+		fixedVersion string
+		expected     []models.Range
+	}{
+		{
+			name:         "not fixed",
+			fixedVersion: "",
+			expected: []models.Range{
+				{Type: models.RangeEcosystem, Events: []models.Event{{Introduced: "0"}}},
+			},
+		},
+		{
+			name:         "one fix",
+			fixedVersion: "1.2.3",
+			expected: []models.Range{
+				{Type: models.RangeEcosystem, Events: []models.Event{{Introduced: "0"}, {Fixed: "1.2.3"}}},
+			},
+		},
+		{
+			name:         "multiple fixes",
+			fixedVersion: "1.2.3, 1.3.1",
+			expected: []models.Range{
+				{Type: models.RangeEcosystem, Events: []models.Event{{Introduced: "0"}, {Fixed: "1.2.3"}}},
+				{Type: models.RangeEcosystem, Events: []models.Event{{Introduced: "0"}, {Fixed: "1.3.1"}}},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, osvRangesForFixedVersion(tt.fixedVersion)); diff != "" {
+				t.Errorf("osvRangesForFixedVersion() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package scan
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ScanFunc produces the scan result for the i-th input in a batch of scans.
+// Implementations are expected to share a single Scanner, so that the
+// vulnerability database is loaded only once for the whole batch.
+type ScanFunc func(ctx context.Context, i int) (*Result, error)
+
+// ResultFunc receives the outcome of the i-th input's scan. If it returns an
+// error, the remaining scans are canceled.
+type ResultFunc func(i int, result *Result, err error) error
+
+// ScanOrdered calls scanFunc for each of the inputs 0 through n-1, using at most
+// jobs concurrent workers. (A jobs value less than 1 is treated as 1.)
+//
+// Scan results are passed to resultFunc strictly in input order, regardless of
+// the order in which the scans finish. This allows callers to print results as
+// soon as they're available without losing deterministic output.
+func ScanOrdered(ctx context.Context, n, jobs int, scanFunc ScanFunc, resultFunc ResultFunc) error {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*Result, n)
+	errs := make([]error, n)
+
+	// done is a slice of pseudo-promises that get closed when results[i] and
+	// errs[i] are ready to be consumed.
+	done := make([]chan struct{}, n)
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	var g errgroup.Group
+	g.SetLimit(jobs)
+
+	// Dispatch work from a separate goroutine, since g.Go blocks once the job
+	// limit has been reached, and we want to start consuming results right away.
+	dispatched := make(chan struct{})
+	go func() {
+		defer close(dispatched)
+
+		for i := 0; i < n; i++ {
+			g.Go(func() error {
+				defer close(done[i])
+
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					return nil
+				}
+
+				results[i], errs[i] = scanFunc(ctx, i)
+				return nil
+			})
+		}
+	}()
+
+	var consumeErr error
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			consumeErr = ctx.Err()
+		case <-done[i]:
+			consumeErr = resultFunc(i, results[i], errs[i])
+		}
+
+		if consumeErr != nil {
+			break
+		}
+
+		// Let the result be garbage collected once it's been handed off.
+		results[i] = nil
+	}
+
+	// Don't return until all workers have stopped, so that callers can safely
+	// release shared resources (like the Scanner) once we return.
+	cancel()
+	<-dispatched
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	return consumeErr
+}
@@ -0,0 +1,104 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanOrdered(t *testing.T) {
+	const n = 20
+
+	t.Run("results are delivered in input order", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+
+		scanFunc := func(_ context.Context, i int) (*Result, error) {
+			cur := running.Add(1)
+			defer running.Add(-1)
+			for {
+				prev := maxRunning.Load()
+				if cur <= prev || maxRunning.CompareAndSwap(prev, cur) {
+					break
+				}
+			}
+
+			// Later inputs finish sooner, to shake out ordering bugs.
+			time.Sleep(time.Duration(n-i) * time.Millisecond)
+
+			return &Result{TargetAPK: TargetAPK{Name: fmt.Sprintf("pkg-%d", i)}}, nil
+		}
+
+		var got []string
+		resultFunc := func(i int, result *Result, err error) error {
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("pkg-%d", i), result.TargetAPK.Name)
+			got = append(got, result.TargetAPK.Name)
+			return nil
+		}
+
+		err := ScanOrdered(context.Background(), n, 4, scanFunc, resultFunc)
+		require.NoError(t, err)
+
+		require.Len(t, got, n)
+		for i := range got {
+			assert.Equal(t, fmt.Sprintf("pkg-%d", i), got[i])
+		}
+		assert.LessOrEqual(t, maxRunning.Load(), int32(4))
+	})
+
+	t.Run("scan errors are passed to the result func", func(t *testing.T) {
+		errBoom := errors.New("boom")
+
+		scanFunc := func(_ context.Context, i int) (*Result, error) {
+			if i == 3 {
+				return nil, errBoom
+			}
+			return &Result{}, nil
+		}
+
+		var failed []int
+		resultFunc := func(i int, _ *Result, err error) error {
+			if err != nil {
+				failed = append(failed, i)
+			}
+			return nil
+		}
+
+		err := ScanOrdered(context.Background(), n, 2, scanFunc, resultFunc)
+		require.NoError(t, err)
+		assert.Equal(t, []int{3}, failed)
+	})
+
+	t.Run("result func error stops the run", func(t *testing.T) {
+		errStop := errors.New("stop")
+
+		scanFunc := func(ctx context.Context, i int) (*Result, error) {
+			if i < 2 {
+				return &Result{}, nil
+			}
+
+			// Remaining scans only finish once the run is canceled.
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		var consumed []int
+		resultFunc := func(i int, _ *Result, _ error) error {
+			consumed = append(consumed, i)
+			if i == 1 {
+				return errStop
+			}
+			return nil
+		}
+
+		err := ScanOrdered(context.Background(), n, 3, scanFunc, resultFunc)
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, []int{0, 1}, consumed)
+	})
+}
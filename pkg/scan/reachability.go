@@ -0,0 +1,328 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/anchore/syft/syft/pkg"
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/tar"
+)
+
+const (
+	// ReachabilityReachable means the binary contains at least one of the
+	// vulnerable symbols.
+	ReachabilityReachable = "reachable"
+
+	// ReachabilityImportedNotReachable means the binary imports the vulnerable
+	// package, but none of its vulnerable symbols are reachable.
+	ReachabilityImportedNotReachable = "imported-not-reachable"
+
+	// ReachabilityNotImported means the binary includes the vulnerable module, but
+	// not the vulnerable package.
+	ReachabilityNotImported = "not-imported"
+)
+
+const (
+	// ReachabilityActionAnnotate only records the reachability of findings.
+	ReachabilityActionAnnotate = "annotate"
+
+	// ReachabilityActionDemote lowers the severity of unreachable findings to
+	// "Negligible".
+	ReachabilityActionDemote = "demote"
+
+	// ReachabilityActionFilter removes unreachable findings.
+	ReachabilityActionFilter = "filter"
+)
+
+// ValidReachabilityActions are the ways unreachable findings can be handled
+// (see ApplyReachabilityAction).
+var ValidReachabilityActions = []string{ReachabilityActionAnnotate, ReachabilityActionDemote, ReachabilityActionFilter}
+
+// Reachability is govulncheck's assessment of whether a finding's vulnerable code
+// can be reached from the Go binary it was found in.
+type Reachability struct {
+	// Status is one of ReachabilityReachable, ReachabilityImportedNotReachable, or
+	// ReachabilityNotImported.
+	Status string
+
+	// Symbols lists the vulnerable symbols found in the binary, when the
+	// vulnerability is reachable.
+	Symbols []string `json:",omitempty"`
+
+	// DemotedFrom is the finding's original severity, if it was demoted because the
+	// vulnerability isn't reachable.
+	DemotedFrom string `json:",omitempty"`
+}
+
+// Reachable returns true unless govulncheck determined that the vulnerable code
+// can't be reached.
+func (r *Reachability) Reachable() bool {
+	return r == nil || r.Status == ReachabilityReachable
+}
+
+// govulncheckMessage is a single message of govulncheck's JSON output. Only the
+// fields we use are decoded.
+type govulncheckMessage struct {
+	OSV *struct {
+		ID      string   `json:"id"`
+		Aliases []string `json:"aliases"`
+	} `json:"osv"`
+
+	Finding *struct {
+		OSV   string `json:"osv"`
+		Trace []struct {
+			Module   string `json:"module"`
+			Package  string `json:"package"`
+			Function string `json:"function"`
+			Receiver string `json:"receiver"`
+		} `json:"trace"`
+	} `json:"finding"`
+}
+
+// govulncheckResult is what govulncheck found in a single binary.
+type govulncheckResult struct {
+	// aliases maps each Go vulnerability ID to its aliases (e.g. CVE IDs).
+	aliases map[string][]string
+
+	// reachability maps each vulnerable module (e.g. "github.com/foo/bar" or
+	// "stdlib") to the reachability of each of its Go vulnerability IDs.
+	reachability map[string]map[string]*Reachability
+}
+
+// parseGovulncheckOutput parses the output of "govulncheck -format json".
+func parseGovulncheckOutput(r io.Reader) (*govulncheckResult, error) {
+	result := &govulncheckResult{
+		aliases:      make(map[string][]string),
+		reachability: make(map[string]map[string]*Reachability),
+	}
+
+	dec := json.NewDecoder(r)
+	for {
+		var msg govulncheckMessage
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decoding govulncheck output: %w", err)
+		}
+
+		if msg.OSV != nil {
+			result.aliases[msg.OSV.ID] = msg.OSV.Aliases
+		}
+
+		if msg.Finding == nil || len(msg.Finding.Trace) == 0 {
+			continue
+		}
+
+		// The first frame of the trace is the vulnerable code itself, at the most
+		// specific level that was found.
+		frame := msg.Finding.Trace[0]
+
+		status := ReachabilityNotImported
+		switch {
+		case frame.Function != "":
+			status = ReachabilityReachable
+		case frame.Package != "":
+			status = ReachabilityImportedNotReachable
+		}
+
+		byID := result.reachability[frame.Module]
+		if byID == nil {
+			byID = make(map[string]*Reachability)
+			result.reachability[frame.Module] = byID
+		}
+
+		current := byID[msg.Finding.OSV]
+		if current == nil {
+			current = &Reachability{Status: status}
+			byID[msg.Finding.OSV] = current
+		} else if reachabilityRank(status) > reachabilityRank(current.Status) {
+			current.Status = status
+		}
+
+		if status == ReachabilityReachable {
+			symbol := frame.Function
+			if frame.Receiver != "" {
+				symbol = frame.Receiver + "." + symbol
+			}
+			symbol = frame.Package + "." + symbol
+
+			if !slices.Contains(current.Symbols, symbol) {
+				current.Symbols = append(current.Symbols, symbol)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func reachabilityRank(status string) int {
+	switch status {
+	case ReachabilityReachable:
+		return 2
+	case ReachabilityImportedNotReachable:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// lookUp returns govulncheck's assessment of the given finding, or nil if
+// govulncheck didn't report the finding's vulnerability for its module.
+func (r *govulncheckResult) lookUp(f *Finding) *Reachability {
+	ids := append([]string{f.Vulnerability.ID}, f.Vulnerability.Aliases...)
+
+	for goID, reachability := range r.reachability[f.Package.Name] {
+		if slices.Contains(ids, goID) {
+			return reachability
+		}
+
+		for _, alias := range r.aliases[goID] {
+			if slices.Contains(ids, alias) {
+				return reachability
+			}
+		}
+	}
+
+	return nil
+}
+
+// AnalyzeReachability uses govulncheck to assess whether the vulnerable code of
+// each Go module finding can be reached from the binary in the APK at apkPath
+// that the finding was reported against, and sets the findings' Reachability
+// accordingly. Findings that govulncheck has no assessment for are left
+// unchanged.
+//
+// The govulncheck command must be installed, and it needs network access to
+// the Go vulnerability database.
+func AnalyzeReachability(ctx context.Context, apkPath string, findings []Finding) error {
+	logger := clog.FromContext(ctx)
+
+	var binaries []string
+	for i := range findings {
+		f := &findings[i]
+		if f.Package.Type != string(pkg.GoModulePkg) || f.Package.Location == "" {
+			continue
+		}
+
+		for _, location := range strings.Split(f.Package.Location, ", ") {
+			if !slices.Contains(binaries, location) {
+				binaries = append(binaries, location)
+			}
+		}
+	}
+
+	if len(binaries) == 0 {
+		return nil
+	}
+
+	govulncheck, err := exec.LookPath("govulncheck")
+	if err != nil {
+		return fmt.Errorf("finding govulncheck: %w", err)
+	}
+
+	tempDir, err := os.MkdirTemp("", "wolfictl-reachability-*")
+	if err != nil {
+		return fmt.Errorf("creating temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	apk, err := os.Open(apkPath)
+	if err != nil {
+		return fmt.Errorf("opening APK: %w", err)
+	}
+	defer apk.Close()
+
+	if err := tar.Untar(apk, tempDir); err != nil {
+		return fmt.Errorf("unpacking APK: %w", err)
+	}
+
+	results := make(map[string]*govulncheckResult, len(binaries))
+	for _, binary := range binaries {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, govulncheck, "-mode=binary", "-format=json", filepath.Join(tempDir, binary))
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("running govulncheck on %s: %w: %s", binary, err, strings.TrimSpace(stderr.String()))
+		}
+
+		result, err := parseGovulncheckOutput(&stdout)
+		if err != nil {
+			return fmt.Errorf("analyzing %s: %w", binary, err)
+		}
+		results[binary] = result
+
+		logger.Debug("analyzed reachability of vulnerabilities in Go binary", "binary", binary, "modules", len(result.reachability))
+	}
+
+	for i := range findings {
+		f := &findings[i]
+		if f.Package.Type != string(pkg.GoModulePkg) {
+			continue
+		}
+
+		// If the module is in more than one binary, it's as reachable as it is in the
+		// binary where it's most reachable.
+		for _, location := range strings.Split(f.Package.Location, ", ") {
+			result := results[location]
+			if result == nil {
+				continue
+			}
+
+			reachability := result.lookUp(f)
+			if reachability == nil {
+				continue
+			}
+
+			if f.Reachability == nil || reachabilityRank(reachability.Status) > reachabilityRank(f.Reachability.Status) {
+				r := *reachability
+				f.Reachability = &r
+			}
+		}
+	}
+
+	return nil
+}
+
+// ApplyReachabilityAction handles the findings that are known to be unreachable
+// according to the given action (see ValidReachabilityActions), and returns the
+// resulting findings.
+func ApplyReachabilityAction(findings []Finding, action string) []Finding {
+	switch action {
+	case ReachabilityActionDemote:
+		for i := range findings {
+			f := &findings[i]
+			if f.Reachability.Reachable() || f.Reachability.DemotedFrom != "" {
+				continue
+			}
+
+			f.Reachability.DemotedFrom = f.Vulnerability.Severity
+			f.Vulnerability.Severity = "Negligible"
+		}
+		return findings
+
+	case ReachabilityActionFilter:
+		var result []Finding
+		for i := range findings {
+			if findings[i].Reachability.Reachable() {
+				result = append(result, findings[i])
+			}
+		}
+		return result
+
+	default:
+		return findings
+	}
+}
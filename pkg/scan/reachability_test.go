@@ -0,0 +1,144 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// govulncheckOutput is abridged output of "govulncheck -mode=binary -format
+// json", which prints a stream of (indented) JSON messages.
+const govulncheckOutput = `{
+  "config": {
+    "protocol_version": "v1.0.0",
+    "scanner_name": "govulncheck",
+    "scan_level": "symbol",
+    "scan_mode": "binary"
+  }
+}
+{
+  "osv": {
+    "id": "GO-2024-0001",
+    "aliases": ["CVE-2024-1111", "GHSA-aaaa-bbbb-cccc"]
+  }
+}
+{
+  "osv": {
+    "id": "GO-2024-0002",
+    "aliases": ["CVE-2024-2222"]
+  }
+}
+{
+  "osv": {
+    "id": "GO-2024-0003",
+    "aliases": ["CVE-2024-3333"]
+  }
+}
+{
+  "finding": {
+    "osv": "GO-2024-0001",
+    "fixed_version": "v1.2.3",
+    "trace": [{"module": "github.com/foo/bar", "version": "v1.2.0"}]
+  }
+}
+{
+  "finding": {
+    "osv": "GO-2024-0001",
+    "fixed_version": "v1.2.3",
+    "trace": [{"module": "github.com/foo/bar", "version": "v1.2.0", "package": "github.com/foo/bar/baz"}]
+  }
+}
+{
+  "finding": {
+    "osv": "GO-2024-0001",
+    "fixed_version": "v1.2.3",
+    "trace": [{"module": "github.com/foo/bar", "version": "v1.2.0", "package": "github.com/foo/bar/baz", "function": "Parse", "receiver": "*Parser"}]
+  }
+}
+{
+  "finding": {
+    "osv": "GO-2024-0002",
+    "fixed_version": "v1.2.3",
+    "trace": [{"module": "github.com/foo/bar", "version": "v1.2.0", "package": "github.com/foo/bar/qux"}]
+  }
+}
+{
+  "finding": {
+    "osv": "GO-2024-0003",
+    "fixed_version": "v1.22.5",
+    "trace": [{"module": "stdlib", "version": "v1.22.1"}]
+  }
+}
+`
+
+func TestParseGovulncheckOutput(t *testing.T) {
+	result, err := parseGovulncheckOutput(strings.NewReader(govulncheckOutput))
+	require.NoError(t, err)
+
+	finding := func(pkgName, vulnID string, aliases ...string) *Finding {
+		return &Finding{
+			Package:       Package{Name: pkgName, Type: "go-module"},
+			Vulnerability: Vulnerability{ID: vulnID, Aliases: aliases},
+		}
+	}
+
+	r := result.lookUp(finding("github.com/foo/bar", "GHSA-aaaa-bbbb-cccc", "CVE-2024-1111"))
+	require.NotNil(t, r)
+	assert.Equal(t, ReachabilityReachable, r.Status)
+	assert.Equal(t, []string{"github.com/foo/bar/baz.*Parser.Parse"}, r.Symbols)
+
+	r = result.lookUp(finding("github.com/foo/bar", "CVE-2024-2222"))
+	require.NotNil(t, r)
+	assert.Equal(t, ReachabilityImportedNotReachable, r.Status)
+	assert.Empty(t, r.Symbols)
+
+	r = result.lookUp(finding("stdlib", "GO-2024-0003"))
+	require.NotNil(t, r)
+	assert.Equal(t, ReachabilityNotImported, r.Status)
+
+	// The vulnerability is known, but not for this module.
+	assert.Nil(t, result.lookUp(finding("github.com/other/module", "CVE-2024-1111")))
+
+	// govulncheck doesn't know about the vulnerability.
+	assert.Nil(t, result.lookUp(finding("github.com/foo/bar", "CVE-2024-9999")))
+}
+
+func TestApplyReachabilityAction(t *testing.T) {
+	findings := func() []Finding {
+		return []Finding{
+			{Vulnerability: Vulnerability{ID: "CVE-2024-0001", Severity: "High"}},
+			{Vulnerability: Vulnerability{ID: "CVE-2024-0002", Severity: "High"}, Reachability: &Reachability{Status: ReachabilityReachable}},
+			{Vulnerability: Vulnerability{ID: "CVE-2024-0003", Severity: "High"}, Reachability: &Reachability{Status: ReachabilityImportedNotReachable}},
+			{Vulnerability: Vulnerability{ID: "CVE-2024-0004", Severity: "Critical"}, Reachability: &Reachability{Status: ReachabilityNotImported}},
+		}
+	}
+
+	t.Run("annotate", func(t *testing.T) {
+		assert.Equal(t, findings(), ApplyReachabilityAction(findings(), ReachabilityActionAnnotate))
+	})
+
+	t.Run("demote", func(t *testing.T) {
+		got := ApplyReachabilityAction(findings(), ReachabilityActionDemote)
+		require.Len(t, got, 4)
+
+		assert.Equal(t, "High", got[0].Vulnerability.Severity)
+		assert.Equal(t, "High", got[1].Vulnerability.Severity)
+		assert.Equal(t, "Negligible", got[2].Vulnerability.Severity)
+		assert.Equal(t, "High", got[2].Reachability.DemotedFrom)
+		assert.Equal(t, "Negligible", got[3].Vulnerability.Severity)
+		assert.Equal(t, "Critical", got[3].Reachability.DemotedFrom)
+
+		// Demoting again doesn't lose the original severity.
+		got = ApplyReachabilityAction(got, ReachabilityActionDemote)
+		assert.Equal(t, "Critical", got[3].Reachability.DemotedFrom)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		got := ApplyReachabilityAction(findings(), ReachabilityActionFilter)
+		require.Len(t, got, 2)
+		assert.Equal(t, "CVE-2024-0001", got[0].Vulnerability.ID)
+		assert.Equal(t, "CVE-2024-0002", got[1].Vulnerability.ID)
+	})
+}
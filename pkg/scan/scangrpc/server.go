@@ -0,0 +1,245 @@
+// Package scangrpc exposes the scanner (see package scan) as a gRPC service, as
+// defined in scangrpc/v1/scan.proto.
+package scangrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+	scanv1 "github.com/wolfi-dev/wolfictl/pkg/scan/scangrpc/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Scanner is the scanning functionality exposed by the gRPC service. It's
+// implemented by scan.Scanner.
+type Scanner interface {
+	ScanAPK(ctx context.Context, apk fs.File, distroID string) (*scan.Result, error)
+	ScanImage(ctx context.Context, ref string) (*scan.ImageResult, error)
+	DataSource() scan.DataSource
+}
+
+// Options configures a Server.
+type Options struct {
+	// DistroID is the distro used during vulnerability matching when a request
+	// doesn't specify one.
+	DistroID string
+
+	// MaxAPKSize is the largest APK (in bytes) that the server will accept,
+	// whether it's uploaded or downloaded from a URL. Note that uploads are also
+	// limited by the gRPC server's maximum receive message size.
+	MaxAPKSize int64
+
+	// HTTPClient is used to download APKs that are specified by URL. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// AllowedURLPrefixes limits the URLs that APKs can be downloaded from (see
+	// scan.URLAllowed). If empty, APKs can't be specified by URL.
+	AllowedURLPrefixes []string
+}
+
+// DefaultOptions is the recommended configuration for a new Server.
+var DefaultOptions = Options{
+	DistroID:   "wolfi",
+	MaxAPKSize: 1 << 30, // 1 GiB
+}
+
+// Server implements the scanv1.ScannerServer interface. Register it with a
+// gRPC server using scanv1.RegisterScannerServer.
+type Server struct {
+	scanv1.UnimplementedScannerServer
+
+	scanner Scanner
+	opts    Options
+}
+
+// NewServer returns a new Server that uses the given scanner.
+func NewServer(scanner Scanner, opts Options) *Server {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	opts.HTTPClient = scan.AllowedURLsClient(opts.HTTPClient, opts.AllowedURLPrefixes)
+
+	return &Server{
+		scanner: scanner,
+		opts:    opts,
+	}
+}
+
+func (s *Server) ScanAPK(ctx context.Context, req *scanv1.ScanAPKRequest) (*scanv1.ScanAPKResponse, error) {
+	distroID := req.GetDistro()
+	if distroID == "" {
+		distroID = s.opts.DistroID
+	}
+
+	var (
+		f   *os.File
+		err error
+	)
+
+	switch src := req.GetSource().(type) {
+	case *scanv1.ScanAPKRequest_Apk:
+		f, err = storeAPK(bytes.NewReader(src.Apk), s.opts.MaxAPKSize)
+	case *scanv1.ScanAPKRequest_Url:
+		f, err = s.downloadAPK(ctx, src.Url)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "either apk or url must be specified")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	clog.FromContext(ctx).Info("scanning APK", "file", f.Name(), "distro", distroID)
+
+	result, err := s.scanner.ScanAPK(ctx, f, distroID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "scanning APK: %v", err)
+	}
+
+	return &scanv1.ScanAPKResponse{Result: resultToProto(result)}, nil
+}
+
+func (s *Server) ScanImage(ctx context.Context, req *scanv1.ScanImageRequest) (*scanv1.ScanImageResponse, error) {
+	if req.GetImage() == "" {
+		return nil, status.Error(codes.InvalidArgument, "image must be specified")
+	}
+
+	result, err := s.scanner.ScanImage(ctx, req.GetImage())
+	if err != nil {
+		return nil, status.Errorf(codes.Unknown, "scanning image: %v", err)
+	}
+
+	return &scanv1.ScanImageResponse{Result: imageResultToProto(result)}, nil
+}
+
+func (s *Server) GetDBStatus(_ context.Context, _ *scanv1.GetDBStatusRequest) (*scanv1.GetDBStatusResponse, error) {
+	ds := s.scanner.DataSource()
+	return &scanv1.GetDBStatusResponse{DataSource: dataSourceToProto(&ds)}, nil
+}
+
+func (s *Server) downloadAPK(ctx context.Context, rawURL string) (*os.File, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid APK URL %q", rawURL)
+	}
+	if !scan.URLAllowed(u, s.opts.AllowedURLPrefixes) {
+		return nil, status.Errorf(codes.PermissionDenied, "downloading APKs from %q isn't allowed", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid APK URL %q: %v", rawURL, err)
+	}
+
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "downloading APK: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, status.Errorf(codes.FailedPrecondition, "downloading APK: unexpected status code %d", resp.StatusCode)
+	}
+
+	return storeAPK(resp.Body, s.opts.MaxAPKSize)
+}
+
+// storeAPK writes the APK read from r to a temporary file, and returns the file,
+// positioned at its start.
+func storeAPK(r io.Reader, maxSize int64) (*os.File, error) {
+	f, err := os.CreateTemp("", "wolfictl-scan-grpc-*.apk")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "creating temp file: %v", err)
+	}
+
+	n, err := io.Copy(f, io.LimitReader(r, maxSize+1))
+	if err == nil && n > maxSize {
+		err = status.Errorf(codes.ResourceExhausted, "APK exceeds maximum size of %d bytes", maxSize)
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "receiving APK: %v", err)
+	}
+
+	return f, nil
+}
+
+func resultToProto(r *scan.Result) *scanv1.Result {
+	return &scanv1.Result{
+		TargetApk: &scanv1.TargetAPK{
+			Name:              r.TargetAPK.Name,
+			Version:           r.TargetAPK.Version,
+			OriginPackageName: r.TargetAPK.OriginPackageName,
+			Arch:              r.TargetAPK.Arch,
+		},
+		Findings:   findingsToProto(r.Findings),
+		DataSource: dataSourceToProto(&r.DataSource),
+	}
+}
+
+func imageResultToProto(r *scan.ImageResult) *scanv1.ImageResult {
+	return &scanv1.ImageResult{
+		Image:      r.Image,
+		Distro:     r.Distro,
+		Findings:   findingsToProto(r.Findings),
+		DataSource: dataSourceToProto(&r.DataSource),
+	}
+}
+
+func findingsToProto(findings []scan.Finding) []*scanv1.Finding {
+	result := make([]*scanv1.Finding, 0, len(findings))
+	for i := range findings {
+		f := &findings[i]
+		result = append(result, &scanv1.Finding{
+			Package: &scanv1.Package{
+				Id:       f.Package.ID,
+				Name:     f.Package.Name,
+				Version:  f.Package.Version,
+				Type:     f.Package.Type,
+				Location: f.Package.Location,
+				Purl:     f.Package.PURL,
+			},
+			Vulnerability: &scanv1.Vulnerability{
+				Id:           f.Vulnerability.ID,
+				Severity:     f.Vulnerability.Severity,
+				Aliases:      f.Vulnerability.Aliases,
+				FixedVersion: f.Vulnerability.FixedVersion,
+			},
+			CgaId: f.CGAID,
+		})
+	}
+
+	return result
+}
+
+func dataSourceToProto(ds *scan.DataSource) *scanv1.DataSource {
+	result := &scanv1.DataSource{
+		Kind:      ds.Kind,
+		Schema:    ds.Schema,
+		Integrity: ds.Integrity,
+	}
+	if !ds.Date.IsZero() {
+		result.Date = timestamppb.New(ds.Date)
+	}
+
+	return result
+}
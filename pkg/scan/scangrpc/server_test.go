@@ -0,0 +1,149 @@
+package scangrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfi-dev/wolfictl/pkg/scan"
+	scanv1 "github.com/wolfi-dev/wolfictl/pkg/scan/scangrpc/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeScanner "scans" an APK by reporting its contents as the package name.
+type fakeScanner struct{}
+
+func (fakeScanner) ScanAPK(_ context.Context, apk fs.File, distroID string) (*scan.Result, error) {
+	data, err := io.ReadAll(apk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &scan.Result{
+		TargetAPK: scan.TargetAPK{Name: string(data), Arch: distroID},
+		Findings: []scan.Finding{
+			{
+				Package:       scan.Package{Name: "crane", Version: "0.19.1-r6", Type: "apk"},
+				Vulnerability: scan.Vulnerability{ID: "CVE-2024-1234", Severity: "High", Aliases: []string{"GHSA-xxxx-xxxx-xxxx"}},
+			},
+		},
+	}, nil
+}
+
+func (fakeScanner) ScanImage(_ context.Context, ref string) (*scan.ImageResult, error) {
+	if ref == "missing" {
+		return nil, errors.New("image not found")
+	}
+	return &scan.ImageResult{Image: ref, Distro: "wolfi"}, nil
+}
+
+func (fakeScanner) DataSource() scan.DataSource {
+	return scan.DataSource{Kind: "fake", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestServer(t *testing.T) {
+	apkSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/crane.apk" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte("from-url"))
+		assert.NoError(t, err)
+	}))
+	defer apkSrv.Close()
+
+	lis := bufconn.Listen(1 << 20)
+	grpcSrv := grpc.NewServer()
+	opts := DefaultOptions
+	opts.MaxAPKSize = 16
+	opts.AllowedURLPrefixes = []string{apkSrv.URL}
+	scanv1.RegisterScannerServer(grpcSrv, NewServer(fakeScanner{}, opts))
+	go func() {
+		assert.NoError(t, grpcSrv.Serve(lis))
+	}()
+	defer grpcSrv.Stop()
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := scanv1.NewScannerClient(conn)
+	ctx := context.Background()
+
+	t.Run("upload", func(t *testing.T) {
+		resp, err := client.ScanAPK(ctx, &scanv1.ScanAPKRequest{Source: &scanv1.ScanAPKRequest_Apk{Apk: []byte("raw")}})
+		require.NoError(t, err)
+		assert.Equal(t, "raw", resp.GetResult().GetTargetApk().GetName())
+		assert.Equal(t, "wolfi", resp.GetResult().GetTargetApk().GetArch())
+		require.Len(t, resp.GetResult().GetFindings(), 1)
+		assert.Equal(t, "CVE-2024-1234", resp.GetResult().GetFindings()[0].GetVulnerability().GetId())
+		assert.Equal(t, []string{"GHSA-xxxx-xxxx-xxxx"}, resp.GetResult().GetFindings()[0].GetVulnerability().GetAliases())
+	})
+
+	t.Run("distro override", func(t *testing.T) {
+		resp, err := client.ScanAPK(ctx, &scanv1.ScanAPKRequest{Source: &scanv1.ScanAPKRequest_Apk{Apk: []byte("raw")}, Distro: "chainguard"})
+		require.NoError(t, err)
+		assert.Equal(t, "chainguard", resp.GetResult().GetTargetApk().GetArch())
+	})
+
+	t.Run("URL", func(t *testing.T) {
+		resp, err := client.ScanAPK(ctx, &scanv1.ScanAPKRequest{Source: &scanv1.ScanAPKRequest_Url{Url: apkSrv.URL + "/crane.apk"}})
+		require.NoError(t, err)
+		assert.Equal(t, "from-url", resp.GetResult().GetTargetApk().GetName())
+	})
+
+	errorCases := []struct {
+		name string
+		req  *scanv1.ScanAPKRequest
+		code codes.Code
+	}{
+		{name: "no source", req: &scanv1.ScanAPKRequest{}, code: codes.InvalidArgument},
+		{name: "invalid URL", req: &scanv1.ScanAPKRequest{Source: &scanv1.ScanAPKRequest_Url{Url: "file:///etc/passwd"}}, code: codes.InvalidArgument},
+		{name: "URL not found", req: &scanv1.ScanAPKRequest{Source: &scanv1.ScanAPKRequest_Url{Url: apkSrv.URL + "/missing.apk"}}, code: codes.FailedPrecondition},
+		{name: "URL not allowed", req: &scanv1.ScanAPKRequest{Source: &scanv1.ScanAPKRequest_Url{Url: "https://example.com/crane.apk"}}, code: codes.PermissionDenied},
+		{name: "too large", req: &scanv1.ScanAPKRequest{Source: &scanv1.ScanAPKRequest_Apk{Apk: []byte(strings.Repeat("x", 17))}}, code: codes.ResourceExhausted},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ScanAPK(ctx, tt.req)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+
+	t.Run("image", func(t *testing.T) {
+		resp, err := client.ScanImage(ctx, &scanv1.ScanImageRequest{Image: "cgr.dev/chainguard/crane"})
+		require.NoError(t, err)
+		assert.Equal(t, "cgr.dev/chainguard/crane", resp.GetResult().GetImage())
+		assert.Equal(t, "wolfi", resp.GetResult().GetDistro())
+
+		_, err = client.ScanImage(ctx, &scanv1.ScanImageRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.ScanImage(ctx, &scanv1.ScanImageRequest{Image: "missing"})
+		assert.Equal(t, codes.Unknown, status.Code(err))
+	})
+
+	t.Run("DB status", func(t *testing.T) {
+		resp, err := client.GetDBStatus(ctx, &scanv1.GetDBStatusRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "fake", resp.GetDataSource().GetKind())
+		assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), resp.GetDataSource().GetDate().AsTime())
+	})
+}
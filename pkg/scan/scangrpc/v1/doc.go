@@ -0,0 +1,5 @@
+// Package scanv1 contains the generated types and gRPC service definitions for
+// version 1 of the wolfictl scanning API.
+package scanv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative scan.proto
@@ -0,0 +1,121 @@
+package scan
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// ResultSchemaVersion is the version of the JSON encoding of Result, which is
+// emitted as each result's "schemaVersion" field. It's incremented whenever the
+// encoding changes in a way that could break consumers, and a migration from the
+// previous version is added to resultMigrations.
+const ResultSchemaVersion = "1"
+
+// legacyResultSchemaVersion is the implied schema version of results that were
+// encoded before results were versioned.
+const legacyResultSchemaVersion = "0"
+
+// resultSchemaVersions lists every schema version of Result, oldest first.
+var resultSchemaVersions = []string{legacyResultSchemaVersion, ResultSchemaVersion}
+
+// resultMigrations upgrade a decoded result (in place) from the schema version
+// of the key to the next version in resultSchemaVersions.
+var resultMigrations = map[string]func(result map[string]any) error{
+	// Versioning was introduced without otherwise changing the encoding.
+	legacyResultSchemaVersion: func(map[string]any) error { return nil },
+}
+
+//go:embed result.schema.json
+var resultJSONSchema []byte
+
+// ResultJSONSchema returns the JSON Schema that describes the JSON encoding of
+// a list of scan results, for the current ResultSchemaVersion.
+func ResultJSONSchema() []byte {
+	return slices.Clone(resultJSONSchema)
+}
+
+// DecodeResults reads JSON-encoded scan results from r, which can be a JSON
+// array of results (as printed by "wolfictl scan -o json"), or one or more
+// results (as printed by "wolfictl scan -o ndjson"). Results encoded with an
+// older schema version are migrated to the current ResultSchemaVersion.
+func DecodeResults(r io.Reader) ([]Result, error) {
+	var raws []map[string]any
+
+	dec := json.NewDecoder(r)
+	for {
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decoding scan results: %w", err)
+		}
+
+		if trimmed := bytes.TrimSpace(v); len(trimmed) > 0 && trimmed[0] == '[' {
+			var list []map[string]any
+			if err := json.Unmarshal(v, &list); err != nil {
+				return nil, fmt.Errorf("decoding scan results: %w", err)
+			}
+			raws = append(raws, list...)
+			continue
+		}
+
+		var raw map[string]any
+		if err := json.Unmarshal(v, &raw); err != nil {
+			return nil, fmt.Errorf("decoding scan result: %w", err)
+		}
+		raws = append(raws, raw)
+	}
+
+	results := make([]Result, 0, len(raws))
+	for i, raw := range raws {
+		if err := migrateResult(raw); err != nil {
+			return nil, fmt.Errorf("migrating scan result %d: %w", i+1, err)
+		}
+
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		var result Result
+		if err := json.Unmarshal(b, &result); err != nil {
+			return nil, fmt.Errorf("decoding scan result %d: %w", i+1, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// migrateResult upgrades the given decoded result to the current
+// ResultSchemaVersion.
+func migrateResult(raw map[string]any) error {
+	version := legacyResultSchemaVersion
+	if v, ok := raw["schemaVersion"]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("invalid schema version %v", v)
+		}
+		version = s
+	}
+
+	i := slices.Index(resultSchemaVersions, version)
+	if i == -1 {
+		return fmt.Errorf("unsupported schema version %q (the latest supported version is %q)", version, ResultSchemaVersion)
+	}
+
+	for ; i < len(resultSchemaVersions)-1; i++ {
+		if err := resultMigrations[resultSchemaVersions[i]](raw); err != nil {
+			return fmt.Errorf("migrating from schema version %q: %w", resultSchemaVersions[i], err)
+		}
+	}
+
+	raw["schemaVersion"] = ResultSchemaVersion
+	return nil
+}
@@ -0,0 +1,140 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultJSONSchema(t *testing.T) {
+	schema, err := jsonschema.CompileString("result.schema.json", string(ResultJSONSchema()))
+	require.NoError(t, err)
+
+	results := []Result{
+		{
+			SchemaVersion: ResultSchemaVersion,
+			TargetAPK:     TargetAPK{Name: "crane", Version: "0.19.1-r6", OriginPackageName: "crane", Arch: "x86_64"},
+			Findings: []Finding{
+				{
+					Package:          Package{ID: "1", Name: "stdlib", Version: "go1.22.1", Type: "go-module", Location: "/usr/bin/crane", PURL: "pkg:golang/stdlib@1.22.1"},
+					Vulnerability:    Vulnerability{ID: "CVE-2024-1234", Severity: "High", Aliases: []string{"GO-2024-0001"}, FixedVersion: "1.22.5", MatchType: "exact-direct-match"},
+					CGAID:            "CGA-xxxx-yyyy-zzzz",
+					AdvisoryStatus:   "fixed",
+					KEV:              &KEVEntry{CVEID: "CVE-2024-1234", DateAdded: "2024-06-01"},
+					Arches:           []string{"x86_64"},
+					TriageSuggestion: &TriageSuggestion{EventType: "fixed", Reason: "fix available upstream in 1.22.5"},
+					Sources:          []string{SourceGrype, SourceOSV},
+					Reachability:     &Reachability{Status: ReachabilityReachable, Symbols: []string{"net/http.Serve"}},
+				},
+			},
+			DataSource: DataSource{Kind: "grype-db", Schema: "v6", Integrity: "sha256:abc", Date: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+			Suppressed: []SuppressedFinding{
+				{
+					Finding: Finding{Vulnerability: Vulnerability{ID: "CVE-2024-5678"}},
+					Rule:    IgnoreRule{Vulnerability: "CVE-2024-5678", Justification: "not applicable"},
+				},
+			},
+			BaselineDataSource: &DataSource{Kind: "grype-db"},
+		},
+		{
+			SchemaVersion: ResultSchemaVersion,
+			TargetAPK:     TargetAPK{Name: "empty", Version: "1.0.0-r0"},
+		},
+	}
+
+	b, err := json.Marshal(results)
+	require.NoError(t, err)
+
+	var v any
+	require.NoError(t, json.Unmarshal(b, &v))
+	assert.NoError(t, schema.Validate(v))
+
+	t.Run("missing schema version", func(t *testing.T) {
+		b, err := json.Marshal([]Result{{}})
+		require.NoError(t, err)
+
+		var v any
+		require.NoError(t, json.Unmarshal(b, &v))
+		assert.Error(t, schema.Validate(v))
+	})
+}
+
+// TestResultJSONSchemaCoversFields makes sure that the schema is updated along
+// with the types it describes.
+func TestResultJSONSchemaCoversFields(t *testing.T) {
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(ResultJSONSchema(), &schema))
+
+	for _, v := range []any{
+		Result{},
+		TargetAPK{},
+		DataSource{},
+		Finding{},
+		Package{},
+		Vulnerability{},
+		KEVEntry{},
+		TriageSuggestion{},
+		Reachability{},
+		TriageAssessment{},
+		SuppressedFinding{},
+		IgnoreRule{},
+	} {
+		typ := reflect.TypeOf(v)
+
+		def, ok := schema.Defs[typ.Name()]
+		if !assert.True(t, ok, "schema is missing a definition for %s", typ.Name()) {
+			continue
+		}
+
+		for i := 0; i < typ.NumField(); i++ {
+			name := typ.Field(i).Name
+			if tag, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ","); tag != "" {
+				name = tag
+			}
+
+			assert.Contains(t, def.Properties, name, "schema definition for %s is missing the %q property", typ.Name(), name)
+		}
+	}
+}
+
+func TestDecodeResults(t *testing.T) {
+	t.Run("legacy JSON array", func(t *testing.T) {
+		input := `[{"TargetAPK": {"Name": "crane", "Version": "0.19.1-r6"}, "Findings": [{"Vulnerability": {"ID": "CVE-2024-1234"}}]}]`
+
+		results, err := DecodeResults(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		assert.Equal(t, ResultSchemaVersion, results[0].SchemaVersion)
+		assert.Equal(t, "crane", results[0].TargetAPK.Name)
+		assert.Equal(t, "CVE-2024-1234", results[0].Findings[0].Vulnerability.ID)
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		enc := json.NewEncoder(buf)
+		require.NoError(t, enc.Encode(Result{SchemaVersion: ResultSchemaVersion, TargetAPK: TargetAPK{Name: "a"}}))
+		require.NoError(t, enc.Encode(Result{SchemaVersion: ResultSchemaVersion, TargetAPK: TargetAPK{Name: "b"}}))
+
+		results, err := DecodeResults(buf)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "b", results[1].TargetAPK.Name)
+	})
+
+	t.Run("unsupported schema version", func(t *testing.T) {
+		_, err := DecodeResults(strings.NewReader(`{"schemaVersion": "99"}`))
+		assert.ErrorContains(t, err, `unsupported schema version "99"`)
+	})
+}
@@ -0,0 +1,352 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+)
+
+// APKScanner scans APK files for vulnerabilities. It's implemented by Scanner.
+type APKScanner interface {
+	ScanAPK(ctx context.Context, apk fs.File, distroID string) (*Result, error)
+	DataSource() DataSource
+}
+
+// ServerOptions configures a Server.
+type ServerOptions struct {
+	// DistroID is the distro used during vulnerability matching when a request
+	// doesn't specify one.
+	DistroID string
+
+	// MaxUploadSize is the largest APK (in bytes) that the server will accept,
+	// whether it's uploaded or downloaded from a URL.
+	MaxUploadSize int64
+
+	// MaxConcurrentScans limits how many scans can run at the same time. Requests
+	// beyond this limit wait for a scan to finish.
+	MaxConcurrentScans int
+
+	// HTTPClient is used to download APKs that are specified by URL. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// AllowedURLPrefixes limits the URLs that APKs can be downloaded from (see
+	// URLAllowed), e.g. to the URLs of APK repositories. If empty, APKs can't be
+	// specified by URL.
+	AllowedURLPrefixes []string
+}
+
+// DefaultServerOptions is the recommended configuration for a new Server.
+var DefaultServerOptions = ServerOptions{
+	DistroID:           "wolfi",
+	MaxUploadSize:      1 << 30, // 1 GiB
+	MaxConcurrentScans: 4,
+}
+
+// Server is an HTTP handler that scans APKs using a long-lived scanner, so that
+// the vulnerability database only needs to be loaded once.
+//
+// The server exposes the following endpoints:
+//
+//   - POST /v1/scan: Scans an APK and responds with the scan Result as JSON. The
+//     APK can be provided as the raw request body, as the "apk" file of a
+//     multipart form, or by URL, using a JSON request body of the form
+//     {"url": "https://..."}, if the URL is allowed by the AllowedURLPrefixes
+//     option. The "distro" query parameter overrides the default distro.
+//
+//   - GET /v1/status: Responds with the DataSource of the loaded vulnerability
+//     database.
+//
+//   - GET /healthz: Responds with 200 OK once the server is ready.
+type Server struct {
+	scanner APKScanner
+	opts    ServerOptions
+	slots   chan struct{}
+	mux     *http.ServeMux
+}
+
+// NewServer returns a new Server that uses the given scanner.
+func NewServer(scanner APKScanner, opts ServerOptions) *Server {
+	if opts.MaxConcurrentScans < 1 {
+		opts.MaxConcurrentScans = 1
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	opts.HTTPClient = AllowedURLsClient(opts.HTTPClient, opts.AllowedURLPrefixes)
+
+	s := &Server{
+		scanner: scanner,
+		opts:    opts,
+		slots:   make(chan struct{}, opts.MaxConcurrentScans),
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("POST /v1/scan", s.handleScan)
+	s.mux.HandleFunc("GET /v1/status", s.handleStatus)
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// serverError is an error with an associated HTTP status code.
+type serverError struct {
+	status int
+	err    error
+}
+
+func (e *serverError) Error() string {
+	return e.err.Error()
+}
+
+func (e *serverError) Unwrap() error {
+	return e.err
+}
+
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := clog.FromContext(ctx)
+
+	distroID := r.URL.Query().Get("distro")
+	if distroID == "" {
+		distroID = s.opts.DistroID
+	}
+
+	f, err := s.receiveAPK(r)
+	if err != nil {
+		s.writeError(ctx, w, err)
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return
+	}
+
+	logger.Info("scanning APK", "file", f.Name(), "distro", distroID)
+
+	result, err := s.scanner.ScanAPK(ctx, f, distroID)
+	if err != nil {
+		s.writeError(ctx, w, &serverError{status: http.StatusUnprocessableEntity, err: fmt.Errorf("scanning APK: %w", err)})
+		return
+	}
+
+	writeJSON(ctx, w, http.StatusOK, result)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(r.Context(), w, http.StatusOK, struct {
+		DataSource DataSource
+	}{
+		DataSource: s.scanner.DataSource(),
+	})
+}
+
+// receiveAPK stores the APK specified by the request in a temporary file, and
+// returns the file, positioned at its start.
+func (s *Server) receiveAPK(r *http.Request) (*os.File, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = ""
+	}
+
+	switch mediaType {
+	case "application/json":
+		var req struct {
+			URL string `json:"url"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+			return nil, &serverError{status: http.StatusBadRequest, err: fmt.Errorf("decoding request: %w", err)}
+		}
+
+		return s.downloadAPK(r.Context(), req.URL)
+
+	case "multipart/form-data":
+		// Stream the form, rather than parsing it up front, since APKs can be large.
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return nil, &serverError{status: http.StatusBadRequest, err: fmt.Errorf("reading multipart form: %w", err)}
+		}
+
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				return nil, &serverError{status: http.StatusBadRequest, err: errors.New("multipart form has no \"apk\" file")}
+			}
+			if err != nil {
+				return nil, &serverError{status: http.StatusBadRequest, err: fmt.Errorf("reading multipart form: %w", err)}
+			}
+
+			if part.FormName() != "apk" {
+				part.Close()
+				continue
+			}
+
+			defer part.Close()
+
+			name := "upload.apk"
+			if fn := part.FileName(); fn != "" {
+				name = path.Base(fn)
+			}
+			return s.storeAPK(part, name)
+		}
+
+	default:
+		return s.storeAPK(r.Body, "upload.apk")
+	}
+}
+
+func (s *Server) downloadAPK(ctx context.Context, rawURL string) (*os.File, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, &serverError{status: http.StatusBadRequest, err: fmt.Errorf("invalid APK URL %q", rawURL)}
+	}
+	if !URLAllowed(u, s.opts.AllowedURLPrefixes) {
+		return nil, &serverError{status: http.StatusForbidden, err: fmt.Errorf("downloading APKs from %q isn't allowed", rawURL)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, &serverError{status: http.StatusBadRequest, err: err}
+	}
+
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &serverError{status: http.StatusBadGateway, err: fmt.Errorf("downloading APK: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &serverError{status: http.StatusBadGateway, err: fmt.Errorf("downloading APK: unexpected status code %d", resp.StatusCode)}
+	}
+
+	return s.storeAPK(resp.Body, path.Base(u.Path))
+}
+
+// URLAllowed reports whether u is within one of the given URL prefixes. The
+// scheme and host must match the prefix's exactly, and u's path must be the
+// prefix's path or below it. For example, "https://packages.wolfi.dev/os"
+// allows "https://packages.wolfi.dev/os/x86_64/crane-0.19.1-r6.apk", but not
+// "https://packages.wolfi.dev/os-other/crane.apk" or
+// "https://packages.wolfi.dev/os/../crane.apk".
+func URLAllowed(u *url.URL, prefixes []string) bool {
+	if u.Path != "" && path.Clean(u.Path) != u.Path {
+		return false
+	}
+
+	for _, prefix := range prefixes {
+		p, err := url.Parse(prefix)
+		if err != nil {
+			continue
+		}
+
+		if !strings.EqualFold(u.Scheme, p.Scheme) || !strings.EqualFold(u.Host, p.Host) {
+			continue
+		}
+
+		dir := strings.TrimSuffix(p.Path, "/")
+		if u.Path == dir || strings.HasPrefix(u.Path, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowedURLsClient returns a copy of client that doesn't follow redirects to
+// URLs that aren't allowed by URLAllowed, in addition to any redirect policy
+// client already has.
+func AllowedURLsClient(client *http.Client, prefixes []string) *http.Client {
+	c := *client
+	checkRedirect := client.CheckRedirect
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !URLAllowed(req.URL, prefixes) {
+			return fmt.Errorf("redirect to %q isn't allowed", req.URL)
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &c
+}
+
+func (s *Server) storeAPK(r io.Reader, name string) (*os.File, error) {
+	// The file name is used for the SBOM's source metadata, so keep it
+	// recognizable.
+	pattern := "wolfictl-scan-serve-*-" + strings.ReplaceAll(name, string(os.PathSeparator), "-")
+
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, &serverError{status: http.StatusInternalServerError, err: fmt.Errorf("creating temp file: %w", err)}
+	}
+
+	n, err := io.Copy(f, io.LimitReader(r, s.opts.MaxUploadSize+1))
+	if err == nil && n > s.opts.MaxUploadSize {
+		err = &serverError{status: http.StatusRequestEntityTooLarge, err: fmt.Errorf("APK exceeds maximum size of %d bytes", s.opts.MaxUploadSize)}
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		var se *serverError
+		if errors.As(err, &se) {
+			return nil, se
+		}
+		return nil, &serverError{status: http.StatusBadRequest, err: fmt.Errorf("receiving APK: %w", err)}
+	}
+
+	return f, nil
+}
+
+func (s *Server) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var se *serverError
+	if errors.As(err, &se) {
+		status = se.status
+	}
+
+	clog.FromContext(ctx).Warn("scan request failed", "status", status, "error", err)
+
+	writeJSON(ctx, w, status, struct {
+		Error string `json:"error"`
+	}{
+		Error: err.Error(),
+	})
+}
+
+func writeJSON(ctx context.Context, w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		clog.FromContext(ctx).Warn("failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,186 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPKScanner "scans" an APK by reporting its contents as the package name.
+type fakeAPKScanner struct{}
+
+func (fakeAPKScanner) ScanAPK(_ context.Context, apk fs.File, distroID string) (*Result, error) {
+	data, err := io.ReadAll(apk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		TargetAPK: TargetAPK{Name: string(data), Arch: distroID},
+	}, nil
+}
+
+func (fakeAPKScanner) DataSource() DataSource {
+	return DataSource{Kind: "fake", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestServer(t *testing.T) {
+	otherSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte("from-other"))
+		assert.NoError(t, err)
+	}))
+	defer otherSrv.Close()
+
+	apkSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect.apk" {
+			http.Redirect(w, r, otherSrv.URL+"/crane.apk", http.StatusFound)
+			return
+		}
+		if r.URL.Path != "/crane.apk" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte("from-url"))
+		assert.NoError(t, err)
+	}))
+	defer apkSrv.Close()
+
+	opts := DefaultServerOptions
+	opts.MaxUploadSize = 16
+	opts.AllowedURLPrefixes = []string{apkSrv.URL}
+	srv := httptest.NewServer(NewServer(fakeAPKScanner{}, opts))
+	defer srv.Close()
+
+	scan := func(t *testing.T, contentType string, body io.Reader, query string) (int, map[string]any) {
+		t.Helper()
+
+		resp, err := http.Post(srv.URL+"/v1/scan"+query, contentType, body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var decoded map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+		return resp.StatusCode, decoded
+	}
+
+	targetName := func(decoded map[string]any) any {
+		return decoded["TargetAPK"].(map[string]any)["Name"]
+	}
+
+	t.Run("raw upload", func(t *testing.T) {
+		status, decoded := scan(t, "application/octet-stream", strings.NewReader("raw"), "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "raw", targetName(decoded))
+		assert.Equal(t, "wolfi", decoded["TargetAPK"].(map[string]any)["Arch"])
+	})
+
+	t.Run("distro override", func(t *testing.T) {
+		status, decoded := scan(t, "application/octet-stream", strings.NewReader("raw"), "?distro=chainguard")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "chainguard", decoded["TargetAPK"].(map[string]any)["Arch"])
+	})
+
+	t.Run("multipart upload", func(t *testing.T) {
+		body := new(bytes.Buffer)
+		mw := multipart.NewWriter(body)
+		fw, err := mw.CreateFormFile("apk", "crane.apk")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte("multipart"))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		status, decoded := scan(t, mw.FormDataContentType(), body, "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "multipart", targetName(decoded))
+	})
+
+	t.Run("URL", func(t *testing.T) {
+		status, decoded := scan(t, "application/json", strings.NewReader(`{"url": "`+apkSrv.URL+`/crane.apk"}`), "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "from-url", targetName(decoded))
+	})
+
+	t.Run("URL not found", func(t *testing.T) {
+		status, decoded := scan(t, "application/json", strings.NewReader(`{"url": "`+apkSrv.URL+`/missing.apk"}`), "")
+		assert.Equal(t, http.StatusBadGateway, status)
+		assert.Contains(t, decoded["error"], "404")
+	})
+
+	t.Run("URL not allowed", func(t *testing.T) {
+		status, decoded := scan(t, "application/json", strings.NewReader(`{"url": "`+otherSrv.URL+`/crane.apk"}`), "")
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Contains(t, decoded["error"], "isn't allowed")
+	})
+
+	t.Run("redirect not allowed", func(t *testing.T) {
+		status, decoded := scan(t, "application/json", strings.NewReader(`{"url": "`+apkSrv.URL+`/redirect.apk"}`), "")
+		assert.Equal(t, http.StatusBadGateway, status)
+		assert.Contains(t, decoded["error"], "isn't allowed")
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		status, _ := scan(t, "application/json", strings.NewReader(`{"url": "file:///etc/passwd"}`), "")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		status, _ := scan(t, "application/octet-stream", strings.NewReader(strings.Repeat("x", 17)), "")
+		assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/v1/status")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var decoded struct{ DataSource DataSource }
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+		assert.Equal(t, "fake", decoded.DataSource.Kind)
+	})
+}
+
+func TestURLAllowed(t *testing.T) {
+	prefixes := []string{"https://packages.wolfi.dev/os", "https://apk.cgr.dev/extra-packages/"}
+
+	cases := []struct {
+		url     string
+		allowed bool
+	}{
+		{url: "https://packages.wolfi.dev/os/x86_64/crane-0.19.1-r6.apk", allowed: true},
+		{url: "https://PACKAGES.wolfi.dev/os/x86_64/crane-0.19.1-r6.apk", allowed: true},
+		{url: "https://apk.cgr.dev/extra-packages/x86_64/foo.apk", allowed: true},
+		{url: "http://packages.wolfi.dev/os/x86_64/crane-0.19.1-r6.apk"},
+		{url: "https://packages.wolfi.dev/os-other/crane.apk"},
+		{url: "https://packages.wolfi.dev/os/../crane.apk"},
+		{url: "https://packages.wolfi.dev.example.com/os/crane.apk"},
+		{url: "https://packages.wolfi.dev:8443/os/crane.apk"},
+		{url: "http://169.254.169.254/latest/meta-data"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, URLAllowed(u, prefixes))
+		})
+	}
+
+	t.Run("no prefixes", func(t *testing.T) {
+		u, err := url.Parse("https://packages.wolfi.dev/os/x86_64/crane-0.19.1-r6.apk")
+		require.NoError(t, err)
+		assert.False(t, URLAllowed(u, nil))
+	})
+}
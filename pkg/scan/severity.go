@@ -0,0 +1,40 @@
+package scan
+
+import (
+	"strings"
+)
+
+// ValidSeverities are the vulnerability severities (in ascending order) that
+// can be used as a threshold when evaluating scan findings.
+var ValidSeverities = []string{"negligible", "low", "medium", "high", "critical"}
+
+// SeverityRank returns the position of the given severity in ValidSeverities,
+// plus one, so that higher severities have higher ranks. Severity names are
+// matched case-insensitively. Unknown or empty severities are ranked 0, below
+// every valid severity.
+func SeverityRank(severity string) int {
+	s := strings.ToLower(severity)
+	for i, v := range ValidSeverities {
+		if s == v {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// FindingsAtOrAboveSeverity returns the subset of findings whose vulnerability
+// severity is at or above the given threshold severity. Findings with an
+// unknown severity are never included.
+func FindingsAtOrAboveSeverity(findings []Finding, threshold string) []Finding {
+	minRank := SeverityRank(threshold)
+
+	var result []Finding
+	for i := range findings {
+		if rank := SeverityRank(findings[i].Vulnerability.Severity); rank > 0 && rank >= minRank {
+			result = append(result, findings[i])
+		}
+	}
+
+	return result
+}
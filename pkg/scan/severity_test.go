@@ -0,0 +1,47 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindingsAtOrAboveSeverity(t *testing.T) {
+	finding := func(id, severity string) Finding {
+		return Finding{Vulnerability: Vulnerability{ID: id, Severity: severity}}
+	}
+
+	findings := []Finding{
+		finding("CVE-2024-0001", "Negligible"),
+		finding("CVE-2024-0002", "Low"),
+		finding("CVE-2024-0003", "Medium"),
+		finding("CVE-2024-0004", "High"),
+		finding("CVE-2024-0005", "Critical"),
+		finding("CVE-2024-0006", "Unknown"),
+		finding("CVE-2024-0007", ""),
+	}
+
+	ids := func(fs []Finding) []string {
+		var out []string
+		for i := range fs {
+			out = append(out, fs[i].Vulnerability.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		threshold string
+		expected  []string
+	}{
+		{"critical", []string{"CVE-2024-0005"}},
+		{"HIGH", []string{"CVE-2024-0004", "CVE-2024-0005"}},
+		{"medium", []string{"CVE-2024-0003", "CVE-2024-0004", "CVE-2024-0005"}},
+		{"negligible", []string{"CVE-2024-0001", "CVE-2024-0002", "CVE-2024-0003", "CVE-2024-0004", "CVE-2024-0005"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.threshold, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ids(FindingsAtOrAboveSeverity(findings, tt.threshold)))
+		})
+	}
+}
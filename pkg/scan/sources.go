@@ -0,0 +1,476 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	grypePkg "github.com/anchore/grype/grype/pkg"
+	"github.com/chainguard-dev/clog"
+	"github.com/package-url/packageurl-go"
+)
+
+// VulnerabilitySource is a source of vulnerability data that's consulted in
+// addition to the Grype database. Its findings are merged with the Grype
+// matches (see Options.Sources), and each finding records which sources
+// reported it.
+type VulnerabilitySource interface {
+	// Name identifies the source, e.g. "osv".
+	Name() string
+
+	// Findings returns the findings for the given packages. Packages that the
+	// source doesn't know about are ignored.
+	Findings(ctx context.Context, pkgs []Package) ([]Finding, error)
+}
+
+const (
+	// SourceGrype is the name used to attribute findings to the Grype database,
+	// when other sources are also used.
+	SourceGrype = "grype"
+
+	SourceOSV    = "osv"
+	SourceGitHub = "github"
+)
+
+// ValidVulnerabilitySources are the names of the supported additional
+// vulnerability sources.
+var ValidVulnerabilitySources = []string{SourceOSV, SourceGitHub}
+
+// NewVulnerabilitySource returns the source with the given name (see
+// ValidVulnerabilitySources), using the sources' default APIs.
+func NewVulnerabilitySource(name string) (VulnerabilitySource, error) {
+	switch name {
+	case SourceOSV:
+		return &OSVSource{}, nil
+	case SourceGitHub:
+		return &GitHubAdvisorySource{}, nil
+	default:
+		return nil, fmt.Errorf("invalid vulnerability source %q, must be one of [%s]", name, strings.Join(ValidVulnerabilitySources, ", "))
+	}
+}
+
+// sourceNames returns the names of the scanner's additional sources, separated
+// by commas.
+func (s *Scanner) sourceNames() string {
+	names := make([]string, 0, len(s.sources))
+	for _, src := range s.sources {
+		names = append(names, src.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+// findingsFromSources queries each of the scanner's additional sources for the
+// given packages, and merges their findings into the given Grype findings.
+func (s *Scanner) findingsFromSources(ctx context.Context, grypePkgs []grypePkg.Package, findings []Finding) ([]Finding, error) {
+	var pkgs []Package
+	for i := range grypePkgs {
+		p := &grypePkgs[i]
+
+		// Distro packages are already covered by the distro's security data in the
+		// Grype database, and the additional sources only cover language ecosystems.
+		if p.PURL == "" || p.Type == "apk" {
+			continue
+		}
+
+		var locations []string
+		for _, l := range p.Locations.ToSlice() {
+			locations = append(locations, "/"+l.RealPath)
+		}
+
+		pkgs = append(pkgs, Package{
+			ID:       string(p.ID),
+			Name:     p.Name,
+			Version:  p.Version,
+			Type:     string(p.Type),
+			Location: strings.Join(locations, ", "),
+			PURL:     p.PURL,
+		})
+	}
+
+	for i := range findings {
+		findings[i].Sources = []string{SourceGrype}
+	}
+
+	for _, src := range s.sources {
+		sourceFindings, err := src.Findings(ctx, pkgs)
+		if err != nil {
+			return nil, fmt.Errorf("querying vulnerability source %q: %w", src.Name(), err)
+		}
+
+		clog.FromContext(ctx).Debug("queried vulnerability source", "source", src.Name(), "packageCount", len(pkgs), "findingCount", len(sourceFindings))
+
+		findings = mergeSourceFindings(findings, src.Name(), sourceFindings)
+	}
+
+	return findings, nil
+}
+
+// mergeSourceFindings adds the findings from the named source to findings.
+// Findings that are already present (using the same criteria as CrossValidate)
+// are attributed to the source instead of being added again.
+func mergeSourceFindings(findings []Finding, source string, sourceFindings []Finding) []Finding {
+	existing := len(findings)
+
+	for i := range sourceFindings {
+		sf := &sourceFindings[i]
+
+		merged := false
+		for j := range findings[:existing] {
+			f := &findings[j]
+			if !sameFinding(f, sf) {
+				continue
+			}
+
+			if !slices.Contains(f.Sources, source) {
+				f.Sources = append(f.Sources, source)
+			}
+			merged = true
+		}
+
+		if !merged {
+			sf.Sources = []string{source}
+			findings = append(findings, *sf)
+			existing++
+		}
+	}
+
+	return findings
+}
+
+// OSVAPIURL is the base URL of the OSV API.
+const OSVAPIURL = "https://api.osv.dev"
+
+// OSVSource finds vulnerabilities using the OSV API (https://osv.dev), which
+// aggregates advisories from many language ecosystems. Packages are looked up
+// by their purl.
+type OSVSource struct {
+	// URL is the base URL of the OSV API. If empty, OSVAPIURL is used.
+	URL string
+
+	// HTTPClient is used to query the API. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+func (o *OSVSource) Name() string {
+	return SourceOSV
+}
+
+// osvBatchSize is the maximum number of queries the OSV API accepts in a single
+// batch query.
+const osvBatchSize = 1000
+
+type osvQueryBatchRequest struct {
+	Queries []osvQuery `json:"queries"`
+}
+
+type osvQuery struct {
+	Package struct {
+		PURL string `json:"purl"`
+	} `json:"package"`
+}
+
+type osvQueryBatchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	} `json:"results"`
+}
+
+// osvVulnerability is the subset of the OSV schema that we use.
+type osvVulnerability struct {
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases"`
+	Affected []struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Events []struct {
+				Fixed string `json:"fixed"`
+			} `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+func (o *OSVSource) Findings(ctx context.Context, pkgs []Package) ([]Finding, error) {
+	base := o.URL
+	if base == "" {
+		base = OSVAPIURL
+	}
+
+	var findings []Finding
+	vulns := make(map[string]*osvVulnerability)
+
+	for start := 0; start < len(pkgs); start += osvBatchSize {
+		batch := pkgs[start:min(start+osvBatchSize, len(pkgs))]
+
+		var req osvQueryBatchRequest
+		for i := range batch {
+			var q osvQuery
+			q.Package.PURL = batch[i].PURL
+			req.Queries = append(req.Queries, q)
+		}
+
+		body, err := json.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp osvQueryBatchResponse
+		if err := doJSONRequest(ctx, o.HTTPClient, http.MethodPost, base+"/v1/querybatch", nil, bytes.NewReader(body), &resp); err != nil {
+			return nil, fmt.Errorf("querying OSV: %w", err)
+		}
+
+		if len(resp.Results) != len(batch) {
+			return nil, fmt.Errorf("querying OSV: expected %d results, got %d", len(batch), len(resp.Results))
+		}
+
+		for i, result := range resp.Results {
+			p := batch[i]
+
+			for _, v := range result.Vulns {
+				vuln, ok := vulns[v.ID]
+				if !ok {
+					vuln = new(osvVulnerability)
+					if err := doJSONRequest(ctx, o.HTTPClient, http.MethodGet, base+"/v1/vulns/"+url.PathEscape(v.ID), nil, nil, vuln); err != nil {
+						return nil, fmt.Errorf("getting OSV vulnerability %s: %w", v.ID, err)
+					}
+					vulns[v.ID] = vuln
+				}
+
+				findings = append(findings, Finding{
+					Package: p,
+					Vulnerability: Vulnerability{
+						ID:           vuln.ID,
+						Severity:     normalizeGHSASeverity(vuln.DatabaseSpecific.Severity),
+						Aliases:      vuln.Aliases,
+						FixedVersion: osvFixedVersion(vuln, p.Name),
+					},
+				})
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// osvFixedVersion returns the fixed versions listed for the named package in
+// the given OSV vulnerability, separated by commas.
+func osvFixedVersion(vuln *osvVulnerability, packageName string) string {
+	var fixed []string
+	for i := range vuln.Affected {
+		a := &vuln.Affected[i]
+
+		// Maven packages are named by group and artifact in OSV, but only by artifact
+		// in SBOMs.
+		if a.Package.Name != packageName && !strings.HasSuffix(a.Package.Name, ":"+packageName) {
+			continue
+		}
+
+		for _, r := range a.Ranges {
+			for _, e := range r.Events {
+				if e.Fixed != "" && !slices.Contains(fixed, e.Fixed) {
+					fixed = append(fixed, e.Fixed)
+				}
+			}
+		}
+	}
+
+	return strings.Join(fixed, ", ")
+}
+
+// GitHubAPIURL is the base URL of the GitHub REST API.
+const GitHubAPIURL = "https://api.github.com"
+
+// GitHubAdvisorySource finds vulnerabilities using the GitHub Advisory Database
+// (https://github.com/advisories). Since the database is queried once per
+// package, setting Token is recommended to avoid GitHub's low rate limit for
+// unauthenticated requests.
+type GitHubAdvisorySource struct {
+	// URL is the base URL of the GitHub REST API. If empty, GitHubAPIURL is used.
+	URL string
+
+	// Token, if set, is used to authenticate to the GitHub API.
+	Token string
+
+	// HTTPClient is used to query the API. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+func (g *GitHubAdvisorySource) Name() string {
+	return SourceGitHub
+}
+
+// githubAdvisory is the subset of the GitHub global security advisory schema
+// that we use.
+type githubAdvisory struct {
+	GHSAID          string `json:"ghsa_id"`
+	CVEID           string `json:"cve_id"`
+	Severity        string `json:"severity"`
+	Vulnerabilities []struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+		FirstPatchedVersion string `json:"first_patched_version"`
+	} `json:"vulnerabilities"`
+}
+
+func (g *GitHubAdvisorySource) Findings(ctx context.Context, pkgs []Package) ([]Finding, error) {
+	base := g.URL
+	if base == "" {
+		base = GitHubAPIURL
+	}
+
+	header := http.Header{"Accept": []string{"application/vnd.github+json"}}
+	if g.Token != "" {
+		header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	var findings []Finding
+	for i := range pkgs {
+		p := pkgs[i]
+
+		ecosystem, name, ok := githubEcosystemPackage(p.PURL)
+		if !ok {
+			continue
+		}
+
+		query := url.Values{
+			"ecosystem": []string{ecosystem},
+			"affects":   []string{name + "@" + p.Version},
+			"per_page":  []string{"100"},
+		}
+
+		var advisories []githubAdvisory
+		if err := doJSONRequest(ctx, g.HTTPClient, http.MethodGet, base+"/advisories?"+query.Encode(), header, nil, &advisories); err != nil {
+			return nil, fmt.Errorf("querying GitHub advisories for %s: %w", name, err)
+		}
+
+		for j := range advisories {
+			adv := &advisories[j]
+
+			var aliases []string
+			if adv.CVEID != "" {
+				aliases = append(aliases, adv.CVEID)
+			}
+
+			var fixed string
+			for _, v := range adv.Vulnerabilities {
+				if v.Package.Name == name {
+					fixed = v.FirstPatchedVersion
+					break
+				}
+			}
+
+			findings = append(findings, Finding{
+				Package: p,
+				Vulnerability: Vulnerability{
+					ID:           adv.GHSAID,
+					Severity:     normalizeGHSASeverity(adv.Severity),
+					Aliases:      aliases,
+					FixedVersion: fixed,
+				},
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// githubEcosystems maps purl types to the corresponding GitHub Advisory Database
+// ecosystems.
+var githubEcosystems = map[string]string{
+	packageurl.TypeGolang:   "go",
+	packageurl.TypePyPi:     "pip",
+	packageurl.TypeNPM:      "npm",
+	packageurl.TypeMaven:    "maven",
+	packageurl.TypeGem:      "rubygems",
+	packageurl.TypeCargo:    "rust",
+	packageurl.TypeNuget:    "nuget",
+	packageurl.TypeComposer: "composer",
+	packageurl.TypeSwift:    "swift",
+	packageurl.TypeHex:      "erlang",
+	packageurl.TypePub:      "pub",
+}
+
+// githubEcosystemPackage returns the GitHub Advisory Database ecosystem and
+// package name for the given purl, or false if the ecosystem isn't covered by
+// the database.
+func githubEcosystemPackage(purl string) (ecosystem, name string, ok bool) {
+	p, err := packageurl.FromString(purl)
+	if err != nil {
+		return "", "", false
+	}
+
+	ecosystem, ok = githubEcosystems[p.Type]
+	if !ok {
+		return "", "", false
+	}
+
+	name = p.Name
+	if p.Namespace != "" {
+		separator := "/"
+		if p.Type == packageurl.TypeMaven {
+			separator = ":"
+		}
+		name = p.Namespace + separator + p.Name
+	}
+
+	return ecosystem, name, true
+}
+
+// normalizeGHSASeverity converts a GitHub advisory severity like "MODERATE" to
+// the form used in findings, e.g. "Medium".
+func normalizeGHSASeverity(severity string) string {
+	if strings.EqualFold(severity, "moderate") {
+		return "Medium"
+	}
+
+	return normalizeSeverity(severity)
+}
+
+// doJSONRequest makes an HTTP request and decodes the JSON response into v.
+func doJSONRequest(ctx context.Context, client *http.Client, method, u string, header http.Header, body io.Reader, v any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
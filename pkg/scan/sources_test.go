@@ -0,0 +1,184 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSourceFindings(t *testing.T) {
+	findings := []Finding{
+		{
+			Package:       Package{Name: "github.com/foo/bar"},
+			Vulnerability: Vulnerability{ID: "GHSA-aaaa-bbbb-cccc", Aliases: []string{"CVE-2024-1234"}},
+			Sources:       []string{SourceGrype},
+		},
+	}
+
+	sourceFindings := []Finding{
+		{
+			Package:       Package{Name: "github.com/foo/bar"},
+			Vulnerability: Vulnerability{ID: "CVE-2024-1234"},
+		},
+		{
+			Package:       Package{Name: "github.com/foo/bar"},
+			Vulnerability: Vulnerability{ID: "GO-2024-0001", Aliases: []string{"CVE-2024-5678"}},
+		},
+		{
+			Package:       Package{Name: "github.com/foo/bar"},
+			Vulnerability: Vulnerability{ID: "CVE-2024-5678"},
+		},
+	}
+
+	got := mergeSourceFindings(findings, SourceOSV, sourceFindings)
+
+	expected := []Finding{
+		{
+			Package:       Package{Name: "github.com/foo/bar"},
+			Vulnerability: Vulnerability{ID: "GHSA-aaaa-bbbb-cccc", Aliases: []string{"CVE-2024-1234"}},
+			Sources:       []string{SourceGrype, SourceOSV},
+		},
+		{
+			Package:       Package{Name: "github.com/foo/bar"},
+			Vulnerability: Vulnerability{ID: "GO-2024-0001", Aliases: []string{"CVE-2024-5678"}},
+			Sources:       []string{SourceOSV},
+		},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected findings (-want +got):\n%s", diff)
+	}
+}
+
+func TestOSVSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			var req osvQueryBatchRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Len(t, req.Queries, 2)
+			assert.Equal(t, "pkg:golang/github.com/foo/bar@v1.2.3", req.Queries[0].Package.PURL)
+
+			_, _ = w.Write([]byte(`{"results": [{"vulns": [{"id": "GO-2024-0001"}]}, {}]}`))
+
+		case "/v1/vulns/GO-2024-0001":
+			_, _ = w.Write([]byte(`{
+				"id": "GO-2024-0001",
+				"aliases": ["CVE-2024-1234", "GHSA-aaaa-bbbb-cccc"],
+				"affected": [
+					{"package": {"name": "github.com/foo/bar"}, "ranges": [{"events": [{"introduced": "0"}, {"fixed": "1.2.4"}]}]},
+					{"package": {"name": "github.com/foo/other"}, "ranges": [{"events": [{"introduced": "0"}, {"fixed": "9.9.9"}]}]}
+				],
+				"database_specific": {"severity": "MODERATE"}
+			}`))
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	pkgs := []Package{
+		{Name: "github.com/foo/bar", Version: "v1.2.3", Type: "go-module", PURL: "pkg:golang/github.com/foo/bar@v1.2.3"},
+		{Name: "requests", Version: "2.31.0", Type: "python", PURL: "pkg:pypi/requests@2.31.0"},
+	}
+
+	src := &OSVSource{URL: srv.URL}
+	findings, err := src.Findings(context.Background(), pkgs)
+	require.NoError(t, err)
+
+	expected := []Finding{
+		{
+			Package: pkgs[0],
+			Vulnerability: Vulnerability{
+				ID:           "GO-2024-0001",
+				Severity:     "Medium",
+				Aliases:      []string{"CVE-2024-1234", "GHSA-aaaa-bbbb-cccc"},
+				FixedVersion: "1.2.4",
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, findings); diff != "" {
+		t.Errorf("unexpected findings (-want +got):\n%s", diff)
+	}
+}
+
+func TestGitHubAdvisorySource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/advisories", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		if r.URL.Query().Get("affects") != "org.example:lib@1.0.0" || r.URL.Query().Get("ecosystem") != "maven" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`[
+			{
+				"ghsa_id": "GHSA-xxxx-yyyy-zzzz",
+				"cve_id": "CVE-2024-9999",
+				"severity": "high",
+				"vulnerabilities": [{"package": {"ecosystem": "maven", "name": "org.example:lib"}, "first_patched_version": "1.0.1"}]
+			}
+		]`))
+	}))
+	defer srv.Close()
+
+	pkgs := []Package{
+		{Name: "lib", Version: "1.0.0", Type: "java-archive", PURL: "pkg:maven/org.example/lib@1.0.0"},
+		{Name: "left-pad", Version: "1.3.0", Type: "npm", PURL: "pkg:npm/left-pad@1.3.0"},
+		{Name: "unsupported", Version: "1", Type: "binary", PURL: "pkg:generic/unsupported@1"},
+	}
+
+	src := &GitHubAdvisorySource{URL: srv.URL, Token: "secret"}
+	findings, err := src.Findings(context.Background(), pkgs)
+	require.NoError(t, err)
+
+	expected := []Finding{
+		{
+			Package: pkgs[0],
+			Vulnerability: Vulnerability{
+				ID:           "GHSA-xxxx-yyyy-zzzz",
+				Severity:     "High",
+				Aliases:      []string{"CVE-2024-9999"},
+				FixedVersion: "1.0.1",
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, findings); diff != "" {
+		t.Errorf("unexpected findings (-want +got):\n%s", diff)
+	}
+}
+
+func TestGitHubEcosystemPackage(t *testing.T) {
+	cases := []struct {
+		purl              string
+		expectedEcosystem string
+		expectedName      string
+		expectedOK        bool
+	}{
+		{"pkg:golang/github.com/foo/bar@v1.2.3", "go", "github.com/foo/bar", true},
+		{"pkg:npm/%40angular/core@17.0.0", "npm", "@angular/core", true},
+		{"pkg:maven/org.example/lib@1.0.0", "maven", "org.example:lib", true},
+		{"pkg:pypi/requests@2.31.0", "pip", "requests", true},
+		{"pkg:apk/wolfi/busybox@1.36.1-r0", "", "", false},
+		{"not a purl", "", "", false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.purl, func(t *testing.T) {
+			ecosystem, name, ok := githubEcosystemPackage(tt.purl)
+			assert.Equal(t, tt.expectedEcosystem, ecosystem)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package scan
+
+import (
+	"strings"
+)
+
+const (
+	// FixStateFixed is the fix state of findings whose vulnerability has a fixed
+	// version available upstream.
+	FixStateFixed = "fixed"
+
+	// FixStateNotFixed is the fix state of findings whose vulnerability doesn't
+	// have a fixed version available upstream.
+	FixStateNotFixed = "not-fixed"
+)
+
+// Thresholds are the conditions under which a scanned package fails a scan run.
+type Thresholds struct {
+	// RequireZero fails a package that has any findings.
+	RequireZero bool
+
+	// FailOnSeverity fails a package that has any findings at or above this
+	// severity. If empty, severity isn't considered.
+	FailOnSeverity string
+}
+
+// failed returns true if the given findings fail the thresholds.
+func (t Thresholds) failed(findings []Finding) bool {
+	if t.RequireZero && len(findings) > 0 {
+		return true
+	}
+
+	return t.FailOnSeverity != "" && len(FindingsAtOrAboveSeverity(findings, t.FailOnSeverity)) > 0
+}
+
+// Summary is a small, machine-readable overview of a scan run, with aggregate
+// counts of the findings instead of the findings themselves.
+type Summary struct {
+	// Pass is true if none of the scanned packages failed the run's thresholds.
+	Pass bool
+
+	// Thresholds are the thresholds that the packages were evaluated against.
+	Thresholds Thresholds
+
+	// Packages is the number of packages scanned.
+	Packages int
+
+	// Findings is the total number of findings across all packages.
+	Findings int
+
+	// BySeverity counts the findings by their lowercase severity (e.g. "high"), or
+	// "unknown" if the severity isn't known.
+	BySeverity map[string]int
+
+	// ByFixState counts the findings by whether a fix is available (FixStateFixed
+	// or FixStateNotFixed).
+	ByFixState map[string]int
+
+	// ByPackage summarizes each scanned package, in the order they were scanned.
+	ByPackage []PackageSummary
+}
+
+// PackageSummary is the part of a Summary that describes a single scanned
+// package.
+type PackageSummary struct {
+	Name       string
+	Version    string
+	Arch       string `json:",omitempty"`
+	Pass       bool
+	Findings   int
+	BySeverity map[string]int
+	ByFixState map[string]int
+}
+
+// Summarize returns a summary of the given scan results, evaluated against the
+// given thresholds.
+func Summarize(results []Result, thresholds Thresholds) Summary {
+	s := Summary{
+		Pass:       true,
+		Thresholds: thresholds,
+		Packages:   len(results),
+		BySeverity: make(map[string]int),
+		ByFixState: make(map[string]int),
+		ByPackage:  make([]PackageSummary, 0, len(results)),
+	}
+
+	for i := range results {
+		result := &results[i]
+
+		ps := PackageSummary{
+			Name:       result.TargetAPK.Name,
+			Version:    result.TargetAPK.Version,
+			Arch:       result.TargetAPK.Arch,
+			Pass:       !thresholds.failed(result.Findings),
+			Findings:   len(result.Findings),
+			BySeverity: make(map[string]int),
+			ByFixState: make(map[string]int),
+		}
+
+		for j := range result.Findings {
+			f := &result.Findings[j]
+
+			severity := strings.ToLower(f.Vulnerability.Severity)
+			if SeverityRank(severity) == 0 {
+				severity = severityUnknown
+			}
+
+			fixState := FixStateNotFixed
+			if f.Vulnerability.FixedVersion != "" {
+				fixState = FixStateFixed
+			}
+
+			ps.BySeverity[severity]++
+			ps.ByFixState[fixState]++
+			s.BySeverity[severity]++
+			s.ByFixState[fixState]++
+		}
+
+		s.Findings += ps.Findings
+		s.Pass = s.Pass && ps.Pass
+		s.ByPackage = append(s.ByPackage, ps)
+	}
+
+	return s
+}
@@ -0,0 +1,107 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSummarize(t *testing.T) {
+	results := []Result{
+		{
+			TargetAPK: TargetAPK{Name: "crane", Version: "0.19.1-r6", Arch: "x86_64"},
+			Findings: []Finding{
+				{Vulnerability: Vulnerability{ID: "CVE-2024-0001", Severity: "High", FixedVersion: "1.2.3"}},
+				{Vulnerability: Vulnerability{ID: "CVE-2024-0002", Severity: "Low"}},
+				{Vulnerability: Vulnerability{ID: "CVE-2024-0003"}},
+			},
+		},
+		{
+			TargetAPK: TargetAPK{Name: "ko", Version: "0.15.0-r0", Arch: "x86_64"},
+			Findings: []Finding{
+				{Vulnerability: Vulnerability{ID: "CVE-2024-0004", Severity: "Medium", FixedVersion: "0.15.1"}},
+			},
+		},
+		{
+			TargetAPK: TargetAPK{Name: "empty", Version: "1.0.0-r0"},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		thresholds Thresholds
+		wantPass   bool
+		wantByPkg  []bool
+	}{
+		{
+			name:      "no thresholds",
+			wantPass:  true,
+			wantByPkg: []bool{true, true, true},
+		},
+		{
+			name:       "require zero",
+			thresholds: Thresholds{RequireZero: true},
+			wantPass:   false,
+			wantByPkg:  []bool{false, false, true},
+		},
+		{
+			name:       "fail on high",
+			thresholds: Thresholds{FailOnSeverity: "high"},
+			wantPass:   false,
+			wantByPkg:  []bool{false, true, true},
+		},
+		{
+			name:       "fail on critical",
+			thresholds: Thresholds{FailOnSeverity: "critical"},
+			wantPass:   true,
+			wantByPkg:  []bool{true, true, true},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Summarize(results, tt.thresholds)
+
+			if s.Pass != tt.wantPass {
+				t.Errorf("Pass = %t, want %t", s.Pass, tt.wantPass)
+			}
+
+			var gotByPkg []bool
+			for _, ps := range s.ByPackage {
+				gotByPkg = append(gotByPkg, ps.Pass)
+			}
+			if diff := cmp.Diff(tt.wantByPkg, gotByPkg); diff != "" {
+				t.Errorf("unexpected package pass states (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("counts", func(t *testing.T) {
+		s := Summarize(results, Thresholds{})
+
+		if s.Packages != 3 || s.Findings != 4 {
+			t.Errorf("got %d packages and %d findings, want 3 and 4", s.Packages, s.Findings)
+		}
+
+		if diff := cmp.Diff(map[string]int{"high": 1, "medium": 1, "low": 1, "unknown": 1}, s.BySeverity); diff != "" {
+			t.Errorf("unexpected severity counts (-want +got):\n%s", diff)
+		}
+
+		if diff := cmp.Diff(map[string]int{FixStateFixed: 2, FixStateNotFixed: 2}, s.ByFixState); diff != "" {
+			t.Errorf("unexpected fix state counts (-want +got):\n%s", diff)
+		}
+
+		expectedCrane := PackageSummary{
+			Name:       "crane",
+			Version:    "0.19.1-r6",
+			Arch:       "x86_64",
+			Pass:       true,
+			Findings:   3,
+			BySeverity: map[string]int{"high": 1, "low": 1, "unknown": 1},
+			ByFixState: map[string]int{FixStateFixed: 1, FixStateNotFixed: 2},
+		}
+		if diff := cmp.Diff(expectedCrane, s.ByPackage[0]); diff != "" {
+			t.Errorf("unexpected package summary (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package scan
+
+import (
+	"fmt"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/syft/syft/pkg"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+)
+
+// TriageSuggestion is a heuristic suggestion for how to triage a finding,
+// derived from the details of the vulnerability match. It's meant to speed up
+// advisory creation, not to replace human judgment.
+type TriageSuggestion struct {
+	// EventType is the type of advisory event that the suggestion most likely leads
+	// to (e.g. "false-positive-determination").
+	EventType string
+
+	// Reason describes the suggested action and why it's suggested, e.g. "fix
+	// available upstream in 1.2.3".
+	Reason string
+}
+
+// suggestTriage returns a TriageSuggestion for the finding produced by the given
+// match. The most specific heuristic that applies wins.
+func suggestTriage(m *match.Match, f *Finding) *TriageSuggestion {
+	fixedVersion := f.Vulnerability.FixedVersion
+
+	if m.Package.Type == pkg.GoModulePkg && m.Package.Name == "stdlib" {
+		if fixedVersion == "" {
+			return &TriageSuggestion{
+				EventType: v2.EventTypePendingUpstreamFix,
+				Reason:    "stdlib match: no fixed Go release yet",
+			}
+		}
+
+		return &TriageSuggestion{
+			EventType: v2.EventTypeFixed,
+			Reason:    fmt.Sprintf("stdlib match: needs Go toolchain bump to %s", fixedVersion),
+		}
+	}
+
+	if isCPEOnlyMatch(m) && !hasTrustedCPESource(m) {
+		return &TriageSuggestion{
+			EventType: v2.EventTypeFalsePositiveDetermination,
+			Reason:    "likely false positive: CPE-only match with no trusted source",
+		}
+	}
+
+	switch m.Vulnerability.Fix.State {
+	case vulnerability.FixStateFixed:
+		if m.Package.Type == pkg.ApkPkg {
+			return &TriageSuggestion{
+				EventType: v2.EventTypeFixed,
+				Reason:    fmt.Sprintf("fixed in distro package version %s", fixedVersion),
+			}
+		}
+
+		return &TriageSuggestion{
+			EventType: v2.EventTypeFixed,
+			Reason:    fmt.Sprintf("fix available upstream in %s", fixedVersion),
+		}
+
+	case vulnerability.FixStateWontFix:
+		return &TriageSuggestion{
+			EventType: v2.EventTypeFixNotPlanned,
+			Reason:    "upstream won't fix",
+		}
+
+	default:
+		return &TriageSuggestion{
+			EventType: v2.EventTypePendingUpstreamFix,
+			Reason:    "no fix available upstream yet",
+		}
+	}
+}
+
+// isCPEOnlyMatch returns true if the match was made only by CPE, rather than by
+// an exact package match.
+func isCPEOnlyMatch(m *match.Match) bool {
+	if len(m.Details) == 0 {
+		return false
+	}
+
+	for _, d := range m.Details {
+		if d.Type != match.CPEMatch {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasTrustedCPESource returns true if any of the CPEs that the match was made
+// with came from a trusted source. See trustedCPESources.
+func hasTrustedCPESource(m *match.Match) bool {
+	for _, d := range m.Details {
+		p, ok := d.SearchedBy.(match.CPEParameters)
+		if !ok {
+			continue
+		}
+
+		if isMatchFromTrustedCPESource(p.CPEs, m.Package.CPEs) {
+			return true
+		}
+	}
+
+	return false
+}
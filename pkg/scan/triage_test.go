@@ -0,0 +1,117 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/anchore/grype/grype/match"
+	grypePkg "github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/syft/syft/cpe"
+	"github.com/anchore/syft/syft/pkg"
+	v2 "github.com/chainguard-dev/advisory-schema/pkg/advisory/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/wolfi-dev/wolfictl/pkg/sbom"
+)
+
+func Test_suggestTriage(t *testing.T) {
+	attrs := cpe.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1.0.0"}
+
+	cpeMatch := func(source cpe.Source) match.Match {
+		return match.Match{
+			Package: grypePkg.Package{
+				Name: "bar",
+				Type: pkg.JavaPkg,
+				CPEs: []cpe.CPE{{Attributes: attrs, Source: source}},
+			},
+			Vulnerability: vulnerability.Vulnerability{
+				Fix: vulnerability.Fix{State: vulnerability.FixStateFixed, Versions: []string{"1.2.3"}},
+			},
+			Details: match.Details{
+				{
+					Type:       match.CPEMatch,
+					SearchedBy: match.CPEParameters{CPEs: []string{attrs.BindToFmtString()}},
+				},
+			},
+		}
+	}
+
+	exactMatch := func(pkgType pkg.Type, name string, fix vulnerability.Fix) match.Match {
+		return match.Match{
+			Package:       grypePkg.Package{Name: name, Type: pkgType},
+			Vulnerability: vulnerability.Vulnerability{Fix: fix},
+			Details:       match.Details{{Type: match.ExactDirectMatch}},
+		}
+	}
+
+	fixed := vulnerability.Fix{State: vulnerability.FixStateFixed, Versions: []string{"1.2.3"}}
+
+	cases := []struct {
+		name     string
+		match    match.Match
+		expected TriageSuggestion
+	}{
+		{
+			name:  "stdlib with fix",
+			match: exactMatch(pkg.GoModulePkg, "stdlib", fixed),
+			expected: TriageSuggestion{
+				EventType: v2.EventTypeFixed,
+				Reason:    "stdlib match: needs Go toolchain bump to 1.2.3",
+			},
+		},
+		{
+			name:  "stdlib without fix",
+			match: exactMatch(pkg.GoModulePkg, "stdlib", vulnerability.Fix{State: vulnerability.FixStateNotFixed}),
+			expected: TriageSuggestion{
+				EventType: v2.EventTypePendingUpstreamFix,
+				Reason:    "stdlib match: no fixed Go release yet",
+			},
+		},
+		{
+			name:  "CPE-only match from untrusted source",
+			match: cpeMatch(cpe.GeneratedSource),
+			expected: TriageSuggestion{
+				EventType: v2.EventTypeFalsePositiveDetermination,
+				Reason:    "likely false positive: CPE-only match with no trusted source",
+			},
+		},
+		{
+			name:  "CPE-only match from trusted source",
+			match: cpeMatch(sbom.CPESourceMelangeConfiguration),
+			expected: TriageSuggestion{
+				EventType: v2.EventTypeFixed,
+				Reason:    "fix available upstream in 1.2.3",
+			},
+		},
+		{
+			name:  "distro package with fix",
+			match: exactMatch(pkg.ApkPkg, "crane", fixed),
+			expected: TriageSuggestion{
+				EventType: v2.EventTypeFixed,
+				Reason:    "fixed in distro package version 1.2.3",
+			},
+		},
+		{
+			name:  "won't fix",
+			match: exactMatch(pkg.PythonPkg, "requests", vulnerability.Fix{State: vulnerability.FixStateWontFix}),
+			expected: TriageSuggestion{
+				EventType: v2.EventTypeFixNotPlanned,
+				Reason:    "upstream won't fix",
+			},
+		},
+		{
+			name:  "no fix",
+			match: exactMatch(pkg.NpmPkg, "lodash", vulnerability.Fix{State: vulnerability.FixStateUnknown}),
+			expected: TriageSuggestion{
+				EventType: v2.EventTypePendingUpstreamFix,
+				Reason:    "no fix available upstream yet",
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Finding{Vulnerability: Vulnerability{FixedVersion: getFixedVersion(tt.match.Vulnerability)}}
+			assert.Equal(t, tt.expected, *suggestTriage(&tt.match, f))
+		})
+	}
+}
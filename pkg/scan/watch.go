@@ -0,0 +1,137 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchAPKs watches the given directory, and all of its subdirectories, for APK
+// files that are created or written. Since APKs are written incrementally (e.g.
+// by melange), fn is only called for an APK once its file hasn't changed for the
+// settle duration. APKs that already exist when watching begins are ignored.
+//
+// Calls to fn are made one at a time, in the order in which the APKs settle.
+// WatchAPKs blocks until ctx is canceled, at which point it returns nil.
+func WatchAPKs(ctx context.Context, dir string, settle time.Duration, fn func(ctx context.Context, apkPath string)) error {
+	logger := clog.FromContext(ctx)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// pending holds the time of the latest change to each APK that hasn't been
+	// handled yet.
+	pending := make(map[string]time.Time)
+
+	// watchTree watches the directory at root and all of its subdirectories. When
+	// a directory is created while watching, APKs may have been written to it
+	// before it's watched, so those are added to pending.
+	watchTree := func(root string, includeExisting bool) error {
+		return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if err := watcher.Add(path); err != nil {
+					return fmt.Errorf("watching %q: %w", path, err)
+				}
+				return nil
+			}
+			if includeExisting && isAPKPath(path) {
+				pending[path] = time.Now()
+			}
+			return nil
+		})
+	}
+
+	if err := watchTree(dir, false); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(settle / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			switch {
+			case event.Has(fsnotify.Create) && isDir(event.Name):
+				if err := watchTree(event.Name, true); err != nil {
+					logger.Warn("failed to watch new directory", "path", event.Name, "error", err)
+				}
+
+			case !isAPKPath(event.Name):
+				continue
+
+			case event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename):
+				delete(pending, event.Name)
+
+			case event.Has(fsnotify.Create) || event.Has(fsnotify.Write):
+				pending[event.Name] = time.Now()
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				logger.Warn("file watcher dropped events, some APKs may not be scanned", "error", err)
+				continue
+			}
+			return fmt.Errorf("watching %q: %w", dir, err)
+
+		case now := <-ticker.C:
+			var settled []string
+			for path, changed := range pending {
+				if now.Sub(changed) >= settle {
+					settled = append(settled, path)
+				}
+			}
+
+			// Handle APKs in the order they were last changed.
+			slices.SortFunc(settled, func(a, b string) int {
+				if c := pending[a].Compare(pending[b]); c != 0 {
+					return c
+				}
+				return strings.Compare(a, b)
+			})
+
+			for _, path := range settled {
+				delete(pending, path)
+
+				if ctx.Err() != nil {
+					return nil
+				}
+				fn(ctx, path)
+			}
+		}
+	}
+}
+
+func isAPKPath(path string) bool {
+	return strings.HasSuffix(path, ".apk")
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
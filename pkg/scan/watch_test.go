@@ -0,0 +1,71 @@
+package scan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchAPKs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing-1.0-r0.apk"), []byte("old"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	found := make(chan string, 10)
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- WatchAPKs(ctx, dir, 100*time.Millisecond, func(_ context.Context, apkPath string) {
+			data, err := os.ReadFile(apkPath)
+			assert.NoError(t, err)
+			assert.Equal(t, "complete", string(data), "APK handled before it was fully written")
+
+			rel, err := filepath.Rel(dir, apkPath)
+			assert.NoError(t, err)
+			found <- rel
+		})
+	}()
+
+	// Give the watcher time to start.
+	time.Sleep(100 * time.Millisecond)
+
+	// Simulate a melange build: a new arch directory, then an APK that's written
+	// in a few steps. Other files are ignored.
+	archDir := filepath.Join(dir, "x86_64")
+	require.NoError(t, os.Mkdir(archDir, 0o755))
+
+	apkPath := filepath.Join(archDir, "crane-0.19.1-r6.apk")
+	f, err := os.Create(apkPath)
+	require.NoError(t, err)
+	for _, chunk := range []string{"comp", "le", "te"} {
+		_, err := f.WriteString(chunk)
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+	}
+	require.NoError(t, f.Close())
+
+	require.NoError(t, os.WriteFile(filepath.Join(archDir, "APKINDEX.tar.gz"), []byte("index"), 0o644))
+
+	select {
+	case got := <-found:
+		assert.Equal(t, filepath.Join("x86_64", "crane-0.19.1-r6.apk"), got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for APK to be handled")
+	}
+
+	// No other files should be handled.
+	select {
+	case got := <-found:
+		t.Fatalf("unexpected file handled: %s", got)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	cancel()
+	require.NoError(t, <-watchErr)
+}
@@ -0,0 +1,71 @@
+package nvdapi
+
+import "strings"
+
+// CVSS is a CVSS score of a CVE.
+type CVSS struct {
+	// Version is the CVSS version, e.g. "3.1".
+	Version string
+
+	// Vector is the CVSS vector string.
+	Vector string
+
+	// Score is the CVSS base score.
+	Score float64
+
+	// Severity is the severity of the base score, e.g. "HIGH".
+	Severity string
+}
+
+// cvssMetricTypePrimary is the type of the metric provided by NVD itself, as
+// opposed to one provided by another source like a CNA ("Secondary").
+const cvssMetricTypePrimary = "Primary"
+
+// CVSS returns the CVE's CVSS score using the newest CVSS version available,
+// preferring NVD's own score for that version to scores from other sources. The
+// second return value is false if the CVE has no CVSS score.
+func (cve Cve) CVSS() (CVSS, bool) {
+	var scores []CVSS
+	primary := -1
+
+	add := func(metricType, version, vector string, score float64, severity string) {
+		if metricType == cvssMetricTypePrimary && primary < 0 {
+			primary = len(scores)
+		}
+		scores = append(scores, CVSS{
+			Version:  version,
+			Vector:   vector,
+			Score:    score,
+			Severity: strings.ToUpper(severity),
+		})
+	}
+
+	m := cve.Metrics
+	switch {
+	case len(m.CvssMetricV31) > 0:
+		for _, metric := range m.CvssMetricV31 { //nolint:gocritic // (rangeValCopy) for readability
+			d := metric.CvssData
+			add(metric.Type, d.Version, d.VectorString, d.BaseScore, d.BaseSeverity)
+		}
+
+	case len(m.CvssMetricV30) > 0:
+		for _, metric := range m.CvssMetricV30 { //nolint:gocritic // (rangeValCopy) for readability
+			d := metric.CvssData
+			add(metric.Type, d.Version, d.VectorString, d.BaseScore, d.BaseSeverity)
+		}
+
+	case len(m.CvssMetricV2) > 0:
+		for _, metric := range m.CvssMetricV2 { //nolint:gocritic // (rangeValCopy) for readability
+			d := metric.CvssData
+			add(metric.Type, d.Version, d.VectorString, d.BaseScore, metric.BaseSeverity)
+		}
+	}
+
+	if len(scores) == 0 {
+		return CVSS{}, false
+	}
+	if primary >= 0 {
+		return scores[primary], true
+	}
+	return scores[0], true
+}
@@ -0,0 +1,66 @@
+package nvdapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_CVE(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, err := os.Open(filepath.Join("testdata", r.URL.Query().Get("cveId")+".json"))
+		if err != nil {
+			// NVD responds with an empty result set for unknown CVEs.
+			_, _ = io.WriteString(w, `{"resultsPerPage":0,"startIndex":0,"totalResults":0,"vulnerabilities":[]}`)
+			return
+		}
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	parsedURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	detector := NewDetector(ts.Client(), parsedURL.Host, "some-api-key")
+
+	t.Run("known CVE", func(t *testing.T) {
+		cve, err := detector.CVE(context.Background(), "CVE-2023-44487")
+		require.NoError(t, err)
+		require.NotNil(t, cve)
+
+		cvss, ok := cve.CVSS()
+		require.True(t, ok)
+
+		// NVD's own v3.1 score is preferred to the CNA's, and to the v2 score.
+		assert.Equal(t, CVSS{
+			Version:  "3.1",
+			Vector:   "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H",
+			Score:    7.5,
+			Severity: "HIGH",
+		}, cvss)
+	})
+
+	t.Run("unknown CVE", func(t *testing.T) {
+		cve, err := detector.CVE(context.Background(), "CVE-2000-0000")
+		require.NoError(t, err)
+		assert.Nil(t, cve)
+	})
+}
+
+func TestCve_CVSS(t *testing.T) {
+	t.Run("no metrics", func(t *testing.T) {
+		_, ok := Cve{ID: "CVE-2023-1234"}.CVSS()
+		assert.False(t, ok)
+	})
+}
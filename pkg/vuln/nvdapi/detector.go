@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 	"sync"
@@ -274,11 +275,6 @@ func cpeStringsMatch(requestCPE, responseCPE string) (bool, error) {
 var ErrRateLimited = errors.New("we've been rate limited by NVD! 🙊")
 
 func (d *Detector) doSearch(ctx context.Context, cpe string) ([]Cve, error) {
-	err := d.rateLimiter.Wait(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	// TODO: Deal with pages (not urgent because the default page size is 2,000
 	//  CVEs, and we're searching for single packages at a time.)
 
@@ -287,11 +283,46 @@ func (d *Detector) doSearch(ctx context.Context, cpe string) ([]Cve, error) {
 	//  for '...*:go...' multiple times because we've pruned versions from multiple,
 	//  related packages like 'go-1.18', 'go-1.19', and 'go-1.20'.
 
+	return d.doQuery(ctx, "virtualMatchString="+cpe)
+}
+
+// CVE looks up the CVE with the given ID. It returns nil if NVD has no record
+// of the CVE. Like the Detector's other lookups, it's constrained by the
+// Detector's configured rate limiter.
+func (d *Detector) CVE(ctx context.Context, id string) (*Cve, error) {
+	cves, err := d.doQuery(ctx, "cveId="+url.QueryEscape(id))
+	if err != nil {
+		if errors.Is(err, ErrRateLimited) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(5 * time.Second):
+			}
+			return d.CVE(ctx, id)
+		}
+		return nil, err
+	}
+
+	for i := range cves {
+		if cves[i].ID == id {
+			return &cves[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (d *Detector) doQuery(ctx context.Context, query string) ([]Cve, error) {
+	err := d.rateLimiter.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	reqURL := fmt.Sprintf(
-		"https://%s%s?virtualMatchString=%s",
+		"https://%s%s?%s",
 		d.serviceHost,
 		d.serviceEndpoint,
-		cpe,
+		query,
 	)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)